@@ -76,6 +76,24 @@ func (s APIHostPortsSetter) SetAPIHostPorts(servers [][]network.HostPort) error
 	})
 }
 
+// CACertSetter trivially wraps an Agent to implement
+// worker/caupdater/CACertSetter.
+type CACertSetter struct {
+	Agent
+}
+
+// SetCACert is the CACertSetter interface. The agent config is only
+// rewritten if the CA certificate has changed.
+func (s CACertSetter) SetCACert(caCert string) error {
+	if s.CurrentConfig().CACert() == caCert {
+		return nil
+	}
+	return s.ChangeConfig(func(c ConfigSetter) error {
+		c.SetCACert(caCert)
+		return nil
+	})
+}
+
 // SetStateServingInfo trivially wraps an Agent to implement
 // worker/certupdater/SetStateServingInfo.
 type StateServingInfoSetter struct {
@@ -278,6 +296,10 @@ type configSetterOnly interface {
 	// SetAPIHostPorts sets the API host/port addresses to connect to.
 	SetAPIHostPorts(servers [][]network.HostPort)
 
+	// SetCACert sets the CA certificates used to validate the API
+	// and state connections.
+	SetCACert(caCert string)
+
 	// Migrate takes an existing agent config and applies the given
 	// parameters to change it.
 	//
@@ -602,6 +624,10 @@ func (c *configInternal) SetAPIHostPorts(servers [][]network.HostPort) {
 	logger.Infof("API server address details %q written to agent config as %q", servers, addrs)
 }
 
+func (c *configInternal) SetCACert(caCert string) {
+	c.caCert = caCert
+}
+
 func (c *configInternal) SetValue(key, value string) {
 	if value == "" {
 		delete(c.values, key)
@@ -730,6 +730,18 @@ func (*suite) TestSetUpgradedToVersion(c *gc.C) {
 	c.Assert(conf.UpgradedToVersion(), gc.Equals, expectVers)
 }
 
+func (*suite) TestSetCACert(c *gc.C) {
+	conf, err := agent.NewAgentConfig(attributeParams)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(conf.CACert(), gc.Equals, attributeParams.CACert)
+
+	conf.SetCACert("new ca cert")
+	c.Assert(conf.CACert(), gc.Equals, "new ca cert")
+	apiInfo, ok := conf.APIInfo()
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(apiInfo.CACert, gc.Equals, "new ca cert")
+}
+
 func (*suite) TestSetAPIHostPorts(c *gc.C) {
 	conf, err := agent.NewAgentConfig(attributeParams)
 	c.Assert(err, jc.ErrorIsNil)
@@ -10,10 +10,14 @@ import (
 )
 
 // Create sends a request to create a backup of juju's state.  It
-// returns the metadata associated with the resulting backup.
-func (c *Client) Create(notes string) (*params.BackupsMetadataResult, error) {
+// returns the metadata associated with the resulting backup. If key is
+// not empty the archive is encrypted with it.
+func (c *Client) Create(notes string, key params.BackupsEncryptionKey) (*params.BackupsMetadataResult, error) {
 	var result params.BackupsMetadataResult
-	args := params.BackupsCreateArgs{Notes: notes}
+	args := params.BackupsCreateArgs{
+		Notes:      notes,
+		Encryption: key,
+	}
 	if err := c.facade.FacadeCall("Create", args, &result); err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -27,6 +27,7 @@ func (s *createSuite) TestCreate(c *gc.C) {
 			c.Assert(paramsIn, gc.FitsTypeOf, params.BackupsCreateArgs{})
 			p := paramsIn.(params.BackupsCreateArgs)
 			c.Check(p.Notes, gc.Equals, "important")
+			c.Check(p.Encryption, gc.Equals, params.BackupsEncryptionKey{Passphrase: "sekrit"})
 
 			if result, ok := resp.(*params.BackupsMetadataResult); ok {
 				*result = apiserverbackups.ResultFromMetadata(s.Meta)
@@ -39,7 +40,7 @@ func (s *createSuite) TestCreate(c *gc.C) {
 	)
 	defer cleanup()
 
-	result, err := s.client.Create("important")
+	result, err := s.client.Create("important", params.BackupsEncryptionKey{Passphrase: "sekrit"})
 	c.Assert(err, jc.ErrorIsNil)
 
 	meta := backupstesting.UpdateNotes(s.Meta, "important")
@@ -11,8 +11,17 @@ import (
 
 // List implements the API method.
 func (c *Client) List() (*params.BackupsListResult, error) {
+	return c.list(params.BackupsListArgs{})
+}
+
+// ListRemote returns the metadata of the backups held in the
+// controller's remote backup storage.
+func (c *Client) ListRemote() (*params.BackupsListResult, error) {
+	return c.list(params.BackupsListArgs{Remote: true})
+}
+
+func (c *Client) list(args params.BackupsListArgs) (*params.BackupsListResult, error) {
 	var result params.BackupsListResult
-	args := params.BackupsListArgs{}
 	if err := c.facade.FacadeCall("List", args, &result); err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -43,3 +43,29 @@ func (s *listSuite) TestList(c *gc.C) {
 	resultItem := result.List[0]
 	s.checkMetadataResult(c, &resultItem, s.Meta)
 }
+
+func (s *listSuite) TestListRemote(c *gc.C) {
+	cleanup := backups.PatchClientFacadeCall(s.client,
+		func(req string, paramsIn interface{}, resp interface{}) error {
+			c.Check(req, gc.Equals, "List")
+			c.Check(paramsIn, gc.Equals, params.BackupsListArgs{Remote: true})
+
+			if result, ok := resp.(*params.BackupsListResult); ok {
+				result.List = []params.BackupsMetadataResult{
+					apiserverbackups.ResultFromMetadata(s.Meta),
+				}
+			} else {
+				c.Fatalf("wrong output structure")
+			}
+			return nil
+		},
+	)
+	defer cleanup()
+
+	result, err := s.client.ListRemote()
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Assert(result.List, gc.HasLen, 1)
+	resultItem := result.List[0]
+	s.checkMetadataResult(c, &resultItem, s.Meta)
+}
@@ -0,0 +1,31 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package backups
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/apiserver/params"
+)
+
+// Remote returns the configuration of the remote storage to which the
+// controller copies completed backups.
+func (c *Client) Remote() (*params.BackupsRemoteConfig, error) {
+	var result params.BackupsRemoteConfig
+	if err := c.facade.FacadeCall("Remote", nil, &result); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &result, nil
+}
+
+// SetRemote sets the remote storage to which the controller copies
+// completed backups. An empty remoteType removes the remote storage.
+func (c *Client) SetRemote(remoteType string, attrs map[string]string) error {
+	args := params.BackupsRemoteConfig{
+		Type:  remoteType,
+		Attrs: attrs,
+	}
+	err := c.facade.FacadeCall("SetRemote", args, nil)
+	return errors.Trace(err)
+}
@@ -0,0 +1,62 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package backups_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/api/backups"
+	"github.com/juju/juju/apiserver/params"
+)
+
+type remoteSuite struct {
+	baseSuite
+}
+
+var _ = gc.Suite(&remoteSuite{})
+
+func (s *remoteSuite) TestRemote(c *gc.C) {
+	cleanup := backups.PatchClientFacadeCall(s.client,
+		func(req string, paramsIn interface{}, resp interface{}) error {
+			c.Check(req, gc.Equals, "Remote")
+			c.Check(paramsIn, gc.IsNil)
+
+			if result, ok := resp.(*params.BackupsRemoteConfig); ok {
+				result.Type = "local"
+				result.Attrs = map[string]string{"path": "/srv/backups"}
+			} else {
+				c.Fatalf("wrong output structure")
+			}
+			return nil
+		},
+	)
+	defer cleanup()
+
+	result, err := s.client.Remote()
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(result, jc.DeepEquals, &params.BackupsRemoteConfig{
+		Type:  "local",
+		Attrs: map[string]string{"path": "/srv/backups"},
+	})
+}
+
+func (s *remoteSuite) TestSetRemote(c *gc.C) {
+	cleanup := backups.PatchClientFacadeCall(s.client,
+		func(req string, paramsIn interface{}, resp interface{}) error {
+			c.Check(req, gc.Equals, "SetRemote")
+			c.Check(paramsIn, jc.DeepEquals, params.BackupsRemoteConfig{
+				Type:  "local",
+				Attrs: map[string]string{"path": "/srv/backups"},
+			})
+			c.Check(resp, gc.IsNil)
+			return nil
+		},
+	)
+	defer cleanup()
+
+	err := s.client.SetRemote("local", map[string]string{"path": "/srv/backups"})
+	c.Assert(err, jc.ErrorIsNil)
+}
@@ -61,8 +61,9 @@ func prepareRestore(newClient ClientConnection) error {
 	return errors.Annotatef(err, "could not start restore process: %v", remoteError)
 }
 
-// RestoreReader restores the contents of backupFile as backup.
-func (c *Client) RestoreReader(r io.ReadSeeker, meta *params.BackupsMetadataResult, newClient ClientConnection) error {
+// RestoreReader restores the contents of backupFile as backup. The key
+// is used to decrypt the backup, if it is encrypted.
+func (c *Client) RestoreReader(r io.ReadSeeker, meta *params.BackupsMetadataResult, key params.BackupsEncryptionKey, newClient ClientConnection) error {
 	if err := prepareRestore(newClient); err != nil {
 		return errors.Trace(err)
 	}
@@ -75,16 +76,28 @@ func (c *Client) RestoreReader(r io.ReadSeeker, meta *params.BackupsMetadataResu
 		logger.Errorf("could not exit restoring status: %v", finishErr)
 		return errors.Annotatef(err, "cannot upload backup file")
 	}
-	return c.restore(backupId, newClient)
+	return c.restore(backupId, key, false, newClient)
 }
 
 // Restore performs restore using a backup id corresponding to a backup stored in the server.
-func (c *Client) Restore(backupId string, newClient ClientConnection) error {
+// The key is used to decrypt the backup, if it is encrypted.
+func (c *Client) Restore(backupId string, key params.BackupsEncryptionKey, newClient ClientConnection) error {
 	if err := prepareRestore(newClient); err != nil {
 		return errors.Trace(err)
 	}
 	logger.Debugf("Server in 'about to restore' mode")
-	return c.restore(backupId, newClient)
+	return c.restore(backupId, key, false, newClient)
+}
+
+// RestoreFromRemote performs restore using a backup id corresponding to
+// a backup held in the controller's remote backup storage. The key is
+// used to decrypt the backup, if it is encrypted.
+func (c *Client) RestoreFromRemote(backupId string, key params.BackupsEncryptionKey, newClient ClientConnection) error {
+	if err := prepareRestore(newClient); err != nil {
+		return errors.Trace(err)
+	}
+	logger.Debugf("Server in 'about to restore' mode")
+	return c.restore(backupId, key, true, newClient)
 }
 
 func restoreAttempt(client *Client, closer closerFunc, restoreArgs params.RestoreArgs) (error, error) {
@@ -100,12 +113,14 @@ func restoreAttempt(client *Client, closer closerFunc, restoreArgs params.Restor
 // It takes backupId as the identifier for the remote backup file and a
 // client connection factory newClient (newClient should no longer be
 // necessary when lp:1399722 is sorted out).
-func (c *Client) restore(backupId string, newClient ClientConnection) error {
+func (c *Client) restore(backupId string, key params.BackupsEncryptionKey, fromRemote bool, newClient ClientConnection) error {
 	var err, remoteError error
 
 	// Restore
 	restoreArgs := params.RestoreArgs{
-		BackupId: backupId,
+		BackupId:   backupId,
+		Encryption: key,
+		FromRemote: fromRemote,
 	}
 
 	for a := restoreStrategy.Start(); a.Next(); {
@@ -19,17 +19,20 @@ import (
 var certDir = filepath.FromSlash(paths.MustSucceed(paths.CertDir(series.HostSeries())))
 
 // CreateCertPool creates a new x509.CertPool and adds in the caCert passed
-// in.  All certs from the cert directory (/etc/juju/cert.d on ubuntu) are
-// also added.
+// in. If caCert holds more than one certificate, as it does while the
+// controller's CA is being rotated, they are all added. All certs from
+// the cert directory (/etc/juju/cert.d on ubuntu) are also added.
 func CreateCertPool(caCert string) (*x509.CertPool, error) {
 
 	pool := x509.NewCertPool()
 	if caCert != "" {
-		xcert, err := cert.ParseCert(caCert)
+		xcerts, err := cert.ParseCerts(caCert)
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
-		pool.AddCert(xcert)
+		for _, xcert := range xcerts {
+			pool.AddCert(xcert)
+		}
 	}
 
 	count := processCertDir(pool)
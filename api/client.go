@@ -38,8 +38,15 @@ type Client struct {
 
 // Status returns the status of the juju environment.
 func (c *Client) Status(patterns []string) (*params.FullStatus, error) {
+	return c.FilteredStatus(patterns, "")
+}
+
+// FilteredStatus returns the status of the juju environment, restricted
+// to the entities matching the patterns and whose labels match the
+// selector. An empty selector matches everything.
+func (c *Client) FilteredStatus(patterns []string, selector string) (*params.FullStatus, error) {
 	var result params.FullStatus
-	p := params.StatusParams{Patterns: patterns}
+	p := params.StatusParams{Patterns: patterns, Selector: selector}
 	if err := c.facade.FacadeCall("FullStatus", p, &result); err != nil {
 		return nil, err
 	}
@@ -65,6 +72,22 @@ func (c *Client) UnitStatusHistory(kind params.HistoryKind, unitName string, siz
 	return &results, nil
 }
 
+// StatusHistory retrieves the status history of the machine, unit,
+// service, volume or filesystem with the given tag, oldest first. Kind
+// selects which of the entity's histories is returned; with
+// params.KindCombined, all of them are merged.
+func (c *Client) StatusHistory(args params.EntityStatusHistoryArgs) (*params.StatusHistoryResult, error) {
+	var result params.StatusHistoryResult
+	err := c.facade.FacadeCall("StatusHistory", args, &result)
+	if err != nil {
+		if params.IsCodeNotImplemented(err) {
+			return &params.StatusHistoryResult{}, errors.NotImplementedf("StatusHistory")
+		}
+		return &params.StatusHistoryResult{}, errors.Trace(err)
+	}
+	return &result, nil
+}
+
 // LegacyStatus is a stub version of Status that 1.16 introduced. Should be
 // removed along with structs when api versioning makes it safe to do so.
 func (c *Client) LegacyStatus() (*params.LegacyStatus, error) {
@@ -157,6 +180,79 @@ func (c *Client) DestroyRelation(endpoints ...string) error {
 	return c.facade.FacadeCall("DestroyRelation", params, nil)
 }
 
+// RelationData returns the relation settings of the units in scope of
+// the relation between the specified endpoints.
+func (c *Client) RelationData(endpoints ...string) (params.RelationDataResults, error) {
+	var result params.RelationDataResults
+	args := params.RelationData{Endpoints: endpoints}
+	err := c.facade.FacadeCall("RelationData", args, &result)
+	if params.IsCodeNotImplemented(err) {
+		return result, errors.NotImplementedf("RelationData")
+	}
+	return result, errors.Trace(err)
+}
+
+// SetRelationData updates the relation settings of a unit on the
+// relation between the specified endpoints. Settings with empty values
+// are removed.
+func (c *Client) SetRelationData(endpoints []string, unit string, settings map[string]string) error {
+	args := params.SetRelationData{
+		Endpoints: endpoints,
+		Unit:      unit,
+		Settings:  settings,
+	}
+	err := c.facade.FacadeCall("SetRelationData", args, nil)
+	if params.IsCodeNotImplemented(err) {
+		return errors.NotImplementedf("SetRelationData")
+	}
+	return errors.Trace(err)
+}
+
+// ControllerCertificates returns the expiry details of the controller's
+// CA and server certificates, and the CA certificates clients should
+// trust.
+func (c *Client) ControllerCertificates() (params.ControllerCertificatesInfo, error) {
+	var result params.ControllerCertificatesInfo
+	err := c.facade.FacadeCall("ControllerCertificates", nil, &result)
+	if params.IsCodeNotImplemented(err) {
+		return result, errors.NotImplementedf("ControllerCertificates")
+	}
+	return result, errors.Trace(err)
+}
+
+// RotateCertificates asks the controller's state servers to replace
+// their server certificates. If newCA is true, a new CA is generated
+// and trusted alongside the current one for the overlap period, after
+// which it signs the server certificates. A zero overlap uses the
+// controller's default.
+func (c *Client) RotateCertificates(newCA bool, overlap time.Duration) (params.ControllerCertificatesInfo, error) {
+	var result params.ControllerCertificatesInfo
+	args := params.RotateCertificates{
+		NewCA:   newCA,
+		Overlap: overlap,
+	}
+	err := c.facade.FacadeCall("RotateCertificates", args, &result)
+	if params.IsCodeNotImplemented(err) {
+		return result, errors.NotImplementedf("RotateCertificates")
+	}
+	return result, errors.Trace(err)
+}
+
+// HookLog returns the hooks recently run by the named unit, oldest
+// first. If size is positive, at most size records are returned.
+func (c *Client) HookLog(unitName string, size int) ([]params.HookRecord, error) {
+	var result params.HookLogResult
+	args := params.HookLog{
+		Tag:  names.NewUnitTag(unitName).String(),
+		Size: size,
+	}
+	err := c.facade.FacadeCall("HookLog", args, &result)
+	if params.IsCodeNotImplemented(err) {
+		return nil, errors.NotImplementedf("HookLog")
+	}
+	return result.Records, errors.Trace(err)
+}
+
 // ServiceCharmRelations returns the service's charms relation names.
 func (c *Client) ServiceCharmRelations(service string) ([]string, error) {
 	var results params.ServiceCharmRelationsResults
@@ -393,6 +489,38 @@ func (c *Client) ShareEnvironment(users ...names.UserTag) error {
 	return result.Combine()
 }
 
+// GrantEnvironment gives the given users the given access to the
+// environment, sharing it with them if it is not already.
+func (c *Client) GrantEnvironment(access string, users ...names.UserTag) error {
+	return c.modifyEnvironmentAccess(params.GrantEnvAccess, access, users)
+}
+
+// RevokeEnvironment takes the given access to the environment away from
+// the given users, leaving them with the access level below it. If
+// access is empty, or is viewer access, the environment is no longer
+// shared with the users.
+func (c *Client) RevokeEnvironment(access string, users ...names.UserTag) error {
+	return c.modifyEnvironmentAccess(params.RevokeEnvAccess, access, users)
+}
+
+func (c *Client) modifyEnvironmentAccess(action params.EnvironAction, access string, users []names.UserTag) error {
+	var args params.ModifyEnvironUsers
+	for _, user := range users {
+		args.Changes = append(args.Changes, params.ModifyEnvironUser{
+			UserTag: user.String(),
+			Action:  action,
+			Access:  access,
+		})
+	}
+
+	var result params.ErrorResults
+	err := c.facade.FacadeCall("ShareEnvironment", args, &result)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return result.Combine()
+}
+
 // EnvironmentUserInfo returns information on all users in the environment.
 func (c *Client) EnvironmentUserInfo() ([]params.EnvUserInfo, error) {
 	var results params.EnvUserInfoResults
@@ -314,6 +314,28 @@ func (s *clientSuite) TestShareEnvironmentThreeUsers(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, `existing user`)
 }
 
+func (s *clientSuite) TestGrantAndRevokeEnvironment(c *gc.C) {
+	client := s.APIState.Client()
+	user := s.Factory.MakeUser(c, &factory.UserParams{NoEnvUser: true})
+
+	err := client.GrantEnvironment(params.EnvOperatorAccess, user.UserTag())
+	c.Assert(err, jc.ErrorIsNil)
+	envUser, err := s.State.EnvironmentUser(user.UserTag())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(envUser.Access(), gc.Equals, state.EnvOperatorAccess)
+
+	err = client.RevokeEnvironment(params.EnvOperatorAccess, user.UserTag())
+	c.Assert(err, jc.ErrorIsNil)
+	envUser, err = s.State.EnvironmentUser(user.UserTag())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(envUser.Access(), gc.Equals, state.EnvViewerAccess)
+
+	err = client.RevokeEnvironment("", user.UserTag())
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.EnvironmentUser(user.UserTag())
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
 func (s *clientSuite) TestUnshareEnvironmentThreeUsers(c *gc.C) {
 	client := s.APIState.Client()
 	missingUser := s.Factory.MakeEnvUser(c, nil)
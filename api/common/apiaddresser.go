@@ -59,6 +59,20 @@ func (a *APIAddresser) CACert() (string, error) {
 	return string(result.Result), nil
 }
 
+// WatchCACert watches the certificates used to validate the API and
+// state connections.
+func (a *APIAddresser) WatchCACert() (watcher.NotifyWatcher, error) {
+	var result params.NotifyWatchResult
+	err := a.facade.FacadeCall("WatchCACert", nil, &result)
+	if err != nil {
+		return nil, err
+	}
+	if err := result.Error; err != nil {
+		return nil, err
+	}
+	return watcher.NewNotifyWatcher(a.facade.RawAPICaller(), result), nil
+}
+
 // APIHostPorts returns the host/port addresses of the API servers.
 func (a *APIAddresser) APIHostPorts() ([][]network.HostPort, error) {
 	var result params.APIHostPortsResult
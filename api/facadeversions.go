@@ -30,12 +30,13 @@ var facadeVersions = map[string]int{
 	"EnvironmentManager":           1,
 	"FilesystemAttachmentsWatcher": 1,
 	"Firewaller":                   1,
-	"HighAvailability":             1,
+	"HighAvailability":             2,
 	"ImageManager":                 1,
 	"ImageMetadata":                1,
 	"InstancePoller":               1,
 	"KeyManager":                   0,
 	"KeyUpdater":                   0,
+	"Labels":                       1,
 	"LeadershipService":            1,
 	"Logger":                       0,
 	"MachineManager":               1,
@@ -43,6 +44,7 @@ var facadeVersions = map[string]int{
 	"MetricsManager":               0,
 	"MeterStatus":                  1,
 	"MetricsAdder":                 1,
+	"Migration":                    1,
 	"Networker":                    0,
 	"NotifyWatcher":                0,
 	"Pinger":                       0,
@@ -51,7 +53,7 @@ var facadeVersions = map[string]int{
 	"RelationUnitsWatcher":         0,
 	"Resumer":                      1,
 	"Rsyslog":                      0,
-	"Service":                      2,
+	"Service":                      3,
 	"Storage":                      1,
 	"Spaces":                       1,
 	"Subnets":                      1,
@@ -61,7 +63,7 @@ var facadeVersions = map[string]int{
 	"SystemManager":                1,
 	"Upgrader":                     0,
 	"UnitAssigner":                 1,
-	"Uniter":                       2,
+	"Uniter":                       3,
 	"UserManager":                  0,
 	"VolumeAttachmentsWatcher":     1,
 	"Undertaker":                   1,
@@ -71,3 +71,34 @@ func (c *Client) EnsureAvailability(
 	}
 	return result.Result, nil
 }
+
+// AddStateServerMembers adds state servers that take part in the mongo
+// replica set with the given role, "non-voting" or "hidden", one for
+// each of the given placement directives.
+func (c *Client) AddStateServerMembers(
+	role string, cons constraints.Value, series string, placement []string,
+) (params.StateServersChanges, error) {
+	if c.facade.BestAPIVersion() < 2 {
+		return params.StateServersChanges{}, errors.Errorf("adding %s state servers not supported with this version of Juju", role)
+	}
+	var results params.StateServersChangeResults
+	arg := params.StateServerMembersSpecs{
+		Specs: []params.StateServerMembersSpec{{
+			EnvironTag:  c.environTag.String(),
+			Role:        role,
+			Constraints: cons,
+			Series:      series,
+			Placement:   placement,
+		}}}
+	if err := c.facade.FacadeCall("AddStateServerMembers", arg, &results); err != nil {
+		return params.StateServersChanges{}, err
+	}
+	if len(results.Results) != 1 {
+		return params.StateServersChanges{}, errors.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return params.StateServersChanges{}, result.Error
+	}
+	return result.Result, nil
+}
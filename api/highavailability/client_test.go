@@ -78,7 +78,29 @@ func (s *clientSuite) TestClientEnsureAvailability(c *gc.C) {
 
 func (s *clientSuite) TestClientEnsureAvailabilityVersion(c *gc.C) {
 	client := highavailability.NewClient(s.APIState)
-	c.Assert(client.BestAPIVersion(), gc.Equals, 1)
+	c.Assert(client.BestAPIVersion(), gc.Equals, 2)
+}
+
+func (s *clientSuite) TestClientAddStateServerMembers(c *gc.C) {
+	_, err := s.State.AddMachine("quantal", state.JobManageEnviron)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+
+	client := highavailability.NewClient(s.APIState)
+	result, err := client.AddStateServerMembers(
+		string(state.ReplicaSetNonVoter), constraints.Value{}, "", []string{"1", "dr-site"},
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Converted, gc.DeepEquals, []string{"machine-1"})
+	c.Assert(result.Added, gc.DeepEquals, []string{"machine-2"})
+
+	for _, id := range []string{"1", "2"} {
+		m, err := s.State.Machine(id)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Check(m.ReplicaSetRole(), gc.Equals, state.ReplicaSetNonVoter)
+		c.Check(m.Series(), gc.Equals, "quantal")
+	}
 }
 
 type clientLegacySuite struct {
@@ -89,6 +111,7 @@ var _ = gc.Suite(&clientLegacySuite{})
 
 func (s *clientLegacySuite) SetUpTest(c *gc.C) {
 	common.Facades.Discard("HighAvailability", 1)
+	common.Facades.Discard("HighAvailability", 2)
 	s.JujuConnSuite.SetUpTest(c)
 }
 
@@ -101,3 +124,9 @@ func (s *clientLegacySuite) TestEnsureAvailabilityLegacyRejectsPlacement(c *gc.C
 	_, err := client.EnsureAvailability(3, constraints.Value{}, "", []string{"machine"})
 	c.Assert(err, gc.ErrorMatches, "placement directives not supported with this version of Juju")
 }
+
+func (s *clientLegacySuite) TestAddStateServerMembersLegacy(c *gc.C) {
+	client := highavailability.NewClient(s.APIState)
+	_, err := client.AddStateServerMembers("non-voting", constraints.Value{}, "", []string{"dr-site"})
+	c.Assert(err, gc.ErrorMatches, "adding non-voting state servers not supported with this version of Juju")
+}
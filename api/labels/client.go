@@ -0,0 +1,64 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package labels
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/apiserver/params"
+)
+
+// Client allows access to the labels API end point.
+type Client struct {
+	base.ClientFacade
+	facade base.FacadeCaller
+}
+
+// NewClient creates a new client for accessing the labels API.
+func NewClient(st base.APICallCloser) *Client {
+	frontend, backend := base.NewClientFacade(st, "Labels")
+	return &Client{ClientFacade: frontend, facade: backend}
+}
+
+// Get returns the labels of the given entities.
+func (c *Client) Get(tags []string) ([]params.LabelsGetResult, error) {
+	args := params.Entities{Entities: make([]params.Entity, len(tags))}
+	for i, tag := range tags {
+		args.Entities[i].Tag = tag
+	}
+	var results params.LabelsGetResults
+	if err := c.facade.FacadeCall("Get", args, &results); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(results.Results) != len(tags) {
+		return nil, errors.Errorf("expected %d results, got %d", len(tags), len(results.Results))
+	}
+	return results.Results, nil
+}
+
+// Set sets labels on the entity with the given tag. Labels with an
+// empty value are removed.
+func (c *Client) Set(tag string, labels map[string]string) error {
+	args := params.LabelsSet{Labels: []params.EntityLabels{{
+		EntityTag: tag,
+		Labels:    labels,
+	}}}
+	var results params.ErrorResults
+	if err := c.facade.FacadeCall("Set", args, &results); err != nil {
+		return errors.Trace(err)
+	}
+	return results.OneError()
+}
+
+// Match returns the names of the machines, services and units whose
+// labels match the given selector.
+func (c *Client) Match(selector string) (params.LabelMatchResult, error) {
+	var result params.LabelMatchResult
+	args := params.LabelSelector{Selector: selector}
+	if err := c.facade.FacadeCall("Match", args, &result); err != nil {
+		return result, errors.Trace(err)
+	}
+	return result, nil
+}
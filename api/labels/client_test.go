@@ -0,0 +1,76 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package labels_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	basetesting "github.com/juju/juju/api/base/testing"
+	"github.com/juju/juju/api/labels"
+	"github.com/juju/juju/apiserver/params"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type labelsSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&labelsSuite{})
+
+func (s *labelsSuite) TestGet(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string, version int, id, request string, a, result interface{}) error {
+			c.Check(objType, gc.Equals, "Labels")
+			c.Check(request, gc.Equals, "Get")
+			c.Check(a, jc.DeepEquals, params.Entities{Entities: []params.Entity{{Tag: "machine-0"}}})
+			*(result.(*params.LabelsGetResults)) = params.LabelsGetResults{
+				Results: []params.LabelsGetResult{{
+					EntityTag: "machine-0",
+					Labels:    map[string]string{"zone": "east"},
+				}},
+			}
+			return nil
+		})
+	results, err := labels.NewClient(apiCaller).Get([]string{"machine-0"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(results, jc.DeepEquals, []params.LabelsGetResult{{
+		EntityTag: "machine-0",
+		Labels:    map[string]string{"zone": "east"},
+	}})
+}
+
+func (s *labelsSuite) TestSet(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string, version int, id, request string, a, result interface{}) error {
+			c.Check(objType, gc.Equals, "Labels")
+			c.Check(request, gc.Equals, "Set")
+			c.Check(a, jc.DeepEquals, params.LabelsSet{Labels: []params.EntityLabels{{
+				EntityTag: "unit-wordpress-0",
+				Labels:    map[string]string{"env": "canary"},
+			}}})
+			*(result.(*params.ErrorResults)) = params.ErrorResults{
+				Results: []params.ErrorResult{{Error: &params.Error{Message: "boom"}}},
+			}
+			return nil
+		})
+	err := labels.NewClient(apiCaller).Set("unit-wordpress-0", map[string]string{"env": "canary"})
+	c.Check(err, gc.ErrorMatches, "boom")
+}
+
+func (s *labelsSuite) TestMatch(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string, version int, id, request string, a, result interface{}) error {
+			c.Check(objType, gc.Equals, "Labels")
+			c.Check(request, gc.Equals, "Match")
+			c.Check(a, jc.DeepEquals, params.LabelSelector{Selector: "env=canary"})
+			*(result.(*params.LabelMatchResult)) = params.LabelMatchResult{
+				Units: []string{"wordpress/0"},
+			}
+			return nil
+		})
+	result, err := labels.NewClient(apiCaller).Match("env=canary")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(result, jc.DeepEquals, params.LabelMatchResult{Units: []string{"wordpress/0"}})
+}
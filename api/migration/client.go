@@ -0,0 +1,153 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package migration
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"gopkg.in/juju/charm.v6-unstable"
+
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/apiserver/params"
+)
+
+// Client provides access to the migration API end point, which moves
+// environments between controllers.
+type Client struct {
+	base.ClientFacade
+	st     base.APICallCloser
+	facade base.FacadeCaller
+}
+
+// NewClient creates a new client for accessing the migration API.
+func NewClient(st base.APICallCloser) *Client {
+	frontend, backend := base.NewClientFacade(st, "Migration")
+	return &Client{ClientFacade: frontend, st: st, facade: backend}
+}
+
+// Start freezes the environment and starts its migration to the
+// target controller.
+func (c *Client) Start(env names.EnvironTag, target params.MigrationControllerInfo) (params.MigrationStatus, error) {
+	args := params.StartMigrationArgs{
+		EnvironTag: env.String(),
+		Target:     target,
+	}
+	var result params.MigrationStatus
+	err := c.facade.FacadeCall("Start", args, &result)
+	return result, errors.Trace(err)
+}
+
+// Export returns the description of the migrating environment. The
+// archives of the described charms are read with CharmArchive.
+func (c *Client) Export(env names.EnvironTag) ([]byte, error) {
+	var result params.BytesResult
+	if err := c.facade.FacadeCall("Export", params.Entity{Tag: env.String()}, &result); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return result.Result, nil
+}
+
+// Import imports an environment description written by Export into
+// the controller, recording that it is migrating from the source
+// controller. The archives of the described charms must then be
+// added with AddCharmArchive.
+func (c *Client) Import(data []byte, source params.MigrationControllerInfo) (params.MigrationStatus, error) {
+	args := params.ImportEnvironmentArgs{
+		Bytes:  data,
+		Source: source,
+	}
+	var result params.MigrationStatus
+	err := c.facade.FacadeCall("Import", args, &result)
+	return result, errors.Trace(err)
+}
+
+// CharmArchive returns the archive of a charm of the environment
+// migrating from the controller. The caller is responsible for
+// closing the archive.
+func (c *Client) CharmArchive(env names.EnvironTag, curl *charm.URL) (io.ReadCloser, error) {
+	httpClient, err := c.st.HTTPClient()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	req, err := http.NewRequest("GET", charmArchivePath(env, curl), nil)
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot create download request")
+	}
+	var resp *http.Response
+	if err := httpClient.Do(req, nil, &resp); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return resp.Body, nil
+}
+
+// AddCharmArchive uploads the archive of a charm of the environment
+// being imported into the controller.
+func (c *Client) AddCharmArchive(env names.EnvironTag, curl *charm.URL, archive io.ReadSeeker) error {
+	httpClient, err := c.st.HTTPClient()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	req, err := http.NewRequest("PUT", charmArchivePath(env, curl), nil)
+	if err != nil {
+		return errors.Annotate(err, "cannot create upload request")
+	}
+	req.Header.Set("Content-Type", "application/zip")
+	var resp params.ErrorResult
+	if err := httpClient.Do(req, archive, &resp); err != nil {
+		return errors.Trace(err)
+	}
+	if resp.Error != nil {
+		return errors.Trace(resp.Error)
+	}
+	return nil
+}
+
+func charmArchivePath(env names.EnvironTag, curl *charm.URL) string {
+	attrs := url.Values{
+		"environ": {env.String()},
+		"url":     {curl.String()},
+	}
+	return "/migration/charms?" + attrs.Encode()
+}
+
+// SetPhase moves the migration of the environment to the given phase.
+func (c *Client) SetPhase(env names.EnvironTag, phase string) (params.MigrationStatus, error) {
+	args := params.SetMigrationPhaseArgs{
+		EnvironTag: env.String(),
+		Phase:      phase,
+	}
+	var result params.MigrationStatus
+	err := c.facade.FacadeCall("SetPhase", args, &result)
+	return result, errors.Trace(err)
+}
+
+// Abort aborts the migration of the environment.
+func (c *Client) Abort(env names.EnvironTag, reason string) (params.MigrationStatus, error) {
+	args := params.AbortMigrationArgs{
+		EnvironTag: env.String(),
+		Reason:     reason,
+	}
+	var result params.MigrationStatus
+	err := c.facade.FacadeCall("Abort", args, &result)
+	return result, errors.Trace(err)
+}
+
+// Status returns the migration of the environment as known to the
+// controller, including which of its agents are connected to it.
+func (c *Client) Status(env names.EnvironTag) (params.MigrationStatus, error) {
+	var result params.MigrationStatus
+	err := c.facade.FacadeCall("Status", params.Entity{Tag: env.String()}, &result)
+	return result, errors.Trace(err)
+}
+
+// RemoveEnvironment removes the migrated environment from the
+// controller, leaving its machines alone.
+func (c *Client) RemoveEnvironment(env names.EnvironTag) error {
+	err := c.facade.FacadeCall("RemoveEnvironment", params.Entity{Tag: env.String()}, nil)
+	return errors.Trace(err)
+}
@@ -72,6 +72,7 @@ func (c *Client) ServiceDeploy(
 	placement []*instance.Placement,
 	networks []string,
 	storage map[string]storage.Constraints,
+	endpointBindings map[string]string,
 ) error {
 	args := params.ServicesDeploy{
 		Services: []params.ServiceDeploy{{
@@ -85,6 +86,8 @@ func (c *Client) ServiceDeploy(
 			Placement:     placement,
 			Networks:      networks,
 			Storage:       storage,
+
+			EndpointBindings: endpointBindings,
 		}},
 	}
 	var results params.ErrorResults
@@ -140,6 +143,87 @@ func (c *Client) ServiceSetCharm(serviceName string, charmUrl string, forceSerie
 	return c.FacadeCall("ServiceSetCharm", args, nil)
 }
 
+// ServiceSetCharmStaged sets the charm for a given service, rolling
+// it out to the service's units in stages as described by rollout.
+func (c *Client) ServiceSetCharmStaged(serviceName string, charmUrl string, forceSeries, forceUnits bool, rollout params.CharmRollout) error {
+	if c.BestAPIVersion() < 3 {
+		return base.OldAgentError("ServiceSetCharmStaged", "2.0")
+	}
+
+	args := params.ServiceSetCharm{
+		ServiceName: serviceName,
+		CharmUrl:    charmUrl,
+		ForceSeries: forceSeries,
+		ForceUnits:  forceUnits,
+		Rollout:     &rollout,
+	}
+	return c.FacadeCall("ServiceSetCharm", args, nil)
+}
+
+// ServiceResumeCharmRollout resumes the paused rollout of the service's
+// charm to its units.
+func (c *Client) ServiceResumeCharmRollout(serviceName string) error {
+	if c.BestAPIVersion() < 3 {
+		return base.OldAgentError("ServiceResumeCharmRollout", "2.0")
+	}
+	args := params.ServiceResumeCharmRollout{ServiceName: serviceName}
+	return c.FacadeCall("ServiceResumeCharmRollout", args, nil)
+}
+
+// ServiceRollbackCharm changes the service's charm back to the one it
+// used before its charm was last changed, and returns that charm's URL.
+func (c *Client) ServiceRollbackCharm(serviceName string, forceUnits bool) (*charm.URL, error) {
+	if c.BestAPIVersion() < 3 {
+		return nil, base.OldAgentError("ServiceRollbackCharm", "2.0")
+	}
+	var result params.StringResult
+	args := params.ServiceRollbackCharm{
+		ServiceName: serviceName,
+		ForceUnits:  forceUnits,
+	}
+	err := c.FacadeCall("ServiceRollbackCharm", args, &result)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return charm.ParseURL(result.Result)
+}
+
+// ServiceOffer offers the named endpoint of a service to the other
+// environments on the controller. If offerName is empty, the offer is
+// named after the service.
+func (c *Client) ServiceOffer(serviceName, endpoint, offerName string) error {
+	if c.BestAPIVersion() < 3 {
+		return base.OldAgentError("ServiceOffer", "2.0")
+	}
+	args := params.ServiceOffer{
+		ServiceName: serviceName,
+		Endpoint:    endpoint,
+		OfferName:   offerName,
+	}
+	return c.FacadeCall("ServiceOffer", args, nil)
+}
+
+// ServiceConsume adds a remote service for an offer made by another
+// environment on the controller, and returns its name. The environment
+// is given by name, optionally qualified by its owner as "owner/name".
+// If serviceName is empty, the remote service is named after the offer.
+func (c *Client) ServiceConsume(environment, offerName, serviceName string) (string, error) {
+	if c.BestAPIVersion() < 3 {
+		return "", base.OldAgentError("ServiceConsume", "2.0")
+	}
+	var result params.StringResult
+	args := params.ServiceConsume{
+		Environment: environment,
+		OfferName:   offerName,
+		ServiceName: serviceName,
+	}
+	err := c.FacadeCall("ServiceConsume", args, &result)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	return result.Result, nil
+}
+
 // ServiceUpdate updates the service attributes, including charm URL,
 // minimum number of units, settings and constraints.
 // TODO(frankban) deprecate redundant API calls that this supercedes.
@@ -150,3 +234,28 @@ func (c *Client) ServiceUpdate(args params.ServiceUpdate) error {
 
 	return c.FacadeCall("ServiceUpdate", args, nil)
 }
+
+// ServiceConfigHistory returns the recorded revisions of the service's
+// config settings, oldest first.
+func (c *Client) ServiceConfigHistory(serviceName string) ([]params.ServiceConfigRevision, error) {
+	if c.BestAPIVersion() < 3 {
+		return nil, base.OldAgentError("ServiceConfigHistory", "2.0")
+	}
+	var result params.ServiceConfigHistoryResults
+	args := params.ServiceGet{ServiceName: serviceName}
+	err := c.FacadeCall("ServiceConfigHistory", args, &result)
+	return result.Revisions, errors.Trace(err)
+}
+
+// ServiceConfigRollback restores the service's config settings to
+// those of the given revision.
+func (c *Client) ServiceConfigRollback(serviceName string, revision int) error {
+	if c.BestAPIVersion() < 3 {
+		return base.OldAgentError("ServiceConfigRollback", "2.0")
+	}
+	args := params.ServiceConfigRollback{
+		ServiceName: serviceName,
+		Revision:    revision,
+	}
+	return c.FacadeCall("ServiceConfigRollback", args, nil)
+}
@@ -5,6 +5,7 @@ package uniter
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/juju/errors"
 	"github.com/juju/names"
@@ -70,6 +71,24 @@ func (u *Unit) SetUnitStatus(status params.Status, info string, data map[string]
 	return result.OneError()
 }
 
+// SetUnitHealth records the results of the unit's health checks.
+func (u *Unit) SetUnitHealth(status params.Status, info string, data map[string]interface{}) error {
+	if u.st.facade.BestAPIVersion() < 3 {
+		return errors.NotImplementedf("SetUnitHealth() (need V3+)")
+	}
+	var result params.ErrorResults
+	args := params.SetStatus{
+		Entities: []params.EntityStatusArgs{
+			{Tag: u.tag.String(), Status: status, Info: info, Data: data},
+		},
+	}
+	err := u.st.facade.FacadeCall("SetUnitHealth", args, &result)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return result.OneError()
+}
+
 // UnitStatus gets the status details of the unit.
 func (u *Unit) UnitStatus() (params.StatusResult, error) {
 	var results params.StatusResults
@@ -757,3 +776,64 @@ func (u *Unit) AddStorage(constraints map[string][]params.StorageConstraints) er
 
 	return results.Combine()
 }
+
+// NetworkInfo returns the network information the unit should use
+// for each of the given relation endpoints, keyed by endpoint name.
+func (u *Unit) NetworkInfo(bindings []string) (map[string]params.NetworkInfoResult, error) {
+	if u.st.facade.BestAPIVersion() < 3 {
+		return nil, errors.NotImplementedf("NetworkInfo() (need V3+)")
+	}
+	args := params.NetworkInfoParams{
+		Unit:     u.tag.String(),
+		Bindings: bindings,
+	}
+	var results params.NetworkInfoResults
+	err := u.st.facade.FacadeCall("NetworkInfo", args, &results)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return results.Results, nil
+}
+
+// AddHookRecord records the execution of a hook by the unit.
+func (u *Unit) AddHookRecord(record params.HookRecord) error {
+	if u.st.facade.BestAPIVersion() < 3 {
+		return errors.NotImplementedf("AddHookRecord() (need V3+)")
+	}
+	args := params.HookRecordParams{
+		Records: []params.HookRecordParam{{
+			Tag:    u.tag.String(),
+			Record: record,
+		}},
+	}
+	var results params.ErrorResults
+	err := u.st.facade.FacadeCall("AddHookRecords", args, &results)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return results.OneError()
+}
+
+// HookTimeout returns how long the unit's hooks may run before they
+// are killed. Zero means hooks may run indefinitely.
+func (u *Unit) HookTimeout() (time.Duration, error) {
+	if u.st.facade.BestAPIVersion() < 3 {
+		return 0, errors.NotImplementedf("HookTimeout() (need V3+)")
+	}
+	args := params.Entities{
+		Entities: []params.Entity{{Tag: u.tag.String()}},
+	}
+	var results params.HookTimeoutResults
+	err := u.st.facade.FacadeCall("HookTimeouts", args, &results)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	if len(results.Results) != 1 {
+		return 0, errors.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return result.Timeout, nil
+}
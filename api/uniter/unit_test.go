@@ -129,6 +129,23 @@ func (s *unitSuite) TestSetUnitStatusOldServer(c *gc.C) {
 	c.Assert(err.Error(), gc.Equals, "SetUnitStatus not implemented")
 }
 
+func (s *unitSuite) TestSetUnitHealth(c *gc.C) {
+	err := s.apiUnit.SetUnitHealth(params.StatusUnhealthy, `check "web" failed`, nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	statusInfo, err := s.wordpressUnit.Health().Status()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(statusInfo.Status, gc.Equals, state.StatusUnhealthy)
+	c.Assert(statusInfo.Message, gc.Equals, `check "web" failed`)
+}
+
+func (s *unitSuite) TestSetUnitHealthOldServer(c *gc.C) {
+	s.patchNewState(c, uniter.NewStateV1)
+
+	err := s.apiUnit.SetUnitHealth(params.StatusHealthy, "", nil)
+	c.Assert(err, jc.Satisfies, errors.IsNotImplemented)
+}
+
 func (s *unitSuite) TestSetAgentStatusOldServer(c *gc.C) {
 	s.patchNewState(c, uniter.NewStateV1)
 
@@ -166,3 +166,80 @@ func (c *Client) SetPassword(username, password string) error {
 	}
 	return results.OneError()
 }
+
+// ownerTag returns the tag of the user with the given name, or the
+// empty string, which stands for the logged in user, if the name is
+// empty.
+func ownerTag(owner string) (string, error) {
+	if owner == "" {
+		return "", nil
+	}
+	if !names.IsValidUserName(owner) {
+		return "", errors.Errorf("%q is not a valid username", owner)
+	}
+	return names.NewLocalUserTag(owner).String(), nil
+}
+
+// AddAPIToken adds an API token owned by the given user, or by the
+// logged in user if owner is empty, and returns the credentials to log
+// in with it. The credentials cannot be retrieved again later.
+func (c *Client) AddAPIToken(owner string, token params.AddAPIToken) (string, error) {
+	tag, err := ownerTag(owner)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	token.Owner = tag
+	args := params.AddAPITokens{Tokens: []params.AddAPIToken{token}}
+	var results params.AddAPITokenResults
+	if err := c.facade.FacadeCall("AddAPIToken", args, &results); err != nil {
+		return "", errors.Trace(err)
+	}
+	if count := len(results.Results); count != 1 {
+		return "", errors.Errorf("expected 1 result, got %d", count)
+	}
+	if err := results.Results[0].Error; err != nil {
+		return "", errors.Trace(err)
+	}
+	return results.Results[0].Credentials, nil
+}
+
+// ListAPITokens returns the API tokens owned by the given user, or by
+// the logged in user if owner is empty.
+func (c *Client) ListAPITokens(owner string) ([]params.APITokenInfo, error) {
+	tag, err := ownerTag(owner)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var args params.Entities
+	if tag != "" {
+		args.Entities = []params.Entity{{Tag: tag}}
+	}
+	var results params.APITokensResults
+	if err := c.facade.FacadeCall("ListAPITokens", args, &results); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if count := len(results.Results); count != 1 {
+		return nil, errors.Errorf("expected 1 result, got %d", count)
+	}
+	if err := results.Results[0].Error; err != nil {
+		return nil, errors.Trace(err)
+	}
+	return results.Results[0].Tokens, nil
+}
+
+// RemoveAPIToken revokes the named API token owned by the given user,
+// or by the logged in user if owner is empty.
+func (c *Client) RemoveAPIToken(owner, name string) error {
+	tag, err := ownerTag(owner)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	args := params.APITokenNames{
+		Tokens: []params.APITokenName{{Owner: tag, Name: name}},
+	}
+	var results params.ErrorResults
+	if err := c.facade.FacadeCall("RemoveAPIToken", args, &results); err != nil {
+		return errors.Trace(err)
+	}
+	return results.OneError()
+}
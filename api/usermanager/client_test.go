@@ -177,3 +177,38 @@ func (s *usermanagerSuite) TestSetUserPasswordBadName(c *gc.C) {
 	err := s.usermanager.SetPassword("not@home", "new-password")
 	c.Assert(err, gc.ErrorMatches, `"not@home" is not a valid username`)
 }
+
+func (s *usermanagerSuite) TestAPITokens(c *gc.C) {
+	credentials, err := s.usermanager.AddAPIToken("", params.AddAPIToken{
+		Name:    "jenkins",
+		Facades: []string{"Client"},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(credentials, jc.HasPrefix, "token:jenkins:")
+
+	tokens, err := s.usermanager.ListAPITokens("")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(tokens, gc.HasLen, 1)
+	c.Assert(tokens[0].Name, gc.Equals, "jenkins")
+	c.Assert(tokens[0].Owner, gc.Equals, s.AdminUserTag(c).String())
+	c.Assert(tokens[0].Facades, jc.DeepEquals, []string{"Client"})
+
+	err = s.usermanager.RemoveAPIToken("", "jenkins")
+	c.Assert(err, jc.ErrorIsNil)
+	tokens, err = s.usermanager.ListAPITokens("")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(tokens, gc.HasLen, 0)
+}
+
+func (s *usermanagerSuite) TestAddAPITokenForOtherUser(c *gc.C) {
+	user := s.Factory.MakeUser(c, &factory.UserParams{Name: "ci-bot"})
+	_, err := s.usermanager.AddAPIToken("ci-bot", params.AddAPIToken{Name: "jenkins"})
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.APIToken(user.UserTag(), "jenkins")
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *usermanagerSuite) TestAddAPITokenBadOwner(c *gc.C) {
+	_, err := s.usermanager.AddAPIToken("not@home", params.AddAPIToken{Name: "jenkins"})
+	c.Assert(err, gc.ErrorMatches, `"not@home" is not a valid username`)
+}
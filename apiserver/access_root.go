@@ -0,0 +1,264 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver
+
+import (
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/rpc"
+	"github.com/juju/juju/rpc/rpcreflect"
+	"github.com/juju/juju/state"
+)
+
+// accessCacheTTL is how long the access found for a user is relied on
+// before it is looked up again.
+var accessCacheTTL = 5 * time.Second
+
+// accessRoot restricts the API calls an environment user may make to
+// those their access to the environment allows. The access is looked
+// up again once it is accessCacheTTL old, so that changes to it apply
+// to connections that are already open.
+type accessRoot struct {
+	rpc.MethodFinder
+	currentAccess func() (state.EnvUserAccess, error)
+
+	mu      sync.Mutex
+	access  state.EnvUserAccess
+	expires time.Time
+}
+
+// newAccessRoot returns a new accessRoot for a user whose current access
+// to the environment is returned by currentAccess.
+func newAccessRoot(finder rpc.MethodFinder, currentAccess func() (state.EnvUserAccess, error)) *accessRoot {
+	return &accessRoot{
+		MethodFinder:  finder,
+		currentAccess: currentAccess,
+	}
+}
+
+// userAccess returns the user's access to the environment, looking it
+// up if the access last found is too old.
+func (r *accessRoot) userAccess() (state.EnvUserAccess, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	if r.access != "" && now.Before(r.expires) {
+		return r.access, nil
+	}
+	access, err := r.currentAccess()
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	r.access = access
+	r.expires = now.Add(accessCacheTTL)
+	return access, nil
+}
+
+// anyMethod is the key, in a facade's permissions, of the access needed
+// to call any method of the facade not listed separately.
+const anyMethod = "*"
+
+const (
+	viewerAccess   = state.EnvViewerAccess
+	operatorAccess = state.EnvOperatorAccess
+	deployerAccess = state.EnvDeployerAccess
+)
+
+// facadePermissions holds, for each facade that environment users may
+// call, the least access needed to call each of its methods. Users with
+// admin access may call any method; other users may call only those
+// methods listed here, even if the facade itself would allow more.
+var facadePermissions = map[string]map[string]state.EnvUserAccess{
+	"Action": {
+		"Actions":                viewerAccess,
+		"FindActionTagsByPrefix": viewerAccess,
+		"ListAll":                viewerAccess,
+		"ListCompleted":          viewerAccess,
+		"ListPending":            viewerAccess,
+		"ListRunning":            viewerAccess,
+		"ServicesCharmActions":   viewerAccess,
+		"Enqueue":                operatorAccess,
+		"Cancel":                 operatorAccess,
+	},
+	"Annotations": {
+		"Get": viewerAccess,
+		"Set": deployerAccess,
+	},
+	"Block": {
+		"List": viewerAccess,
+	},
+	"Charms": {
+		anyMethod: viewerAccess,
+	},
+	"Client": {
+		"APIHostPorts":                 viewerAccess,
+		"AgentVersion":                 viewerAccess,
+		"CharmInfo":                    viewerAccess,
+		"ControllerCertificates":       viewerAccess,
+		"EnvUserInfo":                  viewerAccess,
+		"EnvironmentGet":               viewerAccess,
+		"EnvironmentInfo":              viewerAccess,
+		"FindTools":                    viewerAccess,
+		"FullStatus":                   viewerAccess,
+		"GetAnnotations":               viewerAccess,
+		"GetBundleChanges":             viewerAccess,
+		"GetEnvironmentConstraints":    viewerAccess,
+		"GetServiceConstraints":        viewerAccess,
+		"HookLog":                      viewerAccess,
+		"PrivateAddress":               viewerAccess,
+		"PublicAddress":                viewerAccess,
+		"RelationData":                 viewerAccess,
+		"ResolveCharms":                viewerAccess,
+		"ServiceCharmRelations":        viewerAccess,
+		"ServiceGet":                   viewerAccess,
+		"Status":                       viewerAccess,
+		"StatusHistory":                viewerAccess,
+		"UnitStatusHistory":            viewerAccess,
+		"WatchAll":                     viewerAccess,
+		"Resolved":                     operatorAccess,
+		"RetryProvisioning":            operatorAccess,
+		"Run":                          operatorAccess,
+		"RunOnAllMachines":             operatorAccess,
+		"AddCharm":                     deployerAccess,
+		"AddCharmWithAuthorization":    deployerAccess,
+		"AddMachines":                  deployerAccess,
+		"AddMachinesV2":                deployerAccess,
+		"AddRelation":                  deployerAccess,
+		"AddServiceUnits":              deployerAccess,
+		"AddServiceUnitsWithPlacement": deployerAccess,
+		"DestroyMachines":              deployerAccess,
+		"DestroyRelation":              deployerAccess,
+		"DestroyServiceUnits":          deployerAccess,
+		"InjectMachines":               deployerAccess,
+		"ProvisioningScript":           deployerAccess,
+		"ServiceDeploy":                deployerAccess,
+		"ServiceDeployWithNetworks":    deployerAccess,
+		"ServiceDestroy":               deployerAccess,
+		"ServiceExpose":                deployerAccess,
+		"ServiceSet":                   deployerAccess,
+		"ServiceUnexpose":              deployerAccess,
+		"ServiceUnset":                 deployerAccess,
+		"SetAnnotations":               deployerAccess,
+		"SetRelationData":              deployerAccess,
+		"SetServiceConstraints":        deployerAccess,
+	},
+	"EnvironmentManager": {
+		// Creating an environment does not change this one; the
+		// facade checks who may create environments for whom.
+		anyMethod: viewerAccess,
+	},
+	"ImageManager": {
+		"ListImages": viewerAccess,
+	},
+	"ImageMetadata": {
+		"List": viewerAccess,
+	},
+	"KeyManager": {
+		"ListKeys": viewerAccess,
+	},
+	"Labels": {
+		"Get":   viewerAccess,
+		"Match": viewerAccess,
+		"Set":   deployerAccess,
+	},
+	"MachineManager": {
+		"AddMachines": deployerAccess,
+	},
+	"Pinger": {
+		anyMethod: viewerAccess,
+	},
+	"Service": {
+		"ServiceConfigHistory":        viewerAccess,
+		"ServiceGetCharmURL":          viewerAccess,
+		"ServiceConfigRollback":       deployerAccess,
+		"ServiceConsume":              deployerAccess,
+		"ServiceResumeCharmRollout":   deployerAccess,
+		"ServiceRollbackCharm":        deployerAccess,
+		"ServiceSetCharm":             deployerAccess,
+		"ServiceUpdate":               deployerAccess,
+		"ServicesDeploy":              deployerAccess,
+		"ServicesDeployWithPlacement": deployerAccess,
+		"SetMetricCredentials":        deployerAccess,
+	},
+	"Spaces": {
+		"ListSpaces": viewerAccess,
+	},
+	"Storage": {
+		"ListFilesystems":    viewerAccess,
+		"ListPools":          viewerAccess,
+		"ListStorageDetails": viewerAccess,
+		"ListVolumes":        viewerAccess,
+		"StorageDetails":     viewerAccess,
+		"AddToUnit":          deployerAccess,
+	},
+	"Subnets": {
+		"AllSpaces":   viewerAccess,
+		"AllZones":    viewerAccess,
+		"ListSubnets": viewerAccess,
+	},
+	"UserManager": {
+		// The facade only lets users who are not controller
+		// administrators change their own passwords and manage
+		// their own API tokens.
+		"AddAPIToken":    viewerAccess,
+		"ListAPITokens":  viewerAccess,
+		"RemoveAPIToken": viewerAccess,
+		"SetPassword":    viewerAccess,
+		"UserInfo":       viewerAccess,
+	},
+
+	// Watchers are only ever handed out by the methods above.
+	"AllWatcher":                   {anyMethod: viewerAccess},
+	"EntityWatcher":                {anyMethod: viewerAccess},
+	"FilesystemAttachmentsWatcher": {anyMethod: viewerAccess},
+	"NotifyWatcher":                {anyMethod: viewerAccess},
+	"RelationUnitsWatcher":         {anyMethod: viewerAccess},
+	"StringsWatcher":               {anyMethod: viewerAccess},
+	"VolumeAttachmentsWatcher":     {anyMethod: viewerAccess},
+}
+
+// RequiredAccess returns the least access to the environment a user
+// needs to call the given method, and false if only administrators may
+// call it.
+func RequiredAccess(rootName, methodName string) (state.EnvUserAccess, bool) {
+	methods, ok := facadePermissions[rootName]
+	if !ok {
+		return "", false
+	}
+	if access, ok := methods[methodName]; ok {
+		return access, true
+	}
+	access, ok := methods[anyMethod]
+	return access, ok
+}
+
+// FindMethod returns a permission denied error if the user's access
+// does not allow them to call the method.
+func (r *accessRoot) FindMethod(rootName string, version int, methodName string) (rpcreflect.MethodCaller, error) {
+	caller, err := r.MethodFinder.FindMethod(rootName, version, methodName)
+	if err != nil {
+		return nil, err
+	}
+	access, err := r.userAccess()
+	if errors.IsNotFound(err) {
+		// The user no longer has access to the environment.
+		logger.Debugf("cannot find access for %s.%s: %v", rootName, methodName, err)
+		return nil, common.ErrPerm
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if access == state.EnvAdminAccess {
+		return caller, nil
+	}
+	required, ok := RequiredAccess(rootName, methodName)
+	if !ok || !access.Includes(required) {
+		logger.Debugf("%s access does not allow %s.%s", access, rootName, methodName)
+		return nil, common.ErrPerm
+	}
+	return caller, nil
+}
@@ -0,0 +1,151 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver_test
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver"
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/feature"
+	"github.com/juju/juju/rpc/rpcreflect"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/testing"
+)
+
+type accessRootSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&accessRootSuite{})
+
+func (s *accessRootSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.SetFeatureFlags(feature.JES)
+}
+
+func (s *accessRootSuite) assertAllowed(c *gc.C, access state.EnvUserAccess, rootName string, version int, method string) {
+	caller, err := apiserver.TestingAccessRoot(nil, access).FindMethod(rootName, version, method)
+	c.Check(err, jc.ErrorIsNil)
+	c.Check(caller, gc.NotNil)
+}
+
+func (s *accessRootSuite) assertDenied(c *gc.C, access state.EnvUserAccess, rootName string, version int, method string) {
+	caller, err := apiserver.TestingAccessRoot(nil, access).FindMethod(rootName, version, method)
+	c.Check(err, gc.Equals, common.ErrPerm)
+	c.Check(caller, gc.IsNil)
+}
+
+func (s *accessRootSuite) TestViewer(c *gc.C) {
+	s.assertAllowed(c, state.EnvViewerAccess, "Client", 0, "FullStatus")
+	s.assertAllowed(c, state.EnvViewerAccess, "AllWatcher", 0, "Next")
+	s.assertAllowed(c, state.EnvViewerAccess, "Action", 0, "ListAll")
+	s.assertDenied(c, state.EnvViewerAccess, "Client", 0, "Resolved")
+	s.assertDenied(c, state.EnvViewerAccess, "Action", 0, "Enqueue")
+	s.assertDenied(c, state.EnvViewerAccess, "Service", 2, "ServicesDeploy")
+}
+
+func (s *accessRootSuite) TestOperator(c *gc.C) {
+	s.assertAllowed(c, state.EnvOperatorAccess, "Client", 0, "FullStatus")
+	s.assertAllowed(c, state.EnvOperatorAccess, "Client", 0, "Resolved")
+	s.assertAllowed(c, state.EnvOperatorAccess, "Client", 0, "Run")
+	s.assertAllowed(c, state.EnvOperatorAccess, "Action", 0, "Enqueue")
+	s.assertDenied(c, state.EnvOperatorAccess, "Service", 2, "ServicesDeploy")
+	s.assertDenied(c, state.EnvOperatorAccess, "Client", 0, "EnvironmentSet")
+}
+
+func (s *accessRootSuite) TestDeployer(c *gc.C) {
+	s.assertAllowed(c, state.EnvDeployerAccess, "Client", 0, "Resolved")
+	s.assertAllowed(c, state.EnvDeployerAccess, "Service", 2, "ServicesDeploy")
+	s.assertAllowed(c, state.EnvDeployerAccess, "Client", 0, "AddRelation")
+	s.assertDenied(c, state.EnvDeployerAccess, "Client", 0, "EnvironmentSet")
+	s.assertDenied(c, state.EnvDeployerAccess, "Client", 0, "ShareEnvironment")
+	s.assertDenied(c, state.EnvDeployerAccess, "KeyManager", 0, "AddKeys")
+}
+
+func (s *accessRootSuite) TestAdmin(c *gc.C) {
+	s.assertAllowed(c, state.EnvAdminAccess, "Client", 0, "EnvironmentSet")
+	s.assertAllowed(c, state.EnvAdminAccess, "Client", 0, "ShareEnvironment")
+	s.assertAllowed(c, state.EnvAdminAccess, "KeyManager", 0, "AddKeys")
+}
+
+func (s *accessRootSuite) TestUnknownAccessDenied(c *gc.C) {
+	s.assertDenied(c, "superuser", "Client", 0, "FullStatus")
+}
+
+func (s *accessRootSuite) TestAccessLookedUpAgain(c *gc.C) {
+	s.PatchValue(apiserver.AccessCacheTTL, time.Duration(0))
+	access := state.EnvOperatorAccess
+	root := apiserver.TestingAccessRootFunc(nil, func() (state.EnvUserAccess, error) {
+		return access, nil
+	})
+	_, err := root.FindMethod("Client", 0, "Resolved")
+	c.Assert(err, jc.ErrorIsNil)
+
+	access = state.EnvViewerAccess
+	_, err = root.FindMethod("Client", 0, "Resolved")
+	c.Assert(err, gc.Equals, common.ErrPerm)
+}
+
+func (s *accessRootSuite) TestAccessCached(c *gc.C) {
+	access := state.EnvOperatorAccess
+	lookups := 0
+	root := apiserver.TestingAccessRootFunc(nil, func() (state.EnvUserAccess, error) {
+		lookups++
+		return access, nil
+	})
+	_, err := root.FindMethod("Client", 0, "Resolved")
+	c.Assert(err, jc.ErrorIsNil)
+
+	// The access found is relied on until it is stale.
+	access = state.EnvViewerAccess
+	_, err = root.FindMethod("Client", 0, "Resolved")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(lookups, gc.Equals, 1)
+}
+
+func (s *accessRootSuite) TestAccessNotFoundDenied(c *gc.C) {
+	root := apiserver.TestingAccessRootFunc(nil, func() (state.EnvUserAccess, error) {
+		return "", errors.NotFoundf("environment user")
+	})
+	caller, err := root.FindMethod("Client", 0, "FullStatus")
+	c.Assert(err, gc.Equals, common.ErrPerm)
+	c.Assert(caller, gc.IsNil)
+}
+
+func (s *accessRootSuite) TestNonExistentMethod(c *gc.C) {
+	caller, err := apiserver.TestingAccessRoot(nil, state.EnvViewerAccess).FindMethod("Client", 0, "Bar")
+	c.Assert(err, gc.ErrorMatches, `no such request - method Client\(0\).Bar is not implemented`)
+	c.Assert(caller, gc.IsNil)
+}
+
+func (s *accessRootSuite) TestPermissionsNameRealMethods(c *gc.C) {
+	versions := make(map[string][]int)
+	for _, facade := range common.Facades.List() {
+		versions[facade.Name] = facade.Versions
+	}
+	for rootName, methods := range apiserver.FacadePermissions {
+		c.Check(versions[rootName], gc.Not(gc.HasLen), 0, gc.Commentf("facade %q", rootName))
+		for method, access := range methods {
+			c.Check(access.Validate(), jc.ErrorIsNil)
+			if method == "*" {
+				continue
+			}
+			found := false
+			for _, version := range versions[rootName] {
+				facadeType, err := common.Facades.GetType(rootName, version)
+				c.Assert(err, jc.ErrorIsNil)
+				if _, err := rpcreflect.ObjTypeOf(facadeType).Method(method); err == nil {
+					found = true
+					break
+				}
+			}
+			c.Check(found, jc.IsTrue, gc.Commentf("method %s.%s", rootName, method))
+		}
+	}
+}
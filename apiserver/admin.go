@@ -95,6 +95,9 @@ func (a *admin) doLogin(req params.LoginRequest, loginVersion int) (params.Login
 			logger.Infof("login failed with discharge-required error: %v", err)
 			return loginResult, nil
 		}
+		if isUser {
+			recordFailedLogin(a.srv.statePool.SystemState(), req, err)
+		}
 		if a.maintenanceInProgress() {
 			// An upgrade, restore or similar operation is in
 			// progress. It is possible for logins to fail until this
@@ -127,6 +130,35 @@ func (a *admin) doLogin(req params.LoginRequest, loginVersion int) (params.Login
 	}
 	a.root.entity = entity
 
+	// Environment users may only make the calls their current access
+	// to the environment allows.
+	if user, ok := entity.(*environmentUserEntity); ok {
+		st, userTag := a.root.state, user.envUser.UserTag()
+		authedApi = newAccessRoot(authedApi, func() (state.EnvUserAccess, error) {
+			envUser, err := st.EnvironmentUser(userTag)
+			if err != nil {
+				return "", errors.Trace(err)
+			}
+			return envUser.Access(), nil
+		})
+	}
+
+	// Users logged in with an API token may only make the calls the
+	// token allows, for as long as it remains valid.
+	if isUser {
+		authedApi = a.applyTokenRoot(authedApi, entity, req)
+	}
+
+	// Local users whose password has expired may do nothing but
+	// change it.
+	passwordExpired, err := checkPasswordExpiry(a.srv.statePool.SystemState(), entity, req)
+	if err != nil {
+		return fail, errors.Trace(err)
+	}
+	if passwordExpired {
+		authedApi = newPasswordExpiredRoot(authedApi)
+	}
+
 	if a.reqNotifier != nil {
 		a.reqNotifier.login(entity.Tag().String())
 	}
@@ -145,8 +177,9 @@ func (a *admin) doLogin(req params.LoginRequest, loginVersion int) (params.Login
 	// Send back user info if user
 	if isUser {
 		maybeUserInfo = &params.AuthUserInfo{
-			Identity:       entity.Tag().String(),
-			LastConnection: lastConnection,
+			Identity:        entity.Tag().String(),
+			LastConnection:  lastConnection,
+			PasswordExpired: passwordExpired,
 		}
 	}
 
@@ -216,6 +249,104 @@ func (a *admin) checkCredsOfStateServerMachine(req params.LoginRequest) (state.E
 	return nil, errors.Trace(common.ErrBadCreds)
 }
 
+// applyTokenRoot restricts the API served to users that logged in
+// with an API token to the calls the token allows.
+func (a *admin) applyTokenRoot(finder rpc.MethodFinder, entity state.Entity, req params.LoginRequest) rpc.MethodFinder {
+	name, _, ok := authentication.ParseTokenCredentials(req.Credentials)
+	if !ok {
+		return finder
+	}
+	userTag, ok := entity.Tag().(names.UserTag)
+	if !ok {
+		return finder
+	}
+	st := a.root.state
+	return newTokenRoot(finder, func() (apiToken, error) {
+		token, err := st.APIToken(userTag, name)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return token, nil
+	})
+}
+
+// isTokenLogin returns whether the login request is made with an API
+// token rather than a password.
+func isTokenLogin(req params.LoginRequest) bool {
+	_, _, ok := authentication.ParseTokenCredentials(req.Credentials)
+	return ok
+}
+
+// localUser returns the local user that the given entity logged in as,
+// or nil if the entity is not a local user.
+func localUser(entity state.Entity) *state.User {
+	switch entity := entity.(type) {
+	case *state.User:
+		return entity
+	case *environmentUserEntity:
+		return entity.user
+	}
+	return nil
+}
+
+// recordFailedLogin records a failed password login by a local user,
+// locking them out if they have failed to log in too many times
+// according to the password policy of the state server, whose state
+// is given. Unknown users have nothing to lock out, and failures to
+// record the login are only logged, so that they don't hide the reason
+// the login failed. It is used for logins both to the API and to the
+// HTTP endpoints.
+func recordFailedLogin(st *state.State, req params.LoginRequest, loginErr error) {
+	if errors.Cause(loginErr) != common.ErrBadCreds || isTokenLogin(req) {
+		return
+	}
+	tag, err := names.ParseUserTag(req.AuthTag)
+	if err != nil || !tag.IsLocal() {
+		return
+	}
+	user, err := st.User(tag)
+	if err != nil {
+		return
+	}
+	if user.IsDirectoryUser() || user.IsDisabled() || user.IsLockedOut() {
+		return
+	}
+	policy, err := st.PasswordPolicy()
+	if err != nil {
+		logger.Errorf("cannot record failed login of user %q: %v", tag.Name(), err)
+		return
+	}
+	lockedOut, err := user.RecordFailedLogin(policy.LockoutAttempts, policy.LockoutWindow)
+	if err != nil {
+		logger.Errorf("%v", err)
+		return
+	}
+	if lockedOut {
+		logger.Infof("user %q locked out after %d failed logins", tag.Name(), policy.LockoutAttempts)
+	}
+}
+
+// checkPasswordExpiry forgets the failed logins of an entity that has
+// just logged in with the given request, if it is a local user that
+// logged in with their password, and returns whether the password has
+// expired according to the password policy of the state server, whose
+// state is given. It is used for logins both to the API and to the
+// HTTP endpoints.
+func checkPasswordExpiry(st *state.State, entity state.Entity, req params.LoginRequest) (bool, error) {
+	user := localUser(entity)
+	if user == nil || isTokenLogin(req) {
+		return false, nil
+	}
+	if err := user.ResetFailedLogins(); err != nil {
+		logger.Warningf("%v", err)
+	}
+	policy, err := st.PasswordPolicy()
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	return user.PasswordExpired(policy.MaxAge, time.Now()), nil
+}
+
 func (a *admin) maintenanceInProgress() bool {
 	if a.srv.validator == nil {
 		return false
@@ -256,7 +387,7 @@ func checkCreds(st *state.State, req params.LoginRequest, lookForEnvUser bool, a
 			return nil, nil, errors.Trace(err)
 		}
 	}
-	var entityFinder authentication.EntityFinder = st
+	var entityFinder authentication.EntityFinder = userEntityFinder{st}
 	if lookForEnvUser {
 		// When looking up environment users, use a custom
 		// entity finder that looks up both the local user (if the user
@@ -321,6 +452,155 @@ func (f environmentUserEntityFinder) FindEntity(tag names.Tag) (state.Entity, er
 	return u, nil
 }
 
+// FindAPIToken implements authentication.APITokenFinder.
+func (f environmentUserEntityFinder) FindAPIToken(owner names.UserTag, name string) (authentication.APIToken, error) {
+	return userEntityFinder{f.st}.FindAPIToken(owner, name)
+}
+
+// LoginEnvironUUID implements authentication.APITokenFinder.
+func (f environmentUserEntityFinder) LoginEnvironUUID() string {
+	return f.st.EnvironUUID()
+}
+
+// IsDirectoryUser implements authentication.DirectoryUserProvisioner.
+func (f environmentUserEntityFinder) IsDirectoryUser(tag names.UserTag) (bool, error) {
+	return userEntityFinder{f.st}.IsDirectoryUser(tag)
+}
+
+// ProvisionDirectoryUser implements authentication.DirectoryUserProvisioner
+// by ensuring that the directory user exists as a local user. Directory
+// users are only added to environments whose own ldap-group-access maps
+// one of their groups to an access level. The access of users added that
+// way follows their groups at every login, and they are removed from the
+// environment once their groups no longer give them access. Users added
+// to the environment explicitly keep the access they were given.
+func (f environmentUserEntityFinder) ProvisionDirectoryUser(tag names.UserTag, displayName string, groups []string) error {
+	if err := ensureDirectoryUser(f.st, tag, displayName); err != nil {
+		return errors.Trace(err)
+	}
+	envCfg, err := f.st.EnvironConfig()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	groupAccess, err := ldapGroupAccess(envCfg)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	access, ok := authentication.GroupsAccess(groupAccess, groups)
+	envUser, err := f.st.EnvironmentUser(tag)
+	if err == nil {
+		if envUser.Access() == access {
+			return nil
+		}
+		err := f.st.UpdateDirectoryEnvironmentUser(tag, access)
+		if errors.IsNotFound(err) {
+			// The user was added to the environment explicitly.
+			return nil
+		}
+		return errors.Trace(err)
+	} else if !errors.IsNotFound(err) {
+		return errors.Trace(err)
+	}
+	if !ok {
+		return nil
+	}
+	env, err := f.st.Environment()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	_, err = f.st.AddDirectoryEnvironmentUser(state.EnvUserSpec{
+		User:        tag,
+		CreatedBy:   env.Owner(),
+		DisplayName: displayName,
+		Access:      access,
+	})
+	if errors.IsAlreadyExists(err) {
+		// The user was added before, and has since been removed, or
+		// another login got there first.
+		return nil
+	}
+	return errors.Trace(err)
+}
+
+// userEntityFinder implements EntityFinder by looking up entities in
+// the state, authentication.DirectoryUserProvisioner by creating local
+// users for directory users, and authentication.APITokenFinder. It is
+// used for logins to the state server itself rather than to an
+// environment.
+type userEntityFinder struct {
+	*state.State
+}
+
+// FindAPIToken implements authentication.APITokenFinder.
+func (f userEntityFinder) FindAPIToken(owner names.UserTag, name string) (authentication.APIToken, error) {
+	user, err := f.User(owner)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if user.IsDisabled() {
+		return nil, errors.Unauthorizedf("user %q is disabled", owner.Name())
+	}
+	if user.IsLockedOut() {
+		return nil, errors.Unauthorizedf("user %q is locked out", owner.Name())
+	}
+	token, err := f.APIToken(owner, name)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return token, nil
+}
+
+// LoginEnvironUUID implements authentication.APITokenFinder. Logins to
+// the state server don't involve any environment.
+func (f userEntityFinder) LoginEnvironUUID() string {
+	return ""
+}
+
+// IsDirectoryUser implements authentication.DirectoryUserProvisioner.
+func (f userEntityFinder) IsDirectoryUser(tag names.UserTag) (bool, error) {
+	user, err := f.User(tag)
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	return user.IsDirectoryUser(), nil
+}
+
+// ProvisionDirectoryUser implements authentication.DirectoryUserProvisioner.
+// Logins to the state server don't involve any environment, so the
+// groups are ignored.
+func (f userEntityFinder) ProvisionDirectoryUser(tag names.UserTag, displayName string, _ []string) error {
+	return errors.Trace(ensureDirectoryUser(f.State, tag, displayName))
+}
+
+// ensureDirectoryUser adds the given directory user as a local user if
+// they are not already one. It returns an error satisfying
+// errors.IsUnauthorized if the user has been disabled.
+func ensureDirectoryUser(st *state.State, tag names.UserTag, displayName string) error {
+	user, err := st.User(tag)
+	if errors.IsNotFound(err) {
+		var env *state.Environment
+		env, err = st.StateServerEnvironment()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		user, err = st.AddDirectoryUser(tag.Name(), displayName, env.Owner().Name())
+		if errors.IsAlreadyExists(err) {
+			// Another login got there first.
+			user, err = st.User(tag)
+		}
+	}
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if !user.IsDirectoryUser() {
+		return errors.Errorf("user %q is not a directory user", tag.Name())
+	}
+	if user.IsDisabled() {
+		return errors.Unauthorizedf("user %q is disabled", tag.Name())
+	}
+	return nil
+}
+
 var _ loginEntity = &environmentUserEntity{}
 
 // environmentUserEntity encapsulates an environment user
@@ -449,7 +729,14 @@ func startPingerIfAgent(root *apiHandler, entity state.Entity) error {
 		}
 	}
 	pingTimeout := newPingTimeout(action, maxClientPingInterval)
-	return root.getResources().RegisterNamed("pingTimeout", pingTimeout)
+	if err := root.getResources().RegisterNamed("pingTimeout", pingTimeout); err != nil {
+		return err
+	}
+
+	// Once the environment is migrated to another controller, the
+	// agent's connection is closed so that it reconnects there.
+	root.getResources().Register(newMigrationRedirector(root.state, action, redirectGracePeriod))
+	return nil
 }
 
 // errRoot implements the API that a client first sees
@@ -20,6 +20,8 @@ import (
 	"github.com/juju/juju/api"
 	apitesting "github.com/juju/juju/api/testing"
 	"github.com/juju/juju/apiserver"
+	"github.com/juju/juju/apiserver/authentication"
+	"github.com/juju/juju/apiserver/authentication/ldap"
 	"github.com/juju/juju/apiserver/params"
 	jujutesting "github.com/juju/juju/juju/testing"
 	"github.com/juju/juju/network"
@@ -512,6 +514,492 @@ func (s *loginSuite) TestNonEnvironUserLoginFails(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, "invalid entity name or password")
 }
 
+func (s *loginSuite) TestEnvironUserAccessRestrictsCalls(c *gc.C) {
+	info, cleanup := s.setupServerWithValidator(c, nil)
+	defer cleanup()
+	user := s.Factory.MakeUser(c, &factory.UserParams{Password: "dummy-password", NoEnvUser: true})
+	s.Factory.MakeEnvUser(c, &factory.EnvUserParams{
+		User:   user.UserTag().Canonical(),
+		Access: state.EnvOperatorAccess,
+	})
+	info.Password = "dummy-password"
+	info.Tag = user.UserTag()
+	st, err := api.Open(info, fastDialOpts)
+	c.Assert(err, jc.ErrorIsNil)
+	defer st.Close()
+
+	var statusResult params.FullStatus
+	err = st.APICall("Client", 0, "", "FullStatus", params.StatusParams{}, &statusResult)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = st.APICall("Client", 0, "", "Resolved", params.Resolved{UnitName: "foo/0"}, nil)
+	c.Assert(err, gc.ErrorMatches, `unit "foo/0" not found`)
+
+	err = st.APICall("Client", 0, "", "DestroyEnvironment", nil, nil)
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+	c.Assert(params.ErrCode(err), gc.Equals, params.CodeUnauthorized)
+}
+
+func (s *loginSuite) TestEnvironUserAccessChangesApplyToOpenConnections(c *gc.C) {
+	s.PatchValue(apiserver.AccessCacheTTL, time.Duration(0))
+	info, cleanup := s.setupServerWithValidator(c, nil)
+	defer cleanup()
+	user := s.Factory.MakeUser(c, &factory.UserParams{Password: "dummy-password", NoEnvUser: true})
+	envUser := s.Factory.MakeEnvUser(c, &factory.EnvUserParams{
+		User:   user.UserTag().Canonical(),
+		Access: state.EnvOperatorAccess,
+	})
+	info.Password = "dummy-password"
+	info.Tag = user.UserTag()
+	st, err := api.Open(info, fastDialOpts)
+	c.Assert(err, jc.ErrorIsNil)
+	defer st.Close()
+
+	err = st.APICall("Client", 0, "", "Resolved", params.Resolved{UnitName: "foo/0"}, nil)
+	c.Assert(err, gc.ErrorMatches, `unit "foo/0" not found`)
+
+	// Lowering the user's access denies their next call.
+	err = envUser.SetAccess(state.EnvViewerAccess)
+	c.Assert(err, jc.ErrorIsNil)
+	err = st.APICall("Client", 0, "", "Resolved", params.Resolved{UnitName: "foo/0"}, nil)
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+
+	var statusResult params.FullStatus
+	err = st.APICall("Client", 0, "", "FullStatus", params.StatusParams{}, &statusResult)
+	c.Assert(err, jc.ErrorIsNil)
+
+	// Revoking it altogether denies every call.
+	err = s.State.RemoveEnvironmentUser(user.UserTag())
+	c.Assert(err, jc.ErrorIsNil)
+	err = st.APICall("Client", 0, "", "FullStatus", params.StatusParams{}, &statusResult)
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+	c.Assert(params.ErrCode(err), gc.Equals, params.CodeUnauthorized)
+}
+
+// fakeDirectory is an authentication.Directory holding a single user,
+// bob, with the password "bob-password".
+type fakeDirectory struct {
+	groups []string
+}
+
+func (d *fakeDirectory) Authenticate(name, password string) (authentication.DirectoryUser, error) {
+	if name != "bob" {
+		return authentication.DirectoryUser{}, errors.NotFoundf("user %q", name)
+	}
+	if password != "bob-password" {
+		return authentication.DirectoryUser{}, errors.Unauthorizedf("invalid password")
+	}
+	return authentication.DirectoryUser{
+		DisplayName: "Bob Brown",
+		Groups:      d.groups,
+	}, nil
+}
+
+func (s *loginSuite) setUpDirectory(c *gc.C) *fakeDirectory {
+	err := s.State.UpdateEnvironConfig(map[string]interface{}{
+		"ldap-url":          "ldaps://ldap.example.com",
+		"ldap-user-base-dn": "ou=people,dc=example,dc=com",
+		"ldap-group-access": "developers=operator juju-admins=admin",
+	}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	directory := &fakeDirectory{groups: []string{"staff", "developers"}}
+	s.PatchValue(apiserver.NewDirectory, func(config ldap.Config) (authentication.Directory, error) {
+		c.Check(config.URL, gc.Equals, "ldaps://ldap.example.com")
+		return directory, nil
+	})
+	return directory
+}
+
+func (s *loginSuite) TestDirectoryUserLogin(c *gc.C) {
+	s.setUpDirectory(c)
+	info, cleanup := s.setupServerWithValidator(c, nil)
+	defer cleanup()
+	info.Tag = names.NewLocalUserTag("bob")
+	info.Password = "bob-password"
+	st, err := api.Open(info, fastDialOpts)
+	c.Assert(err, jc.ErrorIsNil)
+	defer st.Close()
+
+	// Bob's user records were created from the directory.
+	user, err := s.State.User(names.NewLocalUserTag("bob"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(user.IsDirectoryUser(), jc.IsTrue)
+	c.Assert(user.DisplayName(), gc.Equals, "Bob Brown")
+	envUser, err := s.State.EnvironmentUser(names.NewLocalUserTag("bob"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(envUser.Access(), gc.Equals, state.EnvOperatorAccess)
+
+	// Bob only has the access his groups give him.
+	err = st.APICall("Client", 0, "", "DestroyEnvironment", nil, nil)
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *loginSuite) TestDirectoryUserAccessFollowsGroups(c *gc.C) {
+	directory := s.setUpDirectory(c)
+	info, cleanup := s.setupServerWithValidator(c, nil)
+	defer cleanup()
+	info.Tag = names.NewLocalUserTag("bob")
+	info.Password = "bob-password"
+	st, err := api.Open(info, fastDialOpts)
+	c.Assert(err, jc.ErrorIsNil)
+	st.Close()
+
+	// Bob's access changes with his groups at his next login.
+	directory.groups = []string{"juju-admins"}
+	st, err = api.Open(info, fastDialOpts)
+	c.Assert(err, jc.ErrorIsNil)
+	st.Close()
+	envUser, err := s.State.EnvironmentUser(names.NewLocalUserTag("bob"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(envUser.Access(), gc.Equals, state.EnvAdminAccess)
+
+	// Once bob has left every group with access, he can't log in.
+	directory.groups = nil
+	_, err = api.Open(info, fastDialOpts)
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *loginSuite) TestDirectoryUserRemovedWhenGroupsNoLongerGiveAccess(c *gc.C) {
+	s.setUpDirectory(c)
+	info, cleanup := s.setupServerWithValidator(c, nil)
+	defer cleanup()
+	info.Tag = names.NewLocalUserTag("bob")
+	info.Password = "bob-password"
+	envState := s.Factory.MakeEnvironment(c, nil)
+	defer envState.Close()
+	err := envState.UpdateEnvironConfig(map[string]interface{}{
+		"ldap-group-access": "developers=viewer",
+	}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	info.EnvironTag = envState.EnvironTag()
+	st, err := api.Open(info, fastDialOpts)
+	c.Assert(err, jc.ErrorIsNil)
+	st.Close()
+
+	// The environment no longer gives bob's groups access, so he is
+	// removed from it at his next login.
+	err = envState.UpdateEnvironConfig(map[string]interface{}{
+		"ldap-group-access": "juju-admins=viewer",
+	}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = api.Open(info, fastDialOpts)
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+	_, err = envState.EnvironmentUser(names.NewLocalUserTag("bob"))
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+
+	// He is added back once it does again.
+	err = envState.UpdateEnvironConfig(map[string]interface{}{
+		"ldap-group-access": "developers=deployer",
+	}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	st, err = api.Open(info, fastDialOpts)
+	c.Assert(err, jc.ErrorIsNil)
+	st.Close()
+	envUser, err := envState.EnvironmentUser(names.NewLocalUserTag("bob"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(envUser.Access(), gc.Equals, state.EnvDeployerAccess)
+}
+
+func (s *loginSuite) TestDirectoryUserExplicitAccessKept(c *gc.C) {
+	directory := s.setUpDirectory(c)
+	_, err := s.State.AddDirectoryUser("bob", "Bob Brown", "admin")
+	c.Assert(err, jc.ErrorIsNil)
+	s.Factory.MakeEnvUser(c, &factory.EnvUserParams{
+		User:   "bob",
+		Access: state.EnvViewerAccess,
+	})
+	info, cleanup := s.setupServerWithValidator(c, nil)
+	defer cleanup()
+	info.Tag = names.NewLocalUserTag("bob")
+	info.Password = "bob-password"
+
+	// Access granted explicitly is not changed by bob's groups.
+	directory.groups = []string{"juju-admins"}
+	st, err := api.Open(info, fastDialOpts)
+	c.Assert(err, jc.ErrorIsNil)
+	st.Close()
+	envUser, err := s.State.EnvironmentUser(names.NewLocalUserTag("bob"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(envUser.Access(), gc.Equals, state.EnvViewerAccess)
+}
+
+func (s *loginSuite) TestDirectoryUserRevokedAccessKept(c *gc.C) {
+	s.setUpDirectory(c)
+	info, cleanup := s.setupServerWithValidator(c, nil)
+	defer cleanup()
+	info.Tag = names.NewLocalUserTag("bob")
+	info.Password = "bob-password"
+	st, err := api.Open(info, fastDialOpts)
+	c.Assert(err, jc.ErrorIsNil)
+	st.Close()
+
+	// Once removed from the environment, bob is not added back.
+	err = s.State.RemoveEnvironmentUser(names.NewLocalUserTag("bob"))
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = api.Open(info, fastDialOpts)
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+	_, err = s.State.EnvironmentUser(names.NewLocalUserTag("bob"))
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *loginSuite) TestDirectoryUserOnlyAddedToEnvironmentsThatOptIn(c *gc.C) {
+	s.setUpDirectory(c)
+	info, cleanup := s.setupServerWithValidator(c, nil)
+	defer cleanup()
+	info.Tag = names.NewLocalUserTag("bob")
+	info.Password = "bob-password"
+
+	// The environment has no ldap-group-access of its own.
+	envState := s.Factory.MakeEnvironment(c, nil)
+	defer envState.Close()
+	info.EnvironTag = envState.EnvironTag()
+	_, err := api.Open(info, fastDialOpts)
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+	_, err = envState.EnvironmentUser(names.NewLocalUserTag("bob"))
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+
+	err = envState.UpdateEnvironConfig(map[string]interface{}{
+		"ldap-group-access": "developers=viewer",
+	}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	st, err := api.Open(info, fastDialOpts)
+	c.Assert(err, jc.ErrorIsNil)
+	st.Close()
+	envUser, err := envState.EnvironmentUser(names.NewLocalUserTag("bob"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(envUser.Access(), gc.Equals, state.EnvViewerAccess)
+}
+
+func (s *loginSuite) TestDirectoryUserBadPassword(c *gc.C) {
+	s.setUpDirectory(c)
+	info, cleanup := s.setupServerWithValidator(c, nil)
+	defer cleanup()
+	info.Tag = names.NewLocalUserTag("bob")
+	info.Password = "wrong"
+	_, err := api.Open(info, fastDialOpts)
+	c.Assert(err, gc.ErrorMatches, "invalid entity name or password")
+	_, err = s.State.User(names.NewLocalUserTag("bob"))
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *loginSuite) TestLocalUserLoginWithDirectory(c *gc.C) {
+	s.setUpDirectory(c)
+	info, cleanup := s.setupServerWithValidator(c, nil)
+	defer cleanup()
+	info.Tag = s.AdminUserTag(c)
+	info.Password = "dummy-secret"
+	st, err := api.Open(info, fastDialOpts)
+	c.Assert(err, jc.ErrorIsNil)
+	st.Close()
+}
+
+func (s *loginSuite) addAPIToken(c *gc.C, spec state.APITokenSpec) string {
+	user := s.Factory.MakeUser(c, &factory.UserParams{Name: "ci-bot", Password: "ci-password"})
+	spec.Owner = user.UserTag()
+	spec.Name = "jenkins"
+	_, secret, err := s.State.AddAPIToken(spec)
+	c.Assert(err, jc.ErrorIsNil)
+	return authentication.FormatTokenCredentials("jenkins", secret)
+}
+
+func (s *loginSuite) TestAPITokenLogin(c *gc.C) {
+	credentials := s.addAPIToken(c, state.APITokenSpec{})
+	info, cleanup := s.setupServerWithValidator(c, nil)
+	defer cleanup()
+	info.Tag = names.NewLocalUserTag("ci-bot")
+	info.Password = credentials
+	st, err := api.Open(info, fastDialOpts)
+	c.Assert(err, jc.ErrorIsNil)
+	st.Close()
+
+	token, err := s.State.APIToken(names.NewLocalUserTag("ci-bot"), "jenkins")
+	c.Assert(err, jc.ErrorIsNil)
+	lastUsed, err := token.LastUsed()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(lastUsed.IsZero(), jc.IsFalse)
+
+	// The user's password still works.
+	info.Password = "ci-password"
+	st, err = api.Open(info, fastDialOpts)
+	c.Assert(err, jc.ErrorIsNil)
+	st.Close()
+}
+
+func (s *loginSuite) TestAPITokenLoginRevoked(c *gc.C) {
+	credentials := s.addAPIToken(c, state.APITokenSpec{})
+	err := s.State.RemoveAPIToken(names.NewLocalUserTag("ci-bot"), "jenkins")
+	c.Assert(err, jc.ErrorIsNil)
+	info, cleanup := s.setupServerWithValidator(c, nil)
+	defer cleanup()
+	info.Tag = names.NewLocalUserTag("ci-bot")
+	info.Password = credentials
+	_, err = api.Open(info, fastDialOpts)
+	c.Assert(err, gc.ErrorMatches, "invalid entity name or password")
+}
+
+func (s *loginSuite) TestAPITokenLoginExpired(c *gc.C) {
+	credentials := s.addAPIToken(c, state.APITokenSpec{
+		Expires: time.Now().Add(-time.Hour),
+	})
+	info, cleanup := s.setupServerWithValidator(c, nil)
+	defer cleanup()
+	info.Tag = names.NewLocalUserTag("ci-bot")
+	info.Password = credentials
+	_, err := api.Open(info, fastDialOpts)
+	c.Assert(err, gc.ErrorMatches, "invalid entity name or password")
+}
+
+func (s *loginSuite) TestAPITokenLoginOtherEnvironment(c *gc.C) {
+	credentials := s.addAPIToken(c, state.APITokenSpec{
+		Environments: []string{"deadbeef-0bad-400d-8000-4b1d0d06f00d"},
+	})
+	info, cleanup := s.setupServerWithValidator(c, nil)
+	defer cleanup()
+	info.Tag = names.NewLocalUserTag("ci-bot")
+	info.Password = credentials
+	_, err := api.Open(info, fastDialOpts)
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *loginSuite) TestAPITokenLoginFacadeScope(c *gc.C) {
+	credentials := s.addAPIToken(c, state.APITokenSpec{
+		Facades: []string{"Client"},
+	})
+	info, cleanup := s.setupServerWithValidator(c, nil)
+	defer cleanup()
+	info.Tag = names.NewLocalUserTag("ci-bot")
+	info.Password = credentials
+	st, err := api.Open(info, fastDialOpts)
+	c.Assert(err, jc.ErrorIsNil)
+	defer st.Close()
+
+	_, err = st.Client().Status(nil)
+	c.Assert(err, jc.ErrorIsNil)
+	err = st.APICall("KeyManager", 0, "", "ListKeys", nil, nil)
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *loginSuite) TestAPITokenRemovedWhileConnected(c *gc.C) {
+	s.PatchValue(apiserver.TokenCacheTTL, time.Duration(0))
+	credentials := s.addAPIToken(c, state.APITokenSpec{})
+	info, cleanup := s.setupServerWithValidator(c, nil)
+	defer cleanup()
+	info.Tag = names.NewLocalUserTag("ci-bot")
+	info.Password = credentials
+	st, err := api.Open(info, fastDialOpts)
+	c.Assert(err, jc.ErrorIsNil)
+	defer st.Close()
+
+	_, err = st.Client().Status(nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.State.RemoveAPIToken(names.NewLocalUserTag("ci-bot"), "jenkins")
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = st.Client().Status(nil)
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *loginSuite) TestAPITokenCannotMakeTokensOrChangePassword(c *gc.C) {
+	credentials := s.addAPIToken(c, state.APITokenSpec{})
+	info, cleanup := s.setupServerWithValidator(c, nil)
+	defer cleanup()
+	info.Tag = names.NewLocalUserTag("ci-bot")
+	info.Password = credentials
+	st, err := api.Open(info, fastDialOpts)
+	c.Assert(err, jc.ErrorIsNil)
+	defer st.Close()
+
+	err = st.APICall("UserManager", 0, "", "AddAPIToken", params.AddAPITokens{}, nil)
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+	err = st.APICall("UserManager", 0, "", "SetPassword", params.EntityPasswords{}, nil)
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *loginSuite) TestLoginLockout(c *gc.C) {
+	err := s.State.UpdateEnvironConfig(map[string]interface{}{
+		"login-lockout-attempts": 2,
+	}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	user := s.Factory.MakeUser(c, &factory.UserParams{Name: "bob", Password: "bob-password"})
+	info, cleanup := s.setupServerWithValidator(c, nil)
+	defer cleanup()
+	info.Tag = user.UserTag()
+
+	info.Password = "wrong"
+	for i := 0; i < 2; i++ {
+		_, err = api.Open(info, fastDialOpts)
+		c.Assert(err, gc.ErrorMatches, "invalid entity name or password")
+	}
+	err = user.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(user.IsLockedOut(), jc.IsTrue)
+
+	// The right password no longer works.
+	info.Password = "bob-password"
+	_, err = api.Open(info, fastDialOpts)
+	c.Assert(err, gc.ErrorMatches, "invalid entity name or password")
+
+	// Until the user is enabled again.
+	err = user.Enable()
+	c.Assert(err, jc.ErrorIsNil)
+	st, err := api.Open(info, fastDialOpts)
+	c.Assert(err, jc.ErrorIsNil)
+	st.Close()
+}
+
+func (s *loginSuite) TestSuccessfulLoginResetsFailures(c *gc.C) {
+	err := s.State.UpdateEnvironConfig(map[string]interface{}{
+		"login-lockout-attempts": 2,
+	}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	user := s.Factory.MakeUser(c, &factory.UserParams{Name: "bob", Password: "bob-password"})
+	info, cleanup := s.setupServerWithValidator(c, nil)
+	defer cleanup()
+	info.Tag = user.UserTag()
+
+	for _, password := range []string{"wrong", "bob-password", "wrong"} {
+		info.Password = password
+		st, err := api.Open(info, fastDialOpts)
+		if err == nil {
+			st.Close()
+		}
+	}
+	err = user.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(user.IsLockedOut(), jc.IsFalse)
+}
+
+func (s *loginSuite) TestLoginPasswordExpired(c *gc.C) {
+	err := s.State.UpdateEnvironConfig(map[string]interface{}{
+		"password-max-age": "1ns",
+	}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	user := s.Factory.MakeUser(c, &factory.UserParams{Name: "bob", Password: "bob-password"})
+	// Creation times are rounded to the second, so wait long enough
+	// for the password to be older than its maximum age.
+	time.Sleep(time.Second)
+	info, cleanup := s.setupServerWithValidator(c, nil)
+	defer cleanup()
+	info.Tag = user.UserTag()
+	info.Password = "bob-password"
+	st, err := api.Open(info, fastDialOpts)
+	c.Assert(err, jc.ErrorIsNil)
+	defer st.Close()
+
+	_, err = st.Client().Status(nil)
+	c.Assert(err, gc.ErrorMatches, `password expired - change it with "juju change-user-password" and log in again`)
+
+	var results params.ErrorResults
+	err = st.APICall("UserManager", 0, "", "SetPassword", params.EntityPasswords{
+		Changes: []params.EntityPassword{{
+			Tag:      user.Tag().String(),
+			Password: "new-password",
+		}},
+	}, &results)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.OneError(), jc.ErrorIsNil)
+}
+
 func (s *loginV0Suite) TestLoginReportsEnvironTag(c *gc.C) {
 	st, cleanup := s.setupServer(c)
 	defer cleanup()
@@ -378,6 +378,21 @@ func (srv *Server) run(lis net.Listener) {
 			ctxt: strictCtxt,
 		},
 	)
+	handleAll(mux, "/environment/:envuuid/migration/charms",
+		&migrationCharmsHandler{
+			ctxt: strictCtxt,
+		},
+	)
+	handleAll(mux, "/environment/:envuuid/services/:service/resources/:name",
+		&resourcesUploadHandler{
+			ctxt: httpCtxt,
+		},
+	)
+	handleAll(mux, "/environment/:envuuid/units/:unit/resources/:name",
+		&resourcesDownloadHandler{
+			ctxt: httpCtxt,
+		},
+	)
 	handleAll(mux, "/environment/:envuuid/api", http.HandlerFunc(srv.apiHandler))
 
 	handleAll(mux, "/environment/:envuuid/images/:kind/:series/:arch/:filename",
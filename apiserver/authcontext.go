@@ -13,8 +13,10 @@ import (
 	"gopkg.in/macaroon-bakery.v1/httpbakery"
 
 	"github.com/juju/juju/apiserver/authentication"
+	"github.com/juju/juju/apiserver/authentication/ldap"
 	"github.com/juju/juju/apiserver/common"
 	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/environs/config"
 	"github.com/juju/juju/state"
 )
 
@@ -25,6 +27,7 @@ type authContext struct {
 
 	agentAuth authentication.AgentAuthenticator
 	userAuth  authentication.UserAuthenticator
+	tokenAuth authentication.TokenAuthenticator
 
 	// macaroonAuthOnce guards the fields below it.
 	macaroonAuthOnce   sync.Once
@@ -43,6 +46,13 @@ func newAuthContext(srv *Server) *authContext {
 // by choosing the right kind of authentication for the given
 // tag.
 func (ctxt *authContext) Authenticate(entityFinder authentication.EntityFinder, tag names.Tag, req params.LoginRequest) (state.Entity, error) {
+	if tag != nil && tag.Kind() == names.UserTagKind {
+		// Users may log in with an API token instead of their
+		// password, whichever way their password is checked.
+		if _, _, ok := authentication.ParseTokenCredentials(req.Credentials); ok {
+			return ctxt.tokenAuth.Authenticate(entityFinder, tag, req)
+		}
+	}
 	auth, err := ctxt.authenticatorForTag(tag)
 	if err != nil {
 		return nil, errors.Trace(err)
@@ -68,7 +78,7 @@ func (ctxt *authContext) authenticatorForTag(tag names.Tag) (authentication.Enti
 	case names.UnitTagKind, names.MachineTagKind:
 		return &ctxt.agentAuth, nil
 	case names.UserTagKind:
-		return &ctxt.userAuth, nil
+		return ctxt.directoryAuth(), nil
 	default:
 		return nil, errors.Annotatef(common.ErrBadRequest, "unexpected login entity tag")
 	}
@@ -86,6 +96,78 @@ func (ctxt *authContext) macaroonAuth() (authentication.EntityAuthenticator, err
 	return ctxt._macaroonAuth, nil
 }
 
+// directoryAuth returns an authenticator that authenticates users
+// against the directory configured in the state server environment, or
+// the password authenticator if no directory is configured. The
+// configuration is read at every login, so changes to it take effect
+// straight away.
+func (ctxt *authContext) directoryAuth() authentication.EntityAuthenticator {
+	auth, err := newDirectoryAuth(ctxt.srv.statePool.SystemState())
+	if err == errDirectoryAuthNotConfigured {
+		return &ctxt.userAuth
+	}
+	if err != nil {
+		// Fall back to password authentication so that local
+		// users can still log in to fix the configuration.
+		logger.Errorf("cannot use directory authentication: %v", err)
+		return &ctxt.userAuth
+	}
+	return auth
+}
+
+var errDirectoryAuthNotConfigured = errors.New("directory authentication is not configured")
+
+// newDirectory returns the directory described by the given
+// configuration. It is a variable so tests can use a fake directory.
+var newDirectory = func(config ldap.Config) (authentication.Directory, error) {
+	return ldap.NewDirectory(config)
+}
+
+// newDirectoryAuth returns an authenticator that can authenticate
+// users against a directory. This is just a helper function for
+// authCtxt.directoryAuth.
+func newDirectoryAuth(st *state.State) (*authentication.DirectoryAuthenticator, error) {
+	envCfg, err := st.EnvironConfig()
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot get environment config")
+	}
+	if envCfg.LDAPURL() == "" {
+		return nil, errDirectoryAuthNotConfigured
+	}
+	groupAccess, err := ldapGroupAccess(envCfg)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	directory, err := newDirectory(ldap.Config{
+		URL:          envCfg.LDAPURL(),
+		BindDN:       envCfg.LDAPBindDN(),
+		BindPassword: envCfg.LDAPBindPassword(),
+		UserBaseDN:   envCfg.LDAPUserBaseDN(),
+		UserFilter:   envCfg.LDAPUserFilter(),
+	})
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot make LDAP directory")
+	}
+	return &authentication.DirectoryAuthenticator{
+		Directory:   directory,
+		GroupAccess: groupAccess,
+	}, nil
+}
+
+// ldapGroupAccess returns the access the environment's configuration
+// gives to members of each LDAP group.
+func ldapGroupAccess(envCfg *config.Config) (map[string]state.EnvUserAccess, error) {
+	groupAccess := make(map[string]state.EnvUserAccess)
+	for group, access := range envCfg.LDAPGroupAccess() {
+		access := state.EnvUserAccess(access)
+		if err := access.Validate(); err != nil {
+			return nil, errors.Annotatef(err, "LDAP group %q", group)
+		}
+		groupAccess[group] = access
+	}
+	return groupAccess, nil
+}
+
 var errMacaroonAuthNotConfigured = errors.New("macaroon authentication is not configured")
 
 // newMacaroonAuth returns an authenticator that can authenticate
@@ -0,0 +1,142 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package authentication
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+)
+
+var logger = loggo.GetLogger("juju.apiserver.authentication")
+
+// Directory is an external directory of users, such as an LDAP server,
+// that users may log in with.
+type Directory interface {
+	// Authenticate checks the given user's password against the
+	// directory and returns the user's details. It returns an error
+	// satisfying errors.IsUnauthorized if the password is wrong, and
+	// one satisfying errors.IsNotFound if there is no such user.
+	Authenticate(name, password string) (DirectoryUser, error)
+}
+
+// DirectoryUser describes a user found in a Directory.
+type DirectoryUser struct {
+	// DisplayName holds the user's full name, if the directory
+	// knows it.
+	DisplayName string
+
+	// Groups holds the names of the directory groups the user is
+	// a member of.
+	Groups []string
+}
+
+// DirectoryUserProvisioner is implemented by entity finders that can
+// create and update the records of directory users when they log in.
+type DirectoryUserProvisioner interface {
+	// IsDirectoryUser returns whether the given user authenticates
+	// against the directory. It returns an error satisfying
+	// errors.IsNotFound if there is no such user yet.
+	IsDirectoryUser(tag names.UserTag) (bool, error)
+
+	// ProvisionDirectoryUser ensures that the given directory user
+	// exists. The user is added to the environment being logged in
+	// to only if that environment maps one of the user's directory
+	// groups to an access level. The access of a user added that way
+	// is brought into line with their groups at every login; access
+	// granted explicitly is never changed. It returns an error
+	// satisfying errors.IsUnauthorized if the user has been disabled.
+	ProvisionDirectoryUser(tag names.UserTag, displayName string, groups []string) error
+}
+
+// DirectoryAuthenticator authenticates local users against an external
+// Directory, creating their user records the first time they log in and
+// updating their access to the environment at every login.
+// Only members of the directory groups given access may log in. Local
+// users that are not directory users, such as the admin user created at
+// bootstrap, continue to log in with their juju password.
+type DirectoryAuthenticator struct {
+	UserAuthenticator
+
+	// Directory holds the directory that users are authenticated
+	// against.
+	Directory Directory
+
+	// GroupAccess maps directory group names to the access their
+	// members have, as configured in the state server environment.
+	// Directory users in none of the groups may not log in.
+	GroupAccess map[string]state.EnvUserAccess
+}
+
+var _ EntityAuthenticator = (*DirectoryAuthenticator)(nil)
+
+// Authenticate authenticates the provided entity and returns an error on authentication failure.
+func (a *DirectoryAuthenticator) Authenticate(entityFinder EntityFinder, tag names.Tag, req params.LoginRequest) (state.Entity, error) {
+	userTag, ok := tag.(names.UserTag)
+	if !ok || !userTag.IsLocal() {
+		return a.UserAuthenticator.Authenticate(entityFinder, tag, req)
+	}
+	provisioner, ok := entityFinder.(DirectoryUserProvisioner)
+	if !ok {
+		return a.UserAuthenticator.Authenticate(entityFinder, tag, req)
+	}
+	isDirectoryUser, err := provisioner.IsDirectoryUser(userTag)
+	if errors.IsNotFound(err) {
+		// Users that juju doesn't know about yet may be in the
+		// directory.
+		isDirectoryUser = true
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if !isDirectoryUser {
+		return a.UserAuthenticator.Authenticate(entityFinder, tag, req)
+	}
+
+	user, err := a.Directory.Authenticate(userTag.Name(), req.Credentials)
+	if errors.IsUnauthorized(err) || errors.IsNotFound(err) {
+		logger.Debugf("directory login for %q failed: %v", userTag.Name(), err)
+		return nil, errors.Trace(common.ErrBadCreds)
+	}
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot authenticate against directory")
+	}
+	if _, ok := GroupsAccess(a.GroupAccess, user.Groups); !ok {
+		logger.Infof("directory user %q is not in any group with access", userTag.Name())
+		return nil, errors.Trace(common.ErrPerm)
+	}
+	err = provisioner.ProvisionDirectoryUser(userTag, user.DisplayName, user.Groups)
+	if errors.IsUnauthorized(err) {
+		logger.Debugf("directory login for %q failed: %v", userTag.Name(), err)
+		return nil, errors.Trace(common.ErrBadCreds)
+	}
+	if err != nil {
+		return nil, errors.Annotatef(err, "cannot provision directory user %q", userTag.Name())
+	}
+	entity, err := entityFinder.FindEntity(tag)
+	if errors.IsNotFound(err) {
+		logger.Infof("directory user %q has not been given access: %v", userTag.Name(), err)
+		return nil, errors.Trace(common.ErrPerm)
+	}
+	return entity, errors.Trace(err)
+}
+
+// GroupsAccess returns the most privileged access the mapping grants
+// to any of the given groups, and whether any access is granted at all.
+func GroupsAccess(groupAccess map[string]state.EnvUserAccess, groups []string) (state.EnvUserAccess, bool) {
+	var result state.EnvUserAccess
+	for _, group := range groups {
+		access, ok := groupAccess[group]
+		if !ok {
+			continue
+		}
+		if result == "" || access.Includes(result) {
+			result = access
+		}
+	}
+	return result, result != ""
+}
@@ -0,0 +1,245 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package authentication_test
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/authentication"
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type directoryAuthenticatorSuite struct {
+	coretesting.BaseSuite
+	directory     *fakeDirectory
+	finder        *fakeDirectoryFinder
+	authenticator *authentication.DirectoryAuthenticator
+}
+
+var _ = gc.Suite(&directoryAuthenticatorSuite{})
+
+// fakeDirectory is a Directory holding users with the password
+// "password".
+type fakeDirectory struct {
+	users map[string]authentication.DirectoryUser
+	err   error
+}
+
+func (d *fakeDirectory) Authenticate(name, password string) (authentication.DirectoryUser, error) {
+	if d.err != nil {
+		return authentication.DirectoryUser{}, d.err
+	}
+	user, ok := d.users[name]
+	if !ok {
+		return authentication.DirectoryUser{}, errors.NotFoundf("user %q", name)
+	}
+	if password != "password" {
+		return authentication.DirectoryUser{}, errors.Unauthorizedf("invalid password")
+	}
+	return user, nil
+}
+
+type provisionedUser struct {
+	displayName string
+	groups      []string
+}
+
+// fakeDirectoryFinder is an EntityFinder and DirectoryUserProvisioner
+// that records the users it provisions.
+type fakeDirectoryFinder struct {
+	localUsers  map[string]*fakeUser
+	provisioned map[string]provisionedUser
+	disabled    map[string]bool
+	// withoutAccess holds the users that the environment gives no
+	// access to.
+	withoutAccess map[string]bool
+}
+
+func (f *fakeDirectoryFinder) FindEntity(tag names.Tag) (state.Entity, error) {
+	if user, ok := f.localUsers[userName(tag)]; ok {
+		return user, nil
+	}
+	if _, ok := f.provisioned[userName(tag)]; ok && !f.withoutAccess[userName(tag)] {
+		return &fakeUser{tag: tag}, nil
+	}
+	return nil, errors.NotFoundf("user %q", userName(tag))
+}
+
+func (f *fakeDirectoryFinder) IsDirectoryUser(tag names.UserTag) (bool, error) {
+	if _, ok := f.localUsers[userName(tag)]; ok {
+		return false, nil
+	}
+	if _, ok := f.provisioned[userName(tag)]; ok {
+		return true, nil
+	}
+	return false, errors.NotFoundf("user %q", userName(tag))
+}
+
+func (f *fakeDirectoryFinder) ProvisionDirectoryUser(tag names.UserTag, displayName string, groups []string) error {
+	if f.disabled[userName(tag)] {
+		return errors.Unauthorizedf("user %q is disabled", userName(tag))
+	}
+	f.provisioned[userName(tag)] = provisionedUser{displayName, groups}
+	return nil
+}
+
+func userName(tag names.Tag) string {
+	return tag.(names.UserTag).Name()
+}
+
+// fakeUser is a local user with the password "local-password".
+type fakeUser struct {
+	tag names.Tag
+}
+
+func (u *fakeUser) Tag() names.Tag {
+	return u.tag
+}
+
+func (u *fakeUser) PasswordValid(password string) bool {
+	return password == "local-password"
+}
+
+func (u *fakeUser) SetPassword(password string) error {
+	return errors.NotImplementedf("SetPassword")
+}
+
+func (u *fakeUser) Refresh() error {
+	return nil
+}
+
+func (s *directoryAuthenticatorSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.directory = &fakeDirectory{
+		users: map[string]authentication.DirectoryUser{
+			"alice": {
+				DisplayName: "Alice Adams",
+				Groups:      []string{"staff", "developers", "juju-admins"},
+			},
+			"bob": {
+				DisplayName: "Bob Brown",
+				Groups:      []string{"staff", "developers"},
+			},
+			"carol": {
+				Groups: []string{"staff"},
+			},
+		},
+	}
+	s.finder = &fakeDirectoryFinder{
+		localUsers: map[string]*fakeUser{
+			"admin": {tag: names.NewLocalUserTag("admin")},
+		},
+		provisioned:   make(map[string]provisionedUser),
+		disabled:      make(map[string]bool),
+		withoutAccess: make(map[string]bool),
+	}
+	s.authenticator = &authentication.DirectoryAuthenticator{
+		Directory: s.directory,
+		GroupAccess: map[string]state.EnvUserAccess{
+			"developers":  state.EnvDeployerAccess,
+			"juju-admins": state.EnvAdminAccess,
+		},
+	}
+}
+
+func (s *directoryAuthenticatorSuite) login(name, password string) (state.Entity, error) {
+	return s.authenticator.Authenticate(s.finder, names.NewLocalUserTag(name), params.LoginRequest{
+		Credentials: password,
+	})
+}
+
+func (s *directoryAuthenticatorSuite) TestProvisionsUserOnFirstLogin(c *gc.C) {
+	entity, err := s.login("bob", "password")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(entity.Tag(), gc.Equals, names.NewLocalUserTag("bob"))
+	c.Assert(s.finder.provisioned, jc.DeepEquals, map[string]provisionedUser{
+		"bob": {"Bob Brown", []string{"staff", "developers"}},
+	})
+}
+
+func (s *directoryAuthenticatorSuite) TestNoAccessToEnvironment(c *gc.C) {
+	s.finder.withoutAccess["bob"] = true
+	_, err := s.login("bob", "password")
+	c.Assert(errors.Cause(err), gc.Equals, common.ErrPerm)
+}
+
+func (s *directoryAuthenticatorSuite) TestGroupsAccess(c *gc.C) {
+	groupAccess := map[string]state.EnvUserAccess{
+		"developers":  state.EnvDeployerAccess,
+		"juju-admins": state.EnvAdminAccess,
+	}
+	for i, test := range []struct {
+		groups []string
+		access state.EnvUserAccess
+	}{{
+		groups: []string{"staff", "developers"},
+		access: state.EnvDeployerAccess,
+	}, {
+		groups: []string{"juju-admins", "developers"},
+		access: state.EnvAdminAccess,
+	}, {
+		groups: []string{"developers", "juju-admins"},
+		access: state.EnvAdminAccess,
+	}, {
+		groups: []string{"staff"},
+	}} {
+		c.Logf("test %d: %v", i, test.groups)
+		access, ok := authentication.GroupsAccess(groupAccess, test.groups)
+		c.Check(access, gc.Equals, test.access)
+		c.Check(ok, gc.Equals, test.access != "")
+	}
+}
+
+func (s *directoryAuthenticatorSuite) TestNoGroupWithAccess(c *gc.C) {
+	_, err := s.login("carol", "password")
+	c.Assert(errors.Cause(err), gc.Equals, common.ErrPerm)
+	c.Assert(s.finder.provisioned, gc.HasLen, 0)
+}
+
+func (s *directoryAuthenticatorSuite) TestWrongPassword(c *gc.C) {
+	_, err := s.login("bob", "wrong")
+	c.Assert(errors.Cause(err), gc.Equals, common.ErrBadCreds)
+	c.Assert(s.finder.provisioned, gc.HasLen, 0)
+}
+
+func (s *directoryAuthenticatorSuite) TestRemovedFromDirectory(c *gc.C) {
+	_, err := s.login("bob", "password")
+	c.Assert(err, jc.ErrorIsNil)
+
+	delete(s.directory.users, "bob")
+	_, err = s.login("bob", "password")
+	c.Assert(errors.Cause(err), gc.Equals, common.ErrBadCreds)
+}
+
+func (s *directoryAuthenticatorSuite) TestDisabledUser(c *gc.C) {
+	s.finder.disabled["bob"] = true
+	_, err := s.login("bob", "password")
+	c.Assert(errors.Cause(err), gc.Equals, common.ErrBadCreds)
+}
+
+func (s *directoryAuthenticatorSuite) TestDirectoryError(c *gc.C) {
+	s.directory.err = errors.New("connection refused")
+	_, err := s.login("bob", "password")
+	c.Assert(err, gc.ErrorMatches, "cannot authenticate against directory: connection refused")
+}
+
+func (s *directoryAuthenticatorSuite) TestLocalUserUsesPassword(c *gc.C) {
+	entity, err := s.login("admin", "local-password")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(entity.Tag(), gc.Equals, names.NewLocalUserTag("admin"))
+
+	// The directory isn't consulted for local users.
+	s.directory.users["admin"] = authentication.DirectoryUser{
+		Groups: []string{"juju-admins"},
+	}
+	_, err = s.login("admin", "password")
+	c.Assert(errors.Cause(err), gc.Equals, common.ErrBadCreds)
+	c.Assert(s.finder.provisioned, gc.HasLen, 0)
+}
@@ -0,0 +1,204 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package ldap provides an authentication.Directory that authenticates
+// users against an LDAP server.
+package ldap
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/juju/errors"
+	goldap "gopkg.in/ldap.v2"
+
+	"github.com/juju/juju/apiserver/authentication"
+)
+
+// Config holds the configuration of an LDAP directory.
+type Config struct {
+	// URL holds the ldap:// or ldaps:// URL of the LDAP server.
+	URL string
+
+	// BindDN and BindPassword hold the credentials used to search
+	// for users. If BindDN is empty, the search is anonymous.
+	BindDN       string
+	BindPassword string
+
+	// UserBaseDN holds the DN under which users are searched for.
+	UserBaseDN string
+
+	// UserFilter holds the filter that finds a user by name. The
+	// escaped user name replaces the single %s in the filter.
+	UserFilter string
+}
+
+// Validate returns an error if the configuration is not valid.
+func (cfg Config) Validate() error {
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return errors.Annotate(err, "invalid LDAP URL")
+	}
+	if u.Scheme != "ldap" && u.Scheme != "ldaps" {
+		return errors.NotValidf("LDAP URL %q", cfg.URL)
+	}
+	if cfg.UserBaseDN == "" {
+		return errors.NotValidf("empty user base DN")
+	}
+	if strings.Count(cfg.UserFilter, "%s") != 1 {
+		return errors.NotValidf("user filter %q", cfg.UserFilter)
+	}
+	return nil
+}
+
+// conn holds the LDAP operations used by the directory.
+type conn interface {
+	Bind(username, password string) error
+	Search(req *goldap.SearchRequest) (*goldap.SearchResult, error)
+	Close()
+}
+
+// dial connects to the LDAP server at the given URL.
+var dial = func(u *url.URL) (conn, error) {
+	host, port, err := net.SplitHostPort(u.Host)
+	if err != nil {
+		// No port was specified.
+		host, port = u.Host, "389"
+		if u.Scheme == "ldaps" {
+			port = "636"
+		}
+	}
+	addr := net.JoinHostPort(host, port)
+	if u.Scheme == "ldaps" {
+		return goldap.DialTLS("tcp", addr, &tls.Config{ServerName: host})
+	}
+	return goldap.Dial("tcp", addr)
+}
+
+// userAttributes holds the attributes of user entries that the
+// directory reads.
+var userAttributes = []string{"cn", "displayName", "memberOf"}
+
+type directory struct {
+	config Config
+	url    *url.URL
+}
+
+// NewDirectory returns a directory that authenticates users against
+// the LDAP server with the given configuration. A user is found by
+// searching for them with the configured filter, then authenticated by
+// binding as their entry. The user's groups are the common names of
+// the groups listed in their entry's memberOf attribute.
+func NewDirectory(config Config) (authentication.Directory, error) {
+	if err := config.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	u, err := url.Parse(config.URL)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &directory{config, u}, nil
+}
+
+// Authenticate implements authentication.Directory.
+func (d *directory) Authenticate(name, password string) (authentication.DirectoryUser, error) {
+	var user authentication.DirectoryUser
+	// An LDAP bind with an empty password is an unauthenticated
+	// bind, which many servers allow, so it must never be taken as
+	// proof of identity.
+	if password == "" {
+		return user, errors.Unauthorizedf("empty password")
+	}
+	c, err := dial(d.url)
+	if err != nil {
+		return user, errors.Annotate(err, "cannot connect to LDAP server")
+	}
+	defer c.Close()
+
+	if d.config.BindDN != "" {
+		if err := c.Bind(d.config.BindDN, d.config.BindPassword); err != nil {
+			return user, errors.Annotate(err, "cannot bind to LDAP server")
+		}
+	}
+	result, err := c.Search(goldap.NewSearchRequest(
+		d.config.UserBaseDN,
+		goldap.ScopeWholeSubtree, goldap.NeverDerefAliases, 0, 0, false,
+		fmt.Sprintf(d.config.UserFilter, escapeFilter(name)),
+		userAttributes,
+		nil,
+	))
+	if err != nil {
+		return user, errors.Annotatef(err, "cannot search for user %q", name)
+	}
+	switch len(result.Entries) {
+	case 0:
+		return user, errors.NotFoundf("user %q", name)
+	case 1:
+	default:
+		return user, errors.Errorf("found %d LDAP entries for user %q", len(result.Entries), name)
+	}
+	entry := result.Entries[0]
+
+	err = c.Bind(entry.DN, password)
+	if goldap.IsErrorWithCode(err, goldap.LDAPResultInvalidCredentials) {
+		return user, errors.Unauthorizedf("invalid password for user %q", name)
+	}
+	if err != nil {
+		return user, errors.Annotatef(err, "cannot bind as user %q", name)
+	}
+
+	user.DisplayName = entry.GetAttributeValue("displayName")
+	if user.DisplayName == "" {
+		user.DisplayName = entry.GetAttributeValue("cn")
+	}
+	for _, groupDN := range entry.GetAttributeValues("memberOf") {
+		if group := firstRDNValue(groupDN); group != "" {
+			user.Groups = append(user.Groups, group)
+		}
+	}
+	return user, nil
+}
+
+// escapeFilter escapes the characters that have special meaning in an
+// LDAP filter, as described in RFC 4515.
+func escapeFilter(s string) string {
+	var buf []byte
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '\\', '*', '(', ')', 0:
+			buf = append(buf, fmt.Sprintf(`\%02x`, c)...)
+		default:
+			buf = append(buf, c)
+		}
+	}
+	return string(buf)
+}
+
+// firstRDNValue returns the value of the first relative distinguished
+// name in the given DN, so "cn=admins,ou=groups,dc=example,dc=com"
+// yields "admins". It returns the empty string if the DN is malformed.
+func firstRDNValue(dn string) string {
+	eq := strings.Index(dn, "=")
+	if eq < 0 {
+		return ""
+	}
+	var value []byte
+	for i := eq + 1; i < len(dn); i++ {
+		switch c := dn[i]; c {
+		case '\\':
+			// Escaped characters stand for themselves.
+			if i+1 < len(dn) {
+				i++
+				value = append(value, dn[i])
+			}
+		case ',', '+':
+			return strings.TrimSpace(string(value))
+		default:
+			value = append(value, c)
+		}
+	}
+	return strings.TrimSpace(string(value))
+}
@@ -0,0 +1,201 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package ldap
+
+import (
+	"net/url"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	goldap "gopkg.in/ldap.v2"
+
+	"github.com/juju/juju/apiserver/authentication"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type directorySuite struct {
+	coretesting.BaseSuite
+	server    *fakeServer
+	directory authentication.Directory
+}
+
+var _ = gc.Suite(&directorySuite{})
+
+// fakeServer is an in-process LDAP server holding entries with
+// passwords.
+type fakeServer struct {
+	entries   []*goldap.Entry
+	passwords map[string]string
+	dialed    *url.URL
+	closed    bool
+	binds     []string
+	filters   []string
+}
+
+func (s *fakeServer) Bind(username, password string) error {
+	s.binds = append(s.binds, username)
+	if p, ok := s.passwords[username]; !ok || p != password {
+		return goldap.NewError(goldap.LDAPResultInvalidCredentials, errors.New("invalid credentials"))
+	}
+	return nil
+}
+
+// Search returns the entries whose uid matches the filter, which the
+// tests always make of the form (uid=<name>).
+func (s *fakeServer) Search(req *goldap.SearchRequest) (*goldap.SearchResult, error) {
+	s.filters = append(s.filters, req.Filter)
+	result := &goldap.SearchResult{}
+	for _, entry := range s.entries {
+		if req.Filter == "(uid="+entry.GetAttributeValue("uid")+")" {
+			result.Entries = append(result.Entries, entry)
+		}
+	}
+	return result, nil
+}
+
+func (s *fakeServer) Close() {
+	s.closed = true
+}
+
+func newEntry(dn string, attrs map[string][]string) *goldap.Entry {
+	entry := &goldap.Entry{DN: dn}
+	for name, values := range attrs {
+		entry.Attributes = append(entry.Attributes, &goldap.EntryAttribute{
+			Name:   name,
+			Values: values,
+		})
+	}
+	return entry
+}
+
+func (s *directorySuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.server = &fakeServer{
+		entries: []*goldap.Entry{
+			newEntry("uid=bob,ou=people,dc=example,dc=com", map[string][]string{
+				"uid":         {"bob"},
+				"cn":          {"Bob"},
+				"displayName": {"Bob Brown"},
+				"memberOf": {
+					"cn=developers,ou=groups,dc=example,dc=com",
+					"cn=juju\\, admins,ou=groups,dc=example,dc=com",
+				},
+			}),
+			newEntry("uid=carol,ou=people,dc=example,dc=com", map[string][]string{
+				"uid": {"carol"},
+				"cn":  {"Carol"},
+			}),
+		},
+		passwords: map[string]string{
+			"cn=juju,dc=example,dc=com":             "search-password",
+			"uid=bob,ou=people,dc=example,dc=com":   "bob-password",
+			"uid=carol,ou=people,dc=example,dc=com": "carol-password",
+		},
+	}
+	s.PatchValue(&dial, func(u *url.URL) (conn, error) {
+		s.server.dialed = u
+		return s.server, nil
+	})
+	var err error
+	s.directory, err = NewDirectory(Config{
+		URL:          "ldaps://ldap.example.com",
+		BindDN:       "cn=juju,dc=example,dc=com",
+		BindPassword: "search-password",
+		UserBaseDN:   "ou=people,dc=example,dc=com",
+		UserFilter:   "(uid=%s)",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *directorySuite) TestAuthenticate(c *gc.C) {
+	user, err := s.directory.Authenticate("bob", "bob-password")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(user, jc.DeepEquals, authentication.DirectoryUser{
+		DisplayName: "Bob Brown",
+		Groups:      []string{"developers", "juju, admins"},
+	})
+	c.Assert(s.server.dialed.String(), gc.Equals, "ldaps://ldap.example.com")
+	c.Assert(s.server.binds, jc.DeepEquals, []string{
+		"cn=juju,dc=example,dc=com",
+		"uid=bob,ou=people,dc=example,dc=com",
+	})
+	c.Assert(s.server.closed, jc.IsTrue)
+}
+
+func (s *directorySuite) TestAuthenticateNoGroups(c *gc.C) {
+	user, err := s.directory.Authenticate("carol", "carol-password")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(user, jc.DeepEquals, authentication.DirectoryUser{
+		DisplayName: "Carol",
+	})
+}
+
+func (s *directorySuite) TestAuthenticateWrongPassword(c *gc.C) {
+	_, err := s.directory.Authenticate("bob", "carol-password")
+	c.Assert(err, jc.Satisfies, errors.IsUnauthorized)
+}
+
+func (s *directorySuite) TestAuthenticateEmptyPassword(c *gc.C) {
+	_, err := s.directory.Authenticate("bob", "")
+	c.Assert(err, jc.Satisfies, errors.IsUnauthorized)
+	c.Assert(s.server.dialed, gc.IsNil)
+}
+
+func (s *directorySuite) TestAuthenticateUnknownUser(c *gc.C) {
+	_, err := s.directory.Authenticate("dave", "dave-password")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *directorySuite) TestAuthenticateEscapesName(c *gc.C) {
+	_, err := s.directory.Authenticate("*)(uid=bob", "bob-password")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	c.Assert(s.server.filters, jc.DeepEquals, []string{`(uid=\2a\29\28uid=bob)`})
+}
+
+func (s *directorySuite) TestAuthenticateSearchUserRejected(c *gc.C) {
+	s.server.passwords["cn=juju,dc=example,dc=com"] = "changed"
+	_, err := s.directory.Authenticate("bob", "bob-password")
+	c.Assert(err, gc.ErrorMatches, "cannot bind to LDAP server: .*")
+}
+
+func (s *directorySuite) TestAuthenticateMultipleEntries(c *gc.C) {
+	s.server.entries = append(s.server.entries, newEntry("uid=bob,ou=contractors,dc=example,dc=com", map[string][]string{
+		"uid": {"bob"},
+	}))
+	_, err := s.directory.Authenticate("bob", "bob-password")
+	c.Assert(err, gc.ErrorMatches, `found 2 LDAP entries for user "bob"`)
+}
+
+func (s *directorySuite) TestNewDirectoryInvalidConfig(c *gc.C) {
+	for i, test := range []struct {
+		config Config
+		err    string
+	}{{
+		config: Config{URL: "https://ldap.example.com", UserBaseDN: "dc=example", UserFilter: "(uid=%s)"},
+		err:    `LDAP URL "https://ldap.example.com" not valid`,
+	}, {
+		config: Config{URL: "ldap://ldap.example.com", UserFilter: "(uid=%s)"},
+		err:    "empty user base DN not valid",
+	}, {
+		config: Config{URL: "ldap://ldap.example.com", UserBaseDN: "dc=example", UserFilter: "(uid=bob)"},
+		err:    `user filter "\(uid=bob\)" not valid`,
+	}} {
+		c.Logf("test %d", i)
+		_, err := NewDirectory(test.config)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
+
+func (s *directorySuite) TestFirstRDNValue(c *gc.C) {
+	for dn, expect := range map[string]string{
+		"cn=admins,ou=groups,dc=example,dc=com": "admins",
+		"CN=Domain Admins,CN=Users,DC=corp":     "Domain Admins",
+		`cn=a\,b,dc=example`:                    "a,b",
+		"cn=solo":                               "solo",
+		"malformed":                             "",
+	} {
+		c.Check(firstRDNValue(dn), gc.Equals, expect, gc.Commentf("dn %q", dn))
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package authentication
+
+import (
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+)
+
+// tokenCredentialsPrefix starts the credentials of logins made with an
+// API token rather than a password.
+const tokenCredentialsPrefix = "token:"
+
+// FormatTokenCredentials returns the login credentials for the API
+// token with the given name and secret.
+func FormatTokenCredentials(name, secret string) string {
+	return tokenCredentialsPrefix + name + ":" + secret
+}
+
+// ParseTokenCredentials returns the name and secret of the API token
+// in the given login credentials, and whether the credentials hold an
+// API token at all.
+func ParseTokenCredentials(credentials string) (name, secret string, ok bool) {
+	if !strings.HasPrefix(credentials, tokenCredentialsPrefix) {
+		return "", "", false
+	}
+	parts := strings.SplitN(strings.TrimPrefix(credentials, tokenCredentialsPrefix), ":", 2)
+	if len(parts) != 2 || !state.IsValidAPITokenName(parts[0]) || parts[1] == "" {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+// APIToken holds the parts of a state.APIToken needed to authenticate
+// logins made with it.
+type APIToken interface {
+	SecretValid(secret string) bool
+	Expired(now time.Time) bool
+	AllowsEnvironment(uuid string) bool
+	UpdateLastUsed() error
+}
+
+// APITokenFinder is implemented by entity finders that can find the
+// API tokens users log in with.
+type APITokenFinder interface {
+	// FindAPIToken returns the API token with the given name owned
+	// by the given user. It returns an error satisfying
+	// errors.IsNotFound if there is no such token, and one
+	// satisfying errors.IsUnauthorized if the user has been
+	// disabled or locked out.
+	FindAPIToken(owner names.UserTag, name string) (APIToken, error)
+
+	// LoginEnvironUUID returns the UUID of the environment being
+	// logged in to, or the empty string for logins to the state
+	// server itself.
+	LoginEnvironUUID() string
+}
+
+// TokenAuthenticator authenticates local users that log in with one of
+// their API tokens instead of their password. Tokens may expire, and
+// may be restricted to some environments.
+type TokenAuthenticator struct{}
+
+var _ EntityAuthenticator = (*TokenAuthenticator)(nil)
+
+// Authenticate authenticates the provided entity and returns an error on authentication failure.
+func (*TokenAuthenticator) Authenticate(entityFinder EntityFinder, tag names.Tag, req params.LoginRequest) (state.Entity, error) {
+	userTag, ok := tag.(names.UserTag)
+	if !ok || !userTag.IsLocal() {
+		return nil, errors.Trace(common.ErrBadCreds)
+	}
+	name, secret, ok := ParseTokenCredentials(req.Credentials)
+	if !ok {
+		return nil, errors.Trace(common.ErrBadCreds)
+	}
+	finder, ok := entityFinder.(APITokenFinder)
+	if !ok {
+		return nil, errors.NotSupportedf("API token login")
+	}
+	token, err := finder.FindAPIToken(userTag, name)
+	if errors.IsNotFound(err) || errors.IsUnauthorized(err) {
+		logger.Debugf("token login for %q failed: %v", userTag.Name(), err)
+		return nil, errors.Trace(common.ErrBadCreds)
+	}
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if !token.SecretValid(secret) {
+		return nil, errors.Trace(common.ErrBadCreds)
+	}
+	if token.Expired(time.Now()) {
+		logger.Debugf("API token %q of user %q has expired", name, userTag.Name())
+		return nil, errors.Trace(common.ErrBadCreds)
+	}
+	if !token.AllowsEnvironment(finder.LoginEnvironUUID()) {
+		logger.Debugf("API token %q of user %q does not allow this environment", name, userTag.Name())
+		return nil, errors.Trace(common.ErrPerm)
+	}
+	entity, err := entityFinder.FindEntity(tag)
+	if errors.IsNotFound(err) {
+		return nil, errors.Trace(common.ErrBadCreds)
+	}
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	// Like last login times, the last use of a token is only
+	// informational, so failing to record it doesn't fail the login.
+	if err := token.UpdateLastUsed(); err != nil {
+		logger.Warningf("cannot record use of API token %q of user %q: %v", name, userTag.Name(), err)
+	}
+	return entity, nil
+}
@@ -0,0 +1,198 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package authentication_test
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/authentication"
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type tokenAuthenticatorSuite struct {
+	coretesting.BaseSuite
+	finder *fakeTokenFinder
+}
+
+var _ = gc.Suite(&tokenAuthenticatorSuite{})
+
+const envUUID = "deadbeef-0bad-400d-8000-4b1d0d06f00d"
+
+// fakeToken is an API token with the secret "sekrit".
+type fakeToken struct {
+	expired      bool
+	environments []string
+	used         int
+}
+
+func (t *fakeToken) SecretValid(secret string) bool {
+	return secret == "sekrit"
+}
+
+func (t *fakeToken) Expired(now time.Time) bool {
+	return t.expired
+}
+
+func (t *fakeToken) AllowsEnvironment(uuid string) bool {
+	if len(t.environments) == 0 {
+		return true
+	}
+	for _, allowed := range t.environments {
+		if uuid == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *fakeToken) UpdateLastUsed() error {
+	t.used++
+	return nil
+}
+
+// fakeTokenFinder is an EntityFinder and APITokenFinder holding the
+// tokens of the user "ci-bot".
+type fakeTokenFinder struct {
+	tokens   map[string]*fakeToken
+	disabled bool
+	envUUID  string
+}
+
+func (f *fakeTokenFinder) FindEntity(tag names.Tag) (state.Entity, error) {
+	if userName(tag) != "ci-bot" {
+		return nil, errors.NotFoundf("user %q", userName(tag))
+	}
+	return &fakeUser{tag: tag}, nil
+}
+
+func (f *fakeTokenFinder) FindAPIToken(owner names.UserTag, name string) (authentication.APIToken, error) {
+	if f.disabled {
+		return nil, errors.Unauthorizedf("user %q is disabled", owner.Name())
+	}
+	token, ok := f.tokens[name]
+	if !ok || owner.Name() != "ci-bot" {
+		return nil, errors.NotFoundf("API token %q", name)
+	}
+	return token, nil
+}
+
+func (f *fakeTokenFinder) LoginEnvironUUID() string {
+	return f.envUUID
+}
+
+func (s *tokenAuthenticatorSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.finder = &fakeTokenFinder{
+		tokens: map[string]*fakeToken{
+			"jenkins": {},
+		},
+		envUUID: envUUID,
+	}
+}
+
+func (s *tokenAuthenticatorSuite) login(user, credentials string) (state.Entity, error) {
+	var auth authentication.TokenAuthenticator
+	return auth.Authenticate(s.finder, names.NewLocalUserTag(user), params.LoginRequest{
+		Credentials: credentials,
+	})
+}
+
+func (s *tokenAuthenticatorSuite) TestParseTokenCredentials(c *gc.C) {
+	for i, test := range []struct {
+		credentials string
+		name        string
+		secret      string
+		ok          bool
+	}{{
+		credentials: authentication.FormatTokenCredentials("jenkins", "sekrit"),
+		name:        "jenkins",
+		secret:      "sekrit",
+		ok:          true,
+	}, {
+		credentials: "token:jenkins:sek:rit",
+		name:        "jenkins",
+		secret:      "sek:rit",
+		ok:          true,
+	}, {
+		credentials: "password",
+	}, {
+		credentials: "token:jenkins",
+	}, {
+		credentials: "token:jenkins:",
+	}, {
+		credentials: "token:Jenkins:sekrit",
+	}} {
+		c.Logf("test %d: %q", i, test.credentials)
+		name, secret, ok := authentication.ParseTokenCredentials(test.credentials)
+		c.Check(ok, gc.Equals, test.ok)
+		c.Check(name, gc.Equals, test.name)
+		c.Check(secret, gc.Equals, test.secret)
+	}
+}
+
+func (s *tokenAuthenticatorSuite) TestLogin(c *gc.C) {
+	entity, err := s.login("ci-bot", "token:jenkins:sekrit")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(entity.Tag(), gc.Equals, names.NewLocalUserTag("ci-bot"))
+	c.Assert(s.finder.tokens["jenkins"].used, gc.Equals, 1)
+}
+
+func (s *tokenAuthenticatorSuite) TestWrongSecret(c *gc.C) {
+	_, err := s.login("ci-bot", "token:jenkins:wrong")
+	c.Assert(errors.Cause(err), gc.Equals, common.ErrBadCreds)
+	c.Assert(s.finder.tokens["jenkins"].used, gc.Equals, 0)
+}
+
+func (s *tokenAuthenticatorSuite) TestUnknownToken(c *gc.C) {
+	_, err := s.login("ci-bot", "token:travis:sekrit")
+	c.Assert(errors.Cause(err), gc.Equals, common.ErrBadCreds)
+}
+
+func (s *tokenAuthenticatorSuite) TestOtherUsersToken(c *gc.C) {
+	_, err := s.login("bob", "token:jenkins:sekrit")
+	c.Assert(errors.Cause(err), gc.Equals, common.ErrBadCreds)
+}
+
+func (s *tokenAuthenticatorSuite) TestNotTokenCredentials(c *gc.C) {
+	_, err := s.login("ci-bot", "sekrit")
+	c.Assert(errors.Cause(err), gc.Equals, common.ErrBadCreds)
+}
+
+func (s *tokenAuthenticatorSuite) TestExpiredToken(c *gc.C) {
+	s.finder.tokens["jenkins"].expired = true
+	_, err := s.login("ci-bot", "token:jenkins:sekrit")
+	c.Assert(errors.Cause(err), gc.Equals, common.ErrBadCreds)
+}
+
+func (s *tokenAuthenticatorSuite) TestDisabledUser(c *gc.C) {
+	s.finder.disabled = true
+	_, err := s.login("ci-bot", "token:jenkins:sekrit")
+	c.Assert(errors.Cause(err), gc.Equals, common.ErrBadCreds)
+}
+
+func (s *tokenAuthenticatorSuite) TestEnvironmentScope(c *gc.C) {
+	s.finder.tokens["jenkins"].environments = []string{"another-uuid"}
+	_, err := s.login("ci-bot", "token:jenkins:sekrit")
+	c.Assert(errors.Cause(err), gc.Equals, common.ErrPerm)
+
+	s.finder.tokens["jenkins"].environments = []string{"another-uuid", envUUID}
+	_, err = s.login("ci-bot", "token:jenkins:sekrit")
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *tokenAuthenticatorSuite) TestFinderWithoutTokens(c *gc.C) {
+	var auth authentication.TokenAuthenticator
+	_, err := auth.Authenticate(entityFinder{}, names.NewLocalUserTag("ci-bot"), params.LoginRequest{
+		Credentials: "token:jenkins:sekrit",
+	})
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+}
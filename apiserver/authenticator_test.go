@@ -65,6 +65,48 @@ func (s *agentAuthenticatorSuite) TestUnitGetsAgentAuthenticator(c *gc.C) {
 	c.Assert(ok, jc.IsTrue)
 }
 
+func (s *agentAuthenticatorSuite) TestUserGetsUserAuthenticator(c *gc.C) {
+	srv := newServer(c, s.State)
+	defer srv.Stop()
+	authenticator, err := apiserver.ServerAuthenticatorForTag(srv, names.NewLocalUserTag("bob"))
+	c.Assert(err, jc.ErrorIsNil)
+	_, ok := authenticator.(*authentication.UserAuthenticator)
+	c.Assert(ok, jc.IsTrue)
+}
+
+func (s *agentAuthenticatorSuite) TestUserGetsDirectoryAuthenticator(c *gc.C) {
+	err := s.State.UpdateEnvironConfig(map[string]interface{}{
+		"ldap-url":          "ldap://ldap.example.com",
+		"ldap-user-base-dn": "dc=example,dc=com",
+		"ldap-group-access": "juju-admins=admin",
+	}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	srv := newServer(c, s.State)
+	defer srv.Stop()
+	authenticator, err := apiserver.ServerAuthenticatorForTag(srv, names.NewLocalUserTag("bob"))
+	c.Assert(err, jc.ErrorIsNil)
+	auth, ok := authenticator.(*authentication.DirectoryAuthenticator)
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(auth.GroupAccess, jc.DeepEquals, map[string]state.EnvUserAccess{
+		"juju-admins": state.EnvAdminAccess,
+	})
+}
+
+func (s *agentAuthenticatorSuite) TestUserGetsUserAuthenticatorWhenDirectoryMisconfigured(c *gc.C) {
+	err := s.State.UpdateEnvironConfig(map[string]interface{}{
+		"ldap-url":          "ldap://ldap.example.com",
+		"ldap-user-base-dn": "dc=example,dc=com",
+		"ldap-group-access": "juju-admins=superuser",
+	}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	srv := newServer(c, s.State)
+	defer srv.Stop()
+	authenticator, err := apiserver.ServerAuthenticatorForTag(srv, names.NewLocalUserTag("bob"))
+	c.Assert(err, jc.ErrorIsNil)
+	_, ok := authenticator.(*authentication.UserAuthenticator)
+	c.Assert(ok, jc.IsTrue)
+}
+
 func (s *agentAuthenticatorSuite) TestNotSupportedTag(c *gc.C) {
 	srv := newServer(c, s.State)
 	defer srv.Stop()
@@ -229,6 +229,15 @@ func (s *authHttpSuite) authRequest(c *gc.C, p httpRequestParams) *http.Response
 	return s.sendRequest(c, p)
 }
 
+// setUserAccess changes the access the suite's user has to the
+// environment.
+func (s *authHttpSuite) setUserAccess(c *gc.C, access state.EnvUserAccess) {
+	envUser, err := s.State.EnvironmentUser(s.userTag)
+	c.Assert(err, jc.ErrorIsNil)
+	err = envUser.SetAccess(access)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
 func (s *authHttpSuite) setupOtherEnvironment(c *gc.C) *state.State {
 	envState := s.Factory.MakeEnvironment(c, nil)
 	s.AddCleanup(func(*gc.C) { envState.Close() })
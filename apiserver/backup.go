@@ -33,7 +33,7 @@ type backupHandler struct {
 func (h *backupHandler) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
 	// Validate before authenticate because the authentication is dependent
 	// on the state connection that is determined during the validation.
-	st, _, err := h.ctxt.stateForRequestAuthenticatedUser(req)
+	st, _, err := h.ctxt.stateForRequestAuthorizedUser(req, state.EnvAdminAccess)
 	if err != nil {
 		h.sendError(resp, err)
 		return
@@ -114,6 +114,15 @@ func (s *backupsSuite) TestAuthRequiresClientNotMachine(c *gc.C) {
 	s.assertErrorResponse(c, resp, http.StatusMethodNotAllowed, `unsupported method: "POST"`)
 }
 
+func (s *backupsSuite) TestRequiresAdminAccess(c *gc.C) {
+	s.setUserAccess(c, state.EnvViewerAccess)
+	for _, method := range []string{"GET", "PUT"} {
+		c.Logf("method: %s", method)
+		resp := s.authRequest(c, httpRequestParams{method: method, url: s.backupURL(c)})
+		s.assertErrorResponse(c, resp, http.StatusUnauthorized, "permission denied")
+	}
+}
+
 type backupsWithMacaroonsSuite struct {
 	backupsCommonSuite
 }
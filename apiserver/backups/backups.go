@@ -105,6 +105,7 @@ func ResultFromMetadata(meta *backups.Metadata) params.BackupsMetadataResult {
 		result.Finished = *meta.Finished
 	}
 	result.Notes = meta.Notes
+	result.Encryption = meta.Encryption
 
 	result.Environment = meta.Origin.Environment
 	result.Machine = meta.Origin.Machine
@@ -128,6 +129,26 @@ func MetadataFromResult(result params.BackupsMetadataResult) *backups.Metadata {
 	meta.Origin.Hostname = result.Hostname
 	meta.Origin.Version = result.Version
 	meta.Notes = result.Notes
+	meta.Encryption = result.Encryption
 	meta.SetFileInfo(result.Size, result.Checksum, result.ChecksumFormat)
 	return meta
 }
+
+// EncryptionKey converts the key material in args into a key usable by
+// the backups machinery. It returns nil if args is empty.
+func EncryptionKey(args params.BackupsEncryptionKey) (*backups.EncryptionKey, error) {
+	if args == (params.BackupsEncryptionKey{}) {
+		return nil, nil
+	}
+	key := backups.EncryptionKey{
+		Passphrase: args.Passphrase,
+	}
+	if args.PublicKey != "" {
+		publicKey, err := backups.ParsePublicKey([]byte(args.PublicKey))
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		key.PublicKey = publicKey
+	}
+	return &key, nil
+}
@@ -40,10 +40,26 @@ func (a *API) Create(args params.BackupsCreateArgs) (p params.BackupsMetadataRes
 	}
 	meta.Notes = args.Notes
 
-	err = backupsMethods.Create(meta, a.paths, dbInfo)
+	key, err := EncryptionKey(args.Encryption)
+	if err != nil {
+		return p, errors.Annotate(err, "invalid encryption key")
+	}
+
+	err = backupsMethods.Create(meta, a.paths, dbInfo, key)
 	if err != nil {
 		return p, errors.Trace(err)
 	}
 
+	// Copy the completed backup to the remote storage, if any.
+	remote, err := newRemoteStorage(a.st)
+	if err == nil {
+		err = backups.ExportToRemote(backupsMethods, remote, meta.ID())
+	} else if errors.IsNotFound(err) {
+		err = nil
+	}
+	if err != nil {
+		return p, errors.Annotatef(err, "backup %q created", meta.ID())
+	}
+
 	return ResultFromMetadata(meta), nil
 }
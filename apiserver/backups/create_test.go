@@ -52,3 +52,31 @@ func (s *backupsSuite) TestCreateError(c *gc.C) {
 
 	c.Check(err, gc.ErrorMatches, "failed!")
 }
+
+func (s *backupsSuite) TestCreateEncrypted(c *gc.C) {
+	s.PatchValue(backups.WaitUntilReady,
+		func(*mgo.Session, int) error { return nil },
+	)
+	fake := s.setBackups(c, s.meta, "")
+	args := params.BackupsCreateArgs{
+		Encryption: params.BackupsEncryptionKey{Passphrase: "sekrit"},
+	}
+	_, err := s.api.Create(args)
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Assert(fake.KeyArg, gc.NotNil)
+	c.Check(fake.KeyArg.Passphrase, gc.Equals, "sekrit")
+}
+
+func (s *backupsSuite) TestCreateInvalidPublicKey(c *gc.C) {
+	s.PatchValue(backups.WaitUntilReady,
+		func(*mgo.Session, int) error { return nil },
+	)
+	s.setBackups(c, s.meta, "")
+	args := params.BackupsCreateArgs{
+		Encryption: params.BackupsEncryptionKey{PublicKey: "garbage"},
+	}
+	_, err := s.api.Create(args)
+
+	c.Check(err, gc.ErrorMatches, "invalid encryption key: no PEM data found")
+}
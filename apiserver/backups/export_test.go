@@ -6,4 +6,6 @@ package backups
 var (
 	NewBackups     = &newBackups
 	WaitUntilReady = &waitUntilReady
+
+	NewRemoteStorage = &newRemoteStorage
 )
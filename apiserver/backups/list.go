@@ -7,18 +7,32 @@ import (
 	"github.com/juju/errors"
 
 	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state/backups"
 )
 
 // List provides the implementation of the API method.
 func (a *API) List(args params.BackupsListArgs) (params.BackupsListResult, error) {
 	var result params.BackupsListResult
 
-	backups, closer := newBackups(a.st)
-	defer closer.Close()
-
-	metaList, err := backups.List()
-	if err != nil {
-		return result, errors.Trace(err)
+	var metaList []*backups.Metadata
+	if args.Remote {
+		remote, err := newRemoteStorage(a.st)
+		if err != nil {
+			return result, errors.Trace(err)
+		}
+		metaList, err = remote.List()
+		if err != nil {
+			return result, errors.Trace(err)
+		}
+	} else {
+		backupsMethods, closer := newBackups(a.st)
+		defer closer.Close()
+
+		var err error
+		metaList, err = backupsMethods.List()
+		if err != nil {
+			return result, errors.Trace(err)
+		}
 	}
 
 	result.List = make([]params.BackupsMetadataResult, len(metaList))
@@ -0,0 +1,50 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package backups
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/backups"
+)
+
+var newRemoteStorage = func(st *state.State) (backups.RemoteStorage, error) {
+	cfg, err := backups.GetRemoteConfig(st)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return backups.NewRemoteStorage(*cfg)
+}
+
+// Remote returns the configuration of the remote storage to which
+// completed backups are copied. The values of secret attributes are
+// not returned.
+func (a *API) Remote() (params.BackupsRemoteConfig, error) {
+	var result params.BackupsRemoteConfig
+	cfg, err := backups.GetRemoteConfig(a.st)
+	if errors.IsNotFound(err) {
+		return result, nil
+	} else if err != nil {
+		return result, errors.Trace(err)
+	}
+	redacted := cfg.Redacted()
+	result.Type = redacted.Type
+	result.Attrs = redacted.Attrs
+	return result, nil
+}
+
+// SetRemote sets the remote storage to which completed backups are
+// copied. An empty type removes the remote storage.
+func (a *API) SetRemote(args params.BackupsRemoteConfig) error {
+	if args.Type == "" {
+		return errors.Trace(backups.SetRemoteConfig(a.st, nil))
+	}
+	cfg := backups.RemoteConfig{
+		Type:  args.Type,
+		Attrs: args.Attrs,
+	}
+	return errors.Trace(backups.SetRemoteConfig(a.st, &cfg))
+}
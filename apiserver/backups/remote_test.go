@@ -0,0 +1,102 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package backups_test
+
+import (
+	"bytes"
+	"io/ioutil"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/mgo.v2"
+
+	"github.com/juju/juju/apiserver/backups"
+	"github.com/juju/juju/apiserver/params"
+)
+
+func (s *backupsSuite) TestRemoteNone(c *gc.C) {
+	result, err := s.api.Remote()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(result, jc.DeepEquals, params.BackupsRemoteConfig{})
+}
+
+func (s *backupsSuite) TestSetRemote(c *gc.C) {
+	args := params.BackupsRemoteConfig{
+		Type:  "local",
+		Attrs: map[string]string{"path": c.MkDir()},
+	}
+	err := s.api.SetRemote(args)
+	c.Assert(err, jc.ErrorIsNil)
+
+	result, err := s.api.Remote()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(result, jc.DeepEquals, args)
+
+	err = s.api.SetRemote(params.BackupsRemoteConfig{})
+	c.Assert(err, jc.ErrorIsNil)
+	result, err = s.api.Remote()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(result, jc.DeepEquals, params.BackupsRemoteConfig{})
+}
+
+func (s *backupsSuite) TestRemoteRedactsSecrets(c *gc.C) {
+	err := s.api.SetRemote(params.BackupsRemoteConfig{
+		Type: "s3",
+		Attrs: map[string]string{
+			"bucket":     "backups",
+			"access-key": "access",
+			"secret-key": "secret",
+			"endpoint":   "http://localhost:8080",
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	result, err := s.api.Remote()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(result, jc.DeepEquals, params.BackupsRemoteConfig{
+		Type: "s3",
+		Attrs: map[string]string{
+			"bucket":     "backups",
+			"access-key": "access",
+			"secret-key": "",
+			"endpoint":   "http://localhost:8080",
+		},
+	})
+}
+
+func (s *backupsSuite) TestSetRemoteInvalid(c *gc.C) {
+	err := s.api.SetRemote(params.BackupsRemoteConfig{Type: "local"})
+	c.Check(err, gc.ErrorMatches, "invalid local backup remote: missing path")
+
+	err = s.api.SetRemote(params.BackupsRemoteConfig{Type: "floppy"})
+	c.Check(err, gc.ErrorMatches, `backup remote type "floppy" not valid`)
+}
+
+func (s *backupsSuite) TestCreateExportsToRemote(c *gc.C) {
+	s.PatchValue(backups.WaitUntilReady,
+		func(*mgo.Session, int) error { return nil },
+	)
+	s.meta.SetID("spam")
+	impl := s.setBackups(c, s.meta, "")
+	impl.Archive = ioutil.NopCloser(bytes.NewBufferString("spamspamspam"))
+	err := s.api.SetRemote(params.BackupsRemoteConfig{
+		Type:  "local",
+		Attrs: map[string]string{"path": c.MkDir()},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = s.api.Create(params.BackupsCreateArgs{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(impl.Calls, jc.DeepEquals, []string{"Create", "Get"})
+
+	result, err := s.api.List(params.BackupsListArgs{Remote: true})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.List, gc.HasLen, 1)
+	c.Check(result.List[0].ID, gc.Equals, "spam")
+}
+
+func (s *backupsSuite) TestListRemoteNotConfigured(c *gc.C) {
+	_, err := s.api.List(params.BackupsListArgs{Remote: true})
+	c.Check(err, gc.ErrorMatches, "backup remote not found")
+}
@@ -22,10 +22,25 @@ var bootstrapNode = names.NewMachineTag("0")
 // Restore implements the server side of Backups.Restore.
 func (a *API) Restore(p params.RestoreArgs) error {
 
+	key, err := EncryptionKey(p.Encryption)
+	if err != nil {
+		return errors.Annotate(err, "invalid encryption key")
+	}
+
 	// Get hold of a backup file Reader
 	backup, closer := newBackups(a.st)
 	defer closer.Close()
 
+	if p.FromRemote {
+		remote, err := newRemoteStorage(a.st)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if err := backups.ImportFromRemote(backup, remote, p.BackupId); err != nil {
+			return errors.Trace(err)
+		}
+	}
+
 	// Obtain the address of current machine, where we will be performing restore.
 	machine, err := a.st.Machine(a.machineID)
 	if err != nil {
@@ -70,6 +85,7 @@ func (a *API) Restore(p params.RestoreArgs) error {
 		NewInstId:      instanceId,
 		NewInstTag:     machine.Tag(),
 		NewInstSeries:  machine.Series(),
+		Key:            key,
 	}
 
 	oldTagString, err := backup.Restore(p.BackupId, restoreArgs)
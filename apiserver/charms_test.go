@@ -13,6 +13,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"time"
 
 	"github.com/juju/errors"
 	jc "github.com/juju/testing/checkers"
@@ -21,6 +22,7 @@ import (
 	"gopkg.in/juju/charm.v6-unstable"
 	"gopkg.in/macaroon-bakery.v1/httpbakery"
 
+	"github.com/juju/juju/apiserver/authentication"
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/state"
 	"github.com/juju/juju/state/storage"
@@ -146,6 +148,82 @@ func (s *charmsSuite) TestAuthRequiresUser(c *gc.C) {
 	s.assertErrorResponse(c, resp, http.StatusBadRequest, "expected series=URL argument")
 }
 
+func (s *charmsSuite) TestUploadRequiresDeployerAccess(c *gc.C) {
+	s.setUserAccess(c, state.EnvViewerAccess)
+	resp := s.authRequest(c, httpRequestParams{method: "POST", url: s.charmsURI(c, "")})
+	s.assertErrorResponse(c, resp, http.StatusUnauthorized, "permission denied")
+
+	s.setUserAccess(c, state.EnvDeployerAccess)
+	resp = s.authRequest(c, httpRequestParams{method: "POST", url: s.charmsURI(c, "")})
+	s.assertErrorResponse(c, resp, http.StatusBadRequest, "expected series=URL argument")
+}
+
+func (s *charmsSuite) TestUploadWithAPIToken(c *gc.C) {
+	_, secret, err := s.State.AddAPIToken(state.APITokenSpec{
+		Owner: s.userTag,
+		Name:  "anything",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	resp := s.sendRequest(c, httpRequestParams{
+		tag:      s.userTag.String(),
+		password: authentication.FormatTokenCredentials("anything", secret),
+		method:   "POST",
+		url:      s.charmsURI(c, ""),
+	})
+	s.assertErrorResponse(c, resp, http.StatusBadRequest, "expected series=URL argument")
+}
+
+func (s *charmsSuite) TestUploadRejectsRestrictedAPIToken(c *gc.C) {
+	_, secret, err := s.State.AddAPIToken(state.APITokenSpec{
+		Owner:   s.userTag,
+		Name:    "client-only",
+		Facades: []string{"Client"},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	resp := s.sendRequest(c, httpRequestParams{
+		tag:      s.userTag.String(),
+		password: authentication.FormatTokenCredentials("client-only", secret),
+		method:   "POST",
+		url:      s.charmsURI(c, ""),
+	})
+	s.assertErrorResponse(c, resp, http.StatusUnauthorized, "permission denied")
+}
+
+func (s *charmsSuite) TestUploadLockout(c *gc.C) {
+	err := s.State.UpdateEnvironConfig(map[string]interface{}{
+		"login-lockout-attempts": 2,
+	}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	for i := 0; i < 2; i++ {
+		resp := s.sendRequest(c, httpRequestParams{
+			tag:      s.userTag.String(),
+			password: "wrong",
+			method:   "POST",
+			url:      s.charmsURI(c, ""),
+		})
+		s.assertErrorResponse(c, resp, http.StatusUnauthorized, "invalid entity name or password")
+	}
+	user, err := s.State.User(s.userTag)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(user.IsLockedOut(), jc.IsTrue)
+
+	// The right password no longer works.
+	resp := s.authRequest(c, httpRequestParams{method: "POST", url: s.charmsURI(c, "")})
+	s.assertErrorResponse(c, resp, http.StatusUnauthorized, "invalid entity name or password")
+}
+
+func (s *charmsSuite) TestUploadPasswordExpired(c *gc.C) {
+	err := s.State.UpdateEnvironConfig(map[string]interface{}{
+		"password-max-age": "1ns",
+	}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	// Creation times are rounded to the second, so wait long enough
+	// for the password to be older than its maximum age.
+	time.Sleep(time.Second)
+	resp := s.authRequest(c, httpRequestParams{method: "POST", url: s.charmsURI(c, "")})
+	s.assertErrorResponse(c, resp, http.StatusUnauthorized, "password expired - .*")
+}
+
 func (s *charmsSuite) TestUploadRequiresSeries(c *gc.C) {
 	resp := s.authRequest(c, httpRequestParams{method: "POST", url: s.charmsURI(c, "")})
 	s.assertErrorResponse(c, resp, http.StatusBadRequest, "expected series=URL argument")
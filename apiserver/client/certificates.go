@@ -0,0 +1,154 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package client
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cert"
+	"github.com/juju/juju/state"
+)
+
+const (
+	// defaultCAOverlap is how long both the current and new CAs are
+	// trusted when rotating the CA, if no overlap is specified.
+	defaultCAOverlap = 7 * 24 * time.Hour
+
+	// certificateWarningPeriod is how long before a certificate's
+	// expiry status starts warning about it.
+	certificateWarningPeriod = 30 * 24 * time.Hour
+)
+
+// ControllerCertificates returns the expiry details of the controller's
+// CA and server certificates.
+func (c *Client) ControllerCertificates() (params.ControllerCertificatesInfo, error) {
+	certs, err := c.api.stateAccessor.ControllerCertificates()
+	if err != nil {
+		return params.ControllerCertificatesInfo{}, errors.Trace(err)
+	}
+	return controllerCertificatesInfo(certs, time.Now())
+}
+
+// RotateCertificates asks the controller's state servers to replace
+// their server certificates, and optionally starts rotating in a new
+// CA. Only controller administrators may rotate certificates.
+func (c *Client) RotateCertificates(args params.RotateCertificates) (params.ControllerCertificatesInfo, error) {
+	var result params.ControllerCertificatesInfo
+	if err := c.check.ChangeAllowed(); err != nil {
+		return result, errors.Trace(err)
+	}
+	userTag, ok := c.api.auth.GetAuthTag().(names.UserTag)
+	if !ok {
+		return result, common.ErrPerm
+	}
+	isAdmin, err := c.api.stateAccessor.IsControllerAdministrator(userTag)
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	if !isAdmin {
+		return result, common.ErrPerm
+	}
+	if args.Overlap < 0 {
+		return result, errors.NotValidf("negative overlap %v", args.Overlap)
+	}
+
+	var caCert, caKey string
+	if args.NewCA {
+		env, err := c.api.stateAccessor.StateServerEnvironment()
+		if err != nil {
+			return result, errors.Trace(err)
+		}
+		caCert, caKey, err = cert.NewCA(env.Name(), time.Now().UTC().AddDate(10, 0, 0))
+		if err != nil {
+			return result, errors.Annotate(err, "cannot generate CA certificate")
+		}
+	}
+	overlap := args.Overlap
+	if overlap == 0 {
+		overlap = defaultCAOverlap
+	}
+	if err := c.api.stateAccessor.RotateControllerCertificates(caCert, caKey, overlap); err != nil {
+		return result, errors.Trace(err)
+	}
+	return c.ControllerCertificates()
+}
+
+// controllerCertificatesInfo returns the API representation of the
+// given controller certificates at the given time.
+func controllerCertificatesInfo(certs state.ControllerCertificates, now time.Time) (params.ControllerCertificatesInfo, error) {
+	caCert, err := cert.ParseCert(certs.CACert)
+	if err != nil {
+		return params.ControllerCertificatesInfo{}, errors.Annotate(err, "cannot parse CA certificate")
+	}
+	info := params.ControllerCertificatesInfo{
+		CACert:             certs.TrustedCACerts(now),
+		CAExpiry:           caCert.NotAfter,
+		ServerCertExpiries: certs.ServerCertExpiries,
+	}
+	if certs.NewCACert != "" {
+		newCACert, err := cert.ParseCert(certs.NewCACert)
+		if err != nil {
+			return params.ControllerCertificatesInfo{}, errors.Annotate(err, "cannot parse new CA certificate")
+		}
+		if now.Before(certs.SwitchTime) {
+			switchTime := certs.SwitchTime
+			info.NewCAExpiry = &newCACert.NotAfter
+			info.SwitchTime = &switchTime
+		} else {
+			// The new CA has taken over.
+			info.CAExpiry = newCACert.NotAfter
+		}
+	}
+	return info, nil
+}
+
+// certificateWarning returns a warning describing any of the
+// controller's certificates that expire within the warning period, or
+// the empty string if there are none.
+func (c *Client) certificateWarning() (string, error) {
+	info, err := c.ControllerCertificates()
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	return certificateWarning(info, time.Now()), nil
+}
+
+func certificateWarning(info params.ControllerCertificatesInfo, now time.Time) string {
+	var warnings []string
+	warn := func(what string, expiry time.Time) {
+		if !expiry.Before(now.Add(certificateWarningPeriod)) {
+			return
+		}
+		verb := "expires"
+		if expiry.Before(now) {
+			verb = "expired"
+		}
+		warnings = append(warnings, fmt.Sprintf("%s %s %s", what, verb, expiry.UTC().Format(time.RFC3339)))
+	}
+	if info.NewCAExpiry == nil {
+		// If a new CA is being rotated in, it doesn't matter
+		// that the current one is close to expiry.
+		warn("CA certificate", info.CAExpiry)
+	}
+	ids := make([]string, 0, len(info.ServerCertExpiries))
+	for id := range info.ServerCertExpiries {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		warn(fmt.Sprintf("server certificate for machine %s", id), info.ServerCertExpiries[id])
+	}
+	if len(warnings) == 0 {
+		return ""
+	}
+	return strings.Join(warnings, "; ")
+}
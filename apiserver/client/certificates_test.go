@@ -0,0 +1,98 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package client_test
+
+import (
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/client"
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	"github.com/juju/juju/cert"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type certificatesSuite struct {
+	baseSuite
+}
+
+var _ = gc.Suite(&certificatesSuite{})
+
+func (s *certificatesSuite) TestControllerCertificates(c *gc.C) {
+	expiry := time.Now().AddDate(0, 0, 10).UTC().Truncate(time.Second)
+	err := s.State.SetServerCertificateExpiry("0", expiry)
+	c.Assert(err, jc.ErrorIsNil)
+
+	info, err := s.APIState.Client().ControllerCertificates()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(info.CACert, gc.Equals, s.State.CACert())
+	c.Assert(info.CAExpiry.Equal(coretesting.CACertX509.NotAfter), jc.IsTrue)
+	c.Assert(info.NewCAExpiry, gc.IsNil)
+	c.Assert(info.SwitchTime, gc.IsNil)
+	c.Assert(info.ServerCertExpiries, gc.HasLen, 1)
+	c.Assert(info.ServerCertExpiries["0"].Equal(expiry), jc.IsTrue)
+}
+
+func (s *certificatesSuite) TestRotateCertificates(c *gc.C) {
+	before := time.Now().Add(-time.Second)
+	info, err := s.APIState.Client().RotateCertificates(false, 0)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(info.NewCAExpiry, gc.IsNil)
+
+	certs, err := s.State.ControllerCertificates()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(certs.ServerCertsRotated.After(before), jc.IsTrue)
+	c.Assert(certs.NewCACert, gc.Equals, "")
+}
+
+func (s *certificatesSuite) TestRotateCertificatesNewCA(c *gc.C) {
+	info, err := s.APIState.Client().RotateCertificates(true, time.Hour)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(info.NewCAExpiry, gc.NotNil)
+	c.Assert(info.NewCAExpiry.After(time.Now().AddDate(9, 0, 0)), jc.IsTrue)
+	c.Assert(info.SwitchTime, gc.NotNil)
+	c.Assert(info.SwitchTime.After(time.Now().Add(59*time.Minute)), jc.IsTrue)
+
+	// Both the current and new CAs are trusted.
+	caCerts, err := cert.ParseCerts(info.CACert)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(caCerts, gc.HasLen, 2)
+	c.Assert(info.CACert, gc.Equals, s.State.CACert())
+}
+
+func (s *certificatesSuite) TestRotateCertificatesNegativeOverlap(c *gc.C) {
+	_, err := s.APIState.Client().RotateCertificates(true, -time.Hour)
+	c.Assert(err, gc.ErrorMatches, "negative overlap -1h0m0s not valid")
+}
+
+func (s *certificatesSuite) TestRotateCertificatesNotControllerAdmin(c *gc.C) {
+	user := s.Factory.MakeEnvUser(c, nil)
+	auth := apiservertesting.FakeAuthorizer{Tag: user.UserTag()}
+	api, err := client.NewClient(s.State, common.NewResources(), auth)
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = api.RotateCertificates(params.RotateCertificates{})
+	c.Assert(err, gc.Equals, common.ErrPerm)
+}
+
+func (s *certificatesSuite) TestBlockRotateCertificates(c *gc.C) {
+	s.BlockAllChanges(c, "TestBlockRotateCertificates")
+	_, err := s.APIState.Client().RotateCertificates(false, 0)
+	s.AssertBlocked(c, err, "TestBlockRotateCertificates")
+}
+
+func (s *certificatesSuite) TestStatusWarnsOfExpiringCertificate(c *gc.C) {
+	expiry := time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)
+	err := s.State.SetServerCertificateExpiry("0", expiry)
+	c.Assert(err, jc.ErrorIsNil)
+
+	status, err := s.APIState.Client().Status(nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(status.CertificateWarning, gc.Equals,
+		"server certificate for machine 0 expired 2015-01-01T00:00:00Z")
+}
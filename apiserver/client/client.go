@@ -103,7 +103,7 @@ func (c *Client) ServiceSet(p params.ServiceSet) error {
 	if err != nil {
 		return err
 	}
-	return service.ServiceSetSettingsStrings(svc, p.Options)
+	return service.ServiceSetSettingsStrings(svc, p.Options, c.authUserName())
 }
 
 // NewServiceSetForClientAPI implements the server side of
@@ -119,7 +119,7 @@ func (c *Client) NewServiceSetForClientAPI(p params.ServiceSet) error {
 	if err != nil {
 		return err
 	}
-	return newServiceSetSettingsStringsForClientAPI(svc, p.Options)
+	return newServiceSetSettingsStringsForClientAPI(svc, p.Options, c.authUserName())
 }
 
 // ServiceUnset implements the server side of Client.ServiceUnset.
@@ -136,7 +136,7 @@ func (c *Client) ServiceUnset(p params.ServiceUnset) error {
 	for _, option := range p.Options {
 		settings[option] = nil
 	}
-	return svc.UpdateConfigSettings(settings)
+	return svc.UpdateConfigSettingsBy(settings, c.authUserName())
 }
 
 // ServiceCharmRelations implements the server side of Client.ServiceCharmRelations.
@@ -278,11 +278,12 @@ func (c *Client) ServiceDeployWithNetworks(args params.ServiceDeploy) error {
 }
 
 // newServiceSetSettingsStringsForClientAPI updates the settings for the given
-// service, taking the configuration from a map of strings.
+// service, taking the configuration from a map of strings. The change is
+// recorded in the service's config history as made by the named user.
 //
 // TODO(Nate): replace serviceSetSettingsStrings with this onces the GUI no
 // longer expects to be able to unset values by sending an empty string.
-func newServiceSetSettingsStringsForClientAPI(service *state.Service, settings map[string]string) error {
+func newServiceSetSettingsStringsForClientAPI(service *state.Service, settings map[string]string, user string) error {
 	ch, _, err := service.Charm()
 	if err != nil {
 		return err
@@ -294,7 +295,7 @@ func newServiceSetSettingsStringsForClientAPI(service *state.Service, settings m
 		return err
 	}
 
-	return service.UpdateConfigSettings(changes)
+	return service.UpdateConfigSettingsBy(changes, user)
 }
 
 // addServiceUnits adds a given number of units to a service.
@@ -684,6 +685,18 @@ func (c *Client) ShareEnvironment(args params.ModifyEnvironUsers) (result params
 				err = errors.Annotate(err, "could not unshare environment")
 				result.Results[i].Error = common.ServerError(err)
 			}
+		case params.GrantEnvAccess:
+			err := c.grantEnvironmentAccess(user, createdBy, state.EnvUserAccess(arg.Access))
+			if err != nil {
+				err = errors.Annotate(err, "could not grant environment access")
+				result.Results[i].Error = common.ServerError(err)
+			}
+		case params.RevokeEnvAccess:
+			err := c.revokeEnvironmentAccess(user, state.EnvUserAccess(arg.Access))
+			if err != nil {
+				err = errors.Annotate(err, "could not revoke environment access")
+				result.Results[i].Error = common.ServerError(err)
+			}
 		default:
 			result.Results[i].Error = common.ServerError(errors.Errorf("unknown action %q", arg.Action))
 		}
@@ -691,6 +704,54 @@ func (c *Client) ShareEnvironment(args params.ModifyEnvironUsers) (result params
 	return result, nil
 }
 
+// grantEnvironmentAccess gives the user the given access to the
+// environment, adding them to it if they have no access yet. Access is
+// never lowered by granting; it must be revoked instead.
+func (c *Client) grantEnvironmentAccess(user, createdBy names.UserTag, access state.EnvUserAccess) error {
+	if err := access.Validate(); err != nil {
+		return errors.Trace(err)
+	}
+	envUser, err := c.api.stateAccessor.EnvironmentUser(user)
+	if errors.IsNotFound(err) {
+		_, err := c.api.stateAccessor.AddEnvironmentUser(state.EnvUserSpec{
+			User:      user,
+			CreatedBy: createdBy,
+			Access:    access,
+		})
+		return errors.Trace(err)
+	} else if err != nil {
+		return errors.Trace(err)
+	}
+	if current := envUser.Access(); current.Includes(access) {
+		return errors.Errorf("user already has %q access", current)
+	}
+	return errors.Trace(envUser.SetAccess(access))
+}
+
+// revokeEnvironmentAccess takes the given access away from the user,
+// leaving them with the access level below it. Revoking viewer access,
+// or no access in particular, removes the user from the environment.
+func (c *Client) revokeEnvironmentAccess(user names.UserTag, access state.EnvUserAccess) error {
+	if access == "" {
+		return errors.Trace(c.api.stateAccessor.RemoveEnvironmentUser(user))
+	}
+	if err := access.Validate(); err != nil {
+		return errors.Trace(err)
+	}
+	envUser, err := c.api.stateAccessor.EnvironmentUser(user)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if !envUser.Access().Includes(access) {
+		return errors.Errorf("user does not have %q access", access)
+	}
+	below, ok := access.Below()
+	if !ok {
+		return errors.Trace(c.api.stateAccessor.RemoveEnvironmentUser(user))
+	}
+	return errors.Trace(envUser.SetAccess(below))
+}
+
 // EnvUserInfo returns information on all users in the environment.
 func (c *Client) EnvUserInfo() (params.EnvUserInfoResults, error) {
 	var results params.EnvUserInfoResults
@@ -720,6 +781,7 @@ func (c *Client) EnvUserInfo() (params.EnvUserInfoResults, error) {
 				CreatedBy:      user.CreatedBy(),
 				DateCreated:    user.DateCreated(),
 				LastConnection: lastConn,
+				Access:         string(user.Access()),
 			},
 		})
 	}
@@ -746,6 +808,15 @@ func (c *Client) GetAnnotations(args params.GetAnnotations) (params.GetAnnotatio
 	return params.GetAnnotationsResults{Annotations: ann}, nil
 }
 
+// authUserName returns the canonical name of the user the API is being
+// used by, or "" if it is not being used by a user.
+func (c *Client) authUserName() string {
+	if tag, ok := c.api.auth.GetAuthTag().(names.UserTag); ok {
+		return tag.Canonical()
+	}
+	return ""
+}
+
 func (c *Client) parseEntityTag(tag0 string) (names.Tag, error) {
 	tag, err := names.ParseTag(tag0)
 	if err != nil {
@@ -799,6 +870,7 @@ func (c *Client) EnvironmentGet() (params.EnvironmentConfigResults, error) {
 		return result, err
 	}
 	result.Config = config.AllAttrs()
+	common.RemoveServerSecrets(result.Config)
 	return result, nil
 }
 
@@ -131,7 +131,7 @@ func (s *serverSuite) TestEnvUsersInfo(c *gc.C) {
 	localUser1 := s.makeLocalEnvUser(c, "ralphdoe", "Ralph Doe")
 	localUser2 := s.makeLocalEnvUser(c, "samsmith", "Sam Smith")
 	remoteUser1 := s.Factory.MakeEnvUser(c, &factory.EnvUserParams{User: "bobjohns@ubuntuone", DisplayName: "Bob Johns"})
-	remoteUser2 := s.Factory.MakeEnvUser(c, &factory.EnvUserParams{User: "nicshaw@idprovider", DisplayName: "Nic Shaw"})
+	remoteUser2 := s.Factory.MakeEnvUser(c, &factory.EnvUserParams{User: "nicshaw@idprovider", DisplayName: "Nic Shaw", Access: state.EnvViewerAccess})
 
 	results, err := s.client.EnvUserInfo()
 	c.Assert(err, jc.ErrorIsNil)
@@ -145,30 +145,35 @@ func (s *serverSuite) TestEnvUsersInfo(c *gc.C) {
 			&params.EnvUserInfo{
 				UserName:    owner.UserName(),
 				DisplayName: owner.DisplayName(),
+				Access:      "admin",
 			},
 		}, {
 			localUser1,
 			&params.EnvUserInfo{
 				UserName:    "ralphdoe@local",
 				DisplayName: "Ralph Doe",
+				Access:      "admin",
 			},
 		}, {
 			localUser2,
 			&params.EnvUserInfo{
 				UserName:    "samsmith@local",
 				DisplayName: "Sam Smith",
+				Access:      "admin",
 			},
 		}, {
 			remoteUser1,
 			&params.EnvUserInfo{
 				UserName:    "bobjohns@ubuntuone",
 				DisplayName: "Bob Johns",
+				Access:      "admin",
 			},
 		}, {
 			remoteUser2,
 			&params.EnvUserInfo{
 				UserName:    "nicshaw@idprovider",
 				DisplayName: "Nic Shaw",
+				Access:      "viewer",
 			},
 		},
 	} {
@@ -265,6 +270,76 @@ func (s *serverSuite) TestUnshareEnvironmentMissingUser(c *gc.C) {
 	c.Assert(errors.IsNotFound(err), jc.IsTrue)
 }
 
+func (s *serverSuite) modifyEnvironUser(c *gc.C, user names.UserTag, action params.EnvironAction, access string) error {
+	result, err := s.client.ShareEnvironment(params.ModifyEnvironUsers{
+		Changes: []params.ModifyEnvironUser{{
+			UserTag: user.String(),
+			Action:  action,
+			Access:  access,
+		}}})
+	c.Assert(err, jc.ErrorIsNil)
+	return result.OneError()
+}
+
+func (s *serverSuite) assertEnvUserAccess(c *gc.C, user names.UserTag, access state.EnvUserAccess) {
+	envUser, err := s.State.EnvironmentUser(user)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(envUser.Access(), gc.Equals, access)
+}
+
+func (s *serverSuite) TestGrantEnvironmentAccessAddsUser(c *gc.C) {
+	user := s.Factory.MakeUser(c, &factory.UserParams{Name: "foobar", NoEnvUser: true})
+	err := s.modifyEnvironUser(c, user.UserTag(), params.GrantEnvAccess, params.EnvOperatorAccess)
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertEnvUserAccess(c, user.UserTag(), state.EnvOperatorAccess)
+
+	envUser, err := s.State.EnvironmentUser(user.UserTag())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(envUser.CreatedBy(), gc.Equals, dummy.AdminUserTag().Canonical())
+}
+
+func (s *serverSuite) TestGrantEnvironmentAccessRaisesAccess(c *gc.C) {
+	envUser := s.Factory.MakeEnvUser(c, &factory.EnvUserParams{Access: state.EnvViewerAccess})
+	err := s.modifyEnvironUser(c, envUser.UserTag(), params.GrantEnvAccess, params.EnvDeployerAccess)
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertEnvUserAccess(c, envUser.UserTag(), state.EnvDeployerAccess)
+
+	err = s.modifyEnvironUser(c, envUser.UserTag(), params.GrantEnvAccess, params.EnvOperatorAccess)
+	c.Assert(err, gc.ErrorMatches, `could not grant environment access: user already has "deployer" access`)
+	s.assertEnvUserAccess(c, envUser.UserTag(), state.EnvDeployerAccess)
+}
+
+func (s *serverSuite) TestGrantEnvironmentAccessInvalid(c *gc.C) {
+	envUser := s.Factory.MakeEnvUser(c, &factory.EnvUserParams{Access: state.EnvViewerAccess})
+	err := s.modifyEnvironUser(c, envUser.UserTag(), params.GrantEnvAccess, "superuser")
+	c.Assert(err, gc.ErrorMatches, `could not grant environment access: access level "superuser" not valid`)
+	s.assertEnvUserAccess(c, envUser.UserTag(), state.EnvViewerAccess)
+}
+
+func (s *serverSuite) TestRevokeEnvironmentAccessLowersAccess(c *gc.C) {
+	envUser := s.Factory.MakeEnvUser(c, nil)
+	err := s.modifyEnvironUser(c, envUser.UserTag(), params.RevokeEnvAccess, params.EnvDeployerAccess)
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertEnvUserAccess(c, envUser.UserTag(), state.EnvOperatorAccess)
+
+	err = s.modifyEnvironUser(c, envUser.UserTag(), params.RevokeEnvAccess, params.EnvAdminAccess)
+	c.Assert(err, gc.ErrorMatches, `could not revoke environment access: user does not have "admin" access`)
+	s.assertEnvUserAccess(c, envUser.UserTag(), state.EnvOperatorAccess)
+
+	err = s.modifyEnvironUser(c, envUser.UserTag(), params.RevokeEnvAccess, params.EnvViewerAccess)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.EnvironmentUser(envUser.UserTag())
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *serverSuite) TestRevokeEnvironmentAccessRemovesUser(c *gc.C) {
+	envUser := s.Factory.MakeEnvUser(c, nil)
+	err := s.modifyEnvironUser(c, envUser.UserTag(), params.RevokeEnvAccess, "")
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.EnvironmentUser(envUser.UserTag())
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
 func (s *serverSuite) TestShareEnvironmentAddLocalUser(c *gc.C) {
 	user := s.Factory.MakeUser(c, &factory.UserParams{Name: "foobar", NoEnvUser: true})
 	args := params.ModifyEnvironUsers{
@@ -2082,6 +2157,28 @@ func (s *serverSuite) TestClientEnvironmentGet(c *gc.C) {
 	c.Assert(result.Config, gc.DeepEquals, envConfig.AllAttrs())
 }
 
+func (s *serverSuite) TestClientEnvironmentGetHidesServerSecrets(c *gc.C) {
+	err := s.State.UpdateEnvironConfig(map[string]interface{}{
+		"ldap-url":           "ldaps://ldap.example.com",
+		"ldap-user-base-dn":  "ou=people,dc=example,dc=com",
+		"ldap-bind-password": "sekrit",
+	}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	user := s.Factory.MakeUser(c, &factory.UserParams{Password: "password", NoEnvUser: true})
+	s.Factory.MakeEnvUser(c, &factory.EnvUserParams{
+		User:   user.Name(),
+		Access: state.EnvViewerAccess,
+	})
+	st := s.OpenAPIAs(c, user.UserTag(), "password")
+	defer st.Close()
+
+	attrs, err := st.Client().EnvironmentGet()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(attrs["ldap-url"], gc.Equals, "ldaps://ldap.example.com")
+	_, found := attrs["ldap-bind-password"]
+	c.Assert(found, jc.IsFalse)
+}
+
 func (s *serverSuite) assertEnvValue(c *gc.C, key string, expected interface{}) {
 	envConfig, err := s.State.EnvironConfig()
 	c.Assert(err, jc.ErrorIsNil)
@@ -12,6 +12,7 @@ import (
 
 	"github.com/juju/errors"
 
+	"github.com/juju/juju/apiserver/common"
 	"github.com/juju/juju/network"
 	"github.com/juju/juju/state"
 )
@@ -120,6 +121,35 @@ func BuildPredicateFor(patterns []string) Predicate {
 // service, will determine whether the unit meets some criteria.
 type Predicate func(interface{}) (matches bool, _ error)
 
+// BuildLabelPredicate returns a Predicate matching the units, machines
+// and services accepted by the given label matcher.
+func BuildLabelPredicate(matcher *common.LabelMatcher) Predicate {
+	return func(i interface{}) (bool, error) {
+		switch i := i.(type) {
+		case *state.Machine:
+			return matcher.MatchMachine(i.Id()), nil
+		case *state.Unit:
+			return matcher.MatchUnit(i.Name()), nil
+		case *state.Service:
+			return matcher.MatchService(i.Name()), nil
+		}
+		panic(errors.Errorf("Programming error. We should only ever pass in machines, services, or units. Received %T.", i))
+	}
+}
+
+// andPredicates returns a Predicate matching whatever all of the given
+// predicates match.
+func andPredicates(predicates ...Predicate) Predicate {
+	return func(i interface{}) (bool, error) {
+		for _, p := range predicates {
+			if matches, err := p(i); err != nil || !matches {
+				return false, err
+			}
+		}
+		return true, nil
+	}
+}
+
 // closurePredicate is a function which has at some point been closed
 // around an element so that it can examine whether this element
 // matches some criteria.
@@ -0,0 +1,43 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package client
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/apiserver/params"
+)
+
+// HookLog returns the hooks recently run by a unit, with their
+// timings, exit statuses and the tail of their standard error.
+func (c *Client) HookLog(args params.HookLog) (params.HookLogResult, error) {
+	tag, err := names.ParseUnitTag(args.Tag)
+	if err != nil {
+		return params.HookLogResult{}, errors.Trace(err)
+	}
+	unit, err := c.api.stateAccessor.Unit(tag.Id())
+	if err != nil {
+		return params.HookLogResult{}, errors.Trace(err)
+	}
+	records, err := unit.HookRecords(args.Size)
+	if err != nil {
+		return params.HookLogResult{}, errors.Trace(err)
+	}
+	result := params.HookLogResult{
+		Records: make([]params.HookRecord, len(records)),
+	}
+	for i, record := range records {
+		result.Records[i] = params.HookRecord{
+			Hook:       record.Hook,
+			RelationId: record.RelationId,
+			RemoteUnit: record.RemoteUnit,
+			Started:    record.Started,
+			Duration:   record.Duration,
+			ExitStatus: record.ExitStatus,
+			Stderr:     record.Stderr,
+		}
+	}
+	return result, nil
+}
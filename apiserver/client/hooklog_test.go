@@ -0,0 +1,53 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package client_test
+
+import (
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+)
+
+type hookLogSuite struct {
+	baseSuite
+}
+
+var _ = gc.Suite(&hookLogSuite{})
+
+func (s *hookLogSuite) TestHookLog(c *gc.C) {
+	unit := s.Factory.MakeUnit(c, nil)
+	started := time.Date(2015, 11, 2, 10, 0, 0, 0, time.UTC)
+	for i, hook := range []string{"install", "config-changed", "start"} {
+		err := unit.AddHookRecord(state.HookRecord{
+			Hook:       hook,
+			RelationId: -1,
+			Started:    started.Add(time.Duration(i) * time.Minute),
+			Duration:   time.Duration(i+1) * time.Second,
+		})
+		c.Assert(err, jc.ErrorIsNil)
+	}
+
+	records, err := s.APIState.Client().HookLog(unit.Name(), 2)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(records, jc.DeepEquals, []params.HookRecord{{
+		Hook:       "config-changed",
+		RelationId: -1,
+		Started:    started.Add(time.Minute),
+		Duration:   2 * time.Second,
+	}, {
+		Hook:       "start",
+		RelationId: -1,
+		Started:    started.Add(2 * time.Minute),
+		Duration:   3 * time.Second,
+	}})
+}
+
+func (s *hookLogSuite) TestHookLogUnitNotFound(c *gc.C) {
+	_, err := s.APIState.Client().HookLog("wordpress/5", 0)
+	c.Assert(err, gc.ErrorMatches, `unit "wordpress/5" not found`)
+}
@@ -0,0 +1,129 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package client
+
+import (
+	"sort"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+)
+
+// relationUnitSettings is used to sort unit settings by unit name.
+type relationUnitSettings []params.RelationUnitSettings
+
+func (s relationUnitSettings) Len() int           { return len(s) }
+func (s relationUnitSettings) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s relationUnitSettings) Less(i, j int) bool { return s[i].Unit < s[j].Unit }
+
+// relation returns the relation between the specified endpoints.
+func (c *Client) relation(endpoints []string) (*state.Relation, error) {
+	eps, err := c.api.stateAccessor.InferEndpoints(endpoints...)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	rel, err := c.api.stateAccessor.EndpointsRelation(eps...)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return rel, nil
+}
+
+// RelationData returns the relation settings of every unit in scope
+// of the relation between the specified endpoints.
+func (c *Client) RelationData(args params.RelationData) (params.RelationDataResults, error) {
+	rel, err := c.relation(args.Endpoints)
+	if err != nil {
+		return params.RelationDataResults{}, errors.Trace(err)
+	}
+	result := params.RelationDataResults{Key: rel.String()}
+	for _, ep := range rel.Endpoints() {
+		service, err := c.api.stateAccessor.Service(ep.ServiceName)
+		if err != nil {
+			return params.RelationDataResults{}, errors.Trace(err)
+		}
+		units, err := service.AllUnits()
+		if err != nil {
+			return params.RelationDataResults{}, errors.Trace(err)
+		}
+		for _, unit := range units {
+			ru, err := rel.Unit(unit)
+			if err != nil {
+				return params.RelationDataResults{}, errors.Trace(err)
+			}
+			if inScope, err := ru.InScope(); err != nil {
+				return params.RelationDataResults{}, errors.Trace(err)
+			} else if !inScope {
+				continue
+			}
+			settings, err := ru.Settings()
+			if err != nil {
+				return params.RelationDataResults{}, errors.Trace(err)
+			}
+			result.Units = append(result.Units, params.RelationUnitSettings{
+				Unit:     unit.Name(),
+				Endpoint: ep.String(),
+				Settings: settings.Map(),
+			})
+		}
+	}
+	sort.Sort(relationUnitSettings(result.Units))
+	return result, nil
+}
+
+// SetRelationData updates the settings of a unit on the relation
+// between the specified endpoints. Settings with empty values are
+// removed. Units on the other side of the relation observe the change
+// as a relation-changed hook. Only controller administrators may
+// change relation settings.
+func (c *Client) SetRelationData(args params.SetRelationData) error {
+	if err := c.check.ChangeAllowed(); err != nil {
+		return errors.Trace(err)
+	}
+	userTag, ok := c.api.auth.GetAuthTag().(names.UserTag)
+	if !ok {
+		return common.ErrPerm
+	}
+	isAdmin, err := c.api.stateAccessor.IsControllerAdministrator(userTag)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if !isAdmin {
+		return common.ErrPerm
+	}
+	rel, err := c.relation(args.Endpoints)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	unit, err := c.api.state().Unit(args.Unit)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	ru, err := rel.Unit(unit)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if inScope, err := ru.InScope(); err != nil {
+		return errors.Trace(err)
+	} else if !inScope {
+		return errors.NotFoundf("unit %q in relation %q", args.Unit, rel)
+	}
+	settings, err := ru.Settings()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for key, value := range args.Settings {
+		if value == "" {
+			settings.Delete(key)
+		} else {
+			settings.Set(key, value)
+		}
+	}
+	_, err = settings.Write()
+	return errors.Annotatef(err, "cannot write settings of unit %q in relation %q", args.Unit, rel)
+}
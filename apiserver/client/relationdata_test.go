@@ -0,0 +1,114 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package client_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/client"
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	"github.com/juju/juju/state"
+)
+
+type relationDataSuite struct {
+	baseSuite
+	relation *state.Relation
+	mysql0   *state.RelationUnit
+}
+
+var _ = gc.Suite(&relationDataSuite{})
+
+func (s *relationDataSuite) SetUpTest(c *gc.C) {
+	s.baseSuite.SetUpTest(c)
+	wordpress := s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+	mysql := s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
+	eps, err := s.State.InferEndpoints("wordpress", "mysql")
+	c.Assert(err, jc.ErrorIsNil)
+	s.relation, err = s.State.AddRelation(eps...)
+	c.Assert(err, jc.ErrorIsNil)
+
+	enterScope := func(service *state.Service, settings map[string]interface{}) *state.RelationUnit {
+		unit, err := service.AddUnit()
+		c.Assert(err, jc.ErrorIsNil)
+		ru, err := s.relation.Unit(unit)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Assert(ru.EnterScope(settings), jc.ErrorIsNil)
+		return ru
+	}
+	s.mysql0 = enterScope(mysql, map[string]interface{}{"user": "wp"})
+	enterScope(wordpress, map[string]interface{}{"private-address": "10.0.0.1"})
+	// A unit that has not joined the relation is not reported.
+	_, err = wordpress.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *relationDataSuite) TestRelationData(c *gc.C) {
+	result, err := s.APIState.Client().RelationData("mysql", "wordpress")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.RelationDataResults{
+		Key: "wordpress:db mysql:server",
+		Units: []params.RelationUnitSettings{{
+			Unit:     "mysql/0",
+			Endpoint: "mysql:server",
+			Settings: map[string]interface{}{"user": "wp"},
+		}, {
+			Unit:     "wordpress/0",
+			Endpoint: "wordpress:db",
+			Settings: map[string]interface{}{"private-address": "10.0.0.1"},
+		}},
+	})
+}
+
+func (s *relationDataSuite) TestRelationDataNotFound(c *gc.C) {
+	_, err := s.APIState.Client().RelationData("wordpress", "logging")
+	c.Assert(err, gc.ErrorMatches, `service "logging" not found`)
+}
+
+func (s *relationDataSuite) TestSetRelationData(c *gc.C) {
+	err := s.APIState.Client().SetRelationData(
+		[]string{"wordpress", "mysql"}, "mysql/0",
+		map[string]string{"password": "secret", "user": ""},
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	settings, err := s.mysql0.Settings()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(settings.Map(), jc.DeepEquals, map[string]interface{}{"password": "secret"})
+}
+
+func (s *relationDataSuite) TestSetRelationDataNotInScope(c *gc.C) {
+	err := s.APIState.Client().SetRelationData(
+		[]string{"wordpress", "mysql"}, "wordpress/1",
+		map[string]string{"password": "secret"},
+	)
+	c.Assert(err, gc.ErrorMatches, `unit "wordpress/1" in relation "wordpress:db mysql:server" not found`)
+}
+
+func (s *relationDataSuite) TestSetRelationDataNotControllerAdmin(c *gc.C) {
+	user := s.Factory.MakeEnvUser(c, nil)
+	auth := apiservertesting.FakeAuthorizer{Tag: user.UserTag()}
+	api, err := client.NewClient(s.State, common.NewResources(), auth)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = api.SetRelationData(params.SetRelationData{
+		Endpoints: []string{"wordpress", "mysql"},
+		Unit:      "mysql/0",
+		Settings:  map[string]string{"password": "secret"},
+	})
+	c.Assert(err, gc.Equals, common.ErrPerm)
+	settings, err := s.mysql0.Settings()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(settings.Map(), jc.DeepEquals, map[string]interface{}{"user": "wp"})
+}
+
+func (s *relationDataSuite) TestBlockSetRelationData(c *gc.C) {
+	s.BlockAllChanges(c, "TestBlockSetRelationData")
+	err := s.APIState.Client().SetRelationData(
+		[]string{"wordpress", "mysql"}, "mysql/0",
+		map[string]string{"password": "secret"},
+	)
+	s.AssertBlocked(c, err, "TestBlockSetRelationData")
+}
@@ -74,6 +74,35 @@ func getAllUnitNames(st *state.State, units, services []string) (result []*state
 	return result, nil
 }
 
+// selectUnitNames returns the names of the units whose labels match
+// the selector. It is an error if there are none.
+func selectUnitNames(st *state.State, selector string) ([]string, error) {
+	matcher, err := common.NewLabelMatcher(st, selector)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	services, err := st.AllServices()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var result []string
+	for _, service := range services {
+		units, err := service.AllUnits()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		for _, unit := range units {
+			if matcher.MatchUnit(unit.Name()) {
+				result = append(result, unit.Name())
+			}
+		}
+	}
+	if len(result) == 0 {
+		return nil, errors.Errorf("no units match selector %q", selector)
+	}
+	return result, nil
+}
+
 func (c *Client) getDataDir() string {
 	dataResource, ok := c.api.resources.Get("dataDir").(common.StringResource)
 	if !ok {
@@ -88,7 +117,15 @@ func (c *Client) Run(run params.RunParams) (results params.RunResults, err error
 	if err := c.check.ChangeAllowed(); err != nil {
 		return params.RunResults{}, errors.Trace(err)
 	}
-	units, err := getAllUnitNames(c.api.state(), run.Units, run.Services)
+	unitNames := run.Units
+	if run.Selector != "" {
+		selected, err := selectUnitNames(c.api.state(), run.Selector)
+		if err != nil {
+			return results, errors.Trace(err)
+		}
+		unitNames = append(selected, unitNames...)
+	}
+	units, err := getAllUnitNames(c.api.state(), unitNames, run.Services)
 	if err != nil {
 		return results, err
 	}
@@ -321,6 +321,43 @@ func (s *runSuite) TestRunMachineAndService(c *gc.C) {
 	c.Assert(results, jc.DeepEquals, expectedResults)
 }
 
+func (s *runSuite) TestRunSelector(c *gc.C) {
+	s.addMachineWithAddress(c, "10.3.2.1")
+
+	charm := s.AddTestingCharm(c, "dummy")
+	owner := s.Factory.MakeUser(c, nil).Tag()
+	magic, err := s.State.AddService(state.AddServiceArgs{Name: "magic", Owner: owner.String(), Charm: charm})
+	c.Assert(err, jc.ErrorIsNil)
+	s.addUnit(c, magic)
+	canary := s.addUnit(c, magic)
+	err = s.State.SetLabels(canary, map[string]string{"env": "canary"})
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.mockSSH(c, echoInput)
+
+	client := s.APIState.Client()
+	results, err := client.Run(
+		params.RunParams{
+			Commands: "hostname",
+			Timeout:  testing.LongWait,
+			Selector: "env=canary",
+		})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results, jc.DeepEquals, []params.RunResult{{
+		ExecResponse: exec.ExecResponse{Stdout: []byte(expectedCommand[2])},
+		MachineId:    "2",
+		UnitId:       "magic/1",
+	}})
+
+	_, err = client.Run(
+		params.RunParams{
+			Commands: "hostname",
+			Timeout:  testing.LongWait,
+			Selector: "env=prod",
+		})
+	c.Assert(err, gc.ErrorMatches, `no units match selector "env=prod"`)
+}
+
 func (s *runSuite) TestBlockRunMachineAndService(c *gc.C) {
 	// Make three machines.
 	s.addMachineWithAddress(c, "10.3.2.1")
@@ -4,6 +4,8 @@
 package client
 
 import (
+	"time"
+
 	"github.com/juju/names"
 	"gopkg.in/juju/charm.v6-unstable"
 
@@ -25,6 +27,13 @@ type Unit interface {
 	PrivateAddress() (network.Address, error)
 	Resolve(retryHooks bool) error
 	AgentHistory() state.StatusHistoryGetter
+	HookRecords(size int) ([]state.HookRecord, error)
+}
+
+// MachineHistory represents the status histories of a state.Machine.
+type MachineHistory interface {
+	state.StatusHistoryGetter
+	InstanceStatusHistory(state.StatusHistoryFilter) ([]state.StatusInfo, error)
 }
 
 // stateInterface contains the state.State methods used in this package,
@@ -34,6 +43,10 @@ type stateInterface interface {
 	Unit(string) (Unit, error)
 	Service(string) (*state.Service, error)
 	Machine(string) (*state.Machine, error)
+	MachineHistory(string) (MachineHistory, error)
+	ServiceHistory(string) (state.StatusHistoryGetter, error)
+	VolumeStatusHistory(names.VolumeTag, state.StatusHistoryFilter) ([]state.StatusInfo, error)
+	FilesystemStatusHistory(names.FilesystemTag, state.StatusHistoryFilter) ([]state.StatusInfo, error)
 	AllMachines() ([]*state.Machine, error)
 	AllServices() ([]*state.Service, error)
 	AllRelations() ([]*state.Relation, error)
@@ -51,16 +64,22 @@ type stateInterface interface {
 	SetEnvironAgentVersion(version.Number) error
 	SetAnnotations(state.GlobalEntity, map[string]string) error
 	Annotations(state.GlobalEntity) (map[string]string, error)
+	AllLabels() (map[string]map[string]string, error)
 	InferEndpoints(...string) ([]state.Endpoint, error)
 	EndpointsRelation(...state.Endpoint) (*state.Relation, error)
 	Charm(*charm.URL) (*state.Charm, error)
 	LatestPlaceholderCharm(*charm.URL) (*state.Charm, error)
 	AddRelation(...state.Endpoint) (*state.Relation, error)
+	IsControllerAdministrator(names.UserTag) (bool, error)
+	EnvironmentUser(names.UserTag) (*state.EnvironmentUser, error)
 	AddEnvironmentUser(state.EnvUserSpec) (*state.EnvironmentUser, error)
 	RemoveEnvironmentUser(names.UserTag) error
 	Watch() *state.Multiwatcher
 	AbortCurrentUpgrade() error
 	APIHostPorts() ([][]network.HostPort, error)
+	StateServerEnvironment() (*state.Environment, error)
+	ControllerCertificates() (state.ControllerCertificates, error)
+	RotateControllerCertificates(newCACert, newCAPrivateKey string, overlap time.Duration) error
 }
 
 type stateShim struct {
@@ -74,3 +93,19 @@ func (s *stateShim) Unit(name string) (Unit, error) {
 	}
 	return u, nil
 }
+
+func (s *stateShim) MachineHistory(id string) (MachineHistory, error) {
+	m, err := s.State.Machine(id)
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (s *stateShim) ServiceHistory(name string) (state.StatusHistoryGetter, error) {
+	svc, err := s.State.Service(name)
+	if err != nil {
+		return nil, err
+	}
+	return svc, nil
+}
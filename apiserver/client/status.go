@@ -13,6 +13,7 @@ import (
 	"gopkg.in/juju/charm.v6-unstable"
 	"gopkg.in/juju/charm.v6-unstable/hooks"
 
+	"github.com/juju/juju/apiserver/common"
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/constraints"
 	"github.com/juju/juju/network"
@@ -59,14 +60,14 @@ func (c *Client) UnitStatusHistory(args params.StatusHistory) (params.UnitStatus
 	}
 	statuses := params.UnitStatusHistory{}
 	if args.Kind == params.KindCombined || args.Kind == params.KindWorkload {
-		unitStatuses, err := unit.StatusHistory(args.Size)
+		unitStatuses, err := unit.StatusHistory(state.StatusHistoryFilter{Size: args.Size})
 		if err != nil {
 			return params.UnitStatusHistory{}, errors.Trace(err)
 		}
 		statuses.Statuses = append(statuses.Statuses, agentStatusFromStatusInfo(unitStatuses, params.KindWorkload)...)
 	}
 	if args.Kind == params.KindCombined || args.Kind == params.KindAgent {
-		agentStatuses, err := unit.AgentHistory().StatusHistory(args.Size)
+		agentStatuses, err := unit.AgentHistory().StatusHistory(state.StatusHistoryFilter{Size: args.Size})
 		if err != nil {
 			return params.UnitStatusHistory{}, errors.Trace(err)
 		}
@@ -91,9 +92,20 @@ func (c *Client) FullStatus(args params.StatusParams) (params.FullStatus, error)
 		return params.FullStatus{}, errors.Annotate(err, "could not get environ config")
 	}
 	var noStatus params.FullStatus
+	var predicates []Predicate
+	if len(args.Patterns) > 0 {
+		predicates = append(predicates, BuildPredicateFor(args.Patterns))
+	}
+	if args.Selector != "" {
+		matcher, err := common.NewLabelMatcher(c.api.stateAccessor, args.Selector)
+		if err != nil {
+			return noStatus, errors.Trace(err)
+		}
+		predicates = append(predicates, BuildLabelPredicate(matcher))
+	}
 	var context statusContext
 	if context.services, context.units, context.latestCharms, err =
-		fetchAllServicesAndUnits(c.api.stateAccessor, len(args.Patterns) <= 0); err != nil {
+		fetchAllServicesAndUnits(c.api.stateAccessor, len(predicates) == 0); err != nil {
 		return noStatus, errors.Annotate(err, "could not fetch services and units")
 	} else if context.machines, err = fetchMachines(c.api.stateAccessor, nil); err != nil {
 		return noStatus, errors.Annotate(err, "could not fetch machines")
@@ -105,8 +117,8 @@ func (c *Client) FullStatus(args params.StatusParams) (params.FullStatus, error)
 
 	logger.Debugf("Services: %v", context.services)
 
-	if len(args.Patterns) > 0 {
-		predicate := BuildPredicateFor(args.Patterns)
+	if len(predicates) > 0 {
+		predicate := andPredicates(predicates...)
 
 		// Filter units
 		unfilteredSvcs := make(set.Strings)
@@ -181,13 +193,19 @@ func (c *Client) FullStatus(args params.StatusParams) (params.FullStatus, error)
 		return noStatus, errors.Annotate(err, "cannot determine if there is a new tools version available")
 	}
 
+	certificateWarning, err := c.certificateWarning()
+	if err != nil {
+		return noStatus, errors.Annotate(err, "cannot check controller certificates")
+	}
+
 	return params.FullStatus{
-		EnvironmentName:  cfg.Name(),
-		AvailableVersion: newToolsVersion,
-		Machines:         processMachines(context.machines),
-		Services:         context.processServices(),
-		Networks:         context.processNetworks(),
-		Relations:        context.processRelations(),
+		EnvironmentName:    cfg.Name(),
+		AvailableVersion:   newToolsVersion,
+		Machines:           processMachines(context.machines),
+		Services:           context.processServices(),
+		Networks:           context.processNetworks(),
+		Relations:          context.processRelations(),
+		CertificateWarning: certificateWarning,
 	}, nil
 }
 
@@ -573,6 +591,14 @@ func (context *statusContext) processService(service *state.Service) (status par
 	if ok && latestCharm != serviceCharmURL.String() {
 		status.CanUpgradeTo = latestCharm
 	}
+	if rollout, ok := service.CharmRollout(); ok {
+		status.CharmRollout = &params.CharmRolloutStatus{
+			FromCharm:   rollout.FromCharmURL.String(),
+			Released:    rollout.Released,
+			Paused:      rollout.Paused,
+			PauseReason: rollout.PauseReason,
+		}
+	}
 	var err error
 	status.Relations, status.SubordinateTo, err = context.processServiceRelations(service)
 	if err != nil {
@@ -672,6 +698,12 @@ func (context *statusContext) processUnit(unit *state.Unit, serviceCharm string)
 		result.Charm = curl.String()
 	}
 	processUnitAndAgentStatus(unit, &result)
+	populateStatusFromGetter(&result.Health, unit.Health())
+	if result.Health.Err == nil && result.Health.Since == nil {
+		// The unit's charm declares no health checks, or they
+		// have not run yet.
+		result.Health = params.AgentStatus{}
+	}
 
 	if subUnits := unit.SubordinateNames(); len(subUnits) > 0 {
 		result.Subordinates = make(map[string]params.UnitStatus)
@@ -46,6 +46,38 @@ func (s *statusSuite) TestFullStatus(c *gc.C) {
 	c.Check(resultMachine.Series, gc.Equals, machine.Series())
 }
 
+func (s *statusSuite) TestFullStatusSelector(c *gc.C) {
+	f := factory.NewFactory(s.State)
+	service := f.MakeService(c, &factory.ServiceParams{Name: "wordpress"})
+	canary := f.MakeUnit(c, &factory.UnitParams{Service: service})
+	stable := f.MakeUnit(c, &factory.UnitParams{Service: service})
+	other := f.MakeUnit(c, nil)
+	err := s.State.SetLabels(service, map[string]string{"tier": "web"})
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.SetLabels(canary, map[string]string{"env": "canary"})
+	c.Assert(err, jc.ErrorIsNil)
+
+	client := s.APIState.Client()
+	status, err := client.FilteredStatus(nil, "tier=web,env!=canary")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(status.Services, gc.HasLen, 1)
+	units := status.Services["wordpress"].Units
+	c.Check(units, gc.HasLen, 1)
+	c.Check(units[stable.Name()], gc.NotNil)
+	stableMachine, err := stable.AssignedMachineId()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(status.Machines, gc.HasLen, 1)
+	c.Check(status.Machines[stableMachine].Id, gc.Equals, stableMachine)
+
+	// Patterns and selectors must both match.
+	status, err = client.FilteredStatus([]string{other.ServiceName()}, "tier=web")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(status.Services, gc.HasLen, 0)
+
+	_, err = client.FilteredStatus(nil, "tier=web,")
+	c.Check(err, gc.ErrorMatches, `invalid selector "tier=web,": empty requirement`)
+}
+
 func (s *statusSuite) TestLegacyStatus(c *gc.C) {
 	machine := s.addMachine(c)
 	instanceId := "i-fakeinstance"
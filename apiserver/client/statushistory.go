@@ -0,0 +1,123 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package client
+
+import (
+	"sort"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+)
+
+// statusHistoryFunc adapts a function to state.StatusHistoryGetter.
+type statusHistoryFunc func(state.StatusHistoryFilter) ([]state.StatusInfo, error)
+
+// StatusHistory is part of the state.StatusHistoryGetter interface.
+func (f statusHistoryFunc) StatusHistory(filter state.StatusHistoryFilter) ([]state.StatusInfo, error) {
+	return f(filter)
+}
+
+// statusHistorySource is one of the status histories kept for an
+// entity, such as a unit's agent or workload history.
+type statusHistorySource struct {
+	kind   params.HistoryKind
+	getter state.StatusHistoryGetter
+}
+
+// statusHistorySources returns the status histories kept for the
+// entity with the given tag.
+func (c *Client) statusHistorySources(tag names.Tag) ([]statusHistorySource, error) {
+	st := c.api.stateAccessor
+	switch tag := tag.(type) {
+	case names.UnitTag:
+		unit, err := st.Unit(tag.Id())
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return []statusHistorySource{
+			{params.KindWorkload, unit},
+			{params.KindAgent, unit.AgentHistory()},
+			{params.KindHealth, unit.Health()},
+		}, nil
+	case names.MachineTag:
+		machine, err := st.MachineHistory(tag.Id())
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return []statusHistorySource{
+			{params.KindAgent, machine},
+			{params.KindInstance, statusHistoryFunc(machine.InstanceStatusHistory)},
+		}, nil
+	case names.ServiceTag:
+		service, err := st.ServiceHistory(tag.Id())
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return []statusHistorySource{{params.KindWorkload, service}}, nil
+	case names.VolumeTag:
+		return []statusHistorySource{{params.KindStorage, statusHistoryFunc(
+			func(filter state.StatusHistoryFilter) ([]state.StatusInfo, error) {
+				return st.VolumeStatusHistory(tag, filter)
+			},
+		)}}, nil
+	case names.FilesystemTag:
+		return []statusHistorySource{{params.KindStorage, statusHistoryFunc(
+			func(filter state.StatusHistoryFilter) ([]state.StatusInfo, error) {
+				return st.FilesystemStatusHistory(tag, filter)
+			},
+		)}}, nil
+	}
+	return nil, errors.NotSupportedf("status history for %s", names.ReadableString(tag))
+}
+
+// StatusHistory returns past statuses of a machine, unit, service,
+// volume or filesystem, oldest first. With the combined kind, the
+// histories of all kinds kept for the entity are merged, and at most
+// Size of the newest entries are returned.
+func (c *Client) StatusHistory(args params.EntityStatusHistoryArgs) (params.StatusHistoryResult, error) {
+	var result params.StatusHistoryResult
+	filter := state.StatusHistoryFilter{
+		Size:     args.Size,
+		FromDate: args.FromDate,
+		ToDate:   args.ToDate,
+	}
+	if err := filter.Validate(); err != nil {
+		return result, errors.Trace(err)
+	}
+	tag, err := names.ParseTag(args.Tag)
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	sources, err := c.statusHistorySources(tag)
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	kind := args.Kind
+	if kind == "" {
+		kind = params.KindCombined
+	}
+	found := false
+	for _, source := range sources {
+		if kind != params.KindCombined && kind != source.kind {
+			continue
+		}
+		found = true
+		statuses, err := source.getter.StatusHistory(filter)
+		if err != nil {
+			return params.StatusHistoryResult{}, errors.Trace(err)
+		}
+		result.Statuses = append(result.Statuses, agentStatusFromStatusInfo(statuses, source.kind)...)
+	}
+	if !found {
+		return result, errors.NotValidf("%s status history for %s", kind, names.ReadableString(tag))
+	}
+	sort.Sort(sortableStatuses(result.Statuses))
+	if args.Size > 0 && len(result.Statuses) > args.Size {
+		result.Statuses = result.Statuses[len(result.Statuses)-args.Size:]
+	}
+	return result, nil
+}
@@ -170,10 +170,100 @@ func (s *statusHistoryTestSuite) TestStatusHistoryCombined(c *gc.C) {
 	checkStatusInfo(c, h.Statuses, expected)
 }
 
+func (s *statusHistoryTestSuite) TestMachineStatusHistory(c *gc.C) {
+	s.st.machineHistory = statusInfoWithDates([]state.StatusInfo{
+		{Status: state.StatusStarted},
+		{Status: state.StatusError, Message: "no matching tools"},
+		{Status: state.StatusPending},
+	})
+	running := time.Unix(1001, 0)
+	s.st.instanceHistory = []state.StatusInfo{
+		{Status: "running", Since: &running},
+	}
+	h, err := s.api.StatusHistory(params.EntityStatusHistoryArgs{
+		Tag:  "machine-0",
+		Kind: params.KindAgent,
+		Size: 10,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	checkStatusInfo(c, h.Statuses, reverseStatusInfo(s.st.machineHistory))
+
+	h, err = s.api.StatusHistory(params.EntityStatusHistoryArgs{
+		Tag:  "machine-0",
+		Size: 2,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	checkStatusInfo(c, h.Statuses, []state.StatusInfo{
+		s.st.machineHistory[0],
+		s.st.instanceHistory[0],
+	})
+	c.Check(h.Statuses[0].Kind, gc.Equals, params.KindAgent)
+	c.Check(h.Statuses[1].Kind, gc.Equals, params.KindInstance)
+}
+
+func (s *statusHistoryTestSuite) TestStatusHistoryDateRange(c *gc.C) {
+	s.st.serviceHistory = statusInfoWithDates([]state.StatusInfo{
+		{Status: state.StatusActive},
+		{Status: state.StatusBlocked},
+		{Status: state.StatusMaintenance},
+	})
+	from := *s.st.serviceHistory[1].Since
+	h, err := s.api.StatusHistory(params.EntityStatusHistoryArgs{
+		Tag:      "service-wordpress",
+		FromDate: &from,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	checkStatusInfo(c, h.Statuses, reverseStatusInfo(s.st.serviceHistory[:2]))
+}
+
+func (s *statusHistoryTestSuite) TestVolumeStatusHistory(c *gc.C) {
+	s.st.volumeHistory = statusInfoWithDates([]state.StatusInfo{
+		{Status: state.StatusAttached},
+		{Status: state.StatusAttaching},
+	})
+	h, err := s.api.StatusHistory(params.EntityStatusHistoryArgs{
+		Tag:  "volume-0",
+		Kind: params.KindStorage,
+		Size: 10,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	checkStatusInfo(c, h.Statuses, reverseStatusInfo(s.st.volumeHistory))
+}
+
+func (s *statusHistoryTestSuite) TestStatusHistoryErrors(c *gc.C) {
+	for i, test := range []struct {
+		args params.EntityStatusHistoryArgs
+		err  string
+	}{{
+		args: params.EntityStatusHistoryArgs{Tag: "machine-0"},
+		err:  "status history filter without size or start date not valid",
+	}, {
+		args: params.EntityStatusHistoryArgs{Tag: "machine-0", Kind: params.KindWorkload, Size: 1},
+		err:  "workload status history for machine 0 not valid",
+	}, {
+		args: params.EntityStatusHistoryArgs{Tag: "machine-1", Size: 1},
+		err:  "machine 1 not found",
+	}, {
+		args: params.EntityStatusHistoryArgs{Tag: "user-bob", Size: 1},
+		err:  "status history for user bob not supported",
+	}, {
+		args: params.EntityStatusHistoryArgs{Tag: "0", Size: 1},
+		err:  `"0" is not a valid tag`,
+	}} {
+		c.Logf("test %d", i)
+		_, err := s.api.StatusHistory(test.args)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
+
 type mockState struct {
 	client.StateInterface
-	unitHistory  []state.StatusInfo
-	agentHistory []state.StatusInfo
+	unitHistory     []state.StatusInfo
+	agentHistory    []state.StatusInfo
+	machineHistory  []state.StatusInfo
+	instanceHistory []state.StatusInfo
+	serviceHistory  []state.StatusInfo
+	volumeHistory   []state.StatusInfo
 }
 
 func (m *mockState) EnvironUUID() string {
@@ -190,14 +280,35 @@ func (m *mockState) Unit(name string) (client.Unit, error) {
 	}, nil
 }
 
+func (m *mockState) MachineHistory(id string) (client.MachineHistory, error) {
+	if id != "0" {
+		return nil, errors.NotFoundf("machine %v", id)
+	}
+	return &mockMachine{m.machineHistory, m.instanceHistory}, nil
+}
+
+func (m *mockState) ServiceHistory(name string) (state.StatusHistoryGetter, error) {
+	if name != "wordpress" {
+		return nil, errors.NotFoundf("service %q", name)
+	}
+	return statuses(m.serviceHistory), nil
+}
+
+func (m *mockState) VolumeStatusHistory(tag names.VolumeTag, filter state.StatusHistoryFilter) ([]state.StatusInfo, error) {
+	if tag.Id() != "0" {
+		return nil, errors.NotFoundf("volume %q", tag.Id())
+	}
+	return statuses(m.volumeHistory).StatusHistory(filter)
+}
+
 type mockUnit struct {
 	status statuses
 	agent  *mockUnitAgent
 	client.Unit
 }
 
-func (m *mockUnit) StatusHistory(size int) ([]state.StatusInfo, error) {
-	return m.status.StatusHistory(size)
+func (m *mockUnit) StatusHistory(filter state.StatusHistoryFilter) ([]state.StatusInfo, error) {
+	return m.status.StatusHistory(filter)
 }
 
 func (m *mockUnit) AgentHistory() state.StatusHistoryGetter {
@@ -210,9 +321,28 @@ type mockUnitAgent struct {
 
 type statuses []state.StatusInfo
 
-func (s statuses) StatusHistory(size int) ([]state.StatusInfo, error) {
-	if size > len(s) {
-		size = len(s)
+func (s statuses) StatusHistory(filter state.StatusHistoryFilter) ([]state.StatusInfo, error) {
+	var result []state.StatusInfo
+	for _, info := range s {
+		if filter.FromDate != nil && info.Since.Before(*filter.FromDate) {
+			continue
+		}
+		if filter.ToDate != nil && info.Since.After(*filter.ToDate) {
+			continue
+		}
+		if filter.Size > 0 && len(result) == filter.Size {
+			break
+		}
+		result = append(result, info)
 	}
-	return s[:size], nil
+	return result, nil
+}
+
+type mockMachine struct {
+	statuses
+	instance statuses
+}
+
+func (m *mockMachine) InstanceStatusHistory(filter state.StatusHistoryFilter) ([]state.StatusInfo, error) {
+	return m.instance.StatusHistory(filter)
 }
@@ -19,6 +19,9 @@ type AddressAndCertGetter interface {
 	EnvironUUID() string
 	APIHostPorts() ([][]network.HostPort, error)
 	WatchAPIHostPorts() state.NotifyWatcher
+	WatchControllerCertificates() state.NotifyWatcher
+	MigrationRedirect() (*state.MigrationRedirect, error)
+	WatchMigration() state.NotifyWatcher
 }
 
 // APIAddresser implements the APIAddresses method
@@ -36,9 +39,23 @@ func NewAPIAddresser(getter AddressAndCertGetter, resources *Resources) *APIAddr
 	}
 }
 
+// apiHostPorts returns the API server addresses that agents should
+// connect to: those of the target controller while the environment
+// is being migrated.
+func (api *APIAddresser) apiHostPorts() ([][]network.HostPort, error) {
+	redirect, err := api.getter.MigrationRedirect()
+	if err != nil {
+		return nil, err
+	}
+	if redirect != nil {
+		return redirect.HostPorts, nil
+	}
+	return api.getter.APIHostPorts()
+}
+
 // APIHostPorts returns the API server addresses.
 func (api *APIAddresser) APIHostPorts() (params.APIHostPortsResult, error) {
-	servers, err := api.getter.APIHostPorts()
+	servers, err := api.apiHostPorts()
 	if err != nil {
 		return params.APIHostPortsResult{}, err
 	}
@@ -47,9 +64,10 @@ func (api *APIAddresser) APIHostPorts() (params.APIHostPortsResult, error) {
 	}, nil
 }
 
-// WatchAPIHostPorts watches the API server addresses.
+// WatchAPIHostPorts watches the API server addresses, and the
+// migration of the environment.
 func (api *APIAddresser) WatchAPIHostPorts() (params.NotifyWatchResult, error) {
-	watch := api.getter.WatchAPIHostPorts()
+	watch := NewMultiNotifyWatcher(api.getter.WatchAPIHostPorts(), api.getter.WatchMigration())
 	if _, ok := <-watch.Changes(); ok {
 		return params.NotifyWatchResult{
 			NotifyWatcherId: api.resources.Register(watch),
@@ -60,7 +78,7 @@ func (api *APIAddresser) WatchAPIHostPorts() (params.NotifyWatchResult, error) {
 
 // APIAddresses returns the list of addresses used to connect to the API.
 func (api *APIAddresser) APIAddresses() (params.StringsResult, error) {
-	apiHostPorts, err := api.getter.APIHostPorts()
+	apiHostPorts, err := api.apiHostPorts()
 	if err != nil {
 		return params.StringsResult{}, err
 	}
@@ -77,12 +95,34 @@ func (api *APIAddresser) APIAddresses() (params.StringsResult, error) {
 }
 
 // CACert returns the certificate used to validate the state connection.
+// While the environment is being migrated, the target controller's
+// certificates are trusted too.
 func (a *APIAddresser) CACert() params.BytesResult {
+	caCert := a.getter.CACert()
+	redirect, err := a.getter.MigrationRedirect()
+	if err != nil {
+		logger.Warningf("cannot get migration of environment: %v", err)
+	} else if redirect != nil {
+		caCert += "\n" + redirect.CACert
+	}
 	return params.BytesResult{
-		Result: []byte(a.getter.CACert()),
+		Result: []byte(caCert),
 	}
 }
 
+// WatchCACert watches the certificates used to validate the state
+// connection, which change when the controller's CA is rotated or
+// the environment is migrated.
+func (a *APIAddresser) WatchCACert() (params.NotifyWatchResult, error) {
+	watch := NewMultiNotifyWatcher(a.getter.WatchControllerCertificates(), a.getter.WatchMigration())
+	if _, ok := <-watch.Changes(); ok {
+		return params.NotifyWatchResult{
+			NotifyWatcherId: a.resources.Register(watch),
+		}, nil
+	}
+	return params.NotifyWatchResult{}, watcher.EnsureErr(watch)
+}
+
 // EnvironUUID returns the environment UUID to connect to the environment
 // that the current connection is for.
 func (a *APIAddresser) EnvironUUID() params.StringResult {
@@ -8,6 +8,7 @@ import (
 	gc "gopkg.in/check.v1"
 
 	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/network"
 	"github.com/juju/juju/state"
 )
@@ -51,6 +52,29 @@ func (s *apiAddresserSuite) TestCACert(c *gc.C) {
 	c.Assert(string(result.Result), gc.Equals, "a cert")
 }
 
+func (s *apiAddresserSuite) TestWatchCACert(c *gc.C) {
+	resources := common.NewResources()
+	addresser := common.NewAPIAddresser(fakeAddresses{}, resources)
+	result, err := addresser.WatchCACert()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.NotifyWatcherId, gc.Equals, "1")
+	c.Assert(resources.Count(), gc.Equals, 1)
+}
+
+func (s *apiAddresserSuite) TestAPIAddressesMigrating(c *gc.C) {
+	addresser := common.NewAPIAddresser(fakeAddresses{redirect: &state.MigrationRedirect{
+		HostPorts: [][]network.HostPort{network.NewHostPorts(17070, "target")},
+		CACert:    "target cert",
+	}}, common.NewResources())
+	result, err := addresser.APIAddresses()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Result, gc.DeepEquals, []string{"target:17070"})
+	hostPorts, err := addresser.APIHostPorts()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(params.NetworkHostsPorts(hostPorts.Servers), gc.DeepEquals, [][]network.HostPort{network.NewHostPorts(17070, "target")})
+	c.Assert(string(addresser.CACert().Result), gc.Equals, "a cert\ntarget cert")
+}
+
 func (s *apiAddresserSuite) TestEnvironUUID(c *gc.C) {
 	result := s.addresser.EnvironUUID()
 	c.Assert(string(result.Result), gc.Equals, "the environ uuid")
@@ -58,7 +82,9 @@ func (s *apiAddresserSuite) TestEnvironUUID(c *gc.C) {
 
 var _ common.AddressAndCertGetter = fakeAddresses{}
 
-type fakeAddresses struct{}
+type fakeAddresses struct {
+	redirect *state.MigrationRedirect
+}
 
 func (fakeAddresses) Addresses() ([]string, error) {
 	return []string{"addresses:1", "addresses:2"}, nil
@@ -86,3 +112,19 @@ func (fakeAddresses) APIHostPorts() ([][]network.HostPort, error) {
 func (fakeAddresses) WatchAPIHostPorts() state.NotifyWatcher {
 	panic("should never be called")
 }
+
+func (fakeAddresses) WatchControllerCertificates() state.NotifyWatcher {
+	changes := make(chan struct{}, 1)
+	changes <- struct{}{}
+	return &fakeNotifyWatcher{changes: changes}
+}
+
+func (f fakeAddresses) MigrationRedirect() (*state.MigrationRedirect, error) {
+	return f.redirect, nil
+}
+
+func (fakeAddresses) WatchMigration() state.NotifyWatcher {
+	changes := make(chan struct{}, 1)
+	changes <- struct{}{}
+	return &fakeNotifyWatcher{changes: changes}
+}
@@ -6,6 +6,7 @@ package common
 import (
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/environs"
+	"github.com/juju/juju/environs/config"
 	"github.com/juju/juju/state"
 	"github.com/juju/juju/state/watcher"
 )
@@ -61,6 +62,7 @@ func (e *EnvironWatcher) EnvironConfig() (params.EnvironConfigResult, error) {
 		return result, err
 	}
 	allAttrs := config.AllAttrs()
+	RemoveServerSecrets(allAttrs)
 
 	if !e.authorizer.AuthEnvironManager() {
 		// Mask out any secrets in the environment configuration
@@ -82,3 +84,11 @@ func (e *EnvironWatcher) EnvironConfig() (params.EnvironConfigResult, error) {
 	result.Config = allAttrs
 	return result, nil
 }
+
+// RemoveServerSecrets removes the settings that only the API server
+// may read from the given environment configuration attributes.
+func RemoveServerSecrets(attrs map[string]interface{}) {
+	for _, name := range config.ServerSecretAttributes {
+		delete(attrs, name)
+	}
+}
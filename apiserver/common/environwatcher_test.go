@@ -122,6 +122,26 @@ func (*environWatcherSuite) TestEnvironConfigMaskedSecrets(c *gc.C) {
 	c.Check(map[string]interface{}(result.Config), jc.DeepEquals, testingEnvConfig.AllAttrs())
 }
 
+func (*environWatcherSuite) TestEnvironConfigRemovesServerSecrets(c *gc.C) {
+	authorizer := apiservertesting.FakeAuthorizer{
+		Tag:            names.NewMachineTag("0"),
+		EnvironManager: true,
+	}
+	testingEnvConfig, err := testingEnvConfig(c).Apply(map[string]interface{}{
+		"ldap-bind-password": "sekrit",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	e := common.NewEnvironWatcher(
+		&fakeEnvironAccessor{envConfig: testingEnvConfig},
+		nil,
+		authorizer,
+	)
+	result, err := e.EnvironConfig()
+	c.Assert(err, jc.ErrorIsNil)
+	_, found := result.Config["ldap-bind-password"]
+	c.Check(found, jc.IsFalse)
+}
+
 func testingEnvConfig(c *gc.C) *config.Config {
 	cfg, err := config.New(config.NoDefaults, dummy.SampleConfig())
 	c.Assert(err, jc.ErrorIsNil)
@@ -0,0 +1,66 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package common
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/labels"
+)
+
+// LabelsGetter is the state method used by LabelMatcher.
+type LabelsGetter interface {
+	AllLabels() (map[string]map[string]string, error)
+}
+
+// LabelMatcher matches machines, services and units against a label
+// selector. Units inherit the labels of their service; labels set on
+// the unit itself take precedence.
+type LabelMatcher struct {
+	selector labels.Selector
+	all      map[string]map[string]string
+}
+
+// NewLabelMatcher parses the selector and returns a LabelMatcher
+// evaluating it against the labels currently in state.
+func NewLabelMatcher(st LabelsGetter, selector string) (*LabelMatcher, error) {
+	sel, err := labels.ParseSelector(selector)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	all, err := st.AllLabels()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &LabelMatcher{selector: sel, all: all}, nil
+}
+
+// MatchMachine returns whether the machine with the given id matches.
+func (m *LabelMatcher) MatchMachine(id string) bool {
+	return m.selector.Matches(m.all[names.NewMachineTag(id).String()])
+}
+
+// MatchService returns whether the named service matches.
+func (m *LabelMatcher) MatchService(name string) bool {
+	return m.selector.Matches(m.all[names.NewServiceTag(name).String()])
+}
+
+// MatchUnit returns whether the named unit matches.
+func (m *LabelMatcher) MatchUnit(name string) bool {
+	return m.selector.Matches(m.UnitLabels(name))
+}
+
+// UnitLabels returns the labels of the named unit, including those
+// inherited from its service.
+func (m *LabelMatcher) UnitLabels(name string) map[string]string {
+	serviceName, err := names.UnitService(name)
+	if err != nil {
+		return m.all[names.NewUnitTag(name).String()]
+	}
+	return labels.Merge(
+		m.all[names.NewServiceTag(serviceName).String()],
+		m.all[names.NewUnitTag(name).String()],
+	)
+}
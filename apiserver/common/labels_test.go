@@ -0,0 +1,63 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package common_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/common"
+)
+
+type labelMatcherSuite struct{}
+
+var _ = gc.Suite(&labelMatcherSuite{})
+
+type fakeLabelsGetter struct {
+	labels map[string]map[string]string
+	err    error
+}
+
+func (g fakeLabelsGetter) AllLabels() (map[string]map[string]string, error) {
+	return g.labels, g.err
+}
+
+var allLabels = fakeLabelsGetter{labels: map[string]map[string]string{
+	"machine-0":         {"zone": "east"},
+	"service-wordpress": {"tier": "web", "env": "prod"},
+	"unit-wordpress-1":  {"env": "canary"},
+}}
+
+func (*labelMatcherSuite) TestMatch(c *gc.C) {
+	m, err := common.NewLabelMatcher(allLabels, "tier=web,env!=canary")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(m.MatchService("wordpress"), jc.IsTrue)
+	c.Check(m.MatchService("mysql"), jc.IsFalse)
+	c.Check(m.MatchUnit("wordpress/0"), jc.IsTrue)
+	c.Check(m.MatchUnit("wordpress/1"), jc.IsFalse)
+	c.Check(m.MatchMachine("0"), jc.IsFalse)
+
+	m, err = common.NewLabelMatcher(allLabels, "zone=east")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(m.MatchMachine("0"), jc.IsTrue)
+	c.Check(m.MatchMachine("1"), jc.IsFalse)
+}
+
+func (*labelMatcherSuite) TestUnitLabels(c *gc.C) {
+	m, err := common.NewLabelMatcher(allLabels, "")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(m.UnitLabels("wordpress/1"), jc.DeepEquals, map[string]string{
+		"tier": "web",
+		"env":  "canary",
+	})
+}
+
+func (*labelMatcherSuite) TestErrors(c *gc.C) {
+	_, err := common.NewLabelMatcher(allLabels, "tier=web,")
+	c.Check(err, gc.ErrorMatches, `invalid selector "tier=web,": empty requirement`)
+
+	_, err = common.NewLabelMatcher(fakeLabelsGetter{err: errors.New("boom")}, "tier=web")
+	c.Check(err, gc.ErrorMatches, "boom")
+}
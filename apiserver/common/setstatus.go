@@ -251,3 +251,26 @@ func (ua *UnitAgentFinder) FindEntity(tag names.Tag) (state.Entity, error) {
 type hasAgent interface {
 	Agent() *state.UnitAgent
 }
+
+// UnitHealthFinder is a state.EntityFinder that finds the health of
+// units.
+type UnitHealthFinder struct {
+	state.EntityFinder
+}
+
+// FindEntity implements state.EntityFinder and returns unit health.
+func (uh *UnitHealthFinder) FindEntity(tag names.Tag) (state.Entity, error) {
+	_, ok := tag.(names.UnitTag)
+	if !ok {
+		return nil, errors.Errorf("unsupported tag %T", tag)
+	}
+	entity, err := uh.EntityFinder.FindEntity(tag)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return entity.(hasHealth).Health(), nil
+}
+
+type hasHealth interface {
+	Health() *state.UnitHealth
+}
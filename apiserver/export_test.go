@@ -30,6 +30,9 @@ var (
 	ParseLogLine          = parseLogLine
 	AgentMatchesFilter    = agentMatchesFilter
 	NewLogTailer          = &newLogTailer
+	NewDirectory          = &newDirectory
+	AccessCacheTTL        = &accessCacheTTL
+	TokenCacheTTL         = &tokenCacheTTL
 )
 
 func ServerMacaroon(srv *Server) (*macaroon.Macaroon, error) {
@@ -119,6 +122,67 @@ func TestingRestrictedApiHandler(st *state.State) rpc.MethodFinder {
 	return newRestrictedRoot(r)
 }
 
+// TestingAccessRoot returns a srvRoot restricted to the calls allowed
+// by the given access to the environment.
+func TestingAccessRoot(st *state.State, access state.EnvUserAccess) rpc.MethodFinder {
+	r := TestingApiRoot(st)
+	return newAccessRoot(r, func() (state.EnvUserAccess, error) {
+		return access, nil
+	})
+}
+
+// TestingAccessRootFunc returns a srvRoot restricted to the calls
+// allowed by the access currentAccess returns.
+func TestingAccessRootFunc(st *state.State, currentAccess func() (state.EnvUserAccess, error)) rpc.MethodFinder {
+	r := TestingApiRoot(st)
+	return newAccessRoot(r, currentAccess)
+}
+
+// TestingTokenScopeRoot returns a srvRoot restricted to calls to the
+// given facades.
+func TestingTokenScopeRoot(st *state.State, facades ...string) rpc.MethodFinder {
+	return TestingTokenRoot(st, func() ([]string, bool, error) {
+		return facades, false, nil
+	})
+}
+
+// TestingTokenRoot returns a srvRoot restricted to the calls allowed by
+// the API token currentToken describes, by the facades it allows and
+// whether it has expired.
+func TestingTokenRoot(st *state.State, currentToken func() (facades []string, expired bool, err error)) rpc.MethodFinder {
+	r := TestingApiRoot(st)
+	return newTokenRoot(r, func() (apiToken, error) {
+		facades, expired, err := currentToken()
+		if err != nil {
+			return nil, err
+		}
+		return fakeAPIToken{facades, expired}, nil
+	})
+}
+
+type fakeAPIToken struct {
+	facades []string
+	expired bool
+}
+
+func (t fakeAPIToken) Facades() []string {
+	return t.facades
+}
+
+func (t fakeAPIToken) Expired(time.Time) bool {
+	return t.expired
+}
+
+// TestingPasswordExpiredRoot returns a srvRoot restricted to the calls
+// allowed to users whose password has expired.
+func TestingPasswordExpiredRoot(st *state.State) rpc.MethodFinder {
+	r := TestingApiRoot(st)
+	return newPasswordExpiredRoot(r)
+}
+
+// FacadePermissions exposes the permission table for tests.
+var FacadePermissions = facadePermissions
+
 type preFacadeAdminApi struct{}
 
 func newPreFacadeAdminApi(srv *Server, root *apiHandler, reqNotifier *requestNotifier) interface{} {
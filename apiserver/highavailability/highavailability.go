@@ -16,6 +16,7 @@ import (
 
 func init() {
 	common.RegisterStandardFacade("HighAvailability", 1, NewHighAvailabilityAPI)
+	common.RegisterStandardFacade("HighAvailability", 2, NewHighAvailabilityAPIV2)
 }
 
 // HighAvailability defines the methods on the highavailability API end point.
@@ -33,6 +34,30 @@ type HighAvailabilityAPI struct {
 
 var _ HighAvailability = (*HighAvailabilityAPI)(nil)
 
+// HighAvailabilityV2 defines the methods on version 2 of the
+// highavailability API end point.
+type HighAvailabilityV2 interface {
+	HighAvailability
+	AddStateServerMembers(args params.StateServerMembersSpecs) (params.StateServersChangeResults, error)
+}
+
+// HighAvailabilityAPIV2 implements the HighAvailabilityV2 interface.
+type HighAvailabilityAPIV2 struct {
+	HighAvailabilityAPI
+}
+
+var _ HighAvailabilityV2 = (*HighAvailabilityAPIV2)(nil)
+
+// NewHighAvailabilityAPIV2 creates a new server-side highavailability
+// API end point, version 2.
+func NewHighAvailabilityAPIV2(st *state.State, resources *common.Resources, authorizer common.Authorizer) (*HighAvailabilityAPIV2, error) {
+	api, err := NewHighAvailabilityAPI(st, resources, authorizer)
+	if err != nil {
+		return nil, err
+	}
+	return &HighAvailabilityAPIV2{*api}, nil
+}
+
 // NewHighAvailabilityAPI creates a new server-side highavailability API end point.
 func NewHighAvailabilityAPI(st *state.State, resources *common.Resources, authorizer common.Authorizer) (*HighAvailabilityAPI, error) {
 	// Only clients and environment managers can access the high availability service.
@@ -56,6 +81,39 @@ func (api *HighAvailabilityAPI) EnsureAvailability(args params.StateServersSpecs
 	return results, nil
 }
 
+// AddStateServerMembers adds state servers that take part in the
+// mongo replica set without voting, such as those at a disaster
+// recovery site.
+func (api *HighAvailabilityAPIV2) AddStateServerMembers(args params.StateServerMembersSpecs) (params.StateServersChangeResults, error) {
+	results := params.StateServersChangeResults{Results: make([]params.StateServersChangeResult, len(args.Specs))}
+	for i, spec := range args.Specs {
+		result, err := addStateServerMembers(api.state, spec)
+		results.Results[i].Result = result
+		results.Results[i].Error = common.ServerError(err)
+	}
+	return results, nil
+}
+
+func addStateServerMembers(st *state.State, spec params.StateServerMembersSpec) (params.StateServersChanges, error) {
+	if err := checkStateServerChange(st, spec.EnvironTag); err != nil {
+		return params.StateServersChanges{}, errors.Trace(err)
+	}
+	series := spec.Series
+	if series == "" {
+		var err error
+		if series, err = stateServerSeries(st); err != nil {
+			return params.StateServersChanges{}, errors.Trace(err)
+		}
+	}
+	changes, err := st.AddStateServerMembers(
+		state.ReplicaSetRole(spec.Role), spec.Constraints, series, spec.Placement,
+	)
+	if err != nil {
+		return params.StateServersChanges{}, err
+	}
+	return stateServersChanges(changes), nil
+}
+
 // Convert machine ids to tags.
 func machineIdsToTags(ids ...string) []string {
 	var result []string
@@ -80,49 +138,66 @@ func stateServersChanges(change state.StateServersChanges) params.StateServersCh
 // EnsureAvailabilitySingle applies a single StateServersSpec specification to the current environment.
 // Exported so it can be called by the legacy client API in the client package.
 func EnsureAvailabilitySingle(st *state.State, spec params.StateServersSpec) (params.StateServersChanges, error) {
+	if err := checkStateServerChange(st, spec.EnvironTag); err != nil {
+		return params.StateServersChanges{}, errors.Trace(err)
+	}
+	series := spec.Series
+	if series == "" {
+		var err error
+		if series, err = stateServerSeries(st); err != nil {
+			return params.StateServersChanges{}, err
+		}
+	}
+	changes, err := st.EnsureAvailability(spec.NumStateServers, spec.Constraints, series, spec.Placement)
+	if err != nil {
+		return params.StateServersChanges{}, err
+	}
+	return stateServersChanges(changes), nil
+}
+
+// checkStateServerChange returns an error if the state servers of
+// the environment with the given tag, if any, may not be changed.
+func checkStateServerChange(st *state.State, environTag string) error {
 	if !st.IsStateServer() {
-		return params.StateServersChanges{}, errors.New("unsupported with hosted environments")
+		return errors.New("unsupported with hosted environments")
 	}
 	// Check if changes are allowed and the command may proceed.
 	blockChecker := common.NewBlockChecker(st)
 	if err := blockChecker.ChangeAllowed(); err != nil {
-		return params.StateServersChanges{}, errors.Trace(err)
+		return errors.Trace(err)
 	}
 	// Validate the environment tag if present.
-	if spec.EnvironTag != "" {
-		tag, err := names.ParseEnvironTag(spec.EnvironTag)
+	if environTag != "" {
+		tag, err := names.ParseEnvironTag(environTag)
 		if err != nil {
-			return params.StateServersChanges{}, errors.Errorf("invalid environment tag: %v", err)
+			return errors.Errorf("invalid environment tag: %v", err)
 		}
 		if _, err := st.FindEntity(tag); err != nil {
-			return params.StateServersChanges{}, err
+			return err
 		}
 	}
+	return nil
+}
 
-	series := spec.Series
-	if series == "" {
-		ssi, err := st.StateServerInfo()
-		if err != nil {
-			return params.StateServersChanges{}, err
-		}
+// stateServerSeries returns the series to use for new state server
+// machines when none is specified.
+func stateServerSeries(st *state.State) (string, error) {
+	ssi, err := st.StateServerInfo()
+	if err != nil {
+		return "", err
+	}
 
-		// We should always have at least one voting machine
-		// If we *really* wanted we could just pick whatever series is
-		// in the majority, but really, if we always copy the value of
-		// the first one, then they'll stay in sync.
-		if len(ssi.VotingMachineIds) == 0 {
-			// Better than a panic()?
-			return params.StateServersChanges{}, fmt.Errorf("internal error, failed to find any voting machines")
-		}
-		templateMachine, err := st.Machine(ssi.VotingMachineIds[0])
-		if err != nil {
-			return params.StateServersChanges{}, err
-		}
-		series = templateMachine.Series()
+	// We should always have at least one voting machine
+	// If we *really* wanted we could just pick whatever series is
+	// in the majority, but really, if we always copy the value of
+	// the first one, then they'll stay in sync.
+	if len(ssi.VotingMachineIds) == 0 {
+		// Better than a panic()?
+		return "", fmt.Errorf("internal error, failed to find any voting machines")
 	}
-	changes, err := st.EnsureAvailability(spec.NumStateServers, spec.Constraints, series, spec.Placement)
+	templateMachine, err := st.Machine(ssi.VotingMachineIds[0])
 	if err != nil {
-		return params.StateServersChanges{}, err
+		return "", err
 	}
-	return stateServersChanges(changes), nil
+	return templateMachine.Series(), nil
 }
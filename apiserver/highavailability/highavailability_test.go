@@ -360,3 +360,62 @@ func (s *clientSuite) TestEnsureAvailabilityHostedEnvErrors(c *gc.C) {
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(machines, gc.HasLen, 0)
 }
+
+func (s *clientSuite) addStateServerMembers(
+	c *gc.C, role state.ReplicaSetRole, series string, placement []string,
+) (params.StateServersChanges, error) {
+	haServer, err := highavailability.NewHighAvailabilityAPIV2(s.State, s.resources, s.authoriser)
+	c.Assert(err, jc.ErrorIsNil)
+	arg := params.StateServerMembersSpecs{
+		Specs: []params.StateServerMembersSpec{{
+			Role:      string(role),
+			Series:    series,
+			Placement: placement,
+		}}}
+	results, err := haServer.AddStateServerMembers(arg)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 1)
+	result := results.Results[0]
+	err = nil
+	if result.Error != nil {
+		err = result.Error
+	}
+	return result.Result, err
+}
+
+func (s *clientSuite) TestAddStateServerMembers(c *gc.C) {
+	_, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+
+	changes, err := s.addStateServerMembers(c, state.ReplicaSetHidden, defaultSeries, []string{"1", "dr-site"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(changes.Converted, gc.DeepEquals, []string{"machine-1"})
+	c.Assert(changes.Added, gc.DeepEquals, []string{"machine-2"})
+
+	info, err := s.State.StateServerInfo()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(info.MachineIds, jc.SameContents, []string{"0", "1", "2"})
+	c.Assert(info.VotingMachineIds, jc.SameContents, []string{"0"})
+	m, err := s.State.Machine("2")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(m.Series(), gc.Equals, "quantal")
+	c.Assert(m.Placement(), gc.Equals, "dr-site")
+	c.Assert(m.ReplicaSetRole(), gc.Equals, state.ReplicaSetHidden)
+	c.Assert(m.WantsVote(), jc.IsFalse)
+}
+
+func (s *clientSuite) TestAddStateServerMembersVoter(c *gc.C) {
+	_, err := s.addStateServerMembers(c, state.ReplicaSetVoter, defaultSeries, []string{"dr-site"})
+	c.Assert(err, gc.ErrorMatches, "use EnsureAvailability to add voting state servers")
+}
+
+func (s *clientSuite) TestBlockAddStateServerMembers(c *gc.C) {
+	s.BlockAllChanges(c, "TestBlockAddStateServerMembers")
+
+	_, err := s.addStateServerMembers(c, state.ReplicaSetNonVoter, defaultSeries, []string{"dr-site"})
+	s.AssertBlocked(c, err, "TestBlockAddStateServerMembers")
+
+	machines, err := s.State.AllMachines()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(machines, gc.HasLen, 1)
+}
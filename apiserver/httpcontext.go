@@ -15,6 +15,7 @@ import (
 	"github.com/juju/names"
 	"gopkg.in/macaroon-bakery.v1/httpbakery"
 
+	"github.com/juju/juju/apiserver/authentication"
 	"github.com/juju/juju/apiserver/common"
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/state"
@@ -68,8 +69,10 @@ func (ctxt *httpContext) stateForRequestAuthenticated(r *http.Request) (*state.S
 	if err != nil {
 		return nil, nil, errors.NewUnauthorized(err, "")
 	}
+	systemState := ctxt.srv.statePool.SystemState()
 	entity, _, err := checkCreds(st, req, true, ctxt.srv.authCtxt)
 	if err != nil {
+		recordFailedLogin(systemState, req, err)
 		// All errors other than a macaroon-discharge error count as
 		// unauthorized at this point.
 		if !common.IsDischargeRequiredError(err) {
@@ -77,9 +80,45 @@ func (ctxt *httpContext) stateForRequestAuthenticated(r *http.Request) (*state.S
 		}
 		return nil, nil, errors.Trace(err)
 	}
+	if err := checkHTTPToken(st, entity, req); err != nil {
+		return nil, nil, errors.NewUnauthorized(err, "")
+	}
+	// There is no way to change a password over HTTP, so users whose
+	// password has expired are refused.
+	passwordExpired, err := checkPasswordExpiry(systemState, entity, req)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	if passwordExpired {
+		return nil, nil, errors.NewUnauthorized(errPasswordExpired, "")
+	}
 	return st, entity, nil
 }
 
+// checkHTTPToken returns a permission denied error if the user logged
+// in with an API token that is restricted to some facades. The HTTP
+// endpoints are not facades, so only unrestricted tokens may be used
+// with them.
+func checkHTTPToken(st *state.State, entity state.Entity, req params.LoginRequest) error {
+	name, _, ok := authentication.ParseTokenCredentials(req.Credentials)
+	if !ok {
+		return nil
+	}
+	userTag, ok := entity.Tag().(names.UserTag)
+	if !ok {
+		return nil
+	}
+	token, err := st.APIToken(userTag, name)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if len(token.Facades()) > 0 {
+		logger.Debugf("API token %q of user %q is restricted to facades %v", name, userTag.Name(), token.Facades())
+		return common.ErrPerm
+	}
+	return nil
+}
+
 // stateForRequestAuthenticatedUser is like stateForRequestAuthenticated
 // except that it also verifies that the authenticated entity is a user.
 func (ctxt *httpContext) stateForRequestAuthenticatedUser(r *http.Request) (*state.State, state.Entity, error) {
@@ -95,6 +134,36 @@ func (ctxt *httpContext) stateForRequestAuthenticatedUser(r *http.Request) (*sta
 	}
 }
 
+// stateForRequestAuthorizedUser is like stateForRequestAuthenticatedUser
+// except that it also verifies that the user's access to the environment
+// includes the given access.
+func (ctxt *httpContext) stateForRequestAuthorizedUser(r *http.Request, required state.EnvUserAccess) (*state.State, state.Entity, error) {
+	st, entity, err := ctxt.stateForRequestAuthenticatedUser(r)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	if err := checkUserAccess(entity, required); err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	return st, entity, nil
+}
+
+// checkUserAccess returns a permission denied error if the given
+// entity is an environment user whose access to the environment does
+// not include the required access.
+func checkUserAccess(entity state.Entity, required state.EnvUserAccess) error {
+	user, ok := entity.(*environmentUserEntity)
+	if !ok {
+		return nil
+	}
+	access := user.envUser.Access()
+	if access.Includes(required) {
+		return nil
+	}
+	logger.Debugf("%s access does not allow %s", access, required)
+	return common.ErrPerm
+}
+
 // stateForRequestAuthenticatedUser is like stateForRequestAuthenticated
 // except that it also verifies that the authenticated entity is a user.
 func (ctxt *httpContext) stateForRequestAuthenticatedAgent(r *http.Request) (*state.State, state.Entity, error) {
@@ -0,0 +1,145 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package labels implements the Labels facade, used to set and get
+// the labels of machines, services and units, and to find the entities
+// matching a label selector.
+package labels
+
+import (
+	"sort"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+)
+
+func init() {
+	common.RegisterStandardFacade("Labels", 1, NewAPI)
+}
+
+var getState = func(st *state.State) labelsAccess {
+	return stateShim{st}
+}
+
+// API implements the Labels facade.
+type API struct {
+	access     labelsAccess
+	authorizer common.Authorizer
+}
+
+// NewAPI returns a new Labels facade.
+func NewAPI(
+	st *state.State,
+	resources *common.Resources,
+	authorizer common.Authorizer,
+) (*API, error) {
+	if !authorizer.AuthClient() {
+		return nil, common.ErrPerm
+	}
+	return &API{
+		access:     getState(st),
+		authorizer: authorizer,
+	}, nil
+}
+
+// Get returns the labels of the given entities. Each entity is treated
+// independently and, hence, will fail or succeed independently.
+func (api *API) Get(args params.Entities) params.LabelsGetResults {
+	results := params.LabelsGetResults{
+		Results: make([]params.LabelsGetResult, len(args.Entities)),
+	}
+	for i, arg := range args.Entities {
+		results.Results[i].EntityTag = arg.Tag
+		entity, err := api.findEntity(arg.Tag)
+		if err != nil {
+			results.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		labels, err := api.access.Labels(entity)
+		if err != nil {
+			results.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		results.Results[i].Labels = labels
+	}
+	return results
+}
+
+// Set sets labels on the given entities. Labels with an empty value
+// are removed.
+func (api *API) Set(args params.LabelsSet) params.ErrorResults {
+	results := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Labels)),
+	}
+	for i, arg := range args.Labels {
+		entity, err := api.findEntity(arg.EntityTag)
+		if err == nil {
+			err = api.access.SetLabels(entity, arg.Labels)
+		}
+		results.Results[i].Error = common.ServerError(err)
+	}
+	return results
+}
+
+// Match returns the names of the machines, services and units whose
+// labels match the given selector. Units inherit the labels of their
+// service.
+func (api *API) Match(args params.LabelSelector) (params.LabelMatchResult, error) {
+	var result params.LabelMatchResult
+	matcher, err := common.NewLabelMatcher(api.access, args.Selector)
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	machines, err := api.access.AllMachines()
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	for _, m := range machines {
+		if matcher.MatchMachine(m.Id()) {
+			result.Machines = append(result.Machines, m.Id())
+		}
+	}
+	services, err := api.access.AllServices()
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	for _, svc := range services {
+		if matcher.MatchService(svc.Name()) {
+			result.Services = append(result.Services, svc.Name())
+		}
+		units, err := svc.AllUnits()
+		if err != nil {
+			return result, errors.Trace(err)
+		}
+		for _, unit := range units {
+			if matcher.MatchUnit(unit.Name()) {
+				result.Units = append(result.Units, unit.Name())
+			}
+		}
+	}
+	sort.Strings(result.Services)
+	sort.Strings(result.Units)
+	return result, nil
+}
+
+func (api *API) findEntity(entityTag string) (state.GlobalEntity, error) {
+	tag, err := names.ParseTag(entityTag)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	entity0, err := api.access.FindEntity(tag)
+	if errors.IsNotFound(err) {
+		return nil, common.ErrPerm
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+	entity, ok := entity0.(state.GlobalEntity)
+	if !ok {
+		return nil, common.NotSupportedError(tag, "labels")
+	}
+	return entity, nil
+}
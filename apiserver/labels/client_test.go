@@ -0,0 +1,129 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package labels_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/labels"
+	"github.com/juju/juju/apiserver/params"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/testing/factory"
+)
+
+type labelsSuite struct {
+	jujutesting.JujuConnSuite
+
+	api     *labels.API
+	machine *state.Machine
+	service *state.Service
+	units   []*state.Unit
+}
+
+var _ = gc.Suite(&labelsSuite{})
+
+func (s *labelsSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	authorizer := apiservertesting.FakeAuthorizer{
+		Tag: s.AdminUserTag(c),
+	}
+	var err error
+	s.api, err = labels.NewAPI(s.State, nil, authorizer)
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.machine = s.Factory.MakeMachine(c, &factory.MachineParams{
+		Jobs: []state.MachineJob{state.JobHostUnits},
+	})
+	s.service = s.Factory.MakeService(c, &factory.ServiceParams{
+		Charm: s.Factory.MakeCharm(c, &factory.CharmParams{Name: "wordpress"}),
+	})
+	s.units = []*state.Unit{
+		s.Factory.MakeUnit(c, &factory.UnitParams{Service: s.service, Machine: s.machine}),
+		s.Factory.MakeUnit(c, &factory.UnitParams{Service: s.service}),
+	}
+}
+
+func (s *labelsSuite) TestNewAPIRequiresClient(c *gc.C) {
+	authorizer := apiservertesting.FakeAuthorizer{
+		Tag: s.machine.Tag(),
+	}
+	_, err := labels.NewAPI(s.State, nil, authorizer)
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *labelsSuite) TestSetGet(c *gc.C) {
+	setResults := s.api.Set(params.LabelsSet{Labels: []params.EntityLabels{{
+		EntityTag: s.machine.Tag().String(),
+		Labels:    map[string]string{"zone": "east"},
+	}, {
+		EntityTag: s.units[0].Tag().String(),
+		Labels:    map[string]string{"env": "canary"},
+	}, {
+		EntityTag: s.units[1].Tag().String(),
+		Labels:    map[string]string{"env": "can ary"},
+	}, {
+		EntityTag: "environment-" + s.State.EnvironUUID(),
+		Labels:    map[string]string{"env": "prod"},
+	}, {
+		EntityTag: "unit-mysql-0",
+		Labels:    map[string]string{"env": "prod"},
+	}}})
+	c.Assert(setResults.Results, gc.HasLen, 5)
+	c.Check(setResults.Results[0].Error, gc.IsNil)
+	c.Check(setResults.Results[1].Error, gc.IsNil)
+	c.Check(setResults.Results[2].Error, gc.ErrorMatches, `cannot set labels on unit wordpress/1: label value "can ary" not valid`)
+	c.Check(setResults.Results[3].Error, gc.ErrorMatches, `cannot set labels on environment .*: labels on environment .* not supported`)
+	c.Check(setResults.Results[4].Error, gc.ErrorMatches, "permission denied")
+
+	getResults := s.api.Get(params.Entities{Entities: []params.Entity{
+		{Tag: s.machine.Tag().String()},
+		{Tag: s.units[0].Tag().String()},
+		{Tag: s.units[1].Tag().String()},
+		{Tag: "invalid"},
+	}})
+	c.Assert(getResults.Results, jc.DeepEquals, []params.LabelsGetResult{{
+		EntityTag: s.machine.Tag().String(),
+		Labels:    map[string]string{"zone": "east"},
+	}, {
+		EntityTag: s.units[0].Tag().String(),
+		Labels:    map[string]string{"env": "canary"},
+	}, {
+		EntityTag: s.units[1].Tag().String(),
+		Labels:    map[string]string{},
+	}, {
+		EntityTag: "invalid",
+		Error:     &params.Error{Message: `"invalid" is not a valid tag`},
+	}})
+}
+
+func (s *labelsSuite) TestMatch(c *gc.C) {
+	err := s.State.SetLabels(s.service, map[string]string{"tier": "web"})
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.SetLabels(s.units[0], map[string]string{"env": "canary"})
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.SetLabels(s.machine, map[string]string{"tier": "web"})
+	c.Assert(err, jc.ErrorIsNil)
+
+	result, err := s.api.Match(params.LabelSelector{Selector: "tier=web,env!=canary"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(result, jc.DeepEquals, params.LabelMatchResult{
+		Machines: []string{s.machine.Id()},
+		Services: []string{"wordpress"},
+		Units:    []string{"wordpress/1"},
+	})
+
+	result, err = s.api.Match(params.LabelSelector{Selector: "env=canary"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(result, jc.DeepEquals, params.LabelMatchResult{
+		Units: []string{"wordpress/0"},
+	})
+}
+
+func (s *labelsSuite) TestMatchInvalidSelector(c *gc.C) {
+	_, err := s.api.Match(params.LabelSelector{Selector: "tier=web,,"})
+	c.Check(err, gc.ErrorMatches, `invalid selector "tier=web,,": empty requirement`)
+}
@@ -0,0 +1,23 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package labels
+
+import (
+	"github.com/juju/names"
+
+	"github.com/juju/juju/state"
+)
+
+type labelsAccess interface {
+	FindEntity(tag names.Tag) (state.Entity, error)
+	Labels(entity state.GlobalEntity) (map[string]string, error)
+	SetLabels(entity state.GlobalEntity, labels map[string]string) error
+	AllLabels() (map[string]map[string]string, error)
+	AllMachines() ([]*state.Machine, error)
+	AllServices() ([]*state.Service, error)
+}
+
+type stateShim struct {
+	*state.State
+}
@@ -0,0 +1,283 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// The migration package defines an API end point for moving
+// environments between controllers. It serves both the controller an
+// environment is migrated from and the one it is migrated to.
+package migration
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/description"
+)
+
+var logger = loggo.GetLogger("juju.apiserver.migration")
+
+func init() {
+	common.RegisterStandardFacade("Migration", 1, NewMigrationAPI)
+}
+
+// Migration defines the methods on the migration API end point.
+type Migration interface {
+	Start(args params.StartMigrationArgs) (params.MigrationStatus, error)
+	Export(args params.Entity) (params.BytesResult, error)
+	Import(args params.ImportEnvironmentArgs) (params.MigrationStatus, error)
+	SetPhase(args params.SetMigrationPhaseArgs) (params.MigrationStatus, error)
+	Abort(args params.AbortMigrationArgs) (params.MigrationStatus, error)
+	Status(args params.Entity) (params.MigrationStatus, error)
+	RemoveEnvironment(args params.Entity) error
+}
+
+// MigrationAPI implements the Migration interface and is the
+// concrete implementation of the api end point.
+type MigrationAPI struct {
+	state      *state.State
+	authorizer common.Authorizer
+}
+
+var _ Migration = (*MigrationAPI)(nil)
+
+// NewMigrationAPI creates a new api server endpoint for migrating
+// environments.
+func NewMigrationAPI(
+	st *state.State,
+	resources *common.Resources,
+	authorizer common.Authorizer,
+) (*MigrationAPI, error) {
+	if !authorizer.AuthClient() {
+		return nil, errors.Trace(common.ErrPerm)
+	}
+	apiUser, _ := authorizer.GetAuthTag().(names.UserTag)
+	isAdmin, err := st.IsControllerAdministrator(apiUser)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	// Migrating environments is only for controller administrators.
+	if !isAdmin {
+		return nil, errors.Trace(common.ErrPerm)
+	}
+	return &MigrationAPI{
+		state:      st,
+		authorizer: authorizer,
+	}, nil
+}
+
+// envState returns a State for the environment with the given tag,
+// and a func to release it. Environments other than the connection's
+// own may only be reached through the controller environment.
+func (api *MigrationAPI) envState(tagString string) (*state.State, func(), error) {
+	tag, err := names.ParseEnvironTag(tagString)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	if tag == api.state.EnvironTag() {
+		return api.state, func() {}, nil
+	}
+	if !api.state.IsStateServer() {
+		return nil, nil, errors.Trace(common.ErrPerm)
+	}
+	st, err := api.state.ForEnviron(tag)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	// Make sure that the agents' presence is known.
+	st.StartSync()
+	return st, func() {
+		if err := st.Close(); err != nil {
+			logger.Errorf("cannot close state for environment %s: %v", tag.Id(), err)
+		}
+	}, nil
+}
+
+// migration returns the migration of the environment with the given
+// tag known to this controller.
+func (api *MigrationAPI) migration(tagString string) (*state.Migration, error) {
+	tag, err := names.ParseEnvironTag(tagString)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if tag != api.state.EnvironTag() && !api.state.IsStateServer() {
+		return nil, errors.Trace(common.ErrPerm)
+	}
+	return api.state.GetMigration(tag)
+}
+
+// Start freezes an environment of this controller and starts its
+// migration to the target controller.
+func (api *MigrationAPI) Start(args params.StartMigrationArgs) (params.MigrationStatus, error) {
+	target, err := migrationSpec(args.Target)
+	if err != nil {
+		return params.MigrationStatus{}, errors.Trace(err)
+	}
+	st, closer, err := api.envState(args.EnvironTag)
+	if err != nil {
+		return params.MigrationStatus{}, errors.Trace(err)
+	}
+	defer closer()
+	m, err := st.StartMigration(target)
+	if err != nil {
+		return params.MigrationStatus{}, errors.Trace(err)
+	}
+	return migrationStatus(m, nil), nil
+}
+
+// Export returns the serialised description of a migrating
+// environment, to be imported into the target controller. The
+// archives of the environment's charms are downloaded over HTTPS.
+func (api *MigrationAPI) Export(args params.Entity) (params.BytesResult, error) {
+	st, closer, err := api.envState(args.Tag)
+	if err != nil {
+		return params.BytesResult{}, errors.Trace(err)
+	}
+	defer closer()
+	desc, err := st.ExportEnvironment()
+	if err != nil {
+		return params.BytesResult{}, errors.Trace(err)
+	}
+	data, err := description.Serialize(desc)
+	if err != nil {
+		return params.BytesResult{}, errors.Trace(err)
+	}
+	return params.BytesResult{Result: data}, nil
+}
+
+// Import imports an environment migrating from the source controller
+// into this controller. The archives of the environment's charms are
+// then uploaded over HTTPS, and the environment is activated by moving
+// its migration to the "repoint" phase.
+func (api *MigrationAPI) Import(args params.ImportEnvironmentArgs) (params.MigrationStatus, error) {
+	source, err := migrationSpec(args.Source)
+	if err != nil {
+		return params.MigrationStatus{}, errors.Trace(err)
+	}
+	desc, err := description.Deserialize(args.Bytes)
+	if err != nil {
+		return params.MigrationStatus{}, errors.Trace(err)
+	}
+	m, err := api.state.ImportEnvironment(desc, source)
+	if err != nil {
+		return params.MigrationStatus{}, errors.Trace(err)
+	}
+	return migrationStatus(m, nil), nil
+}
+
+// SetPhase moves the migration of an environment to its next phase.
+func (api *MigrationAPI) SetPhase(args params.SetMigrationPhaseArgs) (params.MigrationStatus, error) {
+	m, err := api.migration(args.EnvironTag)
+	if err != nil {
+		return params.MigrationStatus{}, errors.Trace(err)
+	}
+	if err := m.SetPhase(state.MigrationPhase(args.Phase)); err != nil {
+		return params.MigrationStatus{}, errors.Trace(err)
+	}
+	return migrationStatus(m, nil), nil
+}
+
+// Abort aborts the migration of an environment.
+func (api *MigrationAPI) Abort(args params.AbortMigrationArgs) (params.MigrationStatus, error) {
+	m, err := api.migration(args.EnvironTag)
+	if err != nil {
+		return params.MigrationStatus{}, errors.Trace(err)
+	}
+	if err := m.Abort(args.Reason); err != nil {
+		return params.MigrationStatus{}, errors.Trace(err)
+	}
+	return migrationStatus(m, nil), nil
+}
+
+// Status returns the migration of an environment, and which of the
+// environment's agents are connected to this controller.
+func (api *MigrationAPI) Status(args params.Entity) (params.MigrationStatus, error) {
+	m, err := api.migration(args.Tag)
+	if err != nil {
+		return params.MigrationStatus{}, errors.Trace(err)
+	}
+	st, closer, err := api.envState(args.Tag)
+	if err != nil {
+		return params.MigrationStatus{}, errors.Trace(err)
+	}
+	defer closer()
+	agents, err := connectedAgents(st)
+	if err != nil {
+		return params.MigrationStatus{}, errors.Trace(err)
+	}
+	return migrationStatus(m, agents), nil
+}
+
+// RemoveEnvironment removes a migrated environment from this
+// controller, leaving its machines alone.
+func (api *MigrationAPI) RemoveEnvironment(args params.Entity) error {
+	m, err := api.migration(args.Tag)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(m.RemoveEnvironment())
+}
+
+// connectedAgents returns the tags of the machine and unit agents of
+// the environment that are connected to this controller.
+func connectedAgents(st *state.State) ([]string, error) {
+	var agents []string
+	machines, err := st.AllMachines()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	for _, machine := range machines {
+		alive, err := machine.AgentPresence()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if alive {
+			agents = append(agents, machine.Tag().String())
+		}
+	}
+	services, err := st.AllServices()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	for _, service := range services {
+		units, err := service.AllUnits()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		for _, unit := range units {
+			alive, err := unit.AgentPresence()
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			if alive {
+				agents = append(agents, unit.Tag().String())
+			}
+		}
+	}
+	return agents, nil
+}
+
+func migrationSpec(info params.MigrationControllerInfo) (state.MigrationSpec, error) {
+	tag, err := names.ParseEnvironTag(info.ControllerTag)
+	if err != nil {
+		return state.MigrationSpec{}, errors.Trace(err)
+	}
+	return state.MigrationSpec{
+		Controller: tag,
+		HostPorts:  params.NetworkHostsPorts(info.Addrs),
+		CACert:     info.CACert,
+	}, nil
+}
+
+func migrationStatus(m *state.Migration, agents []string) params.MigrationStatus {
+	return params.MigrationStatus{
+		EnvironTag:      m.EnvironTag().String(),
+		Role:            string(m.Role()),
+		Phase:           string(m.Phase()),
+		PhaseChanged:    m.PhaseChanged(),
+		Reason:          m.Reason(),
+		ConnectedAgents: agents,
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"gopkg.in/juju/charm.v6-unstable"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+)
+
+// migrationCharmsHandler moves the charm archives of an environment
+// between controllers through HTTPS: it serves them from the source
+// controller of a migration, and stores them on the target controller.
+type migrationCharmsHandler struct {
+	ctxt httpContext
+}
+
+func (h *migrationCharmsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	st, entity, err := h.ctxt.stateForRequestAuthenticatedUser(r)
+	if err != nil {
+		sendError(w, err)
+		return
+	}
+	m, curl, err := h.migrationCharm(r, st, entity.Tag().(names.UserTag))
+	if err != nil {
+		sendError(w, err)
+		return
+	}
+
+	switch r.Method {
+	case "GET":
+		if err := h.processGet(w, m, curl); err != nil {
+			sendError(w, err)
+		}
+	case "PUT":
+		if err := h.processPut(r, m, curl); err != nil {
+			sendError(w, err)
+			return
+		}
+		sendStatusAndJSON(w, http.StatusOK, &params.ErrorResult{})
+	default:
+		sendError(w, errors.MethodNotAllowedf("unsupported method: %q", r.Method))
+	}
+}
+
+// migrationCharm returns the migration of the environment and the
+// charm named in the request, which may only be made by a controller
+// administrator.
+func (h *migrationCharmsHandler) migrationCharm(r *http.Request, st *state.State, user names.UserTag) (*state.Migration, *charm.URL, error) {
+	isAdmin, err := st.IsControllerAdministrator(user)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	if !isAdmin {
+		return nil, nil, common.ErrPerm
+	}
+	envTag, err := names.ParseEnvironTag(r.URL.Query().Get("environ"))
+	if err != nil {
+		return nil, nil, errors.NewBadRequest(err, "")
+	}
+	curl, err := charm.ParseURL(r.URL.Query().Get("url"))
+	if err != nil {
+		return nil, nil, errors.NewBadRequest(err, "")
+	}
+	m, err := st.GetMigration(envTag)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	return m, curl, nil
+}
+
+// processGet sends the charm archive of an environment migrating from
+// this controller.
+func (h *migrationCharmsHandler) processGet(w http.ResponseWriter, m *state.Migration, curl *charm.URL) error {
+	archive, size, err := m.CharmArchive(curl)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer archive.Close()
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, archive); err != nil {
+		// The headers have been sent, so the error can only be logged.
+		logger.Errorf("error sending archive of charm %q: %v", curl, err)
+	}
+	return nil
+}
+
+// processPut stores the request body as the charm archive of an
+// environment being imported into this controller.
+func (h *migrationCharmsHandler) processPut(r *http.Request, m *state.Migration, curl *charm.URL) error {
+	if r.ContentLength < 0 {
+		return errors.BadRequestf("missing Content-Length")
+	}
+	return errors.Trace(m.AddCharmArchive(curl, r.Body, r.ContentLength))
+}
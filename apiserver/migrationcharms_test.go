@@ -0,0 +1,100 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/utils"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/charm.v6-unstable"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/network"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/storage"
+	"github.com/juju/juju/testcharms"
+)
+
+type migrationCharmsSuite struct {
+	authHttpSuite
+	envState *state.State
+	curl     *charm.URL
+}
+
+var _ = gc.Suite(&migrationCharmsSuite{})
+
+func (s *migrationCharmsSuite) SetUpTest(c *gc.C) {
+	s.authHttpSuite.SetUpTest(c)
+	s.envState = s.Factory.MakeEnvironment(c, nil)
+	s.AddCleanup(func(*gc.C) { s.envState.Close() })
+
+	content := "wordpress archive"
+	hash := sha256.Sum256([]byte(content))
+	s.curl = charm.MustParseURL("cs:quantal/wordpress-3")
+	_, err := s.envState.AddCharm(testcharms.Repo.CharmDir("wordpress"), s.curl, "charms/wordpress", hex.EncodeToString(hash[:]))
+	c.Assert(err, jc.ErrorIsNil)
+	stor := storage.NewStorage(s.envState.EnvironUUID(), s.envState.MongoSession())
+	err = stor.Put("charms/wordpress", strings.NewReader(content), int64(len(content)))
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = s.envState.StartMigration(state.MigrationSpec{
+		Controller: names.NewEnvironTag(utils.MustNewUUID().String()),
+		HostPorts:  [][]network.HostPort{network.NewHostPorts(17070, "10.0.0.1")},
+		CACert:     "target cert",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *migrationCharmsSuite) charmsURL(c *gc.C) string {
+	uri := s.baseURL(c)
+	uri.Path = fmt.Sprintf("/environment/%s/migration/charms", s.State.EnvironUUID())
+	uri.RawQuery = url.Values{
+		"environ": {s.envState.EnvironTag().String()},
+		"url":     {s.curl.String()},
+	}.Encode()
+	return uri.String()
+}
+
+func (s *migrationCharmsSuite) assertError(c *gc.C, resp *http.Response, statusCode int, msg string) {
+	body := assertResponse(c, resp, statusCode, params.ContentTypeJSON)
+	var result params.ErrorResult
+	err := json.Unmarshal(body, &result)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Error, gc.ErrorMatches, msg)
+}
+
+func (s *migrationCharmsSuite) TestRequiresAuth(c *gc.C) {
+	resp := s.sendRequest(c, httpRequestParams{method: "GET", url: s.charmsURL(c)})
+	s.assertError(c, resp, http.StatusUnauthorized, "no credentials provided")
+}
+
+func (s *migrationCharmsSuite) TestInvalidHTTPMethod(c *gc.C) {
+	resp := s.authRequest(c, httpRequestParams{method: "POST", url: s.charmsURL(c)})
+	s.assertError(c, resp, http.StatusMethodNotAllowed, `unsupported method: "POST"`)
+}
+
+func (s *migrationCharmsSuite) TestGet(c *gc.C) {
+	resp := s.authRequest(c, httpRequestParams{method: "GET", url: s.charmsURL(c)})
+	body := assertResponse(c, resp, http.StatusOK, "application/zip")
+	c.Assert(string(body), gc.Equals, "wordpress archive")
+}
+
+func (s *migrationCharmsSuite) TestPutIntoSource(c *gc.C) {
+	resp := s.authRequest(c, httpRequestParams{
+		method:      "PUT",
+		url:         s.charmsURL(c),
+		contentType: "application/zip",
+		body:        strings.NewReader("wordpress archive"),
+	})
+	s.assertError(c, resp, http.StatusInternalServerError, `cannot import charm archive in migration phase "quiesce"`)
+}
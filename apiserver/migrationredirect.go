@@ -0,0 +1,81 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"launchpad.net/tomb"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/watcher"
+)
+
+// redirectGracePeriod is how long an agent stays connected once its
+// environment's migration redirects it to another controller. It
+// gives the agent time to pick up and save the other controller's
+// API addresses and CA certificates before it has to reconnect.
+var redirectGracePeriod = time.Minute
+
+// migrationRedirector invokes an action, which closes the agent's
+// connection, once the environment's migration has redirected the
+// agent to another controller for the grace period.
+type migrationRedirector struct {
+	tomb   tomb.Tomb
+	st     *state.State
+	action func()
+	period time.Duration
+}
+
+// newMigrationRedirector returns a new migrationRedirector that
+// watches the migration of the environment of st.
+func newMigrationRedirector(st *state.State, action func(), period time.Duration) *migrationRedirector {
+	r := &migrationRedirector{
+		st:     st,
+		action: action,
+		period: period,
+	}
+	go func() {
+		defer r.tomb.Done()
+		r.tomb.Kill(r.loop())
+	}()
+	return r
+}
+
+// Stop terminates the redirector.
+func (r *migrationRedirector) Stop() error {
+	r.tomb.Kill(nil)
+	return r.tomb.Wait()
+}
+
+func (r *migrationRedirector) loop() error {
+	w := r.st.WatchMigration()
+	defer watcher.Stop(w, &r.tomb)
+
+	var timeout <-chan time.Time
+	for {
+		select {
+		case <-r.tomb.Dying():
+			return tomb.ErrDying
+		case _, ok := <-w.Changes():
+			if !ok {
+				return watcher.EnsureErr(w)
+			}
+			redirect, err := r.st.MigrationRedirect()
+			if err != nil {
+				return errors.Trace(err)
+			}
+			if redirect == nil {
+				timeout = nil
+			} else if timeout == nil {
+				timeout = time.After(r.period)
+			}
+		case <-timeout:
+			logger.Infof("closing connection of agent redirected to another controller")
+			r.action()
+			return nil
+		}
+	}
+}
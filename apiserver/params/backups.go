@@ -12,6 +12,20 @@ import (
 // BackupsCreateArgs holds the args for the API Create method.
 type BackupsCreateArgs struct {
 	Notes string
+	// Encryption holds the key used to encrypt the new archive, if any.
+	Encryption BackupsEncryptionKey
+}
+
+// BackupsEncryptionKey holds the key material used to encrypt or decrypt
+// a backup archive. A zero value means no encryption. There is
+// deliberately no private key: archives encrypted with a public key are
+// only ever decrypted by the client.
+type BackupsEncryptionKey struct {
+	// Passphrase is used for symmetric encryption.
+	Passphrase string
+	// PublicKey is a PEM encoded RSA public key, used when creating
+	// an archive.
+	PublicKey string
 }
 
 // BackupsInfoArgs holds the args for the API Info method.
@@ -21,6 +35,9 @@ type BackupsInfoArgs struct {
 
 // BackupsListArgs holds the args for the API List method.
 type BackupsListArgs struct {
+	// Remote requests the backups in the controller's remote backup
+	// storage rather than those stored locally.
+	Remote bool
 }
 
 // BackupsDownloadArgs holds the args for the API Download method.
@@ -66,10 +83,25 @@ type BackupsMetadataResult struct {
 	Machine     string
 	Hostname    string
 	Version     version.Number
+	Encryption  string
 }
 
 // RestoreArgs Holds the backup file or id
 type RestoreArgs struct {
 	// BackupId holds the id of the backup in server if any
 	BackupId string
+	// Encryption holds the key used to decrypt the backup, if it is
+	// encrypted.
+	Encryption BackupsEncryptionKey
+	// FromRemote indicates that the backup should be fetched from the
+	// controller's remote backup storage.
+	FromRemote bool
+}
+
+// BackupsRemoteConfig holds the configuration of the remote storage
+// to which a controller copies completed backups. An empty Type means
+// there is no remote storage.
+type BackupsRemoteConfig struct {
+	Type  string
+	Attrs map[string]string
 }
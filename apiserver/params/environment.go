@@ -37,14 +37,28 @@ type EnvironAction string
 
 // Actions that can be preformed on an environment.
 const (
-	AddEnvUser    EnvironAction = "add"
-	RemoveEnvUser EnvironAction = "remove"
+	AddEnvUser      EnvironAction = "add"
+	RemoveEnvUser   EnvironAction = "remove"
+	GrantEnvAccess  EnvironAction = "grant"
+	RevokeEnvAccess EnvironAction = "revoke"
+)
+
+// Access levels that can be granted to environment users.
+const (
+	EnvViewerAccess   = "viewer"
+	EnvOperatorAccess = "operator"
+	EnvDeployerAccess = "deployer"
+	EnvAdminAccess    = "admin"
 )
 
 // ModifyEnvironUser stores the parameters used for a Client.ShareEnvironment call.
 type ModifyEnvironUser struct {
 	UserTag string        `json:"user-tag"`
 	Action  EnvironAction `json:"action"`
+
+	// Access is the access to grant or revoke. When revoking, an
+	// empty Access removes all of the user's access.
+	Access string `json:"access,omitempty"`
 }
 
 // SetEnvironAgentVersion contains the arguments for
@@ -60,6 +74,7 @@ type EnvUserInfo struct {
 	CreatedBy      string     `json:"createdby"`
 	DateCreated    time.Time  `json:"datecreated"`
 	LastConnection *time.Time `json:"lastconnection"`
+	Access         string     `json:"access,omitempty"`
 }
 
 // EnvUserInfoResult holds the result of an EnvUserInfo call.
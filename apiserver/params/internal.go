@@ -530,13 +530,18 @@ type CharmsResponse struct {
 
 // RunParams is used to provide the parameters to the Run method.
 // Commands and Timeout are expected to have values, and one or more
-// values should be in the Machines, Services, or Units slices.
+// values should be in the Machines, Services, or Units slices, or
+// a label selector should be given in Selector.
 type RunParams struct {
 	Commands string
 	Timeout  time.Duration
 	Machines []string
 	Services []string
 	Units    []string
+
+	// Selector, if not empty, adds the units whose labels match it
+	// to the units the commands are run on.
+	Selector string `json:",omitempty"`
 }
 
 // RunResult contains the result from an individual run call on a machine.
@@ -631,3 +636,36 @@ type MeterStatusResult struct {
 type MeterStatusResults struct {
 	Results []MeterStatusResult
 }
+
+// HookRecord describes one execution of a hook by a unit.
+type HookRecord struct {
+	Hook       string
+	RelationId int
+	RemoteUnit string
+	Started    time.Time
+	Duration   time.Duration
+	ExitStatus int
+	Stderr     []string
+}
+
+// HookRecordParam holds a hook record for a unit.
+type HookRecordParam struct {
+	Tag    string
+	Record HookRecord
+}
+
+// HookRecordParams holds hook records for multiple units.
+type HookRecordParams struct {
+	Records []HookRecordParam
+}
+
+// HookTimeoutResult holds how long a unit's hooks may run, or an error.
+type HookTimeoutResult struct {
+	Timeout time.Duration
+	Error   *Error
+}
+
+// HookTimeoutResults holds the results of a HookTimeouts call.
+type HookTimeoutResults struct {
+	Results []HookTimeoutResult
+}
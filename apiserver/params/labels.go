@@ -0,0 +1,44 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package params
+
+// LabelsGetResult holds the labels of an entity, or the error
+// retrieving them.
+type LabelsGetResult struct {
+	EntityTag string
+	Labels    map[string]string
+	Error     *Error
+}
+
+// LabelsGetResults holds the labels of several entities.
+type LabelsGetResults struct {
+	Results []LabelsGetResult
+}
+
+// LabelsSet holds the parameters for making the Set call on the Labels
+// facade.
+type LabelsSet struct {
+	Labels []EntityLabels
+}
+
+// EntityLabels holds labels to be set on an entity. Labels with an
+// empty value are removed.
+type EntityLabels struct {
+	EntityTag string
+	Labels    map[string]string
+}
+
+// LabelSelector holds a label selector, as parsed by
+// labels.ParseSelector.
+type LabelSelector struct {
+	Selector string
+}
+
+// LabelMatchResult holds the names of the machines, services and units
+// matching a label selector.
+type LabelMatchResult struct {
+	Machines []string
+	Services []string
+	Units    []string
+}
@@ -0,0 +1,58 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package params
+
+import (
+	"time"
+)
+
+// MigrationControllerInfo describes a controller taking part in the
+// migration of an environment.
+type MigrationControllerInfo struct {
+	ControllerTag string       `json:"controller-tag"`
+	Addrs         [][]HostPort `json:"addrs"`
+	CACert        string       `json:"ca-cert"`
+}
+
+// StartMigrationArgs holds the arguments for starting the migration
+// of an environment to another controller.
+type StartMigrationArgs struct {
+	EnvironTag string                  `json:"environ-tag"`
+	Target     MigrationControllerInfo `json:"target"`
+}
+
+// ImportEnvironmentArgs holds the arguments for importing an
+// environment migrated from another controller.
+type ImportEnvironmentArgs struct {
+	Bytes  []byte                  `json:"bytes"`
+	Source MigrationControllerInfo `json:"source"`
+}
+
+// SetMigrationPhaseArgs holds the arguments for moving the migration
+// of an environment to its next phase.
+type SetMigrationPhaseArgs struct {
+	EnvironTag string `json:"environ-tag"`
+	Phase      string `json:"phase"`
+}
+
+// AbortMigrationArgs holds the arguments for aborting the migration
+// of an environment.
+type AbortMigrationArgs struct {
+	EnvironTag string `json:"environ-tag"`
+	Reason     string `json:"reason"`
+}
+
+// MigrationStatus describes the migration of an environment, as
+// known to one of the controllers taking part in it.
+type MigrationStatus struct {
+	EnvironTag   string    `json:"environ-tag"`
+	Role         string    `json:"role"`
+	Phase        string    `json:"phase"`
+	PhaseChanged time.Time `json:"phase-changed"`
+	Reason       string    `json:"reason,omitempty"`
+
+	// ConnectedAgents holds the tags of the environment's agents
+	// that are connected to the controller.
+	ConnectedAgents []string `json:"connected-agents,omitempty"`
+}
@@ -564,3 +564,32 @@ type Space struct {
 	Subnets []Subnet `json:"Subnets"`
 	Error   *Error   `json:"Error,omitempty"`
 }
+
+// NetworkInfoParams holds the unit tag and the names of the relation
+// endpoints whose network information is requested.
+type NetworkInfoParams struct {
+	Unit     string   `json:"Unit"`
+	Bindings []string `json:"Bindings"`
+}
+
+// NetworkInfo describes the address of a unit used for one of its
+// service's relation endpoints.
+type NetworkInfo struct {
+	SpaceName     string `json:"SpaceName,omitempty"`
+	Address       string `json:"Address"`
+	InterfaceName string `json:"InterfaceName,omitempty"`
+	CIDR          string `json:"CIDR,omitempty"`
+}
+
+// NetworkInfoResult holds the network information for a single
+// endpoint, or an error.
+type NetworkInfoResult struct {
+	Info  NetworkInfo `json:"Info"`
+	Error *Error      `json:"Error,omitempty"`
+}
+
+// NetworkInfoResults holds the network information for the requested
+// endpoints, keyed by endpoint name.
+type NetworkInfoResults struct {
+	Results map[string]NetworkInfoResult `json:"Results"`
+}
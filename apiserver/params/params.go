@@ -116,6 +116,49 @@ type DestroyRelation struct {
 	Endpoints []string
 }
 
+// RelationData holds the parameters for making the RelationData call.
+// The endpoints specified are unordered.
+type RelationData struct {
+	Endpoints []string
+}
+
+// RelationUnitSettings holds the settings a unit has published on a
+// relation endpoint.
+type RelationUnitSettings struct {
+	Unit     string
+	Endpoint string
+	Settings map[string]interface{}
+}
+
+// RelationDataResults holds the results of a RelationData call: the
+// relation's key and the settings of the units in scope, ordered by
+// unit name.
+type RelationDataResults struct {
+	Key   string
+	Units []RelationUnitSettings
+}
+
+// SetRelationData holds the parameters for making the SetRelationData
+// call. Settings with empty values are removed from the unit's
+// relation settings.
+type SetRelationData struct {
+	Endpoints []string
+	Unit      string
+	Settings  map[string]string
+}
+
+// HookLog holds the parameters for making the HookLog call. If Size
+// is positive, at most Size of the most recent records are returned.
+type HookLog struct {
+	Tag  string
+	Size int
+}
+
+// HookLogResult holds the hooks recently run by a unit, oldest first.
+type HookLogResult struct {
+	Records []HookRecord
+}
+
 // AddCharmWithAuthorization holds the arguments for making an AddCharmWithAuthorization API call.
 type AddCharmWithAuthorization struct {
 	URL                string
@@ -205,6 +248,9 @@ type ServiceDeploy struct {
 	Placement     []*instance.Placement
 	Networks      []string
 	Storage       map[string]storage.Constraints
+	// EndpointBindings maps the charm's relation endpoints to the
+	// names of the spaces they are bound to.
+	EndpointBindings map[string]string
 }
 
 // ServiceUpdate holds the parameters for making the ServiceUpdate call.
@@ -217,6 +263,31 @@ type ServiceUpdate struct {
 	SettingsStrings map[string]string
 	SettingsYAML    string // Takes precedence over SettingsStrings if both are present.
 	Constraints     *constraints.Value
+	HookTimeout     *time.Duration
+}
+
+// ServiceConfigRevision describes a service's charm config settings
+// as they were after a change.
+type ServiceConfigRevision struct {
+	Revision     int                    `json:"revision"`
+	CharmURL     string                 `json:"charm-url"`
+	Settings     map[string]interface{} `json:"settings"`
+	User         string                 `json:"user,omitempty"`
+	Time         time.Time              `json:"time"`
+	RolledBackTo int                    `json:"rolled-back-to,omitempty"`
+}
+
+// ServiceConfigHistoryResults holds the config revisions of a service,
+// oldest first.
+type ServiceConfigHistoryResults struct {
+	Revisions []ServiceConfigRevision `json:"revisions"`
+}
+
+// ServiceConfigRollback holds the parameters for restoring a service's
+// config settings to those of an earlier revision.
+type ServiceConfigRollback struct {
+	ServiceName string `json:"service"`
+	Revision    int    `json:"revision"`
 }
 
 // ServiceSetCharm sets the charm for a given service.
@@ -225,6 +296,48 @@ type ServiceSetCharm struct {
 	CharmUrl    string `json:"charmurl"`
 	ForceUnits  bool   `json:"forceunits"`
 	ForceSeries bool   `json:"forceseries"`
+
+	// Rollout, if set, causes the charm to be rolled out to the
+	// service's units in stages.
+	Rollout *CharmRollout `json:"rollout,omitempty"`
+}
+
+// CharmRollout describes how a service's new charm is rolled out to
+// its units.
+type CharmRollout struct {
+	CanaryUnits int  `json:"canaryunits,omitempty"`
+	BatchSize   int  `json:"batchsize,omitempty"`
+	WaitHealthy bool `json:"waithealthy,omitempty"`
+}
+
+// ServiceResumeCharmRollout holds the parameters for making the
+// ServiceResumeCharmRollout call.
+type ServiceResumeCharmRollout struct {
+	ServiceName string `json:"servicename"`
+}
+
+// ServiceRollbackCharm holds the parameters for making the
+// ServiceRollbackCharm call.
+type ServiceRollbackCharm struct {
+	ServiceName string `json:"servicename"`
+	ForceUnits  bool   `json:"forceunits"`
+}
+
+// ServiceOffer holds the parameters for offering a service's endpoint
+// to other environments on the controller.
+type ServiceOffer struct {
+	ServiceName string `json:"servicename"`
+	Endpoint    string `json:"endpoint"`
+	OfferName   string `json:"offername,omitempty"`
+}
+
+// ServiceConsume holds the parameters for consuming an offer from
+// another environment on the controller. The environment is given by
+// name, optionally qualified by its owner as "owner/name".
+type ServiceConsume struct {
+	Environment string `json:"environment"`
+	OfferName   string `json:"offername"`
+	ServiceName string `json:"servicename,omitempty"`
 }
 
 // ServiceExpose holds the parameters for making the ServiceExpose call.
@@ -616,6 +729,11 @@ type AuthUserInfo struct {
 	// Credentials contains an optional opaque credential value to be held by
 	// the client, if any.
 	Credentials *string `json:"credentials,omitempty"`
+
+	// PasswordExpired is set when the password of a local user has
+	// expired. The user may do nothing but change their password
+	// until they log in again.
+	PasswordExpired bool `json:"password-expired,omitempty"`
 }
 
 // LoginResultV1 holds the result of an Admin v1 Login call.
@@ -675,6 +793,29 @@ type StateServersSpecs struct {
 	Specs []StateServersSpec
 }
 
+// StateServerMembersSpec contains arguments for
+// the AddStateServerMembers client API call.
+type StateServerMembersSpec struct {
+	EnvironTag string `json:"environ-tag,omitempty"`
+	// Role is how the new state servers take part in the
+	// mongo replica set: "non-voting" or "hidden".
+	Role        string            `json:"role"`
+	Constraints constraints.Value `json:"constraints,omitempty"`
+	// Series is the series to associate with new state server machines.
+	// If this is empty, then the series of the existing state servers is used.
+	Series string `json:"series,omitempty"`
+	// Placement holds a placement directive for each new state
+	// server. Directives naming existing machines convert them
+	// into state servers.
+	Placement []string `json:"placement"`
+}
+
+// StateServerMembersSpecs contains all the arguments
+// for the AddStateServerMembers API call.
+type StateServerMembersSpecs struct {
+	Specs []StateServerMembersSpec `json:"specs"`
+}
+
 // StateServersChangeResult contains the results
 // of a single EnsureAvailability API call or
 // an error.
@@ -701,6 +842,32 @@ type StateServersChanges struct {
 	Converted  []string `json:"converted,omitempty"`
 }
 
+// RotateCertificates contains the arguments for the
+// RotateCertificates client API call.
+type RotateCertificates struct {
+	// NewCA requests that a new CA be generated as well as new
+	// server certificates.
+	NewCA bool `json:"new-ca,omitempty"`
+	// Overlap is how long the current and new CAs are both
+	// trusted before the new CA starts signing server certificates.
+	Overlap time.Duration `json:"overlap,omitempty"`
+}
+
+// ControllerCertificatesInfo describes the controller's CA and
+// server certificates.
+type ControllerCertificatesInfo struct {
+	// CACert holds the CA certificates that clients should trust.
+	CACert   string    `json:"ca-cert"`
+	CAExpiry time.Time `json:"ca-expiry"`
+	// NewCAExpiry and SwitchTime are set while a new CA is
+	// being rotated in.
+	NewCAExpiry *time.Time `json:"new-ca-expiry,omitempty"`
+	SwitchTime  *time.Time `json:"switch-time,omitempty"`
+	// ServerCertExpiries holds the expiry time of each state
+	// server's certificate, keyed by machine id.
+	ServerCertExpiries map[string]time.Time `json:"server-cert-expiries,omitempty"`
+}
+
 // FindToolsParams defines parameters for the FindTools method.
 type FindToolsParams struct {
 	// Number will be used to match tools versions exactly if non-zero.
@@ -18,6 +18,10 @@ import (
 // StatusParams holds parameters for the Status call.
 type StatusParams struct {
 	Patterns []string
+
+	// Selector, if not empty, restricts the status to the machines,
+	// services and units whose labels match it.
+	Selector string
 }
 
 // TODO(ericsnow) Add FullStatusResult.
@@ -30,6 +34,10 @@ type FullStatus struct {
 	Services         map[string]ServiceStatus
 	Networks         map[string]NetworkStatus
 	Relations        []RelationStatus
+
+	// CertificateWarning is set when one of the controller's
+	// certificates is close to expiry.
+	CertificateWarning string `json:",omitempty"`
 }
 
 // MachineStatus holds status info about a machine.
@@ -71,6 +79,16 @@ type ServiceStatus struct {
 	Units         map[string]UnitStatus
 	MeterStatuses map[string]MeterStatus
 	Status        AgentStatus
+	CharmRollout  *CharmRolloutStatus
+}
+
+// CharmRolloutStatus holds status info about the staged rollout of a
+// service's charm.
+type CharmRolloutStatus struct {
+	FromCharm   string
+	Released    []string
+	Paused      bool
+	PauseReason string
 }
 
 // MeterStatus represents the meter status of a unit.
@@ -87,6 +105,10 @@ type UnitStatus struct {
 	// Workload holds the status for a unit's workload
 	Workload AgentStatus
 
+	// Health holds the results of the health checks declared by the
+	// unit's charm. It is empty if the unit has never reported any.
+	Health AgentStatus
+
 	// Until Juju 2.0, we need to continue to return legacy agent state values
 	// as top level struct attributes when the "FullStatus" API is called.
 	AgentState     Status
@@ -181,6 +203,25 @@ type UnitStatusHistory struct {
 	Statuses []AgentStatus
 }
 
+// EntityStatusHistoryArgs holds the parameters of a status history
+// query for a machine, unit, service, volume or filesystem.
+type EntityStatusHistoryArgs struct {
+	Tag  string
+	Kind HistoryKind
+	Size int
+
+	// FromDate and ToDate, if set, restrict the history to
+	// entries recorded between them.
+	FromDate *time.Time `json:",omitempty"`
+	ToDate   *time.Time `json:",omitempty"`
+}
+
+// StatusHistoryResult holds the status history of an entity,
+// oldest first.
+type StatusHistoryResult struct {
+	Statuses []AgentStatus
+}
+
 const (
 	// DefaultMaxLogsPerEntity is the default value for logs for each entity
 	// that should be kept at any given time.
@@ -195,6 +236,7 @@ const (
 // prunning process.
 type StatusHistoryPruneArgs struct {
 	MaxLogsPerEntity int
+	MaxHistoryTime   time.Duration `json:",omitempty"`
 }
 
 // StatusResult holds an entity status, extra information, or an
@@ -237,6 +279,12 @@ const (
 	KindAgent HistoryKind = "agent"
 	// KindWorkload represents a charm workload status history entry.
 	KindWorkload HistoryKind = "workload"
+	// KindInstance represents a machine instance status history entry.
+	KindInstance HistoryKind = "instance"
+	// KindStorage represents a volume or filesystem status history entry.
+	KindStorage HistoryKind = "storage"
+	// KindHealth represents a unit health check status history entry.
+	KindHealth HistoryKind = "health"
 )
 
 // Life describes the lifecycle state of an entity ("alive", "dying" or "dead").
@@ -369,6 +417,18 @@ const (
 	StatusActive Status = "active"
 )
 
+const (
+	// Status values specific to unit health.
+
+	// StatusHealthy indicates that the unit's health checks last
+	// passed.
+	StatusHealthy Status = "healthy"
+
+	// StatusUnhealthy indicates that at least one of the unit's
+	// health checks last failed.
+	StatusUnhealthy Status = "unhealthy"
+)
+
 const (
 	// Status values specific to storage.
 
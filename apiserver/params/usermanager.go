@@ -15,6 +15,7 @@ type UserInfo struct {
 	DateCreated    time.Time  `json:"date-created"`
 	LastConnection *time.Time `json:"last-connection,omitempty"`
 	Disabled       bool       `json:"disabled"`
+	LockedOut      bool       `json:"locked-out,omitempty"`
 }
 
 // UserInfoResult holds the result of a UserInfo call.
@@ -57,3 +58,66 @@ type AddUserResult struct {
 	Tag   string `json:"tag,omitempty"`
 	Error *Error `json:"error,omitempty"`
 }
+
+// AddAPIToken holds the parameters for adding an API token.
+type AddAPIToken struct {
+	// Owner holds the tag of the user the token logs in as.
+	Owner        string     `json:"owner"`
+	Name         string     `json:"name"`
+	Expires      *time.Time `json:"expires,omitempty"`
+	Environments []string   `json:"environments,omitempty"`
+	Facades      []string   `json:"facades,omitempty"`
+}
+
+// AddAPITokens holds the parameters for the bulk AddAPIToken API call.
+type AddAPITokens struct {
+	Tokens []AddAPIToken `json:"tokens"`
+}
+
+// AddAPITokenResult holds the credentials to log in with a newly
+// created API token, or an error.
+type AddAPITokenResult struct {
+	Credentials string `json:"credentials,omitempty"`
+	Error       *Error `json:"error,omitempty"`
+}
+
+// AddAPITokenResults holds the results of the bulk AddAPIToken API call.
+type AddAPITokenResults struct {
+	Results []AddAPITokenResult `json:"results"`
+}
+
+// APITokenInfo holds information about an API token. It never holds
+// the token's secret.
+type APITokenInfo struct {
+	Owner        string     `json:"owner"`
+	Name         string     `json:"name"`
+	Created      time.Time  `json:"created"`
+	Expires      *time.Time `json:"expires,omitempty"`
+	LastUsed     *time.Time `json:"last-used,omitempty"`
+	Environments []string   `json:"environments,omitempty"`
+	Facades      []string   `json:"facades,omitempty"`
+}
+
+// APITokensResult holds the API tokens of a user, or an error.
+type APITokensResult struct {
+	Tokens []APITokenInfo `json:"tokens,omitempty"`
+	Error  *Error         `json:"error,omitempty"`
+}
+
+// APITokensResults holds the results of the bulk ListAPITokens API call.
+type APITokensResults struct {
+	Results []APITokensResult `json:"results"`
+}
+
+// APITokenName identifies an API token.
+type APITokenName struct {
+	// Owner holds the tag of the user that owns the token.
+	Owner string `json:"owner"`
+	Name  string `json:"name"`
+}
+
+// APITokenNames holds the parameters for the bulk RemoveAPIToken API
+// call.
+type APITokenNames struct {
+	Tokens []APITokenName `json:"tokens"`
+}
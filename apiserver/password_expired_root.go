@@ -0,0 +1,47 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver
+
+import (
+	"errors"
+
+	"github.com/juju/utils/set"
+
+	"github.com/juju/juju/rpc"
+	"github.com/juju/juju/rpc/rpcreflect"
+)
+
+// passwordExpiredRoot restricts the API calls made by a user whose
+// password has expired to those needed to change it.
+type passwordExpiredRoot struct {
+	rpc.MethodFinder
+}
+
+// newPasswordExpiredRoot returns a new passwordExpiredRoot.
+func newPasswordExpiredRoot(finder rpc.MethodFinder) *passwordExpiredRoot {
+	return &passwordExpiredRoot{finder}
+}
+
+var errPasswordExpired = errors.New(`password expired - change it with "juju change-user-password" and log in again`)
+
+var allowedMethodsWithExpiredPassword = set.NewStrings(
+	"SetPassword", // for "juju change-user-password"
+	"UserInfo",    // for "juju user info"
+)
+
+// FindMethod returns errPasswordExpired for all API calls except those
+// needed to change the password.
+func (r *passwordExpiredRoot) FindMethod(rootName string, version int, methodName string) (rpcreflect.MethodCaller, error) {
+	caller, err := r.MethodFinder.FindMethod(rootName, version, methodName)
+	if err != nil {
+		return nil, err
+	}
+	if rootName == "Pinger" {
+		return caller, nil
+	}
+	if rootName == "UserManager" && allowedMethodsWithExpiredPassword.Contains(methodName) {
+		return caller, nil
+	}
+	return nil, errPasswordExpired
+}
@@ -0,0 +1,60 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver"
+	"github.com/juju/juju/testing"
+)
+
+type passwordExpiredRootSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&passwordExpiredRootSuite{})
+
+func (r *passwordExpiredRootSuite) TestAllowedMethods(c *gc.C) {
+	root := apiserver.TestingPasswordExpiredRoot(nil)
+
+	for _, call := range []struct {
+		rootName string
+		method   string
+	}{
+		{"UserManager", "SetPassword"},
+		{"UserManager", "UserInfo"},
+		{"Pinger", "Ping"},
+	} {
+		caller, err := root.FindMethod(call.rootName, 0, call.method)
+		c.Check(err, jc.ErrorIsNil)
+		c.Check(caller, gc.NotNil)
+	}
+}
+
+func (r *passwordExpiredRootSuite) TestFindDisallowedMethod(c *gc.C) {
+	root := apiserver.TestingPasswordExpiredRoot(nil)
+
+	for _, call := range []struct {
+		rootName string
+		method   string
+	}{
+		{"UserManager", "AddUser"},
+		{"Client", "FullStatus"},
+	} {
+		caller, err := root.FindMethod(call.rootName, 0, call.method)
+		c.Check(err, gc.ErrorMatches, `password expired - change it with "juju change-user-password" and log in again`)
+		c.Check(caller, gc.IsNil)
+	}
+}
+
+func (r *passwordExpiredRootSuite) TestFindNonExistentMethod(c *gc.C) {
+	root := apiserver.TestingPasswordExpiredRoot(nil)
+
+	caller, err := root.FindMethod("Foo", 0, "Bar")
+
+	c.Assert(err, gc.ErrorMatches, "unknown object type \"Foo\"")
+	c.Assert(caller, gc.IsNil)
+}
@@ -0,0 +1,151 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/resource/api"
+	"github.com/juju/juju/state"
+)
+
+// resourcesUploadHandler handles the upload of charm resource data
+// through HTTPS in the API server.
+type resourcesUploadHandler struct {
+	ctxt httpContext
+}
+
+// resourcesDownloadHandler handles the download of charm resource data
+// by units through HTTPS in the API server.
+type resourcesDownloadHandler struct {
+	ctxt httpContext
+}
+
+func (h *resourcesUploadHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	st, entity, err := h.ctxt.stateForRequestAuthorizedUser(r, deployerAccess)
+	if err != nil {
+		sendError(w, err)
+		return
+	}
+
+	switch r.Method {
+	case "PUT":
+		res, err := h.processPut(r, st, entity.Tag())
+		if err != nil {
+			sendError(w, err)
+			return
+		}
+		sendStatusAndJSON(w, http.StatusOK, &api.UploadResult{Resource: res})
+	default:
+		sendError(w, errors.MethodNotAllowedf("unsupported method: %q", r.Method))
+	}
+}
+
+// processPut stores the request body as a new revision of the resource.
+func (h *resourcesUploadHandler) processPut(r *http.Request, st *state.State, user names.Tag) (api.Resource, error) {
+	service := r.URL.Query().Get(":service")
+	name := r.URL.Query().Get(":name")
+	if !names.IsValidService(service) {
+		return api.Resource{}, errors.BadRequestf("invalid service name %q", service)
+	}
+	if r.ContentLength < 0 {
+		return api.Resource{}, errors.BadRequestf("missing Content-Length")
+	}
+	fingerprint := r.Header.Get(api.HeaderFingerprint)
+	if fingerprint == "" {
+		return api.Resource{}, errors.BadRequestf("missing %s header", api.HeaderFingerprint)
+	}
+	blockChecker := common.NewBlockChecker(st)
+	if err := blockChecker.ChangeAllowed(); err != nil {
+		return api.Resource{}, errors.Trace(err)
+	}
+
+	resources, err := st.Resources()
+	if err != nil {
+		return api.Resource{}, errors.Trace(err)
+	}
+	res, err := resources.SetResource(service, name, user.Id(), r.Body, r.ContentLength, fingerprint)
+	if err != nil {
+		return api.Resource{}, errors.Annotatef(err, "cannot store resource %q", name)
+	}
+	return api.Resource2API(res), nil
+}
+
+func (h *resourcesDownloadHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	st, entity, err := h.ctxt.stateForRequestAuthenticatedAgent(r)
+	if err != nil {
+		sendError(w, err)
+		return
+	}
+
+	switch r.Method {
+	case "GET":
+		if err := h.processGet(w, r, st, entity.Tag()); err != nil {
+			logger.Errorf("GET(%s) failed: %v", r.URL, err)
+			sendError(w, err)
+		}
+	default:
+		sendError(w, errors.MethodNotAllowedf("unsupported method: %q", r.Method))
+	}
+}
+
+// processGet sends the current revision of the resource to the unit,
+// and records that the unit has it. The data is not sent again if the
+// unit already has a revision with the same fingerprint.
+func (h *resourcesDownloadHandler) processGet(w http.ResponseWriter, r *http.Request, st *state.State, agent names.Tag) error {
+	unitTag, err := names.ParseUnitTag(r.URL.Query().Get(":unit"))
+	if err != nil {
+		return errors.NewBadRequest(err, "")
+	}
+	if agent != unitTag {
+		return common.ErrPerm
+	}
+	service, err := names.UnitService(unitTag.Id())
+	if err != nil {
+		return errors.Trace(err)
+	}
+	name := r.URL.Query().Get(":name")
+
+	resources, err := st.Resources()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	res, data, err := resources.OpenResource(service, name)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer data.Close()
+
+	w.Header().Set(api.HeaderFingerprint, res.Fingerprint)
+	w.Header().Set(api.HeaderRevision, strconv.Itoa(res.Revision))
+	w.Header().Set(api.HeaderPath, res.Path)
+	if r.URL.Query().Get("fingerprint") == res.Fingerprint {
+		// The unit already has the data.
+		if err := resources.SetUnitResource(unitTag.Id(), res); err != nil {
+			return errors.Trace(err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Length", strconv.FormatInt(res.Size, 10))
+	w.WriteHeader(http.StatusOK)
+	// The headers have been sent, so errors from here on can only
+	// be logged. The unit is only recorded as having the resource
+	// once all of it has been sent.
+	if _, err := io.Copy(w, data); err != nil {
+		logger.Errorf("error sending resource %q to %s: %v", name, unitTag.Id(), err)
+		return nil
+	}
+	if err := resources.SetUnitResource(unitTag.Id(), res); err != nil {
+		logger.Errorf("cannot record resource %q for %s: %v", name, unitTag.Id(), err)
+	}
+	return nil
+}
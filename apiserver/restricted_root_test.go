@@ -41,6 +41,9 @@ func (r *restrictedRootSuite) TestFindAllowedMethod(c *gc.C) {
 	r.assertMethodAllowed(c, "EnvironmentManager", 1, "CreateEnvironment")
 	r.assertMethodAllowed(c, "EnvironmentManager", 1, "ListEnvironments")
 
+	r.assertMethodAllowed(c, "Migration", 1, "Import")
+	r.assertMethodAllowed(c, "Migration", 1, "Status")
+
 	r.assertMethodAllowed(c, "UserManager", 0, "AddUser")
 	r.assertMethodAllowed(c, "UserManager", 0, "SetPassword")
 	r.assertMethodAllowed(c, "UserManager", 0, "UserInfo")
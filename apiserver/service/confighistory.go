@@ -0,0 +1,50 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package service
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/apiserver/params"
+)
+
+// ServiceConfigHistory returns the recorded revisions of the given
+// service's config settings, oldest first.
+func (api *APIV3) ServiceConfigHistory(args params.ServiceGet) (params.ServiceConfigHistoryResults, error) {
+	svc, err := api.state.Service(args.ServiceName)
+	if err != nil {
+		return params.ServiceConfigHistoryResults{}, errors.Trace(err)
+	}
+	history, err := svc.ConfigHistory()
+	if err != nil {
+		return params.ServiceConfigHistoryResults{}, errors.Trace(err)
+	}
+	result := params.ServiceConfigHistoryResults{
+		Revisions: make([]params.ServiceConfigRevision, len(history)),
+	}
+	for i, revision := range history {
+		result.Revisions[i] = params.ServiceConfigRevision{
+			Revision:     revision.Revision,
+			CharmURL:     revision.CharmURL,
+			Settings:     revision.Settings,
+			User:         revision.User,
+			Time:         revision.Time,
+			RolledBackTo: revision.RolledBackTo,
+		}
+	}
+	return result, nil
+}
+
+// ServiceConfigRollback restores the given service's config settings
+// to those of an earlier revision.
+func (api *APIV3) ServiceConfigRollback(args params.ServiceConfigRollback) error {
+	if err := api.check.ChangeAllowed(); err != nil {
+		return errors.Trace(err)
+	}
+	svc, err := api.state.Service(args.ServiceName)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return svc.RollbackConfigSettings(args.Revision, api.authUserName())
+}
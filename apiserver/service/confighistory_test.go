@@ -0,0 +1,70 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package service_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/charm.v6-unstable"
+
+	"github.com/juju/juju/apiserver/params"
+)
+
+func (s *serviceSuite) TestServiceConfigHistory(c *gc.C) {
+	s.AddTestingService(c, "dummy", s.AddTestingCharm(c, "dummy"))
+	err := s.serviceApi.ServiceUpdate(params.ServiceUpdate{
+		ServiceName:     "dummy",
+		SettingsStrings: map[string]string{"title": "one"},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	result, err := s.serviceApi.ServiceConfigHistory(params.ServiceGet{"dummy"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Revisions, gc.HasLen, 2)
+	c.Check(result.Revisions[0].Revision, gc.Equals, 1)
+	c.Check(result.Revisions[0].Settings, gc.HasLen, 0)
+	c.Check(result.Revisions[0].User, gc.Equals, "")
+	c.Check(result.Revisions[1].Revision, gc.Equals, 2)
+	c.Check(result.Revisions[1].Settings, jc.DeepEquals, map[string]interface{}{"title": "one"})
+	c.Check(result.Revisions[1].User, gc.Equals, "admin@local")
+	c.Check(result.Revisions[1].CharmURL, gc.Equals, "local:quantal/quantal-dummy-1")
+}
+
+func (s *serviceSuite) TestServiceConfigHistoryNotFound(c *gc.C) {
+	_, err := s.serviceApi.ServiceConfigHistory(params.ServiceGet{"unknown"})
+	c.Assert(err, gc.ErrorMatches, `service "unknown" not found`)
+}
+
+func (s *serviceSuite) TestServiceConfigRollback(c *gc.C) {
+	svc := s.AddTestingService(c, "dummy", s.AddTestingCharm(c, "dummy"))
+	err := svc.UpdateConfigSettings(charm.Settings{"title": "one"})
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.serviceApi.ServiceConfigRollback(params.ServiceConfigRollback{
+		ServiceName: "dummy",
+		Revision:    1,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	settings, err := svc.ConfigSettings()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(settings, gc.HasLen, 0)
+
+	history, err := svc.ConfigHistory()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(history, gc.HasLen, 3)
+	c.Check(history[2].RolledBackTo, gc.Equals, 1)
+	c.Check(history[2].User, gc.Equals, "admin@local")
+}
+
+func (s *serviceSuite) TestBlockChangesServiceConfigRollback(c *gc.C) {
+	svc := s.AddTestingService(c, "dummy", s.AddTestingCharm(c, "dummy"))
+	err := svc.UpdateConfigSettings(charm.Settings{"title": "one"})
+	c.Assert(err, jc.ErrorIsNil)
+	s.BlockAllChanges(c, "TestBlockChangesServiceConfigRollback")
+	err = s.serviceApi.ServiceConfigRollback(params.ServiceConfigRollback{
+		ServiceName: "dummy",
+		Revision:    1,
+	})
+	s.AssertBlocked(c, err, "TestBlockChangesServiceConfigRollback")
+}
@@ -0,0 +1,122 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package service
+
+import (
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"gopkg.in/juju/charm.v6-unstable"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+)
+
+// ServiceOffer offers a service's endpoint to the other environments on
+// the controller. The offer is named after the service unless another
+// name is given.
+func (api *APIV3) ServiceOffer(args params.ServiceOffer) error {
+	if err := api.check.ChangeAllowed(); err != nil {
+		return errors.Trace(err)
+	}
+	user, ok := api.authorizer.GetAuthTag().(names.UserTag)
+	if !ok {
+		return common.ErrPerm
+	}
+	name := args.OfferName
+	if name == "" {
+		name = args.ServiceName
+	}
+	_, err := api.state.AddOffer(name, args.ServiceName, args.Endpoint, user)
+	return errors.Trace(err)
+}
+
+// ServiceConsume adds a remote service to the environment for an offer
+// made by another environment on the controller, so that local services
+// can be related to the offered endpoint. Only users with access to the
+// offering environment may consume its offers. The remote service is
+// named after the offer unless another name is given, and its name is
+// returned.
+func (api *APIV3) ServiceConsume(args params.ServiceConsume) (params.StringResult, error) {
+	if err := api.check.ChangeAllowed(); err != nil {
+		return params.StringResult{}, errors.Trace(err)
+	}
+	user, ok := api.authorizer.GetAuthTag().(names.UserTag)
+	if !ok {
+		return params.StringResult{}, common.ErrPerm
+	}
+	env, err := api.userEnvironment(user, args.Environment)
+	if err != nil {
+		return params.StringResult{}, errors.Trace(err)
+	}
+	if env.UUID() == api.state.EnvironUUID() {
+		return params.StringResult{}, errors.Errorf("cannot consume offer %q from this environment", args.OfferName)
+	}
+	offerer, err := api.state.ForEnviron(env.EnvironTag())
+	if err != nil {
+		return params.StringResult{}, errors.Trace(err)
+	}
+	defer offerer.Close()
+	offer, err := offerer.Offer(args.OfferName)
+	if err != nil {
+		return params.StringResult{}, errors.Trace(err)
+	}
+	ep, err := offer.Endpoint()
+	if err != nil {
+		return params.StringResult{}, errors.Trace(err)
+	}
+	name := args.ServiceName
+	if name == "" {
+		name = offer.Name()
+	}
+	_, err = api.state.AddRemoteService(state.AddRemoteServiceArgs{
+		Name:              name,
+		SourceEnv:         env.EnvironTag(),
+		SourceServiceName: offer.ServiceName(),
+		OfferName:         offer.Name(),
+		ConsumedBy:        user,
+		Endpoints:         []charm.Relation{ep.Relation},
+	})
+	if err != nil {
+		return params.StringResult{}, errors.Trace(err)
+	}
+	return params.StringResult{Result: name}, nil
+}
+
+// userEnvironment returns the environment with the given name, optionally
+// qualified by its owner as "owner/name", from those the user has access
+// to.
+func (api *API) userEnvironment(user names.UserTag, spec string) (*state.UserEnvironment, error) {
+	name, owner := spec, ""
+	if i := strings.Index(spec, "/"); i != -1 {
+		owner, name = spec[:i], spec[i+1:]
+		if !names.IsValidUser(owner) {
+			return nil, errors.NotValidf("environment owner %q", owner)
+		}
+		owner = names.NewUserTag(owner).Canonical()
+	}
+	envs, err := api.state.EnvironmentsForUser(user)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var found []*state.UserEnvironment
+	for _, env := range envs {
+		if env.Name() != name {
+			continue
+		}
+		if owner != "" && env.Owner().Canonical() != owner {
+			continue
+		}
+		found = append(found, env)
+	}
+	switch len(found) {
+	case 0:
+		return nil, errors.NotFoundf("environment %q", spec)
+	case 1:
+		return found[0], nil
+	}
+	return nil, errors.Errorf("environment name %q is ambiguous, use owner/name", spec)
+}
@@ -0,0 +1,111 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package service_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/testing/factory"
+)
+
+func (s *serviceSuite) TestServiceOffer(c *gc.C) {
+	s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
+	err := s.serviceApi.ServiceOffer(params.ServiceOffer{
+		ServiceName: "mysql",
+		Endpoint:    "server",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	offer, err := s.State.Offer("mysql")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(offer.EndpointName(), gc.Equals, "server")
+	c.Assert(offer.Owner(), gc.Equals, s.AdminUserTag(c))
+}
+
+func (s *serviceSuite) TestBlockChangesServiceOffer(c *gc.C) {
+	s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
+	s.BlockAllChanges(c, "TestBlockChangesServiceOffer")
+	err := s.serviceApi.ServiceOffer(params.ServiceOffer{
+		ServiceName: "mysql",
+		Endpoint:    "server",
+	})
+	s.AssertBlocked(c, err, "TestBlockChangesServiceOffer")
+}
+
+// addOfferingEnv adds an environment offering a mysql service as "db".
+func (s *serviceSuite) addOfferingEnv(c *gc.C, envParams *factory.EnvParams) *state.State {
+	st := s.Factory.MakeEnvironment(c, envParams)
+	s.AddCleanup(func(*gc.C) { st.Close() })
+	ch := state.AddTestingCharm(c, st, "mysql")
+	_, err := st.AddService(state.AddServiceArgs{
+		Name:  "mysql",
+		Owner: s.AdminUserTag(c).String(),
+		Charm: ch,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = st.AddOffer("db", "mysql", "server", s.AdminUserTag(c))
+	c.Assert(err, jc.ErrorIsNil)
+	return st
+}
+
+func (s *serviceSuite) TestServiceConsume(c *gc.C) {
+	offerer := s.addOfferingEnv(c, &factory.EnvParams{Name: "shared"})
+	result, err := s.serviceApi.ServiceConsume(params.ServiceConsume{
+		Environment: "shared",
+		OfferName:   "db",
+		ServiceName: "shared-db",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Result, gc.Equals, "shared-db")
+
+	remote, err := s.State.RemoteService("shared-db")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(remote.SourceEnviron(), gc.Equals, offerer.EnvironTag())
+	c.Assert(remote.SourceServiceName(), gc.Equals, "mysql")
+	c.Assert(remote.OfferName(), gc.Equals, "db")
+	c.Assert(remote.ConsumedBy(), gc.Equals, s.AdminUserTag(c))
+	_, err = remote.Endpoint("server")
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *serviceSuite) TestServiceConsumeWithOwner(c *gc.C) {
+	s.addOfferingEnv(c, &factory.EnvParams{Name: "shared"})
+	result, err := s.serviceApi.ServiceConsume(params.ServiceConsume{
+		Environment: "admin/shared",
+		OfferName:   "db",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Result, gc.Equals, "db")
+}
+
+func (s *serviceSuite) TestServiceConsumeRequiresEnvironmentAccess(c *gc.C) {
+	owner := s.Factory.MakeUser(c, &factory.UserParams{Name: "bob"})
+	s.addOfferingEnv(c, &factory.EnvParams{Name: "private", Owner: owner.Tag()})
+	_, err := s.serviceApi.ServiceConsume(params.ServiceConsume{
+		Environment: "private",
+		OfferName:   "db",
+	})
+	c.Assert(err, gc.ErrorMatches, `environment "private" not found`)
+}
+
+func (s *serviceSuite) TestServiceConsumeUnknownOffer(c *gc.C) {
+	s.addOfferingEnv(c, &factory.EnvParams{Name: "shared"})
+	_, err := s.serviceApi.ServiceConsume(params.ServiceConsume{
+		Environment: "shared",
+		OfferName:   "cache",
+	})
+	c.Assert(err, gc.ErrorMatches, `offer "cache" not found`)
+}
+
+func (s *serviceSuite) TestBlockChangesServiceConsume(c *gc.C) {
+	s.addOfferingEnv(c, &factory.EnvParams{Name: "shared"})
+	s.BlockAllChanges(c, "TestBlockChangesServiceConsume")
+	_, err := s.serviceApi.ServiceConsume(params.ServiceConsume{
+		Environment: "shared",
+		OfferName:   "db",
+	})
+	s.AssertBlocked(c, err, "TestBlockChangesServiceConsume")
+}
@@ -59,6 +59,15 @@ func NewAPI(
 	}, nil
 }
 
+// authUserName returns the canonical name of the user the API is being
+// used by, or "" if it is not being used by a user.
+func (api *API) authUserName() string {
+	if tag, ok := api.authorizer.GetAuthTag().(names.UserTag); ok {
+		return tag.Canonical()
+	}
+	return ""
+}
+
 // SetMetricCredentials sets credentials on the service.
 func (api *API) SetMetricCredentials(args params.ServiceMetricCredentials) (params.ErrorResults, error) {
 	result := params.ErrorResults{
@@ -170,13 +179,16 @@ func DeployService(st *state.State, owner string, args params.ServiceDeploy) err
 			Placement:      args.Placement,
 			Networks:       requestedNetworks,
 			Storage:        args.Storage,
+
+			EndpointBindings: args.EndpointBindings,
 		})
 	return err
 }
 
 // ServiceSetSettingsStrings updates the settings for the given service,
-// taking the configuration from a map of strings.
-func ServiceSetSettingsStrings(service *state.Service, settings map[string]string) error {
+// taking the configuration from a map of strings. The change is recorded
+// in the service's config history as made by the named user.
+func ServiceSetSettingsStrings(service *state.Service, settings map[string]string, user string) error {
 	ch, _, err := service.Charm()
 	if err != nil {
 		return err
@@ -186,7 +198,7 @@ func ServiceSetSettingsStrings(service *state.Service, settings map[string]strin
 	if err != nil {
 		return err
 	}
-	return service.UpdateConfigSettings(changes)
+	return service.UpdateConfigSettingsBy(changes, user)
 }
 
 func networkTagsToNames(tags []string) ([]string, error) {
@@ -235,7 +247,7 @@ func parseSettingsCompatible(ch *state.Charm, settings map[string]string) (charm
 }
 
 // ServiceUpdate updates the service attributes, including charm URL,
-// minimum number of units, settings and constraints.
+// minimum number of units, settings, hook timeout and constraints.
 // All parameters in params.ServiceUpdate except the service name are optional.
 func (api *API) ServiceUpdate(args params.ServiceUpdate) error {
 	if !args.ForceCharmUrl {
@@ -261,11 +273,17 @@ func (api *API) ServiceUpdate(args params.ServiceUpdate) error {
 	}
 	// Set up service's settings.
 	if args.SettingsYAML != "" {
-		if err = serviceSetSettingsYAML(svc, args.SettingsYAML); err != nil {
+		if err = serviceSetSettingsYAML(svc, args.SettingsYAML, api.authUserName()); err != nil {
 			return err
 		}
 	} else if len(args.SettingsStrings) > 0 {
-		if err = ServiceSetSettingsStrings(svc, args.SettingsStrings); err != nil {
+		if err = ServiceSetSettingsStrings(svc, args.SettingsStrings, api.authUserName()); err != nil {
+			return err
+		}
+	}
+	// Set how long the service's hooks may run.
+	if args.HookTimeout != nil {
+		if err = svc.SetHookTimeout(*args.HookTimeout); err != nil {
 			return err
 		}
 	}
@@ -305,8 +323,9 @@ func (api *API) serviceSetCharm(service *state.Service, url string, forceSeries,
 }
 
 // serviceSetSettingsYAML updates the settings for the given service,
-// taking the configuration from a YAML string.
-func serviceSetSettingsYAML(service *state.Service, settings string) error {
+// taking the configuration from a YAML string. The change is recorded
+// in the service's config history as made by the named user.
+func serviceSetSettingsYAML(service *state.Service, settings string, user string) error {
 	ch, _, err := service.Charm()
 	if err != nil {
 		return err
@@ -315,7 +334,7 @@ func serviceSetSettingsYAML(service *state.Service, settings string) error {
 	if err != nil {
 		return err
 	}
-	return service.UpdateConfigSettings(changes)
+	return service.UpdateConfigSettingsBy(changes, user)
 }
 
 // ServiceGetCharmURL returns the charm URL the given service is
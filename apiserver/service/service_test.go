@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"sync"
+	"time"
 
 	"github.com/juju/errors"
 	"github.com/juju/names"
@@ -41,7 +42,7 @@ type serviceSuite struct {
 	apiservertesting.CharmStoreSuite
 	commontesting.BlockHelper
 
-	serviceApi *service.API
+	serviceApi *service.APIV3
 	service    *state.Service
 	authorizer apiservertesting.FakeAuthorizer
 }
@@ -74,7 +75,7 @@ func (s *serviceSuite) SetUpTest(c *gc.C) {
 		Tag: s.AdminUserTag(c),
 	}
 	var err error
-	s.serviceApi, err = service.NewAPI(s.State, nil, s.authorizer)
+	s.serviceApi, err = service.NewAPIV3(s.State, nil, s.authorizer)
 	c.Assert(err, jc.ErrorIsNil)
 }
 
@@ -644,6 +645,78 @@ func (s *serviceSuite) TestBlockChangesServiceSetCharm(c *gc.C) {
 	s.assertServiceSetCharmBlocked(c, "TestBlockChangesServiceSetCharm")
 }
 
+func (s *serviceSuite) TestServiceSetCharmStaged(c *gc.C) {
+	s.setupServiceSetCharm(c)
+	err := s.serviceApi.ServiceSetCharm(params.ServiceSetCharm{
+		ServiceName: "service",
+		CharmUrl:    "cs:~who/precise/wordpress-3",
+		Rollout: &params.CharmRollout{
+			BatchSize:   2,
+			WaitHealthy: true,
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	svc, err := s.State.Service("service")
+	c.Assert(err, jc.ErrorIsNil)
+	rollout, ok := svc.CharmRollout()
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(rollout.BatchSize, gc.Equals, 2)
+	c.Assert(rollout.WaitHealthy, jc.IsTrue)
+	c.Assert(rollout.Released, jc.DeepEquals, []string{"service/0", "service/1"})
+
+	err = s.serviceApi.ServiceResumeCharmRollout(params.ServiceResumeCharmRollout{
+		ServiceName: "service",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *serviceSuite) TestBlockChangesServiceResumeCharmRollout(c *gc.C) {
+	s.BlockAllChanges(c, "TestBlockChangesServiceResumeCharmRollout")
+	err := s.serviceApi.ServiceResumeCharmRollout(params.ServiceResumeCharmRollout{
+		ServiceName: "service",
+	})
+	s.AssertBlocked(c, err, "TestBlockChangesServiceResumeCharmRollout")
+}
+
+func (s *serviceSuite) TestServiceRollbackCharm(c *gc.C) {
+	s.setupServiceSetCharm(c)
+	s.assertServiceSetCharm(c, false)
+	svc, err := s.State.Service("service")
+	c.Assert(err, jc.ErrorIsNil)
+	previous := svc.PreviousCharmURL()
+	c.Assert(previous, gc.NotNil)
+
+	result, err := s.serviceApi.ServiceRollbackCharm(params.ServiceRollbackCharm{
+		ServiceName: "service",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Result, gc.Equals, previous.String())
+
+	err = svc.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	url, force := svc.CharmURL()
+	c.Assert(url, gc.DeepEquals, previous)
+	c.Assert(force, jc.IsFalse)
+}
+
+func (s *serviceSuite) TestServiceRollbackCharmNoPrevious(c *gc.C) {
+	s.setupServiceSetCharm(c)
+	_, err := s.serviceApi.ServiceRollbackCharm(params.ServiceRollbackCharm{
+		ServiceName: "service",
+	})
+	c.Assert(err, gc.ErrorMatches, `cannot roll back charm of service "service": no previous charm`)
+}
+
+func (s *serviceSuite) TestBlockChangesServiceRollbackCharm(c *gc.C) {
+	s.setupServiceSetCharm(c)
+	s.assertServiceSetCharm(c, false)
+	s.BlockAllChanges(c, "TestBlockChangesServiceRollbackCharm")
+	_, err := s.serviceApi.ServiceRollbackCharm(params.ServiceRollbackCharm{
+		ServiceName: "service",
+	})
+	s.AssertBlocked(c, err, "TestBlockChangesServiceRollbackCharm")
+}
+
 func (s *serviceSuite) TestClientServiceSetCharmForceUnits(c *gc.C) {
 	curl, _ := s.UploadCharm(c, "precise/dummy-0", "dummy")
 	err := service.AddCharmWithAuthorization(s.State, params.AddCharmWithAuthorization{URL: curl.String()})
@@ -1196,6 +1269,21 @@ func (s *serviceSuite) TestClientServiceUpdateSetMinUnits(c *gc.C) {
 	c.Assert(service.MinUnits(), gc.Equals, minUnits)
 }
 
+func (s *serviceSuite) TestClientServiceUpdateSetHookTimeout(c *gc.C) {
+	service := s.AddTestingService(c, "dummy", s.AddTestingCharm(c, "dummy"))
+
+	timeout := 45 * time.Minute
+	args := params.ServiceUpdate{
+		ServiceName: "dummy",
+		HookTimeout: &timeout,
+	}
+	err := s.serviceApi.ServiceUpdate(args)
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Assert(service.Refresh(), gc.IsNil)
+	c.Assert(service.HookTimeout(), gc.Equals, timeout)
+}
+
 func (s *serviceSuite) TestClientServiceUpdateSetMinUnitsError(c *gc.C) {
 	service := s.AddTestingService(c, "dummy", s.AddTestingCharm(c, "dummy"))
 
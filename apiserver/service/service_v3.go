@@ -0,0 +1,107 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package service
+
+import (
+	"github.com/juju/errors"
+	"gopkg.in/juju/charm.v6-unstable"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+)
+
+func init() {
+	common.RegisterStandardFacade("Service", 3, NewAPIV3)
+}
+
+// APIV3 implements version 3 of the service API end point, which adds
+// staged charm rollouts, charm rollbacks, config history and offers
+// of services to other environments.
+type APIV3 struct {
+	API
+}
+
+// NewAPIV3 returns a new service API facade, version 3.
+func NewAPIV3(
+	st *state.State,
+	resources *common.Resources,
+	authorizer common.Authorizer,
+) (*APIV3, error) {
+	api, err := NewAPI(st, resources, authorizer)
+	if err != nil {
+		return nil, err
+	}
+	return &APIV3{*api}, nil
+}
+
+// ServiceSetCharm sets the charm for a given service, rolling it out
+// to the service's units in stages if a rollout is given.
+func (api *APIV3) ServiceSetCharm(args params.ServiceSetCharm) error {
+	if args.Rollout == nil {
+		return api.API.ServiceSetCharm(args)
+	}
+	// when forced units in error, don't block
+	if !args.ForceUnits {
+		if err := api.check.ChangeAllowed(); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	service, err := api.state.Service(args.ServiceName)
+	if err != nil {
+		return err
+	}
+	return api.serviceSetCharmStaged(service, args.CharmUrl, args.ForceSeries, args.ForceUnits, *args.Rollout)
+}
+
+// serviceSetCharmStaged sets the charm for the given service, rolling
+// it out to the service's units in stages.
+func (api *APIV3) serviceSetCharmStaged(service *state.Service, url string, forceSeries, forceUnits bool, rollout params.CharmRollout) error {
+	curl, err := charm.ParseURL(url)
+	if err != nil {
+		return err
+	}
+	sch, err := api.state.Charm(curl)
+	if err != nil {
+		return err
+	}
+	return service.SetCharmStaged(sch, forceSeries, forceUnits, state.CharmRolloutParams{
+		CanaryUnits: rollout.CanaryUnits,
+		BatchSize:   rollout.BatchSize,
+		WaitHealthy: rollout.WaitHealthy,
+	})
+}
+
+// ServiceResumeCharmRollout resumes the paused rollout of a service's
+// charm to its units.
+func (api *APIV3) ServiceResumeCharmRollout(args params.ServiceResumeCharmRollout) error {
+	if err := api.check.ChangeAllowed(); err != nil {
+		return errors.Trace(err)
+	}
+	service, err := api.state.Service(args.ServiceName)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return service.ResumeCharmRollout()
+}
+
+// ServiceRollbackCharm changes a service's charm back to the one it
+// used before its charm was last changed, and returns that charm's URL.
+func (api *APIV3) ServiceRollbackCharm(args params.ServiceRollbackCharm) (params.StringResult, error) {
+	// when forced units in error, don't block
+	if !args.ForceUnits {
+		if err := api.check.ChangeAllowed(); err != nil {
+			return params.StringResult{}, errors.Trace(err)
+		}
+	}
+	service, err := api.state.Service(args.ServiceName)
+	if err != nil {
+		return params.StringResult{}, errors.Trace(err)
+	}
+	ch, err := service.RollbackCharm(args.ForceUnits)
+	if err != nil {
+		return params.StringResult{}, errors.Trace(err)
+	}
+	return params.StringResult{Result: ch.URL().String()}, nil
+}
@@ -4,6 +4,8 @@
 package statushistory
 
 import (
+	"github.com/juju/errors"
+
 	"github.com/juju/juju/apiserver/common"
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/state"
@@ -32,10 +34,20 @@ func NewAPI(st *state.State, _ *common.Resources, auth common.Authorizer) (*API,
 }
 
 // Prune endpoint removes status history entries until
-// only the N newest records per unit remain.
+// only the N newest records per entity remain, and removes
+// entries older than MaxHistoryTime or, if that is not set,
+// the environment's max-status-history-age.
 func (api *API) Prune(p params.StatusHistoryPruneArgs) error {
 	if !api.authorizer.AuthEnvironManager() {
 		return common.ErrPerm
 	}
-	return state.PruneStatusHistory(api.st, p.MaxLogsPerEntity)
+	maxHistoryTime := p.MaxHistoryTime
+	if maxHistoryTime == 0 {
+		cfg, err := api.st.EnvironConfig()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		maxHistoryTime = cfg.MaxStatusHistoryAge()
+	}
+	return state.PruneStatusHistory(api.st, p.MaxLogsPerEntity, maxHistoryTime)
 }
@@ -194,6 +194,7 @@ func (s *SystemManagerAPI) EnvironmentConfig() (params.EnvironmentConfigResults,
 	}
 
 	result.Config = config.AllAttrs()
+	common.RemoveServerSecrets(result.Config)
 	return result, nil
 }
 
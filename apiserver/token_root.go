@@ -0,0 +1,107 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/utils/set"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/rpc"
+	"github.com/juju/juju/rpc/rpcreflect"
+)
+
+// tokenCacheTTL is how long the API token a user logged in with is
+// relied on before it is looked up again.
+var tokenCacheTTL = 5 * time.Second
+
+// tokenDeniedMethods holds, by facade, the methods that may never be
+// called by a user logged in with an API token, because they would let
+// the caller do more than the token allows.
+var tokenDeniedMethods = map[string]set.Strings{
+	"UserManager": set.NewStrings("AddAPIToken", "SetPassword"),
+}
+
+// apiToken holds the details of an API token that tokenRoot needs.
+type apiToken interface {
+	Facades() []string
+	Expired(now time.Time) bool
+}
+
+// tokenRoot restricts the API calls made by a user logged in with an
+// API token to the facades the token allows. The token is looked up
+// again once it is tokenCacheTTL old, so that calls stop being allowed
+// soon after the token is removed or expires.
+type tokenRoot struct {
+	rpc.MethodFinder
+	currentToken func() (apiToken, error)
+
+	mu      sync.Mutex
+	token   apiToken
+	expires time.Time
+}
+
+// newTokenRoot returns a new tokenRoot for a user logged in with the
+// token currentToken returns.
+func newTokenRoot(finder rpc.MethodFinder, currentToken func() (apiToken, error)) *tokenRoot {
+	return &tokenRoot{
+		MethodFinder: finder,
+		currentToken: currentToken,
+	}
+}
+
+// validToken returns the token the user logged in with, looking it up
+// if the token last found is too old.
+func (r *tokenRoot) validToken() (apiToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	if r.token == nil || !now.Before(r.expires) {
+		token, err := r.currentToken()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		r.token = token
+		r.expires = now.Add(tokenCacheTTL)
+	}
+	if r.token.Expired(now) {
+		return nil, errors.NotFoundf("unexpired API token")
+	}
+	return r.token, nil
+}
+
+// FindMethod returns a permission denied error if the token has been
+// removed or has expired, or if it does not allow calls to the facade.
+// Pinging the server is always allowed, as are calls to watchers, which
+// are only ever handed out by allowed facades.
+func (r *tokenRoot) FindMethod(rootName string, version int, methodName string) (rpcreflect.MethodCaller, error) {
+	caller, err := r.MethodFinder.FindMethod(rootName, version, methodName)
+	if err != nil {
+		return nil, err
+	}
+	if rootName == "Pinger" {
+		return caller, nil
+	}
+	if tokenDeniedMethods[rootName].Contains(methodName) {
+		logger.Debugf("%s.%s cannot be called with an API token", rootName, methodName)
+		return nil, common.ErrPerm
+	}
+	token, err := r.validToken()
+	if errors.IsNotFound(err) {
+		logger.Debugf("API token no longer valid for %s.%s: %v", rootName, methodName, err)
+		return nil, common.ErrPerm
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+	facades := token.Facades()
+	if len(facades) == 0 || strings.HasSuffix(rootName, "Watcher") || set.NewStrings(facades...).Contains(rootName) {
+		return caller, nil
+	}
+	logger.Debugf("API token does not allow %s.%s", rootName, methodName)
+	return nil, common.ErrPerm
+}
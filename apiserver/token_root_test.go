@@ -0,0 +1,105 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver_test
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver"
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/rpc"
+	"github.com/juju/juju/testing"
+)
+
+type tokenScopeRootSuite struct {
+	testing.BaseSuite
+	root rpc.MethodFinder
+}
+
+var _ = gc.Suite(&tokenScopeRootSuite{})
+
+func (s *tokenScopeRootSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.root = apiserver.TestingTokenScopeRoot(nil, "Client", "Action")
+}
+
+func (s *tokenScopeRootSuite) assertAllowed(c *gc.C, rootName string, version int, method string) {
+	caller, err := s.root.FindMethod(rootName, version, method)
+	c.Check(err, jc.ErrorIsNil)
+	c.Check(caller, gc.NotNil)
+}
+
+func (s *tokenScopeRootSuite) assertDenied(c *gc.C, rootName string, version int, method string) {
+	caller, err := s.root.FindMethod(rootName, version, method)
+	c.Check(err, gc.Equals, common.ErrPerm)
+	c.Check(caller, gc.IsNil)
+}
+
+func (s *tokenScopeRootSuite) TestAllowedFacades(c *gc.C) {
+	s.assertAllowed(c, "Client", 0, "FullStatus")
+	s.assertAllowed(c, "Action", 0, "Enqueue")
+}
+
+func (s *tokenScopeRootSuite) TestAlwaysAllowed(c *gc.C) {
+	s.assertAllowed(c, "Pinger", 0, "Ping")
+	s.assertAllowed(c, "AllWatcher", 0, "Next")
+}
+
+func (s *tokenScopeRootSuite) TestOtherFacadesDenied(c *gc.C) {
+	s.assertDenied(c, "KeyManager", 0, "AddKeys")
+	s.assertDenied(c, "UserManager", 0, "AddUser")
+	s.assertDenied(c, "Service", 2, "ServicesDeploy")
+}
+
+func (s *tokenScopeRootSuite) TestPrivilegedMethodsDenied(c *gc.C) {
+	s.root = apiserver.TestingTokenScopeRoot(nil)
+	s.assertAllowed(c, "UserManager", 0, "AddUser")
+	s.assertDenied(c, "UserManager", 0, "AddAPIToken")
+	s.assertDenied(c, "UserManager", 0, "SetPassword")
+}
+
+func (s *tokenScopeRootSuite) TestRemovedTokenDenied(c *gc.C) {
+	s.PatchValue(apiserver.TokenCacheTTL, time.Duration(0))
+	removed := false
+	s.root = apiserver.TestingTokenRoot(nil, func() ([]string, bool, error) {
+		if removed {
+			return nil, false, errors.NotFoundf("API token")
+		}
+		return nil, false, nil
+	})
+	s.assertAllowed(c, "Client", 0, "FullStatus")
+
+	removed = true
+	s.assertDenied(c, "Client", 0, "FullStatus")
+	s.assertDenied(c, "AllWatcher", 0, "Next")
+	s.assertAllowed(c, "Pinger", 0, "Ping")
+}
+
+func (s *tokenScopeRootSuite) TestExpiredTokenDenied(c *gc.C) {
+	s.root = apiserver.TestingTokenRoot(nil, func() ([]string, bool, error) {
+		return []string{"Client"}, true, nil
+	})
+	s.assertDenied(c, "Client", 0, "FullStatus")
+}
+
+func (s *tokenScopeRootSuite) TestTokenCached(c *gc.C) {
+	lookups := 0
+	s.root = apiserver.TestingTokenRoot(nil, func() ([]string, bool, error) {
+		lookups++
+		return nil, false, nil
+	})
+	s.assertAllowed(c, "Client", 0, "FullStatus")
+	s.assertAllowed(c, "Client", 0, "FullStatus")
+	c.Assert(lookups, gc.Equals, 1)
+}
+
+func (s *tokenScopeRootSuite) TestNonExistentMethod(c *gc.C) {
+	caller, err := s.root.FindMethod("Client", 0, "Bar")
+	c.Assert(err, gc.ErrorMatches, `no such request - method Client\(0\).Bar is not implemented`)
+	c.Assert(caller, gc.IsNil)
+}
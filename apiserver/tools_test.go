@@ -140,6 +140,15 @@ func (s *toolsSuite) TestAuthRequiresUser(c *gc.C) {
 	s.assertErrorResponse(c, resp, http.StatusBadRequest, "expected binaryVersion argument")
 }
 
+func (s *toolsSuite) TestUploadRequiresAdminAccess(c *gc.C) {
+	for _, access := range []state.EnvUserAccess{state.EnvViewerAccess, state.EnvDeployerAccess} {
+		c.Logf("access: %s", access)
+		s.setUserAccess(c, access)
+		resp := s.authRequest(c, httpRequestParams{method: "POST", url: s.toolsURI(c, "")})
+		s.assertErrorResponse(c, resp, http.StatusUnauthorized, "permission denied")
+	}
+}
+
 func (s *toolsSuite) TestUploadRequiresVersion(c *gc.C) {
 	resp := s.authRequest(c, httpRequestParams{method: "POST", url: s.toolsURI(c, "")})
 	s.assertErrorResponse(c, resp, http.StatusBadRequest, "expected binaryVersion argument")
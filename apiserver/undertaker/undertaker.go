@@ -130,6 +130,7 @@ func (u *UndertakerAPI) EnvironConfig() (params.EnvironConfigResult, error) {
 		return result, err
 	}
 	allAttrs := config.AllAttrs()
+	common.RemoveServerSecrets(allAttrs)
 	result.Config = allAttrs
 	return result, nil
 }
@@ -437,10 +437,18 @@ func (u *uniterBaseAPI) CharmURL(args params.Entities) (params.StringBoolResults
 			var unitOrService state.Entity
 			unitOrService, err = u.st.FindEntity(tag)
 			if err == nil {
-				charmURLer := unitOrService.(interface {
-					CharmURL() (*charm.URL, bool)
-				})
-				curl, ok := charmURLer.CharmURL()
+				var curl *charm.URL
+				var ok bool
+				if service, isService := unitOrService.(*state.Service); isService {
+					// The service's charm may still be being
+					// rolled out to the unit asking.
+					curl, ok, err = service.CharmURLForUnit(u.unit.Name())
+				} else {
+					charmURLer := unitOrService.(interface {
+						CharmURL() (*charm.URL, bool)
+					})
+					curl, ok = charmURLer.CharmURL()
+				}
 				if curl != nil {
 					result.Results[i].Result = curl.String()
 					result.Results[i].Ok = ok
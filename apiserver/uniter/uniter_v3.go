@@ -0,0 +1,165 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// The uniter package implements the API interface used by the uniter
+// worker. This file contains the API facade version 3.
+
+package uniter
+
+import (
+	"github.com/juju/names"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+)
+
+func init() {
+	common.RegisterStandardFacade("Uniter", 3, NewUniterAPIV3)
+}
+
+// UniterAPIV3 implements the API version 3, used by the uniter worker.
+type UniterAPIV3 struct {
+	UniterAPIV2
+
+	healthSetter *common.StatusSetter
+}
+
+// NewUniterAPIV3 creates a new instance of the Uniter API, version 3.
+func NewUniterAPIV3(st *state.State, resources *common.Resources, authorizer common.Authorizer) (*UniterAPIV3, error) {
+	baseAPI, err := NewUniterAPIV2(st, resources, authorizer)
+	if err != nil {
+		return nil, err
+	}
+	return &UniterAPIV3{
+		UniterAPIV2:  *baseAPI,
+		healthSetter: common.NewStatusSetter(&common.UnitHealthFinder{st}, baseAPI.accessUnit),
+	}, nil
+}
+
+// NetworkInfo returns the address, interface and subnet a unit should
+// use for each of the given relation endpoints, taking into account
+// the spaces the endpoints are bound to.
+func (u *UniterAPIV3) NetworkInfo(args params.NetworkInfoParams) (params.NetworkInfoResults, error) {
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.NetworkInfoResults{}, err
+	}
+	unitTag, err := names.ParseUnitTag(args.Unit)
+	if err != nil {
+		return params.NetworkInfoResults{}, common.ErrPerm
+	}
+	if !canAccess(unitTag) {
+		return params.NetworkInfoResults{}, common.ErrPerm
+	}
+	unit, err := u.getUnit(unitTag)
+	if err != nil {
+		return params.NetworkInfoResults{}, common.ServerError(err)
+	}
+	result := params.NetworkInfoResults{
+		Results: make(map[string]params.NetworkInfoResult),
+	}
+	for _, binding := range args.Bindings {
+		info, err := unit.NetworkInfo(binding)
+		if err != nil {
+			result.Results[binding] = params.NetworkInfoResult{Error: common.ServerError(err)}
+			continue
+		}
+		result.Results[binding] = params.NetworkInfoResult{
+			Info: params.NetworkInfo{
+				SpaceName:     info.SpaceName,
+				Address:       info.Address,
+				InterfaceName: info.InterfaceName,
+				CIDR:          info.CIDR,
+			},
+		}
+	}
+	return result, nil
+}
+
+// HookTimeouts returns how long each of the specified units' hooks may
+// run before they are killed: the service's hook timeout if it has
+// one, and the environment's hook-timeout otherwise. Zero means hooks
+// may run indefinitely.
+func (u *UniterAPIV3) HookTimeouts(args params.Entities) (params.HookTimeoutResults, error) {
+	result := params.HookTimeoutResults{
+		Results: make([]params.HookTimeoutResult, len(args.Entities)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.HookTimeoutResults{}, err
+	}
+	envConfig, err := u.st.EnvironConfig()
+	if err != nil {
+		return params.HookTimeoutResults{}, err
+	}
+	for i, entity := range args.Entities {
+		tag, err := names.ParseUnitTag(entity.Tag)
+		if err != nil {
+			result.Results[i].Error = common.ServerError(common.ErrPerm)
+			continue
+		}
+		if !canAccess(tag) {
+			result.Results[i].Error = common.ServerError(common.ErrPerm)
+			continue
+		}
+		unit, err := u.getUnit(tag)
+		if err != nil {
+			result.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		service, err := unit.Service()
+		if err != nil {
+			result.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		timeout := service.HookTimeout()
+		if timeout == 0 {
+			timeout = envConfig.HookTimeout()
+		}
+		result.Results[i].Timeout = timeout
+	}
+	return result, nil
+}
+
+// AddHookRecords records the execution of hooks by the specified units.
+func (u *UniterAPIV3) AddHookRecords(args params.HookRecordParams) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Records)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.ErrorResults{}, err
+	}
+	for i, arg := range args.Records {
+		tag, err := names.ParseUnitTag(arg.Tag)
+		if err != nil {
+			result.Results[i].Error = common.ServerError(common.ErrPerm)
+			continue
+		}
+		if !canAccess(tag) {
+			result.Results[i].Error = common.ServerError(common.ErrPerm)
+			continue
+		}
+		unit, err := u.getUnit(tag)
+		if err == nil {
+			err = unit.AddHookRecord(state.HookRecord{
+				Hook:       arg.Record.Hook,
+				RelationId: arg.Record.RelationId,
+				RemoteUnit: arg.Record.RemoteUnit,
+				Started:    arg.Record.Started,
+				Duration:   arg.Record.Duration,
+				ExitStatus: arg.Record.ExitStatus,
+				Stderr:     arg.Record.Stderr,
+			})
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// SetUnitHealth records the results of the health checks of the units
+// passed in args; if one of the args is not a unit it will fail.
+func (u *UniterAPIV3) SetUnitHealth(args params.SetStatus) (params.ErrorResults, error) {
+	return u.healthSetter.SetStatus(args)
+}
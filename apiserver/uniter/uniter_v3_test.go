@@ -0,0 +1,163 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package uniter_test
+
+import (
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	"github.com/juju/juju/apiserver/uniter"
+	"github.com/juju/juju/network"
+	"github.com/juju/juju/state"
+)
+
+type uniterV3Suite struct {
+	uniterBaseSuite
+	uniter *uniter.UniterAPIV3
+}
+
+var _ = gc.Suite(&uniterV3Suite{})
+
+func (s *uniterV3Suite) SetUpTest(c *gc.C) {
+	s.uniterBaseSuite.setUpTest(c)
+
+	uniterAPIV3, err := uniter.NewUniterAPIV3(
+		s.State,
+		s.resources,
+		s.authorizer,
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	s.uniter = uniterAPIV3
+}
+
+func (s *uniterV3Suite) TestNetworkInfo(c *gc.C) {
+	err := s.machine0.SetProviderAddresses(
+		network.NewScopedAddress("10.0.0.5", network.ScopeCloudLocal),
+	)
+	c.Assert(err, jc.ErrorIsNil)
+
+	result, err := s.uniter.NetworkInfo(params.NetworkInfoParams{
+		Unit:     "unit-wordpress-0",
+		Bindings: []string{"db", "admin"},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.NetworkInfoResults{
+		Results: map[string]params.NetworkInfoResult{
+			"db": {Info: params.NetworkInfo{Address: "10.0.0.5"}},
+			"admin": {Error: &params.Error{
+				Message: `endpoint "admin" of service "wordpress" not found`,
+				Code:    params.CodeNotFound,
+			}},
+		},
+	})
+}
+
+func (s *uniterV3Suite) TestNetworkInfoPermissionDenied(c *gc.C) {
+	for _, unit := range []string{"unit-mysql-0", "unit-foo-42", "machine-0"} {
+		_, err := s.uniter.NetworkInfo(params.NetworkInfoParams{
+			Unit:     unit,
+			Bindings: []string{"db"},
+		})
+		c.Check(err, gc.ErrorMatches, "permission denied")
+	}
+}
+
+func (s *uniterV3Suite) TestAddHookRecords(c *gc.C) {
+	started := time.Date(2015, 11, 2, 10, 0, 0, 0, time.UTC)
+	record := params.HookRecord{
+		Hook:       "config-changed",
+		RelationId: -1,
+		Started:    started,
+		Duration:   3 * time.Minute,
+		ExitStatus: 1,
+		Stderr:     []string{"E: unable to locate package"},
+	}
+	result, err := s.uniter.AddHookRecords(params.HookRecordParams{
+		Records: []params.HookRecordParam{
+			{Tag: "unit-wordpress-0", Record: record},
+			{Tag: "unit-mysql-0", Record: record},
+			{Tag: "machine-0", Record: record},
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{
+			{nil},
+			{apiservertesting.ErrUnauthorized},
+			{apiservertesting.ErrUnauthorized},
+		},
+	})
+
+	records, err := s.wordpressUnit.HookRecords(0)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(records, jc.DeepEquals, []state.HookRecord{{
+		Hook:       "config-changed",
+		RelationId: -1,
+		Started:    started,
+		Duration:   3 * time.Minute,
+		ExitStatus: 1,
+		Stderr:     []string{"E: unable to locate package"},
+	}})
+}
+
+func (s *uniterV3Suite) TestHookTimeouts(c *gc.C) {
+	args := params.Entities{Entities: []params.Entity{
+		{Tag: "unit-wordpress-0"},
+		{Tag: "unit-mysql-0"},
+		{Tag: "machine-0"},
+	}}
+	result, err := s.uniter.HookTimeouts(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.HookTimeoutResults{
+		Results: []params.HookTimeoutResult{
+			{},
+			{Error: apiservertesting.ErrUnauthorized},
+			{Error: apiservertesting.ErrUnauthorized},
+		},
+	})
+
+	err = s.State.UpdateEnvironConfig(map[string]interface{}{"hook-timeout": "1h"}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	result, err = s.uniter.HookTimeouts(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Results[0], jc.DeepEquals, params.HookTimeoutResult{Timeout: time.Hour})
+
+	err = s.wordpress.SetHookTimeout(10 * time.Minute)
+	c.Assert(err, jc.ErrorIsNil)
+	result, err = s.uniter.HookTimeouts(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Results[0], jc.DeepEquals, params.HookTimeoutResult{Timeout: 10 * time.Minute})
+}
+
+func (s *uniterV3Suite) TestSetUnitHealth(c *gc.C) {
+	args := params.SetStatus{
+		Entities: []params.EntityStatusArgs{
+			{Tag: "unit-mysql-0", Status: params.StatusUnhealthy, Info: "not really"},
+			{Tag: "unit-wordpress-0", Status: params.StatusUnhealthy, Info: `check "web" failed`},
+			{Tag: "unit-foo-42", Status: params.StatusHealthy},
+		}}
+	result, err := s.uniter.SetUnitHealth(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, gc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{
+			{apiservertesting.ErrUnauthorized},
+			{nil},
+			{apiservertesting.ErrUnauthorized},
+		},
+	})
+
+	// Verify mysqlUnit - no change.
+	statusInfo, err := s.mysqlUnit.Health().Status()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(statusInfo.Status, gc.Equals, state.StatusUnknown)
+	// ...wordpressUnit is fine though.
+	statusInfo, err = s.wordpressUnit.Health().Status()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(statusInfo.Status, gc.Equals, state.StatusUnhealthy)
+	c.Assert(statusInfo.Message, gc.Equals, `check "web" failed`)
+}
@@ -0,0 +1,172 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package usermanager
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/apiserver/authentication"
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+)
+
+// tokenOwner returns the tag of the user that owns the API token being
+// managed, if the logged in user may manage their tokens. Users may
+// manage their own tokens; only administrators may manage those of
+// other users.
+func (api *UserManagerAPI) tokenOwner(loggedInUser names.UserTag, owner string) (names.UserTag, error) {
+	if owner == "" {
+		return loggedInUser, nil
+	}
+	ownerTag, err := names.ParseUserTag(owner)
+	if err != nil {
+		return names.UserTag{}, errors.Trace(err)
+	}
+	if ownerTag != loggedInUser {
+		if err := api.permissionCheck(loggedInUser); err != nil {
+			return names.UserTag{}, errors.Trace(err)
+		}
+	}
+	return ownerTag, nil
+}
+
+// AddAPIToken adds API tokens that users can log in with instead of
+// their passwords. The credentials to log in with are returned only
+// once; they cannot be retrieved again later.
+func (api *UserManagerAPI) AddAPIToken(args params.AddAPITokens) (params.AddAPITokenResults, error) {
+	result := params.AddAPITokenResults{
+		Results: make([]params.AddAPITokenResult, len(args.Tokens)),
+	}
+	if err := api.check.ChangeAllowed(); err != nil {
+		return result, errors.Trace(err)
+	}
+	if len(args.Tokens) == 0 {
+		return result, nil
+	}
+	loggedInUser, err := api.getLoggedInUser()
+	if err != nil {
+		return result, errors.Wrap(err, common.ErrPerm)
+	}
+	for i, arg := range args.Tokens {
+		credentials, err := api.addAPIToken(loggedInUser, arg)
+		if err != nil {
+			result.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		result.Results[i].Credentials = credentials
+	}
+	return result, nil
+}
+
+func (api *UserManagerAPI) addAPIToken(loggedInUser names.UserTag, arg params.AddAPIToken) (string, error) {
+	owner, err := api.tokenOwner(loggedInUser, arg.Owner)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	spec := state.APITokenSpec{
+		Owner:        owner,
+		Name:         arg.Name,
+		Environments: arg.Environments,
+		Facades:      arg.Facades,
+	}
+	if arg.Expires != nil {
+		if !arg.Expires.After(time.Now()) {
+			return "", errors.NotValidf("expiry time %v in the past", arg.Expires.UTC())
+		}
+		spec.Expires = *arg.Expires
+	}
+	token, secret, err := api.state.AddAPIToken(spec)
+	if err != nil {
+		return "", errors.Annotate(err, "failed to create API token")
+	}
+	return authentication.FormatTokenCredentials(token.Name(), secret), nil
+}
+
+// ListAPITokens returns the API tokens of the given users. If no users
+// are given, the tokens of the logged in user are returned.
+func (api *UserManagerAPI) ListAPITokens(args params.Entities) (params.APITokensResults, error) {
+	var result params.APITokensResults
+	loggedInUser, err := api.getLoggedInUser()
+	if err != nil {
+		return result, errors.Wrap(err, common.ErrPerm)
+	}
+	owners := args.Entities
+	if len(owners) == 0 {
+		owners = []params.Entity{{Tag: loggedInUser.String()}}
+	}
+	result.Results = make([]params.APITokensResult, len(owners))
+	for i, arg := range owners {
+		tokens, err := api.listAPITokens(loggedInUser, arg.Tag)
+		if err != nil {
+			result.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		result.Results[i].Tokens = tokens
+	}
+	return result, nil
+}
+
+func (api *UserManagerAPI) listAPITokens(loggedInUser names.UserTag, owner string) ([]params.APITokenInfo, error) {
+	ownerTag, err := api.tokenOwner(loggedInUser, owner)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	tokens, err := api.state.APITokens(ownerTag)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	result := make([]params.APITokenInfo, len(tokens))
+	for i, token := range tokens {
+		info := params.APITokenInfo{
+			Owner:        token.Owner().String(),
+			Name:         token.Name(),
+			Created:      token.Created(),
+			Environments: token.Environments(),
+			Facades:      token.Facades(),
+		}
+		if expires := token.Expires(); !expires.IsZero() {
+			info.Expires = &expires
+		}
+		lastUsed, err := token.LastUsed()
+		if err != nil {
+			logger.Debugf("error getting last use of API token: %v", err)
+		} else if !lastUsed.IsZero() {
+			info.LastUsed = &lastUsed
+		}
+		result[i] = info
+	}
+	return result, nil
+}
+
+// RemoveAPIToken revokes API tokens. Connections already made with the
+// tokens stop being able to make calls shortly afterwards.
+func (api *UserManagerAPI) RemoveAPIToken(args params.APITokenNames) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Tokens)),
+	}
+	if err := api.check.RemoveAllowed(); err != nil {
+		return result, errors.Trace(err)
+	}
+	if len(args.Tokens) == 0 {
+		return result, nil
+	}
+	loggedInUser, err := api.getLoggedInUser()
+	if err != nil {
+		return result, errors.Wrap(err, common.ErrPerm)
+	}
+	for i, arg := range args.Tokens {
+		owner, err := api.tokenOwner(loggedInUser, arg.Owner)
+		if err == nil {
+			err = api.state.RemoveAPIToken(owner, arg.Name)
+		}
+		if err != nil {
+			result.Results[i].Error = common.ServerError(err)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,173 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package usermanager_test
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/authentication"
+	"github.com/juju/juju/apiserver/params"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	"github.com/juju/juju/apiserver/usermanager"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/testing/factory"
+)
+
+func (s *userManagerSuite) apiForUser(c *gc.C, tag names.Tag) *usermanager.UserManagerAPI {
+	api, err := usermanager.NewUserManagerAPI(s.State, nil, apiservertesting.FakeAuthorizer{Tag: tag})
+	c.Assert(err, jc.ErrorIsNil)
+	return api
+}
+
+func (s *userManagerSuite) TestAddAPIToken(c *gc.C) {
+	alex := s.Factory.MakeUser(c, &factory.UserParams{Name: "alex"})
+	expires := time.Now().Add(time.Hour).Round(time.Second).UTC()
+	results, err := s.apiForUser(c, alex.Tag()).AddAPIToken(params.AddAPITokens{
+		Tokens: []params.AddAPIToken{{
+			Name:         "jenkins",
+			Expires:      &expires,
+			Environments: []string{s.State.EnvironUUID()},
+			Facades:      []string{"Client"},
+		}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 1)
+	c.Assert(results.Results[0].Error, gc.IsNil)
+
+	name, secret, ok := authentication.ParseTokenCredentials(results.Results[0].Credentials)
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(name, gc.Equals, "jenkins")
+	token, err := s.State.APIToken(alex.UserTag(), "jenkins")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(token.SecretValid(secret), jc.IsTrue)
+	c.Assert(token.Expires(), gc.Equals, expires)
+	c.Assert(token.Environments(), jc.DeepEquals, []string{s.State.EnvironUUID()})
+	c.Assert(token.Facades(), jc.DeepEquals, []string{"Client"})
+}
+
+func (s *userManagerSuite) TestAddAPITokenExpiryInPast(c *gc.C) {
+	expires := time.Now().Add(-time.Hour)
+	results, err := s.usermanager.AddAPIToken(params.AddAPITokens{
+		Tokens: []params.AddAPIToken{{Name: "jenkins", Expires: &expires}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results[0].Error, gc.ErrorMatches, "expiry time .* in the past not valid")
+}
+
+func (s *userManagerSuite) TestAddAPITokenForOther(c *gc.C) {
+	alex := s.Factory.MakeUser(c, &factory.UserParams{Name: "alex"})
+	barb := s.Factory.MakeUser(c, &factory.UserParams{Name: "barb"})
+	args := params.AddAPITokens{
+		Tokens: []params.AddAPIToken{{Owner: barb.Tag().String(), Name: "jenkins"}},
+	}
+
+	// Only administrators may add tokens for other users.
+	results, err := s.apiForUser(c, alex.Tag()).AddAPIToken(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results[0].Error, gc.ErrorMatches, "permission denied")
+
+	results, err = s.usermanager.AddAPIToken(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results[0].Error, gc.IsNil)
+	_, err = s.State.APIToken(barb.UserTag(), "jenkins")
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *userManagerSuite) TestBlockAddAPIToken(c *gc.C) {
+	s.BlockAllChanges(c, "TestBlockAddAPIToken")
+	_, err := s.usermanager.AddAPIToken(params.AddAPITokens{
+		Tokens: []params.AddAPIToken{{Name: "jenkins"}},
+	})
+	s.AssertBlocked(c, err, "TestBlockAddAPIToken")
+}
+
+func (s *userManagerSuite) TestListAPITokens(c *gc.C) {
+	alex := s.Factory.MakeUser(c, &factory.UserParams{Name: "alex"})
+	expires := time.Now().Add(time.Hour).Round(time.Second).UTC()
+	token, _, err := s.State.AddAPIToken(state.APITokenSpec{
+		Owner:   alex.UserTag(),
+		Name:    "jenkins",
+		Expires: expires,
+		Facades: []string{"Client"},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	err = token.UpdateLastUsed()
+	c.Assert(err, jc.ErrorIsNil)
+	lastUsed, err := token.LastUsed()
+	c.Assert(err, jc.ErrorIsNil)
+
+	results, err := s.apiForUser(c, alex.Tag()).ListAPITokens(params.Entities{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results, jc.DeepEquals, params.APITokensResults{
+		Results: []params.APITokensResult{{
+			Tokens: []params.APITokenInfo{{
+				Owner:    alex.Tag().String(),
+				Name:     "jenkins",
+				Created:  token.Created(),
+				Expires:  &expires,
+				LastUsed: &lastUsed,
+				Facades:  []string{"Client"},
+			}},
+		}},
+	})
+}
+
+func (s *userManagerSuite) TestListAPITokensForOther(c *gc.C) {
+	alex := s.Factory.MakeUser(c, &factory.UserParams{Name: "alex"})
+	barb := s.Factory.MakeUser(c, &factory.UserParams{Name: "barb"})
+	_, _, err := s.State.AddAPIToken(state.APITokenSpec{Owner: barb.UserTag(), Name: "jenkins"})
+	c.Assert(err, jc.ErrorIsNil)
+	args := params.Entities{Entities: []params.Entity{{Tag: barb.Tag().String()}}}
+
+	results, err := s.apiForUser(c, alex.Tag()).ListAPITokens(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results[0].Error, gc.ErrorMatches, "permission denied")
+
+	results, err = s.usermanager.ListAPITokens(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results[0].Error, gc.IsNil)
+	c.Assert(results.Results[0].Tokens, gc.HasLen, 1)
+	c.Assert(results.Results[0].Tokens[0].Name, gc.Equals, "jenkins")
+}
+
+func (s *userManagerSuite) TestRemoveAPIToken(c *gc.C) {
+	alex := s.Factory.MakeUser(c, &factory.UserParams{Name: "alex"})
+	_, _, err := s.State.AddAPIToken(state.APITokenSpec{Owner: alex.UserTag(), Name: "jenkins"})
+	c.Assert(err, jc.ErrorIsNil)
+
+	results, err := s.apiForUser(c, alex.Tag()).RemoveAPIToken(params.APITokenNames{
+		Tokens: []params.APITokenName{{Name: "jenkins"}, {Name: "travis"}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 2)
+	c.Assert(results.Results[0].Error, gc.IsNil)
+	c.Assert(results.Results[1].Error, gc.ErrorMatches, `API token "travis" for user "alex" not found`)
+	_, err = s.State.APIToken(alex.UserTag(), "jenkins")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *userManagerSuite) TestRemoveAPITokenForOther(c *gc.C) {
+	alex := s.Factory.MakeUser(c, &factory.UserParams{Name: "alex"})
+	barb := s.Factory.MakeUser(c, &factory.UserParams{Name: "barb"})
+	_, _, err := s.State.AddAPIToken(state.APITokenSpec{Owner: barb.UserTag(), Name: "jenkins"})
+	c.Assert(err, jc.ErrorIsNil)
+	args := params.APITokenNames{
+		Tokens: []params.APITokenName{{Owner: barb.Tag().String(), Name: "jenkins"}},
+	}
+
+	results, err := s.apiForUser(c, alex.Tag()).RemoveAPIToken(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results[0].Error, gc.ErrorMatches, "permission denied")
+	_, err = s.State.APIToken(barb.UserTag(), "jenkins")
+	c.Assert(err, jc.ErrorIsNil)
+
+	results, err = s.usermanager.RemoveAPIToken(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results[0].Error, gc.IsNil)
+}
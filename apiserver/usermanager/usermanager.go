@@ -81,7 +81,15 @@ func (api *UserManagerAPI) AddUser(args params.AddUsers) (params.AddUserResults,
 	if err := api.permissionCheck(loggedInUser); err != nil {
 		return result, errors.Trace(err)
 	}
+	policy, err := api.state.PasswordPolicy()
+	if err != nil {
+		return result, errors.Trace(err)
+	}
 	for i, arg := range args.Users {
+		if err := policy.Check(arg.Password); err != nil {
+			result.Results[i].Error = common.ServerError(err)
+			continue
+		}
 		user, err := api.state.AddUser(arg.Username, arg.DisplayName, arg.Password, loggedInUser.Id())
 		if err != nil {
 			err = errors.Annotate(err, "failed to create user")
@@ -106,7 +114,8 @@ func (api *UserManagerAPI) getUser(tag string) (*state.User, error) {
 }
 
 // EnableUser enables one or more users.  If the user is already enabled,
-// the action is consided a success.
+// the action is consided a success. Enabling a user also clears any
+// lockout after failed logins.
 func (api *UserManagerAPI) EnableUser(users params.Entities) (params.ErrorResults, error) {
 	if err := api.check.ChangeAllowed(); err != nil {
 		return params.ErrorResults{}, errors.Trace(err)
@@ -176,6 +185,7 @@ func (api *UserManagerAPI) UserInfo(request params.UserInfoRequest) (params.User
 				DateCreated:    user.DateCreated(),
 				LastConnection: lastLogin,
 				Disabled:       user.IsDisabled(),
+				LockedOut:      user.IsLockedOut(),
 			},
 		}
 	}
@@ -205,7 +215,7 @@ func (api *UserManagerAPI) UserInfo(request params.UserInfoRequest) (params.User
 	return results, nil
 }
 
-func (api *UserManagerAPI) setPassword(loggedInUser names.UserTag, arg params.EntityPassword, adminUser bool) error {
+func (api *UserManagerAPI) setPassword(loggedInUser names.UserTag, arg params.EntityPassword, adminUser bool, policy state.PasswordPolicy) error {
 	user, err := api.getUser(arg.Tag)
 	if err != nil {
 		return errors.Trace(err)
@@ -216,6 +226,9 @@ func (api *UserManagerAPI) setPassword(loggedInUser names.UserTag, arg params.En
 	if arg.Password == "" {
 		return errors.New("can not use an empty password")
 	}
+	if err := policy.Check(arg.Password); err != nil {
+		return errors.Trace(err)
+	}
 	err = user.SetPassword(arg.Password)
 	if err != nil {
 		return errors.Annotate(err, "failed to set password")
@@ -240,8 +253,12 @@ func (api *UserManagerAPI) SetPassword(args params.EntityPasswords) (params.Erro
 	}
 	permErr := api.permissionCheck(loggedInUser)
 	adminUser := permErr == nil
+	policy, err := api.state.PasswordPolicy()
+	if err != nil {
+		return result, errors.Trace(err)
+	}
 	for i, arg := range args.Changes {
-		if err := api.setPassword(loggedInUser, arg, adminUser); err != nil {
+		if err := api.setPassword(loggedInUser, arg, adminUser, policy); err != nil {
 			result.Results[i].Error = common.ServerError(err)
 		}
 	}
@@ -79,6 +79,37 @@ func (s *userManagerSuite) TestAddUser(c *gc.C) {
 	c.Assert(user.DisplayName(), gc.Equals, "Foo Bar")
 }
 
+func (s *userManagerSuite) setPasswordPolicy(c *gc.C, minLength int) {
+	err := s.State.UpdateEnvironConfig(map[string]interface{}{
+		"password-min-length": minLength,
+	}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *userManagerSuite) TestAddUserPasswordPolicy(c *gc.C) {
+	s.setPasswordPolicy(c, 12)
+	args := params.AddUsers{
+		Users: []params.AddUser{{
+			Username: "foobar",
+			Password: "password",
+		}, {
+			Username: "foobaz",
+			Password: "a much longer password",
+		}}}
+
+	result, err := s.usermanager.AddUser(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Results, gc.DeepEquals, []params.AddUserResult{{
+		Error: &params.Error{
+			Message: "password shorter than 12 characters not valid",
+		},
+	}, {
+		Tag: names.NewLocalUserTag("foobaz").String(),
+	}})
+	_, err = s.State.User(names.NewLocalUserTag("foobar"))
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
 func (s *userManagerSuite) TestBlockAddUser(c *gc.C) {
 	args := params.AddUsers{
 		Users: []params.AddUser{{
@@ -225,6 +256,24 @@ func (s *userManagerSuite) TestEnableUser(c *gc.C) {
 	c.Assert(barb.IsDisabled(), jc.IsFalse)
 }
 
+func (s *userManagerSuite) TestEnableUserClearsLockout(c *gc.C) {
+	alex := s.Factory.MakeUser(c, &factory.UserParams{Name: "alex", Password: "password"})
+	lockedOut, err := alex.RecordFailedLogin(1, time.Hour)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(lockedOut, jc.IsTrue)
+
+	result, err := s.usermanager.EnableUser(params.Entities{
+		Entities: []params.Entity{{alex.Tag().String()}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.OneError(), jc.ErrorIsNil)
+
+	err = alex.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(alex.IsLockedOut(), jc.IsFalse)
+	c.Assert(alex.PasswordValid("password"), jc.IsTrue)
+}
+
 func (s *userManagerSuite) TestBlockEnableUser(c *gc.C) {
 	alex := s.Factory.MakeUser(c, &factory.UserParams{Name: "alex"})
 	barb := s.Factory.MakeUser(c, &factory.UserParams{Name: "barb", Disabled: true})
@@ -436,6 +485,28 @@ func (s *userManagerSuite) TestSetPassword(c *gc.C) {
 	c.Assert(alex.PasswordValid("new-password"), jc.IsTrue)
 }
 
+func (s *userManagerSuite) TestSetPasswordPolicy(c *gc.C) {
+	s.setPasswordPolicy(c, 12)
+	alex := s.Factory.MakeUser(c, &factory.UserParams{Name: "alex"})
+
+	args := params.EntityPasswords{
+		Changes: []params.EntityPassword{{
+			Tag:      alex.Tag().String(),
+			Password: "new-password",
+		}}}
+	results, err := s.usermanager.SetPassword(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 1)
+	c.Assert(results.Results[0], gc.DeepEquals, params.ErrorResult{
+		Error: &params.Error{
+			Message: "password shorter than 12 characters not valid",
+		}})
+
+	err = alex.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(alex.PasswordValid("new-password"), jc.IsFalse)
+}
+
 func (s *userManagerSuite) TestBlockSetPassword(c *gc.C) {
 	alex := s.Factory.MakeUser(c, &factory.UserParams{Name: "alex"})
 
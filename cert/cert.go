@@ -38,6 +38,33 @@ func ParseCert(certPEM string) (*x509.Certificate, error) {
 	return nil, errors.New("no certificates found")
 }
 
+// ParseCerts parses all the PEM-formatted X509 certificates in the
+// given data. It is used to read CA bundles, which hold more than one
+// trusted certificate while a CA is being rotated.
+func ParseCerts(certsPEM string) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	certPEMData := []byte(certsPEM)
+	for len(certPEMData) > 0 {
+		var certBlock *pem.Block
+		certBlock, certPEMData = pem.Decode(certPEMData)
+		if certBlock == nil {
+			break
+		}
+		if certBlock.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(certBlock.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("no certificates found")
+	}
+	return certs, nil
+}
+
 // ParseCertAndKey parses the given PEM-formatted X509 certificate
 // and RSA private key.
 func ParseCertAndKey(certPEM, keyPEM string) (*x509.Certificate, *rsa.PrivateKey, error) {
@@ -59,9 +86,11 @@ func ParseCertAndKey(certPEM, keyPEM string) (*x509.Certificate, *rsa.PrivateKey
 }
 
 // Verify verifies that the given server certificate is valid with
-// respect to the given CA certificate at the given time.
+// respect to the given CA certificate at the given time. If caCertPEM
+// holds more than one certificate, any of them may have signed the
+// server certificate.
 func Verify(srvCertPEM, caCertPEM string, when time.Time) error {
-	caCert, err := ParseCert(caCertPEM)
+	caCerts, err := ParseCerts(caCertPEM)
 	if err != nil {
 		return errors.Annotate(err, "cannot parse CA certificate")
 	}
@@ -70,7 +99,9 @@ func Verify(srvCertPEM, caCertPEM string, when time.Time) error {
 		return errors.Annotate(err, "cannot parse server certificate")
 	}
 	pool := x509.NewCertPool()
-	pool.AddCert(caCert)
+	for _, caCert := range caCerts {
+		pool.AddCert(caCert)
+	}
 	opts := x509.VerifyOptions{
 		DNSName:     "anyServer",
 		Roots:       pool,
@@ -56,6 +56,21 @@ func (certSuite) TestParseCertificate(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, "no certificates found")
 }
 
+func (certSuite) TestParseCerts(c *gc.C) {
+	otherCACert, _, err := cert.NewCA("foo", time.Now().Add(time.Minute))
+	c.Assert(err, jc.ErrorIsNil)
+
+	xcerts, err := cert.ParseCerts(caCertPEM + caKeyPEM + otherCACert)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(xcerts, gc.HasLen, 2)
+	c.Assert(xcerts[0].Subject.CommonName, gc.Equals, "juju testing")
+	c.Assert(xcerts[1].Subject.CommonName, gc.Equals, `juju-generated CA for environment "foo"`)
+
+	xcerts, err = cert.ParseCerts(caKeyPEM)
+	c.Check(xcerts, gc.IsNil)
+	c.Assert(err, gc.ErrorMatches, "no certificates found")
+}
+
 func (certSuite) TestParseCertAndKey(c *gc.C) {
 	xcert, key, err := cert.ParseCertAndKey(caCertPEM, caKeyPEM)
 	c.Assert(err, jc.ErrorIsNil)
@@ -217,6 +232,12 @@ func (certSuite) TestVerify(c *gc.C) {
 	// Check new server certificate against original CA.
 	err = cert.Verify(srvCert2, caCert, now)
 	c.Check(err, gc.ErrorMatches, "x509: certificate signed by unknown authority")
+
+	// Both server certificates are valid against a bundle of both CAs.
+	err = cert.Verify(srvCert, caCert2+caCert, now)
+	c.Check(err, jc.ErrorIsNil)
+	err = cert.Verify(srvCert2, caCert2+caCert, now)
+	c.Check(err, jc.ErrorIsNil)
 }
 
 // checkTLSConnection checks that we can correctly perform a TLS
@@ -30,6 +30,7 @@ func newDoCommand() cmd.Command {
 type doCommand struct {
 	ActionCommandBase
 	unitTag      names.UnitTag
+	selector     string
 	actionName   string
 	paramsYAML   cmd.FileVar
 	parseStrings bool
@@ -103,6 +104,13 @@ $ juju action do sleeper/0 pause time=1000
 $ juju action do sleeper/0 pause --string-args time=1000
 ...
 The value for the "time" param will be the string literal "1000".
+
+Instead of a unit, a label selector may be given with --selector (-l), in
+which case the action is queued on every unit whose labels match it:
+
+$ juju action do -l env=canary backup
+mysql/3: <ID>
+mysql/4: <ID>
 `
 
 // ActionNameRule describes the format an action name must match to be valid.
@@ -113,12 +121,14 @@ func (c *doCommand) SetFlags(f *gnuflag.FlagSet) {
 	c.out.AddFlags(f, "smart", cmd.DefaultFormatters)
 	f.Var(&c.paramsYAML, "params", "path to yaml-formatted params file")
 	f.BoolVar(&c.parseStrings, "string-args", false, "use raw string values of CLI args")
+	f.StringVar(&c.selector, "l", "", "queue the action on the units whose labels match the selector")
+	f.StringVar(&c.selector, "selector", "", "")
 }
 
 func (c *doCommand) Info() *cmd.Info {
 	return &cmd.Info{
 		Name:    "do",
-		Args:    "<unit> <action name> [key.key.key...=value]",
+		Args:    "(<unit> | --selector <selector>) <action name> [key.key.key...=value]",
 		Purpose: "queue an action for execution",
 		Doc:     doDoc,
 	}
@@ -126,29 +136,34 @@ func (c *doCommand) Info() *cmd.Info {
 
 // Init gets the unit tag, and checks for other correct args.
 func (c *doCommand) Init(args []string) error {
-	switch len(args) {
-	case 0:
-		return errors.New("no unit specified")
-	case 1:
-		return errors.New("no action specified")
-	default:
-		// Grab and verify the unit and action names.
+	if c.selector == "" {
+		if len(args) == 0 {
+			return errors.New("no unit specified")
+		}
+		// Grab and verify the unit name.
 		unitName := args[0]
 		if !names.IsValidUnit(unitName) {
 			return errors.Errorf("invalid unit name %q", unitName)
 		}
-		ActionName := args[1]
+		c.unitTag = names.NewUnitTag(unitName)
+		args = args[1:]
+	}
+	switch len(args) {
+	case 0:
+		return errors.New("no action specified")
+	default:
+		// Grab and verify the action name.
+		ActionName := args[0]
 		if valid := ActionNameRule.MatchString(ActionName); !valid {
 			return fmt.Errorf("invalid action name %q", ActionName)
 		}
-		c.unitTag = names.NewUnitTag(unitName)
 		c.actionName = ActionName
-		if len(args) == 2 {
+		if len(args) == 1 {
 			return nil
 		}
 		// Parse CLI key-value args if they exist.
 		c.args = make([][]string, 0)
-		for _, arg := range args[2:] {
+		for _, arg := range args[1:] {
 			thisArg := strings.SplitN(arg, "=", 2)
 			if len(thisArg) != 2 {
 				return fmt.Errorf("argument %q must be of the form key...=value", arg)
@@ -227,37 +242,59 @@ func (c *doCommand) Run(ctx *cmd.Context) error {
 		return errors.Errorf("params must be a map, got %T", typedConformantParams)
 	}
 
+	unitTags := []names.UnitTag{c.unitTag}
+	if c.selector != "" {
+		unitNames, err := common.SelectUnits(&c.EnvCommandBase, c.selector)
+		if err != nil {
+			return err
+		}
+		unitTags = make([]names.UnitTag, len(unitNames))
+		for i, name := range unitNames {
+			unitTags[i] = names.NewUnitTag(name)
+		}
+	}
+
 	actionParam := params.Actions{
-		Actions: []params.Action{{
-			Receiver:   c.unitTag.String(),
+		Actions: make([]params.Action, len(unitTags)),
+	}
+	for i, tag := range unitTags {
+		actionParam.Actions[i] = params.Action{
+			Receiver:   tag.String(),
 			Name:       c.actionName,
 			Parameters: actionParams,
-		}},
+		}
 	}
 
 	results, err := api.Enqueue(actionParam)
 	if err != nil {
 		return err
 	}
-	if len(results.Results) != 1 {
+	if len(results.Results) != len(unitTags) {
 		return errors.New("illegal number of results returned")
 	}
 
-	result := results.Results[0]
-
-	if result.Error != nil {
-		return result.Error
+	ids := make([]string, len(results.Results))
+	for i, result := range results.Results {
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.Action == nil {
+			return errors.New("action failed to enqueue")
+		}
+		tag, err := names.ParseActionTag(result.Action.Tag)
+		if err != nil {
+			return err
+		}
+		ids[i] = tag.Id()
 	}
 
-	if result.Action == nil {
-		return errors.New("action failed to enqueue")
+	if c.selector == "" {
+		output := map[string]string{"Action queued with id": ids[0]}
+		return c.out.Write(ctx, output)
 	}
-
-	tag, err := names.ParseActionTag(result.Action.Tag)
-	if err != nil {
-		return err
+	output := make(map[string]string)
+	for i, tag := range unitTags {
+		output[tag.Id()] = ids[i]
 	}
-
-	output := map[string]string{"Action queued with id": tag.Id()}
 	return c.out.Write(ctx, output)
 }
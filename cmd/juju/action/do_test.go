@@ -17,7 +17,9 @@ import (
 
 	"github.com/juju/juju/apiserver/common"
 	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/envcmd"
 	"github.com/juju/juju/cmd/juju/action"
+	cmdcommon "github.com/juju/juju/cmd/juju/common"
 	"github.com/juju/juju/testing"
 )
 
@@ -65,6 +67,7 @@ func (s *DoSuite) TestInit(c *gc.C) {
 		should               string
 		args                 []string
 		expectUnit           names.UnitTag
+		expectSelector       string
 		expectAction         string
 		expectParamsYamlPath string
 		expectParseStrings   bool
@@ -163,6 +166,16 @@ func (s *DoSuite) TestInit(c *gc.C) {
 			{"foo", "baz", "bo", "y"},
 			{"bar", "foo", "hello"},
 		},
+	}, {
+		should:         "take the action name first with a selector",
+		args:           []string{"-l", "env=canary", "valid-action-name", "foo.bar=2"},
+		expectSelector: "env=canary",
+		expectAction:   "valid-action-name",
+		expectKVArgs:   [][]string{{"foo", "bar", "2"}},
+	}, {
+		should:      "fail with a selector and no action specified",
+		args:        []string{"--selector", "env=canary"},
+		expectError: "no action specified",
 	}}
 
 	for i, t := range tests {
@@ -173,6 +186,7 @@ func (s *DoSuite) TestInit(c *gc.C) {
 		err := testing.InitCommand(wrappedCommand, args)
 		if t.expectError == "" {
 			c.Check(command.UnitTag(), gc.Equals, t.expectUnit)
+			c.Check(command.Selector(), gc.Equals, t.expectSelector)
 			c.Check(command.ActionName(), gc.Equals, t.expectAction)
 			c.Check(command.ParamsYAML().Path, gc.Equals, t.expectParamsYamlPath)
 			c.Check(command.Args(), jc.DeepEquals, t.expectKVArgs)
@@ -400,3 +414,71 @@ func (s *DoSuite) TestRun(c *gc.C) {
 		}()
 	}
 }
+
+type fakeLabelsAPI struct {
+	selector string
+	units    []string
+}
+
+func (f *fakeLabelsAPI) Close() error {
+	return nil
+}
+
+func (f *fakeLabelsAPI) Get(tags []string) ([]params.LabelsGetResult, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (f *fakeLabelsAPI) Set(tag string, labels map[string]string) error {
+	return errors.New("not implemented")
+}
+
+func (f *fakeLabelsAPI) Match(selector string) (params.LabelMatchResult, error) {
+	f.selector = selector
+	return params.LabelMatchResult{Units: f.units}, nil
+}
+
+func (s *DoSuite) TestRunSelector(c *gc.C) {
+	labelsAPI := &fakeLabelsAPI{units: []string{"mysql/0", "mysql/1"}}
+	s.PatchValue(&cmdcommon.NewLabelsAPI, func(*envcmd.EnvCommandBase) (cmdcommon.LabelsAPI, error) {
+		return labelsAPI, nil
+	})
+	fakeClient := &fakeAPIClient{
+		actionResults: []params.ActionResult{{
+			Action: &params.Action{Tag: names.NewActionTag("f47ac10b-58cc-4372-a567-0e02b2c3d479").String()},
+		}, {
+			Action: &params.Action{Tag: names.NewActionTag("a47ac10b-58cc-4372-a567-0e02b2c3d479").String()},
+		}},
+	}
+	restore := s.patchAPIClient(fakeClient)
+	defer restore()
+
+	wrappedCommand, _ := action.NewDoCommand()
+	ctx, err := testing.RunCommand(c, wrappedCommand, "-e", "dummyenv", "-l", "env=canary", "some-action")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(labelsAPI.selector, gc.Equals, "env=canary")
+
+	enqueued := fakeClient.EnqueuedActions()
+	c.Assert(enqueued.Actions, gc.HasLen, 2)
+	c.Check(enqueued.Actions[0].Receiver, gc.Equals, "unit-mysql-0")
+	c.Check(enqueued.Actions[1].Receiver, gc.Equals, "unit-mysql-1")
+
+	resultMap := make(map[string]string)
+	err = yaml.Unmarshal(ctx.Stdout.(*bytes.Buffer).Bytes(), &resultMap)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(resultMap, jc.DeepEquals, map[string]string{
+		"mysql/0": "f47ac10b-58cc-4372-a567-0e02b2c3d479",
+		"mysql/1": "a47ac10b-58cc-4372-a567-0e02b2c3d479",
+	})
+}
+
+func (s *DoSuite) TestRunSelectorNoMatch(c *gc.C) {
+	s.PatchValue(&cmdcommon.NewLabelsAPI, func(*envcmd.EnvCommandBase) (cmdcommon.LabelsAPI, error) {
+		return &fakeLabelsAPI{}, nil
+	})
+	restore := s.patchAPIClient(&fakeAPIClient{})
+	defer restore()
+
+	wrappedCommand, _ := action.NewDoCommand()
+	_, err := testing.RunCommand(c, wrappedCommand, "-e", "dummyenv", "-l", "env=canary", "some-action")
+	c.Check(err, gc.ErrorMatches, `no units match selector "env=canary"`)
+}
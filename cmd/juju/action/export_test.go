@@ -26,6 +26,10 @@ func (c *DoCommand) UnitTag() names.UnitTag {
 	return c.unitTag
 }
 
+func (c *DoCommand) Selector() string {
+	return c.selector
+}
+
 func (c *DoCommand) ActionName() string {
 	return c.actionName
 }
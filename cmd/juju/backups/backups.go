@@ -6,7 +6,9 @@ package backups
 import (
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
+	"strings"
 
 	"github.com/juju/cmd"
 	"github.com/juju/errors"
@@ -62,6 +64,8 @@ func NewSuperCommand() cmd.Command {
 	backupsCmd.Register(newUploadCommand())
 	backupsCmd.Register(newRemoveCommand())
 	backupsCmd.Register(newRestoreCommand())
+	backupsCmd.Register(newSetRemoteCommand())
+	backupsCmd.Register(newShowRemoteCommand())
 	return &backupsCmd
 }
 
@@ -70,11 +74,13 @@ func NewSuperCommand() cmd.Command {
 type APIClient interface {
 	io.Closer
 	// Create sends an RPC request to create a new backup.
-	Create(notes string) (*params.BackupsMetadataResult, error)
+	Create(notes string, key params.BackupsEncryptionKey) (*params.BackupsMetadataResult, error)
 	// Info gets the backup's metadata.
 	Info(id string) (*params.BackupsMetadataResult, error)
 	// List gets all stored metadata.
 	List() (*params.BackupsListResult, error)
+	// ListRemote gets the metadata of all backups in remote storage.
+	ListRemote() (*params.BackupsListResult, error)
 	// Download pulls the backup archive file.
 	Download(id string) (io.ReadCloser, error)
 	// Upload pushes a backup archive to storage.
@@ -82,9 +88,16 @@ type APIClient interface {
 	// Remove removes the stored backup.
 	Remove(id string) error
 	// Restore will restore a backup with the given id into the state server.
-	Restore(string, backups.ClientConnection) error
+	Restore(string, params.BackupsEncryptionKey, backups.ClientConnection) error
 	// Restore will restore a backup file into the state server.
-	RestoreReader(io.ReadSeeker, *params.BackupsMetadataResult, backups.ClientConnection) error
+	RestoreReader(io.ReadSeeker, *params.BackupsMetadataResult, params.BackupsEncryptionKey, backups.ClientConnection) error
+	// RestoreFromRemote will restore a backup with the given id from
+	// remote storage into the state server.
+	RestoreFromRemote(string, params.BackupsEncryptionKey, backups.ClientConnection) error
+	// Remote gets the configuration of the remote backup storage.
+	Remote() (*params.BackupsRemoteConfig, error)
+	// SetRemote sets the remote backup storage.
+	SetRemote(remoteType string, attrs map[string]string) error
 }
 
 // CommandBase is the base type for backups sub-commands.
@@ -116,6 +129,9 @@ func (c *CommandBase) dumpMetadata(ctx *cmd.Context, result *params.BackupsMetad
 	fmt.Fprintf(ctx.Stdout, "started:         %v\n", result.Started)
 	fmt.Fprintf(ctx.Stdout, "finished:        %v\n", result.Finished)
 	fmt.Fprintf(ctx.Stdout, "notes:           %q\n", result.Notes)
+	if result.Encryption != "" {
+		fmt.Fprintf(ctx.Stdout, "encryption:      %q\n", result.Encryption)
+	}
 
 	fmt.Fprintf(ctx.Stdout, "environment ID:  %q\n", result.Environment)
 	fmt.Fprintf(ctx.Stdout, "machine ID:      %q\n", result.Machine)
@@ -128,6 +144,109 @@ type readSeekCloser interface {
 	io.Closer
 }
 
+// readEncryptionKey returns the key material used to encrypt a new
+// backup. Either filename may be empty. The key file holds a PEM
+// encoded RSA public key.
+func readEncryptionKey(passphraseFile, publicKeyFile string) (params.BackupsEncryptionKey, error) {
+	var key params.BackupsEncryptionKey
+	if passphraseFile != "" && publicKeyFile != "" {
+		return key, errors.New("cannot use both a passphrase and a key file")
+	}
+	if passphraseFile != "" {
+		passphrase, err := readPassphrase(passphraseFile)
+		if err != nil {
+			return key, errors.Trace(err)
+		}
+		key.Passphrase = passphrase
+	}
+	if publicKeyFile != "" {
+		data, err := ioutil.ReadFile(publicKeyFile)
+		if err != nil {
+			return key, errors.Annotate(err, "cannot read key file")
+		}
+		key.PublicKey = string(data)
+	}
+	return key, nil
+}
+
+// readDecryptionKey returns the key used to decrypt a backup on the
+// client, or nil if both filenames are empty. The key file holds a PEM
+// encoded RSA private key, which is never sent to the state server.
+func readDecryptionKey(passphraseFile, privateKeyFile string) (*statebackups.EncryptionKey, error) {
+	if passphraseFile != "" && privateKeyFile != "" {
+		return nil, errors.New("cannot use both a passphrase and a key file")
+	}
+	switch {
+	case passphraseFile != "":
+		passphrase, err := readPassphrase(passphraseFile)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return &statebackups.EncryptionKey{Passphrase: passphrase}, nil
+	case privateKeyFile != "":
+		data, err := ioutil.ReadFile(privateKeyFile)
+		if err != nil {
+			return nil, errors.Annotate(err, "cannot read key file")
+		}
+		privateKey, err := statebackups.ParsePrivateKey(data)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return &statebackups.EncryptionKey{PrivateKey: privateKey}, nil
+	}
+	return nil, nil
+}
+
+func readPassphrase(filename string) (string, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return "", errors.Annotate(err, "cannot read passphrase")
+	}
+	passphrase := strings.TrimRight(string(data), "\r\n")
+	if passphrase == "" {
+		return "", errors.Errorf("passphrase file %q is empty", filename)
+	}
+	return passphrase, nil
+}
+
+// decryptArchive decrypts the backup archive in filename into a new
+// temporary file and returns its name, along with a function that
+// removes it. If the archive is not encrypted, filename is returned
+// unchanged.
+func decryptArchive(filename string, key statebackups.EncryptionKey) (_ string, cleanup func(), err error) {
+	archive, err := os.Open(filename)
+	if err != nil {
+		return "", nil, errors.Trace(err)
+	}
+	defer archive.Close()
+	encryption, err := statebackups.ArchiveEncryption(archive)
+	if err != nil {
+		return "", nil, errors.Trace(err)
+	}
+	if encryption == statebackups.EncryptionNone {
+		return filename, func() {}, nil
+	}
+	if _, err := archive.Seek(0, os.SEEK_SET); err != nil {
+		return "", nil, errors.Trace(err)
+	}
+	contents, err := statebackups.NewDecryptingReader(archive, key)
+	if err != nil {
+		return "", nil, errors.Trace(err)
+	}
+
+	decrypted, err := ioutil.TempFile("", "juju-backup-")
+	if err != nil {
+		return "", nil, errors.Trace(err)
+	}
+	defer decrypted.Close()
+	cleanup = func() { os.Remove(decrypted.Name()) }
+	if _, err := io.Copy(decrypted, contents); err != nil {
+		cleanup()
+		return "", nil, errors.Annotate(err, "cannot decrypt backup")
+	}
+	return decrypted.Name(), cleanup, nil
+}
+
 func getArchive(filename string) (rc readSeekCloser, metaResult *params.BackupsMetadataResult, err error) {
 	defer func() {
 		if err != nil && rc != nil {
@@ -140,8 +259,8 @@ func getArchive(filename string) (rc readSeekCloser, metaResult *params.BackupsM
 		return nil, nil, errors.Trace(err)
 	}
 
-	// Extract the metadata.
-	ad, err := statebackups.NewArchiveDataReader(archive)
+	// Find out whether the archive is encrypted.
+	encryption, err := statebackups.ArchiveEncryption(archive)
 	if err != nil {
 		return nil, nil, errors.Trace(err)
 	}
@@ -149,16 +268,32 @@ func getArchive(filename string) (rc readSeekCloser, metaResult *params.BackupsM
 	if err != nil {
 		return nil, nil, errors.Trace(err)
 	}
-	meta, err := ad.Metadata()
-	if err != nil {
-		if !errors.IsNotFound(err) {
+
+	// Extract the metadata. The contents of an encrypted archive are
+	// not available, so we fall back to what can be learned from the
+	// file itself.
+	var meta *statebackups.Metadata
+	if encryption == statebackups.EncryptionNone {
+		ad, err := statebackups.NewArchiveDataReader(archive)
+		if err != nil {
 			return nil, nil, errors.Trace(err)
 		}
+		meta, err = ad.Metadata()
+		if err != nil && !errors.IsNotFound(err) {
+			return nil, nil, errors.Trace(err)
+		}
+		_, err = archive.Seek(0, os.SEEK_SET)
+		if err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+	}
+	if meta == nil {
 		meta, err = statebackups.BuildMetadata(archive)
 		if err != nil {
 			return nil, nil, errors.Trace(err)
 		}
 	}
+	meta.Encryption = encryption
 	// Make sure the file info is set.
 	fileMeta, err := statebackups.BuildMetadata(archive)
 	if err != nil {
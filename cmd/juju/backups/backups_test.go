@@ -20,6 +20,8 @@ var expectedSubCommmandNames = []string{
 	"list",
 	"remove",
 	"restore",
+	"set-remote",
+	"show-remote",
 	"upload",
 }
 
@@ -41,6 +41,12 @@ Therefore, you should use the --download or --filename options, or use
 "juju backups download", to get a local copy of the backup archive.
 This local copy can then be used to restore an environment even if that
 environment was already destroyed or is otherwise unavailable.
+
+The archive contains secrets such as passwords and the CA private key.
+Use --passphrase-file to encrypt it with a passphrase read from a file,
+or --public-key to encrypt it for the holder of the matching RSA private
+key (given as a PEM file).  The same passphrase or the private key must
+then be given to "juju backups restore".
 `
 
 func newCreateCommand() cmd.Command {
@@ -58,6 +64,10 @@ type createCommand struct {
 	Filename string
 	// Notes is the custom message to associated with the new backup.
 	Notes string
+	// PassphraseFile holds the passphrase used to encrypt the backup.
+	PassphraseFile string
+	// PublicKeyFile holds the public key used to encrypt the backup.
+	PublicKeyFile string
 }
 
 // Info implements Command.Info.
@@ -75,6 +85,8 @@ func (c *createCommand) SetFlags(f *gnuflag.FlagSet) {
 	f.BoolVar(&c.Quiet, "quiet", false, "do not print the metadata")
 	f.BoolVar(&c.NoDownload, "no-download", false, "do not download the archive")
 	f.StringVar(&c.Filename, "filename", notset, "download to this file")
+	f.StringVar(&c.PassphraseFile, "passphrase-file", "", "encrypt the backup with the passphrase in this file")
+	f.StringVar(&c.PublicKeyFile, "public-key", "", "encrypt the backup with the RSA public key in this PEM file")
 }
 
 // Init implements Command.Init.
@@ -91,6 +103,9 @@ func (c *createCommand) Init(args []string) error {
 	if c.Filename == "" {
 		return errors.Errorf("missing filename")
 	}
+	if c.PassphraseFile != "" && c.PublicKeyFile != "" {
+		return errors.Errorf("cannot mix --passphrase-file and --public-key")
+	}
 
 	return nil
 }
@@ -103,7 +118,12 @@ func (c *createCommand) Run(ctx *cmd.Context) error {
 	}
 	defer client.Close()
 
-	result, err := client.Create(c.Notes)
+	key, err := readEncryptionKey(c.PassphraseFile, c.PublicKeyFile)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	result, err := client.Create(c.Notes, key)
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -22,6 +22,10 @@ var (
 	NewUploadCommand  = newUploadCommand
 	NewRemoveCommand  = newRemoveCommand
 	NewRestoreCommand = newRestoreCommand
+	DecryptArchive    = decryptArchive
+
+	NewSetRemoteCommand  = newSetRemoteCommand
+	NewShowRemoteCommand = newShowRemoteCommand
 )
 
 type CreateCommand struct {
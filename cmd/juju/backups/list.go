@@ -10,11 +10,16 @@ import (
 	"github.com/juju/errors"
 	"launchpad.net/gnuflag"
 
+	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/cmd/envcmd"
 )
 
 const listDoc = `
 "list" provides the metadata associated with all backups.
+
+With --remote, the backups held in the remote backup storage configured
+with "juju backups set-remote" are listed instead of those held by the
+state server.
 `
 
 func newListCommand() cmd.Command {
@@ -26,6 +31,8 @@ type listCommand struct {
 	CommandBase
 	// Brief means only IDs will be printed.
 	Brief bool
+	// Remote means backups in remote storage will be listed.
+	Remote bool
 }
 
 // Info implements Command.Info.
@@ -41,6 +48,7 @@ func (c *listCommand) Info() *cmd.Info {
 // SetFlags implements Command.SetFlags.
 func (c *listCommand) SetFlags(f *gnuflag.FlagSet) {
 	f.BoolVar(&c.Brief, "brief", false, "only print IDs")
+	f.BoolVar(&c.Remote, "remote", false, "list backups in remote storage")
 }
 
 // Init implements Command.Init.
@@ -59,7 +67,12 @@ func (c *listCommand) Run(ctx *cmd.Context) error {
 	}
 	defer client.Close()
 
-	result, err := client.List()
+	var result *params.BackupsListResult
+	if c.Remote {
+		result, err = client.ListRemote()
+	} else {
+		result, err = client.List()
+	}
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -48,6 +48,15 @@ func (s *listSuite) TestBrief(c *gc.C) {
 	s.checkStd(c, ctx, out, "")
 }
 
+func (s *listSuite) TestRemote(c *gc.C) {
+	client := s.setSuccess()
+	ctx, err := testing.RunCommand(c, s.subcommand, []string{"--remote", "--brief"}...)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(client.calls, jc.DeepEquals, []string{"ListRemote"})
+	out := s.metaresult.ID + "\n"
+	s.checkStd(c, ctx, out, "")
+}
+
 func (s *listSuite) TestError(c *gc.C) {
 	s.setFailure("failed!")
 	_, err := testing.RunCommand(c, s.subcommand)
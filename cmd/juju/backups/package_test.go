@@ -148,6 +148,9 @@ type fakeAPIClient struct {
 	args  []string
 	idArg string
 	notes string
+	key   params.BackupsEncryptionKey
+
+	remote params.BackupsRemoteConfig
 }
 
 func (f *fakeAPIClient) Check(c *gc.C, id, notes string, calls ...string) {
@@ -156,10 +159,11 @@ func (f *fakeAPIClient) Check(c *gc.C, id, notes string, calls ...string) {
 	c.Check(f.notes, gc.Equals, notes)
 }
 
-func (c *fakeAPIClient) Create(notes string) (*params.BackupsMetadataResult, error) {
+func (c *fakeAPIClient) Create(notes string, key params.BackupsEncryptionKey) (*params.BackupsMetadataResult, error) {
 	c.calls = append(c.calls, "Create")
-	c.args = append(c.args, "notes")
+	c.args = append(c.args, "notes", "key")
 	c.notes = notes
+	c.key = key
 	if c.err != nil {
 		return nil, c.err
 	}
@@ -186,6 +190,16 @@ func (c *fakeAPIClient) List() (*params.BackupsListResult, error) {
 	return &result, nil
 }
 
+func (c *fakeAPIClient) ListRemote() (*params.BackupsListResult, error) {
+	c.calls = append(c.calls, "ListRemote")
+	if c.err != nil {
+		return nil, c.err
+	}
+	var result params.BackupsListResult
+	result.List = []params.BackupsMetadataResult{*c.metaresult}
+	return &result, nil
+}
+
 func (c *fakeAPIClient) Download(id string) (io.ReadCloser, error) {
 	c.calls = append(c.calls, "Download")
 	c.args = append(c.args, "id")
@@ -218,10 +232,32 @@ func (c *fakeAPIClient) Close() error {
 	return nil
 }
 
-func (c *fakeAPIClient) RestoreReader(io.ReadSeeker, *params.BackupsMetadataResult, apibackups.ClientConnection) error {
+func (c *fakeAPIClient) RestoreReader(io.ReadSeeker, *params.BackupsMetadataResult, params.BackupsEncryptionKey, apibackups.ClientConnection) error {
+	return nil
+}
+
+func (c *fakeAPIClient) Restore(string, params.BackupsEncryptionKey, apibackups.ClientConnection) error {
 	return nil
 }
 
-func (c *fakeAPIClient) Restore(string, apibackups.ClientConnection) error {
+func (c *fakeAPIClient) RestoreFromRemote(string, params.BackupsEncryptionKey, apibackups.ClientConnection) error {
 	return nil
 }
+
+func (c *fakeAPIClient) Remote() (*params.BackupsRemoteConfig, error) {
+	c.calls = append(c.calls, "Remote")
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &c.remote, nil
+}
+
+func (c *fakeAPIClient) SetRemote(remoteType string, attrs map[string]string) error {
+	c.calls = append(c.calls, "SetRemote")
+	c.args = append(c.args, "remoteType", "attrs")
+	c.remote = params.BackupsRemoteConfig{
+		Type:  remoteType,
+		Attrs: attrs,
+	}
+	return c.err
+}
@@ -0,0 +1,212 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package backups
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"github.com/juju/utils/keyvalues"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/cmd/envcmd"
+	statebackups "github.com/juju/juju/state/backups"
+)
+
+const setRemoteDoc = `
+"set-remote" configures remote storage to which the state server copies
+every backup it creates, so that backups survive the loss of the state
+server. Backups in remote storage can be listed with "list --remote" and
+restored with "restore --from-remote".
+
+The supported storage types and their settings are:
+
+    local   path=<dir>
+            a directory on the state server, usually a mount point
+            for a network filesystem.
+
+    s3      bucket=<name> access-key=<key> secret-key-file=<file>
+            [region=<region>] [endpoint=<url>] [prefix=<prefix>]
+            an S3 bucket, or a bucket on any service with an S3
+            compatible API when endpoint is given.
+
+Secret settings such as secret-key are not accepted on the command line,
+where other users could see them. They are read from the file named by
+<setting>-file, or else from the environment variable
+JUJU_BACKUPS_<SETTING>, e.g. JUJU_BACKUPS_SECRET_KEY.
+
+With --unset, the remote storage is removed and backups are only kept by
+the state server.
+`
+
+func newSetRemoteCommand() cmd.Command {
+	return envcmd.Wrap(&setRemoteCommand{})
+}
+
+// setRemoteCommand is the sub-command for configuring remote storage
+// for backups.
+type setRemoteCommand struct {
+	CommandBase
+	// Unset means any remote storage will be removed.
+	Unset bool
+	// Type is the type of the remote storage.
+	Type string
+	// Attrs holds the settings for the remote storage.
+	Attrs map[string]string
+}
+
+// Info implements Command.Info.
+func (c *setRemoteCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "set-remote",
+		Args:    "<type> [key=value ...]",
+		Purpose: "configure remote storage for backups",
+		Doc:     setRemoteDoc,
+	}
+}
+
+// SetFlags implements Command.SetFlags.
+func (c *setRemoteCommand) SetFlags(f *gnuflag.FlagSet) {
+	f.BoolVar(&c.Unset, "unset", false, "remove the remote storage")
+}
+
+// Init implements Command.Init.
+func (c *setRemoteCommand) Init(args []string) error {
+	if c.Unset {
+		return errors.Trace(cmd.CheckEmpty(args))
+	}
+	if len(args) == 0 {
+		return errors.New("missing remote storage type")
+	}
+	attrs, err := keyvalues.Parse(args[1:], false)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for key := range attrs {
+		if statebackups.IsSecretRemoteAttr(key) {
+			return errors.Errorf("%s must not be given on the command line; use %s-file or $%s", key, key, secretEnvVar(key))
+		}
+	}
+	c.Type = args[0]
+	c.Attrs = attrs
+	return nil
+}
+
+// secretEnvVar returns the environment variable from which the secret
+// remote storage setting is read if no file is given for it.
+func secretEnvVar(key string) string {
+	return "JUJU_BACKUPS_" + strings.ToUpper(strings.Replace(key, "-", "_", -1))
+}
+
+// readSecretAttrs replaces <setting>-file attributes naming secret
+// settings with the contents of the files, and adds secret settings
+// found in the environment.
+func readSecretAttrs(remoteType string, attrs map[string]string) error {
+	for key, path := range attrs {
+		secret := strings.TrimSuffix(key, "-file")
+		if secret == key || !statebackups.IsSecretRemoteAttr(secret) {
+			continue
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return errors.Annotatef(err, "cannot read %s", secret)
+		}
+		delete(attrs, key)
+		attrs[secret] = strings.TrimSpace(string(data))
+	}
+	if remoteType != "s3" {
+		return nil
+	}
+	if _, ok := attrs["secret-key"]; !ok {
+		if value := os.Getenv(secretEnvVar("secret-key")); value != "" {
+			attrs["secret-key"] = value
+		}
+	}
+	return nil
+}
+
+// Run implements Command.Run.
+func (c *setRemoteCommand) Run(ctx *cmd.Context) error {
+	client, err := c.NewAPIClient()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer client.Close()
+
+	if !c.Unset {
+		if err := readSecretAttrs(c.Type, c.Attrs); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return errors.Trace(client.SetRemote(c.Type, c.Attrs))
+}
+
+const showRemoteDoc = `
+"show-remote" shows the remote storage to which the state server copies
+backups, as configured with "set-remote". Secret keys are never sent
+by the state server, and are not shown.
+`
+
+func newShowRemoteCommand() cmd.Command {
+	return envcmd.Wrap(&showRemoteCommand{})
+}
+
+// showRemoteCommand is the sub-command for showing the remote storage
+// for backups.
+type showRemoteCommand struct {
+	CommandBase
+}
+
+// Info implements Command.Info.
+func (c *showRemoteCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "show-remote",
+		Args:    "",
+		Purpose: "show the remote storage for backups",
+		Doc:     showRemoteDoc,
+	}
+}
+
+// Init implements Command.Init.
+func (c *showRemoteCommand) Init(args []string) error {
+	return errors.Trace(cmd.CheckEmpty(args))
+}
+
+// Run implements Command.Run.
+func (c *showRemoteCommand) Run(ctx *cmd.Context) error {
+	client, err := c.NewAPIClient()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer client.Close()
+
+	remote, err := client.Remote()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if remote.Type == "" {
+		fmt.Fprintln(ctx.Stdout, "(no remote storage configured)")
+		return nil
+	}
+
+	fmt.Fprintf(ctx.Stdout, "type: %s\n", remote.Type)
+	var keys []string
+	for key := range remote.Attrs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := remote.Attrs[key]
+		if statebackups.IsSecretRemoteAttr(key) {
+			value = "<hidden>"
+		}
+		fmt.Fprintf(ctx.Stdout, "%s: %s\n", key, value)
+	}
+	return nil
+}
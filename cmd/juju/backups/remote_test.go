@@ -0,0 +1,130 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package backups_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/juju/backups"
+	"github.com/juju/juju/testing"
+)
+
+type remoteSuite struct {
+	BaseBackupsSuite
+	setCommand  cmd.Command
+	showCommand cmd.Command
+}
+
+var _ = gc.Suite(&remoteSuite{})
+
+func (s *remoteSuite) SetUpTest(c *gc.C) {
+	s.BaseBackupsSuite.SetUpTest(c)
+	s.setCommand = backups.NewSetRemoteCommand()
+	s.showCommand = backups.NewShowRemoteCommand()
+}
+
+func (s *remoteSuite) TestHelp(c *gc.C) {
+	s.checkHelp(c, s.setCommand)
+	s.checkHelp(c, s.showCommand)
+}
+
+func (s *remoteSuite) TestSetRemote(c *gc.C) {
+	client := s.setSuccess()
+	_, err := testing.RunCommand(c, s.setCommand, "local", "path=/mnt/backups")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(client.calls, jc.DeepEquals, []string{"SetRemote"})
+	c.Check(client.remote, jc.DeepEquals, params.BackupsRemoteConfig{
+		Type:  "local",
+		Attrs: map[string]string{"path": "/mnt/backups"},
+	})
+}
+
+func (s *remoteSuite) TestSetRemoteUnset(c *gc.C) {
+	client := s.setSuccess()
+	client.remote.Type = "local"
+	_, err := testing.RunCommand(c, s.setCommand, "--unset")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(client.calls, jc.DeepEquals, []string{"SetRemote"})
+	c.Check(client.remote.Type, gc.Equals, "")
+}
+
+func (s *remoteSuite) TestSetRemoteArgs(c *gc.C) {
+	_, err := testing.RunCommand(c, s.setCommand)
+	c.Check(err, gc.ErrorMatches, "missing remote storage type")
+
+	_, err = testing.RunCommand(c, s.setCommand, "local", "path")
+	c.Check(err, gc.ErrorMatches, `expected "key=value", got "path"`)
+
+	_, err = testing.RunCommand(c, s.setCommand, "--unset", "local")
+	c.Check(err, gc.ErrorMatches, `unrecognized args: \["local"\]`)
+}
+
+func (s *remoteSuite) TestSetRemoteSecretOnCommandLine(c *gc.C) {
+	_, err := testing.RunCommand(c, s.setCommand, "s3", "bucket=backups", "secret-key=sekrit")
+	c.Check(err, gc.ErrorMatches, `secret-key must not be given on the command line; use secret-key-file or \$JUJU_BACKUPS_SECRET_KEY`)
+}
+
+func (s *remoteSuite) TestSetRemoteSecretFromFile(c *gc.C) {
+	client := s.setSuccess()
+	path := filepath.Join(c.MkDir(), "secret")
+	err := ioutil.WriteFile(path, []byte("sekrit\n"), 0600)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = testing.RunCommand(c, s.setCommand, "s3", "bucket=backups", "secret-key-file="+path)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(client.remote.Attrs, jc.DeepEquals, map[string]string{
+		"bucket":     "backups",
+		"secret-key": "sekrit",
+	})
+}
+
+func (s *remoteSuite) TestSetRemoteSecretFromEnvironment(c *gc.C) {
+	client := s.setSuccess()
+	s.PatchEnvironment("JUJU_BACKUPS_SECRET_KEY", "sekrit")
+	_, err := testing.RunCommand(c, s.setCommand, "s3", "bucket=backups")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(client.remote.Attrs, jc.DeepEquals, map[string]string{
+		"bucket":     "backups",
+		"secret-key": "sekrit",
+	})
+}
+
+func (s *remoteSuite) TestSetRemoteError(c *gc.C) {
+	s.setFailure("failed!")
+	_, err := testing.RunCommand(c, s.setCommand, "local", "path=/mnt/backups")
+	c.Check(errors.Cause(err), gc.ErrorMatches, "failed!")
+}
+
+func (s *remoteSuite) TestShowRemote(c *gc.C) {
+	client := s.setSuccess()
+	client.remote = params.BackupsRemoteConfig{
+		Type: "s3",
+		Attrs: map[string]string{
+			"bucket":     "backups",
+			"access-key": "access",
+			"secret-key": "secret",
+		},
+	}
+	ctx, err := testing.RunCommand(c, s.showCommand)
+	c.Assert(err, jc.ErrorIsNil)
+	s.checkStd(c, ctx, `
+type: s3
+access-key: access
+bucket: backups
+secret-key: <hidden>
+`[1:], "")
+}
+
+func (s *remoteSuite) TestShowRemoteNone(c *gc.C) {
+	s.setSuccess()
+	ctx, err := testing.RunCommand(c, s.showCommand)
+	c.Assert(err, jc.ErrorIsNil)
+	s.checkStd(c, ctx, "(no remote storage configured)\n", "")
+}
@@ -34,6 +34,10 @@ type restoreCommand struct {
 	backupId    string
 	bootstrap   bool
 	uploadTools bool
+	fromRemote  bool
+
+	passphraseFile string
+	privateKeyFile string
 }
 
 var restoreDoc = `
@@ -52,6 +56,18 @@ If the provided state cannot be restored, this command will fail with
 an appropriate message.  For instance, if the existing bootstrap
 instance is already running then the command will fail with a message
 to that effect.
+
+If the backup is encrypted, the passphrase used to create it must be
+given with --passphrase-file, or the RSA private key matching the public
+key used to create it must be given with --private-key. Backups given
+with --file are decrypted locally before being uploaded. The private key
+never leaves the client, so a backup encrypted with a public key can
+only be restored with --file; use "juju backups download" to fetch it
+first.
+
+With --from-remote, the backup with the given --id is fetched from the
+remote backup storage configured with "juju backups set-remote", which
+allows restoring a backup that the state server no longer holds.
 `
 
 // Info returns the content for --help.
@@ -73,6 +89,9 @@ func (c *restoreCommand) SetFlags(f *gnuflag.FlagSet) {
 	f.StringVar(&c.filename, "file", "", "provide a file to be used as the backup.")
 	f.StringVar(&c.backupId, "id", "", "provide the name of the backup to be restored.")
 	f.BoolVar(&c.uploadTools, "upload-tools", false, "upload tools if bootstraping a new machine.")
+	f.BoolVar(&c.fromRemote, "from-remote", false, "fetch the backup with the given id from remote storage.")
+	f.StringVar(&c.passphraseFile, "passphrase-file", "", "decrypt the backup with the passphrase in this file.")
+	f.StringVar(&c.privateKeyFile, "private-key", "", "decrypt the backup with the RSA private key in this PEM file.")
 }
 
 // Init is where the preconditions for this commands can be checked.
@@ -86,6 +105,15 @@ func (c *restoreCommand) Init(args []string) error {
 	if c.backupId != "" && c.bootstrap {
 		return errors.Errorf("it is not possible to rebootstrap and restore from an id.")
 	}
+	if c.fromRemote && c.backupId == "" {
+		return errors.Errorf("it is only possible to restore from remote storage with a backup id.")
+	}
+	if c.passphraseFile != "" && c.privateKeyFile != "" {
+		return errors.Errorf("you must specify either a passphrase file or a private key but not both.")
+	}
+	if c.privateKeyFile != "" && c.backupId != "" {
+		return errors.Errorf("it is only possible to restore with a private key from a file.")
+	}
 	var err error
 	if c.filename != "" {
 		c.filename, err = filepath.Abs(c.filename)
@@ -111,16 +139,37 @@ func (c *restoreCommand) runRestore(ctx *cmd.Context) error {
 	var rErr error
 	if c.filename != "" {
 		target = c.filename
-		archive, meta, err := getArchive(c.filename)
+		filename := c.filename
+		key, err := readDecryptionKey(c.passphraseFile, c.privateKeyFile)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if key != nil {
+			decrypted, cleanup, err := decryptArchive(c.filename, *key)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			defer cleanup()
+			filename = decrypted
+		}
+		archive, meta, err := getArchive(filename)
 		if err != nil {
 			return errors.Trace(err)
 		}
 		defer archive.Close()
 
-		rErr = client.RestoreReader(archive, meta, c.newClient)
+		rErr = client.RestoreReader(archive, meta, params.BackupsEncryptionKey{}, c.newClient)
 	} else {
 		target = c.backupId
-		rErr = client.Restore(c.backupId, c.newClient)
+		key, err := readEncryptionKey(c.passphraseFile, "")
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if c.fromRemote {
+			rErr = client.RestoreFromRemote(c.backupId, key, c.newClient)
+		} else {
+			rErr = client.Restore(c.backupId, key, c.newClient)
+		}
 	}
 	if params.IsCodeNotImplemented(rErr) {
 		return errors.Errorf(restoreAPIIncompatibility)
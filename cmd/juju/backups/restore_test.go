@@ -4,10 +4,19 @@
 package backups_test
 
 import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
 	"github.com/juju/cmd"
+	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
 
 	"github.com/juju/juju/cmd/juju/backups"
+	statebackups "github.com/juju/juju/state/backups"
 	"github.com/juju/juju/testing"
 )
 
@@ -32,4 +41,49 @@ func (s *restoreSuite) TestRestoreArgs(c *gc.C) {
 
 	_, err = testing.RunCommand(c, s.command, "restore", "--id", "anid", "-b")
 	c.Assert(err, gc.ErrorMatches, "it is not possible to rebootstrap and restore from an id.")
+
+	_, err = testing.RunCommand(c, s.command, "restore", "--id", "anid", "--passphrase-file", "a", "--private-key", "b")
+	c.Assert(err, gc.ErrorMatches, "you must specify either a passphrase file or a private key but not both.")
+
+	_, err = testing.RunCommand(c, s.command, "restore", "--id", "anid", "--private-key", "b")
+	c.Assert(err, gc.ErrorMatches, "it is only possible to restore with a private key from a file.")
+
+	_, err = testing.RunCommand(c, s.command, "restore", "--file", "afile", "--from-remote")
+	c.Assert(err, gc.ErrorMatches, "it is only possible to restore from remote storage with a backup id.")
+}
+
+func (s *restoreSuite) TestDecryptArchive(c *gc.C) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	c.Assert(err, jc.ErrorIsNil)
+	var buf bytes.Buffer
+	w, err := statebackups.NewEncryptingWriter(&buf, statebackups.EncryptionKey{PublicKey: &privateKey.PublicKey})
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = w.Write([]byte("<archive>"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(w.Close(), jc.ErrorIsNil)
+	filename := filepath.Join(c.MkDir(), "backup.tar.gz")
+	err = ioutil.WriteFile(filename, buf.Bytes(), 0600)
+	c.Assert(err, jc.ErrorIsNil)
+
+	decrypted, cleanup, err := backups.DecryptArchive(filename, statebackups.EncryptionKey{PrivateKey: privateKey})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(decrypted, gc.Not(gc.Equals), filename)
+	data, err := ioutil.ReadFile(decrypted)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(string(data), gc.Equals, "<archive>")
+
+	cleanup()
+	_, err = ioutil.ReadFile(decrypted)
+	c.Check(err, jc.Satisfies, os.IsNotExist)
+}
+
+func (s *restoreSuite) TestDecryptArchiveUnencrypted(c *gc.C) {
+	filename := filepath.Join(c.MkDir(), "backup.tar.gz")
+	err := ioutil.WriteFile(filename, []byte("<archive>"), 0600)
+	c.Assert(err, jc.ErrorIsNil)
+
+	decrypted, cleanup, err := backups.DecryptArchive(filename, statebackups.EncryptionKey{Passphrase: "sekrit"})
+	c.Assert(err, jc.ErrorIsNil)
+	defer cleanup()
+	c.Check(decrypted, gc.Equals, filename)
 }
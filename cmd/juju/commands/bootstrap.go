@@ -5,7 +5,9 @@ package commands
 
 import (
 	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -24,6 +26,8 @@ import (
 	"github.com/juju/juju/environs"
 	"github.com/juju/juju/environs/bootstrap"
 	"github.com/juju/juju/environs/configstore"
+	"github.com/juju/juju/environs/offline"
+	"github.com/juju/juju/environs/sync"
 	"github.com/juju/juju/feature"
 	"github.com/juju/juju/instance"
 	"github.com/juju/juju/juju"
@@ -75,6 +79,15 @@ available. In this case, use the --metadata-source parameter to point
 bootstrap to a local directory from which to upload tools and/or image
 metadata.
 
+Environments without any outgoing Internet access can instead be bootstrapped
+from an offline bundle created with "juju create-offline-bundle", using the
+--offline-bundle parameter. The tools and image metadata in the bundle are used
+to bootstrap the environment, and once it is running the tools, charms and charm
+bundles in the bundle are uploaded to it. Charm bundles are kept in
+$JUJU_HOME/offline-bundles/<environment name>, ready to be deployed. If the
+bundle is signed, the public key to verify it with must be held in the file
+named by $JUJU_STREAMS_PUBLICKEY_FILE.
+
 If agent-version is specifed, this is the default tools version to use when running the Juju agents.
 Only the numeric version is relevant. To enable ease of scripting, the full binary version
 is accepted (eg 1.24.4-trusty-amd64) but only the numeric version (eg 1.24.4) is used.
@@ -86,6 +99,7 @@ See Also:
    juju help constraints
    juju help set-constraints
    juju help placement
+   juju help create-offline-bundle
 `
 
 func newBootstrapCommand() cmd.Command {
@@ -101,6 +115,7 @@ type bootstrapCommand struct {
 	Series                []string
 	seriesOld             []string
 	MetadataSource        string
+	OfflineBundle         string
 	Placement             string
 	KeepBrokenEnvironment bool
 	NoAutoUpgrade         bool
@@ -122,6 +137,7 @@ func (c *bootstrapCommand) SetFlags(f *gnuflag.FlagSet) {
 	f.Var(newSeriesValue(nil, &c.Series), "upload-series", "upload tools for supplied comma-separated series list (OBSOLETE)")
 	f.Var(newSeriesValue(nil, &c.seriesOld), "series", "see --upload-series (OBSOLETE)")
 	f.StringVar(&c.MetadataSource, "metadata-source", "", "local path to use as tools and/or metadata source")
+	f.StringVar(&c.OfflineBundle, "offline-bundle", "", "local offline bundle to bootstrap from and upload")
 	f.StringVar(&c.Placement, "to", "", "a placement directive indicating an instance to bootstrap")
 	f.BoolVar(&c.KeepBrokenEnvironment, "keep-broken", false, "do not destroy the environment if bootstrap fails")
 	f.BoolVar(&c.NoAutoUpgrade, "no-auto-upgrade", false, "do not upgrade to newer tools on first bootstrap")
@@ -144,6 +160,12 @@ func (c *bootstrapCommand) Init(args []string) (err error) {
 	if c.AgentVersionParam != "" && c.NoAutoUpgrade {
 		return fmt.Errorf("--agent-version and --no-auto-upgrade can't be used together")
 	}
+	if c.OfflineBundle != "" && c.MetadataSource != "" {
+		return fmt.Errorf("--offline-bundle and --metadata-source can't be used together")
+	}
+	if c.OfflineBundle != "" && c.UploadTools {
+		return fmt.Errorf("--offline-bundle and --upload-tools can't be used together")
+	}
 
 	// Parse the placement directive. Bootstrap currently only
 	// supports provider-specific placement directives.
@@ -300,6 +322,17 @@ func (c *bootstrapCommand) Run(ctx *cmd.Context) (resultErr error) {
 		metadataDir = ctx.AbsPath(c.MetadataSource)
 	}
 
+	// If --offline-bundle is specified, extract it and use it as the
+	// tools and image metadata source.
+	var manifest *offline.Manifest
+	if c.OfflineBundle != "" {
+		metadataDir, manifest, err = extractOfflineBundle(ctx.AbsPath(c.OfflineBundle), environ.Config().AgentStream())
+		if err != nil {
+			return errors.Annotate(err, "cannot use offline bundle")
+		}
+		defer os.RemoveAll(metadataDir)
+	}
+
 	// TODO (wallyworld): 2013-09-20 bug 1227931
 	// We can set a custom tools data source instead of doing an
 	// unnecessary upload.
@@ -330,7 +363,152 @@ func (c *bootstrapCommand) Run(ctx *cmd.Context) (resultErr error) {
 	// To avoid race conditions when running scripted bootstraps, wait
 	// for the state server's machine agent to be ready to accept commands
 	// before exiting this bootstrap command.
-	return c.waitForAgentInitialisation(ctx)
+	if err := c.waitForAgentInitialisation(ctx); err != nil {
+		return err
+	}
+	if manifest == nil {
+		return nil
+	}
+
+	// The environment is now running, so failing to upload the
+	// contents of the offline bundle must not destroy it.
+	cleanup = nil
+	err = uploadOfflineBundle(c, ctx, envName, metadataDir, manifest)
+	return errors.Annotate(err, "cannot upload offline bundle contents")
+}
+
+// extractOfflineBundle extracts the offline bundle at the given path
+// to a new temporary directory, which the caller must remove, and
+// checks that it is usable with the given tools stream. If a public
+// key is configured for simplestreams, the bundle's metadata must be
+// signed with it.
+func extractOfflineBundle(path, stream string) (_ string, _ *offline.Manifest, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", nil, errors.Trace(err)
+	}
+	defer f.Close()
+	dir, err := ioutil.TempDir("", "juju-offline-bundle")
+	if err != nil {
+		return "", nil, errors.Trace(err)
+	}
+	defer func() {
+		if err != nil {
+			os.RemoveAll(dir)
+		}
+	}()
+	manifest, err := offline.Extract(f, dir)
+	if err != nil {
+		return "", nil, errors.Trace(err)
+	}
+	if manifest.Stream != stream {
+		return "", nil, errors.Errorf("bundle holds tools for stream %q, environment uses %q", manifest.Stream, stream)
+	}
+	publicKey, err := streamsPublicKey()
+	if err != nil {
+		return "", nil, errors.Trace(err)
+	}
+	switch {
+	case publicKey != "":
+		// The manifest comes from the bundle itself, so whether the
+		// bundle claims to be signed counts for nothing once a key
+		// has been given.
+		if err := offline.VerifySignatures(dir, publicKey); err != nil {
+			return "", nil, errors.Trace(err)
+		}
+	case manifest.Signed:
+		return "", nil, errors.New("bundle is signed but JUJU_STREAMS_PUBLICKEY_FILE is not set")
+	}
+	return dir, manifest, nil
+}
+
+// streamsPublicKey returns the contents of the file named by
+// $JUJU_STREAMS_PUBLICKEY_FILE, or "" if it is not set.
+func streamsPublicKey() (string, error) {
+	keyFile := os.Getenv("JUJU_STREAMS_PUBLICKEY_FILE")
+	if keyFile == "" {
+		return "", nil
+	}
+	path, err := utils.NormalizePath(keyFile)
+	if err != nil {
+		return "", errors.Annotatef(err, "cannot expand key file path: %s", keyFile)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", errors.Annotatef(err, "invalid public key file: %s", path)
+	}
+	return string(data), nil
+}
+
+// offlineUploadAPI provides the subset of the api.Client API used to
+// upload the contents of an offline bundle. This exists to enable
+// mocking.
+type offlineUploadAPI interface {
+	syncToolsAPI
+	AddLocalCharm(curl *charm.URL, ch charm.Charm) (*charm.URL, error)
+}
+
+var getOfflineUploadAPI = func(c *bootstrapCommand) (offlineUploadAPI, error) {
+	return c.NewAPIClient()
+}
+
+// uploadOfflineBundle uploads the tools and charms of the offline
+// bundle extracted to dir to the newly bootstrapped environment, and
+// keeps its charm bundles in the juju home directory.
+var uploadOfflineBundle = func(c *bootstrapCommand, ctx *cmd.Context, envName, dir string, manifest *offline.Manifest) error {
+	client, err := getOfflineUploadAPI(c)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer client.Close()
+
+	ctx.Infof("Uploading tools from offline bundle")
+	adapter := syncToolsAPIAdapter{client}
+	err = syncTools(&sync.SyncContext{
+		Source:              dir,
+		Stream:              manifest.Stream,
+		AllVersions:         true,
+		TargetToolsFinder:   adapter,
+		TargetToolsUploader: adapter,
+	})
+	if err != nil {
+		return errors.Annotate(err, "cannot upload tools")
+	}
+
+	for _, s := range manifest.Charms {
+		curl, err := charm.ParseURL(s)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		ch, err := charm.ReadCharmArchive(offline.CharmPath(dir, curl))
+		if err != nil {
+			return errors.Annotatef(err, "cannot read charm %q", curl)
+		}
+		if _, err := client.AddLocalCharm(curl, ch); err != nil {
+			return errors.Annotatef(err, "cannot upload charm %q", curl)
+		}
+		ctx.Infof("Uploaded charm %s", curl)
+	}
+
+	if len(manifest.Bundles) == 0 {
+		return nil
+	}
+	bundlesDir := osenv.JujuHomePath("offline-bundles", envName)
+	if err := os.MkdirAll(bundlesDir, 0755); err != nil {
+		return errors.Trace(err)
+	}
+	for _, name := range manifest.Bundles {
+		data, err := ioutil.ReadFile(offline.BundlePath(dir, name))
+		if err != nil {
+			return errors.Trace(err)
+		}
+		path := filepath.Join(bundlesDir, name)
+		if err := ioutil.WriteFile(path, data, 0644); err != nil {
+			return errors.Trace(err)
+		}
+		ctx.Infof("Bundle %s is ready to deploy with: juju deploy %s", name, path)
+	}
+	return nil
 }
 
 var (
@@ -5,7 +5,9 @@ package commands
 
 import (
 	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"runtime"
 	"strings"
 	"time"
@@ -19,6 +21,7 @@ import (
 	jujuos "github.com/juju/utils/os"
 	"github.com/juju/utils/series"
 	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/charm.v6-unstable"
 
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/cmd/envcmd"
@@ -31,7 +34,9 @@ import (
 	"github.com/juju/juju/environs/configstore"
 	"github.com/juju/juju/environs/filestorage"
 	"github.com/juju/juju/environs/imagemetadata"
+	"github.com/juju/juju/environs/offline"
 	"github.com/juju/juju/environs/simplestreams"
+	sstesting "github.com/juju/juju/environs/simplestreams/testing"
 	"github.com/juju/juju/environs/sync"
 	envtesting "github.com/juju/juju/environs/testing"
 	envtools "github.com/juju/juju/environs/tools"
@@ -41,6 +46,7 @@ import (
 	"github.com/juju/juju/juju/osenv"
 	"github.com/juju/juju/network"
 	"github.com/juju/juju/provider/dummy"
+	"github.com/juju/juju/testcharms"
 	coretesting "github.com/juju/juju/testing"
 	coretools "github.com/juju/juju/tools"
 	"github.com/juju/juju/version"
@@ -360,6 +366,14 @@ var bootstrapTests = []bootstrapTest{{
 	info: "--agent-version with --no-auto-upgrade",
 	args: []string{"--agent-version", "1.1.0", "--no-auto-upgrade"},
 	err:  `--agent-version and --no-auto-upgrade can't be used together`,
+}, {
+	info: "--offline-bundle with --metadata-source",
+	args: []string{"--offline-bundle", "offline.tar.gz", "--metadata-source", "/foo"},
+	err:  `--offline-bundle and --metadata-source can't be used together`,
+}, {
+	info: "--offline-bundle with --upload-tools",
+	args: []string{"--offline-bundle", "offline.tar.gz", "--upload-tools"},
+	err:  `--offline-bundle and --upload-tools can't be used together`,
 }, {
 	info: "invalid --agent-version value",
 	args: []string{"--agent-version", "foo"},
@@ -730,6 +744,148 @@ func (s *BootstrapSuite) TestAutoSyncLocalSource(c *gc.C) {
 	checkTools(c, env, v120All)
 }
 
+// writeOfflineBundle writes an offline bundle created with the given
+// parameters, with tools from a local source, and returns its path.
+func writeOfflineBundle(c *gc.C, args offline.CreateParams) string {
+	args.ToolsSource = createToolsSource(c, vAll)
+	path := filepath.Join(c.MkDir(), "offline.tar.gz")
+	f, err := os.Create(path)
+	c.Assert(err, jc.ErrorIsNil)
+	defer f.Close()
+	_, err = offline.Create(f, args)
+	c.Assert(err, jc.ErrorIsNil)
+	return path
+}
+
+func (s *BootstrapSuite) TestBootstrapWithOfflineBundle(c *gc.C) {
+	s.PatchValue(&version.Current, version.MustParse("1.2.0"))
+	bundlePath := writeOfflineBundle(c, offline.CreateParams{})
+	env := resetJujuHome(c, "peckham")
+
+	var uploadDir string
+	var uploaded *offline.Manifest
+	s.PatchValue(&uploadOfflineBundle, func(_ *bootstrapCommand, _ *cmd.Context, envName, dir string, manifest *offline.Manifest) error {
+		c.Check(envName, gc.Equals, "peckham")
+		_, err := os.Stat(filepath.Join(dir, offline.ManifestFile))
+		c.Check(err, jc.ErrorIsNil)
+		uploadDir, uploaded = dir, manifest
+		return nil
+	})
+	_, err := coretesting.RunCommand(c, newBootstrapCommand(), "--offline-bundle", bundlePath)
+	c.Assert(err, jc.ErrorIsNil)
+
+	// The environment was bootstrapped with the tools in the bundle,
+	// which was then uploaded and removed.
+	checkTools(c, env, v120All)
+	c.Assert(uploaded, gc.NotNil)
+	c.Assert(uploaded.Tools, gc.HasLen, len(v120All))
+	_, err = os.Stat(uploadDir)
+	c.Assert(err, jc.Satisfies, os.IsNotExist)
+}
+
+func (s *BootstrapSuite) TestBootstrapOfflineBundleUploadFailureKeepsEnvironment(c *gc.C) {
+	s.PatchValue(&version.Current, version.MustParse("1.2.0"))
+	bundlePath := writeOfflineBundle(c, offline.CreateParams{})
+	resetJujuHome(c, "peckham")
+
+	s.PatchValue(&uploadOfflineBundle, func(*bootstrapCommand, *cmd.Context, string, string, *offline.Manifest) error {
+		return errors.New("boom")
+	})
+	_, err := coretesting.RunCommand(c, newBootstrapCommand(), "--offline-bundle", bundlePath)
+	c.Assert(err, gc.ErrorMatches, "cannot upload offline bundle contents: boom")
+
+	// The environment was not destroyed.
+	_, err = coretesting.RunCommand(c, newBootstrapCommand())
+	c.Assert(err, gc.Equals, environs.ErrAlreadyBootstrapped)
+}
+
+func (s *BootstrapSuite) TestExtractOfflineBundleStreamMismatch(c *gc.C) {
+	s.PatchValue(&version.Current, version.MustParse("1.2.0"))
+	bundlePath := writeOfflineBundle(c, offline.CreateParams{})
+	_, _, err := extractOfflineBundle(bundlePath, "devel")
+	c.Assert(err, gc.ErrorMatches, `bundle holds tools for stream "released", environment uses "devel"`)
+}
+
+func (s *BootstrapSuite) TestExtractSignedOfflineBundle(c *gc.C) {
+	s.PatchValue(&version.Current, version.MustParse("1.2.0"))
+	bundlePath := writeOfflineBundle(c, offline.CreateParams{
+		SigningKey: sstesting.SignedMetadataPrivateKey,
+		Passphrase: sstesting.PrivateKeyPassphrase,
+	})
+
+	s.PatchEnvironment("JUJU_STREAMS_PUBLICKEY_FILE", "")
+	_, _, err := extractOfflineBundle(bundlePath, "released")
+	c.Assert(err, gc.ErrorMatches, "bundle is signed but JUJU_STREAMS_PUBLICKEY_FILE is not set")
+
+	keyFile := filepath.Join(c.MkDir(), "public.key")
+	err = ioutil.WriteFile(keyFile, []byte(simplestreams.SimplestreamsJujuPublicKey), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+	s.PatchEnvironment("JUJU_STREAMS_PUBLICKEY_FILE", keyFile)
+	_, _, err = extractOfflineBundle(bundlePath, "released")
+	c.Assert(err, gc.ErrorMatches, "cannot verify .*")
+
+	err = ioutil.WriteFile(keyFile, []byte(sstesting.SignedMetadataPublicKey), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+	dir, manifest, err := extractOfflineBundle(bundlePath, "released")
+	c.Assert(err, jc.ErrorIsNil)
+	defer os.RemoveAll(dir)
+	c.Assert(manifest.Signed, jc.IsTrue)
+}
+
+func (s *BootstrapSuite) TestExtractUnsignedOfflineBundleWithPublicKey(c *gc.C) {
+	s.PatchValue(&version.Current, version.MustParse("1.2.0"))
+	bundlePath := writeOfflineBundle(c, offline.CreateParams{})
+	keyFile := filepath.Join(c.MkDir(), "public.key")
+	err := ioutil.WriteFile(keyFile, []byte(sstesting.SignedMetadataPublicKey), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+	s.PatchEnvironment("JUJU_STREAMS_PUBLICKEY_FILE", keyFile)
+	_, _, err = extractOfflineBundle(bundlePath, "released")
+	c.Assert(err, gc.ErrorMatches, "cannot read signed metadata for .*")
+}
+
+type fakeOfflineUploadAPI struct {
+	fakeSyncToolsAPI
+	charms []*charm.URL
+}
+
+func (api *fakeOfflineUploadAPI) AddLocalCharm(curl *charm.URL, ch charm.Charm) (*charm.URL, error) {
+	api.charms = append(api.charms, curl)
+	return curl, nil
+}
+
+func (s *BootstrapSuite) TestUploadOfflineBundle(c *gc.C) {
+	s.PatchValue(&version.Current, version.MustParse("1.2.0"))
+	bundlePath := writeOfflineBundle(c, offline.CreateParams{
+		Charms:  []string{testcharms.Repo.CharmDirPath("dummy")},
+		Bundles: []string{filepath.Join(testcharms.Repo.Path(), "bundle", "wordpress-simple", "bundle.yaml")},
+	})
+	dir, manifest, err := extractOfflineBundle(bundlePath, "released")
+	c.Assert(err, jc.ErrorIsNil)
+	defer os.RemoveAll(dir)
+
+	api := &fakeOfflineUploadAPI{}
+	s.PatchValue(&getOfflineUploadAPI, func(*bootstrapCommand) (offlineUploadAPI, error) {
+		return api, nil
+	})
+	var sctx *sync.SyncContext
+	s.PatchValue(&syncTools, func(arg *sync.SyncContext) error {
+		sctx = arg
+		return nil
+	})
+
+	ctx := coretesting.Context(c)
+	err = uploadOfflineBundle(&bootstrapCommand{}, ctx, "peckham", dir, manifest)
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Assert(sctx, gc.NotNil)
+	c.Assert(sctx.Source, gc.Equals, dir)
+	c.Assert(sctx.Stream, gc.Equals, "released")
+	c.Assert(sctx.AllVersions, jc.IsTrue)
+	c.Assert(api.charms, jc.DeepEquals, []*charm.URL{charm.MustParseURL("local:quantal/dummy-1")})
+	_, err = os.Stat(osenv.JujuHomePath("offline-bundles", "peckham", "bundle.yaml"))
+	c.Assert(err, jc.ErrorIsNil)
+}
+
 func (s *BootstrapSuite) setupAutoUploadTest(c *gc.C, vers, ser string) environs.Environ {
 	s.PatchValue(&envtools.BundleTools, toolstesting.GetMockBundleTools(c))
 	sourceDir := createToolsSource(c, vAll)
@@ -0,0 +1,251 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/cmd/juju/block"
+)
+
+const showCertificatesDoc = `
+show-certificates shows when the controller's CA certificate and the
+server certificate of each state server expire. While a new CA is being
+rotated in, it also shows the new CA's expiry and the time at which
+state servers switch to certificates signed by it.
+
+The CA certificates the controller currently trusts are saved to the
+local environment information, so this command can be used to pick up
+a new CA after another user has started rotating one.
+
+See Also:
+    juju help rotate-certificates
+`
+
+const rotateCertificatesDoc = `
+rotate-certificates asks every state server to replace its server
+certificate. Only controller administrators may rotate certificates.
+
+With --new-ca, a new CA certificate is also generated. Agents and clients
+trust both the current and new CAs straight away, and state servers
+switch to server certificates signed by the new CA once the overlap
+period (7 days by default) has passed. Agents pick up the new CA
+automatically; other clients should run "juju show-certificates" during
+the overlap to save it.
+
+Examples:
+
+    juju rotate-certificates
+    juju rotate-certificates --new-ca --overlap 72h
+
+See Also:
+    juju help show-certificates
+`
+
+// certificatesAPI defines the API methods used by the certificate
+// commands.
+type certificatesAPI interface {
+	ControllerCertificates() (params.ControllerCertificatesInfo, error)
+	RotateCertificates(newCA bool, overlap time.Duration) (params.ControllerCertificatesInfo, error)
+	Close() error
+}
+
+var newCertificatesAPI = func(c *envcmd.EnvCommandBase) (certificatesAPI, error) {
+	return c.NewAPIClient()
+}
+
+// cacheCACert saves the given CA certificates in the connection
+// information for the environment, if they have changed.
+var cacheCACert = func(c *envcmd.EnvCommandBase, caCert string) error {
+	if c.ConnectionName() == "" {
+		return nil
+	}
+	info, err := envcmd.ConnectionInfoForName(c.ConnectionName())
+	if err != nil {
+		return errors.Trace(err)
+	}
+	endpoint := info.APIEndpoint()
+	if endpoint.CACert == caCert {
+		return nil
+	}
+	endpoint.CACert = caCert
+	info.SetAPIEndpoint(endpoint)
+	return errors.Trace(info.Write())
+}
+
+// formattedCertificates is the output format of the certificate
+// commands.
+type formattedCertificates struct {
+	CAExpiry           string            `yaml:"ca-expiry" json:"ca-expiry"`
+	NewCAExpiry        string            `yaml:"new-ca-expiry,omitempty" json:"new-ca-expiry,omitempty"`
+	SwitchTime         string            `yaml:"switch-time,omitempty" json:"switch-time,omitempty"`
+	ServerCertExpiries map[string]string `yaml:"server-certificates,omitempty" json:"server-certificates,omitempty"`
+}
+
+func formatCertificates(info params.ControllerCertificatesInfo) formattedCertificates {
+	formatTime := func(t time.Time) string {
+		return t.UTC().Format(time.RFC3339)
+	}
+	result := formattedCertificates{
+		CAExpiry: formatTime(info.CAExpiry),
+	}
+	if info.NewCAExpiry != nil {
+		result.NewCAExpiry = formatTime(*info.NewCAExpiry)
+	}
+	if info.SwitchTime != nil {
+		result.SwitchTime = formatTime(*info.SwitchTime)
+	}
+	if len(info.ServerCertExpiries) > 0 {
+		result.ServerCertExpiries = make(map[string]string)
+		for id, expiry := range info.ServerCertExpiries {
+			result.ServerCertExpiries[id] = formatTime(expiry)
+		}
+	}
+	return result
+}
+
+// formatCertificatesTabular returns a tabular summary of the
+// controller's certificates.
+func formatCertificatesTabular(value interface{}) ([]byte, error) {
+	certs, ok := value.(formattedCertificates)
+	if !ok {
+		return nil, errors.Errorf("expected value of type %T, got %T", certs, value)
+	}
+	var out bytes.Buffer
+	tw := tabwriter.NewWriter(&out, 0, 1, 1, ' ', 0)
+	fmt.Fprintln(tw, "CERTIFICATE\tEXPIRES")
+	fmt.Fprintf(tw, "ca\t%s\n", certs.CAExpiry)
+	if certs.NewCAExpiry != "" {
+		fmt.Fprintf(tw, "new ca\t%s\n", certs.NewCAExpiry)
+	}
+	ids := make([]string, 0, len(certs.ServerCertExpiries))
+	for id := range certs.ServerCertExpiries {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		fmt.Fprintf(tw, "machine %s\t%s\n", id, certs.ServerCertExpiries[id])
+	}
+	tw.Flush()
+	if certs.SwitchTime != "" {
+		fmt.Fprintf(&out, "\nstate servers switch to the new ca at %s\n", certs.SwitchTime)
+	}
+	return out.Bytes(), nil
+}
+
+func addCertificatesOutputFlags(out *cmd.Output, f *gnuflag.FlagSet) {
+	out.AddFlags(f, "tabular", map[string]cmd.Formatter{
+		"yaml":    cmd.FormatYaml,
+		"json":    cmd.FormatJson,
+		"tabular": formatCertificatesTabular,
+	})
+}
+
+func newShowCertificatesCommand() cmd.Command {
+	return envcmd.Wrap(&showCertificatesCommand{})
+}
+
+// showCertificatesCommand shows the expiry of the controller's
+// certificates.
+type showCertificatesCommand struct {
+	envcmd.EnvCommandBase
+	out cmd.Output
+}
+
+func (c *showCertificatesCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "show-certificates",
+		Purpose: "show when the controller's certificates expire",
+		Doc:     showCertificatesDoc,
+	}
+}
+
+func (c *showCertificatesCommand) SetFlags(f *gnuflag.FlagSet) {
+	addCertificatesOutputFlags(&c.out, f)
+}
+
+func (c *showCertificatesCommand) Init(args []string) error {
+	return cmd.CheckEmpty(args)
+}
+
+func (c *showCertificatesCommand) Run(ctx *cmd.Context) error {
+	apiclient, err := newCertificatesAPI(&c.EnvCommandBase)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer apiclient.Close()
+
+	info, err := apiclient.ControllerCertificates()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if err := cacheCACert(&c.EnvCommandBase, info.CACert); err != nil {
+		return errors.Annotate(err, "cannot save CA certificates")
+	}
+	return c.out.Write(ctx, formatCertificates(info))
+}
+
+func newRotateCertificatesCommand() cmd.Command {
+	return envcmd.Wrap(&rotateCertificatesCommand{})
+}
+
+// rotateCertificatesCommand replaces the controller's server
+// certificates, and optionally its CA.
+type rotateCertificatesCommand struct {
+	envcmd.EnvCommandBase
+	out     cmd.Output
+	NewCA   bool
+	Overlap time.Duration
+}
+
+func (c *rotateCertificatesCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "rotate-certificates",
+		Purpose: "replace the controller's server certificates and optionally its CA",
+		Doc:     rotateCertificatesDoc,
+	}
+}
+
+func (c *rotateCertificatesCommand) SetFlags(f *gnuflag.FlagSet) {
+	addCertificatesOutputFlags(&c.out, f)
+	f.BoolVar(&c.NewCA, "new-ca", false, "generate a new CA certificate")
+	f.DurationVar(&c.Overlap, "overlap", 0, "how long to trust both the current and new CAs")
+}
+
+func (c *rotateCertificatesCommand) Init(args []string) error {
+	if c.Overlap < 0 {
+		return errors.Errorf("invalid overlap %v", c.Overlap)
+	}
+	if c.Overlap != 0 && !c.NewCA {
+		return errors.New("--overlap requires --new-ca")
+	}
+	return cmd.CheckEmpty(args)
+}
+
+func (c *rotateCertificatesCommand) Run(ctx *cmd.Context) error {
+	apiclient, err := newCertificatesAPI(&c.EnvCommandBase)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer apiclient.Close()
+
+	info, err := apiclient.RotateCertificates(c.NewCA, c.Overlap)
+	if err != nil {
+		return block.ProcessBlockedError(err, block.BlockChange)
+	}
+	if err := cacheCACert(&c.EnvCommandBase, info.CACert); err != nil {
+		return errors.Annotate(err, "cannot save CA certificates")
+	}
+	return c.out.Write(ctx, formatCertificates(info))
+}
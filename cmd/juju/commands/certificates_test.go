@@ -0,0 +1,142 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package commands
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/testing"
+)
+
+type CertificatesSuite struct {
+	testing.FakeJujuHomeSuite
+	api    *fakeCertificatesAPI
+	cached string
+}
+
+var _ = gc.Suite(&CertificatesSuite{})
+
+type fakeCertificatesAPI struct {
+	info    params.ControllerCertificatesInfo
+	err     error
+	newCA   bool
+	overlap time.Duration
+}
+
+func (f *fakeCertificatesAPI) ControllerCertificates() (params.ControllerCertificatesInfo, error) {
+	return f.info, f.err
+}
+
+func (f *fakeCertificatesAPI) RotateCertificates(newCA bool, overlap time.Duration) (params.ControllerCertificatesInfo, error) {
+	f.newCA = newCA
+	f.overlap = overlap
+	return f.info, f.err
+}
+
+func (f *fakeCertificatesAPI) Close() error {
+	return nil
+}
+
+func (s *CertificatesSuite) SetUpTest(c *gc.C) {
+	s.FakeJujuHomeSuite.SetUpTest(c)
+	newCAExpiry := time.Date(2025, 11, 3, 0, 0, 0, 0, time.UTC)
+	switchTime := time.Date(2015, 11, 10, 0, 0, 0, 0, time.UTC)
+	s.api = &fakeCertificatesAPI{
+		info: params.ControllerCertificatesInfo{
+			CACert:      "ca-certs",
+			CAExpiry:    time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC),
+			NewCAExpiry: &newCAExpiry,
+			SwitchTime:  &switchTime,
+			ServerCertExpiries: map[string]time.Time{
+				"0": time.Date(2025, 11, 3, 10, 30, 0, 0, time.UTC),
+				"1": time.Date(2025, 11, 4, 10, 30, 0, 0, time.UTC),
+			},
+		},
+	}
+	s.cached = ""
+	s.PatchValue(&newCertificatesAPI, func(_ *envcmd.EnvCommandBase) (certificatesAPI, error) {
+		return s.api, nil
+	})
+	s.PatchValue(&cacheCACert, func(_ *envcmd.EnvCommandBase, caCert string) error {
+		s.cached = caCert
+		return nil
+	})
+}
+
+func (s *CertificatesSuite) TestShowTabular(c *gc.C) {
+	ctx, err := testing.RunCommand(c, newShowCertificatesCommand())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(s.cached, gc.Equals, "ca-certs")
+	c.Check(testing.Stdout(ctx), gc.Equals, `
+CERTIFICATE EXPIRES
+ca          2016-01-01T00:00:00Z
+new ca      2025-11-03T00:00:00Z
+machine 0   2025-11-03T10:30:00Z
+machine 1   2025-11-04T10:30:00Z
+
+state servers switch to the new ca at 2015-11-10T00:00:00Z
+`[1:])
+}
+
+func (s *CertificatesSuite) TestShowYAML(c *gc.C) {
+	s.api.info.NewCAExpiry = nil
+	s.api.info.SwitchTime = nil
+	ctx, err := testing.RunCommand(c, newShowCertificatesCommand(), "--format", "yaml")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(testing.Stdout(ctx), gc.Equals, `
+ca-expiry: "2016-01-01T00:00:00Z"
+server-certificates:
+  "0": "2025-11-03T10:30:00Z"
+  "1": "2025-11-04T10:30:00Z"
+`[1:])
+}
+
+func (s *CertificatesSuite) TestRotateInitErrors(c *gc.C) {
+	for i, test := range []struct {
+		args []string
+		err  string
+	}{{
+		args: []string{"foo"},
+		err:  `unrecognized args: \["foo"\]`,
+	}, {
+		args: []string{"--new-ca", "--overlap", "-1h"},
+		err:  "invalid overlap -1h0m0s",
+	}, {
+		args: []string{"--overlap", "1h"},
+		err:  "--overlap requires --new-ca",
+	}} {
+		c.Logf("test %d: %v", i, test.args)
+		err := testing.InitCommand(newRotateCertificatesCommand(), test.args)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
+
+func (s *CertificatesSuite) TestRotate(c *gc.C) {
+	_, err := testing.RunCommand(c, newRotateCertificatesCommand())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(s.api.newCA, jc.IsFalse)
+	c.Check(s.api.overlap, gc.Equals, time.Duration(0))
+	c.Check(s.cached, gc.Equals, "ca-certs")
+}
+
+func (s *CertificatesSuite) TestRotateNewCA(c *gc.C) {
+	_, err := testing.RunCommand(c, newRotateCertificatesCommand(), "--new-ca", "--overlap", "72h")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(s.api.newCA, jc.IsTrue)
+	c.Check(s.api.overlap, gc.Equals, 72*time.Hour)
+	c.Check(s.cached, gc.Equals, "ca-certs")
+}
+
+func (s *CertificatesSuite) TestRotateError(c *gc.C) {
+	s.api.err = errors.New("boom")
+	_, err := testing.RunCommand(c, newRotateCertificatesCommand())
+	c.Assert(err, gc.ErrorMatches, "boom")
+	c.Check(s.cached, gc.Equals, "")
+}
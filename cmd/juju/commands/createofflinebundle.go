@@ -0,0 +1,193 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package commands
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/environs/offline"
+	"github.com/juju/juju/version"
+)
+
+const createOfflineBundleDoc = `
+Create an offline bundle: a single archive holding everything needed to
+bootstrap an environment without access to the Internet. The bundle
+holds the juju tools tarballs with their simplestreams metadata, and
+optionally image metadata, local charms and charm bundles.
+
+Tools are copied from the official tools store, or from --source, which
+may be a local directory as for "juju sync-tools". Image metadata is
+copied from the "images" directory of --image-metadata-dir, as written by
+"juju metadata generate-image -d". Charms must be in a local repository
+unless their metadata names a series.
+
+If --signing-key is given, the simplestreams metadata is signed with the
+armored private key held in that file. Bootstrapping from a signed
+bundle requires the matching public key to be held in the file named by
+$JUJU_STREAMS_PUBLICKEY_FILE.
+
+The bundle is used with "juju bootstrap --offline-bundle", which serves
+the tools and image metadata locally during bootstrap and then uploads
+the tools and charms to the new environment.
+
+Examples:
+    juju create-offline-bundle --source /srv/tools offline.tar.gz
+
+    juju create-offline-bundle --version 1.25 \
+        --image-metadata-dir ~/metadata \
+        --charms ~/charms/trusty/mysql,~/charms/trusty/wordpress \
+        --signing-key ~/lab-signing.key offline.tar.gz
+
+See Also:
+    juju help bootstrap
+    juju help sync-tools
+    juju help metadata
+`
+
+func newCreateOfflineBundleCommand() cmd.Command {
+	return &createOfflineBundleCommand{}
+}
+
+// createOfflineBundleCommand writes an offline bundle for
+// bootstrapping without Internet access.
+type createOfflineBundleCommand struct {
+	cmd.CommandBase
+	versionStr       string
+	majorVersion     int
+	minorVersion     int
+	allVersions      bool
+	stream           string
+	source           string
+	imageMetadataDir string
+	charms           string
+	bundles          string
+	signingKeyFile   string
+	passphrase       string
+	path             string
+}
+
+var _ cmd.Command = (*createOfflineBundleCommand)(nil)
+
+var createOfflineBundle = offline.Create
+
+func (c *createOfflineBundleCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "create-offline-bundle",
+		Args:    "<file>",
+		Purpose: "create an archive for bootstrapping without Internet access",
+		Doc:     createOfflineBundleDoc,
+	}
+}
+
+func (c *createOfflineBundleCommand) SetFlags(f *gnuflag.FlagSet) {
+	f.StringVar(&c.versionStr, "version", "", "include tools of a specific major[.minor] version")
+	f.BoolVar(&c.allVersions, "all", false, "include all matching versions, not just the latest")
+	f.StringVar(&c.stream, "stream", "", "simplestreams stream of the tools to include")
+	f.StringVar(&c.source, "source", "", "local source directory or URL for tools")
+	f.StringVar(&c.imageMetadataDir, "image-metadata-dir", "", "local directory holding image metadata to include")
+	f.StringVar(&c.charms, "charms", "", "comma-separated paths of local charms to include")
+	f.StringVar(&c.bundles, "bundles", "", "comma-separated paths of charm bundle files to include")
+	f.StringVar(&c.signingKeyFile, "signing-key", "", "file holding an armored private key to sign the metadata with")
+	f.StringVar(&c.passphrase, "passphrase", "", "passphrase of the signing key")
+}
+
+func (c *createOfflineBundleCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return errors.New("no file specified")
+	}
+	c.path, args = args[0], args[1:]
+	if c.versionStr != "" {
+		var err error
+		if c.majorVersion, c.minorVersion, err = version.ParseMajorMinor(c.versionStr); err != nil {
+			return err
+		}
+	}
+	if c.passphrase != "" && c.signingKeyFile == "" {
+		return errors.New("--passphrase requires --signing-key")
+	}
+	return cmd.CheckEmpty(args)
+}
+
+// splitPaths splits a comma-separated list of paths, ignoring empty
+// items.
+func splitPaths(ctx *cmd.Context, s string) []string {
+	var paths []string
+	for _, path := range strings.Split(s, ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			paths = append(paths, ctx.AbsPath(path))
+		}
+	}
+	return paths
+}
+
+func (c *createOfflineBundleCommand) Run(ctx *cmd.Context) (resultErr error) {
+	// Register writer for output on screen.
+	loggo.RegisterWriter("offlinebundle", cmd.NewCommandLogWriter("juju.environs.sync", ctx.Stdout, ctx.Stderr), loggo.INFO)
+	defer loggo.RemoveWriter("offlinebundle")
+
+	args := offline.CreateParams{
+		MajorVersion: c.majorVersion,
+		MinorVersion: c.minorVersion,
+		AllVersions:  c.allVersions,
+		Stream:       c.stream,
+		ToolsSource:  c.source,
+		Charms:       splitPaths(ctx, c.charms),
+		Bundles:      splitPaths(ctx, c.bundles),
+		Passphrase:   c.passphrase,
+	}
+	if c.imageMetadataDir != "" {
+		args.ImageMetadataDir = ctx.AbsPath(c.imageMetadataDir)
+	}
+	if c.signingKeyFile != "" {
+		data, err := ioutil.ReadFile(ctx.AbsPath(c.signingKeyFile))
+		if err != nil {
+			return errors.Annotate(err, "cannot read signing key")
+		}
+		args.SigningKey = string(data)
+	}
+
+	path := ctx.AbsPath(c.path)
+	f, err := os.Create(path)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer func() {
+		if err := f.Close(); err != nil && resultErr == nil {
+			resultErr = errors.Trace(err)
+		}
+		if resultErr != nil {
+			os.Remove(path)
+		}
+	}()
+	manifest, err := createOfflineBundle(f, args)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	fmt.Fprintf(ctx.Stdout, "Created offline bundle %s\n", filepath.Base(path))
+	fmt.Fprintf(ctx.Stdout, "  stream:  %s\n", manifest.Stream)
+	fmt.Fprintf(ctx.Stdout, "  tools:   %s\n", strings.Join(manifest.Tools, ", "))
+	if manifest.Images {
+		fmt.Fprintf(ctx.Stdout, "  images:  yes\n")
+	}
+	if len(manifest.Charms) > 0 {
+		fmt.Fprintf(ctx.Stdout, "  charms:  %s\n", strings.Join(manifest.Charms, ", "))
+	}
+	if len(manifest.Bundles) > 0 {
+		fmt.Fprintf(ctx.Stdout, "  bundles: %s\n", strings.Join(manifest.Bundles, ", "))
+	}
+	if manifest.Signed {
+		fmt.Fprintf(ctx.Stdout, "  signed:  yes\n")
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package commands
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/environs/offline"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type createOfflineBundleSuite struct {
+	coretesting.BaseSuite
+	args offline.CreateParams
+}
+
+var _ = gc.Suite(&createOfflineBundleSuite{})
+
+func (s *createOfflineBundleSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.args = offline.CreateParams{}
+	s.PatchValue(&createOfflineBundle, func(w io.Writer, args offline.CreateParams) (*offline.Manifest, error) {
+		s.args = args
+		if _, err := io.WriteString(w, "bundle"); err != nil {
+			return nil, err
+		}
+		return &offline.Manifest{
+			Format: offline.Format,
+			Stream: "released",
+			Tools:  []string{"1.25.0-trusty-amd64"},
+			Charms: []string{"local:trusty/mysql-1"},
+			Signed: args.SigningKey != "",
+		}, nil
+	})
+}
+
+func runCreateOfflineBundleCommand(c *gc.C, args ...string) (*cmd.Context, error) {
+	return coretesting.RunCommand(c, newCreateOfflineBundleCommand(), args...)
+}
+
+func (s *createOfflineBundleSuite) TestInitErrors(c *gc.C) {
+	for i, test := range []struct {
+		args []string
+		err  string
+	}{{
+		err: "no file specified",
+	}, {
+		args: []string{"a", "b"},
+		err:  `unrecognized args: \["b"\]`,
+	}, {
+		args: []string{"--version", "foo", "a"},
+		err:  `invalid major version number foo: .*`,
+	}, {
+		args: []string{"--passphrase", "secret", "a"},
+		err:  "--passphrase requires --signing-key",
+	}} {
+		c.Logf("test %d: %v", i, test.args)
+		_, err := runCreateOfflineBundleCommand(c, test.args...)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
+
+func (s *createOfflineBundleSuite) TestRun(c *gc.C) {
+	dir := c.MkDir()
+	keyPath := filepath.Join(dir, "signing.key")
+	err := ioutil.WriteFile(keyPath, []byte("private key"), 0600)
+	c.Assert(err, jc.ErrorIsNil)
+
+	ctx, err := runCreateOfflineBundleCommand(c,
+		"--version", "1.25", "--all", "--source", "/srv/tools",
+		"--image-metadata-dir", filepath.Join(dir, "metadata"),
+		"--charms", "/charms/trusty/mysql, /charms/trusty/wordpress",
+		"--bundles", "/bundles/lamp.yaml",
+		"--signing-key", keyPath, "--passphrase", "secret",
+		filepath.Join(dir, "offline.tar.gz"),
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.args, jc.DeepEquals, offline.CreateParams{
+		MajorVersion:     1,
+		MinorVersion:     25,
+		AllVersions:      true,
+		ToolsSource:      "/srv/tools",
+		ImageMetadataDir: filepath.Join(dir, "metadata"),
+		Charms:           []string{"/charms/trusty/mysql", "/charms/trusty/wordpress"},
+		Bundles:          []string{"/bundles/lamp.yaml"},
+		SigningKey:       "private key",
+		Passphrase:       "secret",
+	})
+	data, err := ioutil.ReadFile(filepath.Join(dir, "offline.tar.gz"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(data), gc.Equals, "bundle")
+	c.Assert(coretesting.Stdout(ctx), gc.Equals, ""+
+		"Created offline bundle offline.tar.gz\n"+
+		"  stream:  released\n"+
+		"  tools:   1.25.0-trusty-amd64\n"+
+		"  charms:  local:trusty/mysql-1\n"+
+		"  signed:  yes\n",
+	)
+}
+
+func (s *createOfflineBundleSuite) TestRunErrorRemovesFile(c *gc.C) {
+	s.PatchValue(&createOfflineBundle, func(io.Writer, offline.CreateParams) (*offline.Manifest, error) {
+		return nil, errors.New("boom")
+	})
+	path := filepath.Join(c.MkDir(), "offline.tar.gz")
+	_, err := runCreateOfflineBundleCommand(c, path)
+	c.Assert(err, gc.ErrorMatches, "boom")
+	_, err = os.Stat(path)
+	c.Assert(err, jc.Satisfies, os.IsNotExist)
+}
@@ -62,6 +62,10 @@ type DeployCommand struct {
 	// the storage name defined in that service's charm storage metadata.
 	BundleStorage map[string]map[string]storage.Constraints
 
+	// Bindings maps the charm's relation endpoints to the names of
+	// the spaces they are bound to.
+	Bindings map[string]string
+
 	Steps []DeployStep
 }
 
@@ -146,6 +150,11 @@ When deploying a service or adding machines, the "spaces" constraint can be
 used to define a comma-delimited list of required and forbidden spaces
 (the latter prefixed with "^", similar to the "tags" constraint).
 
+The charm's relation endpoints can be bound to spaces with the --bind flag,
+which takes a space- or comma-separated list of endpoint=space pairs. Units
+use the address in the bound space for the endpoint, as reported to the
+charm by the network-get hook tool.
+
 If you have the main container directory mounted on a btrfs partition,
 then the clone will be using btrfs snapshots to create the containers.
 This means that clones use up much less disk space.  If you do not have btrfs,
@@ -166,6 +175,9 @@ Examples:
    (deploy 2 instances of haproxy on cloud instances being part of the dmz
     space but not of the cmd and the database space)
 
+   juju deploy mysql --bind db=internal
+   (deploy mysql with its db endpoint bound to the internal space)
+
 See Also:
    juju help spaces
    juju help constraints
@@ -212,6 +224,7 @@ func (c *DeployCommand) SetFlags(f *gnuflag.FlagSet) {
 	f.StringVar(&c.Series, "series", "", "the series on which to deploy")
 	f.BoolVar(&c.Force, "force", false, "allow a charm to be deployed to a machine running an unsupported series")
 	f.Var(storageFlag{&c.Storage, &c.BundleStorage}, "storage", "charm storage constraints")
+	f.Var(bindingsFlag{&c.Bindings}, "bind", "bind charm endpoints to spaces")
 	for _, step := range c.Steps {
 		step.SetFlags(f)
 	}
@@ -496,6 +509,7 @@ func (c *DeployCommand) deployCharm(
 		c.Placement,
 		c.Networks,
 		c.Storage,
+		c.Bindings,
 	}); err != nil {
 		return err
 	}
@@ -530,6 +544,7 @@ type serviceDeployParams struct {
 	placement     []*instance.Placement
 	networks      string
 	storage       map[string]storage.Constraints
+	bindings      map[string]string
 }
 
 type serviceDeployer struct {
@@ -575,6 +590,7 @@ func (c *serviceDeployer) serviceDeploy(args serviceDeployParams) error {
 		args.placement,
 		[]string{},
 		args.storage,
+		args.bindings,
 	)
 }
 
@@ -87,6 +87,12 @@ var initErrorTests = []struct {
 	}, {
 		args: []string{"charm", "service", "--force"},
 		err:  `--force is only used with --series`,
+	}, {
+		args: []string{"craziness", "burble1", "--bind", "db"},
+		err:  `invalid value "db" for flag --bind: expected <endpoint>=<space>, got "db"`,
+	}, {
+		args: []string{"craziness", "burble1", "--bind", "db=internal,=dmz"},
+		err:  `invalid value "db=internal,=dmz" for flag --bind: expected <endpoint>=<space>, got "=dmz"`,
 	},
 }
 
@@ -283,6 +289,31 @@ func (s *DeploySuite) TestNetworksIsDeprecated(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, "use of --networks is deprecated. Please use spaces")
 }
 
+func (s *DeploySuite) TestBindings(c *gc.C) {
+	_, err := s.State.AddSubnet(state.SubnetInfo{CIDR: "10.0.0.0/24"})
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.AddSpace("internal", []string{"10.0.0.0/24"}, false)
+	c.Assert(err, jc.ErrorIsNil)
+
+	testcharms.Repo.CharmArchivePath(s.SeriesPath, "wordpress")
+	err = runDeploy(c, "local:wordpress", "--bind", "db=internal url=internal")
+	c.Assert(err, jc.ErrorIsNil)
+	service, err := s.State.Service("wordpress")
+	c.Assert(err, jc.ErrorIsNil)
+	bindings, err := service.EndpointBindings()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(bindings, jc.DeepEquals, map[string]string{
+		"db":  "internal",
+		"url": "internal",
+	})
+}
+
+func (s *DeploySuite) TestBindingsUnknownSpace(c *gc.C) {
+	testcharms.Repo.CharmArchivePath(s.SeriesPath, "wordpress")
+	err := runDeploy(c, "local:wordpress", "--bind", "db=dmz")
+	c.Assert(err, gc.ErrorMatches, `cannot add service "wordpress": binding of endpoint "db" to unknown space "dmz" not valid`)
+}
+
 // TODO(wallyworld) - add another test that deploy with storage fails for older environments
 // (need deploy client to be refactored to use API stub)
 func (s *DeploySuite) TestStorage(c *gc.C) {
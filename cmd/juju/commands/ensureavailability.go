@@ -46,6 +46,16 @@ type ensureAvailabilityCommand struct {
 	Placement []string
 	// PlacementSpec holds the unparsed placement directives argument (--to).
 	PlacementSpec string
+	// NonVoting holds placement directives for state servers to add as
+	// non-voting members of the mongo replica set.
+	NonVoting []string
+	// NonVotingSpec holds the unparsed --non-voting argument.
+	NonVotingSpec string
+	// Hidden holds placement directives for state servers to add as
+	// hidden members of the mongo replica set.
+	Hidden []string
+	// HiddenSpec holds the unparsed --hidden argument.
+	HiddenSpec string
 }
 
 const ensureAvailabilityDoc = `
@@ -72,6 +82,18 @@ Examples:
      Ensure that 7 state servers are available, with machines server1 and
      server2 used first, and if necessary, newly created state server
      machines having the default series, and at least 8GB RAM.
+ juju ensure-availability --non-voting zone=dr1
+     Add a state server in zone dr1 that replicates the database but
+     never votes in elections or becomes primary, such as at a disaster
+     recovery site.
+ juju ensure-availability --hidden 4
+     Convert machine 4 into a state server that only holds a backup
+     of the database: it never votes, and is hidden from database
+     clients.
+
+State servers added with --non-voting or --hidden do not count towards
+the number of state servers made available, which must stay odd. They
+cannot be combined with -n or --to.
 `
 
 // formatSimple marshals value to a yaml-formatted []byte, unless value is nil.
@@ -136,6 +158,8 @@ func (c *ensureAvailabilityCommand) SetFlags(f *gnuflag.FlagSet) {
 	f.IntVar(&c.NumStateServers, "n", 0, "number of state servers to make available")
 	f.StringVar(&c.Series, "series", "", "the charm series")
 	f.StringVar(&c.PlacementSpec, "to", "", "the machine(s) to become state servers, bypasses constraints")
+	f.StringVar(&c.NonVotingSpec, "non-voting", "", "placement directives for state servers to add as non-voting replica set members")
+	f.StringVar(&c.HiddenSpec, "hidden", "", "placement directives for state servers to add as hidden replica set members")
 	f.Var(constraints.ConstraintsValue{&c.Constraints}, "constraints", "additional machine constraints")
 	c.out.AddFlags(f, "simple", map[string]cmd.Formatter{
 		"yaml":   cmd.FormatYaml,
@@ -149,28 +173,48 @@ func (c *ensureAvailabilityCommand) Init(args []string) error {
 	if c.NumStateServers < 0 || (c.NumStateServers%2 != 1 && c.NumStateServers != 0) {
 		return fmt.Errorf("must specify a number of state servers odd and non-negative")
 	}
-	if c.PlacementSpec != "" {
-		placementSpecs := strings.Split(c.PlacementSpec, ",")
-		c.Placement = make([]string, len(placementSpecs))
-		for i, spec := range placementSpecs {
-			p, err := instance.ParsePlacement(strings.TrimSpace(spec))
-			if err == nil && names.IsContainerMachine(p.Directive) {
-				return errors.New("ensure-availability cannot be used with container placement directives")
-			}
-			if err == nil && p.Scope == instance.MachineScope {
-				// Targeting machines is ok.
-				c.Placement[i] = p.String()
-				continue
-			}
-			if err != instance.ErrPlacementScopeMissing {
-				return fmt.Errorf("unsupported ensure-availability placement directive %q", spec)
-			}
-			c.Placement[i] = spec
-		}
+	var err error
+	if c.Placement, err = parsePlacementSpec(c.PlacementSpec); err != nil {
+		return err
+	}
+	if c.NonVoting, err = parsePlacementSpec(c.NonVotingSpec); err != nil {
+		return err
+	}
+	if c.Hidden, err = parsePlacementSpec(c.HiddenSpec); err != nil {
+		return err
+	}
+	if len(c.NonVoting)+len(c.Hidden) > 0 && (c.NumStateServers != 0 || len(c.Placement) > 0) {
+		return errors.New("--non-voting and --hidden cannot be used with -n or --to")
 	}
 	return cmd.CheckEmpty(args)
 }
 
+// parsePlacementSpec parses a comma-separated list of
+// ensure-availability placement directives.
+func parsePlacementSpec(placementSpec string) ([]string, error) {
+	if placementSpec == "" {
+		return nil, nil
+	}
+	placementSpecs := strings.Split(placementSpec, ",")
+	placement := make([]string, len(placementSpecs))
+	for i, spec := range placementSpecs {
+		p, err := instance.ParsePlacement(strings.TrimSpace(spec))
+		if err == nil && names.IsContainerMachine(p.Directive) {
+			return nil, errors.New("ensure-availability cannot be used with container placement directives")
+		}
+		if err == nil && p.Scope == instance.MachineScope {
+			// Targeting machines is ok.
+			placement[i] = p.String()
+			continue
+		}
+		if err != instance.ErrPlacementScopeMissing {
+			return nil, fmt.Errorf("unsupported ensure-availability placement directive %q", spec)
+		}
+		placement[i] = spec
+	}
+	return placement, nil
+}
+
 type availabilityInfo struct {
 	Maintained []string `json:"maintained,omitempty" yaml:"maintained,flow,omitempty"`
 	Removed    []string `json:"removed,omitempty" yaml:"removed,flow,omitempty"`
@@ -188,6 +232,9 @@ type EnsureAvailabilityClient interface {
 	EnsureAvailability(
 		numStateServers int, cons constraints.Value, series string,
 		placement []string) (params.StateServersChanges, error)
+	AddStateServerMembers(
+		role string, cons constraints.Value, series string,
+		placement []string) (params.StateServersChanges, error)
 }
 
 func (c *ensureAvailabilityCommand) getHAClient() (EnsureAvailabilityClient, error) {
@@ -213,6 +260,9 @@ func (c *ensureAvailabilityCommand) Run(ctx *cmd.Context) error {
 	}
 
 	defer haClient.Close()
+	if len(c.NonVoting)+len(c.Hidden) > 0 {
+		return c.addStateServerMembers(ctx, haClient)
+	}
 	ensureAvailabilityResult, err := haClient.EnsureAvailability(
 		c.NumStateServers,
 		c.Constraints,
@@ -222,18 +272,45 @@ func (c *ensureAvailabilityCommand) Run(ctx *cmd.Context) error {
 	if err != nil {
 		return block.ProcessBlockedError(err, block.BlockChange)
 	}
+	return c.out.Write(ctx, newAvailabilityInfo(ensureAvailabilityResult))
+}
 
-	result := availabilityInfo{
-		Added:      machineTagsToIds(ensureAvailabilityResult.Added...),
-		Removed:    machineTagsToIds(ensureAvailabilityResult.Removed...),
-		Maintained: machineTagsToIds(ensureAvailabilityResult.Maintained...),
-		Promoted:   machineTagsToIds(ensureAvailabilityResult.Promoted...),
-		Demoted:    machineTagsToIds(ensureAvailabilityResult.Demoted...),
-		Converted:  machineTagsToIds(ensureAvailabilityResult.Converted...),
+// addStateServerMembers adds the non-voting and hidden state servers
+// requested.
+func (c *ensureAvailabilityCommand) addStateServerMembers(ctx *cmd.Context, haClient EnsureAvailabilityClient) error {
+	var result availabilityInfo
+	for _, members := range []struct {
+		role      string
+		placement []string
+	}{
+		{"non-voting", c.NonVoting},
+		{"hidden", c.Hidden},
+	} {
+		if len(members.placement) == 0 {
+			continue
+		}
+		changes, err := haClient.AddStateServerMembers(members.role, c.Constraints, c.Series, members.placement)
+		if err != nil {
+			return block.ProcessBlockedError(err, block.BlockChange)
+		}
+		info := newAvailabilityInfo(changes)
+		result.Added = append(result.Added, info.Added...)
+		result.Converted = append(result.Converted, info.Converted...)
 	}
 	return c.out.Write(ctx, result)
 }
 
+func newAvailabilityInfo(changes params.StateServersChanges) availabilityInfo {
+	return availabilityInfo{
+		Added:      machineTagsToIds(changes.Added...),
+		Removed:    machineTagsToIds(changes.Removed...),
+		Maintained: machineTagsToIds(changes.Maintained...),
+		Promoted:   machineTagsToIds(changes.Promoted...),
+		Demoted:    machineTagsToIds(changes.Demoted...),
+		Converted:  machineTagsToIds(changes.Converted...),
+	}
+}
+
 // Convert machine tags to ids, skipping any non-machine tags.
 func machineTagsToIds(tags ...string) []string {
 	var result []string
@@ -53,6 +53,8 @@ type fakeHAClient struct {
 	series          string
 	placement       []string
 	result          params.StateServersChanges
+	members         map[string][]string
+	membersAdded    int
 }
 
 func (f *fakeHAClient) Close() error {
@@ -98,6 +100,32 @@ func (f *fakeHAClient) EnsureAvailability(numStateServers int, cons constraints.
 	return f.result, nil
 }
 
+func (f *fakeHAClient) AddStateServerMembers(role string, cons constraints.Value,
+	series string, placement []string) (params.StateServersChanges, error) {
+
+	f.cons = cons
+	f.series = series
+	if f.members == nil {
+		f.members = make(map[string][]string)
+	}
+	f.members[role] = placement
+
+	if f.err != nil {
+		return params.StateServersChanges{}, f.err
+	}
+	var result params.StateServersChanges
+	for _, p := range placement {
+		m, err := instance.ParsePlacement(p)
+		if err == nil && m.Scope == instance.MachineScope {
+			result.Converted = append(result.Converted, "machine-"+m.Directive)
+		} else {
+			result.Added = append(result.Added, fmt.Sprintf("machine-%d", 10+f.membersAdded))
+			f.membersAdded++
+		}
+	}
+	return result, nil
+}
+
 var _ = gc.Suite(&EnsureAvailabilitySuite{})
 
 func (s *EnsureAvailabilitySuite) runEnsureAvailability(c *gc.C, args ...string) (*cmd.Context, error) {
@@ -275,3 +303,50 @@ converting machines: 1, 2
 	c.Check(s.fake.series, gc.Equals, "")
 	c.Check(len(s.fake.placement), gc.Equals, 2)
 }
+
+func (s *EnsureAvailabilitySuite) TestEnsureAvailabilityNonVotingAndHidden(c *gc.C) {
+	ctx, err := s.runEnsureAvailability(c, "--non-voting", "zone=dr1,3", "--hidden", "4", "--series", "trusty")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(coretesting.Stdout(ctx), gc.Equals, `
+adding machines: 10
+converting machines: 3, 4
+
+`[1:])
+
+	c.Check(s.fake.numStateServers, gc.Equals, invalidNumServers)
+	c.Check(s.fake.series, gc.Equals, "trusty")
+	c.Check(s.fake.members, jc.DeepEquals, map[string][]string{
+		"non-voting": {"zone=dr1", "3"},
+		"hidden":     {"4"},
+	})
+}
+
+func (s *EnsureAvailabilitySuite) TestBlockEnsureAvailabilityHidden(c *gc.C) {
+	s.fake.err = common.OperationBlockedError("TestBlockEnsureAvailabilityHidden")
+	_, err := s.runEnsureAvailability(c, "--hidden", "4")
+	c.Assert(err, gc.ErrorMatches, cmd.ErrSilent.Error())
+
+	stripped := strings.Replace(c.GetTestLog(), "\n", "", -1)
+	c.Check(stripped, gc.Matches, ".*TestBlockEnsureAvailabilityHidden.*")
+}
+
+func (s *EnsureAvailabilitySuite) TestEnsureAvailabilityHiddenErrors(c *gc.C) {
+	for i, test := range []struct {
+		args []string
+		err  string
+	}{{
+		args: []string{"--hidden", "4", "-n", "3"},
+		err:  "--non-voting and --hidden cannot be used with -n or --to",
+	}, {
+		args: []string{"--non-voting", "zone=dr1", "--to", "1"},
+		err:  "--non-voting and --hidden cannot be used with -n or --to",
+	}, {
+		args: []string{"--hidden", "4/lxc/0"},
+		err:  "ensure-availability cannot be used with container placement directives",
+	}} {
+		c.Logf("test %d: %v", i, test.args)
+		_, err := s.runEnsureAvailability(c, test.args...)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+	c.Assert(s.fake.members, gc.IsNil)
+}
@@ -5,9 +5,11 @@ package commands
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/juju/errors"
+	"github.com/juju/utils/keyvalues"
 
 	"github.com/juju/juju/storage"
 )
@@ -67,3 +69,68 @@ func (f storageFlag) String() string {
 	}
 	return strings.Join(strs, " ")
 }
+
+type bindingsFlag struct {
+	bindings *map[string]string
+}
+
+// Set implements gnuflag.Value.Set.
+func (f bindingsFlag) Set(s string) error {
+	pairs := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' '
+	})
+	if len(pairs) == 0 {
+		return errors.New("expected <endpoint>=<space>")
+	}
+	if *f.bindings == nil {
+		*f.bindings = make(map[string]string)
+	}
+	for _, pair := range pairs {
+		fields := strings.SplitN(pair, "=", 2)
+		if len(fields) < 2 || fields[0] == "" || fields[1] == "" {
+			return errors.Errorf("expected <endpoint>=<space>, got %q", pair)
+		}
+		(*f.bindings)[fields[0]] = fields[1]
+	}
+	return nil
+}
+
+// String implements gnuflag.Value.String.
+func (f bindingsFlag) String() string {
+	strs := make([]string, 0, len(*f.bindings))
+	for endpoint, space := range *f.bindings {
+		strs = append(strs, fmt.Sprintf("%s=%s", endpoint, space))
+	}
+	sort.Strings(strs)
+	return strings.Join(strs, " ")
+}
+
+// keyValuesFlag collects the key=value pairs given to a repeatable flag.
+type keyValuesFlag struct {
+	values *map[string]string
+}
+
+// Set implements gnuflag.Value.Set.
+func (f keyValuesFlag) Set(s string) error {
+	values, err := keyvalues.Parse([]string{s}, true)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if *f.values == nil {
+		*f.values = make(map[string]string)
+	}
+	for key, value := range values {
+		(*f.values)[key] = value
+	}
+	return nil
+}
+
+// String implements gnuflag.Value.String.
+func (f keyValuesFlag) String() string {
+	strs := make([]string, 0, len(*f.values))
+	for key, value := range *f.values {
+		strs = append(strs, fmt.Sprintf("%s=%s", key, value))
+	}
+	sort.Strings(strs)
+	return strings.Join(strs, " ")
+}
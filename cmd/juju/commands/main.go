@@ -138,6 +138,8 @@ func registerCommands(r commandRegistry, ctx *cmd.Context) {
 	r.Register(newSwitchCommand())
 	r.Register(newEndpointCommand())
 	r.Register(newAPIInfoCommand())
+	r.Register(newShowRelationDataCommand())
+	r.Register(newShowHookLogCommand())
 	r.Register(status.NewStatusHistoryCommand())
 
 	// Error resolution and debugging commands.
@@ -152,8 +154,11 @@ func registerCommands(r commandRegistry, ctx *cmd.Context) {
 	r.Register(newInitCommand())
 	r.Register(common.NewGetConstraintsCommand())
 	r.Register(common.NewSetConstraintsCommand())
+	r.Register(common.NewGetLabelsCommand())
+	r.Register(common.NewSetLabelsCommand())
 	r.Register(newExposeCommand())
 	r.Register(newSyncToolsCommand())
+	r.Register(newCreateOfflineBundleCommand())
 	r.Register(newUnexposeCommand())
 	r.Register(newUpgradeJujuCommand())
 	r.Register(newUpgradeCharmCommand())
@@ -172,6 +177,11 @@ func registerCommands(r commandRegistry, ctx *cmd.Context) {
 
 	// Manage users and access
 	r.Register(user.NewSuperCommand())
+	r.RegisterSuperAlias("change-user-password", "user", "change-password", nil)
+	r.RegisterSuperAlias("enable-user", "user", "enable", nil)
+	r.RegisterSuperAlias("add-token", "user", "add-token", nil)
+	r.RegisterSuperAlias("list-tokens", "user", "list-tokens", nil)
+	r.RegisterSuperAlias("remove-token", "user", "remove-token", nil)
 
 	// Manage cached images
 	r.Register(cachedimages.NewSuperCommand())
@@ -192,17 +202,26 @@ func registerCommands(r commandRegistry, ctx *cmd.Context) {
 	r.RegisterSuperAlias("unset-environment", "environment", "unset", nil)
 	r.RegisterSuperAlias("unset-env", "environment", "unset", nil)
 	r.RegisterSuperAlias("retry-provisioning", "environment", "retry-provisioning", nil)
+	r.RegisterSuperAlias("grant", "environment", "grant", nil)
+	r.RegisterSuperAlias("revoke", "environment", "revoke", nil)
+	r.RegisterSuperAlias("list-shares", "environment", "users", nil)
 
 	// Manage and control actions
 	r.Register(action.NewSuperCommand())
 
-	// Manage state server availability
+	// Manage state server availability and certificates
 	r.Register(newEnsureAvailabilityCommand())
+	r.Register(newRotateCertificatesCommand())
+	r.Register(newShowCertificatesCommand())
 
 	// Manage and control services
 	r.Register(service.NewSuperCommand())
 	r.RegisterSuperAlias("add-unit", "service", "add-unit", nil)
+	r.RegisterSuperAlias("config-history", "service", "config-history", nil)
+	r.RegisterSuperAlias("config-rollback", "service", "config-rollback", nil)
+	r.RegisterSuperAlias("consume", "service", "consume", nil)
 	r.RegisterSuperAlias("get", "service", "get", nil)
+	r.RegisterSuperAlias("offer", "service", "offer", nil)
 	r.RegisterSuperAlias("set", "service", "set", nil)
 	r.RegisterSuperAlias("unset", "service", "unset", nil)
 
@@ -229,6 +248,7 @@ func registerCommands(r commandRegistry, ctx *cmd.Context) {
 		r.RegisterSuperAlias("login", "system", "login", nil)
 		r.RegisterSuperAlias("create-environment", "system", "create-environment", nil)
 		r.RegisterSuperAlias("create-env", "system", "create-env", nil)
+		r.RegisterSuperAlias("migrate", "system", "migrate", nil)
 	}
 
 	// Commands registered elsewhere.
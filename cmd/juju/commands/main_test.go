@@ -200,6 +200,7 @@ var commandNames = []string{
 	"action",
 	"add-machine",
 	"add-relation",
+	"add-token", // alias for user add-token
 	"add-unit",
 	"api-endpoints",
 	"api-info",
@@ -209,6 +210,11 @@ var commandNames = []string{
 	"block",
 	"bootstrap",
 	"cached-images",
+	"change-user-password", // alias for user change-password
+	"config-history",
+	"config-rollback",
+	"consume",
+	"create-offline-bundle",
 	"debug-hooks",
 	"debug-log",
 	"deploy",
@@ -217,6 +223,7 @@ var commandNames = []string{
 	"destroy-relation",
 	"destroy-service",
 	"destroy-unit",
+	"enable-user", // alias for user enable
 	"ensure-availability",
 	"env", // alias for switch
 	"environment",
@@ -226,17 +233,25 @@ var commandNames = []string{
 	"get-constraints",
 	"get-env", // alias for get-environment
 	"get-environment",
+	"get-labels",
+	"grant",
 	"help",
 	"help-tool",
 	"init",
+	"list-shares", // alias for environment users
+	"list-tokens", // alias for user list-tokens
 	"machine",
+	"offer",
 	"publish",
 	"remove-machine",  // alias for destroy-machine
 	"remove-relation", // alias for destroy-relation
 	"remove-service",  // alias for destroy-service
+	"remove-token",    // alias for user remove-token
 	"remove-unit",     // alias for destroy-unit
 	"resolved",
 	"retry-provisioning",
+	"revoke",
+	"rotate-certificates",
 	"run",
 	"scp",
 	"service",
@@ -244,6 +259,10 @@ var commandNames = []string{
 	"set-constraints",
 	"set-env", // alias for set-environment
 	"set-environment",
+	"set-labels",
+	"show-certificates",
+	"show-hook-log",
+	"show-relation-data",
 	"space",
 	"ssh",
 	"stat", // alias for status
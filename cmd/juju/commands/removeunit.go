@@ -8,9 +8,11 @@ import (
 
 	"github.com/juju/cmd"
 	"github.com/juju/names"
+	"launchpad.net/gnuflag"
 
 	"github.com/juju/juju/cmd/envcmd"
 	"github.com/juju/juju/cmd/juju/block"
+	"github.com/juju/juju/cmd/juju/common"
 )
 
 func newRemoveUnitCommand() cmd.Command {
@@ -21,6 +23,7 @@ func newRemoveUnitCommand() cmd.Command {
 type removeUnitCommand struct {
 	envcmd.EnvCommandBase
 	UnitNames []string
+	Selector  string
 }
 
 const removeUnitDoc = `
@@ -31,21 +34,30 @@ the unit is hosted will also be destroyed, if possible.
 The machine will be destroyed if:
 - it is not a state server
 - it is not hosting any Juju managed containers
+
+Units may also be selected by their labels with --selector (-l). For
+example, "juju remove-unit -l env=canary" removes all units labelled (or
+whose service is labelled) env=canary. See "juju help set-labels".
 `
 
 func (c *removeUnitCommand) Info() *cmd.Info {
 	return &cmd.Info{
 		Name:    "remove-unit",
-		Args:    "<unit> [...]",
+		Args:    "<unit> [...] | --selector <selector>",
 		Purpose: "remove service units from the environment",
 		Doc:     removeUnitDoc,
 		Aliases: []string{"destroy-unit"},
 	}
 }
 
+func (c *removeUnitCommand) SetFlags(f *gnuflag.FlagSet) {
+	f.StringVar(&c.Selector, "l", "", "remove the units whose labels match the selector")
+	f.StringVar(&c.Selector, "selector", "", "")
+}
+
 func (c *removeUnitCommand) Init(args []string) error {
 	c.UnitNames = args
-	if len(c.UnitNames) == 0 {
+	if len(c.UnitNames) == 0 && c.Selector == "" {
 		return fmt.Errorf("no units specified")
 	}
 	for _, name := range c.UnitNames {
@@ -59,10 +71,18 @@ func (c *removeUnitCommand) Init(args []string) error {
 // Run connects to the environment specified on the command line and destroys
 // units therein.
 func (c *removeUnitCommand) Run(_ *cmd.Context) error {
+	unitNames := c.UnitNames
+	if c.Selector != "" {
+		selected, err := common.SelectUnits(&c.EnvCommandBase, c.Selector)
+		if err != nil {
+			return err
+		}
+		unitNames = append(unitNames, selected...)
+	}
 	client, err := c.NewAPIClient()
 	if err != nil {
 		return err
 	}
 	defer client.Close()
-	return block.ProcessBlockedError(client.DestroyServiceUnits(c.UnitNames...), block.BlockRemove)
+	return block.ProcessBlockedError(client.DestroyServiceUnits(unitNames...), block.BlockRemove)
 }
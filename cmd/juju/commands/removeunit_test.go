@@ -64,3 +64,26 @@ func (s *RemoveUnitSuite) TestBlockRemoveUnit(c *gc.C) {
 	s.AssertBlocked(c, err, ".*TestBlockRemoveUnit.*")
 	c.Assert(svc.Life(), gc.Equals, state.Alive)
 }
+
+func (s *RemoveUnitSuite) TestRemoveUnitSelector(c *gc.C) {
+	svc := s.setupUnitForRemove(c)
+	canary, err := s.State.Unit("dummy/1")
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.SetLabels(canary, map[string]string{"env": "canary"})
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = runRemoveUnit(c, "-l", "env=prod")
+	c.Assert(err, gc.ErrorMatches, `no units match selector "env=prod"`)
+
+	err = runRemoveUnit(c, "-l", "env=canary")
+	c.Assert(err, jc.ErrorIsNil)
+	units, err := svc.AllUnits()
+	c.Assert(err, jc.ErrorIsNil)
+	for _, u := range units {
+		if u.Name() == "dummy/1" {
+			c.Check(u.Life(), gc.Equals, state.Dying)
+		} else {
+			c.Check(u.Life(), gc.Equals, state.Alive)
+		}
+	}
+}
@@ -32,6 +32,7 @@ type runCommand struct {
 	machines []string
 	services []string
 	units    []string
+	selector string
 	commands string
 }
 
@@ -57,6 +58,12 @@ is equivalent to
 Commands run for services or units are executed in a 'hook context' for
 the unit.
 
+The --selector (-l) option runs the command on all units whose labels match
+the given label selector, in addition to any other targets. For example,
+  -l tier=web,env!=canary
+runs the command on the units labelled (or whose service is labelled)
+tier=web and not labelled env=canary. See "juju help set-labels".
+
 --all is provided as a simple way to run the command on all the machines
 in the environment.  If you specify --all you cannot provide additional
 targets.
@@ -79,6 +86,8 @@ func (c *runCommand) SetFlags(f *gnuflag.FlagSet) {
 	f.Var(cmd.NewStringsValue(nil, &c.machines), "machine", "one or more machine ids")
 	f.Var(cmd.NewStringsValue(nil, &c.services), "service", "one or more service names")
 	f.Var(cmd.NewStringsValue(nil, &c.units), "unit", "one or more unit ids")
+	f.StringVar(&c.selector, "l", "", "run the commands on the units whose labels match the selector")
+	f.StringVar(&c.selector, "selector", "", "")
 }
 
 func (c *runCommand) Init(args []string) error {
@@ -97,9 +106,12 @@ func (c *runCommand) Init(args []string) error {
 		if len(c.units) != 0 {
 			return fmt.Errorf("You cannot specify --all and individual units")
 		}
+		if c.selector != "" {
+			return fmt.Errorf("You cannot specify --all and a label selector")
+		}
 	} else {
-		if len(c.machines) == 0 && len(c.services) == 0 && len(c.units) == 0 {
-			return fmt.Errorf("You must specify a target, either through --all, --machine, --service or --unit")
+		if len(c.machines) == 0 && len(c.services) == 0 && len(c.units) == 0 && c.selector == "" {
+			return fmt.Errorf("You must specify a target, either through --all, --machine, --service, --unit or --selector")
 		}
 	}
 
@@ -193,6 +205,7 @@ func (c *runCommand) Run(ctx *cmd.Context) error {
 			Machines: c.machines,
 			Services: c.services,
 			Units:    c.units,
+			Selector: c.selector,
 		}
 		runResults, err = client.Run(params)
 	}
@@ -34,6 +34,7 @@ func (*RunSuite) TestTargetArgParsing(c *gc.C) {
 		machines []string
 		units    []string
 		services []string
+		selector string
 		commands string
 		errMatch string
 	}{{
@@ -42,7 +43,7 @@ func (*RunSuite) TestTargetArgParsing(c *gc.C) {
 	}, {
 		message:  "no target",
 		args:     []string{"sudo reboot"},
-		errMatch: "You must specify a target, either through --all, --machine, --service or --unit",
+		errMatch: "You must specify a target, either through --all, --machine, --service, --unit or --selector",
 	}, {
 		message:  "too many args",
 		args:     []string{"--all", "sudo reboot", "oops"},
@@ -107,6 +108,15 @@ func (*RunSuite) TestTargetArgParsing(c *gc.C) {
 		machines: []string{"0"},
 		services: []string{"mysql"},
 		units:    []string{"wordpress/0", "wordpress/1"},
+	}, {
+		message:  "command to units matching a selector",
+		args:     []string{"-l", "tier=web,env!=canary", "sudo reboot"},
+		commands: "sudo reboot",
+		selector: "tier=web,env!=canary",
+	}, {
+		message:  "all and a selector",
+		args:     []string{"--all", "--selector=tier=web", "sudo reboot"},
+		errMatch: `You cannot specify --all and a label selector`,
 	}} {
 		c.Log(fmt.Sprintf("%v: %s", i, test.message))
 		cmd := &runCommand{}
@@ -117,6 +127,7 @@ func (*RunSuite) TestTargetArgParsing(c *gc.C) {
 			c.Check(cmd.machines, gc.DeepEquals, test.machines)
 			c.Check(cmd.services, gc.DeepEquals, test.services)
 			c.Check(cmd.units, gc.DeepEquals, test.units)
+			c.Check(cmd.selector, gc.Equals, test.selector)
 			c.Check(cmd.commands, gc.Equals, test.commands)
 		}
 	}
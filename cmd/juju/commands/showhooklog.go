@@ -0,0 +1,170 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/envcmd"
+)
+
+const showHookLogDoc = `
+show-hook-log shows the hooks a unit has most recently run, oldest first:
+when each hook started, how long it took, the relation and remote unit it
+ran for, its exit status, and the last lines it wrote to stderr. The
+state server keeps the last 100 hook runs of each unit.
+
+An exit status of -1 means the hook did not exit normally, for example
+because it could not be started or was killed.
+
+Examples:
+
+    juju show-hook-log mysql/0
+    juju show-hook-log -n 5 --format yaml wordpress/1
+
+See Also:
+    juju help debug-log
+    juju help debug-hooks
+`
+
+// hookLogAPI defines the API methods used by the show-hook-log command.
+type hookLogAPI interface {
+	HookLog(unitName string, size int) ([]params.HookRecord, error)
+	Close() error
+}
+
+var newHookLogAPI = func(c *showHookLogCommand) (hookLogAPI, error) {
+	return c.NewAPIClient()
+}
+
+func newShowHookLogCommand() cmd.Command {
+	return envcmd.Wrap(&showHookLogCommand{})
+}
+
+// showHookLogCommand shows the hooks recently run by a unit.
+type showHookLogCommand struct {
+	envcmd.EnvCommandBase
+	out      cmd.Output
+	UnitName string
+	Size     int
+}
+
+func (c *showHookLogCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "show-hook-log",
+		Args:    "<unit>",
+		Purpose: "show the hooks recently run by a unit",
+		Doc:     showHookLogDoc,
+	}
+}
+
+func (c *showHookLogCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.out.AddFlags(f, "tabular", map[string]cmd.Formatter{
+		"yaml":    cmd.FormatYaml,
+		"json":    cmd.FormatJson,
+		"tabular": formatHookLogTabular,
+	})
+	f.IntVar(&c.Size, "n", 20, "the number of hook runs to show")
+}
+
+func (c *showHookLogCommand) Init(args []string) error {
+	switch len(args) {
+	case 0:
+		return errors.New("no unit specified")
+	case 1:
+		if !names.IsValidUnit(args[0]) {
+			return errors.Errorf("invalid unit name %q", args[0])
+		}
+		c.UnitName = args[0]
+	default:
+		return cmd.CheckEmpty(args[1:])
+	}
+	if c.Size < 1 {
+		return errors.Errorf("invalid number of hook runs %d", c.Size)
+	}
+	return nil
+}
+
+// formattedHookRecord is the output format of a single show-hook-log entry.
+type formattedHookRecord struct {
+	Hook       string   `yaml:"hook" json:"hook"`
+	RelationId *int     `yaml:"relation-id,omitempty" json:"relation-id,omitempty"`
+	RemoteUnit string   `yaml:"remote-unit,omitempty" json:"remote-unit,omitempty"`
+	Started    string   `yaml:"started" json:"started"`
+	Duration   string   `yaml:"duration" json:"duration"`
+	ExitStatus int      `yaml:"exit-status" json:"exit-status"`
+	Stderr     []string `yaml:"stderr,omitempty" json:"stderr,omitempty"`
+}
+
+func (c *showHookLogCommand) Run(ctx *cmd.Context) error {
+	apiclient, err := newHookLogAPI(c)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer apiclient.Close()
+
+	records, err := apiclient.HookLog(c.UnitName, c.Size)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	formatted := make([]formattedHookRecord, len(records))
+	for i, record := range records {
+		formatted[i] = formattedHookRecord{
+			Hook:       record.Hook,
+			RemoteUnit: record.RemoteUnit,
+			Started:    record.Started.UTC().Format(time.RFC3339),
+			Duration:   record.Duration.String(),
+			ExitStatus: record.ExitStatus,
+			Stderr:     record.Stderr,
+		}
+		if record.RelationId >= 0 {
+			relationId := record.RelationId
+			formatted[i].RelationId = &relationId
+		}
+	}
+	return c.out.Write(ctx, formatted)
+}
+
+// formatHookLogTabular returns a tabular summary of the hook log,
+// followed by the stderr output of each hook that wrote any.
+func formatHookLogTabular(value interface{}) ([]byte, error) {
+	records, ok := value.([]formattedHookRecord)
+	if !ok {
+		return nil, errors.Errorf("expected value of type %T, got %T", records, value)
+	}
+	var out bytes.Buffer
+	tw := tabwriter.NewWriter(&out, 0, 1, 1, ' ', 0)
+	fmt.Fprintln(tw, "STARTED\tHOOK\tRELATION\tREMOTE\tDURATION\tEXIT")
+	for _, record := range records {
+		relation := ""
+		if record.RelationId != nil {
+			relation = strconv.Itoa(*record.RelationId)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%d\n",
+			record.Started, record.Hook, relation, record.RemoteUnit,
+			record.Duration, record.ExitStatus,
+		)
+	}
+	tw.Flush()
+	for _, record := range records {
+		if len(record.Stderr) == 0 {
+			continue
+		}
+		fmt.Fprintf(&out, "\n%s %s stderr:\n", record.Started, record.Hook)
+		for _, line := range record.Stderr {
+			fmt.Fprintf(&out, "  %s\n", line)
+		}
+	}
+	return out.Bytes(), nil
+}
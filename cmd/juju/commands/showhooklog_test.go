@@ -0,0 +1,118 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package commands
+
+import (
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/testing"
+)
+
+type ShowHookLogSuite struct {
+	testing.FakeJujuHomeSuite
+	api *fakeHookLogAPI
+}
+
+var _ = gc.Suite(&ShowHookLogSuite{})
+
+type fakeHookLogAPI struct {
+	unitName string
+	size     int
+	records  []params.HookRecord
+}
+
+func (f *fakeHookLogAPI) HookLog(unitName string, size int) ([]params.HookRecord, error) {
+	f.unitName = unitName
+	f.size = size
+	return f.records, nil
+}
+
+func (f *fakeHookLogAPI) Close() error {
+	return nil
+}
+
+func (s *ShowHookLogSuite) SetUpTest(c *gc.C) {
+	s.FakeJujuHomeSuite.SetUpTest(c)
+	started := time.Date(2015, 11, 3, 10, 30, 0, 0, time.UTC)
+	s.api = &fakeHookLogAPI{
+		records: []params.HookRecord{{
+			Hook:       "install",
+			RelationId: -1,
+			Started:    started,
+			Duration:   2 * time.Second,
+		}, {
+			Hook:       "db-relation-changed",
+			RelationId: 3,
+			RemoteUnit: "mysql/0",
+			Started:    started.Add(time.Minute),
+			Duration:   500 * time.Millisecond,
+			ExitStatus: 1,
+			Stderr:     []string{"no password yet"},
+		}},
+	}
+	s.PatchValue(&newHookLogAPI, func(_ *showHookLogCommand) (hookLogAPI, error) {
+		return s.api, nil
+	})
+}
+
+func (s *ShowHookLogSuite) TestInitErrors(c *gc.C) {
+	for i, test := range []struct {
+		args []string
+		err  string
+	}{{
+		err: "no unit specified",
+	}, {
+		args: []string{"wordpress"},
+		err:  `invalid unit name "wordpress"`,
+	}, {
+		args: []string{"wordpress/0", "mysql/0"},
+		err:  `unrecognized args: \["mysql/0"\]`,
+	}, {
+		args: []string{"wordpress/0", "-n", "0"},
+		err:  "invalid number of hook runs 0",
+	}} {
+		c.Logf("test %d: %v", i, test.args)
+		err := testing.InitCommand(newShowHookLogCommand(), test.args)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
+
+func (s *ShowHookLogSuite) TestShowTabular(c *gc.C) {
+	ctx, err := testing.RunCommand(c, newShowHookLogCommand(), "wordpress/0")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(s.api.unitName, gc.Equals, "wordpress/0")
+	c.Check(s.api.size, gc.Equals, 20)
+	c.Check(testing.Stdout(ctx), gc.Equals, `
+STARTED              HOOK                RELATION REMOTE  DURATION EXIT
+2015-11-03T10:30:00Z install                              2s       0
+2015-11-03T10:31:00Z db-relation-changed 3        mysql/0 500ms    1
+
+2015-11-03T10:31:00Z db-relation-changed stderr:
+  no password yet
+`[1:])
+}
+
+func (s *ShowHookLogSuite) TestShowYAML(c *gc.C) {
+	ctx, err := testing.RunCommand(c, newShowHookLogCommand(), "wordpress/0", "-n", "2", "--format", "yaml")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(s.api.size, gc.Equals, 2)
+	c.Check(testing.Stdout(ctx), gc.Equals, `
+- hook: install
+  started: "2015-11-03T10:30:00Z"
+  duration: 2s
+  exit-status: 0
+- hook: db-relation-changed
+  relation-id: 3
+  remote-unit: mysql/0
+  started: "2015-11-03T10:31:00Z"
+  duration: 500ms
+  exit-status: 1
+  stderr:
+  - no password yet
+`[1:])
+}
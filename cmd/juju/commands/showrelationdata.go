@@ -0,0 +1,134 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package commands
+
+import (
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/cmd/juju/block"
+)
+
+const showRelationDataDoc = `
+show-relation-data shows the settings every unit in scope of a relation
+has published on it; these are the settings the units' relation-get
+hook tool reads. A peer relation is specified by a single endpoint.
+
+With --set, the settings of the unit given by --unit are changed instead,
+and the units on the other side of the relation run their
+relation-changed hook. A setting with an empty value is removed. Only
+controller administrators may change relation settings.
+
+Examples:
+
+    juju show-relation-data wordpress:db mysql:db
+    juju show-relation-data --format json wordpress mysql
+    juju show-relation-data mysql:db wordpress:db --unit mysql/0 --set password=secret
+
+See Also:
+    juju help add-relation
+`
+
+// relationDataAPI defines the API methods used by the
+// show-relation-data command.
+type relationDataAPI interface {
+	RelationData(endpoints ...string) (params.RelationDataResults, error)
+	SetRelationData(endpoints []string, unit string, settings map[string]string) error
+	Close() error
+}
+
+var newRelationDataAPI = func(c *showRelationDataCommand) (relationDataAPI, error) {
+	return c.NewAPIClient()
+}
+
+func newShowRelationDataCommand() cmd.Command {
+	return envcmd.Wrap(&showRelationDataCommand{})
+}
+
+// showRelationDataCommand shows or changes the settings units have
+// published on a relation.
+type showRelationDataCommand struct {
+	envcmd.EnvCommandBase
+	out       cmd.Output
+	Endpoints []string
+	Unit      string
+	Settings  map[string]string
+}
+
+func (c *showRelationDataCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "show-relation-data",
+		Args:    "<service1>[:<relation name1>] [<service2>[:<relation name2>]]",
+		Purpose: "show or change the settings units have published on a relation",
+		Doc:     showRelationDataDoc,
+	}
+}
+
+func (c *showRelationDataCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.out.AddFlags(f, "yaml", map[string]cmd.Formatter{
+		"yaml": cmd.FormatYaml,
+		"json": cmd.FormatJson,
+	})
+	f.StringVar(&c.Unit, "unit", "", "the unit whose settings are changed with --set")
+	f.Var(keyValuesFlag{&c.Settings}, "set", "change a relation setting of the unit, as key=value")
+}
+
+func (c *showRelationDataCommand) Init(args []string) error {
+	if len(args) == 0 || len(args) > 2 {
+		return errors.New("a relation must be specified by one or two endpoints")
+	}
+	c.Endpoints = args
+	switch {
+	case len(c.Settings) > 0 && c.Unit == "":
+		return errors.New("--set requires --unit")
+	case len(c.Settings) == 0 && c.Unit != "":
+		return errors.New("--unit is only used with --set")
+	case c.Unit != "" && !names.IsValidUnit(c.Unit):
+		return errors.Errorf("invalid unit name %q", c.Unit)
+	}
+	return nil
+}
+
+// formattedRelationData is the output format of show-relation-data.
+type formattedRelationData struct {
+	Relation string                                   `yaml:"relation" json:"relation"`
+	Units    map[string]formattedRelationUnitSettings `yaml:"units,omitempty" json:"units,omitempty"`
+}
+
+type formattedRelationUnitSettings struct {
+	Endpoint string                 `yaml:"endpoint" json:"endpoint"`
+	Settings map[string]interface{} `yaml:"settings,omitempty" json:"settings,omitempty"`
+}
+
+func (c *showRelationDataCommand) Run(ctx *cmd.Context) error {
+	apiclient, err := newRelationDataAPI(c)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer apiclient.Close()
+
+	if len(c.Settings) > 0 {
+		err := apiclient.SetRelationData(c.Endpoints, c.Unit, c.Settings)
+		return block.ProcessBlockedError(err, block.BlockChange)
+	}
+	result, err := apiclient.RelationData(c.Endpoints...)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	formatted := formattedRelationData{Relation: result.Key}
+	if len(result.Units) > 0 {
+		formatted.Units = make(map[string]formattedRelationUnitSettings)
+	}
+	for _, unit := range result.Units {
+		formatted.Units[unit.Unit] = formattedRelationUnitSettings{
+			Endpoint: unit.Endpoint,
+			Settings: unit.Settings,
+		}
+	}
+	return c.out.Write(ctx, formatted)
+}
@@ -0,0 +1,124 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package commands
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/testing"
+)
+
+type ShowRelationDataSuite struct {
+	testing.FakeJujuHomeSuite
+	api *fakeRelationDataAPI
+}
+
+var _ = gc.Suite(&ShowRelationDataSuite{})
+
+type fakeRelationDataAPI struct {
+	endpoints []string
+	unit      string
+	settings  map[string]string
+	result    params.RelationDataResults
+}
+
+func (f *fakeRelationDataAPI) RelationData(endpoints ...string) (params.RelationDataResults, error) {
+	f.endpoints = endpoints
+	return f.result, nil
+}
+
+func (f *fakeRelationDataAPI) SetRelationData(endpoints []string, unit string, settings map[string]string) error {
+	f.endpoints = endpoints
+	f.unit = unit
+	f.settings = settings
+	return nil
+}
+
+func (f *fakeRelationDataAPI) Close() error {
+	return nil
+}
+
+func (s *ShowRelationDataSuite) SetUpTest(c *gc.C) {
+	s.FakeJujuHomeSuite.SetUpTest(c)
+	s.api = &fakeRelationDataAPI{
+		result: params.RelationDataResults{
+			Key: "wordpress:db mysql:server",
+			Units: []params.RelationUnitSettings{{
+				Unit:     "mysql/0",
+				Endpoint: "mysql:server",
+				Settings: map[string]interface{}{"password": "secret", "user": "wp"},
+			}, {
+				Unit:     "wordpress/0",
+				Endpoint: "wordpress:db",
+			}},
+		},
+	}
+	s.PatchValue(&newRelationDataAPI, func(_ *showRelationDataCommand) (relationDataAPI, error) {
+		return s.api, nil
+	})
+}
+
+func (s *ShowRelationDataSuite) TestInitErrors(c *gc.C) {
+	for i, test := range []struct {
+		args []string
+		err  string
+	}{{
+		err: "a relation must be specified by one or two endpoints",
+	}, {
+		args: []string{"a", "b", "c"},
+		err:  "a relation must be specified by one or two endpoints",
+	}, {
+		args: []string{"wordpress", "mysql", "--set", "user=wp"},
+		err:  "--set requires --unit",
+	}, {
+		args: []string{"wordpress", "mysql", "--unit", "mysql/0"},
+		err:  "--unit is only used with --set",
+	}, {
+		args: []string{"wordpress", "mysql", "--unit", "mysql", "--set", "user=wp"},
+		err:  `invalid unit name "mysql"`,
+	}, {
+		args: []string{"wordpress", "mysql", "--unit", "mysql/0", "--set", "user"},
+		err:  `invalid value "user" for flag --set: expected "key=value", got "user"`,
+	}} {
+		c.Logf("test %d: %v", i, test.args)
+		err := testing.InitCommand(newShowRelationDataCommand(), test.args)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
+
+func (s *ShowRelationDataSuite) TestShow(c *gc.C) {
+	ctx, err := testing.RunCommand(c, newShowRelationDataCommand(), "wordpress:db", "mysql")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(s.api.endpoints, jc.DeepEquals, []string{"wordpress:db", "mysql"})
+	c.Check(testing.Stdout(ctx), gc.Equals, `
+relation: wordpress:db mysql:server
+units:
+  mysql/0:
+    endpoint: mysql:server
+    settings:
+      password: secret
+      user: wp
+  wordpress/0:
+    endpoint: wordpress:db
+`[1:])
+}
+
+func (s *ShowRelationDataSuite) TestShowJSON(c *gc.C) {
+	s.api.result.Units = nil
+	ctx, err := testing.RunCommand(c, newShowRelationDataCommand(), "--format", "json", "wordpress", "mysql")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(testing.Stdout(ctx), gc.Equals, `{"relation":"wordpress:db mysql:server"}`+"\n")
+}
+
+func (s *ShowRelationDataSuite) TestSet(c *gc.C) {
+	ctx, err := testing.RunCommand(c, newShowRelationDataCommand(),
+		"wordpress", "mysql", "--unit", "mysql/0", "--set", "password=secret", "--set", "user=")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(testing.Stdout(ctx), gc.Equals, "")
+	c.Check(s.api.endpoints, jc.DeepEquals, []string{"wordpress", "mysql"})
+	c.Check(s.api.unit, gc.Equals, "mysql/0")
+	c.Check(s.api.settings, jc.DeepEquals, map[string]string{"password": "secret", "user": ""})
+}
@@ -18,6 +18,7 @@ import (
 	"launchpad.net/gnuflag"
 
 	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/cmd/juju/common"
 	"github.com/juju/juju/environs/config"
 )
 
@@ -28,6 +29,7 @@ func newSSHCommand() cmd.Command {
 // sshCommand is responsible for launching a ssh shell on a given unit or machine.
 type sshCommand struct {
 	SSHCommon
+	selector string
 }
 
 // SSHCommon provides common methods for sshCommand, SCPCommand and DebugHooksCommand.
@@ -87,6 +89,14 @@ Connect to the first mysql unit and run 'ls -la /var/log/juju':
 Connect to the first jenkins unit as the user jenkins:
 
     juju ssh jenkins@jenkins/0
+
+Instead of a target, a label selector may be given with --selector (-l);
+it must match exactly one unit. Any parameters are then passed to the ssh
+command. See "juju help set-labels" for details of the selector syntax.
+
+Connect to the unit labelled env=canary and run 'uptime':
+
+    juju ssh -l env=canary uptime
 `
 
 func (c *sshCommand) Info() *cmd.Info {
@@ -98,7 +108,17 @@ func (c *sshCommand) Info() *cmd.Info {
 	}
 }
 
+func (c *sshCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.SSHCommon.SetFlags(f)
+	f.StringVar(&c.selector, "l", "", "connect to the unit whose labels match the selector")
+	f.StringVar(&c.selector, "selector", "", "")
+}
+
 func (c *sshCommand) Init(args []string) error {
+	if c.selector != "" {
+		c.Args = args
+		return nil
+	}
 	if len(args) == 0 {
 		return fmt.Errorf("no target name specified")
 	}
@@ -106,6 +126,20 @@ func (c *sshCommand) Init(args []string) error {
 	return nil
 }
 
+// selectTarget sets the target to the single unit matching the
+// selector.
+func (c *sshCommand) selectTarget() error {
+	units, err := common.SelectUnits(&c.EnvCommandBase, c.selector)
+	if err != nil {
+		return err
+	}
+	if len(units) > 1 {
+		return fmt.Errorf("selector %q matches more than one unit: %s", c.selector, strings.Join(units, ", "))
+	}
+	c.Target = units[0]
+	return nil
+}
+
 // getJujuExecutable returns the path to the juju
 // executable, or an error if it could not be found.
 var getJujuExecutable = func() (string, error) {
@@ -146,6 +180,11 @@ func (c *sshCommand) Run(ctx *cmd.Context) error {
 			}
 		}()
 	}
+	if c.selector != "" {
+		if err := c.selectTarget(); err != nil {
+			return err
+		}
+	}
 	options, err := c.getSSHOptions(c.pty)
 	if err != nil {
 		return err
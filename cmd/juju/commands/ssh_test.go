@@ -142,6 +142,54 @@ func (s *SSHSuite) TestSSHCommandEnvironProxySSH(c *gc.C) {
 	c.Check(strings.TrimRight(ctx.Stdout.(*bytes.Buffer).String(), "\r\n"), gc.Equals, sshArgsNoProxy+"ubuntu@dummyenv-0.dns")
 }
 
+func (s *SSHSuite) TestSSHCommandSelector(c *gc.C) {
+	m := s.makeMachines(2, c, true)
+	ch := testcharms.Repo.CharmDir("dummy")
+	curl := charm.MustParseURL(
+		fmt.Sprintf("local:quantal/%s-%d", ch.Meta().Name, ch.Revision()),
+	)
+	dummy, err := s.State.AddCharm(ch, curl, "dummy-path", "dummy-1-sha256")
+	c.Assert(err, jc.ErrorIsNil)
+	srv := s.AddTestingService(c, "mongodb", dummy)
+	s.addUnit(srv, m[0], c)
+	s.addUnit(srv, m[1], c)
+	canary, err := s.State.Unit("mongodb/1")
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.SetLabels(canary, map[string]string{"env": "canary"})
+	c.Assert(err, jc.ErrorIsNil)
+
+	for i, t := range []struct {
+		args   []string
+		code   int
+		stdout string
+		stderr string
+	}{{
+		args:   []string{"ssh", "-l", "env=canary", "ls", "/"},
+		stdout: sshArgs + "ubuntu@dummyenv-1.internal ls /",
+	}, {
+		args:   []string{"ssh", "--selector", "env!=canary"},
+		stdout: sshArgs + "ubuntu@dummyenv-0.internal",
+	}, {
+		args:   []string{"ssh", "-l", "env=prod"},
+		code:   1,
+		stderr: `error: no units match selector "env=prod"` + "\n",
+	}, {
+		args:   []string{"ssh", "-l", "!tier"},
+		code:   1,
+		stderr: `error: selector "!tier" matches more than one unit: mongodb/0, mongodb/1` + "\n",
+	}} {
+		c.Logf("test %d: %s", i, t.args)
+		ctx := coretesting.Context(c)
+		jujucmd := cmd.NewSuperCommand(cmd.SuperCommandParams{})
+		jujucmd.Register(newSSHCommand())
+
+		code := cmd.Main(jujucmd, ctx, t.args)
+		c.Check(code, gc.Equals, t.code)
+		c.Check(ctx.Stderr.(*bytes.Buffer).String(), gc.Equals, t.stderr)
+		c.Check(strings.TrimRight(ctx.Stdout.(*bytes.Buffer).String(), "\r\n"), gc.Equals, t.stdout)
+	}
+}
+
 func (s *SSHSuite) TestSSHWillWorkInUpgrade(c *gc.C) {
 	// Check the API client interface used by "juju ssh" against what
 	// the API server will allow during upgrades. Ensure that the API
@@ -17,6 +17,7 @@ import (
 
 	"github.com/juju/juju/api"
 	apiservice "github.com/juju/juju/api/service"
+	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/cmd/envcmd"
 	"github.com/juju/juju/cmd/juju/block"
 	"github.com/juju/juju/cmd/juju/service"
@@ -36,6 +37,11 @@ type upgradeCharmCommand struct {
 	SwitchURL   string
 	CharmPath   string
 	Revision    int // defaults to -1 (latest)
+	Rollback    bool
+	CanaryUnits int
+	BatchSize   int
+	WaitHealthy bool
+	Resume      bool
 }
 
 const upgradeCharmDoc = `
@@ -91,6 +97,31 @@ is determined by the contents of the charm at the specified path.
 number with --switch, give it in the charm URL, for instance "cs:wordpress-5"
 would specify revision number 5 of the wordpress charm.
 
+The --rollback flag changes the service's charm back to the one it used before
+its charm was last changed. Units run their upgrade-charm hooks with the
+restored charm. The rollback is refused if the restored charm would lose any
+of the service's storage or the endpoints of any of its relations. Rolling
+back twice returns the service to the charm it was rolled back from.
+
+--rollback cannot be combined with --switch, --path or --revision.
+
+By default all of the service's units upgrade to the new charm at once. The
+--units and --batch-size flags instead roll the new charm out a few units at a
+time. With --units N, only N units are upgraded before the rollout pauses, so
+the new charm can be tried out on them; "juju upgrade-charm <service> --resume"
+continues the rollout, in batches if --batch-size was given and otherwise with
+all the remaining units at once. With --batch-size N, units are upgraded N at a time,
+each batch once the previous one is running the new charm. With --wait-healthy,
+each batch must also report an active workload status (see status-set) first.
+The rollout pauses automatically if any upgraded unit goes into an error
+state; fix the unit and resume the rollout, or abandon it with --rollback.
+Units added during a rollout use the new charm. The progress of a rollout is
+shown in juju status.
+
+Example:
+
+    juju upgrade-charm web --units 2 --batch-size 10 --wait-healthy
+
 Use of the --force-units flag is not generally recommended; units upgraded while in an
 error state will not have upgrade-charm hooks executed, and may cause unexpected
 behavior.
@@ -112,6 +143,11 @@ func (c *upgradeCharmCommand) SetFlags(f *gnuflag.FlagSet) {
 	f.StringVar(&c.SwitchURL, "switch", "", "crossgrade to a different charm")
 	f.StringVar(&c.CharmPath, "path", "", "upgrade to a charm located at path")
 	f.IntVar(&c.Revision, "revision", -1, "explicit revision of current charm")
+	f.BoolVar(&c.Rollback, "rollback", false, "revert to the service's previous charm")
+	f.IntVar(&c.CanaryUnits, "units", 0, "upgrade only this many units, then pause the rollout")
+	f.IntVar(&c.BatchSize, "batch-size", 0, "upgrade this many units at a time")
+	f.BoolVar(&c.WaitHealthy, "wait-healthy", false, "wait for each batch of units to be active before upgrading the next")
+	f.BoolVar(&c.Resume, "resume", false, "resume a paused rollout of the service's charm")
 }
 
 func (c *upgradeCharmCommand) Init(args []string) error {
@@ -135,9 +171,33 @@ func (c *upgradeCharmCommand) Init(args []string) error {
 	if c.SwitchURL != "" && c.CharmPath != "" {
 		return fmt.Errorf("--switch and --path are mutually exclusive")
 	}
+	if c.Rollback && (c.SwitchURL != "" || c.CharmPath != "" || c.Revision != -1) {
+		return fmt.Errorf("--rollback cannot be used with --switch, --path or --revision")
+	}
+	if c.CanaryUnits < 0 {
+		return fmt.Errorf("invalid --units %d: must not be negative", c.CanaryUnits)
+	}
+	if c.BatchSize < 0 {
+		return fmt.Errorf("invalid --batch-size %d: must not be negative", c.BatchSize)
+	}
+	if c.WaitHealthy && !c.staged() {
+		return fmt.Errorf("--wait-healthy requires --units or --batch-size")
+	}
+	if c.Rollback && c.staged() {
+		return fmt.Errorf("--rollback cannot be used with --units or --batch-size")
+	}
+	if c.Resume && (c.Rollback || c.staged() || c.SwitchURL != "" || c.CharmPath != "" || c.Revision != -1) {
+		return fmt.Errorf("--resume cannot be used with other upgrade options")
+	}
 	return nil
 }
 
+// staged returns whether the new charm should be rolled out to the
+// service's units in stages.
+func (c *upgradeCharmCommand) staged() bool {
+	return c.CanaryUnits > 0 || c.BatchSize > 0
+}
+
 func (c *upgradeCharmCommand) newServiceAPIClient() (*apiservice.Client, error) {
 	root, err := c.NewAPIRoot()
 	if err != nil {
@@ -160,6 +220,20 @@ func (c *upgradeCharmCommand) Run(ctx *cmd.Context) error {
 		return err
 	}
 
+	if c.Resume {
+		err := serviceClient.ServiceResumeCharmRollout(c.ServiceName)
+		return block.ProcessBlockedError(err, block.BlockChange)
+	}
+
+	if c.Rollback {
+		curl, err := serviceClient.ServiceRollbackCharm(c.ServiceName, c.ForceUnits)
+		if err != nil {
+			return block.ProcessBlockedError(err, block.BlockChange)
+		}
+		ctx.Infof("Rolled back service %q to charm %q.", c.ServiceName, curl)
+		return nil
+	}
+
 	oldURL, err := serviceClient.ServiceGetCharmURL(c.ServiceName)
 	if err != nil {
 		return err
@@ -185,6 +259,16 @@ func (c *upgradeCharmCommand) Run(ctx *cmd.Context) error {
 		return block.ProcessBlockedError(err, block.BlockChange)
 	}
 
+	if c.staged() {
+		rollout := params.CharmRollout{
+			CanaryUnits: c.CanaryUnits,
+			BatchSize:   c.BatchSize,
+			WaitHealthy: c.WaitHealthy,
+		}
+		return block.ProcessBlockedError(
+			serviceClient.ServiceSetCharmStaged(c.ServiceName, addedURL.String(), c.ForceSeries, c.ForceUnits, rollout),
+			block.BlockChange)
+	}
 	return block.ProcessBlockedError(
 		serviceClient.ServiceSetCharm(c.ServiceName, addedURL.String(), c.ForceSeries, c.ForceUnits),
 		block.BlockChange)
@@ -125,6 +125,56 @@ func (s *UpgradeCharmErrorsSuite) TestSwitchAndPathFails(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, "--switch and --path are mutually exclusive")
 }
 
+func (s *UpgradeCharmErrorsSuite) TestRollbackWithOtherFlagsFails(c *gc.C) {
+	s.deployService(c)
+	for _, flag := range []string{"--switch=riak", "--path=foo", "--revision=2"} {
+		err := runUpgradeCharm(c, "riak", "--rollback", flag)
+		c.Check(err, gc.ErrorMatches, "--rollback cannot be used with --switch, --path or --revision")
+	}
+}
+
+func (s *UpgradeCharmErrorsSuite) TestRollbackWithoutPreviousCharmFails(c *gc.C) {
+	s.deployService(c)
+	err := runUpgradeCharm(c, "riak", "--rollback")
+	c.Assert(err, gc.ErrorMatches, `cannot roll back charm of service "riak": no previous charm`)
+}
+
+func (s *UpgradeCharmErrorsSuite) TestStagedUpgradeInvalidFlags(c *gc.C) {
+	s.deployService(c)
+	for i, test := range []struct {
+		args []string
+		err  string
+	}{{
+		args: []string{"--units=-1"},
+		err:  "invalid --units -1: must not be negative",
+	}, {
+		args: []string{"--batch-size=-1"},
+		err:  "invalid --batch-size -1: must not be negative",
+	}, {
+		args: []string{"--wait-healthy"},
+		err:  "--wait-healthy requires --units or --batch-size",
+	}, {
+		args: []string{"--rollback", "--batch-size=2"},
+		err:  "--rollback cannot be used with --units or --batch-size",
+	}, {
+		args: []string{"--resume", "--units=2"},
+		err:  "--resume cannot be used with other upgrade options",
+	}, {
+		args: []string{"--resume", "--revision=2"},
+		err:  "--resume cannot be used with other upgrade options",
+	}} {
+		c.Logf("test %d: %v", i, test.args)
+		err := runUpgradeCharm(c, append([]string{"riak"}, test.args...)...)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
+
+func (s *UpgradeCharmErrorsSuite) TestResumeWithoutRolloutFails(c *gc.C) {
+	s.deployService(c)
+	err := runUpgradeCharm(c, "riak", "--resume")
+	c.Assert(err, gc.ErrorMatches, `cannot resume charm rollout of service "riak": no charm rollout in progress`)
+}
+
 func (s *UpgradeCharmErrorsSuite) TestInvalidRevision(c *gc.C) {
 	s.deployService(c)
 	err := runUpgradeCharm(c, "riak", "--revision=blah")
@@ -285,6 +335,53 @@ func (s *UpgradeCharmSuccessSuite) TestBlockForcedUnitsUpgrade(c *gc.C) {
 	s.assertLocalRevision(c, 7, s.path)
 }
 
+func (s *UpgradeCharmSuccessSuite) TestRollback(c *gc.C) {
+	err := runUpgradeCharm(c, "riak")
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertUpgraded(c, 8, false)
+
+	err = runUpgradeCharm(c, "riak", "--rollback")
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertUpgraded(c, 7, false)
+
+	// Rolling back again returns to the charm rolled back from.
+	err = runUpgradeCharm(c, "riak", "--rollback", "--force-units")
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertUpgraded(c, 8, true)
+}
+
+func (s *UpgradeCharmSuccessSuite) TestBlockRollback(c *gc.C) {
+	err := runUpgradeCharm(c, "riak")
+	c.Assert(err, jc.ErrorIsNil)
+	// Block operation
+	s.BlockAllChanges(c, "TestBlockRollback")
+	err = runUpgradeCharm(c, "riak", "--rollback")
+	s.AssertBlocked(c, err, ".*TestBlockRollback.*")
+}
+
+func (s *UpgradeCharmSuccessSuite) TestStagedUpgrade(c *gc.C) {
+	err := runUpgradeCharm(c, "riak", "--units=1", "--batch-size=5", "--wait-healthy")
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertUpgraded(c, 8, false)
+	rollout, ok := s.riak.CharmRollout()
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(rollout.FromCharmURL.Revision, gc.Equals, 7)
+	c.Assert(rollout.CharmRolloutParams, jc.DeepEquals, state.CharmRolloutParams{
+		CanaryUnits: 1,
+		BatchSize:   5,
+		WaitHealthy: true,
+	})
+	c.Assert(rollout.Released, jc.DeepEquals, []string{"riak/0"})
+
+	err = runUpgradeCharm(c, "riak", "--resume")
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.riak.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	rollout, ok = s.riak.CharmRollout()
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(rollout.CanaryUnits, gc.Equals, 0)
+}
+
 var myriakMeta = []byte(`
 name: myriak
 summary: "K/V storage engine"
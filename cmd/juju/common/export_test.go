@@ -22,3 +22,19 @@ func NewSetConstraintsCommandWithAPI(api ConstraintsAPI) cmd.Command {
 		api: api,
 	})
 }
+
+// NewGetLabelsCommandWithAPI returns a GetLabelsCommand with the api
+// provided as specified.
+func NewGetLabelsCommandWithAPI(api LabelsAPI) cmd.Command {
+	return envcmd.Wrap(&GetLabelsCommand{
+		api: api,
+	})
+}
+
+// NewSetLabelsCommandWithAPI returns a SetLabelsCommand with the api
+// provided as specified.
+func NewSetLabelsCommandWithAPI(api LabelsAPI) cmd.Command {
+	return envcmd.Wrap(&SetLabelsCommand{
+		api: api,
+	})
+}
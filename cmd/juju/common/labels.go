@@ -0,0 +1,243 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package common
+
+import (
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"github.com/juju/utils/keyvalues"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/api/labels"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/envcmd"
+)
+
+const getLabelsDoc = `
+get-labels shows the labels set on a machine, service or unit. The labels
+of a unit include those inherited from its service.
+
+Examples:
+
+   juju get-labels 0              (labels of machine 0)
+   juju get-labels wordpress      (labels of the wordpress service)
+   juju get-labels wordpress/0    (labels of unit wordpress/0)
+
+See Also:
+   juju help set-labels
+`
+
+const setLabelsDoc = `
+set-labels sets labels on a machine, service or unit. A label with an
+empty value is removed. Units inherit the labels of their service; a
+label set on a unit overrides the service label with the same key.
+
+Label keys consist of letters, digits, '-' and '_', and must start and
+end with a letter or digit; values may also contain '.'. Both are at
+most 63 characters long.
+
+Labels are used to select entities with the -l (--selector) flag of
+commands such as "juju status", "juju run", "juju action do",
+"juju remove-unit" and "juju ssh". A selector is a comma-separated list
+of requirements, all of which must be met:
+
+   key=value    the label is set to value
+   key!=value   the label is not set to value, or is not set at all
+   key          the label is set
+   !key         the label is not set
+
+Examples:
+
+   juju set-labels wordpress tier=web
+   juju set-labels wordpress/0 env=canary
+   juju set-labels wordpress/0 env=       (removes the env label)
+   juju status -l tier=web,env!=canary
+
+See Also:
+   juju help get-labels
+`
+
+// LabelsAPI defines the methods on the labels API used by the
+// get-labels and set-labels commands, and by the commands taking
+// label selectors.
+type LabelsAPI interface {
+	Close() error
+	Get(tags []string) ([]params.LabelsGetResult, error)
+	Set(tag string, labels map[string]string) error
+	Match(selector string) (params.LabelMatchResult, error)
+}
+
+// NewLabelsAPI returns a client for the labels API of the command's
+// environment.
+var NewLabelsAPI = func(c *envcmd.EnvCommandBase) (LabelsAPI, error) {
+	root, err := c.NewAPIRoot()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return labels.NewClient(root), nil
+}
+
+// SelectUnits returns the names of the units whose labels match the
+// selector. It is an error if there are none.
+func SelectUnits(c *envcmd.EnvCommandBase, selector string) ([]string, error) {
+	api, err := NewLabelsAPI(c)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer api.Close()
+	result, err := api.Match(selector)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(result.Units) == 0 {
+		return nil, errors.Errorf("no units match selector %q", selector)
+	}
+	return result.Units, nil
+}
+
+// labelledEntityTag returns the tag of the machine, service or unit
+// named by the argument.
+func labelledEntityTag(arg string) (names.Tag, error) {
+	switch {
+	case names.IsValidMachine(arg):
+		return names.NewMachineTag(arg), nil
+	case names.IsValidUnit(arg):
+		return names.NewUnitTag(arg), nil
+	case names.IsValidService(arg):
+		return names.NewServiceTag(arg), nil
+	}
+	return nil, errors.Errorf("%q is not a valid machine, service or unit", arg)
+}
+
+// NewGetLabelsCommand returns a new command that shows the labels of a
+// machine, service or unit.
+func NewGetLabelsCommand() cmd.Command {
+	return envcmd.Wrap(&GetLabelsCommand{})
+}
+
+// GetLabelsCommand shows the labels of a machine, service or unit.
+type GetLabelsCommand struct {
+	envcmd.EnvCommandBase
+	Tag names.Tag
+	out cmd.Output
+	api LabelsAPI
+}
+
+func (c *GetLabelsCommand) getAPI() (LabelsAPI, error) {
+	if c.api != nil {
+		return c.api, nil
+	}
+	return NewLabelsAPI(&c.EnvCommandBase)
+}
+
+func (c *GetLabelsCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "get-labels",
+		Args:    "<machine|service|unit>",
+		Purpose: "view the labels of a machine, service or unit",
+		Doc:     getLabelsDoc,
+	}
+}
+
+func (c *GetLabelsCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.out.AddFlags(f, "yaml", map[string]cmd.Formatter{
+		"yaml": cmd.FormatYaml,
+		"json": cmd.FormatJson,
+	})
+}
+
+func (c *GetLabelsCommand) Init(args []string) (err error) {
+	if len(args) == 0 {
+		return errors.New("no machine, service or unit specified")
+	}
+	if c.Tag, err = labelledEntityTag(args[0]); err != nil {
+		return err
+	}
+	return cmd.CheckEmpty(args[1:])
+}
+
+func (c *GetLabelsCommand) Run(ctx *cmd.Context) error {
+	apiclient, err := c.getAPI()
+	if err != nil {
+		return err
+	}
+	defer apiclient.Close()
+
+	tags := []string{c.Tag.String()}
+	if unitTag, ok := c.Tag.(names.UnitTag); ok {
+		serviceName, err := names.UnitService(unitTag.Id())
+		if err != nil {
+			return errors.Trace(err)
+		}
+		tags = append([]string{names.NewServiceTag(serviceName).String()}, tags...)
+	}
+	results, err := apiclient.Get(tags)
+	if err != nil {
+		return err
+	}
+	result := make(map[string]string)
+	for _, r := range results {
+		if r.Error != nil {
+			return r.Error
+		}
+		for key, value := range r.Labels {
+			result[key] = value
+		}
+	}
+	return c.out.Write(ctx, result)
+}
+
+// NewSetLabelsCommand returns a new command that sets labels on a
+// machine, service or unit.
+func NewSetLabelsCommand() cmd.Command {
+	return envcmd.Wrap(&SetLabelsCommand{})
+}
+
+// SetLabelsCommand sets labels on a machine, service or unit.
+type SetLabelsCommand struct {
+	envcmd.EnvCommandBase
+	Tag    names.Tag
+	Labels map[string]string
+	api    LabelsAPI
+}
+
+func (c *SetLabelsCommand) getAPI() (LabelsAPI, error) {
+	if c.api != nil {
+		return c.api, nil
+	}
+	return NewLabelsAPI(&c.EnvCommandBase)
+}
+
+func (c *SetLabelsCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "set-labels",
+		Args:    "<machine|service|unit> key=[value] ...",
+		Purpose: "set labels on a machine, service or unit",
+		Doc:     setLabelsDoc,
+	}
+}
+
+func (c *SetLabelsCommand) Init(args []string) (err error) {
+	if len(args) == 0 {
+		return errors.New("no machine, service or unit specified")
+	}
+	if c.Tag, err = labelledEntityTag(args[0]); err != nil {
+		return err
+	}
+	if len(args) == 1 {
+		return errors.New("no labels specified")
+	}
+	c.Labels, err = keyvalues.Parse(args[1:], true)
+	return err
+}
+
+func (c *SetLabelsCommand) Run(_ *cmd.Context) error {
+	apiclient, err := c.getAPI()
+	if err != nil {
+		return err
+	}
+	defer apiclient.Close()
+	return apiclient.Set(c.Tag.String(), c.Labels)
+}
@@ -0,0 +1,135 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package common_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	cmdcommon "github.com/juju/juju/cmd/juju/common"
+	"github.com/juju/juju/testing"
+)
+
+type LabelsCommandsSuite struct {
+	testing.FakeJujuHomeSuite
+	fake *fakeLabelsClient
+}
+
+var _ = gc.Suite(&LabelsCommandsSuite{})
+
+type fakeLabelsClient struct {
+	labels map[string]map[string]string
+}
+
+func (f *fakeLabelsClient) Close() error {
+	return nil
+}
+
+func (f *fakeLabelsClient) Get(tags []string) ([]params.LabelsGetResult, error) {
+	results := make([]params.LabelsGetResult, len(tags))
+	for i, tag := range tags {
+		results[i] = params.LabelsGetResult{EntityTag: tag, Labels: f.labels[tag]}
+	}
+	return results, nil
+}
+
+func (f *fakeLabelsClient) Set(tag string, labels map[string]string) error {
+	if tag == "machine-42" {
+		return errors.New("permission denied")
+	}
+	if f.labels[tag] == nil {
+		f.labels[tag] = make(map[string]string)
+	}
+	for key, value := range labels {
+		if value == "" {
+			delete(f.labels[tag], key)
+		} else {
+			f.labels[tag][key] = value
+		}
+	}
+	return nil
+}
+
+func (f *fakeLabelsClient) Match(selector string) (params.LabelMatchResult, error) {
+	return params.LabelMatchResult{}, errors.NotImplementedf("Match")
+}
+
+func (s *LabelsCommandsSuite) SetUpTest(c *gc.C) {
+	s.FakeJujuHomeSuite.SetUpTest(c)
+	s.fake = &fakeLabelsClient{labels: make(map[string]map[string]string)}
+}
+
+func (s *LabelsCommandsSuite) TestSetLabels(c *gc.C) {
+	command := cmdcommon.NewSetLabelsCommandWithAPI(s.fake)
+	code, stdout, stderr := runCmdLine(c, command, "wordpress/0", "env=canary", "tier=web")
+	c.Assert(code, gc.Equals, 0)
+	c.Check(stdout, gc.Equals, "")
+	c.Check(stderr, gc.Equals, "")
+	c.Check(s.fake.labels, jc.DeepEquals, map[string]map[string]string{
+		"unit-wordpress-0": {"env": "canary", "tier": "web"},
+	})
+
+	command = cmdcommon.NewSetLabelsCommandWithAPI(s.fake)
+	code, _, _ = runCmdLine(c, command, "wordpress/0", "env=")
+	c.Assert(code, gc.Equals, 0)
+	c.Check(s.fake.labels, jc.DeepEquals, map[string]map[string]string{
+		"unit-wordpress-0": {"tier": "web"},
+	})
+}
+
+func (s *LabelsCommandsSuite) TestSetLabelsErrors(c *gc.C) {
+	for i, test := range []struct {
+		args   []string
+		code   int
+		stderr string
+	}{{
+		code:   2,
+		stderr: "no machine, service or unit specified",
+	}, {
+		args:   []string{"0"},
+		code:   2,
+		stderr: "no labels specified",
+	}, {
+		args:   []string{"wordpress/x", "env=canary"},
+		code:   2,
+		stderr: `"wordpress/x" is not a valid machine, service or unit`,
+	}, {
+		args:   []string{"0", "env"},
+		code:   2,
+		stderr: `expected "key=value", got "env"`,
+	}, {
+		args:   []string{"42", "env=canary"},
+		code:   1,
+		stderr: "permission denied",
+	}} {
+		c.Logf("test %d: %v", i, test.args)
+		command := cmdcommon.NewSetLabelsCommandWithAPI(s.fake)
+		code, _, stderr := runCmdLine(c, command, test.args...)
+		c.Check(code, gc.Equals, test.code)
+		c.Check(stderr, gc.Equals, "error: "+test.stderr+"\n")
+	}
+}
+
+func (s *LabelsCommandsSuite) TestGetLabels(c *gc.C) {
+	s.fake.labels["service-wordpress"] = map[string]string{"tier": "web", "env": "prod"}
+	s.fake.labels["unit-wordpress-0"] = map[string]string{"env": "canary"}
+
+	command := cmdcommon.NewGetLabelsCommandWithAPI(s.fake)
+	code, stdout, stderr := runCmdLine(c, command, "wordpress")
+	c.Assert(code, gc.Equals, 0)
+	c.Check(stderr, gc.Equals, "")
+	c.Check(stdout, gc.Equals, "env: prod\ntier: web\n")
+
+	command = cmdcommon.NewGetLabelsCommandWithAPI(s.fake)
+	code, stdout, _ = runCmdLine(c, command, "--format", "json", "wordpress/0")
+	c.Assert(code, gc.Equals, 0)
+	c.Check(stdout, gc.Equals, `{"env":"canary","tier":"web"}`+"\n")
+
+	command = cmdcommon.NewGetLabelsCommandWithAPI(s.fake)
+	code, stdout, _ = runCmdLine(c, command, "0")
+	c.Assert(code, gc.Equals, 0)
+	c.Check(stdout, gc.Equals, "{}\n")
+}
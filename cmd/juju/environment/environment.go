@@ -33,11 +33,13 @@ func NewSuperCommand() cmd.Command {
 	environmentCmd.Register(newRetryProvisioningCommand())
 	environmentCmd.Register(newEnvSetConstraintsCommand())
 	environmentCmd.Register(newEnvGetConstraintsCommand())
+	environmentCmd.Register(newGrantCommand())
+	environmentCmd.Register(newRevokeCommand())
+	environmentCmd.Register(newUsersCommand())
 
 	if featureflag.Enabled(feature.JES) {
 		environmentCmd.Register(newShareCommand())
 		environmentCmd.Register(newUnshareCommand())
-		environmentCmd.Register(newUsersCommand())
 		environmentCmd.Register(newDestroyCommand())
 	}
 	return environmentCmd
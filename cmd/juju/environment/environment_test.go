@@ -26,9 +26,11 @@ var expectedCommmandNames = []string{
 	"destroy",
 	"get",
 	"get-constraints",
+	"grant",
 	"help",
 	"jenv",
 	"retry-provisioning",
+	"revoke",
 	"set",
 	"set-constraints",
 	"share",
@@ -47,7 +49,7 @@ func (s *EnvironmentCommandSuite) TestHelpCommands(c *gc.C) {
 
 	// Remove "share" for the first test because the feature is not
 	// enabled.
-	devFeatures := set.NewStrings("destroy", "share", "unshare", "users")
+	devFeatures := set.NewStrings("destroy", "share", "unshare")
 
 	// Remove features behind dev_flag for the first test since they are not
 	// enabled.
@@ -70,6 +70,30 @@ func NewUnshareCommand(api UnshareEnvironmentAPI) (cmd.Command, *UnshareCommand)
 	return envcmd.Wrap(cmd), &UnshareCommand{cmd}
 }
 
+type GrantCommand struct {
+	*grantCommand
+}
+
+// NewGrantCommand returns a grantCommand with the api provided as specified.
+func NewGrantCommand(api GrantEnvironmentAPI) (cmd.Command, *GrantCommand) {
+	cmd := &grantCommand{
+		api: api,
+	}
+	return envcmd.Wrap(cmd), &GrantCommand{cmd}
+}
+
+type RevokeCommand struct {
+	*revokeCommand
+}
+
+// NewRevokeCommand returns a revokeCommand with the api provided as specified.
+func NewRevokeCommand(api RevokeEnvironmentAPI) (cmd.Command, *RevokeCommand) {
+	cmd := &revokeCommand{
+		api: api,
+	}
+	return envcmd.Wrap(cmd), &RevokeCommand{cmd}
+}
+
 // NewUsersCommand returns a UsersCommand with the api provided as specified.
 func NewUsersCommand(api UsersAPI) cmd.Command {
 	cmd := &usersCommand{
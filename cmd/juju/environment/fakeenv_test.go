@@ -32,6 +32,7 @@ type fakeEnvAPI struct {
 	keys        []string
 	addUsers    []names.UserTag
 	removeUsers []names.UserTag
+	access      string
 }
 
 func (f *fakeEnvAPI) Close() error {
@@ -61,3 +62,15 @@ func (f *fakeEnvAPI) UnshareEnvironment(users ...names.UserTag) error {
 	f.removeUsers = users
 	return f.err
 }
+
+func (f *fakeEnvAPI) GrantEnvironment(access string, users ...names.UserTag) error {
+	f.access = access
+	f.addUsers = users
+	return f.err
+}
+
+func (f *fakeEnvAPI) RevokeEnvironment(access string, users ...names.UserTag) error {
+	f.access = access
+	f.removeUsers = users
+	return f.err
+}
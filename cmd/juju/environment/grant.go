@@ -0,0 +1,136 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package environment
+
+import (
+	"strings"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/cmd/juju/block"
+)
+
+const grantEnvHelpDoc = `
+Grant users access to the current environment, sharing it with them if it
+is not already.
+
+Access levels, each of which allows everything the ones before it do, are:
+ viewer     look at the environment, but not change it
+ operator   run commands and actions, and mark unit errors resolved
+ deployer   deploy, configure, relate, scale and remove services
+ admin      anything, including changing who has access
+
+Granting never lowers a user's access; use "juju environment revoke" for
+that.
+
+Examples:
+ juju environment grant joe operator
+     Let local user "joe" run actions and resolve errors in the current
+     environment
+
+ juju environment grant user1 user2@ubuntuone deployer
+     Let a local user and a remote user deploy services to the current
+     environment
+
+ juju environment grant sam viewer -e myenv
+     Let local user "sam" look at the environment named "myenv"
+`
+
+// accessLevels holds the access levels that can be granted or revoked.
+var accessLevels = []string{
+	params.EnvViewerAccess,
+	params.EnvOperatorAccess,
+	params.EnvDeployerAccess,
+	params.EnvAdminAccess,
+}
+
+// parseAccessArgs parses arguments of the form <user> ... <access>.
+func parseAccessArgs(args []string) ([]names.UserTag, string, error) {
+	if len(args) == 0 {
+		return nil, "", errors.New("no users specified")
+	}
+	access := args[len(args)-1]
+	valid := false
+	for _, level := range accessLevels {
+		if access == level {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return nil, "", errors.Errorf("invalid access level %q, expected one of %s", access, strings.Join(accessLevels, ", "))
+	}
+	if len(args) == 1 {
+		return nil, "", errors.New("no users specified")
+	}
+	var users []names.UserTag
+	for _, arg := range args[:len(args)-1] {
+		if !names.IsValidUser(arg) {
+			return nil, "", errors.Errorf("invalid username: %q", arg)
+		}
+		users = append(users, names.NewUserTag(arg))
+	}
+	return users, access, nil
+}
+
+func newGrantCommand() cmd.Command {
+	return envcmd.Wrap(&grantCommand{})
+}
+
+// grantCommand grants users access to an environment.
+type grantCommand struct {
+	envcmd.EnvCommandBase
+	api GrantEnvironmentAPI
+
+	// Users to grant access to.
+	Users []names.UserTag
+
+	// Access is the access level to grant.
+	Access string
+}
+
+// Info implements Command.Info.
+func (c *grantCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "grant",
+		Args:    "<user> ... <access>",
+		Purpose: "grant users access to the current environment",
+		Doc:     strings.TrimSpace(grantEnvHelpDoc),
+	}
+}
+
+// Init implements Command.Init.
+func (c *grantCommand) Init(args []string) (err error) {
+	c.Users, c.Access, err = parseAccessArgs(args)
+	return err
+}
+
+func (c *grantCommand) getAPI() (GrantEnvironmentAPI, error) {
+	if c.api != nil {
+		return c.api, nil
+	}
+	return c.NewAPIClient()
+}
+
+// GrantEnvironmentAPI defines the API functions used by the environment
+// grant command.
+type GrantEnvironmentAPI interface {
+	Close() error
+	GrantEnvironment(access string, users ...names.UserTag) error
+}
+
+// Run implements Command.Run.
+func (c *grantCommand) Run(ctx *cmd.Context) error {
+	client, err := c.getAPI()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	return block.ProcessBlockedError(client.GrantEnvironment(c.Access, c.Users...), block.BlockChange)
+}
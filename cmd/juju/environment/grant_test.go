@@ -0,0 +1,65 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package environment_test
+
+import (
+	"github.com/juju/cmd"
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/juju/environment"
+	"github.com/juju/juju/testing"
+)
+
+type grantSuite struct {
+	fakeEnvSuite
+}
+
+var _ = gc.Suite(&grantSuite{})
+
+func (s *grantSuite) run(c *gc.C, args ...string) (*cmd.Context, error) {
+	command, _ := environment.NewGrantCommand(s.fake)
+	return testing.RunCommand(c, command, args...)
+}
+
+func (s *grantSuite) TestInit(c *gc.C) {
+	wrappedCommand, grantCmd := environment.NewGrantCommand(s.fake)
+	err := testing.InitCommand(wrappedCommand, []string{})
+	c.Assert(err, gc.ErrorMatches, "no users specified")
+
+	err = testing.InitCommand(wrappedCommand, []string{"operator"})
+	c.Assert(err, gc.ErrorMatches, "no users specified")
+
+	err = testing.InitCommand(wrappedCommand, []string{"bob", "superuser"})
+	c.Assert(err, gc.ErrorMatches, `invalid access level "superuser", expected one of viewer, operator, deployer, admin`)
+
+	err = testing.InitCommand(wrappedCommand, []string{"not valid/0", "viewer"})
+	c.Assert(err, gc.ErrorMatches, `invalid username: "not valid/0"`)
+
+	err = testing.InitCommand(wrappedCommand, []string{"bob@local", "sam", "operator"})
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Assert(grantCmd.Users, jc.DeepEquals, []names.UserTag{
+		names.NewUserTag("bob@local"), names.NewUserTag("sam"),
+	})
+	c.Assert(grantCmd.Access, gc.Equals, "operator")
+}
+
+func (s *grantSuite) TestPassesValues(c *gc.C) {
+	_, err := s.run(c, "sam", "ralph", "deployer")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.fake.addUsers, jc.DeepEquals, []names.UserTag{
+		names.NewUserTag("sam"), names.NewUserTag("ralph"),
+	})
+	c.Assert(s.fake.access, gc.Equals, "deployer")
+}
+
+func (s *grantSuite) TestBlockGrant(c *gc.C) {
+	s.fake.err = &params.Error{Code: params.CodeOperationBlocked}
+	_, err := s.run(c, "sam", "viewer")
+	c.Assert(err, gc.Equals, cmd.ErrSilent)
+	c.Check(c.GetTestLog(), jc.Contains, "To unblock changes")
+}
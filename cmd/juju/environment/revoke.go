@@ -0,0 +1,92 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package environment
+
+import (
+	"strings"
+
+	"github.com/juju/cmd"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/cmd/juju/block"
+)
+
+const revokeEnvHelpDoc = `
+Revoke an access level from users of the current environment, leaving
+them with the level below it. Revoking viewer access stops sharing the
+environment with the users altogether.
+
+See "juju environment grant --help" for the access levels.
+
+Examples:
+ juju environment revoke joe deployer
+     Stop local user "joe" deploying services to the current environment,
+     leaving them with operator access
+
+ juju environment revoke user1 user2@ubuntuone viewer
+     Deny a local user and a remote user all access to the current
+     environment
+
+ juju environment revoke sam admin -e myenv
+     Take administrator access to the environment named "myenv" away
+     from local user "sam"
+`
+
+func newRevokeCommand() cmd.Command {
+	return envcmd.Wrap(&revokeCommand{})
+}
+
+// revokeCommand revokes users' access to an environment.
+type revokeCommand struct {
+	envcmd.EnvCommandBase
+	api RevokeEnvironmentAPI
+
+	// Users to revoke access from.
+	Users []names.UserTag
+
+	// Access is the access level to revoke.
+	Access string
+}
+
+// Info implements Command.Info.
+func (c *revokeCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "revoke",
+		Args:    "<user> ... <access>",
+		Purpose: "revoke users' access to the current environment",
+		Doc:     strings.TrimSpace(revokeEnvHelpDoc),
+	}
+}
+
+// Init implements Command.Init.
+func (c *revokeCommand) Init(args []string) (err error) {
+	c.Users, c.Access, err = parseAccessArgs(args)
+	return err
+}
+
+func (c *revokeCommand) getAPI() (RevokeEnvironmentAPI, error) {
+	if c.api != nil {
+		return c.api, nil
+	}
+	return c.NewAPIClient()
+}
+
+// RevokeEnvironmentAPI defines the API functions used by the environment
+// revoke command.
+type RevokeEnvironmentAPI interface {
+	Close() error
+	RevokeEnvironment(access string, users ...names.UserTag) error
+}
+
+// Run implements Command.Run.
+func (c *revokeCommand) Run(ctx *cmd.Context) error {
+	client, err := c.getAPI()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	return block.ProcessBlockedError(client.RevokeEnvironment(c.Access, c.Users...), block.BlockChange)
+}
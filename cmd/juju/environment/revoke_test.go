@@ -0,0 +1,53 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package environment_test
+
+import (
+	"github.com/juju/cmd"
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/juju/environment"
+	"github.com/juju/juju/testing"
+)
+
+type revokeSuite struct {
+	fakeEnvSuite
+}
+
+var _ = gc.Suite(&revokeSuite{})
+
+func (s *revokeSuite) run(c *gc.C, args ...string) (*cmd.Context, error) {
+	command, _ := environment.NewRevokeCommand(s.fake)
+	return testing.RunCommand(c, command, args...)
+}
+
+func (s *revokeSuite) TestInit(c *gc.C) {
+	wrappedCommand, revokeCmd := environment.NewRevokeCommand(s.fake)
+	err := testing.InitCommand(wrappedCommand, []string{"bob"})
+	c.Assert(err, gc.ErrorMatches, `invalid access level "bob", expected one of viewer, operator, deployer, admin`)
+
+	err = testing.InitCommand(wrappedCommand, []string{"bob", "admin"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(revokeCmd.Users, jc.DeepEquals, []names.UserTag{names.NewUserTag("bob")})
+	c.Assert(revokeCmd.Access, gc.Equals, "admin")
+}
+
+func (s *revokeSuite) TestPassesValues(c *gc.C) {
+	_, err := s.run(c, "sam", "ralph", "viewer")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.fake.removeUsers, jc.DeepEquals, []names.UserTag{
+		names.NewUserTag("sam"), names.NewUserTag("ralph"),
+	})
+	c.Assert(s.fake.access, gc.Equals, "viewer")
+}
+
+func (s *revokeSuite) TestBlockRevoke(c *gc.C) {
+	s.fake.err = &params.Error{Code: params.CodeOperationBlocked}
+	_, err := s.run(c, "sam", "operator")
+	c.Assert(err, gc.Equals, cmd.ErrSilent)
+	c.Check(c.GetTestLog(), jc.Contains, "To unblock changes")
+}
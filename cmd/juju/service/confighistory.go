@@ -0,0 +1,269 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/api/service"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/cmd/juju/block"
+)
+
+// ConfigHistoryAPI defines the methods on the service API
+// that the config-history and config-rollback commands call.
+type ConfigHistoryAPI interface {
+	ServiceConfigHistory(serviceName string) ([]params.ServiceConfigRevision, error)
+	ServiceConfigRollback(serviceName string, revision int) error
+}
+
+// configHistoryAPI returns the API the command should use, and a
+// function that releases it.
+func configHistoryAPI(c *envcmd.EnvCommandBase, api ConfigHistoryAPI) (ConfigHistoryAPI, func() error, error) {
+	if api != nil {
+		return api, func() error { return nil }, nil
+	}
+	root, err := c.NewAPIRoot()
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	return service.NewClient(root), root.Close, nil
+}
+
+func newConfigHistoryCommand() cmd.Command {
+	return envcmd.Wrap(&configHistoryCommand{})
+}
+
+// configHistoryCommand shows the recorded changes to a service's
+// configuration.
+type configHistoryCommand struct {
+	envcmd.EnvCommandBase
+	ServiceName string
+	out         cmd.Output
+	api         ConfigHistoryAPI
+}
+
+const configHistoryDoc = `
+Show the history of changes made to the configuration of <service>, oldest
+first. Each revision records when the change was made, by whom, and the
+service's charm at the time. Revision 1 holds the configuration the service
+had before it was first changed.
+
+In the default tabular format, each revision is followed by the settings that
+changed since the previous revision:
+
+    + name: value          the setting was added
+    - name: value          the setting was removed (reset to its default)
+    ~ name: old -> new     the setting's value was changed
+
+Any revision can be restored with "juju config-rollback" or "juju set
+--revision".
+
+Example:
+
+    juju config-history wordpress
+
+See Also:
+   juju help config-rollback
+   juju help set
+`
+
+func (c *configHistoryCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "config-history",
+		Args:    "<service>",
+		Purpose: "show the history of a service's configuration",
+		Doc:     configHistoryDoc,
+	}
+}
+
+func (c *configHistoryCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.out.AddFlags(f, "tabular", map[string]cmd.Formatter{
+		"yaml":    cmd.FormatYaml,
+		"json":    cmd.FormatJson,
+		"tabular": formatConfigHistoryTabular,
+	})
+}
+
+func (c *configHistoryCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return errors.New("no service name specified")
+	}
+	c.ServiceName = args[0]
+	return cmd.CheckEmpty(args[1:])
+}
+
+// Run shows the service's config history.
+func (c *configHistoryCommand) Run(ctx *cmd.Context) error {
+	api, closer, err := configHistoryAPI(&c.EnvCommandBase, c.api)
+	if err != nil {
+		return err
+	}
+	defer closer()
+
+	history, err := api.ServiceConfigHistory(c.ServiceName)
+	if err != nil {
+		return err
+	}
+	revisions := make([]configRevision, len(history))
+	for i, revision := range history {
+		revisions[i] = configRevision{
+			Revision:     revision.Revision,
+			Time:         revision.Time.UTC().Format(time.RFC3339),
+			User:         revision.User,
+			Charm:        revision.CharmURL,
+			RolledBackTo: revision.RolledBackTo,
+			Settings:     revision.Settings,
+		}
+	}
+	return c.out.Write(ctx, revisions)
+}
+
+// configRevision holds a config revision in the form shown to the user.
+type configRevision struct {
+	Revision     int                    `yaml:"revision" json:"revision"`
+	Time         string                 `yaml:"time" json:"time"`
+	User         string                 `yaml:"user,omitempty" json:"user,omitempty"`
+	Charm        string                 `yaml:"charm" json:"charm"`
+	RolledBackTo int                    `yaml:"rolled-back-to,omitempty" json:"rolled-back-to,omitempty"`
+	Settings     map[string]interface{} `yaml:"settings" json:"settings"`
+}
+
+// formatConfigHistoryTabular shows each revision followed by the
+// settings that changed since the one before it.
+func formatConfigHistoryTabular(value interface{}) ([]byte, error) {
+	revisions, ok := value.([]configRevision)
+	if !ok {
+		return nil, errors.Errorf("expected value of type %T, got %T", revisions, value)
+	}
+	var out bytes.Buffer
+	var previous map[string]interface{}
+	for _, revision := range revisions {
+		fmt.Fprintf(&out, "revision %d at %s", revision.Revision, revision.Time)
+		if revision.User != "" {
+			fmt.Fprintf(&out, " by %s", revision.User)
+		}
+		fmt.Fprintf(&out, " (%s)", revision.Charm)
+		if revision.RolledBackTo != 0 {
+			fmt.Fprintf(&out, ", rolled back to revision %d", revision.RolledBackTo)
+		}
+		fmt.Fprintln(&out)
+		if revision.Revision > 1 {
+			writeConfigDiff(&out, previous, revision.Settings)
+		}
+		previous = revision.Settings
+	}
+	return out.Bytes(), nil
+}
+
+// writeConfigDiff writes the differences between two sets of settings,
+// ordered by setting name.
+func writeConfigDiff(out *bytes.Buffer, before, after map[string]interface{}) {
+	var names []string
+	for name := range before {
+		names = append(names, name)
+	}
+	for name := range after {
+		if _, ok := before[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		oldValue, inBefore := before[name]
+		newValue, inAfter := after[name]
+		switch {
+		case !inBefore:
+			fmt.Fprintf(out, "  + %s: %v\n", name, newValue)
+		case !inAfter:
+			fmt.Fprintf(out, "  - %s: %v\n", name, oldValue)
+		case fmt.Sprint(oldValue) != fmt.Sprint(newValue):
+			fmt.Fprintf(out, "  ~ %s: %v -> %v\n", name, oldValue, newValue)
+		}
+	}
+}
+
+func newConfigRollbackCommand() cmd.Command {
+	return envcmd.Wrap(&configRollbackCommand{})
+}
+
+// configRollbackCommand restores a service's configuration to an
+// earlier revision.
+type configRollbackCommand struct {
+	envcmd.EnvCommandBase
+	ServiceName string
+	Revision    int
+	api         ConfigHistoryAPI
+}
+
+const configRollbackDoc = `
+Restore the configuration of <service> to that of an earlier revision, as
+listed by "juju config-history". Settings that were not set at that revision
+are reset to their defaults. The rollback is itself recorded as a new
+revision, so it can be undone in the same way.
+
+Example:
+
+    juju config-rollback wordpress 3
+
+See Also:
+   juju help config-history
+   juju help set
+`
+
+func (c *configRollbackCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "config-rollback",
+		Args:    "<service> <revision>",
+		Purpose: "restore a service's configuration to an earlier revision",
+		Doc:     configRollbackDoc,
+	}
+}
+
+func (c *configRollbackCommand) Init(args []string) error {
+	switch len(args) {
+	case 0:
+		return errors.New("no service name specified")
+	case 1:
+		return errors.New("no revision specified")
+	}
+	c.ServiceName = args[0]
+	revision, err := parseConfigRevision(args[1])
+	if err != nil {
+		return err
+	}
+	c.Revision = revision
+	return cmd.CheckEmpty(args[2:])
+}
+
+// Run restores the service's config to the requested revision.
+func (c *configRollbackCommand) Run(ctx *cmd.Context) error {
+	api, closer, err := configHistoryAPI(&c.EnvCommandBase, c.api)
+	if err != nil {
+		return err
+	}
+	defer closer()
+
+	err = api.ServiceConfigRollback(c.ServiceName, c.Revision)
+	return block.ProcessBlockedError(err, block.BlockChange)
+}
+
+// parseConfigRevision parses a config revision number given on the
+// command line.
+func parseConfigRevision(value string) (int, error) {
+	revision, err := strconv.Atoi(value)
+	if err != nil || revision < 1 {
+		return 0, errors.Errorf("invalid revision %q", value)
+	}
+	return revision, nil
+}
@@ -0,0 +1,132 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package service_test
+
+import (
+	"strings"
+	"time"
+
+	"github.com/juju/cmd"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/juju/service"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type ConfigHistorySuite struct {
+	coretesting.FakeJujuHomeSuite
+	fake *fakeServiceAPI
+}
+
+var _ = gc.Suite(&ConfigHistorySuite{})
+
+func (s *ConfigHistorySuite) SetUpTest(c *gc.C) {
+	s.FakeJujuHomeSuite.SetUpTest(c)
+	changed := time.Date(2015, 11, 3, 10, 30, 0, 0, time.UTC)
+	s.fake = &fakeServiceAPI{
+		serviceName: "dummy-service",
+		history: []params.ServiceConfigRevision{{
+			Revision: 1,
+			CharmURL: "cs:quantal/dummy-1",
+			Settings: map[string]interface{}{"title": "one"},
+			Time:     changed,
+		}, {
+			Revision: 2,
+			CharmURL: "cs:quantal/dummy-1",
+			Settings: map[string]interface{}{"title": "two", "username": "admin"},
+			User:     "bob@local",
+			Time:     changed.Add(time.Hour),
+		}, {
+			Revision:     3,
+			CharmURL:     "cs:quantal/dummy-1",
+			Settings:     map[string]interface{}{"title": "one"},
+			User:         "alice@local",
+			Time:         changed.Add(2 * time.Hour),
+			RolledBackTo: 1,
+		}},
+	}
+}
+
+func (s *ConfigHistorySuite) TestHistoryInit(c *gc.C) {
+	err := coretesting.InitCommand(service.NewConfigHistoryCommand(s.fake), nil)
+	c.Assert(err, gc.ErrorMatches, "no service name specified")
+	err = coretesting.InitCommand(service.NewConfigHistoryCommand(s.fake), []string{"dummy-service", "extra"})
+	c.Assert(err, gc.ErrorMatches, `unrecognized args: \["extra"\]`)
+}
+
+func (s *ConfigHistorySuite) TestHistoryTabular(c *gc.C) {
+	ctx, err := coretesting.RunCommand(c, service.NewConfigHistoryCommand(s.fake), "dummy-service")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(coretesting.Stdout(ctx), gc.Equals, `
+revision 1 at 2015-11-03T10:30:00Z (cs:quantal/dummy-1)
+revision 2 at 2015-11-03T11:30:00Z by bob@local (cs:quantal/dummy-1)
+  ~ title: one -> two
+  + username: admin
+revision 3 at 2015-11-03T12:30:00Z by alice@local (cs:quantal/dummy-1), rolled back to revision 1
+  ~ title: two -> one
+  - username: admin
+`[1:])
+}
+
+func (s *ConfigHistorySuite) TestHistoryYAML(c *gc.C) {
+	s.fake.history = s.fake.history[2:]
+	ctx, err := coretesting.RunCommand(c, service.NewConfigHistoryCommand(s.fake), "dummy-service", "--format", "yaml")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(coretesting.Stdout(ctx), gc.Equals, `
+- revision: 3
+  time: "2015-11-03T12:30:00Z"
+  user: alice@local
+  charm: cs:quantal/dummy-1
+  rolled-back-to: 1
+  settings:
+    title: one
+`[1:])
+}
+
+func (s *ConfigHistorySuite) TestHistoryServiceNotFound(c *gc.C) {
+	_, err := coretesting.RunCommand(c, service.NewConfigHistoryCommand(s.fake), "unknown")
+	c.Assert(err, gc.ErrorMatches, `service "unknown" not found`)
+}
+
+func (s *ConfigHistorySuite) TestRollbackInit(c *gc.C) {
+	for i, test := range []struct {
+		args []string
+		err  string
+	}{{
+		err: "no service name specified",
+	}, {
+		args: []string{"dummy-service"},
+		err:  "no revision specified",
+	}, {
+		args: []string{"dummy-service", "latest"},
+		err:  `invalid revision "latest"`,
+	}, {
+		args: []string{"dummy-service", "0"},
+		err:  `invalid revision "0"`,
+	}, {
+		args: []string{"dummy-service", "1", "2"},
+		err:  `unrecognized args: \["2"\]`,
+	}} {
+		c.Logf("test %d: %v", i, test.args)
+		err := coretesting.InitCommand(service.NewConfigRollbackCommand(s.fake), test.args)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
+
+func (s *ConfigHistorySuite) TestRollback(c *gc.C) {
+	_, err := coretesting.RunCommand(c, service.NewConfigRollbackCommand(s.fake), "dummy-service", "2")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.fake.rolledBack, gc.Equals, 2)
+}
+
+func (s *ConfigHistorySuite) TestBlockRollback(c *gc.C) {
+	s.fake.err = common.OperationBlockedError("TestBlockRollback")
+	_, err := coretesting.RunCommand(c, service.NewConfigRollbackCommand(s.fake), "dummy-service", "2")
+	c.Assert(err, gc.ErrorMatches, cmd.ErrSilent.Error())
+	stripped := strings.Replace(c.GetTestLog(), "\n", "", -1)
+	c.Check(stripped, gc.Matches, ".*TestBlockRollback.*")
+}
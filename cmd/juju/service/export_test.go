@@ -38,6 +38,34 @@ func NewAddUnitCommand(api ServiceAddUnitAPI) cmd.Command {
 	})
 }
 
+// NewConfigHistoryCommand returns a ConfigHistoryCommand with the api provided as specified.
+func NewConfigHistoryCommand(api ConfigHistoryAPI) cmd.Command {
+	return envcmd.Wrap(&configHistoryCommand{
+		api: api,
+	})
+}
+
+// NewConfigRollbackCommand returns a ConfigRollbackCommand with the api provided as specified.
+func NewConfigRollbackCommand(api ConfigHistoryAPI) cmd.Command {
+	return envcmd.Wrap(&configRollbackCommand{
+		api: api,
+	})
+}
+
+// NewOfferCommand returns an OfferCommand with the api provided as specified.
+func NewOfferCommand(api OfferAPI) cmd.Command {
+	return envcmd.Wrap(&offerCommand{
+		api: api,
+	})
+}
+
+// NewConsumeCommand returns a ConsumeCommand with the api provided as specified.
+func NewConsumeCommand(api OfferAPI) cmd.Command {
+	return envcmd.Wrap(&consumeCommand{
+		api: api,
+	})
+}
+
 var (
 	NewServiceSetConstraintsCommand = newServiceSetConstraintsCommand
 	NewServiceGetConstraintsCommand = newServiceGetConstraintsCommand
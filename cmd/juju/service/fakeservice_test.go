@@ -5,6 +5,7 @@ package service_test
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/juju/errors"
 
@@ -86,10 +87,13 @@ func (f *fakeClientAPI) ServiceUnset(service string, options []string) error {
 }
 
 // fakeServiceAPI is the fake service API for testing the service
-// update command.
+// update, config-history and config-rollback commands.
 type fakeServiceAPI struct {
 	serviceName string
 	config      string
+	hookTimeout *time.Duration
+	history     []params.ServiceConfigRevision
+	rolledBack  int
 	err         error
 }
 
@@ -103,5 +107,26 @@ func (f *fakeServiceAPI) ServiceUpdate(args params.ServiceUpdate) error {
 	}
 
 	f.config = args.SettingsYAML
+	f.hookTimeout = args.HookTimeout
+	return nil
+}
+
+func (f *fakeServiceAPI) ServiceConfigHistory(serviceName string) ([]params.ServiceConfigRevision, error) {
+	if serviceName != f.serviceName {
+		return nil, errors.NotFoundf("service %q", serviceName)
+	}
+	return f.history, nil
+}
+
+func (f *fakeServiceAPI) ServiceConfigRollback(serviceName string, revision int) error {
+	if f.err != nil {
+		return f.err
+	}
+
+	if serviceName != f.serviceName {
+		return errors.NotFoundf("service %q", serviceName)
+	}
+
+	f.rolledBack = revision
 	return nil
 }
@@ -0,0 +1,189 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/api/service"
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/cmd/juju/block"
+)
+
+// OfferAPI defines the methods on the service API that the offer and
+// consume commands call.
+type OfferAPI interface {
+	ServiceOffer(serviceName, endpoint, offerName string) error
+	ServiceConsume(environment, offerName, serviceName string) (string, error)
+}
+
+// offerAPI returns the API the command should use, and a function that
+// releases it.
+func offerAPI(c *envcmd.EnvCommandBase, api OfferAPI) (OfferAPI, func() error, error) {
+	if api != nil {
+		return api, func() error { return nil }, nil
+	}
+	root, err := c.NewAPIRoot()
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	return service.NewClient(root), root.Close, nil
+}
+
+func newOfferCommand() cmd.Command {
+	return envcmd.Wrap(&offerCommand{})
+}
+
+// offerCommand offers a service's endpoint to other environments.
+type offerCommand struct {
+	envcmd.EnvCommandBase
+	ServiceName string
+	Endpoint    string
+	OfferName   string
+	api         OfferAPI
+}
+
+const offerDoc = `
+Offer an endpoint of a service in this environment to the other environments
+on the same controller. Users with access to this environment can then consume
+the offer from their own environments with "juju consume", and relate their
+services to it as if the offered service were deployed alongside them.
+
+The offer is named after the service unless an offer name is given. Only
+endpoints of global scope can be offered.
+
+Example:
+
+    juju offer mysql:db
+    juju offer mysql:db shared-db
+
+See Also:
+   juju help consume
+   juju help add-relation
+`
+
+func (c *offerCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "offer",
+		Args:    "<service>:<endpoint> [<offer-name>]",
+		Purpose: "offer a service endpoint to other environments",
+		Doc:     offerDoc,
+	}
+}
+
+func (c *offerCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return errors.New("no endpoint specified")
+	}
+	parts := strings.Split(args[0], ":")
+	if len(parts) != 2 || !names.IsValidService(parts[0]) || parts[1] == "" {
+		return errors.Errorf("invalid endpoint %q, expected <service>:<endpoint>", args[0])
+	}
+	c.ServiceName, c.Endpoint = parts[0], parts[1]
+	if len(args) > 1 {
+		if !names.IsValidService(args[1]) {
+			return errors.Errorf("invalid offer name %q", args[1])
+		}
+		c.OfferName = args[1]
+		args = args[1:]
+	}
+	return cmd.CheckEmpty(args[1:])
+}
+
+// Run offers the service's endpoint.
+func (c *offerCommand) Run(ctx *cmd.Context) error {
+	api, closer, err := offerAPI(&c.EnvCommandBase, c.api)
+	if err != nil {
+		return err
+	}
+	defer closer()
+
+	err = api.ServiceOffer(c.ServiceName, c.Endpoint, c.OfferName)
+	return block.ProcessBlockedError(err, block.BlockChange)
+}
+
+func newConsumeCommand() cmd.Command {
+	return envcmd.Wrap(&consumeCommand{})
+}
+
+// consumeCommand adds a remote service for an offer made by another
+// environment.
+type consumeCommand struct {
+	envcmd.EnvCommandBase
+	Environment string
+	OfferName   string
+	ServiceName string
+	api         OfferAPI
+}
+
+const consumeDoc = `
+Consume an offer made by another environment on the same controller with
+"juju offer". A remote service is added to this environment, standing for the
+offered service, and local services can be related to it with "juju
+add-relation". Relation settings flow between the environments through the
+controller, so no credentials need to be copied between them.
+
+The offering environment is given by name, qualified by its owner if the name
+is ambiguous; you must have access to it. The remote service is named after
+the offer unless a service name is given.
+
+Example:
+
+    juju consume shared:mysql
+    juju consume bob/shared:mysql shared-db
+    juju add-relation wordpress shared-db
+
+See Also:
+   juju help offer
+   juju help add-relation
+`
+
+func (c *consumeCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "consume",
+		Args:    "[<owner>/]<environment>:<offer> [<service-name>]",
+		Purpose: "use an offer from another environment",
+		Doc:     consumeDoc,
+	}
+}
+
+func (c *consumeCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return errors.New("no offer specified")
+	}
+	parts := strings.Split(args[0], ":")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return errors.Errorf("invalid offer %q, expected [<owner>/]<environment>:<offer>", args[0])
+	}
+	c.Environment, c.OfferName = parts[0], parts[1]
+	if len(args) > 1 {
+		if !names.IsValidService(args[1]) {
+			return errors.Errorf("invalid service name %q", args[1])
+		}
+		c.ServiceName = args[1]
+		args = args[1:]
+	}
+	return cmd.CheckEmpty(args[1:])
+}
+
+// Run adds a remote service for the offer.
+func (c *consumeCommand) Run(ctx *cmd.Context) error {
+	api, closer, err := offerAPI(&c.EnvCommandBase, c.api)
+	if err != nil {
+		return err
+	}
+	defer closer()
+
+	name, err := api.ServiceConsume(c.Environment, c.OfferName, c.ServiceName)
+	if err != nil {
+		return block.ProcessBlockedError(err, block.BlockChange)
+	}
+	fmt.Fprintf(ctx.Stdout, "Added remote service %q.\n", name)
+	return nil
+}
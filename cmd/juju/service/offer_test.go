@@ -0,0 +1,134 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package service_test
+
+import (
+	"strings"
+
+	"github.com/juju/cmd"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/cmd/juju/service"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type OfferSuite struct {
+	coretesting.FakeJujuHomeSuite
+	fake *fakeOfferAPI
+}
+
+var _ = gc.Suite(&OfferSuite{})
+
+func (s *OfferSuite) SetUpTest(c *gc.C) {
+	s.FakeJujuHomeSuite.SetUpTest(c)
+	s.fake = &fakeOfferAPI{}
+}
+
+type fakeOfferAPI struct {
+	calls []string
+	err   error
+}
+
+func (f *fakeOfferAPI) ServiceOffer(serviceName, endpoint, offerName string) error {
+	f.calls = append(f.calls, "ServiceOffer", serviceName, endpoint, offerName)
+	return f.err
+}
+
+func (f *fakeOfferAPI) ServiceConsume(environment, offerName, serviceName string) (string, error) {
+	f.calls = append(f.calls, "ServiceConsume", environment, offerName, serviceName)
+	if serviceName == "" {
+		serviceName = offerName
+	}
+	return serviceName, f.err
+}
+
+func (s *OfferSuite) TestOfferInit(c *gc.C) {
+	for i, test := range []struct {
+		args []string
+		err  string
+	}{{
+		err: "no endpoint specified",
+	}, {
+		args: []string{"mysql"},
+		err:  `invalid endpoint "mysql", expected <service>:<endpoint>`,
+	}, {
+		args: []string{"mysql:"},
+		err:  `invalid endpoint "mysql:", expected <service>:<endpoint>`,
+	}, {
+		args: []string{"mysql:db", "Shared_DB"},
+		err:  `invalid offer name "Shared_DB"`,
+	}, {
+		args: []string{"mysql:db", "shared-db", "extra"},
+		err:  `unrecognized args: \["extra"\]`,
+	}} {
+		c.Logf("test %d: %v", i, test.args)
+		err := coretesting.InitCommand(service.NewOfferCommand(s.fake), test.args)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
+
+func (s *OfferSuite) TestOffer(c *gc.C) {
+	_, err := coretesting.RunCommand(c, service.NewOfferCommand(s.fake), "mysql:db")
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = coretesting.RunCommand(c, service.NewOfferCommand(s.fake), "mysql:db", "shared-db")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.fake.calls, jc.DeepEquals, []string{
+		"ServiceOffer", "mysql", "db", "",
+		"ServiceOffer", "mysql", "db", "shared-db",
+	})
+}
+
+func (s *OfferSuite) TestBlockOffer(c *gc.C) {
+	s.fake.err = common.OperationBlockedError("TestBlockOffer")
+	_, err := coretesting.RunCommand(c, service.NewOfferCommand(s.fake), "mysql:db")
+	c.Assert(err, gc.ErrorMatches, cmd.ErrSilent.Error())
+	stripped := strings.Replace(c.GetTestLog(), "\n", "", -1)
+	c.Check(stripped, gc.Matches, ".*TestBlockOffer.*")
+}
+
+func (s *OfferSuite) TestConsumeInit(c *gc.C) {
+	for i, test := range []struct {
+		args []string
+		err  string
+	}{{
+		err: "no offer specified",
+	}, {
+		args: []string{"shared"},
+		err:  `invalid offer "shared", expected \[<owner>/\]<environment>:<offer>`,
+	}, {
+		args: []string{":mysql"},
+		err:  `invalid offer ":mysql", expected \[<owner>/\]<environment>:<offer>`,
+	}, {
+		args: []string{"shared:mysql", "Shared_DB"},
+		err:  `invalid service name "Shared_DB"`,
+	}, {
+		args: []string{"shared:mysql", "shared-db", "extra"},
+		err:  `unrecognized args: \["extra"\]`,
+	}} {
+		c.Logf("test %d: %v", i, test.args)
+		err := coretesting.InitCommand(service.NewConsumeCommand(s.fake), test.args)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
+
+func (s *OfferSuite) TestConsume(c *gc.C) {
+	ctx, err := coretesting.RunCommand(c, service.NewConsumeCommand(s.fake), "bob/shared:mysql", "shared-db")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(coretesting.Stdout(ctx), gc.Equals, "Added remote service \"shared-db\".\n")
+	c.Assert(s.fake.calls, jc.DeepEquals, []string{"ServiceConsume", "bob/shared", "mysql", "shared-db"})
+
+	ctx, err = coretesting.RunCommand(c, service.NewConsumeCommand(s.fake), "shared:mysql")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(coretesting.Stdout(ctx), gc.Equals, "Added remote service \"mysql\".\n")
+}
+
+func (s *OfferSuite) TestBlockConsume(c *gc.C) {
+	s.fake.err = common.OperationBlockedError("TestBlockConsume")
+	_, err := coretesting.RunCommand(c, service.NewConsumeCommand(s.fake), "shared:mysql")
+	c.Assert(err, gc.ErrorMatches, cmd.ErrSilent.Error())
+	stripped := strings.Replace(c.GetTestLog(), "\n", "", -1)
+	c.Check(stripped, gc.Matches, ".*TestBlockConsume.*")
+}
@@ -25,9 +25,13 @@ func NewSuperCommand() cmd.Command {
 	})
 
 	environmentCmd.Register(newAddUnitCommand())
+	environmentCmd.Register(newConfigHistoryCommand())
+	environmentCmd.Register(newConfigRollbackCommand())
+	environmentCmd.Register(newConsumeCommand())
 	environmentCmd.Register(newServiceGetConstraintsCommand())
 	environmentCmd.Register(newServiceSetConstraintsCommand())
 	environmentCmd.Register(newGetCommand())
+	environmentCmd.Register(newOfferCommand())
 	environmentCmd.Register(NewSetCommand())
 	environmentCmd.Register(newUnsetCommand())
 
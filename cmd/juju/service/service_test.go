@@ -21,9 +21,13 @@ var _ = gc.Suite(&ServiceCommandSuite{})
 
 var expectedCommmandNames = []string{
 	"add-unit",
+	"config-history",
+	"config-rollback",
+	"consume",
 	"get",
 	"get-constraints",
 	"help",
+	"offer",
 	"set",
 	"set-constraints",
 	"unset",
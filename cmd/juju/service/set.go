@@ -8,6 +8,7 @@ import (
 	"io/ioutil"
 	"os"
 	"strings"
+	"time"
 	"unicode/utf8"
 
 	"github.com/juju/cmd"
@@ -31,6 +32,10 @@ type setCommand struct {
 	ServiceName     string
 	SettingsStrings map[string]string
 	SettingsYAML    cmd.FileVar
+	HookTimeout     *time.Duration
+	hookTimeout     string
+	Revision        int
+	revision        string
 	clientApi       ClientAPI
 	serviceApi      ServiceAPI
 }
@@ -46,6 +51,14 @@ size of this value is 5M.
 
 Option values may be any UTF-8 encoded string. UTF-8 is accepted on the command
 line and in configuration files.
+
+The --hook-timeout option sets how long the service's hooks may run, e.g. 30m,
+before they are killed and the unit is put into an error state. A timeout of 0
+makes the service use the environment's hook-timeout setting again.
+
+The --revision option restores the service's configuration to that of an
+earlier revision, as listed by "juju config-history". It cannot be combined
+with name=value arguments or --config.
 `
 
 const maxValueSize = 5242880
@@ -61,6 +74,8 @@ func (c *setCommand) Info() *cmd.Info {
 
 func (c *setCommand) SetFlags(f *gnuflag.FlagSet) {
 	f.Var(&c.SettingsYAML, "config", "path to yaml-formatted service config")
+	f.StringVar(&c.hookTimeout, "hook-timeout", "", "how long the service's hooks may run before they are killed")
+	f.StringVar(&c.revision, "revision", "", "restore the config of an earlier revision")
 }
 
 func (c *setCommand) Init(args []string) error {
@@ -71,6 +86,26 @@ func (c *setCommand) Init(args []string) error {
 		return errors.New("cannot specify --config when using key=value arguments")
 	}
 	c.ServiceName = args[0]
+	if c.revision != "" {
+		if c.SettingsYAML.Path != "" || len(args) > 1 {
+			return errors.New("cannot specify --revision when using key=value arguments or --config")
+		}
+		revision, err := parseConfigRevision(c.revision)
+		if err != nil {
+			return errors.Annotate(err, "invalid --revision")
+		}
+		c.Revision = revision
+	}
+	if c.hookTimeout != "" {
+		timeout, err := time.ParseDuration(c.hookTimeout)
+		if err != nil {
+			return errors.Annotate(err, "invalid --hook-timeout")
+		}
+		if timeout < 0 {
+			return errors.Errorf("invalid --hook-timeout %v: must not be negative", c.hookTimeout)
+		}
+		c.HookTimeout = &timeout
+	}
 	settings, err := keyvalues.Parse(args[1:], true)
 	if err != nil {
 		return err
@@ -99,6 +134,7 @@ func (c *setCommand) getClientAPI() (ClientAPI, error) {
 // that the service set command calls.
 type ServiceAPI interface {
 	ServiceUpdate(args params.ServiceUpdate) error
+	ServiceConfigRollback(serviceName string, revision int) error
 }
 
 func (c *setCommand) getServiceAPI() (ServiceAPI, error) {
@@ -126,6 +162,21 @@ func (c *setCommand) Run(ctx *cmd.Context) error {
 		return err
 	}
 
+	if c.HookTimeout != nil {
+		err := serviceApi.ServiceUpdate(params.ServiceUpdate{
+			ServiceName: c.ServiceName,
+			HookTimeout: c.HookTimeout,
+		})
+		if err != nil {
+			return block.ProcessBlockedError(err, block.BlockChange)
+		}
+	}
+
+	if c.Revision != 0 {
+		err := serviceApi.ServiceConfigRollback(c.ServiceName, c.Revision)
+		return block.ProcessBlockedError(err, block.BlockChange)
+	}
+
 	if c.SettingsYAML.Path != "" {
 		b, err := c.SettingsYAML.Read(ctx)
 		if err != nil {
@@ -8,6 +8,7 @@ import (
 	"io/ioutil"
 	"os"
 	"strings"
+	"time"
 	"unicode/utf8"
 
 	"github.com/juju/cmd"
@@ -65,6 +66,43 @@ func (s *SetSuite) TestSetCommandInit(c *gc.C) {
 	// --config and options specified
 	err = coretesting.InitCommand(service.NewSetCommandWithAPI(s.fakeClientAPI, s.fakeServiceAPI), []string{"service", "--config", "testconfig.yaml", "bees="})
 	c.Assert(err, gc.ErrorMatches, "cannot specify --config when using key=value arguments")
+
+	// invalid --hook-timeout
+	err = coretesting.InitCommand(service.NewSetCommandWithAPI(s.fakeClientAPI, s.fakeServiceAPI), []string{"service", "--hook-timeout", "forever"})
+	c.Assert(err, gc.ErrorMatches, "invalid --hook-timeout: time: invalid duration forever")
+	err = coretesting.InitCommand(service.NewSetCommandWithAPI(s.fakeClientAPI, s.fakeServiceAPI), []string{"service", "--hook-timeout", "-1m"})
+	c.Assert(err, gc.ErrorMatches, "invalid --hook-timeout -1m: must not be negative")
+
+	// --revision and options specified
+	err = coretesting.InitCommand(service.NewSetCommandWithAPI(s.fakeClientAPI, s.fakeServiceAPI), []string{"service", "--revision", "2", "bees="})
+	c.Assert(err, gc.ErrorMatches, "cannot specify --revision when using key=value arguments or --config")
+
+	// invalid --revision
+	err = coretesting.InitCommand(service.NewSetCommandWithAPI(s.fakeClientAPI, s.fakeServiceAPI), []string{"service", "--revision", "0"})
+	c.Assert(err, gc.ErrorMatches, `invalid --revision: invalid revision "0"`)
+}
+
+func (s *SetSuite) TestSetRevision(c *gc.C) {
+	ctx := coretesting.ContextForDir(c, s.dir)
+	code := cmd.Main(service.NewSetCommandWithAPI(s.fakeClientAPI, s.fakeServiceAPI), ctx, []string{
+		"dummy-service",
+		"--revision",
+		"3"})
+	c.Check(code, gc.Equals, 0)
+	c.Check(s.fakeServiceAPI.rolledBack, gc.Equals, 3)
+	c.Check(s.fakeClientAPI.values, gc.HasLen, 0)
+}
+
+func (s *SetSuite) TestSetHookTimeout(c *gc.C) {
+	ctx := coretesting.ContextForDir(c, s.dir)
+	code := cmd.Main(service.NewSetCommandWithAPI(s.fakeClientAPI, s.fakeServiceAPI), ctx, []string{
+		"dummy-service",
+		"--hook-timeout",
+		"30m"})
+	c.Check(code, gc.Equals, 0)
+	c.Assert(s.fakeServiceAPI.hookTimeout, gc.NotNil)
+	c.Check(*s.fakeServiceAPI.hookTimeout, gc.Equals, 30*time.Minute)
+	c.Check(s.fakeClientAPI.values, gc.HasLen, 0)
 }
 
 func (s *SetSuite) TestSetOptionSuccess(c *gc.C) {
@@ -24,7 +24,8 @@ type errorStatus struct {
 }
 
 type environmentStatus struct {
-	AvailableVersion string `json:"upgrade-available,omitempty" yaml:"upgrade-available,omitempty"`
+	AvailableVersion   string `json:"upgrade-available,omitempty" yaml:"upgrade-available,omitempty"`
+	CertificateWarning string `json:"certificate-warning,omitempty" yaml:"certificate-warning,omitempty"`
 }
 
 type machineStatus struct {
@@ -65,6 +66,7 @@ type serviceStatus struct {
 	Err           error                 `json:"-" yaml:",omitempty"`
 	Charm         string                `json:"charm" yaml:"charm"`
 	CanUpgradeTo  string                `json:"can-upgrade-to,omitempty" yaml:"can-upgrade-to,omitempty"`
+	CharmRollout  *charmRolloutStatus   `json:"charm-rollout,omitempty" yaml:"charm-rollout,omitempty"`
 	Exposed       bool                  `json:"exposed" yaml:"exposed"`
 	Life          string                `json:"life,omitempty" yaml:"life,omitempty"`
 	StatusInfo    statusInfoContents    `json:"service-status,omitempty" yaml:"service-status"`
@@ -76,6 +78,13 @@ type serviceStatus struct {
 
 type serviceStatusNoMarshal serviceStatus
 
+type charmRolloutStatus struct {
+	FromCharm   string   `json:"from-charm" yaml:"from-charm"`
+	Released    []string `json:"released" yaml:"released"`
+	Paused      bool     `json:"paused,omitempty" yaml:"paused,omitempty"`
+	PauseReason string   `json:"pause-reason,omitempty" yaml:"pause-reason,omitempty"`
+}
+
 func (s serviceStatus) MarshalJSON() ([]byte, error) {
 	if s.Err != nil {
 		return json.Marshal(errorStatus{s.Err.Error()})
@@ -97,9 +106,10 @@ type meterStatus struct {
 
 type unitStatus struct {
 	// New Juju Health Status fields.
-	WorkloadStatusInfo statusInfoContents `json:"workload-status,omitempty" yaml:"workload-status"`
-	AgentStatusInfo    statusInfoContents `json:"agent-status,omitempty" yaml:"agent-status"`
-	MeterStatus        *meterStatus       `json:"meter-status,omitempty" yaml:"meter-status,omitempty"`
+	WorkloadStatusInfo statusInfoContents  `json:"workload-status,omitempty" yaml:"workload-status"`
+	AgentStatusInfo    statusInfoContents  `json:"agent-status,omitempty" yaml:"agent-status"`
+	HealthStatusInfo   *statusInfoContents `json:"health-status,omitempty" yaml:"health-status,omitempty"`
+	MeterStatus        *meterStatus        `json:"meter-status,omitempty" yaml:"meter-status,omitempty"`
 
 	// Legacy status fields, to be removed in Juju 2.0
 	AgentState     params.Status `json:"agent-state,omitempty" yaml:"agent-state,omitempty"`
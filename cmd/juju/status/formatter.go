@@ -40,9 +40,10 @@ func (sf *statusFormatter) format() formattedStatus {
 		Machines:    make(map[string]machineStatus),
 		Services:    make(map[string]serviceStatus),
 	}
-	if sf.status.AvailableVersion != "" {
+	if sf.status.AvailableVersion != "" || sf.status.CertificateWarning != "" {
 		out.EnvironmentStatus = &environmentStatus{
-			AvailableVersion: sf.status.AvailableVersion,
+			AvailableVersion:   sf.status.AvailableVersion,
+			CertificateWarning: sf.status.CertificateWarning,
 		}
 	}
 
@@ -126,6 +127,14 @@ func (sf *statusFormatter) formatService(name string, service params.ServiceStat
 		Units:         make(map[string]unitStatus),
 		StatusInfo:    sf.getServiceStatusInfo(service),
 	}
+	if rollout := service.CharmRollout; rollout != nil {
+		out.CharmRollout = &charmRolloutStatus{
+			FromCharm:   rollout.FromCharm,
+			Released:    rollout.Released,
+			Paused:      rollout.Paused,
+			PauseReason: rollout.PauseReason,
+		}
+	}
 	if len(service.Networks.Enabled) > 0 {
 		out.Networks["enabled"] = service.Networks.Enabled
 	}
@@ -177,6 +186,10 @@ func (sf *statusFormatter) formatUnit(info unitFormatInfo) unitStatus {
 		Subordinates:       make(map[string]unitStatus),
 	}
 
+	if info.unit.Health.Status != "" {
+		out.HealthStatusInfo = sf.getHealthStatusInfo(info.unit)
+	}
+
 	if ms, ok := info.meterStatuses[info.unitName]; ok {
 		out.MeterStatus = &meterStatus{
 			Color:   ms.Color,
@@ -217,6 +230,18 @@ func (sf *statusFormatter) getWorkloadStatusInfo(unit params.UnitStatus) statusI
 	return info
 }
 
+func (sf *statusFormatter) getHealthStatusInfo(unit params.UnitStatus) *statusInfoContents {
+	info := &statusInfoContents{
+		Err:     unit.Health.Err,
+		Current: unit.Health.Status,
+		Message: unit.Health.Info,
+	}
+	if unit.Health.Since != nil {
+		info.Since = common.FormatTime(unit.Health.Since, sf.isoTime)
+	}
+	return info
+}
+
 func (sf *statusFormatter) getAgentStatusInfo(unit params.UnitStatus) statusInfoContents {
 	info := statusInfoContents{
 		Err:     unit.UnitAgent.Err,
@@ -7,9 +7,11 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/juju/cmd"
 	"github.com/juju/errors"
+	"github.com/juju/names"
 	"launchpad.net/gnuflag"
 
 	"github.com/juju/juju/apiserver/params"
@@ -18,8 +20,20 @@ import (
 	"github.com/juju/juju/juju/osenv"
 )
 
+// statusHistoryAPI defines the API methods used by the
+// status-history command.
+type statusHistoryAPI interface {
+	StatusHistory(args params.EntityStatusHistoryArgs) (*params.StatusHistoryResult, error)
+	UnitStatusHistory(kind params.HistoryKind, unitName string, size int) (*params.UnitStatusHistory, error)
+	Close() error
+}
+
+var newAPIClientForStatusHistory = func(c *statusHistoryCommand) (statusHistoryAPI, error) {
+	return c.NewAPIClient()
+}
+
 // NewStatusHistoryCommand returns a command that reports the history
-// of status changes for the specified unit.
+// of status changes for the specified entity.
 func NewStatusHistoryCommand() cmd.Command {
 	return envcmd.Wrap(&statusHistoryCommand{})
 }
@@ -30,48 +44,141 @@ type statusHistoryCommand struct {
 	outputContent string
 	backlogSize   int
 	isoTime       bool
-	unitName      string
+	fromDate      string
+	toDate        string
+	tag           names.Tag
+	from          *time.Time
+	to            *time.Time
 }
 
 var statusHistoryDoc = `
-This command will report the history of status changes for
-a given unit.
-The statuses for the unit workload and/or agent are available.
--type supports:
-    agent: will show statuses for the unit's agent
-    workload: will show statuses for the unit's workload
-    combined: will show agent and workload statuses combined
- and sorted by time of occurrence.
+This command will report the history of status changes for a unit,
+machine, service, volume or filesystem. If no entity kind is given,
+the entity is assumed to be a unit.
+
+The statuses to show are selected with --type:
+    agent: the unit's or machine's agent statuses
+    workload: the unit's or service's workload statuses
+    instance: the provider statuses of the machine's instance
+    storage: the volume's or filesystem's statuses
+    health: the results of the unit's health checks
+    combined: all of the entity's statuses, sorted by time
+     of occurrence (the default)
+
+At most -n of the most recent statuses are shown. --from and --to
+restrict the history to a time range; they accept dates in the
+"YYYY-MM-DD" or "YYYY-MM-DD HH:MM:SS" formats, in local time, or in
+RFC3339 format. A --to date without a time includes the whole day.
+
+Examples:
+
+    juju status-history wordpress/0
+    juju status-history machine 3
+    juju status-history --type instance machine 3
+    juju status-history --type health wordpress/0
+    juju status-history -n 100 --from 2015-10-01 --to 2015-10-02 machine 3
+    juju status-history service wordpress
+    juju status-history volume 0/1
 `
 
 func (c *statusHistoryCommand) Info() *cmd.Info {
 	return &cmd.Info{
 		Name:    "status-history",
-		Args:    "[-n N] <unit>",
-		Purpose: "output past statuses for a unit",
+		Args:    "[-n N] [--from date] [--to date] [unit|machine|service|volume|filesystem] <name>",
+		Purpose: "output past statuses for a unit, machine, service, volume or filesystem",
 		Doc:     statusHistoryDoc,
 	}
 }
 
 func (c *statusHistoryCommand) SetFlags(f *gnuflag.FlagSet) {
-	f.StringVar(&c.outputContent, "type", "combined", "type of statuses to be displayed [agent|workload|combined].")
+	f.StringVar(&c.outputContent, "type", "combined", "type of statuses to be displayed [agent|workload|instance|storage|health|combined].")
 	f.IntVar(&c.backlogSize, "n", 20, "size of logs backlog.")
 	f.BoolVar(&c.isoTime, "utc", false, "display time as UTC in RFC3339 format")
+	f.StringVar(&c.fromDate, "from", "", "only show statuses set on or after this date")
+	f.StringVar(&c.toDate, "to", "", "only show statuses set on or before this date")
+}
+
+// historyEntityTag returns the tag of the entity of the given kind
+// with the given name.
+func historyEntityTag(kind, name string) (names.Tag, error) {
+	var valid bool
+	var tag names.Tag
+	switch kind {
+	case "unit":
+		if valid = names.IsValidUnit(name); valid {
+			tag = names.NewUnitTag(name)
+		}
+	case "machine":
+		if valid = names.IsValidMachine(name); valid {
+			tag = names.NewMachineTag(name)
+		}
+	case "service":
+		if valid = names.IsValidService(name); valid {
+			tag = names.NewServiceTag(name)
+		}
+	case "volume":
+		if valid = names.IsValidVolume(name); valid {
+			tag = names.NewVolumeTag(name)
+		}
+	case "filesystem":
+		if valid = names.IsValidFilesystem(name); valid {
+			tag = names.NewFilesystemTag(name)
+		}
+	default:
+		return nil, errors.Errorf("unexpected entity kind %q", kind)
+	}
+	if !valid {
+		return nil, errors.Errorf("invalid %s name %q", kind, name)
+	}
+	return tag, nil
 }
 
-func (c *statusHistoryCommand) Init(args []string) error {
-	switch {
-	case len(args) > 1:
-		return errors.Errorf("unexpected arguments after unit name.")
-	case len(args) == 0:
+// parseHistoryDate parses a date given to --from or --to. If end is
+// true and the date has no time, the end of the day is returned.
+func parseHistoryDate(value string, end bool) (*time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02 15:04:05", value, time.Local); err == nil {
+		return &t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return nil, errors.Errorf("invalid date %q", value)
+	}
+	if end {
+		t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	return &t, nil
+}
+
+func (c *statusHistoryCommand) Init(args []string) (err error) {
+	switch len(args) {
+	case 0:
 		return errors.Errorf("unit name is missing.")
+	case 1:
+		c.tag, err = historyEntityTag("unit", args[0])
+	case 2:
+		c.tag, err = historyEntityTag(args[0], args[1])
 	default:
-		c.unitName = args[0]
+		return errors.Errorf("unexpected arguments after entity name.")
+	}
+	if err != nil {
+		return err
+	}
+	if c.fromDate != "" {
+		if c.from, err = parseHistoryDate(c.fromDate, false); err != nil {
+			return err
+		}
+	}
+	if c.toDate != "" {
+		if c.to, err = parseHistoryDate(c.toDate, true); err != nil {
+			return err
+		}
 	}
 	// If use of ISO time not specified on command line,
 	// check env var.
 	if !c.isoTime {
-		var err error
 		envVarValue := os.Getenv(osenv.JujuStatusIsoTimeEnvKey)
 		if envVarValue != "" {
 			if c.isoTime, err = strconv.ParseBool(envVarValue); err != nil {
@@ -81,34 +188,54 @@ func (c *statusHistoryCommand) Init(args []string) error {
 	}
 	kind := params.HistoryKind(c.outputContent)
 	switch kind {
-	case params.KindCombined, params.KindAgent, params.KindWorkload:
+	case params.KindCombined, params.KindAgent, params.KindWorkload, params.KindInstance, params.KindStorage, params.KindHealth:
 		return nil
 
 	}
 	return errors.Errorf("unexpected status type %q", c.outputContent)
 }
 
+// statusHistory fetches the entity's status history, falling back to
+// the unit-only API call if the API server does not support others.
+func (c *statusHistoryCommand) statusHistory(apiclient statusHistoryAPI) ([]params.AgentStatus, error) {
+	kind := params.HistoryKind(c.outputContent)
+	result, err := apiclient.StatusHistory(params.EntityStatusHistoryArgs{
+		Tag:      c.tag.String(),
+		Kind:     kind,
+		Size:     c.backlogSize,
+		FromDate: c.from,
+		ToDate:   c.to,
+	})
+	if !errors.IsNotImplemented(err) {
+		return result.Statuses, err
+	}
+	unitTag, ok := c.tag.(names.UnitTag)
+	if !ok || c.from != nil || c.to != nil {
+		return nil, errors.New("this API server only supports unit status history without a time range")
+	}
+	unitResult, err := apiclient.UnitStatusHistory(kind, unitTag.Id(), c.backlogSize)
+	return unitResult.Statuses, err
+}
+
 func (c *statusHistoryCommand) Run(ctx *cmd.Context) error {
-	apiclient, err := c.NewAPIClient()
+	apiclient, err := newAPIClientForStatusHistory(c)
 	if err != nil {
 		return fmt.Errorf(connectionError, c.ConnectionName(), err)
 	}
 	defer apiclient.Close()
-	var statuses *params.UnitStatusHistory
-	kind := params.HistoryKind(c.outputContent)
-	statuses, err = apiclient.UnitStatusHistory(kind, c.unitName, c.backlogSize)
+	statuses, err := c.statusHistory(apiclient)
 	if err != nil {
-		if len(statuses.Statuses) == 0 {
+		if len(statuses) == 0 {
 			return errors.Trace(err)
 		}
 		// Display any error, but continue to print status if some was returned
 		fmt.Fprintf(ctx.Stderr, "%v\n", err)
-	} else if len(statuses.Statuses) == 0 {
+	} else if len(statuses) == 0 {
 		return errors.Errorf("no status history available")
 	}
 	table := [][]string{{"TIME", "TYPE", "STATUS", "MESSAGE"}}
 	lengths := []int{1, 1, 1, 1}
-	for _, v := range statuses.Statuses {
+	for _, v := range statuses {
 		fields := []string{common.FormatTime(v.Since, c.isoTime), string(v.Kind), string(v.Status), v.Info}
 		for k, v := range fields {
 			if len(v) > lengths[k] {
@@ -119,7 +246,7 @@ func (c *statusHistoryCommand) Run(ctx *cmd.Context) error {
 	}
 	f := fmt.Sprintf("%%-%ds\t%%-%ds\t%%-%ds\t%%-%ds\n", lengths[0], lengths[1], lengths[2], lengths[3])
 	for _, v := range table {
-		fmt.Printf(f, v[0], v[1], v[2], v[3])
+		fmt.Fprintf(ctx.Stdout, f, v[0], v[1], v[2], v[3])
 	}
 	return nil
 }
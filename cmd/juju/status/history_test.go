@@ -0,0 +1,172 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package status
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/testing"
+)
+
+type StatusHistorySuite struct {
+	testing.FakeJujuHomeSuite
+	api *fakeStatusHistoryAPI
+}
+
+var _ = gc.Suite(&StatusHistorySuite{})
+
+type fakeStatusHistoryAPI struct {
+	args           params.EntityStatusHistoryArgs
+	statuses       []params.AgentStatus
+	notImplemented bool
+}
+
+func (f *fakeStatusHistoryAPI) StatusHistory(args params.EntityStatusHistoryArgs) (*params.StatusHistoryResult, error) {
+	f.args = args
+	if f.notImplemented {
+		return &params.StatusHistoryResult{}, errors.NotImplementedf("StatusHistory")
+	}
+	return &params.StatusHistoryResult{Statuses: f.statuses}, nil
+}
+
+func (f *fakeStatusHistoryAPI) UnitStatusHistory(kind params.HistoryKind, unitName string, size int) (*params.UnitStatusHistory, error) {
+	f.args = params.EntityStatusHistoryArgs{
+		Tag:  names.NewUnitTag(unitName).String(),
+		Kind: kind,
+		Size: size,
+	}
+	return &params.UnitStatusHistory{Statuses: f.statuses}, nil
+}
+
+func (f *fakeStatusHistoryAPI) Close() error {
+	return nil
+}
+
+func (s *StatusHistorySuite) SetUpTest(c *gc.C) {
+	s.FakeJujuHomeSuite.SetUpTest(c)
+	since := time.Date(2015, 10, 1, 12, 0, 0, 0, time.UTC)
+	s.api = &fakeStatusHistoryAPI{
+		statuses: []params.AgentStatus{{
+			Status: params.Status("pending"),
+			Kind:   params.KindAgent,
+			Since:  &since,
+		}},
+	}
+	s.PatchValue(&newAPIClientForStatusHistory, func(_ *statusHistoryCommand) (statusHistoryAPI, error) {
+		return s.api, nil
+	})
+}
+
+func (s *StatusHistorySuite) TestInit(c *gc.C) {
+	for i, test := range []struct {
+		args []string
+		tag  string
+		err  string
+	}{{
+		args: []string{"wordpress/0"},
+		tag:  "unit-wordpress-0",
+	}, {
+		args: []string{"machine", "3"},
+		tag:  "machine-3",
+	}, {
+		args: []string{"service", "wordpress"},
+		tag:  "service-wordpress",
+	}, {
+		args: []string{"volume", "0/1"},
+		tag:  "volume-0-1",
+	}, {
+		args: []string{"filesystem", "2"},
+		tag:  "filesystem-2",
+	}, {
+		err: "unit name is missing.",
+	}, {
+		args: []string{"machine", "wordpress"},
+		err:  `invalid machine name "wordpress"`,
+	}, {
+		args: []string{"relation", "wordpress"},
+		err:  `unexpected entity kind "relation"`,
+	}, {
+		args: []string{"machine", "3", "4"},
+		err:  "unexpected arguments after entity name.",
+	}, {
+		args: []string{"--type", "instance", "machine", "3"},
+		tag:  "machine-3",
+	}, {
+		args: []string{"--type", "foo", "machine", "3"},
+		err:  `unexpected status type "foo"`,
+	}, {
+		args: []string{"--from", "yesterday", "machine", "3"},
+		err:  `invalid date "yesterday"`,
+	}} {
+		c.Logf("test %d: %v", i, test.args)
+		command := &statusHistoryCommand{}
+		err := testing.InitCommand(envcmd.Wrap(command), test.args)
+		if test.err != "" {
+			c.Check(err, gc.ErrorMatches, test.err)
+			continue
+		}
+		c.Assert(err, jc.ErrorIsNil)
+		c.Check(command.tag.String(), gc.Equals, test.tag)
+	}
+}
+
+func (s *StatusHistorySuite) TestParseHistoryDate(c *gc.C) {
+	t, err := parseHistoryDate("2015-10-01T12:00:00Z", true)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(t.Equal(time.Date(2015, 10, 1, 12, 0, 0, 0, time.UTC)), jc.IsTrue)
+
+	t, err = parseHistoryDate("2015-10-01 12:30:00", false)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(t.Equal(time.Date(2015, 10, 1, 12, 30, 0, 0, time.Local)), jc.IsTrue)
+
+	t, err = parseHistoryDate("2015-10-01", false)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(t.Equal(time.Date(2015, 10, 1, 0, 0, 0, 0, time.Local)), jc.IsTrue)
+
+	t, err = parseHistoryDate("2015-10-01", true)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(t.Equal(time.Date(2015, 10, 2, 0, 0, 0, 0, time.Local).Add(-time.Nanosecond)), jc.IsTrue)
+}
+
+func (s *StatusHistorySuite) TestRun(c *gc.C) {
+	ctx, err := testing.RunCommand(c, NewStatusHistoryCommand(),
+		"--utc", "-n", "5", "--from", "2015-10-01T00:00:00Z", "machine", "3")
+	c.Assert(err, jc.ErrorIsNil)
+	from := time.Date(2015, 10, 1, 0, 0, 0, 0, time.UTC)
+	c.Check(s.api.args.Tag, gc.Equals, "machine-3")
+	c.Check(s.api.args.Kind, gc.Equals, params.KindCombined)
+	c.Check(s.api.args.Size, gc.Equals, 5)
+	c.Check(s.api.args.FromDate.Equal(from), jc.IsTrue)
+	c.Check(s.api.args.ToDate, gc.IsNil)
+	c.Check(testing.Stdout(ctx), gc.Equals, ""+
+		"TIME                \tTYPE \tSTATUS \tMESSAGE\n"+
+		"2015-10-01 12:00:00Z\tagent\tpending\t \n")
+}
+
+func (s *StatusHistorySuite) TestRunUnitFallback(c *gc.C) {
+	s.api.notImplemented = true
+	_, err := testing.RunCommand(c, NewStatusHistoryCommand(), "--type", "agent", "wordpress/0")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(s.api.args, jc.DeepEquals, params.EntityStatusHistoryArgs{
+		Tag:  "unit-wordpress-0",
+		Kind: params.KindAgent,
+		Size: 20,
+	})
+
+	_, err = testing.RunCommand(c, NewStatusHistoryCommand(), "machine", "3")
+	c.Assert(err, gc.ErrorMatches, "this API server only supports unit status history without a time range")
+}
+
+func (s *StatusHistorySuite) TestRunNoHistory(c *gc.C) {
+	s.api.statuses = nil
+	_, err := testing.RunCommand(c, NewStatusHistoryCommand(), "service", "wordpress")
+	c.Assert(err, gc.ErrorMatches, "no status history available")
+}
@@ -42,6 +42,10 @@ func FormatTabular(value interface{}) ([]byte, error) {
 			p("UPGRADE-AVAILABLE")
 			p(envStatus.AvailableVersion)
 		}
+		if envStatus.CertificateWarning != "" {
+			p("CERTIFICATE-WARNING")
+			p(envStatus.CertificateWarning)
+		}
 		p()
 		tw.Flush()
 	}
@@ -58,15 +62,42 @@ func FormatTabular(value interface{}) ([]byte, error) {
 	}
 	tw.Flush()
 
+	// See if we have new or old data; that determines what data we can display.
+	newStatus := false
+	for _, u := range units {
+		if u.AgentStatusInfo.Current != "" {
+			newStatus = true
+			break
+		}
+	}
+	// The health column is only shown if some unit has reported the
+	// results of its charm's health checks.
+	withHealth := false
+	for _, u := range units {
+		if reportsHealth(u) {
+			withHealth = true
+			break
+		}
+	}
+
 	pUnit := func(name string, u unitStatus, level int) {
 		message := u.WorkloadStatusInfo.Message
 		agentDoing := agentDoing(u.AgentStatusInfo)
 		if agentDoing != "" {
 			message = fmt.Sprintf("(%s) %s", agentDoing, message)
 		}
-		p(
+		values := []interface{}{
 			indent("", level*2, name),
 			u.WorkloadStatusInfo.Current,
+		}
+		if withHealth {
+			var health params.Status
+			if u.HealthStatusInfo != nil {
+				health = u.HealthStatusInfo.Current
+			}
+			values = append(values, health)
+		}
+		values = append(values,
 			u.AgentStatusInfo.Current,
 			u.AgentStatusInfo.Version,
 			u.Machine,
@@ -74,19 +105,16 @@ func FormatTabular(value interface{}) ([]byte, error) {
 			u.PublicAddress,
 			message,
 		)
+		p(values...)
 	}
 
-	// See if we have new or old data; that determines what data we can display.
-	newStatus := false
-	for _, u := range units {
-		if u.AgentStatusInfo.Current != "" {
-			newStatus = true
-			break
-		}
-	}
 	var header []string
 	if newStatus {
-		header = []string{"ID", "WORKLOAD-STATE", "AGENT-STATE", "VERSION", "MACHINE", "PORTS", "PUBLIC-ADDRESS", "MESSAGE"}
+		header = []string{"ID", "WORKLOAD-STATE"}
+		if withHealth {
+			header = append(header, "HEALTH")
+		}
+		header = append(header, "AGENT-STATE", "VERSION", "MACHINE", "PORTS", "PUBLIC-ADDRESS", "MESSAGE")
 	} else {
 		header = []string{"ID", "STATE", "VERSION", "MACHINE", "PORTS", "PUBLIC-ADDRESS"}
 	}
@@ -121,6 +149,20 @@ func FormatTabular(value interface{}) ([]byte, error) {
 	return out.Bytes(), nil
 }
 
+// reportsHealth returns whether the unit or any of its subordinates
+// has reported the results of its charm's health checks.
+func reportsHealth(u unitStatus) bool {
+	if u.HealthStatusInfo != nil {
+		return true
+	}
+	for _, sub := range u.Subordinates {
+		if reportsHealth(sub) {
+			return true
+		}
+	}
+	return false
+}
+
 // agentDoing returns what hook or action, if any,
 // the agent is currently executing.
 // The hook name or action is extracted from the agent message.
@@ -22,6 +22,7 @@ var logger = loggo.GetLogger("juju.cmd.juju.status")
 
 type statusAPI interface {
 	Status(patterns []string) (*params.FullStatus, error)
+	FilteredStatus(patterns []string, selector string) (*params.FullStatus, error)
 	Close() error
 }
 
@@ -35,6 +36,7 @@ type statusCommand struct {
 	envcmd.EnvCommandBase
 	out      cmd.Output
 	patterns []string
+	selector string
 	isoTime  bool
 	api      statusAPI
 }
@@ -70,6 +72,12 @@ will be displayed.
 Wildcards ('*') may be specified in service/unit names to match any sequence
 of characters. For example, 'nova-*' will match any service whose name begins
 with 'nova-': 'nova-compute', 'nova-volume', etc.
+
+A label selector may be given with -l to filter the status to the machines,
+services and units whose labels match it, in the same way. For example,
+'-l tier=web,env!=canary' matches the units labelled (or whose service is
+labelled) tier=web and not labelled env=canary. See "juju help set-labels"
+for details of the selector syntax.
 `
 
 func (c *statusCommand) Info() *cmd.Info {
@@ -84,6 +92,8 @@ func (c *statusCommand) Info() *cmd.Info {
 
 func (c *statusCommand) SetFlags(f *gnuflag.FlagSet) {
 	f.BoolVar(&c.isoTime, "utc", false, "display time as UTC in RFC3339 format")
+	f.StringVar(&c.selector, "l", "", "only show entities whose labels match the selector")
+	f.StringVar(&c.selector, "selector", "", "")
 
 	oneLineFormatter := FormatOneline
 	defaultFormat := "yaml"
@@ -137,7 +147,12 @@ func (c *statusCommand) Run(ctx *cmd.Context) error {
 	}
 	defer apiclient.Close()
 
-	status, err := apiclient.Status(c.patterns)
+	var status *params.FullStatus
+	if c.selector != "" {
+		status, err = apiclient.FilteredStatus(c.patterns, c.selector)
+	} else {
+		status, err = apiclient.Status(c.patterns)
+	}
 	if err != nil {
 		if status == nil {
 			// Status call completely failed, there is nothing to report
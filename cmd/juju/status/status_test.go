@@ -2869,6 +2869,10 @@ func (a *fakeApiClient) Status(patterns []string) (*params.FullStatus, error) {
 	return a.statusReturn, nil
 }
 
+func (a *fakeApiClient) FilteredStatus(patterns []string, selector string) (*params.FullStatus, error) {
+	return a.Status(patterns)
+}
+
 func (a *fakeApiClient) Close() error {
 	a.closeCalled = true
 	return nil
@@ -3302,6 +3306,83 @@ ID         STATE DNS INS-ID SERIES AZ
 `[1:])
 }
 
+func (s *StatusSuite) TestFormatTabularCertificateWarning(c *gc.C) {
+	status := formattedStatus{
+		EnvironmentStatus: &environmentStatus{
+			CertificateWarning: "CA certificate expires 2016-01-01T00:00:00Z",
+		},
+	}
+	out, err := FormatTabular(status)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(out), jc.HasPrefix, `
+[Environment]                               
+CERTIFICATE-WARNING                         
+CA certificate expires 2016-01-01T00:00:00Z 
+
+`[1:])
+}
+
+func (s *StatusSuite) TestFormatTabularHealth(c *gc.C) {
+	status := formattedStatus{
+		Services: map[string]serviceStatus{
+			"foo": serviceStatus{
+				Units: map[string]unitStatus{
+					"foo/0": unitStatus{
+						AgentStatusInfo: statusInfoContents{
+							Current: params.StatusIdle,
+						},
+						WorkloadStatusInfo: statusInfoContents{
+							Current: params.StatusActive,
+						},
+						HealthStatusInfo: &statusInfoContents{
+							Current: params.StatusHealthy,
+						},
+					},
+					"foo/1": unitStatus{
+						AgentStatusInfo: statusInfoContents{
+							Current: params.StatusIdle,
+						},
+						WorkloadStatusInfo: statusInfoContents{
+							Current: params.StatusBlocked,
+							Message: `health check failed: check "web" failed`,
+						},
+						HealthStatusInfo: &statusInfoContents{
+							Current: params.StatusUnhealthy,
+							Message: `check "web" failed`,
+						},
+						Subordinates: map[string]unitStatus{
+							"bar/0": unitStatus{
+								AgentStatusInfo: statusInfoContents{
+									Current: params.StatusIdle,
+								},
+								WorkloadStatusInfo: statusInfoContents{
+									Current: params.StatusActive,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	out, err := FormatTabular(status)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(out), gc.Equals, `
+[Services] 
+NAME       STATUS EXPOSED CHARM 
+foo               false         
+
+[Units] 
+ID      WORKLOAD-STATE HEALTH    AGENT-STATE VERSION MACHINE PORTS PUBLIC-ADDRESS MESSAGE                                 
+foo/0   active         healthy   idle                                                                                     
+foo/1   blocked        unhealthy idle                                             health check failed: check "web" failed 
+  bar/0 active                   idle                                                                                     
+
+[Machines] 
+ID         STATE DNS INS-ID SERIES AZ 
+`[1:])
+}
+
 func (s *StatusSuite) TestStatusWithNilStatusApi(c *gc.C) {
 	ctx := s.newContext(c)
 	defer s.resetContext(c, ctx)
@@ -3464,6 +3545,28 @@ func (s *StatusSuite) TestFilterToService(c *gc.C) {
 	c.Assert(string(stdout), gc.Equals, expected[1:])
 }
 
+// Scenario: User filters with a label selector
+func (s *StatusSuite) TestFilterToLabelSelector(c *gc.C) {
+	ctx := s.FilteringTestSetup(c)
+	defer s.resetContext(c, ctx)
+
+	// Given the "wordpress" service is labelled tier=web
+	wordpress, err := s.State.Service("wordpress")
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.SetLabels(wordpress, map[string]string{"tier": "web"})
+	c.Assert(err, jc.ErrorIsNil)
+	// When I run juju status --format oneline -l tier=web
+	_, stdout, stderr := runStatus(c, "--format", "oneline", "-l", "tier=web")
+	c.Assert(stderr, gc.IsNil)
+	// Then I should receive output prefixed with:
+	const expected = `
+
+- wordpress/0: dummyenv-1.dns (started)
+  - logging/0: dummyenv-1.dns (started)
+`
+	c.Assert(string(stdout), gc.Equals, expected[1:])
+}
+
 // Scenario: User filters to exposed services
 func (s *StatusSuite) TestFilterToExposedService(c *gc.C) {
 	ctx := s.FilteringTestSetup(c)
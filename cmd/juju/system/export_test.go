@@ -7,6 +7,7 @@ import (
 	"github.com/juju/cmd"
 
 	"github.com/juju/juju/api"
+	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/cmd/envcmd"
 	"github.com/juju/juju/environs/configstore"
 )
@@ -14,6 +15,7 @@ import (
 var (
 	SetConfigSpecialCaseDefaults = setConfigSpecialCaseDefaults
 	UserCurrent                  = &userCurrent
+	MigratePollInterval          = &migratePollInterval
 )
 
 // NewListCommand returns a ListCommand with the configstore provided as specified.
@@ -129,3 +131,11 @@ func FmtEnvStatus(data EnvData) string {
 func NewData(api destroySystemAPI, ctrUUID string) (ctrData, []envData, error) {
 	return newData(api, ctrUUID)
 }
+
+// NewMigrateCommand returns a migrate command with the function used to
+// connect to each system mocked out.
+func NewMigrateCommand(openAPI func(systemName string) (MigrateAPI, params.MigrationControllerInfo, error)) cmd.Command {
+	return envcmd.WrapSystem(&migrateCommand{
+		openAPI: openAPI,
+	})
+}
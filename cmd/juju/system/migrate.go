@@ -0,0 +1,441 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package system
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"gopkg.in/juju/charm.v6-unstable"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/api/migration"
+	"github.com/juju/juju/api/systemmanager"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/state/description"
+)
+
+func newMigrateCommand() cmd.Command {
+	return envcmd.WrapSystem(&migrateCommand{})
+}
+
+// migrateCommand moves an environment of the current system to
+// another system.
+type migrateCommand struct {
+	envcmd.SysCommandBase
+
+	// openAPI, if set, is used instead of connecting to the named
+	// system; it is set in tests.
+	openAPI func(systemName string) (MigrateAPI, params.MigrationControllerInfo, error)
+
+	envName      string
+	owner        string
+	envUUID      string
+	targetSystem string
+	abort        bool
+	timeout      time.Duration
+}
+
+// MigrateAPI defines the methods of the migration and system manager
+// APIs that the migrate command calls on both systems.
+type MigrateAPI interface {
+	Close() error
+	AllEnvironments() ([]base.UserEnvironment, error)
+	Start(env names.EnvironTag, target params.MigrationControllerInfo) (params.MigrationStatus, error)
+	Export(env names.EnvironTag) ([]byte, error)
+	Import(data []byte, source params.MigrationControllerInfo) (params.MigrationStatus, error)
+	CharmArchive(env names.EnvironTag, curl *charm.URL) (io.ReadCloser, error)
+	AddCharmArchive(env names.EnvironTag, curl *charm.URL, archive io.ReadSeeker) error
+	SetPhase(env names.EnvironTag, phase string) (params.MigrationStatus, error)
+	Abort(env names.EnvironTag, reason string) (params.MigrationStatus, error)
+	Status(env names.EnvironTag) (params.MigrationStatus, error)
+	RemoveEnvironment(env names.EnvironTag) error
+}
+
+// The phases of a migration, as reported by the systems taking part
+// in it.
+const (
+	migrationImport  = "import"
+	migrationRepoint = "repoint"
+	migrationDone    = "done"
+	migrationAborted = "aborted"
+)
+
+// migratePollInterval is how often the migrate command checks which
+// agents are still connected to a system.
+var migratePollInterval = 5 * time.Second
+
+var migrateDoc = `
+Moves an environment of the current system to another system, without
+redeploying it and with its agents and workloads left running.
+
+The migration proceeds in phases:
+
+  1. The environment is frozen on the current system: changes, removals
+     and its destruction are blocked.
+  2. The environment is described by the current system and built from
+     that description on the target system, and the archives of its
+     charms are copied over.
+  3. The environment is activated on the target system, and its agents
+     are told to connect to the target system's API servers, which they
+     trust alongside the current ones.
+  4. Once no agent is connected to the current system any more, the
+     environment is removed from it, leaving its machines alone.
+
+If any phase fails, or the agents do not all move within --timeout, the
+migration is aborted: whatever was imported into the target system is
+removed, the agents are sent back to the current system, and the blocks
+set for the migration are removed. A migration that was interrupted can
+be aborted with --abort.
+
+You must be an administrator of both systems, and the environment's local
+users must exist on the target system. The target system must be able to
+provide the tools the environment's agents run. Environments using
+storage or resources cannot be migrated yet; the environment's logs, status history
+and actions are not moved.
+
+The environment may be specified by name, as [owner/]name, or by UUID.
+
+Examples:
+
+    juju system migrate test other-system
+    juju system migrate --abort test other-system
+
+See Also:
+    juju help juju-systems
+    juju help system environments
+    juju help system use-environment
+`
+
+// Info implements Command.Info.
+func (c *migrateCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "migrate",
+		Args:    "<environment> <target system>",
+		Purpose: "move an environment to another system",
+		Doc:     migrateDoc,
+	}
+}
+
+// SetFlags implements Command.SetFlags.
+func (c *migrateCommand) SetFlags(f *gnuflag.FlagSet) {
+	f.BoolVar(&c.abort, "abort", false, "abort an interrupted migration of the environment")
+	f.DurationVar(&c.timeout, "timeout", 10*time.Minute, "how long to wait for the agents to move to the target system")
+}
+
+// Init implements Command.Init.
+func (c *migrateCommand) Init(args []string) error {
+	switch len(args) {
+	case 0:
+		return errors.New("no environment specified")
+	case 1:
+		return errors.New("no target system specified")
+	}
+	name, target, args := args[0], args[1], args[2:]
+	if bits := strings.SplitN(name, "/", 2); len(bits) == 2 {
+		if !names.IsValidUser(bits[0]) {
+			return errors.Errorf("%q is not a valid user", bits[0])
+		}
+		c.owner, name = bits[0], bits[1]
+	}
+	if names.IsValidEnvironment(name) {
+		c.envUUID = name
+	} else {
+		c.envName = name
+	}
+	c.targetSystem = target
+	if c.timeout <= 0 {
+		return errors.New("--timeout must be positive")
+	}
+	return cmd.CheckEmpty(args)
+}
+
+// Run implements Command.Run.
+func (c *migrateCommand) Run(ctx *cmd.Context) error {
+	if c.targetSystem == c.SystemName() {
+		return errors.New("cannot migrate an environment to its own system")
+	}
+	source, sourceInfo, err := c.open(c.SystemName())
+	if err != nil {
+		return errors.Annotatef(err, "cannot connect to system %q", c.SystemName())
+	}
+	defer source.Close()
+	target, targetInfo, err := c.open(c.targetSystem)
+	if err != nil {
+		return errors.Annotatef(err, "cannot connect to system %q", c.targetSystem)
+	}
+	defer target.Close()
+
+	env, err := c.findEnvironment(source)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	m := &migrator{
+		ctx:          ctx,
+		env:          env,
+		source:       source,
+		target:       target,
+		targetSystem: c.targetSystem,
+		timeout:      c.timeout,
+	}
+	if c.abort {
+		return m.abort("aborted by user")
+	}
+	return m.migrate(sourceInfo, targetInfo)
+}
+
+// open connects to the named system, returning its API and how
+// agents reach it.
+func (c *migrateCommand) open(systemName string) (MigrateAPI, params.MigrationControllerInfo, error) {
+	if c.openAPI != nil {
+		return c.openAPI(systemName)
+	}
+	var info params.MigrationControllerInfo
+	storeInfo, err := envcmd.ConnectionInfoForName(systemName)
+	if err != nil {
+		return nil, info, errors.Trace(err)
+	}
+	endpoint := storeInfo.APIEndpoint()
+	serverUUID := endpoint.ServerUUID
+	if serverUUID == "" {
+		serverUUID = endpoint.EnvironUUID
+	}
+	root, err := c.NewAPIRoot(systemName)
+	if err != nil {
+		return nil, info, errors.Trace(err)
+	}
+	info = params.MigrationControllerInfo{
+		ControllerTag: names.NewEnvironTag(serverUUID).String(),
+		Addrs:         params.FromNetworkHostsPorts(root.APIHostPorts()),
+		CACert:        endpoint.CACert,
+	}
+	return &migrateClient{
+		Client:    migration.NewClient(root),
+		envLister: systemmanager.NewClient(root),
+	}, info, nil
+}
+
+// migrateClient combines the APIs used by the migrate command.
+type migrateClient struct {
+	*migration.Client
+	envLister *systemmanager.Client
+}
+
+func (c *migrateClient) AllEnvironments() ([]base.UserEnvironment, error) {
+	return c.envLister.AllEnvironments()
+}
+
+// findEnvironment returns the tag of the environment to migrate.
+func (c *migrateCommand) findEnvironment(api MigrateAPI) (names.EnvironTag, error) {
+	envs, err := api.AllEnvironments()
+	if err != nil {
+		return names.EnvironTag{}, errors.Trace(err)
+	}
+	var matches []base.UserEnvironment
+	for _, env := range envs {
+		switch {
+		case c.envUUID != "" && env.UUID != c.envUUID:
+		case c.envName != "" && env.Name != c.envName:
+		case c.owner != "" && env.Owner != names.NewUserTag(c.owner).Canonical():
+		default:
+			matches = append(matches, env)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return names.EnvironTag{}, errors.NotFoundf("environment %q on system %q", c.envUUID+c.envName, c.SystemName())
+	case 1:
+		return names.NewEnvironTag(matches[0].UUID), nil
+	}
+	return names.EnvironTag{}, errors.Errorf("multiple environments matched %q; specify the owner or the UUID", c.envName)
+}
+
+// migrator drives the migration of an environment between systems.
+type migrator struct {
+	ctx          *cmd.Context
+	env          names.EnvironTag
+	source       MigrateAPI
+	target       MigrateAPI
+	targetSystem string
+	timeout      time.Duration
+}
+
+func (m *migrator) migrate(sourceInfo, targetInfo params.MigrationControllerInfo) error {
+	m.ctx.Infof("Freezing environment %s", m.env.Id())
+	if _, err := m.source.Start(m.env, targetInfo); err != nil {
+		return errors.Annotate(err, "cannot start migration")
+	}
+	m.ctx.Infof("Exporting environment")
+	data, err := m.source.Export(m.env)
+	if err == nil {
+		_, err = m.source.SetPhase(m.env, migrationImport)
+	}
+	if err != nil {
+		return m.fail(errors.Annotate(err, "cannot export environment"))
+	}
+	m.ctx.Infof("Importing environment into system %q", m.targetSystem)
+	if _, err := m.target.Import(data, sourceInfo); err != nil {
+		return m.fail(errors.Annotate(err, "cannot import environment"))
+	}
+	m.ctx.Infof("Copying charm archives")
+	if err := m.copyCharmArchives(data); err != nil {
+		return m.fail(errors.Annotate(err, "cannot import environment"))
+	}
+	if _, err := m.target.SetPhase(m.env, migrationRepoint); err != nil {
+		return m.fail(errors.Annotate(err, "cannot activate environment"))
+	}
+	m.ctx.Infof("Redirecting agents to system %q", m.targetSystem)
+	if _, err := m.source.SetPhase(m.env, migrationRepoint); err != nil {
+		return m.fail(errors.Annotate(err, "cannot redirect agents"))
+	}
+	if err := m.waitForAgents(m.source, "source"); err != nil {
+		return m.fail(err)
+	}
+
+	m.ctx.Infof("Removing environment from the source system")
+	if _, err := m.source.SetPhase(m.env, migrationDone); err != nil {
+		return m.fail(errors.Annotate(err, "cannot complete migration"))
+	}
+	if _, err := m.target.SetPhase(m.env, migrationDone); err != nil {
+		// The environment now lives on the target; all that is
+		// missing is the target's record of the migration.
+		logger.Warningf("cannot complete migration on system %q: %v", m.targetSystem, err)
+	}
+	if err := m.source.RemoveEnvironment(m.env); err != nil {
+		return errors.Annotate(err, "environment migrated, but cannot remove it from the source system")
+	}
+	fmt.Fprintf(m.ctx.Stdout, "Environment %s migrated to system %q.\n", m.env.Id(), m.targetSystem)
+	return nil
+}
+
+// copyCharmArchives copies the archives of the charms in the
+// environment description from the source system to the target
+// system.
+func (m *migrator) copyCharmArchives(data []byte) error {
+	desc, err := description.Deserialize(data)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for _, ch := range desc.Charms {
+		if ch.StoragePath == "" {
+			continue
+		}
+		curl, err := charm.ParseURL(ch.URL)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if err := m.copyCharmArchive(curl); err != nil {
+			return errors.Annotatef(err, "cannot copy archive of charm %q", curl)
+		}
+	}
+	return nil
+}
+
+// copyCharmArchive downloads the archive of a charm from the source
+// system and uploads it to the target system.
+func (m *migrator) copyCharmArchive(curl *charm.URL) error {
+	r, err := m.source.CharmArchive(m.env, curl)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer r.Close()
+	archive, err := ioutil.TempFile("", "charm")
+	if err != nil {
+		return errors.Annotate(err, "cannot create temp file")
+	}
+	defer os.Remove(archive.Name())
+	defer archive.Close()
+	if _, err := io.Copy(archive, r); err != nil {
+		return errors.Annotate(err, "cannot download archive")
+	}
+	if _, err := archive.Seek(0, 0); err != nil {
+		return errors.Annotate(err, "cannot rewind archive")
+	}
+	return errors.Trace(m.target.AddCharmArchive(m.env, curl, archive))
+}
+
+// fail aborts the migration after err, and returns err.
+func (m *migrator) fail(err error) error {
+	m.ctx.Infof("Migration failed, aborting: %v", err)
+	if abortErr := m.abort(err.Error()); abortErr != nil {
+		logger.Errorf("cannot abort migration: %v", abortErr)
+	}
+	return err
+}
+
+// abort aborts the migration on both systems, in reverse order, and
+// removes the environment from the target system once no agents are
+// connected to it any more.
+func (m *migrator) abort(reason string) error {
+	sourceStatus, err := m.source.Status(m.env)
+	if params.IsCodeNotFound(err) {
+		sourceStatus = params.MigrationStatus{Phase: migrationAborted}
+	} else if err != nil {
+		return errors.Annotate(err, "cannot get migration status of the source system")
+	}
+	if sourceStatus.Phase == migrationDone {
+		return errors.New("cannot abort migration: migration is complete")
+	}
+
+	targetStatus, err := m.target.Status(m.env)
+	if params.IsCodeNotFound(err) {
+		targetStatus = params.MigrationStatus{Phase: migrationAborted}
+	} else if err != nil {
+		return errors.Annotate(err, "cannot get migration status of the target system")
+	}
+	if targetStatus.Phase != migrationAborted && targetStatus.Phase != migrationDone {
+		if targetStatus, err = m.target.Abort(m.env, reason); err != nil {
+			return errors.Annotate(err, "cannot abort migration on the target system")
+		}
+	}
+	if sourceStatus.Phase != migrationAborted {
+		if _, err := m.source.Abort(m.env, reason); err != nil {
+			return errors.Annotate(err, "cannot abort migration on the source system")
+		}
+	}
+
+	// Agents that already moved to the target system are sent back
+	// by it before the environment is removed there.
+	if targetStatus.Role == "target" && targetStatus.Phase == migrationAborted {
+		if err := m.waitForAgents(m.target, "target"); err != nil {
+			return errors.Trace(err)
+		}
+		if err := m.target.RemoveEnvironment(m.env); err != nil {
+			return errors.Annotate(err, "cannot remove environment from the target system")
+		}
+	}
+	m.ctx.Infof("Migration of environment %s aborted", m.env.Id())
+	return nil
+}
+
+// waitForAgents waits until none of the environment's agents are
+// connected to the given system.
+func (m *migrator) waitForAgents(api MigrateAPI, which string) error {
+	timeout := time.After(m.timeout)
+	for {
+		status, err := api.Status(m.env)
+		if err != nil {
+			return errors.Annotatef(err, "cannot get migration status of the %s system", which)
+		}
+		if len(status.ConnectedAgents) == 0 {
+			return nil
+		}
+		m.ctx.Verbosef("waiting for %d agents to leave the %s system", len(status.ConnectedAgents), which)
+		select {
+		case <-time.After(migratePollInterval):
+		case <-timeout:
+			return errors.Errorf("timed out waiting for agents to leave the %s system: %s",
+				which, strings.Join(status.ConnectedAgents, ", "))
+		}
+	}
+}
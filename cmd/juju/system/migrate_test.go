@@ -0,0 +1,368 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package system_test
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"time"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/charm.v6-unstable"
+
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/cmd/juju/system"
+	"github.com/juju/juju/state/description"
+	"github.com/juju/juju/testing"
+)
+
+const migrateEnvUUID = "deadbeef-0bad-400d-8000-4b1d0d06f00d"
+
+type migrateSuite struct {
+	testing.FakeJujuHomeSuite
+	calls  []string
+	source *fakeMigrateAPI
+	target *fakeMigrateAPI
+}
+
+var _ = gc.Suite(&migrateSuite{})
+
+func (s *migrateSuite) SetUpTest(c *gc.C) {
+	s.FakeJujuHomeSuite.SetUpTest(c)
+	err := envcmd.WriteCurrentSystem("old")
+	c.Assert(err, jc.ErrorIsNil)
+	s.PatchValue(system.MigratePollInterval, time.Millisecond)
+
+	exported, err := description.Serialize(&description.Environment{
+		Version: description.Version,
+		UUID:    migrateEnvUUID,
+		Name:    "web",
+		Owner:   "bob@local",
+		Charms: []description.Charm{{
+			URL:         "cs:quantal/wordpress-3",
+			StoragePath: "charms/wordpress",
+		}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.calls = nil
+	s.source = &fakeMigrateAPI{
+		name:  "old",
+		calls: &s.calls,
+		envs: []base.UserEnvironment{{
+			Name:  "web",
+			UUID:  migrateEnvUUID,
+			Owner: "bob@local",
+		}, {
+			Name:  "web",
+			UUID:  "deadbeef-0bad-400d-8000-4b1d0d06f00e",
+			Owner: "mary@local",
+		}},
+		status:   params.MigrationStatus{Role: "source"},
+		exported: exported,
+		archives: map[string]string{"cs:quantal/wordpress-3": "wordpress archive"},
+	}
+	s.target = &fakeMigrateAPI{
+		name:     "new",
+		calls:    &s.calls,
+		status:   params.MigrationStatus{Role: "target"},
+		archives: make(map[string]string),
+	}
+}
+
+func (s *migrateSuite) newCommand() cmd.Command {
+	return system.NewMigrateCommand(func(systemName string) (system.MigrateAPI, params.MigrationControllerInfo, error) {
+		info := params.MigrationControllerInfo{ControllerTag: systemName}
+		switch systemName {
+		case "old":
+			return s.source, info, nil
+		case "new":
+			return s.target, info, nil
+		}
+		return nil, info, errors.NotFoundf("system %q", systemName)
+	})
+}
+
+func (s *migrateSuite) run(c *gc.C, args ...string) (*cmd.Context, error) {
+	return testing.RunCommand(c, s.newCommand(), args...)
+}
+
+func (s *migrateSuite) TestInit(c *gc.C) {
+	for i, test := range []struct {
+		args []string
+		err  string
+	}{{
+		err: "no environment specified",
+	}, {
+		args: []string{"web"},
+		err:  "no target system specified",
+	}, {
+		args: []string{"web", "new", "extra"},
+		err:  `unrecognized args: \["extra"\]`,
+	}, {
+		args: []string{"bob!/web", "new"},
+		err:  `"bob!" is not a valid user`,
+	}, {
+		args: []string{"--timeout", "0", "web", "new"},
+		err:  "--timeout must be positive",
+	}} {
+		c.Logf("test %d: %v", i, test.args)
+		err := testing.InitCommand(s.newCommand(), test.args)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
+
+func (s *migrateSuite) TestMigrate(c *gc.C) {
+	s.source.connected = [][]string{{"machine-0", "unit-mysql-0"}, {"unit-mysql-0"}, nil}
+	ctx, err := s.run(c, "bob/web", "new")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(testing.Stdout(ctx), gc.Equals, "Environment "+migrateEnvUUID+` migrated to system "new".`+"\n")
+	c.Assert(s.calls, jc.DeepEquals, []string{
+		"old AllEnvironments",
+		"old Start new",
+		"old Export",
+		"old SetPhase import",
+		"new Import old",
+		"old CharmArchive cs:quantal/wordpress-3",
+		"new AddCharmArchive cs:quantal/wordpress-3",
+		"new SetPhase repoint",
+		"old SetPhase repoint",
+		"old Status",
+		"old Status",
+		"old Status",
+		"old SetPhase done",
+		"new SetPhase done",
+		"old RemoveEnvironment",
+	})
+	c.Assert(s.target.archives, jc.DeepEquals, s.source.archives)
+}
+
+func (s *migrateSuite) TestMigrateByUUID(c *gc.C) {
+	_, err := s.run(c, migrateEnvUUID, "new")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.target.imported, gc.Equals, string(s.source.exported))
+}
+
+func (s *migrateSuite) TestMigrateAmbiguousName(c *gc.C) {
+	_, err := s.run(c, "web", "new")
+	c.Assert(err, gc.ErrorMatches, `multiple environments matched "web"; specify the owner or the UUID`)
+	c.Assert(s.calls, jc.DeepEquals, []string{"old AllEnvironments"})
+}
+
+func (s *migrateSuite) TestMigrateUnknownEnvironment(c *gc.C) {
+	_, err := s.run(c, "bob/db", "new")
+	c.Assert(err, gc.ErrorMatches, `environment "db" on system "old" not found`)
+}
+
+func (s *migrateSuite) TestMigrateToSameSystem(c *gc.C) {
+	_, err := s.run(c, "bob/web", "old")
+	c.Assert(err, gc.ErrorMatches, "cannot migrate an environment to its own system")
+	c.Assert(s.calls, gc.HasLen, 0)
+}
+
+func (s *migrateSuite) TestMigrateUnknownTarget(c *gc.C) {
+	_, err := s.run(c, "bob/web", "other")
+	c.Assert(err, gc.ErrorMatches, `cannot connect to system "other": system "other" not found`)
+}
+
+func (s *migrateSuite) TestMigrateStartFails(c *gc.C) {
+	s.source.errors = map[string]error{"Start": common.ErrPerm}
+	_, err := s.run(c, "bob/web", "new")
+	c.Assert(err, gc.ErrorMatches, "cannot start migration: permission denied")
+	c.Assert(s.calls, jc.DeepEquals, []string{
+		"old AllEnvironments",
+		"old Start new",
+	})
+}
+
+func (s *migrateSuite) TestMigrateImportFails(c *gc.C) {
+	s.target.errors = map[string]error{
+		"Import": errors.New("no room"),
+		"Status": &params.Error{Code: params.CodeNotFound},
+	}
+	_, err := s.run(c, "bob/web", "new")
+	c.Assert(err, gc.ErrorMatches, "cannot import environment: no room")
+	c.Assert(s.calls, jc.DeepEquals, []string{
+		"old AllEnvironments",
+		"old Start new",
+		"old Export",
+		"old SetPhase import",
+		"new Import old",
+		"old Status",
+		"new Status",
+		"old Abort cannot import environment: no room",
+	})
+}
+
+func (s *migrateSuite) TestMigrateCharmArchiveFails(c *gc.C) {
+	s.target.errors = map[string]error{"AddCharmArchive": errors.New("SHA256 mismatch")}
+	_, err := s.run(c, "bob/web", "new")
+	c.Assert(err, gc.ErrorMatches, `cannot import environment: cannot copy archive of charm "cs:quantal/wordpress-3": SHA256 mismatch`)
+	c.Assert(s.target.status.Phase, gc.Equals, "aborted")
+	c.Assert(s.calls[len(s.calls)-2:], jc.DeepEquals, []string{
+		"new Status",
+		"new RemoveEnvironment",
+	})
+}
+
+func (s *migrateSuite) TestMigrateAgentsTimeout(c *gc.C) {
+	s.source.connected = [][]string{{"machine-0"}}
+	_, err := s.run(c, "--timeout", "10ms", "bob/web", "new")
+	c.Assert(err, gc.ErrorMatches, "timed out waiting for agents to leave the source system: machine-0")
+	c.Assert(s.source.status.Phase, gc.Equals, "aborted")
+	c.Assert(s.target.status.Phase, gc.Equals, "aborted")
+	c.Assert(s.calls[len(s.calls)-3:], jc.DeepEquals, []string{
+		"old Abort timed out waiting for agents to leave the source system: machine-0",
+		"new Status",
+		"new RemoveEnvironment",
+	})
+}
+
+func (s *migrateSuite) TestAbort(c *gc.C) {
+	s.source.status.Phase = "repoint"
+	s.target.status.Phase = "repoint"
+	_, err := s.run(c, "--abort", "bob/web", "new")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.calls, jc.DeepEquals, []string{
+		"old AllEnvironments",
+		"old Status",
+		"new Status",
+		"new Abort aborted by user",
+		"old Abort aborted by user",
+		"new Status",
+		"new RemoveEnvironment",
+	})
+}
+
+func (s *migrateSuite) TestAbortCompleted(c *gc.C) {
+	s.source.status.Phase = "done"
+	_, err := s.run(c, "--abort", "bob/web", "new")
+	c.Assert(err, gc.ErrorMatches, "cannot abort migration: migration is complete")
+}
+
+func (s *migrateSuite) TestAbortNotStarted(c *gc.C) {
+	s.source.errors = map[string]error{"Status": &params.Error{Code: params.CodeNotFound}}
+	s.target.errors = map[string]error{"Status": &params.Error{Code: params.CodeNotFound}}
+	_, err := s.run(c, "--abort", "bob/web", "new")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.calls, jc.DeepEquals, []string{
+		"old AllEnvironments",
+		"old Status",
+		"new Status",
+	})
+}
+
+// fakeMigrateAPI records the calls made on one system, and keeps the
+// status of the migration as that system would.
+type fakeMigrateAPI struct {
+	name   string
+	calls  *[]string
+	errors map[string]error
+
+	envs      []base.UserEnvironment
+	status    params.MigrationStatus
+	exported  []byte
+	imported  string
+	archives  map[string]string
+	connected [][]string
+}
+
+func (f *fakeMigrateAPI) call(args ...string) error {
+	call := f.name
+	for _, arg := range args {
+		call += " " + arg
+	}
+	*f.calls = append(*f.calls, call)
+	return f.errors[args[0]]
+}
+
+func (f *fakeMigrateAPI) Close() error {
+	return nil
+}
+
+func (f *fakeMigrateAPI) AllEnvironments() ([]base.UserEnvironment, error) {
+	return f.envs, f.call("AllEnvironments")
+}
+
+func (f *fakeMigrateAPI) Start(env names.EnvironTag, target params.MigrationControllerInfo) (params.MigrationStatus, error) {
+	if err := f.call("Start", target.ControllerTag); err != nil {
+		return params.MigrationStatus{}, err
+	}
+	f.status.Phase = "quiesce"
+	return f.status, nil
+}
+
+func (f *fakeMigrateAPI) Export(env names.EnvironTag) ([]byte, error) {
+	return f.exported, f.call("Export")
+}
+
+func (f *fakeMigrateAPI) Import(data []byte, source params.MigrationControllerInfo) (params.MigrationStatus, error) {
+	if err := f.call("Import", source.ControllerTag); err != nil {
+		return params.MigrationStatus{}, err
+	}
+	f.imported = string(data)
+	f.status = params.MigrationStatus{Role: "target", Phase: "import"}
+	return f.status, nil
+}
+
+func (f *fakeMigrateAPI) CharmArchive(env names.EnvironTag, curl *charm.URL) (io.ReadCloser, error) {
+	if err := f.call("CharmArchive", curl.String()); err != nil {
+		return nil, err
+	}
+	return ioutil.NopCloser(bytes.NewBufferString(f.archives[curl.String()])), nil
+}
+
+func (f *fakeMigrateAPI) AddCharmArchive(env names.EnvironTag, curl *charm.URL, archive io.ReadSeeker) error {
+	if err := f.call("AddCharmArchive", curl.String()); err != nil {
+		return err
+	}
+	data, err := ioutil.ReadAll(archive)
+	if err != nil {
+		return err
+	}
+	f.archives[curl.String()] = string(data)
+	return nil
+}
+
+func (f *fakeMigrateAPI) SetPhase(env names.EnvironTag, phase string) (params.MigrationStatus, error) {
+	if err := f.call("SetPhase", phase); err != nil {
+		return params.MigrationStatus{}, err
+	}
+	f.status.Phase = phase
+	return f.status, nil
+}
+
+func (f *fakeMigrateAPI) Abort(env names.EnvironTag, reason string) (params.MigrationStatus, error) {
+	if err := f.call("Abort", reason); err != nil {
+		return params.MigrationStatus{}, err
+	}
+	f.status.Phase = "aborted"
+	return f.status, nil
+}
+
+func (f *fakeMigrateAPI) Status(env names.EnvironTag) (params.MigrationStatus, error) {
+	if err := f.call("Status"); err != nil {
+		return params.MigrationStatus{}, err
+	}
+	status := f.status
+	if len(f.connected) > 0 {
+		status.ConnectedAgents = f.connected[0]
+		if len(f.connected) > 1 {
+			f.connected = f.connected[1:]
+		}
+	}
+	return status, nil
+}
+
+func (f *fakeMigrateAPI) RemoveEnvironment(env names.EnvironTag) error {
+	return f.call("RemoveEnvironment")
+}
@@ -45,6 +45,7 @@ func NewSuperCommand() cmd.Command {
 	systemCmd.Register(newEnvironmentsCommand())
 	systemCmd.Register(newCreateEnvironmentCommand())
 	systemCmd.Register(newRemoveBlocksCommand())
+	systemCmd.Register(newMigrateCommand())
 	systemCmd.Register(newUseEnvironmentCommand())
 
 	return systemCmd
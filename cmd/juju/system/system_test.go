@@ -29,6 +29,7 @@ var expectedCommmandNames = []string{
 	"list",
 	"list-blocks",
 	"login",
+	"migrate",
 	"remove-blocks",
 	"use-env", // alias for use-environment
 	"use-environment",
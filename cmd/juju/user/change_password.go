@@ -24,6 +24,9 @@ const userChangePasswordDoc = `
 Change the password for the user you are currently logged in as,
 or as an admin, change the password for another user.
 
+New passwords must follow the password policy of the controller. Users
+whose password has expired can do nothing else until they change it.
+
 Examples:
   # You will be prompted to enter a password.
   juju user change-password
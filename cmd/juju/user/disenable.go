@@ -28,6 +28,9 @@ Enabling a user that is disabled allows that user to log in again. The user
 still exists and can be reenabled using the "juju enable" command.  If the
 user is already enabled, this command succeeds silently.
 
+Enabling a user also allows a user that has been locked out after too many
+failed logins to log in again, without waiting for the lockout to expire.
+
 Examples:
   juju user enable foobar
 
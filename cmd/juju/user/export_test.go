@@ -90,3 +90,27 @@ func NewListCommand(api UserInfoAPI) cmd.Command {
 	}
 	return envcmd.WrapSystem(c)
 }
+
+// NewAddTokenCommand returns an add-token command with the api provided
+// as specified.
+func NewAddTokenCommand(api TokenAPI) cmd.Command {
+	c := &addTokenCommand{}
+	c.api = api
+	return envcmd.WrapSystem(c)
+}
+
+// NewListTokensCommand returns a list-tokens command with the api
+// provided as specified.
+func NewListTokensCommand(api TokenAPI) cmd.Command {
+	c := &listTokensCommand{}
+	c.api = api
+	return envcmd.WrapSystem(c)
+}
+
+// NewRemoveTokenCommand returns a remove-token command with the api
+// provided as specified.
+func NewRemoveTokenCommand(api TokenAPI) cmd.Command {
+	c := &removeTokenCommand{}
+	c.api = api
+	return envcmd.WrapSystem(c)
+}
@@ -84,6 +84,7 @@ type UserInfo struct {
 	DateCreated    string `yaml:"date-created" json:"date-created"`
 	LastConnection string `yaml:"last-connection" json:"last-connection"`
 	Disabled       bool   `yaml:"disabled,omitempty" json:"disabled,omitempty"`
+	LockedOut      bool   `yaml:"locked-out,omitempty" json:"locked-out,omitempty"`
 }
 
 // Info implements Command.Info.
@@ -152,6 +153,7 @@ func (c *infoCommandBase) apiUsersToUserInfoSlice(users []params.UserInfo) []Use
 			Username:       info.Username,
 			DisplayName:    info.DisplayName,
 			Disabled:       info.Disabled,
+			LockedOut:      info.LockedOut,
 			LastConnection: LastConnection(info.LastConnection, now, c.exactTime),
 		}
 		if c.exactTime {
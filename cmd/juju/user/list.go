@@ -92,6 +92,9 @@ func (c *listCommand) formatTabular(value interface{}) ([]byte, error) {
 		if user.Disabled {
 			conn += " (disabled)"
 		}
+		if user.LockedOut {
+			conn += " (locked out)"
+		}
 		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", user.Username, user.DisplayName, user.DateCreated, conn)
 	}
 	tw.Flush()
@@ -64,6 +64,7 @@ func (f *fakeUserListAPI) UserInfo(usernames []string, all usermanager.IncludeDi
 		},
 	}
 	if all {
+		result[1].LockedOut = true
 		result = append(result, params.UserInfo{
 			Username:       "davey",
 			DisplayName:    "Davey Willow",
@@ -92,7 +93,7 @@ func (s *UserListCommandSuite) TestUserInfoWithDisabled(c *gc.C) {
 	c.Assert(testing.Stdout(context), gc.Equals, ""+
 		"NAME     DISPLAY NAME    DATE CREATED  LAST CONNECTION\n"+
 		"adam     Adam Zulu       2012-10-08    2014-01-01\n"+
-		"barbara  Barbara Yellow  2013-05-02    just now\n"+
+		"barbara  Barbara Yellow  2013-05-02    just now (locked out)\n"+
 		"charlie  Charlie Xavier  6 hours ago   never connected\n"+
 		"davey    Davey Willow    2014-10-09    35 minutes ago (disabled)\n"+
 		"\n")
@@ -0,0 +1,383 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package user
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/cmd/juju/block"
+)
+
+const addTokenCommandDoc = `
+Add a named API token that can be used to log in instead of a password.
+
+API tokens are intended for automation, such as CI systems, that should
+not need to know the password of the user they act as. A user may have
+any number of tokens, each of which can be revoked separately with
+"juju user remove-token". To log in with a token, use the credentials
+printed by this command as the password. The credentials are only shown
+once; they cannot be retrieved again later.
+
+A token may be restricted to some environments, given by UUID, and to
+some API facades. It never grants more access than its owner has, and
+cannot be used to add further tokens or to change a password.
+Tokens may be given an expiry time, either as a duration from now or as
+an RFC3339 timestamp.
+
+Only administrators may add tokens for other users.
+
+Examples:
+    # Add a token for the current user that never expires.
+    juju user add-token jenkins
+
+    # Add a token for the ci-bot user that expires in 90 days and may
+    # only call the Client facade.
+    juju user add-token --owner ci-bot --expires 2160h --facades Client jenkins
+
+See Also:
+    juju help user list-tokens
+    juju help user remove-token
+`
+
+const listTokensCommandDoc = `
+List the API tokens of a user, and when they were last used.
+
+Only administrators may list the tokens of other users.
+
+See Also:
+    juju help user add-token
+`
+
+const removeTokenCommandDoc = `
+Revoke an API token so that it can no longer be used to log in.
+Connections already made with the token stop being able to make calls
+within a few seconds.
+
+Only administrators may remove the tokens of other users.
+
+Examples:
+    juju user remove-token jenkins
+    juju user remove-token --owner ci-bot jenkins
+
+See Also:
+    juju help user add-token
+`
+
+// TokenAPI defines the usermanager API methods that the token commands
+// use.
+type TokenAPI interface {
+	AddAPIToken(owner string, token params.AddAPIToken) (string, error)
+	ListAPITokens(owner string) ([]params.APITokenInfo, error)
+	RemoveAPIToken(owner, name string) error
+	Close() error
+}
+
+// tokenCommandBase holds what the token commands have in common.
+type tokenCommandBase struct {
+	UserCommandBase
+	api   TokenAPI
+	Owner string
+}
+
+func (c *tokenCommandBase) SetFlags(f *gnuflag.FlagSet) {
+	f.StringVar(&c.Owner, "owner", "", "the user that owns the token, if not the current user")
+}
+
+func (c *tokenCommandBase) checkOwner() error {
+	if c.Owner != "" && !names.IsValidUserName(c.Owner) {
+		return errors.Errorf("%q is not a valid username", c.Owner)
+	}
+	return nil
+}
+
+func (c *tokenCommandBase) getTokenAPI() (TokenAPI, error) {
+	if c.api != nil {
+		return c.api, nil
+	}
+	return c.NewUserManagerAPIClient()
+}
+
+func newAddTokenCommand() cmd.Command {
+	return envcmd.WrapSystem(&addTokenCommand{})
+}
+
+// addTokenCommand adds an API token.
+type addTokenCommand struct {
+	tokenCommandBase
+	Name         string
+	Expires      string
+	Environments string
+	Facades      string
+}
+
+// Info implements Command.Info.
+func (c *addTokenCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "add-token",
+		Args:    "<token name>",
+		Purpose: "adds an API token to log in with",
+		Doc:     addTokenCommandDoc,
+	}
+}
+
+// SetFlags implements Command.SetFlags.
+func (c *addTokenCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.tokenCommandBase.SetFlags(f)
+	f.StringVar(&c.Expires, "expires", "", "when the token expires, as a duration from now or an RFC3339 time")
+	f.StringVar(&c.Environments, "environments", "", "comma-separated UUIDs of the environments the token may be used with")
+	f.StringVar(&c.Facades, "facades", "", "comma-separated names of the API facades the token may be used to call")
+}
+
+// Init implements Command.Init.
+func (c *addTokenCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return errors.New("no token name supplied")
+	}
+	c.Name, args = args[0], args[1:]
+	if err := c.checkOwner(); err != nil {
+		return errors.Trace(err)
+	}
+	if c.Expires != "" {
+		if _, err := parseExpiry(c.Expires, time.Now()); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	for _, uuid := range splitList(c.Environments) {
+		if !names.IsValidEnvironment(uuid) {
+			return errors.Errorf("%q is not a valid environment UUID", uuid)
+		}
+	}
+	return cmd.CheckEmpty(args)
+}
+
+// parseExpiry returns the expiry time described by s, which holds
+// either a duration from now or an RFC3339 time.
+func parseExpiry(s string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		if d <= 0 {
+			return time.Time{}, errors.Errorf("expiry duration %v not positive", d)
+		}
+		return now.Add(d), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, errors.Errorf("invalid expiry %q: expected duration or RFC3339 time", s)
+	}
+	return t, nil
+}
+
+// splitList splits a comma-separated list, ignoring empty items.
+func splitList(s string) []string {
+	var result []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// Run implements Command.Run.
+func (c *addTokenCommand) Run(ctx *cmd.Context) error {
+	api, err := c.getTokenAPI()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer api.Close()
+
+	token := params.AddAPIToken{
+		Name:         c.Name,
+		Environments: splitList(c.Environments),
+		Facades:      splitList(c.Facades),
+	}
+	if c.Expires != "" {
+		expires, err := parseExpiry(c.Expires, time.Now())
+		if err != nil {
+			return errors.Trace(err)
+		}
+		token.Expires = &expires
+	}
+	credentials, err := api.AddAPIToken(c.Owner, token)
+	if err != nil {
+		return block.ProcessBlockedError(err, block.BlockChange)
+	}
+	ctx.Infof("API token %q added; use these credentials as the password to log in with it.", c.Name)
+	ctx.Infof("They will not be shown again.")
+	fmt.Fprintln(ctx.Stdout, credentials)
+	return nil
+}
+
+func newListTokensCommand() cmd.Command {
+	return envcmd.WrapSystem(&listTokensCommand{})
+}
+
+// listTokensCommand lists the API tokens of a user.
+type listTokensCommand struct {
+	tokenCommandBase
+	out cmd.Output
+}
+
+// TokenInfo holds the fields of an API token that are shown by
+// "juju user list-tokens".
+type TokenInfo struct {
+	Name         string   `yaml:"name" json:"name"`
+	Owner        string   `yaml:"owner" json:"owner"`
+	Created      string   `yaml:"created" json:"created"`
+	Expires      string   `yaml:"expires,omitempty" json:"expires,omitempty"`
+	LastUsed     string   `yaml:"last-used,omitempty" json:"last-used,omitempty"`
+	Environments []string `yaml:"environments,omitempty" json:"environments,omitempty"`
+	Facades      []string `yaml:"facades,omitempty" json:"facades,omitempty"`
+}
+
+// Info implements Command.Info.
+func (c *listTokensCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "list-tokens",
+		Purpose: "shows the API tokens of a user",
+		Doc:     listTokensCommandDoc,
+	}
+}
+
+// SetFlags implements Command.SetFlags.
+func (c *listTokensCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.tokenCommandBase.SetFlags(f)
+	c.out.AddFlags(f, "tabular", map[string]cmd.Formatter{
+		"yaml":    cmd.FormatYaml,
+		"json":    cmd.FormatJson,
+		"tabular": c.formatTabular,
+	})
+}
+
+// Init implements Command.Init.
+func (c *listTokensCommand) Init(args []string) error {
+	if err := c.checkOwner(); err != nil {
+		return errors.Trace(err)
+	}
+	return cmd.CheckEmpty(args)
+}
+
+// Run implements Command.Run.
+func (c *listTokensCommand) Run(ctx *cmd.Context) error {
+	api, err := c.getTokenAPI()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer api.Close()
+
+	tokens, err := api.ListAPITokens(c.Owner)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	infos := make([]TokenInfo, len(tokens))
+	for i, token := range tokens {
+		owner := token.Owner
+		if tag, err := names.ParseUserTag(owner); err == nil {
+			owner = tag.Name()
+		}
+		info := TokenInfo{
+			Name:         token.Name,
+			Owner:        owner,
+			Created:      token.Created.Format(time.RFC3339),
+			Environments: token.Environments,
+			Facades:      token.Facades,
+		}
+		if token.Expires != nil {
+			info.Expires = token.Expires.Format(time.RFC3339)
+		}
+		if token.LastUsed != nil {
+			info.LastUsed = token.LastUsed.Format(time.RFC3339)
+		}
+		infos[i] = info
+	}
+	return c.out.Write(ctx, infos)
+}
+
+func (c *listTokensCommand) formatTabular(value interface{}) ([]byte, error) {
+	tokens, ok := value.([]TokenInfo)
+	if !ok {
+		return nil, errors.Errorf("expected value of type %T, got %T", tokens, value)
+	}
+	var out bytes.Buffer
+	const (
+		// To format things into columns.
+		minwidth = 0
+		tabwidth = 1
+		padding  = 2
+		padchar  = ' '
+		flags    = 0
+	)
+	tw := tabwriter.NewWriter(&out, minwidth, tabwidth, padding, padchar, flags)
+	fmt.Fprintf(tw, "NAME\tOWNER\tCREATED\tEXPIRES\tLAST USED\n")
+	for _, token := range tokens {
+		expires := token.Expires
+		if expires == "" {
+			expires = "never"
+		}
+		lastUsed := token.LastUsed
+		if lastUsed == "" {
+			lastUsed = "never"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", token.Name, token.Owner, token.Created, expires, lastUsed)
+	}
+	tw.Flush()
+	return out.Bytes(), nil
+}
+
+func newRemoveTokenCommand() cmd.Command {
+	return envcmd.WrapSystem(&removeTokenCommand{})
+}
+
+// removeTokenCommand revokes an API token.
+type removeTokenCommand struct {
+	tokenCommandBase
+	Name string
+}
+
+// Info implements Command.Info.
+func (c *removeTokenCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "remove-token",
+		Args:    "<token name>",
+		Purpose: "revokes an API token",
+		Doc:     removeTokenCommandDoc,
+	}
+}
+
+// Init implements Command.Init.
+func (c *removeTokenCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return errors.New("no token name supplied")
+	}
+	c.Name, args = args[0], args[1:]
+	if err := c.checkOwner(); err != nil {
+		return errors.Trace(err)
+	}
+	return cmd.CheckEmpty(args)
+}
+
+// Run implements Command.Run.
+func (c *removeTokenCommand) Run(ctx *cmd.Context) error {
+	api, err := c.getTokenAPI()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer api.Close()
+
+	if err := api.RemoveAPIToken(c.Owner, c.Name); err != nil {
+		return block.ProcessBlockedError(err, block.BlockRemove)
+	}
+	ctx.Infof("API token %q removed", c.Name)
+	return nil
+}
@@ -0,0 +1,183 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package user_test
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/juju/user"
+	"github.com/juju/juju/testing"
+)
+
+type TokenCommandsSuite struct {
+	BaseSuite
+	mock *mockTokenAPI
+}
+
+var _ = gc.Suite(&TokenCommandsSuite{})
+
+func (s *TokenCommandsSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.mock = &mockTokenAPI{}
+}
+
+func (s *TokenCommandsSuite) TestAddTokenInit(c *gc.C) {
+	for i, test := range []struct {
+		args     []string
+		errMatch string
+	}{{
+		errMatch: "no token name supplied",
+	}, {
+		args:     []string{"jenkins", "extra"},
+		errMatch: `unrecognized args: \["extra"\]`,
+	}, {
+		args:     []string{"--owner", "not@home", "jenkins"},
+		errMatch: `"not@home" is not a valid username`,
+	}, {
+		args:     []string{"--expires", "tomorrow", "jenkins"},
+		errMatch: `invalid expiry "tomorrow": expected duration or RFC3339 time`,
+	}, {
+		args:     []string{"--expires", "0s", "jenkins"},
+		errMatch: `expiry duration 0s not positive`,
+	}, {
+		args:     []string{"--environments", "foo", "jenkins"},
+		errMatch: `"foo" is not a valid environment UUID`,
+	}, {
+		args: []string{"--expires", "2030-01-02T15:04:05Z", "jenkins"},
+	}} {
+		c.Logf("test %d: %v", i, test.args)
+		err := testing.InitCommand(user.NewAddTokenCommand(s.mock), test.args)
+		if test.errMatch == "" {
+			c.Check(err, jc.ErrorIsNil)
+		} else {
+			c.Check(err, gc.ErrorMatches, test.errMatch)
+		}
+	}
+}
+
+func (s *TokenCommandsSuite) TestAddToken(c *gc.C) {
+	uuid := "deadbeef-0bad-400d-8000-4b1d0d06f00d"
+	before := time.Now()
+	ctx, err := testing.RunCommand(c, user.NewAddTokenCommand(s.mock),
+		"--owner", "ci-bot",
+		"--expires", "24h",
+		"--environments", uuid,
+		"--facades", "Client, Action",
+		"jenkins",
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(testing.Stdout(ctx), gc.Equals, "token:jenkins:sekrit\n")
+	c.Assert(testing.Stderr(ctx), jc.Contains, "They will not be shown again.")
+
+	c.Assert(s.mock.owner, gc.Equals, "ci-bot")
+	token := s.mock.added
+	c.Assert(token.Name, gc.Equals, "jenkins")
+	c.Assert(token.Environments, jc.DeepEquals, []string{uuid})
+	c.Assert(token.Facades, jc.DeepEquals, []string{"Client", "Action"})
+	c.Assert(token.Expires, gc.NotNil)
+	c.Assert(token.Expires.Before(before.Add(24*time.Hour)), jc.IsFalse)
+	c.Assert(token.Expires.After(time.Now().Add(24*time.Hour)), jc.IsFalse)
+}
+
+func (s *TokenCommandsSuite) TestAddTokenNoExpiry(c *gc.C) {
+	_, err := testing.RunCommand(c, user.NewAddTokenCommand(s.mock), "jenkins")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.mock.owner, gc.Equals, "")
+	c.Assert(s.mock.added.Expires, gc.IsNil)
+}
+
+func (s *TokenCommandsSuite) TestAddTokenError(c *gc.C) {
+	s.mock.err = errors.New("boom")
+	_, err := testing.RunCommand(c, user.NewAddTokenCommand(s.mock), "jenkins")
+	c.Assert(err, gc.ErrorMatches, "boom")
+}
+
+func (s *TokenCommandsSuite) TestListTokens(c *gc.C) {
+	created := time.Date(2015, 10, 1, 12, 0, 0, 0, time.UTC)
+	expires := created.Add(90 * 24 * time.Hour)
+	lastUsed := created.Add(time.Hour)
+	s.mock.tokens = []params.APITokenInfo{{
+		Owner:    "user-ci-bot@local",
+		Name:     "jenkins",
+		Created:  created,
+		Expires:  &expires,
+		LastUsed: &lastUsed,
+		Facades:  []string{"Client"},
+	}, {
+		Owner:   "user-ci-bot@local",
+		Name:    "travis",
+		Created: created,
+	}}
+	ctx, err := testing.RunCommand(c, user.NewListTokensCommand(s.mock), "--owner", "ci-bot")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.mock.owner, gc.Equals, "ci-bot")
+	c.Assert(testing.Stdout(ctx), gc.Equals, ""+
+		"NAME     OWNER   CREATED               EXPIRES               LAST USED\n"+
+		"jenkins  ci-bot  2015-10-01T12:00:00Z  2015-12-30T12:00:00Z  2015-10-01T13:00:00Z\n"+
+		"travis   ci-bot  2015-10-01T12:00:00Z  never                 never\n")
+}
+
+func (s *TokenCommandsSuite) TestListTokensJSON(c *gc.C) {
+	created := time.Date(2015, 10, 1, 12, 0, 0, 0, time.UTC)
+	s.mock.tokens = []params.APITokenInfo{{
+		Owner:   "user-ci-bot@local",
+		Name:    "jenkins",
+		Created: created,
+		Facades: []string{"Client"},
+	}}
+	ctx, err := testing.RunCommand(c, user.NewListTokensCommand(s.mock), "--format", "json")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.mock.owner, gc.Equals, "")
+	c.Assert(testing.Stdout(ctx), gc.Equals, `
+[{"name":"jenkins","owner":"ci-bot","created":"2015-10-01T12:00:00Z","facades":["Client"]}]
+`[1:])
+}
+
+func (s *TokenCommandsSuite) TestRemoveToken(c *gc.C) {
+	ctx, err := testing.RunCommand(c, user.NewRemoveTokenCommand(s.mock), "--owner", "ci-bot", "jenkins")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.mock.owner, gc.Equals, "ci-bot")
+	c.Assert(s.mock.removed, gc.Equals, "jenkins")
+	c.Assert(testing.Stderr(ctx), gc.Equals, "API token \"jenkins\" removed\n")
+}
+
+func (s *TokenCommandsSuite) TestRemoveTokenInit(c *gc.C) {
+	err := testing.InitCommand(user.NewRemoveTokenCommand(s.mock), nil)
+	c.Assert(err, gc.ErrorMatches, "no token name supplied")
+}
+
+type mockTokenAPI struct {
+	owner   string
+	added   params.AddAPIToken
+	removed string
+	tokens  []params.APITokenInfo
+	err     error
+}
+
+func (m *mockTokenAPI) AddAPIToken(owner string, token params.AddAPIToken) (string, error) {
+	m.owner, m.added = owner, token
+	if m.err != nil {
+		return "", m.err
+	}
+	return "token:" + token.Name + ":sekrit", nil
+}
+
+func (m *mockTokenAPI) ListAPITokens(owner string) ([]params.APITokenInfo, error) {
+	m.owner = owner
+	return m.tokens, m.err
+}
+
+func (m *mockTokenAPI) RemoveAPIToken(owner, name string) error {
+	m.owner, m.removed = owner, name
+	return m.err
+}
+
+func (m *mockTokenAPI) Close() error {
+	return nil
+}
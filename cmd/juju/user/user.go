@@ -38,6 +38,9 @@ func NewSuperCommand() cmd.Command {
 	usercmd.Register(newDisableCommand())
 	usercmd.Register(newEnableCommand())
 	usercmd.Register(newListCommand())
+	usercmd.Register(newAddTokenCommand())
+	usercmd.Register(newListTokensCommand())
+	usercmd.Register(newRemoveTokenCommand())
 	return usercmd
 }
 
@@ -26,6 +26,7 @@ var _ = gc.Suite(&UserCommandSuite{})
 
 var expectedUserCommmandNames = []string{
 	"add",
+	"add-token",
 	"change-password",
 	"credentials",
 	"disable",
@@ -33,6 +34,8 @@ var expectedUserCommmandNames = []string{
 	"help",
 	"info",
 	"list",
+	"list-tokens",
+	"remove-token",
 }
 
 func (s *UserCommandSuite) TestHelp(c *gc.C) {
@@ -73,10 +73,13 @@ import (
 	"github.com/juju/juju/worker/apiaddressupdater"
 	"github.com/juju/juju/worker/apicaller"
 	"github.com/juju/juju/worker/authenticationworker"
+	"github.com/juju/juju/worker/caupdater"
 	"github.com/juju/juju/worker/certupdater"
 	"github.com/juju/juju/worker/charmrevisionworker"
+	"github.com/juju/juju/worker/charmrollout"
 	"github.com/juju/juju/worker/cleaner"
 	"github.com/juju/juju/worker/conv2state"
+	"github.com/juju/juju/worker/crossenvrelations"
 	"github.com/juju/juju/worker/dblogpruner"
 	"github.com/juju/juju/worker/deployer"
 	"github.com/juju/juju/worker/diskmanager"
@@ -790,6 +793,9 @@ func (a *MachineAgent) postUpgradeAPIWorker(
 		addressUpdater := agent.APIHostPortsSetter{a}
 		return apiaddressupdater.NewAPIAddressUpdater(st.Machiner(), addressUpdater), nil
 	})
+	runner.StartWorker("caupdater", func() (worker.Worker, error) {
+		return caupdater.NewCACertUpdater(st.Machiner(), agent.CACertSetter{a}), nil
+	})
 
 	runner.StartWorker("logger", func() (worker.Worker, error) {
 		return workerlogger.NewLogger(st.Logger(), agentConfig), nil
@@ -1165,6 +1171,13 @@ func (a *MachineAgent) StateWorker() (worker.Worker, error) {
 			a.startWorkerAfterUpgrade(singularRunner, "txnpruner", func() (worker.Worker, error) {
 				return txnpruner.New(st, time.Hour*2), nil
 			})
+			a.startWorkerAfterUpgrade(singularRunner, "crossenvrelations", func() (worker.Worker, error) {
+				return crossenvrelations.New(crossenvrelations.Config{
+					Facade:        st,
+					CheckInterval: crossenvrelations.DefaultCheckInterval,
+					NewTimer:      worker.NewTimer,
+				})
+			})
 
 		case state.JobManageStateDeprecated:
 			// Legacy environments may set this, but we ignore it.
@@ -1249,6 +1262,13 @@ func (a *MachineAgent) startEnvWorkers(
 	singularRunner.StartWorker("minunitsworker", func() (worker.Worker, error) {
 		return minunitsworker.NewMinUnitsWorker(st), nil
 	})
+	singularRunner.StartWorker("charmrollout", func() (worker.Worker, error) {
+		return charmrollout.New(charmrollout.Config{
+			Facade:        st,
+			CheckInterval: charmrollout.DefaultCheckInterval,
+			NewTimer:      worker.NewTimer,
+		})
+	})
 
 	// Start workers that use an API connection.
 	singularRunner.StartWorker("environ-provisioner", func() (worker.Worker, error) {
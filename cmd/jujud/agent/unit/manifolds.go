@@ -11,8 +11,10 @@ import (
 	"github.com/juju/juju/worker/agent"
 	"github.com/juju/juju/worker/apiaddressupdater"
 	"github.com/juju/juju/worker/apicaller"
+	"github.com/juju/juju/worker/caupdater"
 	"github.com/juju/juju/worker/dependency"
 	"github.com/juju/juju/worker/fortress"
+	"github.com/juju/juju/worker/healthcheck"
 	"github.com/juju/juju/worker/leadership"
 	"github.com/juju/juju/worker/logger"
 	"github.com/juju/juju/worker/logsender"
@@ -104,6 +106,14 @@ func Manifolds(config ManifoldsConfig) dependency.Manifolds {
 			APICallerName: APICallerName,
 		}),
 
+		// The CA cert updater is a leaf worker that rewrites agent config
+		// as the state servers' CA certificates are rotated. We should only
+		// need one of these in a consolidated agent.
+		CACertUpdaterName: caupdater.Manifold(caupdater.ManifoldConfig{
+			AgentName:     AgentName,
+			APICallerName: APICallerName,
+		}),
+
 		// The proxy config updater is a leaf worker that sets http/https/apt/etc
 		// proxy settings.
 		// TODO(fwereade): timing of this is suspicious. There was superstitious
@@ -167,6 +177,14 @@ func Manifolds(config ManifoldsConfig) dependency.Manifolds {
 			CharmDirName:    CharmDirName,
 		}),
 
+		// The health check worker runs the health checks declared by the
+		// charm, and reports their results as the unit's health.
+		HealthCheckName: healthcheck.Manifold(healthcheck.ManifoldConfig{
+			AgentName:     AgentName,
+			APICallerName: APICallerName,
+			CharmDirName:  CharmDirName,
+		}),
+
 		// The meter status worker executes the meter-status-changed hook when it detects
 		// that the meter status has changed.
 		MeterStatusName: meterstatus.Manifold(meterstatus.ManifoldConfig{
@@ -191,6 +209,7 @@ const (
 	AgentName                = "agent"
 	APIAdddressUpdaterName   = "api-address-updater"
 	APICallerName            = "api-caller"
+	CACertUpdaterName        = "ca-cert-updater"
 	LeadershipTrackerName    = "leadership-tracker"
 	LoggingConfigUpdaterName = "logging-config-updater"
 	LogSenderName            = "log-sender"
@@ -204,4 +223,5 @@ const (
 	MeterStatusName          = "meter-status"
 	MetricCollectName        = "metric-collect"
 	MetricSenderName         = "metric-sender"
+	HealthCheckName          = "health-check"
 )
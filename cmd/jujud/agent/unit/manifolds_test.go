@@ -41,6 +41,7 @@ func (s *ManifoldsSuite) TestManifoldNames(c *gc.C) {
 		unit.AgentName,
 		unit.APIAdddressUpdaterName,
 		unit.APICallerName,
+		unit.CACertUpdaterName,
 		unit.LeadershipTrackerName,
 		unit.LoggingConfigUpdaterName,
 		unit.LogSenderName,
@@ -54,6 +55,7 @@ func (s *ManifoldsSuite) TestManifoldNames(c *gc.C) {
 		unit.MeterStatusName,
 		unit.MetricSenderName,
 		unit.CharmDirName,
+		unit.HealthCheckName,
 	}
 	keys := make([]string, 0, len(manifolds))
 	for k := range manifolds {
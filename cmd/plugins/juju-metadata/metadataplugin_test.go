@@ -33,6 +33,7 @@ var metadataCommandNames = []string{
 	"generate-tools",
 	"help",
 	"list-images",
+	"mirror",
 	"sign",
 	"validate-images",
 	"validate-tools",
@@ -0,0 +1,209 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"github.com/juju/utils/arch"
+	"github.com/juju/utils/series"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/environs/filestorage"
+	"github.com/juju/juju/environs/storage"
+	"github.com/juju/juju/environs/sync"
+	envtools "github.com/juju/juju/environs/tools"
+	"github.com/juju/juju/juju/osenv"
+	"github.com/juju/juju/version"
+)
+
+func newMirrorCommand() cmd.Command {
+	return &mirrorCommand{}
+}
+
+var mirrorDoc = `
+mirror maintains a local mirror of Juju tools and their simplestreams
+metadata.
+
+The tools are read from a simplestreams source, which may be a URL or a local
+directory, and default to the official tools store. The selected tools that
+are missing from the mirror, or that differ from the source, are copied into
+the "tools" directory of the mirror directory specified with -d (defaults to
+~/.juju), and index and products metadata are written for the result.
+
+By default the newest tools of the current major version are mirrored. Use
+--version to select a major[.minor] version, and --all to mirror all versions
+rather than only the newest. The tools may further be restricted with
+--series and --arch.
+
+If --keyring is specified, only source metadata signed with a key from that
+armored public keyring is accepted. The metadata written to the mirror may be
+signed with --signing-key. With --prune, tools of the stream that are in the
+mirror but no longer selected are removed.
+
+The mirror can be run repeatedly, for instance from cron; tools that are
+already up to date are not copied again.
+
+Examples:
+
+  - mirror the newest released tools, checking the official signatures:
+
+   juju metadata mirror -d /srv/mirror --keyring juju-release.asc
+
+  - mirror all 1.25 tools for trusty amd64, removing any others:
+
+   juju metadata mirror -d /srv/mirror --version 1.25 --all \
+       --series trusty --arch amd64 --prune
+
+See Also:
+   juju help metadata sign
+   juju help sync-tools
+`
+
+// mirrorCommand is used to maintain a local mirror of tools.
+type mirrorCommand struct {
+	cmd.CommandBase
+	dir            string
+	source         string
+	keyringFile    string
+	stream         string
+	versionStr     string
+	majorVersion   int
+	minorVersion   int
+	allVersions    bool
+	series         string
+	arches         string
+	prune          bool
+	dryRun         bool
+	public         bool
+	signingKeyFile string
+	passphrase     string
+}
+
+var mirrorTools = sync.MirrorTools
+
+func (c *mirrorCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "mirror",
+		Purpose: "maintain a local mirror of tools and their metadata",
+		Doc:     mirrorDoc,
+	}
+}
+
+func (c *mirrorCommand) SetFlags(f *gnuflag.FlagSet) {
+	f.StringVar(&c.dir, "d", "", "local directory holding the mirror")
+	f.StringVar(&c.source, "source", "", "URL or local directory of the tools to mirror")
+	f.StringVar(&c.keyringFile, "keyring", "", "file holding the armored public keys to verify source metadata with")
+	f.StringVar(&c.stream, "stream", envtools.ReleasedStream, "simplestreams stream to mirror")
+	f.StringVar(&c.versionStr, "version", "", "mirror a specific major[.minor] version")
+	f.BoolVar(&c.allVersions, "all", false, "mirror all matching versions, not just the newest")
+	f.StringVar(&c.series, "series", "", "comma-separated series to mirror tools for")
+	f.StringVar(&c.arches, "arch", "", "comma-separated architectures to mirror tools for")
+	f.BoolVar(&c.prune, "prune", false, "remove tools from the mirror that are no longer selected")
+	f.BoolVar(&c.dryRun, "dry-run", false, "don't change the mirror, just print what would be changed")
+	f.BoolVar(&c.public, "public", false, "mirror is for a public cloud, so generate mirrors information")
+	f.StringVar(&c.signingKeyFile, "signing-key", "", "file holding the armored private key to sign the mirror metadata with")
+	f.StringVar(&c.passphrase, "passphrase", "", "passphrase used to decrypt the signing key")
+}
+
+func (c *mirrorCommand) Init(args []string) error {
+	if c.versionStr != "" {
+		var err error
+		if c.majorVersion, c.minorVersion, err = version.ParseMajorMinor(c.versionStr); err != nil {
+			return err
+		}
+	}
+	for _, name := range splitList(c.series) {
+		if _, err := series.SeriesVersion(name); err != nil {
+			return errors.Errorf("invalid series %q", name)
+		}
+	}
+	for _, name := range splitList(c.arches) {
+		if !arch.IsSupportedArch(name) {
+			return errors.Errorf("invalid architecture %q", name)
+		}
+	}
+	if c.passphrase != "" && c.signingKeyFile == "" {
+		return errors.New("--passphrase requires --signing-key")
+	}
+	return cmd.CheckEmpty(args)
+}
+
+// splitList splits a comma-separated list, ignoring empty items.
+func splitList(s string) []string {
+	var result []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+func (c *mirrorCommand) Run(context *cmd.Context) error {
+	loggo.RegisterWriter("mirror", cmd.NewCommandLogWriter("juju.environs.sync", context.Stdout, context.Stderr), loggo.INFO)
+	defer loggo.RemoveWriter("mirror")
+	if c.dir == "" {
+		c.dir = osenv.JujuHome()
+	} else {
+		c.dir = context.AbsPath(c.dir)
+	}
+	target, err := filestorage.NewFileStorageWriter(c.dir)
+	if err != nil {
+		return err
+	}
+	args := sync.MirrorParams{
+		Source:       c.source,
+		Target:       target,
+		Stream:       c.stream,
+		MajorVersion: c.majorVersion,
+		MinorVersion: c.minorVersion,
+		AllVersions:  c.allVersions,
+		Series:       splitList(c.series),
+		Arches:       splitList(c.arches),
+		Prune:        c.prune,
+		DryRun:       c.dryRun,
+		WriteMirrors: envtools.ShouldWriteMirrors(c.public),
+	}
+	if c.source != "" && !strings.Contains(c.source, "://") {
+		args.Source = context.AbsPath(c.source)
+	}
+	if c.keyringFile != "" {
+		data, err := ioutil.ReadFile(context.AbsPath(c.keyringFile))
+		if err != nil {
+			return errors.Annotate(err, "cannot read keyring")
+		}
+		args.PublicKey = string(data)
+	}
+	var signingKey string
+	if c.signingKeyFile != "" {
+		data, err := ioutil.ReadFile(context.AbsPath(c.signingKeyFile))
+		if err != nil {
+			return errors.Annotate(err, "cannot read signing key")
+		}
+		signingKey = string(data)
+	}
+
+	result, err := mirrorTools(args)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	verb := ""
+	if c.dryRun {
+		verb = "would be "
+	}
+	fmt.Fprintf(context.Stdout, "%d tools %scopied, %d %spruned, %d unchanged.\n",
+		len(result.Copied), verb, len(result.Pruned), verb, len(result.Unchanged))
+	if c.dryRun || signingKey == "" {
+		return nil
+	}
+	metadataDir := filepath.Join(c.dir, storage.BaseToolsPath, "streams")
+	return errors.Annotate(process(metadataDir, signingKey, c.passphrase), "cannot sign mirror metadata")
+}
@@ -0,0 +1,132 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/juju/cmd"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/environs/simplestreams"
+	sstesting "github.com/juju/juju/environs/simplestreams/testing"
+	"github.com/juju/juju/environs/sync"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/version"
+)
+
+type MirrorSuite struct {
+	coretesting.FakeJujuHomeSuite
+	args sync.MirrorParams
+}
+
+var _ = gc.Suite(&MirrorSuite{})
+
+func (s *MirrorSuite) SetUpTest(c *gc.C) {
+	s.FakeJujuHomeSuite.SetUpTest(c)
+	s.args = sync.MirrorParams{}
+	s.PatchValue(&mirrorTools, func(args sync.MirrorParams) (*sync.MirrorResult, error) {
+		s.args = args
+		return &sync.MirrorResult{
+			Copied:    []version.Binary{version.MustParseBinary("1.25.0-trusty-amd64")},
+			Pruned:    []version.Binary{version.MustParseBinary("1.24.0-trusty-amd64")},
+			Unchanged: []version.Binary{version.MustParseBinary("1.25.0-precise-amd64")},
+		}, nil
+	})
+}
+
+func runMirror(c *gc.C, args ...string) (*cmd.Context, error) {
+	return coretesting.RunCommand(c, newMirrorCommand(), args...)
+}
+
+func (s *MirrorSuite) TestInitErrors(c *gc.C) {
+	for i, test := range []struct {
+		args []string
+		err  string
+	}{{
+		args: []string{"foo"},
+		err:  `unrecognized args: \["foo"\]`,
+	}, {
+		args: []string{"--version", "x"},
+		err:  `invalid major version number x: .*`,
+	}, {
+		args: []string{"--series", "trusty,bogus"},
+		err:  `invalid series "bogus"`,
+	}, {
+		args: []string{"--arch", "amd64,z80"},
+		err:  `invalid architecture "z80"`,
+	}, {
+		args: []string{"--passphrase", "secret"},
+		err:  "--passphrase requires --signing-key",
+	}} {
+		c.Logf("test %d: %v", i, test.args)
+		_, err := runMirror(c, test.args...)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
+
+func (s *MirrorSuite) TestRun(c *gc.C) {
+	dir := c.MkDir()
+	keyring := filepath.Join(dir, "keyring.asc")
+	err := ioutil.WriteFile(keyring, []byte(sstesting.SignedMetadataPublicKey), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+
+	ctx, err := runMirror(c,
+		"-d", filepath.Join(dir, "mirror"), "--source", "http://tools.example.com/tools",
+		"--keyring", keyring, "--stream", "proposed", "--version", "1.25", "--all",
+		"--series", "trusty, precise", "--arch", "amd64", "--prune", "--public",
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.args.Target, gc.NotNil)
+	s.args.Target = nil
+	c.Assert(s.args, jc.DeepEquals, sync.MirrorParams{
+		Source:       "http://tools.example.com/tools",
+		PublicKey:    sstesting.SignedMetadataPublicKey,
+		Stream:       "proposed",
+		MajorVersion: 1,
+		MinorVersion: 25,
+		AllVersions:  true,
+		Series:       []string{"trusty", "precise"},
+		Arches:       []string{"amd64"},
+		Prune:        true,
+		WriteMirrors: true,
+	})
+	c.Assert(coretesting.Stdout(ctx), gc.Equals, "1 tools copied, 1 pruned, 1 unchanged.\n")
+}
+
+func (s *MirrorSuite) TestRunDryRun(c *gc.C) {
+	ctx, err := runMirror(c, "-d", c.MkDir(), "--dry-run")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.args.DryRun, jc.IsTrue)
+	c.Assert(s.args.Stream, gc.Equals, "released")
+	c.Assert(coretesting.Stdout(ctx), gc.Equals, "1 tools would be copied, 1 would be pruned, 1 unchanged.\n")
+}
+
+func (s *MirrorSuite) TestRunSignsMetadata(c *gc.C) {
+	dir := c.MkDir()
+	metadataDir := filepath.Join(dir, "tools", "streams", "v1")
+	s.PatchValue(&mirrorTools, func(args sync.MirrorParams) (*sync.MirrorResult, error) {
+		if err := os.MkdirAll(metadataDir, 0755); err != nil {
+			return nil, err
+		}
+		err := ioutil.WriteFile(filepath.Join(metadataDir, "index2.json"), []byte(`{"format": "index:1.0"}`), 0644)
+		return &sync.MirrorResult{}, err
+	})
+	keyFile := filepath.Join(dir, "signing.key")
+	err := ioutil.WriteFile(keyFile, []byte(sstesting.SignedMetadataPrivateKey), 0600)
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = runMirror(c, "-d", dir, "--signing-key", keyFile, "--passphrase", sstesting.PrivateKeyPassphrase)
+	c.Assert(err, jc.ErrorIsNil)
+	f, err := os.Open(filepath.Join(metadataDir, "index2.sjson"))
+	c.Assert(err, jc.ErrorIsNil)
+	defer f.Close()
+	data, err := simplestreams.DecodeCheckSignature(f, sstesting.SignedMetadataPublicKey)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(strings.TrimSpace(string(data)), gc.Equals, `{"format": "index:1.0"}`)
+}
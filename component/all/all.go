@@ -22,6 +22,7 @@ type component interface {
 
 var components = []component{
 	&payloads{},
+	&resources{},
 }
 
 // RegisterForServer registers all the parts of the components with the
@@ -0,0 +1,142 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package all
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/cmd/juju/commands"
+	"github.com/juju/juju/resource"
+	"github.com/juju/juju/resource/api/client"
+	"github.com/juju/juju/resource/api/server"
+	resourcecmd "github.com/juju/juju/resource/cmd"
+	"github.com/juju/juju/resource/context"
+	"github.com/juju/juju/resource/persistence"
+	resourcestate "github.com/juju/juju/resource/state"
+	"github.com/juju/juju/state"
+	unitercontext "github.com/juju/juju/worker/uniter/runner/context"
+	"github.com/juju/juju/worker/uniter/runner/jujuc"
+)
+
+type resources struct{}
+
+func (c resources) registerForServer() error {
+	c.registerState()
+	c.registerPublicFacade()
+
+	c.registerHookContext()
+
+	return nil
+}
+
+func (c resources) registerForClient() error {
+	c.registerPublicCommands()
+	return nil
+}
+
+func (resources) newPublicFacade(st *state.State, _ *common.Resources, authorizer common.Authorizer) (*server.PublicAPI, error) {
+	if !authorizer.AuthClient() {
+		return nil, common.ErrPerm
+	}
+	rst, err := st.Resources()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return server.NewPublicAPI(rst), nil
+}
+
+func (c resources) registerPublicFacade() {
+	common.RegisterStandardFacade(
+		resource.ComponentName,
+		0,
+		c.newPublicFacade,
+	)
+}
+
+// newAPIClient returns a client for the resources API of the command's
+// environment.
+func (resources) newAPIClient(c *envcmd.EnvCommandBase) (*client.Client, error) {
+	apiCaller, err := c.NewAPIRoot()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	httpClient, err := apiCaller.HTTPClient()
+	if err != nil {
+		apiCaller.Close()
+		return nil, errors.Trace(err)
+	}
+	caller := base.NewFacadeCallerForVersion(apiCaller, resource.ComponentName, 0)
+	return client.NewClient(&facadeCaller{
+		FacadeCaller: caller,
+		closeFunc:    apiCaller.Close,
+	}, httpClient), nil
+}
+
+func (c resources) registerPublicCommands() {
+	if !markRegistered(resource.ComponentName, "public-commands") {
+		return
+	}
+
+	commands.RegisterEnvCommand(func() envcmd.EnvironCommand {
+		return resourcecmd.NewPushCommand(func(command *resourcecmd.PushCommand) (resourcecmd.PushAPI, error) {
+			apiclient, err := c.newAPIClient(&command.EnvCommandBase)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			return apiclient, nil
+		})
+	})
+
+	commands.RegisterEnvCommand(func() envcmd.EnvironCommand {
+		return resourcecmd.NewListCommand(func(command *resourcecmd.ListCommand) (resourcecmd.ListAPI, error) {
+			apiclient, err := c.newAPIClient(&command.EnvCommandBase)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			return apiclient, nil
+		})
+	})
+}
+
+func (c resources) registerHookContext() {
+	if !markRegistered(resource.ComponentName, "hook-context") {
+		return
+	}
+
+	unitercontext.RegisterComponentFunc(resource.ComponentName,
+		func(config unitercontext.ComponentConfig) (jujuc.ContextComponent, error) {
+			httpClient, err := config.APICaller.HTTPClient()
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			unitClient := client.NewUnitClient(config.UnitName, httpClient)
+			return context.NewContext(unitClient, config.DataDir), nil
+		},
+	)
+
+	c.registerHookContextCommands()
+}
+
+func (resources) registerHookContextCommands() {
+	if !markRegistered(resource.ComponentName, "hook-context-commands") {
+		return
+	}
+
+	jujuc.RegisterCommand(jujuc.ResourceGetCmdName, jujuc.NewResourceGetCommand)
+}
+
+func (resources) registerState() {
+	newResources := func(persist state.ResourcesPersistence) (state.Resources, error) {
+		return resourcestate.NewResources(
+			persistence.NewPersistence(persist),
+			persist.Storage(),
+			persist.CharmResourceMeta,
+		), nil
+	}
+
+	state.SetResourcesComponent(newResources)
+}
@@ -178,12 +178,67 @@ const (
 	// is primarily for enabling Juju to work cleanly in a closed network.
 	CloudImageBaseURL = "cloudimg-base-url"
 
+	// HookTimeoutKey stores the key for the longest time a hook may
+	// run before the uniter kills it and puts the unit into an error
+	// state. Services may override it.
+	HookTimeoutKey = "hook-timeout"
+
+	// MaxStatusHistoryAgeKey stores the key for the age beyond which
+	// status history entries are pruned.
+	MaxStatusHistoryAgeKey = "max-status-history-age"
+
 	// IdentityURL sets the url of the identity manager.
 	IdentityURL = "identity-url"
 
 	// IdentityPublicKey sets the public key of the identity manager.
 	IdentityPublicKey = "identity-public-key"
 
+	// LDAPURLKey stores the key for the URL of the LDAP directory that
+	// users may log in with, e.g. ldaps://ldap.example.com.
+	LDAPURLKey = "ldap-url"
+
+	// LDAPBindDNKey and LDAPBindPasswordKey store the keys for the
+	// credentials used to search the LDAP directory for users. If they
+	// are not set, the directory is searched anonymously.
+	LDAPBindDNKey       = "ldap-bind-dn"
+	LDAPBindPasswordKey = "ldap-bind-password"
+
+	// LDAPUserBaseDNKey stores the key for the DN under which the LDAP
+	// directory is searched for users.
+	LDAPUserBaseDNKey = "ldap-user-base-dn"
+
+	// LDAPUserFilterKey stores the key for the LDAP filter that finds a
+	// user by name. The user name replaces the single %s in the filter.
+	LDAPUserFilterKey = "ldap-user-filter"
+
+	// LDAPGroupAccessKey stores the key for the map from LDAP group
+	// names to the access their members are given to the environment.
+	LDAPGroupAccessKey = "ldap-group-access"
+
+	// PasswordMinLengthKey stores the key for the minimum length of
+	// the passwords of local users.
+	PasswordMinLengthKey = "password-min-length"
+
+	// PasswordMinCharacterClassesKey stores the key for the minimum
+	// number of character classes (lower case letters, upper case
+	// letters, digits and other characters) that the passwords of
+	// local users must contain.
+	PasswordMinCharacterClassesKey = "password-min-character-classes"
+
+	// PasswordMaxAgeKey stores the key for how long the passwords of
+	// local users last before they must be changed.
+	PasswordMaxAgeKey = "password-max-age"
+
+	// LoginLockoutAttemptsKey stores the key for the number of failed
+	// logins within the lockout window after which a local user is
+	// locked out.
+	LoginLockoutAttemptsKey = "login-lockout-attempts"
+
+	// LoginLockoutWindowKey stores the key for the window within which
+	// failed logins count towards locking a local user out, which is
+	// also how long the lockout lasts.
+	LoginLockoutWindowKey = "login-lockout-window"
+
 	//
 	// Deprecated Settings Attributes
 	//
@@ -261,6 +316,13 @@ var ProxyAttributes = []string{
 	AptFtpProxyKey,
 }
 
+// ServerSecretAttributes holds the names of the settings that only the
+// API server itself may read. They are never returned to clients or
+// agents.
+var ServerSecretAttributes = []string{
+	LDAPBindPasswordKey,
+}
+
 // String returns the description of the harvesting mode.
 func (method HarvestMode) String() string {
 	if description, ok := harvestingMethodToFlag[method]; ok {
@@ -619,6 +681,14 @@ func Validate(cfg, old *Config) error {
 		}
 	}
 
+	if err := cfg.validateLDAP(); err != nil {
+		return errors.Trace(err)
+	}
+
+	if err := cfg.validatePasswordPolicy(); err != nil {
+		return errors.Trace(err)
+	}
+
 	caCert, caCertOK := cfg.CACert()
 	caKey, caKeyOK := cfg.CAPrivateKey()
 	if caCertOK || caKeyOK {
@@ -665,6 +735,28 @@ func Validate(cfg, old *Config) error {
 		}
 	}
 
+	// Check HookTimeout is a non-negative duration, when set.
+	if v := cfg.asString(HookTimeoutKey); v != "" {
+		timeout, err := time.ParseDuration(v)
+		if err != nil {
+			return errors.Annotatef(err, "invalid %s", HookTimeoutKey)
+		}
+		if timeout < 0 {
+			return errors.Errorf("%s: expected non-negative duration, got %v", HookTimeoutKey, v)
+		}
+	}
+
+	// Check MaxStatusHistoryAge is a non-negative duration, when set.
+	if v := cfg.asString(MaxStatusHistoryAgeKey); v != "" {
+		maxAge, err := time.ParseDuration(v)
+		if err != nil {
+			return errors.Annotatef(err, "invalid %s", MaxStatusHistoryAgeKey)
+		}
+		if maxAge < 0 {
+			return errors.Errorf("%s: expected non-negative duration, got %v", MaxStatusHistoryAgeKey, v)
+		}
+	}
+
 	// Check LXCDefaultMTU is a positive integer, when set.
 	if lxcDefaultMTU, ok := cfg.LXCDefaultMTU(); ok && lxcDefaultMTU < 0 {
 		return errors.Errorf("%s: expected positive integer, got %v", LXCDefaultMTU, lxcDefaultMTU)
@@ -1194,6 +1286,22 @@ func (c *Config) AllowLXCLoopMounts() (bool, bool) {
 	return v, ok
 }
 
+// HookTimeout returns the longest time a hook may run before it is
+// killed. Zero means hooks are never killed.
+func (c *Config) HookTimeout() time.Duration {
+	// Validate has already checked the value parses.
+	timeout, _ := time.ParseDuration(c.asString(HookTimeoutKey))
+	return timeout
+}
+
+// MaxStatusHistoryAge returns the age beyond which status history
+// entries are pruned. Zero means entries are only pruned by number.
+func (c *Config) MaxStatusHistoryAge() time.Duration {
+	// Validate has already checked the value parses.
+	maxAge, _ := time.ParseDuration(c.asString(MaxStatusHistoryAgeKey))
+	return maxAge
+}
+
 // CloudImageBaseURL returns the specified override url that the 'ubuntu-
 // cloudimg-query' executable uses to find container images. The empty string
 // means that the default URL is used.
@@ -1286,6 +1394,148 @@ func (c *Config) IdentityPublicKey() *bakery.PublicKey {
 	return &pubKey
 }
 
+// LDAPURL returns the URL of the LDAP directory that users may log in
+// with, or the empty string if users may not log in with a directory.
+func (c *Config) LDAPURL() string {
+	return c.asString(LDAPURLKey)
+}
+
+// LDAPBindDN returns the DN used to search the LDAP directory for
+// users. If it is empty, the directory is searched anonymously.
+func (c *Config) LDAPBindDN() string {
+	return c.asString(LDAPBindDNKey)
+}
+
+// LDAPBindPassword returns the password of the LDAP bind DN.
+func (c *Config) LDAPBindPassword() string {
+	return c.asString(LDAPBindPasswordKey)
+}
+
+// LDAPUserBaseDN returns the DN under which the LDAP directory is
+// searched for users.
+func (c *Config) LDAPUserBaseDN() string {
+	return c.asString(LDAPUserBaseDNKey)
+}
+
+// DefaultLDAPUserFilter is the LDAP filter used to find users by
+// name if none is configured.
+const DefaultLDAPUserFilter = "(uid=%s)"
+
+// LDAPUserFilter returns the LDAP filter that finds a user by name.
+// The user name replaces the single %s in the filter.
+func (c *Config) LDAPUserFilter() string {
+	if filter := c.asString(LDAPUserFilterKey); filter != "" {
+		return filter
+	}
+	return DefaultLDAPUserFilter
+}
+
+// LDAPGroupAccess returns the map from LDAP group names to the access
+// their members are given to the environment when they first log in
+// to it.
+func (c *Config) LDAPGroupAccess() map[string]string {
+	access, _ := c.defined[LDAPGroupAccessKey].(map[string]string)
+	return access
+}
+
+func (cfg *Config) validateLDAP() error {
+	v := cfg.LDAPURL()
+	if v == "" {
+		return nil
+	}
+	u, err := url.Parse(v)
+	if err != nil {
+		return errors.Annotatef(err, "invalid %s", LDAPURLKey)
+	}
+	if u.Scheme != "ldap" && u.Scheme != "ldaps" {
+		return errors.Errorf("%s: expected ldap or ldaps URL, got %q", LDAPURLKey, v)
+	}
+	if cfg.LDAPUserBaseDN() == "" {
+		return errors.Errorf("%s must be set when %s is", LDAPUserBaseDNKey, LDAPURLKey)
+	}
+	if filter := cfg.LDAPUserFilter(); strings.Count(filter, "%s") != 1 {
+		return errors.Errorf("%s: expected a single %%s, got %q", LDAPUserFilterKey, filter)
+	}
+	return nil
+}
+
+// PasswordMinLength returns the minimum length of the passwords of
+// local users.
+func (c *Config) PasswordMinLength() int {
+	v, _ := c.defined[PasswordMinLengthKey].(int)
+	return v
+}
+
+// PasswordMinCharacterClasses returns the minimum number of character
+// classes (lower case letters, upper case letters, digits and other
+// characters) that the passwords of local users must contain.
+func (c *Config) PasswordMinCharacterClasses() int {
+	v, _ := c.defined[PasswordMinCharacterClassesKey].(int)
+	return v
+}
+
+// PasswordMaxAge returns how long the passwords of local users last
+// before they must be changed. Zero means passwords never expire.
+func (c *Config) PasswordMaxAge() time.Duration {
+	// Validate has already checked the value parses.
+	maxAge, _ := time.ParseDuration(c.asString(PasswordMaxAgeKey))
+	return maxAge
+}
+
+// LoginLockoutAttempts returns the number of failed logins within the
+// lockout window after which a local user is locked out. Zero means
+// users are never locked out.
+func (c *Config) LoginLockoutAttempts() int {
+	v, _ := c.defined[LoginLockoutAttemptsKey].(int)
+	return v
+}
+
+// DefaultLoginLockoutWindow is the window within which failed logins
+// count towards locking a user out if none is configured.
+const DefaultLoginLockoutWindow = 15 * time.Minute
+
+// LoginLockoutWindow returns the window within which failed logins
+// count towards locking a local user out, which is also how long
+// the lockout lasts.
+func (c *Config) LoginLockoutWindow() time.Duration {
+	if v := c.asString(LoginLockoutWindowKey); v != "" {
+		// Validate has already checked the value parses.
+		window, _ := time.ParseDuration(v)
+		return window
+	}
+	return DefaultLoginLockoutWindow
+}
+
+func (cfg *Config) validatePasswordPolicy() error {
+	for _, key := range []string{PasswordMinLengthKey, PasswordMinCharacterClassesKey, LoginLockoutAttemptsKey} {
+		if v, _ := cfg.defined[key].(int); v < 0 {
+			return errors.Errorf("%s: expected non-negative integer, got %d", key, v)
+		}
+	}
+	if v := cfg.PasswordMinCharacterClasses(); v > 4 {
+		return errors.Errorf("%s: expected at most 4, got %d", PasswordMinCharacterClassesKey, v)
+	}
+	if v := cfg.asString(PasswordMaxAgeKey); v != "" {
+		maxAge, err := time.ParseDuration(v)
+		if err != nil {
+			return errors.Annotatef(err, "invalid %s", PasswordMaxAgeKey)
+		}
+		if maxAge < 0 {
+			return errors.Errorf("%s: expected non-negative duration, got %v", PasswordMaxAgeKey, v)
+		}
+	}
+	if v := cfg.asString(LoginLockoutWindowKey); v != "" {
+		window, err := time.ParseDuration(v)
+		if err != nil {
+			return errors.Annotatef(err, "invalid %s", LoginLockoutWindowKey)
+		}
+		if window <= 0 {
+			return errors.Errorf("%s: expected positive duration, got %v", LoginLockoutWindowKey, v)
+		}
+	}
+	return nil
+}
+
 // fields holds the validation schema fields derived from configSchema.
 var fields = func() schema.Fields {
 	fs, _, err := configSchema.ValidationSchema()
@@ -1332,10 +1582,25 @@ var alwaysOptional = schema.Defaults{
 	AgentStreamKey:               schema.Omit,
 	IdentityURL:                  schema.Omit,
 	IdentityPublicKey:            schema.Omit,
+	LDAPURLKey:                   schema.Omit,
+	LDAPBindDNKey:                schema.Omit,
+	LDAPBindPasswordKey:          schema.Omit,
+	LDAPUserBaseDNKey:            schema.Omit,
+	LDAPUserFilterKey:            schema.Omit,
+	LDAPGroupAccessKey:           schema.Omit,
 	SetNumaControlPolicyKey:      DefaultNumaControlPolicy,
 	AllowLXCLoopMounts:           false,
 	ResourceTagsKey:              schema.Omit,
 	CloudImageBaseURL:            schema.Omit,
+	HookTimeoutKey:               schema.Omit,
+	MaxStatusHistoryAgeKey:       schema.Omit,
+
+	// Password policy related config.
+	PasswordMinLengthKey:           schema.Omit,
+	PasswordMinCharacterClassesKey: schema.Omit,
+	PasswordMaxAgeKey:              schema.Omit,
+	LoginLockoutAttemptsKey:        schema.Omit,
+	LoginLockoutWindowKey:          schema.Omit,
 
 	// Storage related config.
 	// Environ providers will specify their own defaults.
@@ -1705,6 +1970,16 @@ var configSchema = environschema.Fields{
 		Type:        environschema.Tstring,
 		Group:       environschema.EnvironGroup,
 	},
+	HookTimeoutKey: {
+		Description: "The longest time a hook may run, e.g. 30m, before it is killed and the unit is put into an error state. Hooks may run indefinitely if unset.",
+		Type:        environschema.Tstring,
+		Group:       environschema.EnvironGroup,
+	},
+	MaxStatusHistoryAgeKey: {
+		Description: "The age, e.g. 336h, beyond which status history entries are removed. If unset, entries are only removed once an entity has more than 100 of them.",
+		Type:        environschema.Tstring,
+		Group:       environschema.EnvironGroup,
+	},
 	"default-series": {
 		Description: "The default series of Ubuntu to use for deploying charms",
 		Type:        environschema.Tstring,
@@ -1927,4 +2202,62 @@ data of the store. (default false)`,
 		Group:       environschema.JujuGroup,
 		Immutable:   true,
 	},
+	LDAPURLKey: {
+		Description: "The URL of an LDAP directory that users may log in with, e.g. ldaps://ldap.example.com. Only used in the state server environment.",
+		Type:        environschema.Tstring,
+		Group:       environschema.EnvironGroup,
+	},
+	LDAPBindDNKey: {
+		Description: "The DN used to search the LDAP directory for users. The directory is searched anonymously if unset.",
+		Type:        environschema.Tstring,
+		Group:       environschema.EnvironGroup,
+	},
+	LDAPBindPasswordKey: {
+		Description: "The password of the LDAP bind DN.",
+		Type:        environschema.Tstring,
+		Secret:      true,
+		Group:       environschema.EnvironGroup,
+	},
+	LDAPUserBaseDNKey: {
+		Description: "The DN under which the LDAP directory is searched for users, e.g. ou=people,dc=example,dc=com.",
+		Type:        environschema.Tstring,
+		Group:       environschema.EnvironGroup,
+	},
+	LDAPUserFilterKey: {
+		Description: "The LDAP filter that finds a user by name, with %s standing for the name.",
+		Type:        environschema.Tstring,
+		Example:     DefaultLDAPUserFilter,
+		Group:       environschema.EnvironGroup,
+	},
+	LDAPGroupAccessKey: {
+		Description: "LDAP group names and the access their members are given to this environment whenever they log in to it, e.g. juju-admins=admin developers=deployer. Directory users are only added to environments that set this. In the state server environment it also decides who may log in: directory users in none of its groups cannot log in.",
+		Type:        environschema.Tattrs,
+		Group:       environschema.EnvironGroup,
+	},
+	PasswordMinLengthKey: {
+		Description: "The minimum length of the passwords of local users. Only used in the state server environment.",
+		Type:        environschema.Tint,
+		Group:       environschema.EnvironGroup,
+	},
+	PasswordMinCharacterClassesKey: {
+		Description: "The minimum number of character classes (lower case letters, upper case letters, digits and other characters) that the passwords of local users must contain. Only used in the state server environment.",
+		Type:        environschema.Tint,
+		Group:       environschema.EnvironGroup,
+	},
+	PasswordMaxAgeKey: {
+		Description: "How long the passwords of local users last before they must be changed, e.g. 2160h. Passwords never expire if unset. Only used in the state server environment.",
+		Type:        environschema.Tstring,
+		Group:       environschema.EnvironGroup,
+	},
+	LoginLockoutAttemptsKey: {
+		Description: "The number of failed logins within the lockout window after which a local user is locked out for the length of the window, or until they are enabled again. Users are never locked out if unset. Only used in the state server environment.",
+		Type:        environschema.Tint,
+		Group:       environschema.EnvironGroup,
+	},
+	LoginLockoutWindowKey: {
+		Description: "The window within which failed logins count towards locking a local user out, and how long the lockout lasts.",
+		Type:        environschema.Tstring,
+		Example:     DefaultLoginLockoutWindow.String(),
+		Group:       environschema.EnvironGroup,
+	},
 }
@@ -258,6 +258,138 @@ var configTests = []configTest{
 			"lxc-default-mtu": -42,
 		},
 		err: `lxc-default-mtu: expected positive integer, got -42`,
+	}, {
+		about:       "Hook timeout set explicitly",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":         "my-type",
+			"name":         "my-name",
+			"hook-timeout": "30m",
+		},
+	}, {
+		about:       "Hook timeout invalid",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":         "my-type",
+			"name":         "my-name",
+			"hook-timeout": "forever",
+		},
+		err: `invalid hook-timeout: time: invalid duration forever`,
+	}, {
+		about:       "Hook timeout invalid (negative)",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":         "my-type",
+			"name":         "my-name",
+			"hook-timeout": "-5m",
+		},
+		err: `hook-timeout: expected non-negative duration, got -5m`,
+	}, {
+		about:       "Max status history age set explicitly",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":                   "my-type",
+			"name":                   "my-name",
+			"max-status-history-age": "336h",
+		},
+	}, {
+		about:       "Max status history age invalid",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":                   "my-type",
+			"name":                   "my-name",
+			"max-status-history-age": "-1h",
+		},
+		err: `max-status-history-age: expected non-negative duration, got -1h`,
+	}, {
+		about:       "LDAP directory set",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":              "my-type",
+			"name":              "my-name",
+			"ldap-url":          "ldaps://ldap.example.com",
+			"ldap-user-base-dn": "ou=people,dc=example,dc=com",
+			"ldap-user-filter":  "(&(objectClass=person)(uid=%s))",
+			"ldap-group-access": "juju-admins=admin developers=deployer",
+		},
+	}, {
+		about:       "LDAP URL invalid scheme",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":              "my-type",
+			"name":              "my-name",
+			"ldap-url":          "https://ldap.example.com",
+			"ldap-user-base-dn": "ou=people,dc=example,dc=com",
+		},
+		err: `ldap-url: expected ldap or ldaps URL, got "https://ldap.example.com"`,
+	}, {
+		about:       "LDAP user base DN missing",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":     "my-type",
+			"name":     "my-name",
+			"ldap-url": "ldap://ldap.example.com",
+		},
+		err: `ldap-user-base-dn must be set when ldap-url is`,
+	}, {
+		about:       "LDAP user filter invalid",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":              "my-type",
+			"name":              "my-name",
+			"ldap-url":          "ldap://ldap.example.com",
+			"ldap-user-base-dn": "ou=people,dc=example,dc=com",
+			"ldap-user-filter":  "(objectClass=person)",
+		},
+		err: `ldap-user-filter: expected a single %s, got "\(objectClass=person\)"`,
+	}, {
+		about:       "Password policy set",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":                           "my-type",
+			"name":                           "my-name",
+			"password-min-length":            12,
+			"password-min-character-classes": 3,
+			"password-max-age":               "2160h",
+			"login-lockout-attempts":         5,
+			"login-lockout-window":           "30m",
+		},
+	}, {
+		about:       "Password minimum length negative",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":                "my-type",
+			"name":                "my-name",
+			"password-min-length": -1,
+		},
+		err: `password-min-length: expected non-negative integer, got -1`,
+	}, {
+		about:       "Password character classes too many",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":                           "my-type",
+			"name":                           "my-name",
+			"password-min-character-classes": 5,
+		},
+		err: `password-min-character-classes: expected at most 4, got 5`,
+	}, {
+		about:       "Password max age invalid",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":             "my-type",
+			"name":             "my-name",
+			"password-max-age": "90 days",
+		},
+		err: `invalid password-max-age: time: .*`,
+	}, {
+		about:       "Login lockout window not positive",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":                 "my-type",
+			"name":                 "my-name",
+			"login-lockout-window": "0s",
+		},
+		err: `login-lockout-window: expected positive duration, got 0s`,
 	}, {
 		about:       "CA cert & key from path",
 		useDefaults: config.UseDefaults,
@@ -1763,6 +1895,61 @@ func (s *ConfigSuite) TestCloudImageBaseURLSet(c *gc.C) {
 	c.Assert(config.CloudImageBaseURL(), gc.Equals, "http://local.foo/query")
 }
 
+func (s *ConfigSuite) TestLDAPNotSet(c *gc.C) {
+	s.addJujuFiles(c)
+	config := newTestConfig(c, testing.Attrs{})
+	c.Assert(config.LDAPURL(), gc.Equals, "")
+	c.Assert(config.LDAPUserFilter(), gc.Equals, "(uid=%s)")
+	c.Assert(config.LDAPGroupAccess(), gc.HasLen, 0)
+}
+
+func (s *ConfigSuite) TestLDAPSet(c *gc.C) {
+	s.addJujuFiles(c)
+	config := newTestConfig(c, testing.Attrs{
+		"ldap-url":           "ldaps://ldap.example.com",
+		"ldap-bind-dn":       "cn=juju,dc=example,dc=com",
+		"ldap-bind-password": "sekrit",
+		"ldap-user-base-dn":  "ou=people,dc=example,dc=com",
+		"ldap-user-filter":   "(mail=%s@example.com)",
+		"ldap-group-access":  "juju-admins=admin developers=deployer",
+	})
+	c.Assert(config.LDAPURL(), gc.Equals, "ldaps://ldap.example.com")
+	c.Assert(config.LDAPBindDN(), gc.Equals, "cn=juju,dc=example,dc=com")
+	c.Assert(config.LDAPBindPassword(), gc.Equals, "sekrit")
+	c.Assert(config.LDAPUserBaseDN(), gc.Equals, "ou=people,dc=example,dc=com")
+	c.Assert(config.LDAPUserFilter(), gc.Equals, "(mail=%s@example.com)")
+	c.Assert(config.LDAPGroupAccess(), jc.DeepEquals, map[string]string{
+		"juju-admins": "admin",
+		"developers":  "deployer",
+	})
+}
+
+func (s *ConfigSuite) TestPasswordPolicyNotSet(c *gc.C) {
+	s.addJujuFiles(c)
+	config := newTestConfig(c, testing.Attrs{})
+	c.Assert(config.PasswordMinLength(), gc.Equals, 0)
+	c.Assert(config.PasswordMinCharacterClasses(), gc.Equals, 0)
+	c.Assert(config.PasswordMaxAge(), gc.Equals, time.Duration(0))
+	c.Assert(config.LoginLockoutAttempts(), gc.Equals, 0)
+	c.Assert(config.LoginLockoutWindow(), gc.Equals, 15*time.Minute)
+}
+
+func (s *ConfigSuite) TestPasswordPolicySet(c *gc.C) {
+	s.addJujuFiles(c)
+	config := newTestConfig(c, testing.Attrs{
+		"password-min-length":            12,
+		"password-min-character-classes": 3,
+		"password-max-age":               "2160h",
+		"login-lockout-attempts":         5,
+		"login-lockout-window":           "30m",
+	})
+	c.Assert(config.PasswordMinLength(), gc.Equals, 12)
+	c.Assert(config.PasswordMinCharacterClasses(), gc.Equals, 3)
+	c.Assert(config.PasswordMaxAge(), gc.Equals, 90*24*time.Hour)
+	c.Assert(config.LoginLockoutAttempts(), gc.Equals, 5)
+	c.Assert(config.LoginLockoutWindow(), gc.Equals, 30*time.Minute)
+}
+
 func (s *ConfigSuite) TestProxyValuesWithFallback(c *gc.C) {
 	s.addJujuFiles(c)
 
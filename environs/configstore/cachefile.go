@@ -39,6 +39,14 @@ type ServerData struct {
 	// Identities is a mapping of full username to credentials.
 	Identities      map[string]string      `yaml:"identities"`
 	BootstrapConfig map[string]interface{} `yaml:"bootstrap-config,omitempty"`
+	// CredentialHelpers maps full usernames to the names of the
+	// credential helpers holding their passwords, for those not
+	// held in Identities.
+	CredentialHelpers map[string]string `yaml:"credential-helpers,omitempty"`
+	// BootstrapCredentialHelper names the credential helper holding
+	// the secret attributes of BootstrapConfig, if they are not
+	// held there.
+	BootstrapCredentialHelper string `yaml:"bootstrap-credential-helper,omitempty"`
 }
 
 // EnvironmentData represents a single environment running in a Juju
@@ -109,17 +117,22 @@ func (cache CacheFile) readInfo(envName string) (*environInfo, error) {
 	}
 
 	info.credentials = srvData.Identities[info.user]
+	info.passwordHelper = srvData.CredentialHelpers[info.user]
+	info.passwordPending = info.passwordHelper != ""
 	info.caCert = srvData.CACert
 	info.apiEndpoints = srvData.APIEndpoints
 	info.apiHostnames = srvData.ServerHostnames
 	if info.serverUUID == info.environmentUUID {
 		info.bootstrapConfig = srvData.BootstrapConfig
+		info.bootstrapHelper = srvData.BootstrapCredentialHelper
+		info.bootstrapPending = info.bootstrapHelper != "" && info.bootstrapConfig != nil
 	}
 	return info, nil
 }
 
-func (cache *CacheFile) updateInfo(info *environInfo) error {
-	// If the info is new, then check for name clashes.
+// checkNewInfo returns ErrEnvironInfoAlreadyExists if the info is new
+// and its name clashes with an existing entry.
+func (cache *CacheFile) checkNewInfo(info *environInfo) error {
 	if info.source == sourceCreated {
 		if _, found := cache.Environment[info.name]; found {
 			return ErrEnvironInfoAlreadyExists
@@ -133,6 +146,13 @@ func (cache *CacheFile) updateInfo(info *environInfo) error {
 			}
 		}
 	}
+	return nil
+}
+
+func (cache *CacheFile) updateInfo(info *environInfo) error {
+	if err := cache.checkNewInfo(info); err != nil {
+		return errors.Trace(err)
+	}
 
 	// If the serverUUID and environmentUUID are the same, or the
 	// environmentUUID is not specified, then add a name entry
@@ -160,12 +180,21 @@ func (cache *CacheFile) updateInfo(info *environInfo) error {
 	serverData.ServerHostnames = info.apiHostnames
 	serverData.CACert = info.caCert
 	if info.bootstrapConfig != nil {
-		serverData.BootstrapConfig = info.bootstrapConfig
+		serverData.BootstrapConfig = info.fileBootstrapConfig()
+		serverData.BootstrapCredentialHelper = info.bootstrapHelper
 	}
 	if serverData.Identities == nil {
 		serverData.Identities = make(map[string]string)
 	}
-	serverData.Identities[info.user] = info.credentials
+	serverData.Identities[info.user] = info.filePassword()
+	if info.passwordHelper != "" {
+		if serverData.CredentialHelpers == nil {
+			serverData.CredentialHelpers = make(map[string]string)
+		}
+		serverData.CredentialHelpers[info.user] = info.passwordHelper
+	} else {
+		delete(serverData.CredentialHelpers, info.user)
+	}
 	cache.ServerData[info.serverUUID] = serverData
 	return nil
 }
@@ -0,0 +1,330 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package configstore
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/juju/osenv"
+)
+
+// A credential helper is an external executable that stores secrets on
+// behalf of the disk store, so that passwords and private keys need not
+// be written to the .jenv and cache.yaml files. It is chosen by setting
+// $JUJU_CREDENTIAL_HELPER to a path, or to a name; a name is resolved
+// to an executable called juju-credential-<name> in $PATH.
+//
+// The helper is run with a single argument naming the action: "get",
+// "store" or "erase". A JSON object is written to its standard input,
+// and for "get" a JSON object is read from its standard output:
+//
+//	store: {"key": <key>, "secret": <secret>}
+//	get:   {"key": <key>}  ->  {"secret": <secret>}
+//	erase: {"key": <key>}
+//
+// When asked to get or erase an unknown key, the helper must print
+// "credentials not found" and exit with a non-zero status. Any other
+// failure is reported by a non-zero exit status and a message on
+// standard output or standard error.
+//
+// If no helper is set, or the helper fails to store a secret, secrets
+// are written to the files in plain text as before.
+
+const (
+	credentialHelperPrefix = "juju-credential-"
+	credentialsNotFound    = "credentials not found"
+)
+
+// secretBootstrapAttrs holds the bootstrap configuration attributes
+// kept with the credential helper.
+var secretBootstrapAttrs = []string{"admin-secret", "ca-private-key"}
+
+// CredentialHelper stores secrets outside the configuration files.
+type CredentialHelper interface {
+	// Get returns the secret stored with the given key. If there is
+	// no such secret, an error satisfying errors.IsNotFound is
+	// returned.
+	Get(key string) (string, error)
+
+	// Store stores the secret with the given key, replacing any
+	// secret already stored with it.
+	Store(key, secret string) error
+
+	// Erase removes the secret stored with the given key.
+	Erase(key string) error
+}
+
+// credentialHelperName returns the name of the credential helper
+// configured in the environment, or "" if there is none.
+func credentialHelperName() string {
+	return os.Getenv(osenv.JujuCredentialHelperEnvKey)
+}
+
+// newCredentialHelper returns the credential helper with the given
+// name.
+var newCredentialHelper = func(name string) CredentialHelper {
+	return execCredentialHelper{name: name}
+}
+
+// execCredentialHelper is a CredentialHelper that runs an external
+// executable following the protocol described above.
+type execCredentialHelper struct {
+	name string
+}
+
+type credentialHelperRequest struct {
+	Key    string `json:"key"`
+	Secret string `json:"secret,omitempty"`
+}
+
+type credentialHelperResponse struct {
+	Secret string `json:"secret"`
+}
+
+// Get implements CredentialHelper.
+func (h execCredentialHelper) Get(key string) (string, error) {
+	var resp credentialHelperResponse
+	if err := h.run("get", credentialHelperRequest{Key: key}, &resp); err != nil {
+		return "", errors.Trace(err)
+	}
+	return resp.Secret, nil
+}
+
+// Store implements CredentialHelper.
+func (h execCredentialHelper) Store(key, secret string) error {
+	req := credentialHelperRequest{Key: key, Secret: secret}
+	return errors.Trace(h.run("store", req, nil))
+}
+
+// Erase implements CredentialHelper.
+func (h execCredentialHelper) Erase(key string) error {
+	return errors.Trace(h.run("erase", credentialHelperRequest{Key: key}, nil))
+}
+
+func (h execCredentialHelper) path() (string, error) {
+	if strings.ContainsRune(h.name, os.PathSeparator) || strings.ContainsRune(h.name, '/') {
+		return h.name, nil
+	}
+	path, err := exec.LookPath(credentialHelperPrefix + h.name)
+	return path, errors.Annotatef(err, "cannot find credential helper %q", h.name)
+}
+
+func (h execCredentialHelper) run(action string, req credentialHelperRequest, resp interface{}) error {
+	path, err := h.path()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	input, err := json.Marshal(req)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(path, action)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		message := strings.TrimSpace(stdout.String())
+		if message == credentialsNotFound {
+			return errors.NotFoundf("credentials for %q", req.Key)
+		}
+		if message == "" {
+			message = strings.TrimSpace(stderr.String())
+		}
+		return errors.Errorf("credential helper %q %s failed: %v: %s", h.name, action, err, message)
+	}
+	if resp == nil {
+		return nil
+	}
+	if err := json.Unmarshal(stdout.Bytes(), resp); err != nil {
+		return errors.Annotatef(err, "invalid output from credential helper %q", h.name)
+	}
+	return nil
+}
+
+// passwordKey returns the credential helper key for the password of
+// the info's user.
+func (info *environInfo) passwordKey(inCache bool) string {
+	if inCache {
+		return serverIdentityKey(info.serverUUID, info.user)
+	}
+	return "juju:env:" + info.name + ":password"
+}
+
+// bootstrapKey returns the credential helper key for the given secret
+// bootstrap configuration attribute.
+func (info *environInfo) bootstrapKey(inCache bool, attr string) string {
+	if inCache {
+		return serverBootstrapKey(info.serverUUID, attr)
+	}
+	return "juju:env:" + info.name + ":bootstrap-config:" + attr
+}
+
+// serverIdentityKey returns the credential helper key for the password
+// of the given user on the given server. It is shared by all the
+// environments on the server in the cache file.
+func serverIdentityKey(serverUUID, user string) string {
+	return "juju:server:" + serverUUID + ":identity:" + user
+}
+
+// serverBootstrapKey returns the credential helper key for the given
+// secret bootstrap configuration attribute of the given server.
+func serverBootstrapKey(serverUUID, attr string) string {
+	return "juju:server:" + serverUUID + ":bootstrap-config:" + attr
+}
+
+// loadPassword fetches the password from the credential helper, if it
+// was not read from the file. It must be called with info.mu held.
+func (info *environInfo) loadPassword() error {
+	if !info.passwordPending {
+		return nil
+	}
+	helper := newCredentialHelper(info.passwordHelper)
+	password, err := helper.Get(info.passwordKey(info.source == sourceCache))
+	if err != nil {
+		return errors.Annotatef(err, "cannot get password from credential helper %q", info.passwordHelper)
+	}
+	info.credentials = password
+	info.passwordPending = false
+	return nil
+}
+
+// loadBootstrapSecrets fetches the secret bootstrap configuration
+// attributes from the credential helper, if they were not read from
+// the file. It must be called with info.mu held.
+func (info *environInfo) loadBootstrapSecrets() error {
+	if !info.bootstrapPending {
+		return nil
+	}
+	helper := newCredentialHelper(info.bootstrapHelper)
+	attrs := make(map[string]interface{})
+	for attr, value := range info.bootstrapConfig {
+		attrs[attr] = value
+	}
+	for _, attr := range secretBootstrapAttrs {
+		secret, err := helper.Get(info.bootstrapKey(info.source == sourceCache, attr))
+		if errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return errors.Annotatef(err, "cannot get %s from credential helper %q", attr, info.bootstrapHelper)
+		}
+		attrs[attr] = secret
+	}
+	info.bootstrapConfig = attrs
+	info.bootstrapPending = false
+	return nil
+}
+
+// loadSecrets fetches any secrets not read from the file from the
+// credential helpers holding them. It must be called with info.mu held.
+func (info *environInfo) loadSecrets() error {
+	if err := info.loadPassword(); err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(info.loadBootstrapSecrets())
+}
+
+// storeSecrets stores the info's secrets with the credential helper
+// configured in the environment, if there is one, so that they can be
+// left out of the file written to. If the secrets cannot be stored,
+// they are written to the file instead. It must be called with info.mu
+// held, after loadSecrets.
+func (info *environInfo) storeSecrets(inCache bool) {
+	info.passwordHelper = ""
+	info.bootstrapHelper = ""
+	name := credentialHelperName()
+	if name == "" {
+		return
+	}
+	helper := newCredentialHelper(name)
+	if err := helper.Store(info.passwordKey(inCache), info.credentials); err != nil {
+		logger.Warningf("cannot store password with credential helper %q, writing it to file: %v", name, err)
+		return
+	}
+	info.passwordHelper = name
+	if info.bootstrapConfig == nil {
+		return
+	}
+	for _, attr := range secretBootstrapAttrs {
+		value, ok := info.bootstrapConfig[attr].(string)
+		if !ok {
+			continue
+		}
+		if err := helper.Store(info.bootstrapKey(inCache, attr), value); err != nil {
+			logger.Warningf("cannot store %s with credential helper %q, writing it to file: %v", attr, name, err)
+			return
+		}
+	}
+	info.bootstrapHelper = name
+}
+
+// eraseJENVSecrets removes the secrets of a .jenv file from the given
+// credential helpers; either may be empty. Failures are logged but
+// otherwise ignored, as there is nothing more to be done about them.
+// It must be called with info.mu held.
+func (info *environInfo) eraseJENVSecrets(passwordHelper, bootstrapHelper string) {
+	if passwordHelper != "" {
+		eraseSecret(passwordHelper, info.passwordKey(false))
+	}
+	if bootstrapHelper != "" {
+		for _, attr := range secretBootstrapAttrs {
+			eraseSecret(bootstrapHelper, info.bootstrapKey(false, attr))
+		}
+	}
+}
+
+// eraseServerSecrets removes the secrets referred to by the server
+// data of the given server from the credential helpers holding them.
+// It must only be called once the server data has been removed from
+// the cache file, as the secrets are shared by all the environments
+// on the server.
+func eraseServerSecrets(serverUUID string, data ServerData) {
+	for user, name := range data.CredentialHelpers {
+		eraseSecret(name, serverIdentityKey(serverUUID, user))
+	}
+	if data.BootstrapCredentialHelper != "" {
+		for _, attr := range secretBootstrapAttrs {
+			eraseSecret(data.BootstrapCredentialHelper, serverBootstrapKey(serverUUID, attr))
+		}
+	}
+}
+
+// eraseSecret removes the secret with the given key from the named
+// credential helper, logging any failure.
+func eraseSecret(helperName, key string) {
+	helper := newCredentialHelper(helperName)
+	if err := helper.Erase(key); err != nil && !errors.IsNotFound(err) {
+		logger.Warningf("cannot erase %q: %v", key, err)
+	}
+}
+
+// filePassword returns the password to be written to file.
+func (info *environInfo) filePassword() string {
+	if info.passwordHelper != "" {
+		return ""
+	}
+	return info.credentials
+}
+
+// fileBootstrapConfig returns the bootstrap configuration to be written
+// to file.
+func (info *environInfo) fileBootstrapConfig() map[string]interface{} {
+	if info.bootstrapHelper == "" || info.bootstrapConfig == nil {
+		return info.bootstrapConfig
+	}
+	attrs := make(map[string]interface{})
+	for attr, value := range info.bootstrapConfig {
+		attrs[attr] = value
+	}
+	for _, attr := range secretBootstrapAttrs {
+		delete(attrs, attr)
+	}
+	return attrs
+}
@@ -0,0 +1,344 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package configstore_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	goyaml "gopkg.in/yaml.v2"
+
+	"github.com/juju/juju/environs/configstore"
+	"github.com/juju/juju/feature"
+	"github.com/juju/juju/juju/osenv"
+	"github.com/juju/juju/testing"
+)
+
+var _ = gc.Suite(&credentialHelperSuite{})
+
+type credentialHelperSuite struct {
+	testing.BaseSuite
+	dir     string
+	store   configstore.Storage
+	helper  *fakeCredentialHelper
+	helpers []string
+}
+
+func (s *credentialHelperSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.dir = c.MkDir()
+	store, err := configstore.NewDisk(s.dir)
+	c.Assert(err, jc.ErrorIsNil)
+	s.store = store
+	s.helper = &fakeCredentialHelper{secrets: make(map[string]string)}
+	s.helpers = nil
+	s.PatchValue(configstore.NewCredentialHelper, func(name string) configstore.CredentialHelper {
+		s.helpers = append(s.helpers, name)
+		return s.helper
+	})
+	s.PatchEnvironment(osenv.JujuCredentialHelperEnvKey, "fake")
+}
+
+// fakeCredentialHelper is a CredentialHelper that keeps secrets in
+// memory.
+type fakeCredentialHelper struct {
+	secrets  map[string]string
+	storeErr error
+}
+
+func (h *fakeCredentialHelper) Get(key string) (string, error) {
+	secret, ok := h.secrets[key]
+	if !ok {
+		return "", errors.NotFoundf("credentials for %q", key)
+	}
+	return secret, nil
+}
+
+func (h *fakeCredentialHelper) Store(key, secret string) error {
+	if h.storeErr != nil {
+		return h.storeErr
+	}
+	h.secrets[key] = secret
+	return nil
+}
+
+func (h *fakeCredentialHelper) Erase(key string) error {
+	if _, ok := h.secrets[key]; !ok {
+		return errors.NotFoundf("credentials for %q", key)
+	}
+	delete(h.secrets, key)
+	return nil
+}
+
+func (s *credentialHelperSuite) writeInfo(c *gc.C, name, uuid string) configstore.EnvironInfo {
+	info := s.store.CreateInfo(name)
+	info.SetBootstrapConfig(map[string]interface{}{
+		"name":           name,
+		"admin-secret":   "sekrit",
+		"ca-private-key": "<private key>",
+	})
+	info.SetAPIEndpoint(configstore.APIEndpoint{
+		Addresses:   []string{"address1"},
+		CACert:      testing.CACert,
+		EnvironUUID: uuid,
+		ServerUUID:  uuid,
+	})
+	info.SetAPICredentials(configstore.APICredentials{
+		User:     "admin",
+		Password: "hunter2",
+	})
+	err := info.Write()
+	c.Assert(err, jc.ErrorIsNil)
+	return info
+}
+
+func (s *credentialHelperSuite) checkInfo(c *gc.C, name string) {
+	info, err := s.store.ReadInfo(name)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(info.APICredentials(), jc.DeepEquals, configstore.APICredentials{
+		User:     "admin",
+		Password: "hunter2",
+	})
+	c.Check(info.BootstrapConfig(), jc.DeepEquals, map[string]interface{}{
+		"name":           name,
+		"admin-secret":   "sekrit",
+		"ca-private-key": "<private key>",
+	})
+}
+
+func (s *credentialHelperSuite) TestJENV(c *gc.C) {
+	s.writeInfo(c, "someenv", "")
+
+	data, err := ioutil.ReadFile(configstore.JENVFilename(filepath.Join(s.dir, "environments"), "someenv"))
+	c.Assert(err, jc.ErrorIsNil)
+	var values configstore.EnvironInfoData
+	err = goyaml.Unmarshal(data, &values)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(values.Password, gc.Equals, "")
+	c.Check(values.CredentialHelper, gc.Equals, "fake")
+	c.Check(values.Config, jc.DeepEquals, map[string]interface{}{"name": "someenv"})
+	c.Check(values.BootstrapCredentialHelper, gc.Equals, "fake")
+	c.Check(s.helper.secrets, jc.DeepEquals, map[string]string{
+		"juju:env:someenv:password":                        "hunter2",
+		"juju:env:someenv:bootstrap-config:admin-secret":   "sekrit",
+		"juju:env:someenv:bootstrap-config:ca-private-key": "<private key>",
+	})
+
+	// Secrets are only fetched when asked for.
+	s.helpers = nil
+	info, err := s.store.ReadInfo("someenv")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(s.helpers, gc.HasLen, 0)
+	c.Check(info.APICredentials().Password, gc.Equals, "hunter2")
+	c.Check(s.helpers, jc.DeepEquals, []string{"fake"})
+
+	s.checkInfo(c, "someenv")
+
+	err = info.Destroy()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(s.helper.secrets, gc.HasLen, 0)
+}
+
+func (s *credentialHelperSuite) TestCacheFile(c *gc.C) {
+	s.SetFeatureFlags(feature.JES)
+	uuid := testing.EnvironmentTag.Id()
+	s.writeInfo(c, "someenv", uuid)
+
+	cache, err := configstore.ReadCacheFile(configstore.CacheFilename(filepath.Join(s.dir, "environments")))
+	c.Assert(err, jc.ErrorIsNil)
+	serverData := cache.ServerData[uuid]
+	c.Check(serverData.Identities, jc.DeepEquals, map[string]string{"admin": ""})
+	c.Check(serverData.CredentialHelpers, jc.DeepEquals, map[string]string{"admin": "fake"})
+	c.Check(serverData.BootstrapConfig, jc.DeepEquals, map[string]interface{}{"name": "someenv"})
+	c.Check(serverData.BootstrapCredentialHelper, gc.Equals, "fake")
+	c.Check(s.helper.secrets, jc.DeepEquals, map[string]string{
+		"juju:server:" + uuid + ":identity:admin":                  "hunter2",
+		"juju:server:" + uuid + ":bootstrap-config:admin-secret":   "sekrit",
+		"juju:server:" + uuid + ":bootstrap-config:ca-private-key": "<private key>",
+	})
+
+	s.checkInfo(c, "someenv")
+}
+
+func (s *credentialHelperSuite) TestDestroyKeepsSharedServerSecrets(c *gc.C) {
+	s.SetFeatureFlags(feature.JES)
+	serverUUID := testing.EnvironmentTag.Id()
+	s.writeInfo(c, "someenv", serverUUID)
+
+	info := s.store.CreateInfo("otherenv")
+	info.SetAPIEndpoint(configstore.APIEndpoint{
+		Addresses:   []string{"address1"},
+		CACert:      testing.CACert,
+		EnvironUUID: "deadbeef-0bad-400d-8000-4b1d0d06f00d",
+		ServerUUID:  serverUUID,
+	})
+	info.SetAPICredentials(configstore.APICredentials{
+		User:     "admin",
+		Password: "hunter2",
+	})
+	err := info.Write()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(s.helper.secrets, gc.HasLen, 3)
+
+	// The server is still used by someenv, so its secrets are kept.
+	err = info.Destroy()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(s.helper.secrets, gc.HasLen, 3)
+	s.checkInfo(c, "someenv")
+
+	info, err = s.store.ReadInfo("someenv")
+	c.Assert(err, jc.ErrorIsNil)
+	err = info.Destroy()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(s.helper.secrets, gc.HasLen, 0)
+}
+
+func (s *credentialHelperSuite) TestMigrateJENVErasesSecrets(c *gc.C) {
+	s.writeInfo(c, "someenv", "")
+	c.Check(s.helper.secrets, gc.HasLen, 3)
+
+	s.SetFeatureFlags(feature.JES)
+	uuid := testing.EnvironmentTag.Id()
+	info, err := s.store.ReadInfo("someenv")
+	c.Assert(err, jc.ErrorIsNil)
+	info.SetAPIEndpoint(configstore.APIEndpoint{
+		Addresses:   []string{"address1"},
+		CACert:      testing.CACert,
+		EnvironUUID: uuid,
+		ServerUUID:  uuid,
+	})
+	err = info.Write()
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(s.helper.secrets, jc.DeepEquals, map[string]string{
+		"juju:server:" + uuid + ":identity:admin":                  "hunter2",
+		"juju:server:" + uuid + ":bootstrap-config:admin-secret":   "sekrit",
+		"juju:server:" + uuid + ":bootstrap-config:ca-private-key": "<private key>",
+	})
+	s.checkInfo(c, "someenv")
+}
+
+func (s *credentialHelperSuite) TestRewriteKeepsSecrets(c *gc.C) {
+	s.writeInfo(c, "someenv", "")
+
+	info, err := s.store.ReadInfo("someenv")
+	c.Assert(err, jc.ErrorIsNil)
+	info.SetAPIEndpoint(configstore.APIEndpoint{Addresses: []string{"address2"}})
+	err = info.Write()
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.checkInfo(c, "someenv")
+}
+
+func (s *credentialHelperSuite) TestStoreFailsFallsBackToFile(c *gc.C) {
+	s.helper.storeErr = errors.New("keyring locked")
+	s.writeInfo(c, "someenv", "")
+
+	data, err := ioutil.ReadFile(configstore.JENVFilename(filepath.Join(s.dir, "environments"), "someenv"))
+	c.Assert(err, jc.ErrorIsNil)
+	var values configstore.EnvironInfoData
+	err = goyaml.Unmarshal(data, &values)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(values.Password, gc.Equals, "hunter2")
+	c.Check(values.CredentialHelper, gc.Equals, "")
+	c.Check(values.Config["admin-secret"], gc.Equals, "sekrit")
+	c.Check(values.BootstrapCredentialHelper, gc.Equals, "")
+
+	s.helpers = nil
+	s.checkInfo(c, "someenv")
+	c.Check(s.helpers, gc.HasLen, 0)
+}
+
+func (s *credentialHelperSuite) TestNoHelper(c *gc.C) {
+	s.PatchEnvironment(osenv.JujuCredentialHelperEnvKey, "")
+	s.writeInfo(c, "someenv", "")
+	c.Check(s.helpers, gc.HasLen, 0)
+	s.checkInfo(c, "someenv")
+}
+
+func (s *credentialHelperSuite) TestExistingInfoKeepsSecrets(c *gc.C) {
+	s.writeInfo(c, "someenv", "")
+
+	info := s.store.CreateInfo("someenv")
+	info.SetAPICredentials(configstore.APICredentials{
+		User:     "admin",
+		Password: "clobbered",
+	})
+	err := info.Write()
+	c.Assert(err, gc.Equals, configstore.ErrEnvironInfoAlreadyExists)
+	c.Check(s.helper.secrets["juju:env:someenv:password"], gc.Equals, "hunter2")
+}
+
+var _ = gc.Suite(&execCredentialHelperSuite{})
+
+type execCredentialHelperSuite struct {
+	testing.BaseSuite
+}
+
+// fakeHelperScript implements the credential helper protocol, keeping
+// a single secret in a file next to the script.
+const fakeHelperScript = `#!/bin/sh
+dir=$(dirname "$0")
+input=$(cat)
+case "$1" in
+store)
+	echo "$input" > "$dir/secret"
+	;;
+get)
+	if [ ! -f "$dir/secret" ]; then
+		echo "credentials not found"
+		exit 1
+	fi
+	cat "$dir/secret"
+	;;
+erase)
+	if [ ! -f "$dir/secret" ]; then
+		echo "credentials not found"
+		exit 1
+	fi
+	rm "$dir/secret"
+	;;
+*)
+	echo "unknown action $1" >&2
+	exit 1
+	;;
+esac
+`
+
+func (s *execCredentialHelperSuite) TestProtocol(c *gc.C) {
+	if runtime.GOOS == "windows" {
+		c.Skip("credential helper script is a shell script")
+	}
+	dir := c.MkDir()
+	err := ioutil.WriteFile(filepath.Join(dir, "juju-credential-fake"), []byte(fakeHelperScript), 0755)
+	c.Assert(err, jc.ErrorIsNil)
+	s.PatchEnvironment("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	helper := (*configstore.NewCredentialHelper)("fake")
+	_, err = helper.Get("some-key")
+	c.Check(err, jc.Satisfies, errors.IsNotFound)
+
+	err = helper.Store("some-key", "sekrit")
+	c.Assert(err, jc.ErrorIsNil)
+	secret, err := helper.Get("some-key")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(secret, gc.Equals, "sekrit")
+
+	err = helper.Erase("some-key")
+	c.Assert(err, jc.ErrorIsNil)
+	err = helper.Erase("some-key")
+	c.Check(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *execCredentialHelperSuite) TestMissingHelper(c *gc.C) {
+	s.PatchEnvironment("PATH", c.MkDir())
+	helper := (*configstore.NewCredentialHelper)("nonexistent")
+	err := helper.Store("some-key", "sekrit")
+	c.Check(err, gc.ErrorMatches, `cannot find credential helper "nonexistent": .*`)
+}
@@ -62,6 +62,13 @@ type EnvironInfoData struct {
 	ServerHostnames []string               `json:"server-hostnames,omitempty" yaml:"server-hostnames,omitempty"`
 	CACert          string                 `json:"ca-cert" yaml:"ca-cert"`
 	Config          map[string]interface{} `json:"bootstrap-config,omitempty" yaml:"bootstrap-config,omitempty"`
+
+	// CredentialHelper names the credential helper holding the
+	// password, if it is not held in Password.
+	CredentialHelper string `json:"credential-helper,omitempty" yaml:"credential-helper,omitempty"`
+	// BootstrapCredentialHelper names the credential helper holding
+	// the secret attributes of Config, if they are not held there.
+	BootstrapCredentialHelper string `json:"bootstrap-credential-helper,omitempty" yaml:"bootstrap-credential-helper,omitempty"`
 }
 
 type environInfo struct {
@@ -85,6 +92,15 @@ type environInfo struct {
 	apiHostnames    []string
 	caCert          string
 	bootstrapConfig map[string]interface{}
+
+	// passwordHelper and bootstrapHelper name the credential helpers
+	// holding the password and the secret bootstrap attributes, if
+	// they are not held in the file. The pending flags record whether
+	// the secrets are yet to be fetched from the helpers.
+	passwordHelper   string
+	bootstrapHelper  string
+	passwordPending  bool
+	bootstrapPending bool
 }
 
 // NewDisk returns a ConfigStorage implementation that stores configuration in
@@ -240,6 +256,9 @@ func (info *environInfo) initialized() bool {
 func (info *environInfo) BootstrapConfig() map[string]interface{} {
 	info.mu.Lock()
 	defer info.mu.Unlock()
+	if err := info.loadBootstrapSecrets(); err != nil {
+		logger.Errorf("%v", err)
+	}
 	return info.bootstrapConfig
 }
 
@@ -247,6 +266,9 @@ func (info *environInfo) BootstrapConfig() map[string]interface{} {
 func (info *environInfo) APICredentials() APICredentials {
 	info.mu.Lock()
 	defer info.mu.Unlock()
+	if err := info.loadPassword(); err != nil {
+		logger.Errorf("%v", err)
+	}
 	return APICredentials{
 		User:     info.user,
 		Password: info.credentials,
@@ -293,6 +315,7 @@ func (info *environInfo) SetAPICredentials(creds APICredentials) {
 	defer info.mu.Unlock()
 	info.user = creds.User
 	info.credentials = creds.Password
+	info.passwordPending = false
 }
 
 // Location returns the location of the environInfo in human readable format.
@@ -312,6 +335,12 @@ func (info *environInfo) Write() error {
 	}
 	defer unlockEnvironmentLock(lock)
 
+	// Any secrets held by a credential helper must be fetched before
+	// they are written anew.
+	if err := info.loadSecrets(); err != nil {
+		return errors.Annotatef(err, "cannot write info")
+	}
+
 	// In order to write out the environment info to the cache
 	// file we need to make sure the server UUID is set. Sufficiently
 	// up to date servers will write the server UUID to the JENV
@@ -332,6 +361,11 @@ func (info *environInfo) Write() error {
 		if err != nil {
 			return errors.Trace(err)
 		}
+		if err := cache.checkNewInfo(info); err != nil {
+			return errors.Trace(err)
+		}
+		jenvPasswordHelper, jenvBootstrapHelper := info.passwordHelper, info.bootstrapHelper
+		info.storeSecrets(true)
 		if err := cache.updateInfo(info); err != nil {
 			return errors.Trace(err)
 		}
@@ -340,12 +374,14 @@ func (info *environInfo) Write() error {
 		}
 		oldPath := info.path
 		info.path = filename
-		// If source was jenv file, delete the jenv.
+		// If source was jenv file, delete the jenv and the secrets
+		// stored for it, which are now stored for the server.
 		if info.source == sourceJenv {
 			err := os.Remove(oldPath)
 			if err != nil {
 				return errors.Trace(err)
 			}
+			info.eraseJENVSecrets(jenvPasswordHelper, jenvBootstrapHelper)
 		}
 		info.source = sourceCache
 	} else {
@@ -374,7 +410,11 @@ func (info *environInfo) Destroy() error {
 			if os.IsNotExist(err) {
 				return errors.New("environment info has already been removed")
 			}
-			return err
+			if err != nil {
+				return err
+			}
+			info.eraseJENVSecrets(info.passwordHelper, info.bootstrapHelper)
+			return nil
 		}
 		if info.source == sourceCache {
 			filename := cacheFilename(info.environmentDir)
@@ -382,12 +422,24 @@ func (info *environInfo) Destroy() error {
 			if err != nil {
 				return errors.Trace(err)
 			}
+			serverData := make(map[string]ServerData)
+			for serverUUID, data := range cache.ServerData {
+				serverData[serverUUID] = data
+			}
 			if err := cache.removeInfo(info); err != nil {
 				return errors.Trace(err)
 			}
 			if err := writeCacheFile(filename, cache); err != nil {
 				return errors.Trace(err)
 			}
+			// Secrets held for a server are shared by all its
+			// environments, so they are only erased along with
+			// the server data.
+			for serverUUID, data := range serverData {
+				if _, ok := cache.ServerData[serverUUID]; !ok {
+					eraseServerSecrets(serverUUID, data)
+				}
+			}
 			return nil
 		}
 		return errors.Errorf("unknown source %q for environment info", info.source)
@@ -428,6 +480,10 @@ func (d *diskStore) readJENVFile(envName string) (*environInfo, error) {
 	info.apiEndpoints = values.StateServers
 	info.apiHostnames = values.ServerHostnames
 	info.bootstrapConfig = values.Config
+	info.passwordHelper = values.CredentialHelper
+	info.passwordPending = values.CredentialHelper != ""
+	info.bootstrapHelper = values.BootstrapCredentialHelper
+	info.bootstrapPending = values.BootstrapCredentialHelper != "" && values.Config != nil
 
 	info.source = sourceJenv
 	return &info, nil
@@ -447,25 +503,8 @@ func (info *environInfo) ensureNoJENV() error {
 
 // Kept primarily for testing purposes now.
 func (info *environInfo) writeJENVFile() error {
-
-	infoData := EnvironInfoData{
-		User:            info.user,
-		Password:        info.credentials,
-		EnvironUUID:     info.environmentUUID,
-		ServerUUID:      info.serverUUID,
-		StateServers:    info.apiEndpoints,
-		ServerHostnames: info.apiHostnames,
-		CACert:          info.caCert,
-		Config:          info.bootstrapConfig,
-	}
-
-	data, err := goyaml.Marshal(infoData)
-	if err != nil {
-		return errors.Annotate(err, "cannot marshal environment info")
-	}
 	// We now use a fslock to sync reads and writes across the environment,
 	// so we don't need to use a temporary file any more.
-
 	flags := os.O_WRONLY
 	if info.initialized() {
 		flags |= os.O_TRUNC
@@ -477,10 +516,34 @@ func (info *environInfo) writeJENVFile() error {
 	file, err := os.OpenFile(path, flags, 0600)
 	if os.IsExist(err) {
 		return ErrEnvironInfoAlreadyExists
+	} else if err != nil {
+		return errors.Annotate(err, "cannot write file")
+	}
+	defer file.Close()
+
+	// Secrets are only stored once we know we are not clobbering
+	// those of some other environment.
+	info.storeSecrets(false)
+
+	infoData := EnvironInfoData{
+		User:            info.user,
+		Password:        info.filePassword(),
+		EnvironUUID:     info.environmentUUID,
+		ServerUUID:      info.serverUUID,
+		StateServers:    info.apiEndpoints,
+		ServerHostnames: info.apiHostnames,
+		CACert:          info.caCert,
+		Config:          info.fileBootstrapConfig(),
+
+		CredentialHelper:          info.passwordHelper,
+		BootstrapCredentialHelper: info.bootstrapHelper,
 	}
 
+	data, err := goyaml.Marshal(infoData)
+	if err != nil {
+		return errors.Annotate(err, "cannot marshal environment info")
+	}
 	_, err = file.Write(data)
-	file.Close()
 	info.path = path
 	return errors.Annotate(err, "cannot write file")
 }
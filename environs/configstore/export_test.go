@@ -8,4 +8,5 @@ var (
 	JENVFilename           = jenvFilename
 	ReadCacheFile          = readCacheFile
 	AcquireEnvironmentLock = acquireEnvironmentLock
+	NewCredentialHelper    = &newCredentialHelper
 )
@@ -0,0 +1,463 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package offline creates and reads offline bundles: archives holding
+// everything needed to bootstrap an environment without access to the
+// Internet, namely tools tarballs with their simplestreams metadata,
+// image metadata, and optionally charms and bundles to upload to the
+// new environment.
+package offline
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"github.com/juju/utils"
+	"github.com/juju/utils/tar"
+	"gopkg.in/juju/charm.v6-unstable"
+	goyaml "gopkg.in/yaml.v2"
+
+	"github.com/juju/juju/environs/filestorage"
+	"github.com/juju/juju/environs/simplestreams"
+	"github.com/juju/juju/environs/storage"
+	"github.com/juju/juju/environs/sync"
+	envtools "github.com/juju/juju/environs/tools"
+	"github.com/juju/juju/version"
+)
+
+var logger = loggo.GetLogger("juju.environs.offline")
+
+const (
+	// ManifestFile is the name of the file describing the contents
+	// of an offline bundle.
+	ManifestFile = "manifest.yaml"
+
+	// Format is the format of offline bundles written by Create.
+	Format = 1
+
+	charmsDir  = "charms"
+	bundlesDir = "bundles"
+)
+
+// Manifest describes the contents of an offline bundle.
+type Manifest struct {
+	// Format holds the format of the bundle.
+	Format int `yaml:"format"`
+
+	// Created holds when the bundle was created, in RFC3339 format.
+	Created string `yaml:"created"`
+
+	// Stream holds the simplestreams stream of the tools.
+	Stream string `yaml:"stream"`
+
+	// Tools holds the versions of the tools in the bundle.
+	Tools []string `yaml:"tools"`
+
+	// Images reports whether the bundle holds image metadata.
+	Images bool `yaml:"images,omitempty"`
+
+	// Charms holds the local charm URLs of the charms in the bundle.
+	Charms []string `yaml:"charms,omitempty"`
+
+	// Bundles holds the file names of the charm bundles in the bundle.
+	Bundles []string `yaml:"bundles,omitempty"`
+
+	// Signed reports whether the simplestreams metadata in the
+	// bundle is signed.
+	Signed bool `yaml:"signed,omitempty"`
+}
+
+// CreateParams holds the parameters for creating an offline bundle.
+type CreateParams struct {
+	// MajorVersion and MinorVersion select the tools to include. If
+	// both are zero, the tools matching the current version are
+	// included. MinorVersion -1 includes all minor versions.
+	MajorVersion int
+	MinorVersion int
+
+	// AllVersions includes all matching tools, not just the newest.
+	AllVersions bool
+
+	// Stream holds the simplestreams stream of the tools to include;
+	// it defaults to "released".
+	Stream string
+
+	// ToolsSource, if non-empty, holds a local directory or URL to
+	// read tools from instead of the official tools store.
+	ToolsSource string
+
+	// ImageMetadataDir, if non-empty, holds a local directory whose
+	// "images" directory holds image metadata to include, as
+	// written by "juju metadata generate-image".
+	ImageMetadataDir string
+
+	// Charms holds the paths of local charm directories or archives
+	// to include.
+	Charms []string
+
+	// Bundles holds the paths of charm bundle files to include.
+	Bundles []string
+
+	// SigningKey, if non-empty, holds an armored private key used
+	// to sign the simplestreams metadata, decrypted with Passphrase.
+	SigningKey string
+	Passphrase string
+}
+
+var syncTools = sync.SyncTools
+
+// Create writes a gzipped tar archive of an offline bundle built
+// according to the given parameters to w, and returns its manifest.
+func Create(w io.Writer, args CreateParams) (*Manifest, error) {
+	dir, err := ioutil.TempDir("", "juju-offline-bundle")
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer os.RemoveAll(dir)
+
+	manifest := &Manifest{
+		Format:  Format,
+		Created: time.Now().UTC().Format(time.RFC3339),
+		Stream:  args.Stream,
+	}
+	if manifest.Stream == "" {
+		manifest.Stream = envtools.ReleasedStream
+	}
+	if err := addTools(dir, manifest, args); err != nil {
+		return nil, errors.Annotate(err, "cannot add tools")
+	}
+	if args.ImageMetadataDir != "" {
+		if err := copyDir(filepath.Join(args.ImageMetadataDir, storage.BaseImagesPath), filepath.Join(dir, storage.BaseImagesPath)); err != nil {
+			return nil, errors.Annotate(err, "cannot add image metadata")
+		}
+		manifest.Images = true
+	}
+	for _, path := range args.Charms {
+		if err := addCharm(dir, manifest, path); err != nil {
+			return nil, errors.Annotatef(err, "cannot add charm %q", path)
+		}
+	}
+	for _, path := range args.Bundles {
+		if err := addBundle(dir, manifest, path); err != nil {
+			return nil, errors.Annotatef(err, "cannot add bundle %q", path)
+		}
+	}
+	if args.SigningKey != "" {
+		if err := signMetadata(dir, args.SigningKey, args.Passphrase); err != nil {
+			return nil, errors.Annotate(err, "cannot sign metadata")
+		}
+		manifest.Signed = true
+	}
+	data, err := goyaml.Marshal(manifest)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, ManifestFile), data, 0644); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if err := writeArchive(w, dir); err != nil {
+		return nil, errors.Annotate(err, "cannot write offline bundle")
+	}
+	return manifest, nil
+}
+
+// addTools copies the tools selected by args, and their metadata,
+// into the bundle directory.
+func addTools(dir string, manifest *Manifest, args CreateParams) error {
+	stor, err := filestorage.NewFileStorageWriter(dir)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	sctx := &sync.SyncContext{
+		AllVersions:       args.AllVersions,
+		MajorVersion:      args.MajorVersion,
+		MinorVersion:      args.MinorVersion,
+		Stream:            manifest.Stream,
+		Source:            args.ToolsSource,
+		TargetToolsFinder: sync.StorageToolsFinder{Storage: stor},
+		TargetToolsUploader: sync.StorageToolsUploader{
+			Storage:       stor,
+			WriteMetadata: true,
+			WriteMirrors:  envtools.DoNotWriteMirrors,
+		},
+	}
+	if err := syncTools(sctx); err != nil {
+		return errors.Trace(err)
+	}
+	names, err := filepath.Glob(filepath.Join(dir, storage.BaseToolsPath, manifest.Stream, "juju-*.tgz"))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for _, name := range names {
+		vers := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(name), "juju-"), ".tgz")
+		if _, err := version.ParseBinary(vers); err != nil {
+			logger.Warningf("ignoring unexpected tools file %q", name)
+			continue
+		}
+		manifest.Tools = append(manifest.Tools, vers)
+	}
+	if len(manifest.Tools) == 0 {
+		return errors.NotFoundf("%s tools", manifest.Stream)
+	}
+	return nil
+}
+
+// addCharm copies the charm at path into the bundle directory as an
+// archive, in the layout of a local charm repository.
+func addCharm(dir string, manifest *Manifest, path string) error {
+	ch, err := charm.ReadCharm(path)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	series, err := charmSeries(path, ch)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	curl := &charm.URL{
+		Schema:   "local",
+		Name:     ch.Meta().Name,
+		Series:   series,
+		Revision: ch.Revision(),
+	}
+	archivePath := CharmPath(dir, curl)
+	if _, err := os.Stat(archivePath); err == nil {
+		return errors.AlreadyExistsf("charm %s/%s", curl.Series, curl.Name)
+	}
+	if err := os.MkdirAll(filepath.Dir(archivePath), 0755); err != nil {
+		return errors.Trace(err)
+	}
+	switch ch := ch.(type) {
+	case *charm.CharmDir:
+		f, err := os.Create(archivePath)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		defer f.Close()
+		if err := ch.ArchiveTo(f); err != nil {
+			return errors.Annotate(err, "cannot archive charm")
+		}
+	case *charm.CharmArchive:
+		if err := utils.CopyFile(archivePath, ch.Path); err != nil {
+			return errors.Trace(err)
+		}
+	default:
+		return errors.Errorf("unknown charm type %T", ch)
+	}
+	manifest.Charms = append(manifest.Charms, curl.String())
+	return nil
+}
+
+// charmSeries returns the series to add the charm at path for: the
+// first series the charm supports, or else the name of the directory
+// holding it in a local charm repository.
+func charmSeries(path string, ch charm.Charm) (string, error) {
+	if series := ch.Meta().Series; len(series) > 0 {
+		return series[0], nil
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	if series := filepath.Base(filepath.Dir(absPath)); charm.IsValidSeries(series) {
+		return series, nil
+	}
+	return "", errors.New("cannot determine series: charm specifies none and is not in a local repository")
+}
+
+// addBundle copies the charm bundle file at path into the bundle
+// directory, after checking that it is valid.
+func addBundle(dir string, manifest *Manifest, path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if _, err := charm.ReadBundleData(bytes.NewReader(data)); err != nil {
+		return errors.Trace(err)
+	}
+	name := filepath.Base(path)
+	for _, existing := range manifest.Bundles {
+		if existing == name {
+			return errors.AlreadyExistsf("bundle %q", name)
+		}
+	}
+	bundlePath := BundlePath(dir, name)
+	if err := os.MkdirAll(filepath.Dir(bundlePath), 0755); err != nil {
+		return errors.Trace(err)
+	}
+	if err := ioutil.WriteFile(bundlePath, data, 0644); err != nil {
+		return errors.Trace(err)
+	}
+	manifest.Bundles = append(manifest.Bundles, name)
+	return nil
+}
+
+// CharmPath returns the path of the archive of the charm with the
+// given URL in an offline bundle extracted to dir.
+func CharmPath(dir string, curl *charm.URL) string {
+	return filepath.Join(dir, charmsDir, curl.Series, curl.Name+".charm")
+}
+
+// CharmRepositoryPath returns the path of the local charm repository
+// holding the charms of an offline bundle extracted to dir.
+func CharmRepositoryPath(dir string) string {
+	return filepath.Join(dir, charmsDir)
+}
+
+// BundlePath returns the path of the charm bundle file with the given
+// name in an offline bundle extracted to dir.
+func BundlePath(dir, name string) string {
+	return filepath.Join(dir, bundlesDir, name)
+}
+
+// signMetadata writes an inline signed .sjson file for each .json
+// file under the simplestreams metadata directories of dir.
+func signMetadata(dir, key, passphrase string) error {
+	return walkMetadata(dir, func(path string) error {
+		f, err := os.Open(path)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		defer f.Close()
+		encoded, err := simplestreams.Encode(f, key, passphrase)
+		if err != nil {
+			return errors.Annotatef(err, "cannot sign %q", path)
+		}
+		signedPath := strings.TrimSuffix(path, simplestreams.UnsignedSuffix) + simplestreams.SignedSuffix
+		return errors.Trace(ioutil.WriteFile(signedPath, encoded, 0644))
+	})
+}
+
+// VerifySignatures checks that the simplestreams metadata in an
+// offline bundle extracted to dir is signed with the private key
+// corresponding to the given armored public key, and that the signed
+// metadata matches the unsigned metadata.
+func VerifySignatures(dir, publicKey string) error {
+	return walkMetadata(dir, func(path string) error {
+		signedPath := strings.TrimSuffix(path, simplestreams.UnsignedSuffix) + simplestreams.SignedSuffix
+		f, err := os.Open(signedPath)
+		if err != nil {
+			return errors.Annotatef(err, "cannot read signed metadata for %q", path)
+		}
+		defer f.Close()
+		signed, err := simplestreams.DecodeCheckSignature(f, publicKey)
+		if err != nil {
+			return errors.Annotatef(err, "cannot verify %q", signedPath)
+		}
+		unsigned, err := ioutil.ReadFile(path)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if !bytes.Equal(bytes.TrimSpace(signed), bytes.TrimSpace(unsigned)) {
+			return errors.Errorf("%q does not match its signed metadata", path)
+		}
+		return nil
+	})
+}
+
+// walkMetadata calls f with the path of each unsigned simplestreams
+// metadata file in the tools and images directories of dir.
+func walkMetadata(dir string, f func(path string) error) error {
+	for _, base := range []string{storage.BaseToolsPath, storage.BaseImagesPath} {
+		root := filepath.Join(dir, base)
+		if _, err := os.Stat(root); os.IsNotExist(err) {
+			continue
+		}
+		err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() || !strings.HasSuffix(path, simplestreams.UnsignedSuffix) {
+				return nil
+			}
+			return f(path)
+		})
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// copyDir copies the directory tree at src to dst.
+func copyDir(src, dst string) error {
+	if _, err := os.Stat(src); err != nil {
+		return errors.Trace(err)
+	}
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if info.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		return utils.CopyFile(target, path)
+	})
+}
+
+// writeArchive writes a gzipped tar archive of the contents of dir
+// to w.
+func writeArchive(w io.Writer, dir string) error {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	var filenames []string
+	for _, entry := range entries {
+		filenames = append(filenames, filepath.Join(dir, entry.Name()))
+	}
+	gzw := gzip.NewWriter(w)
+	// We add a trailing slash to dir so that it is stripped off
+	// when each file is added to the tar file.
+	if _, err := tar.TarFiles(filenames, gzw, dir+string(os.PathSeparator)); err != nil {
+		gzw.Close()
+		return errors.Trace(err)
+	}
+	return errors.Trace(gzw.Close())
+}
+
+// Extract extracts the offline bundle read from r into dir, and
+// returns its manifest.
+func Extract(r io.Reader, dir string) (*Manifest, error) {
+	gzr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot uncompress offline bundle")
+	}
+	defer gzr.Close()
+	if err := tar.UntarFiles(gzr, dir); err != nil {
+		return nil, errors.Annotate(err, "cannot extract offline bundle")
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dir, ManifestFile))
+	if os.IsNotExist(err) {
+		return nil, errors.NotValidf("offline bundle without %s", ManifestFile)
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var manifest Manifest
+	if err := goyaml.Unmarshal(data, &manifest); err != nil {
+		return nil, errors.Annotate(err, "cannot read offline bundle manifest")
+	}
+	if manifest.Format != Format {
+		return nil, errors.NotSupportedf("offline bundle format %d", manifest.Format)
+	}
+	for _, name := range manifest.Bundles {
+		// Bundle names are used as file names outside the
+		// extracted bundle, so must not name other directories.
+		if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+			return nil, errors.NotValidf("bundle name %q", name)
+		}
+	}
+	return &manifest, nil
+}
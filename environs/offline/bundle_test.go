@@ -0,0 +1,187 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package offline_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	stdtesting "testing"
+
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/utils/tar"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/charm.v6-unstable"
+
+	"github.com/juju/juju/environs/filestorage"
+	"github.com/juju/juju/environs/imagemetadata"
+	"github.com/juju/juju/environs/offline"
+	"github.com/juju/juju/environs/simplestreams"
+	sstesting "github.com/juju/juju/environs/simplestreams/testing"
+	toolstesting "github.com/juju/juju/environs/tools/testing"
+	"github.com/juju/juju/testcharms"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/version"
+)
+
+func TestPackage(t *stdtesting.T) {
+	gc.TestingT(t)
+}
+
+type bundleSuite struct {
+	coretesting.FakeJujuHomeSuite
+	toolsSource string
+}
+
+var _ = gc.Suite(&bundleSuite{})
+
+func (s *bundleSuite) SetUpTest(c *gc.C) {
+	s.FakeJujuHomeSuite.SetUpTest(c)
+	s.PatchValue(&version.Current, version.MustParse("1.2.0"))
+	s.toolsSource = c.MkDir()
+	toolstesting.MakeTools(c, s.toolsSource, "released", []string{
+		"1.1.0-quantal-amd64",
+		"1.2.0-quantal-amd64",
+		"1.2.0-trusty-amd64",
+	})
+}
+
+func (s *bundleSuite) create(c *gc.C, args offline.CreateParams) (*offline.Manifest, string) {
+	args.ToolsSource = s.toolsSource
+	var buf bytes.Buffer
+	manifest, err := offline.Create(&buf, args)
+	c.Assert(err, jc.ErrorIsNil)
+	dir := c.MkDir()
+	extracted, err := offline.Extract(&buf, dir)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(extracted, jc.DeepEquals, manifest)
+	return manifest, dir
+}
+
+func (s *bundleSuite) TestCreateTools(c *gc.C) {
+	manifest, dir := s.create(c, offline.CreateParams{})
+	c.Assert(manifest.Format, gc.Equals, offline.Format)
+	c.Assert(manifest.Stream, gc.Equals, "released")
+	c.Assert(manifest.Tools, jc.SameContents, []string{"1.2.0-quantal-amd64", "1.2.0-trusty-amd64"})
+	c.Assert(manifest.Images, jc.IsFalse)
+	c.Assert(manifest.Signed, jc.IsFalse)
+
+	for _, name := range []string{
+		"tools/released/juju-1.2.0-quantal-amd64.tgz",
+		"tools/released/juju-1.2.0-trusty-amd64.tgz",
+		"tools/streams/v1/index2.json",
+	} {
+		_, err := os.Stat(filepath.Join(dir, name))
+		c.Check(err, jc.ErrorIsNil)
+	}
+}
+
+func (s *bundleSuite) TestCreateAllVersions(c *gc.C) {
+	manifest, _ := s.create(c, offline.CreateParams{AllVersions: true})
+	c.Assert(manifest.Tools, jc.SameContents, []string{
+		"1.1.0-quantal-amd64", "1.2.0-quantal-amd64", "1.2.0-trusty-amd64",
+	})
+}
+
+func (s *bundleSuite) TestCreateNoTools(c *gc.C) {
+	var buf bytes.Buffer
+	_, err := offline.Create(&buf, offline.CreateParams{
+		ToolsSource:  s.toolsSource,
+		MajorVersion: 3,
+		MinorVersion: 0,
+	})
+	c.Assert(err, gc.ErrorMatches, "cannot add tools: .*")
+}
+
+func (s *bundleSuite) TestCreateImagesCharmsAndBundles(c *gc.C) {
+	imageDir := c.MkDir()
+	stor, err := filestorage.NewFileStorageWriter(imageDir)
+	c.Assert(err, jc.ErrorIsNil)
+	err = imagemetadata.MergeAndWriteMetadata("trusty", []*imagemetadata.ImageMetadata{{
+		Id:         "1234",
+		Arch:       "amd64",
+		Version:    "14.04",
+		RegionName: "region",
+		Endpoint:   "endpoint",
+	}}, &simplestreams.CloudSpec{Region: "region", Endpoint: "endpoint"}, stor)
+	c.Assert(err, jc.ErrorIsNil)
+
+	bundlePath := filepath.Join(testcharms.Repo.Path(), "bundle", "wordpress-simple", "bundle.yaml")
+	manifest, dir := s.create(c, offline.CreateParams{
+		ImageMetadataDir: imageDir,
+		Charms: []string{
+			testcharms.Repo.CharmDirPath("dummy"),
+			testcharms.Repo.CharmDirPath("mysql"),
+		},
+		Bundles: []string{bundlePath},
+	})
+	c.Assert(manifest.Images, jc.IsTrue)
+	c.Assert(manifest.Bundles, gc.DeepEquals, []string{"bundle.yaml"})
+	c.Assert(manifest.Charms, gc.HasLen, 2)
+	c.Assert(manifest.Charms[0], gc.Equals, "local:quantal/dummy-1")
+
+	_, err = os.Stat(filepath.Join(dir, "images", "streams", "v1", "index.json"))
+	c.Assert(err, jc.ErrorIsNil)
+	for _, s := range manifest.Charms {
+		curl := charm.MustParseURL(s)
+		ch, err := charm.ReadCharmArchive(offline.CharmPath(dir, curl))
+		c.Assert(err, jc.ErrorIsNil)
+		c.Assert(ch.Meta().Name, gc.Equals, curl.Name)
+	}
+	_, err = os.Stat(offline.BundlePath(dir, "bundle.yaml"))
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *bundleSuite) TestCreateCharmWithoutSeries(c *gc.C) {
+	path := testcharms.Repo.ClonedDirPath(c.MkDir(), "dummy")
+	var buf bytes.Buffer
+	_, err := offline.Create(&buf, offline.CreateParams{
+		ToolsSource: s.toolsSource,
+		Charms:      []string{path},
+	})
+	c.Assert(err, gc.ErrorMatches, `cannot add charm ".*": cannot determine series: .*`)
+}
+
+func (s *bundleSuite) TestCreateSigned(c *gc.C) {
+	manifest, dir := s.create(c, offline.CreateParams{
+		SigningKey: sstesting.SignedMetadataPrivateKey,
+		Passphrase: sstesting.PrivateKeyPassphrase,
+	})
+	c.Assert(manifest.Signed, jc.IsTrue)
+	_, err := os.Stat(filepath.Join(dir, "tools", "streams", "v1", "index2.sjson"))
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = offline.VerifySignatures(dir, sstesting.SignedMetadataPublicKey)
+	c.Assert(err, jc.ErrorIsNil)
+	err = offline.VerifySignatures(dir, simplestreams.SimplestreamsJujuPublicKey)
+	c.Assert(err, gc.ErrorMatches, "cannot verify .*")
+}
+
+func (s *bundleSuite) TestExtractWithoutManifest(c *gc.C) {
+	var buf bytes.Buffer
+	gzw := gzip.NewWriter(&buf)
+	_, err := tar.TarFiles([]string{c.MkDir()}, gzw, "")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(gzw.Close(), jc.ErrorIsNil)
+
+	_, err = offline.Extract(&buf, c.MkDir())
+	c.Assert(err, gc.ErrorMatches, "offline bundle without manifest.yaml not valid")
+}
+
+func (s *bundleSuite) TestExtractInvalidBundleName(c *gc.C) {
+	dir := c.MkDir()
+	manifest := "format: 1\nstream: released\nbundles: [../../evil.yaml]\n"
+	err := ioutil.WriteFile(filepath.Join(dir, offline.ManifestFile), []byte(manifest), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+	var buf bytes.Buffer
+	gzw := gzip.NewWriter(&buf)
+	_, err = tar.TarFiles([]string{filepath.Join(dir, offline.ManifestFile)}, gzw, dir+string(os.PathSeparator))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(gzw.Close(), jc.ErrorIsNil)
+
+	_, err = offline.Extract(&buf, c.MkDir())
+	c.Assert(err, gc.ErrorMatches, `bundle name "../../evil.yaml" not valid`)
+}
@@ -0,0 +1,295 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package sync
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+
+	"github.com/juju/errors"
+	"github.com/juju/utils"
+	"github.com/juju/utils/arch"
+	jujuseries "github.com/juju/utils/series"
+
+	"github.com/juju/juju/environs/simplestreams"
+	"github.com/juju/juju/environs/storage"
+	envtools "github.com/juju/juju/environs/tools"
+	"github.com/juju/juju/version"
+)
+
+// MirrorParams describes how to bring a local mirror of tools up to
+// date with a source.
+type MirrorParams struct {
+	// Source holds the base URL or local directory of the tools
+	// to mirror. It defaults to the official tools store.
+	Source string
+
+	// PublicKey, if non-empty, holds an armored keyring. Only
+	// source metadata signed by one of its keys is then accepted.
+	PublicKey string
+
+	// Target holds the storage of the mirror.
+	Target storage.Storage
+
+	// Stream holds the simplestreams stream to mirror; it defaults
+	// to "released".
+	Stream string
+
+	// MajorVersion and MinorVersion select the tools versions to
+	// mirror. MajorVersion 0 selects the major version of the
+	// current tools, and MinorVersion -1 selects all minor versions.
+	MajorVersion int
+	MinorVersion int
+
+	// AllVersions mirrors all matching versions, not just the newest.
+	AllVersions bool
+
+	// Series and Arches, if non-empty, restrict the tools mirrored
+	// to those series and architectures.
+	Series []string
+	Arches []string
+
+	// Prune removes the tools, and their metadata, that are in the
+	// mirror but no longer selected from the source.
+	Prune bool
+
+	// DryRun logs what would be changed without changing anything.
+	DryRun bool
+
+	// WriteMirrors controls whether mirrors metadata is written.
+	WriteMirrors envtools.ShouldWriteMirrors
+}
+
+// MirrorResult describes the changes made to a mirror by MirrorTools.
+type MirrorResult struct {
+	// Copied holds the versions of the tools copied from the source.
+	Copied []version.Binary
+
+	// Pruned holds the versions of the tools removed from the mirror.
+	Pruned []version.Binary
+
+	// Unchanged holds the versions of the tools that were already
+	// up to date in the mirror.
+	Unchanged []version.Binary
+}
+
+// MirrorTools brings a mirror of the tools in a simplestreams source up
+// to date: it copies the selected tools that are missing or different
+// in the mirror, optionally removes those no longer selected, and
+// writes consistent index and products metadata for the result.
+func MirrorTools(args MirrorParams) (*MirrorResult, error) {
+	if args.Target == nil {
+		return nil, errors.NotValidf("mirror without target storage")
+	}
+	stream := args.Stream
+	if stream == "" {
+		stream = envtools.ReleasedStream
+	}
+	wanted, err := fetchMirrorSourceMetadata(args, stream)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	existing, err := envtools.ReadAllMetadata(args.Target)
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot read mirror metadata")
+	}
+	existingByVersion := make(map[version.Binary]*envtools.ToolsMetadata)
+	for _, md := range existing[stream] {
+		vers, err := metadataBinary(md)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		existingByVersion[vers] = md
+	}
+
+	result := &MirrorResult{}
+	var metadata []*envtools.ToolsMetadata
+	for _, md := range wanted {
+		vers, err := metadataBinary(md)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if old, ok := existingByVersion[vers]; ok {
+			delete(existingByVersion, vers)
+			if old.SHA256 == md.SHA256 && old.Size == md.Size {
+				result.Unchanged = append(result.Unchanged, vers)
+				metadata = append(metadata, old)
+				continue
+			}
+		}
+		storageName := envtools.StorageName(vers, stream)
+		if args.DryRun {
+			logger.Infof("would copy %s from %s", vers, md.FullPath)
+		} else if err := copyMirrorTools(args.Target, storageName, md); err != nil {
+			return nil, errors.Annotatef(err, "cannot copy %s", vers)
+		}
+		result.Copied = append(result.Copied, vers)
+		metadata = append(metadata, &envtools.ToolsMetadata{
+			Release:  md.Release,
+			Version:  md.Version,
+			Arch:     md.Arch,
+			Size:     md.Size,
+			Path:     path.Join(stream, path.Base(storageName)),
+			FileType: md.FileType,
+			SHA256:   md.SHA256,
+		})
+	}
+
+	// Whatever remains in the mirror was not selected this time.
+	var pruned []*envtools.ToolsMetadata
+	for _, md := range existing[stream] {
+		vers, _ := metadataBinary(md)
+		if _, ok := existingByVersion[vers]; !ok {
+			continue
+		}
+		if !args.Prune {
+			metadata = append(metadata, md)
+			continue
+		}
+		if args.DryRun {
+			logger.Infof("would remove %s", vers)
+		}
+		pruned = append(pruned, md)
+		result.Pruned = append(result.Pruned, vers)
+	}
+
+	if args.DryRun || (len(result.Copied) == 0 && len(result.Pruned) == 0) {
+		return result, nil
+	}
+	envtools.Sort(metadata)
+	existing[stream] = metadata
+	if err := envtools.WriteMetadata(args.Target, existing, []string{stream}, args.WriteMirrors); err != nil {
+		return nil, errors.Annotate(err, "cannot write mirror metadata")
+	}
+
+	// The pruned tools are only removed once the metadata no longer
+	// refers to them, so that the mirror stays usable if this fails.
+	for _, md := range pruned {
+		if err := args.Target.Remove(path.Join(storage.BaseToolsPath, md.Path)); err != nil {
+			vers, _ := metadataBinary(md)
+			return nil, errors.Annotatef(err, "cannot remove %s", vers)
+		}
+	}
+	return result, nil
+}
+
+// fetchMirrorSourceMetadata returns the metadata of the tools selected
+// by args in the given stream of the source.
+func fetchMirrorSourceMetadata(args MirrorParams, stream string) ([]*envtools.ToolsMetadata, error) {
+	source := args.Source
+	if source == "" {
+		source = envtools.DefaultBaseURL
+	}
+	sourceURL, err := envtools.ToolsURL(source)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	logger.Infof("using mirror source: %v", sourceURL)
+	var dataSource simplestreams.DataSource
+	onlySigned := args.PublicKey != ""
+	if onlySigned {
+		dataSource = simplestreams.NewURLSignedDataSource("mirror source", sourceURL, args.PublicKey, utils.VerifySSLHostnames)
+	} else {
+		dataSource = simplestreams.NewURLDataSource("mirror source", sourceURL, utils.VerifySSLHostnames)
+	}
+
+	majorVersion, minorVersion := args.MajorVersion, args.MinorVersion
+	if majorVersion == 0 {
+		majorVersion = version.Current.Major
+		minorVersion = -1
+	}
+	cons := envtools.NewGeneralToolsConstraint(majorVersion, minorVersion, simplestreams.LookupParams{
+		Stream: stream,
+		Series: args.Series,
+		Arches: args.Arches,
+	})
+	if len(cons.Series) == 0 {
+		cons.Series = jujuseries.SupportedSeries()
+	}
+	if len(cons.Arches) == 0 {
+		cons.Arches = arch.AllSupportedArches
+	}
+	metadata, _, err := envtools.Fetch([]simplestreams.DataSource{dataSource}, cons, onlySigned)
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot read source metadata")
+	}
+	if len(metadata) == 0 {
+		return nil, errors.NotFoundf("tools matching the mirror filters")
+	}
+	if args.AllVersions {
+		return metadata, nil
+	}
+
+	var newest version.Number
+	for _, md := range metadata {
+		num, err := version.Parse(md.Version)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if num.Compare(newest) > 0 {
+			newest = num
+		}
+	}
+	var result []*envtools.ToolsMetadata
+	for _, md := range metadata {
+		if md.Version == newest.String() {
+			result = append(result, md)
+		}
+	}
+	return result, nil
+}
+
+// copyMirrorTools downloads the tools described by md, checks them
+// against their metadata, and stores them in stor with the given name.
+// The tools are downloaded to a temporary file, so that they are never
+// held in memory whole, nor stored before they have been checked.
+func copyMirrorTools(stor storage.Storage, name string, md *envtools.ToolsMetadata) error {
+	logger.Infof("downloading %v", md.FullPath)
+	resp, err := utils.GetValidatingHTTPClient().Get(md.FullPath)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("cannot download %q: %s", md.FullPath, resp.Status)
+	}
+	f, err := ioutil.TempFile("", "juju-mirror-tools")
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer f.Close()
+	defer os.Remove(f.Name())
+	// Reading one byte more than expected is enough to detect a
+	// size mismatch, without downloading an arbitrary amount.
+	body := io.LimitReader(resp.Body, md.Size+1)
+	sha256, size, err := utils.ReadSHA256(io.TeeReader(body, f))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if sha256 != md.SHA256 {
+		return errors.Errorf("SHA-256 hash mismatch (%v/%v)", sha256, md.SHA256)
+	}
+	if size != md.Size {
+		return errors.Errorf("size mismatch (%v/%v)", size, md.Size)
+	}
+	if _, err := f.Seek(0, 0); err != nil {
+		return errors.Trace(err)
+	}
+	logger.Infof("writing %v (%dkB)", name, (size+512)/1024)
+	return errors.Trace(stor.Put(name, f, size))
+}
+
+// metadataBinary returns the binary version of the tools described by
+// md.
+func metadataBinary(md *envtools.ToolsMetadata) (version.Binary, error) {
+	num, err := version.Parse(md.Version)
+	if err != nil {
+		return version.Binary{}, errors.Trace(err)
+	}
+	return version.Binary{Number: num, Series: md.Release, Arch: md.Arch}, nil
+}
@@ -0,0 +1,207 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package sync_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/environs/filestorage"
+	"github.com/juju/juju/environs/simplestreams"
+	sstesting "github.com/juju/juju/environs/simplestreams/testing"
+	"github.com/juju/juju/environs/storage"
+	"github.com/juju/juju/environs/sync"
+	envtools "github.com/juju/juju/environs/tools"
+	toolstesting "github.com/juju/juju/environs/tools/testing"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/version"
+)
+
+type mirrorSuite struct {
+	coretesting.FakeJujuHomeSuite
+	source    string
+	targetDir string
+	target    storage.Storage
+}
+
+var _ = gc.Suite(&mirrorSuite{})
+
+func (s *mirrorSuite) SetUpTest(c *gc.C) {
+	s.FakeJujuHomeSuite.SetUpTest(c)
+	s.PatchValue(&version.Current, version.MustParse("1.8.3"))
+
+	s.source = c.MkDir()
+	versionStrings := make([]string, len(v1all))
+	for i, vers := range v1all {
+		versionStrings[i] = vers.String()
+	}
+	toolstesting.MakeTools(c, s.source, "released", versionStrings)
+
+	s.targetDir = c.MkDir()
+	stor, err := filestorage.NewFileStorageWriter(s.targetDir)
+	c.Assert(err, jc.ErrorIsNil)
+	s.target = stor
+}
+
+func (s *mirrorSuite) mirror(c *gc.C, args sync.MirrorParams) *sync.MirrorResult {
+	args.Source = s.source
+	args.Target = s.target
+	result, err := sync.MirrorTools(args)
+	c.Assert(err, jc.ErrorIsNil)
+	return result
+}
+
+// assertMirrored checks that the mirror holds metadata and tarballs
+// for exactly the given tools.
+func (s *mirrorSuite) assertMirrored(c *gc.C, expected []version.Binary) {
+	metadata, err := envtools.ReadMetadata(s.target, "released")
+	c.Assert(err, jc.ErrorIsNil)
+	var found []version.Binary
+	for _, md := range metadata {
+		found = append(found, version.MustParseBinary(md.Version+"-"+md.Release+"-"+md.Arch))
+		_, err := os.Stat(filepath.Join(s.targetDir, "tools", md.Path))
+		c.Check(err, jc.ErrorIsNil)
+	}
+	c.Assert(found, jc.SameContents, expected)
+
+	tarballs, err := filepath.Glob(filepath.Join(s.targetDir, "tools", "released", "juju-*.tgz"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(tarballs, gc.HasLen, len(expected))
+}
+
+func (s *mirrorSuite) TestMirrorNewest(c *gc.C) {
+	result := s.mirror(c, sync.MirrorParams{})
+	c.Assert(result.Copied, jc.SameContents, v190all)
+	c.Assert(result.Pruned, gc.HasLen, 0)
+	s.assertMirrored(c, v190all)
+}
+
+func (s *mirrorSuite) TestMirrorFilters(c *gc.C) {
+	result := s.mirror(c, sync.MirrorParams{
+		AllVersions: true,
+		Series:      []string{"quantal"},
+		Arches:      []string{"amd64"},
+	})
+	expected := []version.Binary{v100q64, v180q64, v190q64}
+	c.Assert(result.Copied, jc.SameContents, expected)
+	s.assertMirrored(c, expected)
+}
+
+func (s *mirrorSuite) TestMirrorMajorMinor(c *gc.C) {
+	result := s.mirror(c, sync.MirrorParams{MajorVersion: 1, MinorVersion: 8})
+	c.Assert(result.Copied, jc.SameContents, v180all)
+	s.assertMirrored(c, v180all)
+}
+
+func (s *mirrorSuite) TestMirrorNoMatches(c *gc.C) {
+	_, err := sync.MirrorTools(sync.MirrorParams{
+		Source: s.source,
+		Target: s.target,
+		Series: []string{"trusty"},
+	})
+	c.Assert(err, gc.ErrorMatches, "(tools matching the mirror filters not found|cannot read source metadata: .*)")
+}
+
+func (s *mirrorSuite) TestMirrorAgainIsUnchanged(c *gc.C) {
+	s.mirror(c, sync.MirrorParams{AllVersions: true})
+	result := s.mirror(c, sync.MirrorParams{AllVersions: true})
+	c.Assert(result.Copied, gc.HasLen, 0)
+	c.Assert(result.Unchanged, jc.SameContents, v1all)
+	s.assertMirrored(c, v1all)
+}
+
+func (s *mirrorSuite) TestMirrorKeepsUnselectedWithoutPrune(c *gc.C) {
+	s.mirror(c, sync.MirrorParams{AllVersions: true})
+	result := s.mirror(c, sync.MirrorParams{MajorVersion: 1, MinorVersion: 9})
+	c.Assert(result.Pruned, gc.HasLen, 0)
+	c.Assert(result.Unchanged, jc.SameContents, v190all)
+	s.assertMirrored(c, v1all)
+}
+
+func (s *mirrorSuite) TestMirrorPrune(c *gc.C) {
+	s.mirror(c, sync.MirrorParams{AllVersions: true})
+	result := s.mirror(c, sync.MirrorParams{MajorVersion: 1, MinorVersion: 9, Prune: true})
+	c.Assert(result.Copied, gc.HasLen, 0)
+	c.Assert(result.Unchanged, jc.SameContents, v190all)
+	c.Assert(result.Pruned, jc.SameContents, append(append([]version.Binary{}, v100all...), v180all...))
+	s.assertMirrored(c, v190all)
+}
+
+func (s *mirrorSuite) TestMirrorDryRun(c *gc.C) {
+	s.mirror(c, sync.MirrorParams{AllVersions: true})
+	result := s.mirror(c, sync.MirrorParams{
+		AllVersions: true,
+		Series:      []string{"precise"},
+		Prune:       true,
+		DryRun:      true,
+	})
+	c.Assert(result.Pruned, jc.SameContents, []version.Binary{v100q64, v100q32, v180q64, v190q64})
+	s.assertMirrored(c, v1all)
+}
+
+func (s *mirrorSuite) TestMirrorRejectsCorruptTools(c *gc.C) {
+	path := filepath.Join(s.source, "tools", "released", "juju-"+v190q64.String()+".tgz")
+	data, err := ioutil.ReadFile(path)
+	c.Assert(err, jc.ErrorIsNil)
+	data[len(data)-1] ^= 0xff
+	err = ioutil.WriteFile(path, data, 0644)
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = sync.MirrorTools(sync.MirrorParams{
+		Source: s.source,
+		Target: s.target,
+		Series: []string{"quantal"},
+		Arches: []string{"amd64"},
+	})
+	c.Assert(err, gc.ErrorMatches, "cannot copy "+v190q64.String()+": SHA-256 hash mismatch .*")
+	_, err = os.Stat(filepath.Join(s.targetDir, "tools", "released", "juju-"+v190q64.String()+".tgz"))
+	c.Assert(err, jc.Satisfies, os.IsNotExist)
+}
+
+// signSource writes signed copies of the source metadata.
+func (s *mirrorSuite) signSource(c *gc.C) {
+	paths, err := filepath.Glob(filepath.Join(s.source, "tools", "streams", "v1", "*.json"))
+	c.Assert(err, jc.ErrorIsNil)
+	for _, path := range paths {
+		f, err := os.Open(path)
+		c.Assert(err, jc.ErrorIsNil)
+		signed, err := simplestreams.Encode(f, sstesting.SignedMetadataPrivateKey, sstesting.PrivateKeyPassphrase)
+		f.Close()
+		c.Assert(err, jc.ErrorIsNil)
+		signedPath := strings.TrimSuffix(path, simplestreams.UnsignedSuffix) + simplestreams.SignedSuffix
+		err = ioutil.WriteFile(signedPath, signed, 0644)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+}
+
+func (s *mirrorSuite) TestMirrorVerifiesSignatures(c *gc.C) {
+	s.signSource(c)
+	result := s.mirror(c, sync.MirrorParams{PublicKey: sstesting.SignedMetadataPublicKey})
+	c.Assert(result.Copied, jc.SameContents, v190all)
+}
+
+func (s *mirrorSuite) TestMirrorRejectsBadSignatures(c *gc.C) {
+	s.signSource(c)
+	_, err := sync.MirrorTools(sync.MirrorParams{
+		Source:    s.source,
+		Target:    s.target,
+		PublicKey: simplestreams.SimplestreamsJujuPublicKey,
+	})
+	c.Assert(err, gc.ErrorMatches, "cannot read source metadata: .*")
+	s.assertMirrored(c, nil)
+}
+
+func (s *mirrorSuite) TestMirrorRequiresSignedSourceWithKey(c *gc.C) {
+	_, err := sync.MirrorTools(sync.MirrorParams{
+		Source:    s.source,
+		Target:    s.target,
+		PublicKey: sstesting.SignedMetadataPublicKey,
+	})
+	c.Assert(err, gc.ErrorMatches, "cannot read source metadata: .*")
+}
@@ -39,6 +39,9 @@ type DeployServiceParams struct {
 	// TODO(dimitern): Drop this in a follow-up in favor of constraints.
 	Networks []string
 	Storage  map[string]storage.Constraints
+	// EndpointBindings maps the charm's relation endpoints to the
+	// names of the spaces they are bound to.
+	EndpointBindings map[string]string
 }
 
 type ServiceDeployer interface {
@@ -94,6 +97,8 @@ func DeployService(st ServiceDeployer, args DeployServiceParams) (*state.Service
 		Settings:  settings,
 		NumUnits:  args.NumUnits,
 		Placement: args.Placement,
+
+		EndpointBindings: args.EndpointBindings,
 	}
 
 	if !args.Charm.Meta().Subordinate {
@@ -17,6 +17,11 @@ const (
 	JujuLoggingConfigEnvKey = "JUJU_LOGGING_CONFIG"
 	JujuFeatureFlagEnvKey   = "JUJU_DEV_FEATURE_FLAGS"
 
+	// JujuCredentialHelperEnvKey names the credential helper used to
+	// keep passwords and other secrets out of the files in JUJU_HOME.
+	// See environs/configstore for the helper protocol.
+	JujuCredentialHelperEnvKey = "JUJU_CREDENTIAL_HELPER"
+
 	// JujuStartupLoggingConfigEnvKey if set is used to configure the initial
 	// logging before the command objects are even created to allow debugging
 	// of the command creation and initialisation process.
@@ -0,0 +1,71 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package labels holds the validation rules for the structured labels
+// that may be set on machines, services and units, and the selectors
+// used to pick out entities by their labels.
+package labels
+
+import (
+	"regexp"
+
+	"github.com/juju/errors"
+)
+
+var (
+	// Keys may not contain dots, so that they can be stored as
+	// document keys in mongo.
+	validKey   = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9_-]*[a-zA-Z0-9])?$`)
+	validValue = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9_.-]*[a-zA-Z0-9])?$`)
+)
+
+// maxLength is the maximum length of a label key or value.
+const maxLength = 63
+
+// ValidateKey returns an error if key is not a valid label key. Keys
+// consist of letters, digits, '-' and '_', and start and end with a
+// letter or digit.
+func ValidateKey(key string) error {
+	if len(key) > maxLength || !validKey.MatchString(key) {
+		return errors.NotValidf("label key %q", key)
+	}
+	return nil
+}
+
+// ValidateValue returns an error if value is not a valid label value.
+// Values consist of letters, digits, '-', '_' and '.', and start and
+// end with a letter or digit.
+func ValidateValue(value string) error {
+	if len(value) > maxLength || !validValue.MatchString(value) {
+		return errors.NotValidf("label value %q", value)
+	}
+	return nil
+}
+
+// Validate returns an error if any of the labels has an invalid key
+// or value.
+func Validate(labels map[string]string) error {
+	for key, value := range labels {
+		if err := ValidateKey(key); err != nil {
+			return errors.Trace(err)
+		}
+		if err := ValidateValue(value); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// Merge returns the labels in base overridden by those in overlay. It
+// is used to compute the labels of a unit, which inherits the labels
+// of its service.
+func Merge(base, overlay map[string]string) map[string]string {
+	result := make(map[string]string, len(base)+len(overlay))
+	for key, value := range base {
+		result[key] = value
+	}
+	for key, value := range overlay {
+		result[key] = value
+	}
+	return result
+}
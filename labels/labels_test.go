@@ -0,0 +1,55 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package labels_test
+
+import (
+	"strings"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/labels"
+	"github.com/juju/juju/testing"
+)
+
+type labelsSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&labelsSuite{})
+
+func (s *labelsSuite) TestValidateKey(c *gc.C) {
+	for _, key := range []string{"tier", "a", "web-tier", "tier_2", "A1"} {
+		c.Check(labels.ValidateKey(key), jc.ErrorIsNil, gc.Commentf("key %q", key))
+	}
+	for _, key := range []string{"", "-tier", "tier-", "a.b", "a b", "$x", strings.Repeat("a", 64)} {
+		c.Check(labels.ValidateKey(key), gc.ErrorMatches, `label key ".*" not valid`, gc.Commentf("key %q", key))
+	}
+}
+
+func (s *labelsSuite) TestValidateValue(c *gc.C) {
+	for _, value := range []string{"web", "1.2.3", "eu-west_1"} {
+		c.Check(labels.ValidateValue(value), jc.ErrorIsNil, gc.Commentf("value %q", value))
+	}
+	for _, value := range []string{"", ".web", "a,b", "a=b", strings.Repeat("a", 64)} {
+		c.Check(labels.ValidateValue(value), gc.ErrorMatches, `label value ".*" not valid`, gc.Commentf("value %q", value))
+	}
+}
+
+func (s *labelsSuite) TestValidate(c *gc.C) {
+	err := labels.Validate(map[string]string{"tier": "web", "env": "prod"})
+	c.Check(err, jc.ErrorIsNil)
+	err = labels.Validate(map[string]string{"tier": "web", "env": "pro d"})
+	c.Check(err, gc.ErrorMatches, `label value "pro d" not valid`)
+}
+
+func (s *labelsSuite) TestMerge(c *gc.C) {
+	base := map[string]string{"tier": "web", "env": "prod"}
+	overlay := map[string]string{"env": "canary"}
+	c.Check(labels.Merge(base, overlay), jc.DeepEquals, map[string]string{
+		"tier": "web",
+		"env":  "canary",
+	})
+	c.Check(labels.Merge(nil, nil), jc.DeepEquals, map[string]string{})
+}
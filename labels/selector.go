@@ -0,0 +1,142 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package labels
+
+import (
+	"strings"
+
+	"github.com/juju/errors"
+)
+
+// Operator identifies how a requirement compares a label.
+type Operator string
+
+const (
+	Equals       Operator = "="
+	NotEquals    Operator = "!="
+	Exists       Operator = "exists"
+	DoesNotExist Operator = "!"
+)
+
+// Requirement is a single condition on an entity's labels.
+type Requirement struct {
+	Key      string
+	Operator Operator
+	Value    string
+}
+
+// Matches returns whether the labels satisfy the requirement. Note
+// that a NotEquals requirement is satisfied by labels without the key.
+func (r Requirement) Matches(labels map[string]string) bool {
+	value, ok := labels[r.Key]
+	switch r.Operator {
+	case Equals:
+		return ok && value == r.Value
+	case NotEquals:
+		return !ok || value != r.Value
+	case Exists:
+		return ok
+	case DoesNotExist:
+		return !ok
+	}
+	return false
+}
+
+// String returns the requirement in the form accepted by ParseSelector.
+func (r Requirement) String() string {
+	switch r.Operator {
+	case Exists:
+		return r.Key
+	case DoesNotExist:
+		return "!" + r.Key
+	}
+	return r.Key + string(r.Operator) + r.Value
+}
+
+// Selector picks out entities by their labels. An entity matches a
+// selector if its labels satisfy all of the selector's requirements;
+// so every entity matches an empty selector.
+type Selector []Requirement
+
+// ParseSelector parses a selector made of comma-separated requirements,
+// each of which is one of:
+//
+//	key=value    the label is set to value ("==" is also accepted)
+//	key!=value   the label is not set to value, or is not set at all
+//	key          the label is set
+//	!key         the label is not set
+//
+// For example: "tier=web,env!=canary".
+func ParseSelector(s string) (Selector, error) {
+	var selector Selector
+	if strings.TrimSpace(s) == "" {
+		return selector, nil
+	}
+	for _, part := range strings.Split(s, ",") {
+		req, err := parseRequirement(strings.TrimSpace(part))
+		if err != nil {
+			return nil, errors.Annotatef(err, "invalid selector %q", s)
+		}
+		selector = append(selector, req)
+	}
+	return selector, nil
+}
+
+func parseRequirement(s string) (Requirement, error) {
+	var req Requirement
+	switch {
+	case s == "":
+		return req, errors.New("empty requirement")
+	case strings.HasPrefix(s, "!") && !strings.Contains(s, "="):
+		req.Key, req.Operator = strings.TrimSpace(s[1:]), DoesNotExist
+	case strings.Contains(s, "!="):
+		parts := strings.SplitN(s, "!=", 2)
+		req.Key, req.Operator, req.Value = parts[0], NotEquals, parts[1]
+	case strings.Contains(s, "=="):
+		parts := strings.SplitN(s, "==", 2)
+		req.Key, req.Operator, req.Value = parts[0], Equals, parts[1]
+	case strings.Contains(s, "="):
+		parts := strings.SplitN(s, "=", 2)
+		req.Key, req.Operator, req.Value = parts[0], Equals, parts[1]
+	default:
+		req.Key, req.Operator = s, Exists
+	}
+	req.Key = strings.TrimSpace(req.Key)
+	req.Value = strings.TrimSpace(req.Value)
+	if err := ValidateKey(req.Key); err != nil {
+		return req, errors.Trace(err)
+	}
+	if req.Operator == Equals || req.Operator == NotEquals {
+		if err := ValidateValue(req.Value); err != nil {
+			return req, errors.Trace(err)
+		}
+	}
+	return req, nil
+}
+
+// Matches returns whether the labels satisfy all of the selector's
+// requirements.
+func (s Selector) Matches(labels map[string]string) bool {
+	for _, req := range s {
+		if !req.Matches(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+// Empty returns whether the selector has no requirements, and so
+// matches everything.
+func (s Selector) Empty() bool {
+	return len(s) == 0
+}
+
+// String returns the selector in the form accepted by ParseSelector.
+func (s Selector) String() string {
+	parts := make([]string, len(s))
+	for i, req := range s {
+		parts[i] = req.String()
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,103 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package labels_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/labels"
+	"github.com/juju/juju/testing"
+)
+
+type selectorSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&selectorSuite{})
+
+func (s *selectorSuite) TestParseSelector(c *gc.C) {
+	for i, test := range []struct {
+		input    string
+		expected labels.Selector
+		str      string
+	}{{
+		input: "",
+	}, {
+		input:    "tier=web",
+		expected: labels.Selector{{Key: "tier", Operator: labels.Equals, Value: "web"}},
+		str:      "tier=web",
+	}, {
+		input:    "tier==web",
+		expected: labels.Selector{{Key: "tier", Operator: labels.Equals, Value: "web"}},
+		str:      "tier=web",
+	}, {
+		input: "tier=web, env!=canary,backup,!debug",
+		expected: labels.Selector{
+			{Key: "tier", Operator: labels.Equals, Value: "web"},
+			{Key: "env", Operator: labels.NotEquals, Value: "canary"},
+			{Key: "backup", Operator: labels.Exists},
+			{Key: "debug", Operator: labels.DoesNotExist},
+		},
+		str: "tier=web,env!=canary,backup,!debug",
+	}} {
+		c.Logf("test %d: %q", i, test.input)
+		selector, err := labels.ParseSelector(test.input)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Check(selector, jc.DeepEquals, test.expected)
+		c.Check(selector.String(), gc.Equals, test.str)
+	}
+}
+
+func (s *selectorSuite) TestParseSelectorErrors(c *gc.C) {
+	for i, test := range []struct {
+		input string
+		err   string
+	}{{
+		input: "tier=web,",
+		err:   `invalid selector "tier=web,": empty requirement`,
+	}, {
+		input: "=web",
+		err:   `invalid selector "=web": label key "" not valid`,
+	}, {
+		input: "tier=",
+		err:   `invalid selector "tier=": label value "" not valid`,
+	}, {
+		input: "tier=we b",
+		err:   `invalid selector "tier=we b": label value "we b" not valid`,
+	}, {
+		input: "!",
+		err:   `invalid selector "!": label key "" not valid`,
+	}} {
+		c.Logf("test %d: %q", i, test.input)
+		_, err := labels.ParseSelector(test.input)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
+
+func (s *selectorSuite) TestMatches(c *gc.C) {
+	entityLabels := map[string]string{"tier": "web", "env": "prod"}
+	for i, test := range []struct {
+		selector string
+		matches  bool
+	}{
+		{"", true},
+		{"tier=web", true},
+		{"tier=db", false},
+		{"tier=web,env=prod", true},
+		{"tier=web,env=canary", false},
+		{"env!=canary", true},
+		{"env!=prod", false},
+		{"zone!=east", true},
+		{"tier", true},
+		{"zone", false},
+		{"!zone", true},
+		{"!tier", false},
+	} {
+		c.Logf("test %d: %q", i, test.selector)
+		selector, err := labels.ParseSelector(test.selector)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Check(selector.Matches(entityLabels), gc.Equals, test.matches)
+	}
+}
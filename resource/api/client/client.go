@@ -0,0 +1,115 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package client
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/resource"
+	"github.com/juju/juju/resource/api"
+)
+
+type facadeCaller interface {
+	FacadeCall(request string, params, response interface{}) error
+}
+
+type rawAPI interface {
+	facadeCaller
+	io.Closer
+}
+
+// HTTPClient sends HTTP requests to the API server, relative to the
+// environment. It is satisfied by *httprequest.Client.
+type HTTPClient interface {
+	Do(req *http.Request, body io.ReadSeeker, resp interface{}) error
+}
+
+// Client provides methods for interacting with Juju's public API,
+// relative to resources.
+type Client struct {
+	rawAPI
+	http HTTPClient
+}
+
+// NewClient builds a new resources API client.
+func NewClient(raw rawAPI, http HTTPClient) *Client {
+	return &Client{
+		rawAPI: raw,
+		http:   http,
+	}
+}
+
+// ListResources calls the ListResources API server method with the
+// given service names.
+func (c Client) ListResources(services ...string) ([]resource.ServiceResources, error) {
+	args := api.ListResourcesArgs{
+		Entities: make([]params.Entity, len(services)),
+	}
+	for i, service := range services {
+		if !names.IsValidService(service) {
+			return nil, errors.NotValidf("service name %q", service)
+		}
+		args.Entities[i].Tag = names.NewServiceTag(service).String()
+	}
+	var result api.ResourcesResults
+	if err := c.FacadeCall("ListResources", &args, &result); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(result.Results) != len(services) {
+		return nil, errors.Errorf("expected %d results, got %d", len(services), len(result.Results))
+	}
+
+	results := make([]resource.ServiceResources, len(services))
+	for i, r := range result.Results {
+		if r.Error != nil {
+			return nil, errors.Trace(r.Error)
+		}
+		svcRes, err := api.API2ServiceResources(r)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		results[i] = svcRes
+	}
+	return results, nil
+}
+
+// Upload sends the data read from r as a new revision of the named
+// resource of the service, returning the new revision.
+func (c Client) Upload(service, name string, r io.ReadSeeker) (resource.Resource, error) {
+	if !names.IsValidService(service) {
+		return resource.Resource{}, errors.NotValidf("service name %q", service)
+	}
+	fingerprint, size, err := resource.Fingerprint(r)
+	if err != nil {
+		return resource.Resource{}, errors.Annotate(err, "cannot read resource data")
+	}
+	if _, err := r.Seek(0, 0); err != nil {
+		return resource.Resource{}, errors.Trace(err)
+	}
+
+	endpoint := fmt.Sprintf("/services/%s/resources/%s", service, name)
+	req, err := http.NewRequest("PUT", endpoint, nil)
+	if err != nil {
+		return resource.Resource{}, errors.Annotate(err, "cannot create upload request")
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set(api.HeaderFingerprint, fingerprint)
+	req.ContentLength = size
+
+	var result api.UploadResult
+	if err := c.http.Do(req, r, &result); err != nil {
+		return resource.Resource{}, errors.Trace(err)
+	}
+	if result.Error != nil {
+		return resource.Resource{}, errors.Trace(result.Error)
+	}
+	res, err := api.API2Resource(result.Resource)
+	return res, errors.Trace(err)
+}
@@ -0,0 +1,173 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package client_test
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/resource"
+	"github.com/juju/juju/resource/api"
+	"github.com/juju/juju/resource/api/client"
+)
+
+type ClientSuite struct {
+	testing.IsolationSuite
+
+	stub *testing.Stub
+	fake *fakeAPI
+}
+
+var _ = gc.Suite(&ClientSuite{})
+
+func (s *ClientSuite) SetUpTest(c *gc.C) {
+	s.IsolationSuite.SetUpTest(c)
+	s.stub = &testing.Stub{}
+	s.fake = &fakeAPI{Stub: s.stub}
+}
+
+var placeholder = api.Resource{
+	Name:    "licence",
+	Type:    resource.TypeFile,
+	Path:    "licence.txt",
+	Service: "starsay",
+}
+
+func (s *ClientSuite) TestListResources(c *gc.C) {
+	s.fake.listResult = api.ResourcesResults{
+		Results: []api.ResourcesResult{{Resources: []api.Resource{placeholder}}},
+	}
+	results, err := client.NewClient(s.fake, s.fake).ListResources("starsay")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(results, jc.DeepEquals, []resource.ServiceResources{{
+		Resources: []resource.Resource{{
+			Meta:    resource.Meta{Name: "licence", Type: resource.TypeFile, Path: "licence.txt"},
+			Service: "starsay",
+		}},
+	}})
+	s.stub.CheckCall(c, 0, "FacadeCall", "ListResources", &api.ListResourcesArgs{
+		Entities: []params.Entity{{Tag: "service-starsay"}},
+	})
+}
+
+func (s *ClientSuite) TestListResourcesError(c *gc.C) {
+	s.fake.listResult = api.ResourcesResults{
+		Results: []api.ResourcesResult{{Error: &params.Error{Message: "boom"}}},
+	}
+	_, err := client.NewClient(s.fake, s.fake).ListResources("starsay")
+	c.Check(err, gc.ErrorMatches, "boom")
+
+	_, err = client.NewClient(s.fake, s.fake).ListResources("starsay/0")
+	c.Check(err, gc.ErrorMatches, `service name "starsay/0" not valid`)
+}
+
+func (s *ClientSuite) TestUpload(c *gc.C) {
+	fingerprint, _, err := resource.Fingerprint(strings.NewReader("GPLv3"))
+	c.Assert(err, jc.ErrorIsNil)
+	uploaded := placeholder
+	uploaded.Revision = 1
+	uploaded.Fingerprint = fingerprint
+	uploaded.Size = 5
+	uploaded.Username = "bob"
+	s.fake.uploadResult = api.UploadResult{Resource: uploaded}
+
+	res, err := client.NewClient(s.fake, s.fake).Upload("starsay", "licence", strings.NewReader("GPLv3"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(res.Revision, gc.Equals, 1)
+
+	req := s.fake.req
+	c.Check(req.Method, gc.Equals, "PUT")
+	c.Check(req.URL.Path, gc.Equals, "/services/starsay/resources/licence")
+	c.Check(req.Header.Get(api.HeaderFingerprint), gc.Equals, fingerprint)
+	c.Check(req.ContentLength, gc.Equals, int64(5))
+	c.Check(s.fake.body, gc.Equals, "GPLv3")
+}
+
+func (s *ClientSuite) TestDownload(c *gc.C) {
+	s.fake.response = &http.Response{
+		StatusCode: http.StatusOK,
+		Header: http.Header{
+			api.HeaderFingerprint: {"abc"},
+			api.HeaderRevision:    {"2"},
+			api.HeaderPath:        {"licence.txt"},
+		},
+		Body: ioutil.NopCloser(strings.NewReader("GPLv3")),
+	}
+	download, err := client.NewUnitClient("starsay/0", s.fake).Download("licence", "def")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(download.Unchanged, jc.IsFalse)
+	c.Check(download.Revision, gc.Equals, 2)
+	c.Check(download.Fingerprint, gc.Equals, "abc")
+	c.Check(download.Path, gc.Equals, "licence.txt")
+	data, err := ioutil.ReadAll(download.Data)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(string(data), gc.Equals, "GPLv3")
+	c.Check(s.fake.req.URL.String(), gc.Equals, "/units/unit-starsay-0/resources/licence?fingerprint=def")
+}
+
+func (s *ClientSuite) TestDownloadUnchanged(c *gc.C) {
+	s.fake.response = &http.Response{
+		StatusCode: http.StatusNoContent,
+		Header: http.Header{
+			api.HeaderFingerprint: {"abc"},
+			api.HeaderRevision:    {"2"},
+		},
+		Body: ioutil.NopCloser(&bytes.Buffer{}),
+	}
+	download, err := client.NewUnitClient("starsay/0", s.fake).Download("licence", "abc")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(download.Unchanged, jc.IsTrue)
+	c.Check(download.Data, gc.IsNil)
+}
+
+type fakeAPI struct {
+	*testing.Stub
+
+	listResult   api.ResourcesResults
+	uploadResult api.UploadResult
+	response     *http.Response
+
+	req  *http.Request
+	body string
+}
+
+func (f *fakeAPI) FacadeCall(request string, args, response interface{}) error {
+	f.AddCall("FacadeCall", request, args)
+	if err := f.NextErr(); err != nil {
+		return err
+	}
+	*response.(*api.ResourcesResults) = f.listResult
+	return nil
+}
+
+func (f *fakeAPI) Close() error {
+	return nil
+}
+
+func (f *fakeAPI) Do(req *http.Request, body io.ReadSeeker, resp interface{}) error {
+	f.req = req
+	if body != nil {
+		data, err := ioutil.ReadAll(body)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		f.body = string(data)
+	}
+	switch resp := resp.(type) {
+	case *api.UploadResult:
+		*resp = f.uploadResult
+	case **http.Response:
+		*resp = f.response
+	}
+	return nil
+}
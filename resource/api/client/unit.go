@@ -0,0 +1,86 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package client
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/resource/api"
+)
+
+// Download describes resource data downloaded by a unit.
+type Download struct {
+	// Unchanged is true if the resource has the revision the unit
+	// already had, in which case Data is nil.
+	Unchanged bool
+
+	// Revision is the revision of the resource.
+	Revision int
+
+	// Fingerprint is the fingerprint of the data.
+	Fingerprint string
+
+	// Path is where the resource is to be made available to the unit,
+	// relative to its resource directory.
+	Path string
+
+	// Data is the resource data. The caller must close it.
+	Data io.ReadCloser
+}
+
+// UnitClient provides methods for a unit agent to download the charm
+// resources of its service.
+type UnitClient struct {
+	unit string
+	http HTTPClient
+}
+
+// NewUnitClient builds a new client for the resources of the named
+// unit's service.
+func NewUnitClient(unit string, http HTTPClient) *UnitClient {
+	return &UnitClient{
+		unit: unit,
+		http: http,
+	}
+}
+
+// Download fetches the current revision of the named resource. If it
+// has the given fingerprint, the data is not sent again.
+func (c UnitClient) Download(name, fingerprint string) (*Download, error) {
+	endpoint := fmt.Sprintf("/units/%s/resources/%s", names.NewUnitTag(c.unit), name)
+	if fingerprint != "" {
+		endpoint += "?fingerprint=" + url.QueryEscape(fingerprint)
+	}
+	req, err := http.NewRequest("GET", endpoint, nil)
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot create download request")
+	}
+
+	var resp *http.Response
+	if err := c.http.Do(req, nil, &resp); err != nil {
+		return nil, errors.Trace(err)
+	}
+	download := &Download{
+		Unchanged:   resp.StatusCode == http.StatusNoContent,
+		Fingerprint: resp.Header.Get(api.HeaderFingerprint),
+		Path:        resp.Header.Get(api.HeaderPath),
+	}
+	if download.Revision, err = strconv.Atoi(resp.Header.Get(api.HeaderRevision)); err != nil {
+		resp.Body.Close()
+		return nil, errors.Annotate(err, "invalid resource revision")
+	}
+	if download.Unchanged {
+		resp.Body.Close()
+	} else {
+		download.Data = resp.Body
+	}
+	return download, nil
+}
@@ -0,0 +1,77 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package api
+
+import (
+	"time"
+
+	"github.com/juju/juju/apiserver/params"
+)
+
+const (
+	// HeaderFingerprint is the HTTP header holding the fingerprint of
+	// the resource data being uploaded or downloaded.
+	HeaderFingerprint = "Juju-Resource-Fingerprint"
+
+	// HeaderRevision is the HTTP header holding the revision of the
+	// resource being downloaded.
+	HeaderRevision = "Juju-Resource-Revision"
+
+	// HeaderPath is the HTTP header holding the path, relative to the
+	// unit's resource directory, of the resource being downloaded.
+	HeaderPath = "Juju-Resource-Path"
+)
+
+// ListResourcesArgs are the arguments for the ListResources endpoint.
+type ListResourcesArgs struct {
+	// Entities holds the tags of the services whose resources are
+	// to be listed.
+	Entities []params.Entity
+}
+
+// ResourcesResults holds the resources of each requested service.
+type ResourcesResults struct {
+	// Results is the list of resource results.
+	Results []ResourcesResult
+}
+
+// ResourcesResult holds the resources of a service.
+type ResourcesResult struct {
+	// Resources are the current revisions of the service's resources.
+	Resources []Resource
+	// UnitResources are the revisions each of the service's units has.
+	UnitResources []UnitResources
+	// Error is any error encountered listing the service's resources.
+	Error *params.Error
+}
+
+// UnitResources holds the resource revisions a unit has.
+type UnitResources struct {
+	// Tag is the tag of the unit.
+	Tag string
+	// Resources are the revisions the unit has.
+	Resources []Resource
+}
+
+// UploadResult is the response to uploading resource data.
+type UploadResult struct {
+	// Resource describes the new revision of the resource.
+	Resource Resource
+	// Error is any error encountered storing the resource.
+	Error *params.Error
+}
+
+// Resource contains full information about a resource revision.
+type Resource struct {
+	Name        string
+	Type        string
+	Path        string
+	Description string
+	Service     string
+	Revision    int
+	Fingerprint string
+	Size        int64
+	Username    string
+	Timestamp   time.Time
+}
@@ -0,0 +1,97 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package api
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/resource"
+)
+
+// Resource2API converts a resource.Resource into a Resource struct.
+func Resource2API(res resource.Resource) Resource {
+	return Resource{
+		Name:        res.Name,
+		Type:        res.Type,
+		Path:        res.Path,
+		Description: res.Description,
+		Service:     res.Service,
+		Revision:    res.Revision,
+		Fingerprint: res.Fingerprint,
+		Size:        res.Size,
+		Username:    res.Username,
+		Timestamp:   res.Timestamp,
+	}
+}
+
+// API2Resource converts an API Resource struct into a resource.Resource.
+func API2Resource(apiRes Resource) (resource.Resource, error) {
+	res := resource.Resource{
+		Meta: resource.Meta{
+			Name:        apiRes.Name,
+			Type:        apiRes.Type,
+			Path:        apiRes.Path,
+			Description: apiRes.Description,
+		},
+		Service:     apiRes.Service,
+		Revision:    apiRes.Revision,
+		Fingerprint: apiRes.Fingerprint,
+		Size:        apiRes.Size,
+		Username:    apiRes.Username,
+		Timestamp:   apiRes.Timestamp,
+	}
+	if err := res.Validate(); err != nil {
+		return res, errors.Trace(err)
+	}
+	return res, nil
+}
+
+// ServiceResources2API converts a resource.ServiceResources into
+// a ResourcesResult struct.
+func ServiceResources2API(svcRes resource.ServiceResources) ResourcesResult {
+	var result ResourcesResult
+	for _, res := range svcRes.Resources {
+		result.Resources = append(result.Resources, Resource2API(res))
+	}
+	for _, unitRes := range svcRes.UnitResources {
+		apiUnitRes := UnitResources{
+			Tag: names.NewUnitTag(unitRes.Unit).String(),
+		}
+		for _, res := range unitRes.Resources {
+			apiUnitRes.Resources = append(apiUnitRes.Resources, Resource2API(res))
+		}
+		result.UnitResources = append(result.UnitResources, apiUnitRes)
+	}
+	return result
+}
+
+// API2ServiceResources converts a ResourcesResult struct into
+// a resource.ServiceResources.
+func API2ServiceResources(result ResourcesResult) (resource.ServiceResources, error) {
+	var svcRes resource.ServiceResources
+	for _, apiRes := range result.Resources {
+		res, err := API2Resource(apiRes)
+		if err != nil {
+			return svcRes, errors.Trace(err)
+		}
+		svcRes.Resources = append(svcRes.Resources, res)
+	}
+	for _, apiUnitRes := range result.UnitResources {
+		tag, err := names.ParseUnitTag(apiUnitRes.Tag)
+		if err != nil {
+			return svcRes, errors.Trace(err)
+		}
+		unitRes := resource.UnitResources{Unit: tag.Id()}
+		for _, apiRes := range apiUnitRes.Resources {
+			res, err := API2Resource(apiRes)
+			if err != nil {
+				return svcRes, errors.Trace(err)
+			}
+			unitRes.Resources = append(unitRes.Resources, res)
+		}
+		svcRes.UnitResources = append(svcRes.UnitResources, unitRes)
+	}
+	return svcRes, nil
+}
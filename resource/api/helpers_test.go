@@ -0,0 +1,58 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package api_test
+
+import (
+	"strings"
+	"time"
+
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/resource"
+	"github.com/juju/juju/resource/api"
+)
+
+type HelpersSuite struct {
+	testing.IsolationSuite
+}
+
+var _ = gc.Suite(&HelpersSuite{})
+
+func (s *HelpersSuite) TestServiceResourcesRoundTrip(c *gc.C) {
+	res := resource.Resource{
+		Meta: resource.Meta{
+			Name:        "licence",
+			Type:        resource.TypeFile,
+			Path:        "licence.txt",
+			Description: "the licence",
+		},
+		Service:     "starsay",
+		Revision:    2,
+		Fingerprint: strings.Repeat("a", 96),
+		Size:        10,
+		Username:    "bob",
+		Timestamp:   time.Date(2015, 11, 1, 0, 0, 0, 0, time.UTC),
+	}
+	svcRes := resource.ServiceResources{
+		Resources: []resource.Resource{res},
+		UnitResources: []resource.UnitResources{{
+			Unit:      "starsay/0",
+			Resources: []resource.Resource{res},
+		}},
+	}
+
+	result := api.ServiceResources2API(svcRes)
+	c.Check(result.UnitResources[0].Tag, gc.Equals, "unit-starsay-0")
+
+	converted, err := api.API2ServiceResources(result)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(converted, jc.DeepEquals, svcRes)
+}
+
+func (s *HelpersSuite) TestAPI2ResourceInvalid(c *gc.C) {
+	_, err := api.API2Resource(api.Resource{Name: "licence", Type: "docker", Path: "x"})
+	c.Check(err, gc.ErrorMatches, `resource "licence" type "docker" not valid`)
+}
@@ -0,0 +1,52 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package server
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/resource"
+	"github.com/juju/juju/resource/api"
+)
+
+// DataStore exposes the State functionality for resources.
+type DataStore interface {
+	// ListResources returns the resources of the service.
+	ListResources(service string) (resource.ServiceResources, error)
+}
+
+// PublicAPI serves resource-specific API methods.
+type PublicAPI struct {
+	// State exposes the resources aspect of Juju's state.
+	State DataStore
+}
+
+// NewPublicAPI builds a new facade for the given State.
+func NewPublicAPI(st DataStore) *PublicAPI {
+	return &PublicAPI{State: st}
+}
+
+// ListResources returns the resources of each of the given services,
+// along with the revisions their units have.
+func (a PublicAPI) ListResources(args api.ListResourcesArgs) (api.ResourcesResults, error) {
+	r := api.ResourcesResults{
+		Results: make([]api.ResourcesResult, len(args.Entities)),
+	}
+	for i, entity := range args.Entities {
+		tag, err := names.ParseServiceTag(entity.Tag)
+		if err != nil {
+			r.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		svcRes, err := a.State.ListResources(tag.Id())
+		if err != nil {
+			r.Results[i].Error = common.ServerError(errors.Trace(err))
+			continue
+		}
+		r.Results[i] = api.ServiceResources2API(svcRes)
+	}
+	return r, nil
+}
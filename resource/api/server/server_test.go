@@ -0,0 +1,55 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package server_test
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/resource"
+	"github.com/juju/juju/resource/api"
+	"github.com/juju/juju/resource/api/server"
+)
+
+type PublicAPISuite struct {
+	testing.IsolationSuite
+}
+
+var _ = gc.Suite(&PublicAPISuite{})
+
+type fakeDataStore struct {
+	resources map[string]resource.ServiceResources
+}
+
+func (f fakeDataStore) ListResources(service string) (resource.ServiceResources, error) {
+	svcRes, ok := f.resources[service]
+	if !ok {
+		return svcRes, errors.NotFoundf("service %q", service)
+	}
+	return svcRes, nil
+}
+
+func (s *PublicAPISuite) TestListResources(c *gc.C) {
+	placeholder := resource.Resource{
+		Meta:    resource.Meta{Name: "licence", Type: resource.TypeFile, Path: "licence.txt"},
+		Service: "starsay",
+	}
+	facade := server.NewPublicAPI(fakeDataStore{map[string]resource.ServiceResources{
+		"starsay": {Resources: []resource.Resource{placeholder}},
+	}})
+
+	results, err := facade.ListResources(api.ListResourcesArgs{
+		Entities: []params.Entity{{Tag: "service-starsay"}, {Tag: "service-wordpress"}, {Tag: "unit-starsay-0"}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 3)
+	c.Check(results.Results[0], jc.DeepEquals, api.ResourcesResult{
+		Resources: []api.Resource{api.Resource2API(placeholder)},
+	})
+	c.Check(results.Results[1].Error, gc.ErrorMatches, `service "wordpress" not found`)
+	c.Check(results.Results[2].Error, gc.ErrorMatches, `"unit-starsay-0" is not a valid service tag`)
+}
@@ -0,0 +1,167 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package cmd_test
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/resource"
+	resourcecmd "github.com/juju/juju/resource/cmd"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type CommandsSuite struct {
+	testing.IsolationSuite
+
+	stub   *testing.Stub
+	client *stubClient
+}
+
+var _ = gc.Suite(&CommandsSuite{})
+
+func (s *CommandsSuite) SetUpTest(c *gc.C) {
+	s.IsolationSuite.SetUpTest(c)
+	s.stub = &testing.Stub{}
+	s.client = &stubClient{stub: s.stub}
+}
+
+func (s *CommandsSuite) newListClient(c *resourcecmd.ListCommand) (resourcecmd.ListAPI, error) {
+	return s.client, nil
+}
+
+func (s *CommandsSuite) newPushClient(c *resourcecmd.PushCommand) (resourcecmd.PushAPI, error) {
+	return s.client, nil
+}
+
+func newResource(revision int) resource.Resource {
+	return resource.Resource{
+		Meta:        resource.Meta{Name: "licence", Type: resource.TypeFile, Path: "licence.txt"},
+		Service:     "starsay",
+		Revision:    revision,
+		Fingerprint: strings.Repeat("ab", 48),
+		Size:        5,
+		Username:    "bob",
+		Timestamp:   time.Date(2015, 11, 1, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+func (s *CommandsSuite) TestListTabular(c *gc.C) {
+	s.client.resources = resource.ServiceResources{
+		Resources: []resource.Resource{newResource(2), {
+			Meta:    resource.Meta{Name: "store", Type: resource.TypeFile, Path: "store.tgz"},
+			Service: "starsay",
+		}},
+		UnitResources: []resource.UnitResources{{
+			Unit:      "starsay/0",
+			Resources: []resource.Resource{newResource(1)},
+		}},
+	}
+	code, stdout, stderr := runCommand(c, resourcecmd.NewListCommand(s.newListClient), "starsay")
+	c.Assert(code, gc.Equals, 0)
+	c.Check(stderr, gc.Equals, "")
+	c.Check(stdout, gc.Equals, `
+[Service]
+RESOURCE REVISION SIZE FINGERPRINT  PUSHED-BY PUSHED-AT           
+licence  2        5    abababababab bob       2015-11-01 12:00:00 
+store    0        0                                               
+
+[Units]
+UNIT      RESOURCE REVISION 
+starsay/0 licence  1        
+`[1:])
+	s.stub.CheckCall(c, 0, "ListResources", []string{"starsay"})
+}
+
+func (s *CommandsSuite) TestListInit(c *gc.C) {
+	code, _, stderr := runCommand(c, resourcecmd.NewListCommand(s.newListClient))
+	c.Check(code, gc.Equals, 2)
+	c.Check(stderr, gc.Equals, "error: no service specified\n")
+
+	code, _, stderr = runCommand(c, resourcecmd.NewListCommand(s.newListClient), "starsay/0")
+	c.Check(code, gc.Equals, 2)
+	c.Check(stderr, gc.Equals, "error: invalid service name \"starsay/0\"\n")
+}
+
+func (s *CommandsSuite) TestPush(c *gc.C) {
+	filename := filepath.Join(c.MkDir(), "licence.txt")
+	c.Assert(ioutil.WriteFile(filename, []byte("GPLv3"), 0644), jc.ErrorIsNil)
+	s.client.uploaded = newResource(3)
+
+	code, _, stderr := runCommand(c, resourcecmd.NewPushCommand(s.newPushClient), "starsay", "licence="+filename)
+	c.Assert(code, gc.Equals, 0)
+	c.Check(stderr, gc.Equals, `uploaded resource "licence" of service "starsay", revision 3`+"\n")
+	s.stub.CheckCall(c, 0, "Upload", "starsay", "licence", "GPLv3")
+}
+
+func (s *CommandsSuite) TestPushInit(c *gc.C) {
+	for i, test := range []struct {
+		args []string
+		err  string
+	}{{
+		err: "no service specified",
+	}, {
+		args: []string{"starsay"},
+		err:  "no resource specified",
+	}, {
+		args: []string{"starsay", "licence"},
+		err:  `expected <resource>=<file>, got "licence"`,
+	}, {
+		args: []string{"starsay", "licence=foo", "bar"},
+		err:  `unrecognized args: \["bar"\]`,
+	}} {
+		c.Logf("test %d: %v", i, test.args)
+		code, _, stderr := runCommand(c, resourcecmd.NewPushCommand(s.newPushClient), test.args...)
+		c.Check(code, gc.Equals, 2)
+		c.Check(stderr, gc.Matches, "error: "+test.err+"\n")
+	}
+}
+
+func runCommand(c *gc.C, command cmd.Command, args ...string) (int, string, string) {
+	ctx := coretesting.Context(c)
+	code := cmd.Main(command, ctx, args)
+	stdout := ctx.Stdout.(*bytes.Buffer).String()
+	stderr := ctx.Stderr.(*bytes.Buffer).String()
+	return code, stdout, stderr
+}
+
+type stubClient struct {
+	stub      *testing.Stub
+	resources resource.ServiceResources
+	uploaded  resource.Resource
+}
+
+func (s *stubClient) ListResources(services ...string) ([]resource.ServiceResources, error) {
+	s.stub.AddCall("ListResources", services)
+	if err := s.stub.NextErr(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return []resource.ServiceResources{s.resources}, nil
+}
+
+func (s *stubClient) Upload(service, name string, r io.ReadSeeker) (resource.Resource, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return resource.Resource{}, errors.Trace(err)
+	}
+	s.stub.AddCall("Upload", service, name, string(data))
+	if err := s.stub.NextErr(); err != nil {
+		return resource.Resource{}, errors.Trace(err)
+	}
+	return s.uploaded, nil
+}
+
+func (s *stubClient) Close() error {
+	return nil
+}
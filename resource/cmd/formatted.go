@@ -0,0 +1,68 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package cmd
+
+import (
+	"time"
+
+	"github.com/juju/juju/resource"
+)
+
+// FormattedResource holds the formatted representation of a resource.
+type FormattedResource struct {
+	Name        string    `json:"name" yaml:"name"`
+	Path        string    `json:"path" yaml:"path"`
+	Description string    `json:"description,omitempty" yaml:"description,omitempty"`
+	Revision    int       `json:"revision" yaml:"revision"`
+	Fingerprint string    `json:"fingerprint,omitempty" yaml:"fingerprint,omitempty"`
+	Size        int64     `json:"size" yaml:"size"`
+	Username    string    `json:"username,omitempty" yaml:"username,omitempty"`
+	Timestamp   time.Time `json:"timestamp,omitempty" yaml:"timestamp,omitempty"`
+}
+
+// FormattedUnitResource records the revision of a resource a unit has.
+type FormattedUnitResource struct {
+	Unit     string `json:"unit" yaml:"unit"`
+	Name     string `json:"resource" yaml:"resource"`
+	Revision int    `json:"revision" yaml:"revision"`
+}
+
+// FormattedServiceResources holds the formatted representation of the
+// resources of a service.
+type FormattedServiceResources struct {
+	Resources []FormattedResource     `json:"resources" yaml:"resources"`
+	Units     []FormattedUnitResource `json:"units,omitempty" yaml:"units,omitempty"`
+}
+
+func formatResource(res resource.Resource) FormattedResource {
+	return FormattedResource{
+		Name:        res.Name,
+		Path:        res.Path,
+		Description: res.Description,
+		Revision:    res.Revision,
+		Fingerprint: res.Fingerprint,
+		Size:        res.Size,
+		Username:    res.Username,
+		Timestamp:   res.Timestamp,
+	}
+}
+
+func formatServiceResources(svcRes resource.ServiceResources) FormattedServiceResources {
+	formatted := FormattedServiceResources{
+		Resources: make([]FormattedResource, len(svcRes.Resources)),
+	}
+	for i, res := range svcRes.Resources {
+		formatted.Resources[i] = formatResource(res)
+	}
+	for _, unitRes := range svcRes.UnitResources {
+		for _, res := range unitRes.Resources {
+			formatted.Units = append(formatted.Units, FormattedUnitResource{
+				Unit:     unitRes.Unit,
+				Name:     res.Name,
+				Revision: res.Revision,
+			})
+		}
+	}
+	return formatted
+}
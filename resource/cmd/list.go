@@ -0,0 +1,97 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/resource"
+)
+
+// ListAPI has the API methods needed by ListCommand.
+type ListAPI interface {
+	ListResources(services ...string) ([]resource.ServiceResources, error)
+	io.Closer
+}
+
+// ListCommand implements the list-resources command.
+type ListCommand struct {
+	envcmd.EnvCommandBase
+	out     cmd.Output
+	service string
+
+	newAPIClient func(c *ListCommand) (ListAPI, error)
+}
+
+// NewListCommand returns a new command that lists the charm resources
+// of a service.
+func NewListCommand(newAPIClient func(c *ListCommand) (ListAPI, error)) *ListCommand {
+	return &ListCommand{
+		newAPIClient: newAPIClient,
+	}
+}
+
+var listDoc = `
+This command shows the resources declared by a service's charm, along
+with their current revisions and the revision each of the service's
+units last downloaded. A resource with revision 0 has not been pushed
+yet.
+`
+
+func (c *ListCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "list-resources",
+		Args:    "<service>",
+		Purpose: "show the resources of a service",
+		Doc:     listDoc,
+	}
+}
+
+func (c *ListCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.out.AddFlags(f, "tabular", map[string]cmd.Formatter{
+		"tabular": FormatTabular,
+		"yaml":    cmd.FormatYaml,
+		"json":    cmd.FormatJson,
+	})
+}
+
+func (c *ListCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return errors.New("no service specified")
+	}
+	c.service = args[0]
+	if !names.IsValidService(c.service) {
+		return errors.Errorf("invalid service name %q", c.service)
+	}
+	return cmd.CheckEmpty(args[1:])
+}
+
+// TODO(ericsnow) Move this to a common place, like cmd/envcmd?
+const connectionError = `Unable to connect to environment %q.
+Please check your credentials or use 'juju bootstrap' to create a new environment.
+
+Error details:
+%v
+`
+
+func (c *ListCommand) Run(ctx *cmd.Context) error {
+	apiclient, err := c.newAPIClient(c)
+	if err != nil {
+		return fmt.Errorf(connectionError, c.ConnectionName(), err)
+	}
+	defer apiclient.Close()
+
+	results, err := apiclient.ListResources(c.service)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return c.out.Write(ctx, formatServiceResources(results[0]))
+}
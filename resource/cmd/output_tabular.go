@@ -0,0 +1,53 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/juju/errors"
+)
+
+// fingerprintLength is the number of characters of a fingerprint shown
+// in tabular output.
+const fingerprintLength = 12
+
+// FormatTabular returns a tabular summary of a service's resources.
+func FormatTabular(value interface{}) ([]byte, error) {
+	formatted, ok := value.(FormattedServiceResources)
+	if !ok {
+		return nil, errors.Errorf("expected value of type %T, got %T", formatted, value)
+	}
+
+	var out bytes.Buffer
+	tw := tabwriter.NewWriter(&out, 0, 1, 1, ' ', 0)
+
+	fmt.Fprintln(tw, "[Service]")
+	fmt.Fprintln(tw, "RESOURCE\tREVISION\tSIZE\tFINGERPRINT\tPUSHED-BY\tPUSHED-AT\t")
+	for _, res := range formatted.Resources {
+		fingerprint := res.Fingerprint
+		if len(fingerprint) > fingerprintLength {
+			fingerprint = fingerprint[:fingerprintLength]
+		}
+		var timestamp string
+		if !res.Timestamp.IsZero() {
+			timestamp = res.Timestamp.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%s\t%s\t%s\t\n",
+			res.Name, res.Revision, res.Size, fingerprint, res.Username, timestamp)
+	}
+	if len(formatted.Units) > 0 {
+		fmt.Fprintln(tw)
+		fmt.Fprintln(tw, "[Units]")
+		fmt.Fprintln(tw, "UNIT\tRESOURCE\tREVISION\t")
+		for _, unitRes := range formatted.Units {
+			fmt.Fprintf(tw, "%s\t%s\t%d\t\n", unitRes.Unit, unitRes.Name, unitRes.Revision)
+		}
+	}
+	tw.Flush()
+
+	return out.Bytes(), nil
+}
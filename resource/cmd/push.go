@@ -0,0 +1,102 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/resource"
+)
+
+// PushAPI has the API methods needed by PushCommand.
+type PushAPI interface {
+	Upload(service, name string, r io.ReadSeeker) (resource.Resource, error)
+	io.Closer
+}
+
+// PushCommand implements the push-resource command.
+type PushCommand struct {
+	envcmd.EnvCommandBase
+	service  string
+	name     string
+	filename string
+
+	newAPIClient func(c *PushCommand) (PushAPI, error)
+}
+
+// NewPushCommand returns a new command that uploads a new revision of
+// a charm resource of a service.
+func NewPushCommand(newAPIClient func(c *PushCommand) (PushAPI, error)) *PushCommand {
+	return &PushCommand{
+		newAPIClient: newAPIClient,
+	}
+}
+
+var pushDoc = `
+This command uploads a file as a new revision of a resource declared
+by a service's charm. The data is stored in the environment, and is
+made available to the service's units through the resource-get hook
+tool.
+
+Example:
+
+    juju push-resource starsay store-install=./store.tgz
+`
+
+func (c *PushCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "push-resource",
+		Args:    "<service> <resource>=<file>",
+		Purpose: "upload a new revision of a service's charm resource",
+		Doc:     pushDoc,
+	}
+}
+
+func (c *PushCommand) Init(args []string) error {
+	switch len(args) {
+	case 0:
+		return errors.New("no service specified")
+	case 1:
+		return errors.New("no resource specified")
+	}
+	c.service = args[0]
+	if !names.IsValidService(c.service) {
+		return errors.Errorf("invalid service name %q", c.service)
+	}
+	parts := strings.SplitN(args[1], "=", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return errors.Errorf("expected <resource>=<file>, got %q", args[1])
+	}
+	c.name, c.filename = parts[0], parts[1]
+	return cmd.CheckEmpty(args[2:])
+}
+
+func (c *PushCommand) Run(ctx *cmd.Context) error {
+	f, err := os.Open(ctx.AbsPath(c.filename))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer f.Close()
+
+	apiclient, err := c.newAPIClient(c)
+	if err != nil {
+		return fmt.Errorf(connectionError, c.ConnectionName(), err)
+	}
+	defer apiclient.Close()
+
+	res, err := apiclient.Upload(c.service, c.name, f)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	ctx.Infof("uploaded resource %q of service %q, revision %d", res.Name, c.service, res.Revision)
+	return nil
+}
@@ -0,0 +1,10 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// The resource package (and subpackages) contain the implementation of
+// the charm resource feature component. The various pieces are
+// connected to the Juju machinery in component/all/resource.go.
+package resource
+
+// ComponentName is the name of the Juju component for resource management.
+const ComponentName = "resources"
@@ -0,0 +1,138 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package context
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+
+	"github.com/juju/juju/resource"
+	"github.com/juju/juju/resource/api/client"
+)
+
+var logger = loggo.GetLogger("juju.resource.context")
+
+// fingerprintSuffix is appended to a resource's name to give the file
+// recording the fingerprint of the revision the unit has.
+const fingerprintSuffix = ".fingerprint"
+
+// APIClient represents the API needs of a Context.
+type APIClient interface {
+	// Download fetches the current revision of the named resource,
+	// unless it has the given fingerprint.
+	Download(name, fingerprint string) (*client.Download, error)
+}
+
+// Context is the resources portion of the hook context. Resources are
+// stored in the data directory, each in a directory of its own.
+type Context struct {
+	api     APIClient
+	dataDir string
+
+	// paths holds the paths of the resources downloaded during
+	// the hook, so that they are fetched at most once.
+	paths map[string]string
+}
+
+// NewContext returns a new jujuc.ContextComponent for resources.
+func NewContext(api APIClient, dataDir string) *Context {
+	return &Context{
+		api:     api,
+		dataDir: dataDir,
+		paths:   make(map[string]string),
+	}
+}
+
+// Download makes the current revision of the named resource available
+// to the unit and returns the path to its file. The data is only
+// fetched if the unit does not already have the revision.
+func (c *Context) Download(name string) (string, error) {
+	if path, ok := c.paths[name]; ok {
+		return path, nil
+	}
+	if name == "" || strings.ContainsAny(name, `/\`) || strings.HasPrefix(name, ".") {
+		return "", errors.NotValidf("resource name %q", name)
+	}
+
+	fingerprintFile := filepath.Join(c.dataDir, name+fingerprintSuffix)
+	current, err := ioutil.ReadFile(fingerprintFile)
+	if err != nil && !os.IsNotExist(err) {
+		return "", errors.Trace(err)
+	}
+	download, err := c.api.Download(name, string(current))
+	if err != nil {
+		return "", errors.Annotatef(err, "cannot download resource %q", name)
+	}
+	dir := filepath.Join(c.dataDir, name)
+	path := filepath.Join(dir, download.Path)
+	if download.Unchanged {
+		if _, err := os.Stat(path); err == nil {
+			c.paths[name] = path
+			return path, nil
+		}
+		// The file has gone missing; fetch it again.
+		logger.Warningf("resource %q missing from %q, downloading again", name, path)
+		if download, err = c.api.Download(name, ""); err != nil {
+			return "", errors.Annotatef(err, "cannot download resource %q", name)
+		}
+		path = filepath.Join(dir, download.Path)
+	}
+	defer download.Data.Close()
+
+	if err := writeResource(dir, path, download); err != nil {
+		return "", errors.Annotatef(err, "cannot store resource %q", name)
+	}
+	if err := ioutil.WriteFile(fingerprintFile, []byte(download.Fingerprint), 0644); err != nil {
+		return "", errors.Trace(err)
+	}
+	logger.Debugf("downloaded revision %d of resource %q", download.Revision, name)
+	c.paths[name] = path
+	return path, nil
+}
+
+// writeResource replaces the contents of the resource directory with
+// the downloaded data, checking that it has the expected fingerprint.
+func writeResource(dir, path string, download *client.Download) error {
+	if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+		return errors.Trace(err)
+	}
+	tempDir, err := ioutil.TempDir(filepath.Dir(dir), ".download-")
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	f, err := os.Create(filepath.Join(tempDir, filepath.Base(path)))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	fr := resource.NewFingerprintReader(download.Data)
+	_, err = io.Copy(f, fr)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if fr.Fingerprint() != download.Fingerprint {
+		return errors.Errorf("fingerprint mismatch")
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(os.Rename(tempDir, dir))
+}
+
+// Flush implements jujuc.ContextComponent. Resources have no data to
+// write back to state.
+func (c *Context) Flush() error {
+	return nil
+}
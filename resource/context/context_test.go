@@ -0,0 +1,117 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package context_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/resource"
+	"github.com/juju/juju/resource/api/client"
+	"github.com/juju/juju/resource/context"
+)
+
+type ContextSuite struct {
+	testing.IsolationSuite
+
+	stub    *testing.Stub
+	api     *fakeAPI
+	dataDir string
+}
+
+var _ = gc.Suite(&ContextSuite{})
+
+func (s *ContextSuite) SetUpTest(c *gc.C) {
+	s.IsolationSuite.SetUpTest(c)
+	s.stub = &testing.Stub{}
+	s.api = &fakeAPI{stub: s.stub, data: "GPLv3", revision: 1}
+	s.dataDir = c.MkDir()
+}
+
+func (s *ContextSuite) TestDownload(c *gc.C) {
+	path, err := context.NewContext(s.api, s.dataDir).Download("licence")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(path, gc.Equals, filepath.Join(s.dataDir, "licence", "licence.txt"))
+	data, err := ioutil.ReadFile(path)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(string(data), gc.Equals, "GPLv3")
+	s.stub.CheckCall(c, 0, "Download", "licence", "")
+}
+
+func (s *ContextSuite) TestDownloadOncePerHook(c *gc.C) {
+	ctx := context.NewContext(s.api, s.dataDir)
+	_, err := ctx.Download("licence")
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = ctx.Download("licence")
+	c.Assert(err, jc.ErrorIsNil)
+	s.stub.CheckCallNames(c, "Download")
+}
+
+func (s *ContextSuite) TestDownloadUnchanged(c *gc.C) {
+	_, err := context.NewContext(s.api, s.dataDir).Download("licence")
+	c.Assert(err, jc.ErrorIsNil)
+
+	path, err := context.NewContext(s.api, s.dataDir).Download("licence")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(path, gc.Equals, filepath.Join(s.dataDir, "licence", "licence.txt"))
+	fingerprint, _, err := resource.Fingerprint(strings.NewReader("GPLv3"))
+	c.Assert(err, jc.ErrorIsNil)
+	s.stub.CheckCall(c, 1, "Download", "licence", fingerprint)
+}
+
+func (s *ContextSuite) TestDownloadNewRevision(c *gc.C) {
+	_, err := context.NewContext(s.api, s.dataDir).Download("licence")
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.api.data = "AGPLv3"
+	s.api.revision = 2
+	path, err := context.NewContext(s.api, s.dataDir).Download("licence")
+	c.Assert(err, jc.ErrorIsNil)
+	data, err := ioutil.ReadFile(path)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(string(data), gc.Equals, "AGPLv3")
+}
+
+func (s *ContextSuite) TestDownloadFingerprintMismatch(c *gc.C) {
+	s.api.badFingerprint = true
+	_, err := context.NewContext(s.api, s.dataDir).Download("licence")
+	c.Check(err, gc.ErrorMatches, `cannot store resource "licence": fingerprint mismatch`)
+}
+
+type fakeAPI struct {
+	stub           *testing.Stub
+	data           string
+	revision       int
+	badFingerprint bool
+}
+
+func (f *fakeAPI) Download(name, fingerprint string) (*client.Download, error) {
+	f.stub.AddCall("Download", name, fingerprint)
+	if err := f.stub.NextErr(); err != nil {
+		return nil, err
+	}
+	current, _, err := resource.Fingerprint(strings.NewReader(f.data))
+	if err != nil {
+		return nil, err
+	}
+	download := &client.Download{
+		Revision:    f.revision,
+		Fingerprint: current,
+		Path:        "licence.txt",
+	}
+	if f.badFingerprint {
+		download.Fingerprint = "bad"
+	}
+	if fingerprint == current {
+		download.Unchanged = true
+		return download, nil
+	}
+	download.Data = ioutil.NopCloser(strings.NewReader(f.data))
+	return download, nil
+}
@@ -0,0 +1,57 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package resource
+
+import (
+	"crypto/sha512"
+	"encoding/hex"
+	"hash"
+	"io"
+	"io/ioutil"
+
+	"github.com/juju/errors"
+)
+
+// FingerprintReader wraps a reader, computing the fingerprint (the
+// hex-encoded SHA-384 hash) and size of the data read through it.
+type FingerprintReader struct {
+	r    io.Reader
+	hash hash.Hash
+	size int64
+}
+
+// NewFingerprintReader returns a FingerprintReader reading from r.
+func NewFingerprintReader(r io.Reader) *FingerprintReader {
+	return &FingerprintReader{
+		r:    r,
+		hash: sha512.New384(),
+	}
+}
+
+// Read implements io.Reader.
+func (f *FingerprintReader) Read(p []byte) (int, error) {
+	n, err := f.r.Read(p)
+	f.hash.Write(p[:n])
+	f.size += int64(n)
+	return n, err
+}
+
+// Fingerprint returns the fingerprint of the data read so far.
+func (f *FingerprintReader) Fingerprint() string {
+	return hex.EncodeToString(f.hash.Sum(nil))
+}
+
+// Size returns the number of bytes read so far.
+func (f *FingerprintReader) Size() int64 {
+	return f.size
+}
+
+// Fingerprint returns the fingerprint and size of the data read from r.
+func Fingerprint(r io.Reader) (string, int64, error) {
+	fr := NewFingerprintReader(r)
+	if _, err := io.Copy(ioutil.Discard, fr); err != nil {
+		return "", 0, errors.Trace(err)
+	}
+	return fr.Fingerprint(), fr.Size(), nil
+}
@@ -0,0 +1,125 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package resource
+
+import (
+	"archive/zip"
+	"io"
+	"io/ioutil"
+	"path"
+	"sort"
+
+	"github.com/juju/errors"
+	goyaml "gopkg.in/yaml.v2"
+)
+
+// TypeFile is the only type of resource currently supported: a single
+// file that is made available to the unit.
+const TypeFile = "file"
+
+// Meta describes a resource declared by a charm.
+type Meta struct {
+	// Name identifies the resource within the charm.
+	Name string
+
+	// Type is the kind of resource.
+	Type string
+
+	// Path is the filename, relative to the unit's resource directory,
+	// where the resource is made available to the unit.
+	Path string
+
+	// Description is a user-facing description of the resource.
+	Description string
+}
+
+// Validate checks the resource metadata to ensure it is correct.
+func (meta Meta) Validate() error {
+	if meta.Name == "" {
+		return errors.NotValidf("resource missing name")
+	}
+	if meta.Type != TypeFile {
+		return errors.NotValidf("resource %q type %q", meta.Name, meta.Type)
+	}
+	if meta.Path == "" {
+		return errors.NotValidf("resource %q missing path", meta.Name)
+	}
+	if path.IsAbs(meta.Path) || path.Clean(meta.Path) != meta.Path || path.Base(meta.Path) != meta.Path {
+		return errors.NotValidf("resource %q path %q", meta.Name, meta.Path)
+	}
+	return nil
+}
+
+type metadataResource struct {
+	Type        string `yaml:"type"`
+	Filename    string `yaml:"filename"`
+	Description string `yaml:"description"`
+}
+
+type metadataResources struct {
+	Resources map[string]metadataResource `yaml:"resources"`
+}
+
+// ParseMeta returns the resources declared in the "resources" section
+// of a charm's metadata.yaml, sorted by name. A resource without a type
+// is a file, and a file without a filename is made available under the
+// resource's name.
+func ParseMeta(metadata []byte) ([]Meta, error) {
+	var raw metadataResources
+	if err := goyaml.Unmarshal(metadata, &raw); err != nil {
+		return nil, errors.Annotate(err, "cannot parse charm metadata")
+	}
+	var metas []Meta
+	for name, res := range raw.Resources {
+		meta := Meta{
+			Name:        name,
+			Type:        res.Type,
+			Path:        res.Filename,
+			Description: res.Description,
+		}
+		if meta.Type == "" {
+			meta.Type = TypeFile
+		}
+		if meta.Path == "" {
+			meta.Path = name
+		}
+		if err := meta.Validate(); err != nil {
+			return nil, errors.Trace(err)
+		}
+		metas = append(metas, meta)
+	}
+	sort.Sort(byName(metas))
+	return metas, nil
+}
+
+// ReadCharmArchiveMeta returns the resources declared by the charm in
+// the given archive.
+func ReadCharmArchiveMeta(r io.ReaderAt, size int64) ([]Meta, error) {
+	zipr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot read charm archive")
+	}
+	for _, f := range zipr.File {
+		if path.Clean(f.Name) != "metadata.yaml" {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		defer rc.Close()
+		data, err := ioutil.ReadAll(rc)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return ParseMeta(data)
+	}
+	return nil, errors.NotFoundf("metadata.yaml in charm archive")
+}
+
+type byName []Meta
+
+func (b byName) Len() int           { return len(b) }
+func (b byName) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+func (b byName) Less(i, j int) bool { return b[i].Name < b[j].Name }
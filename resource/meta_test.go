@@ -0,0 +1,88 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package resource_test
+
+import (
+	"archive/zip"
+	"bytes"
+
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/resource"
+)
+
+type MetaSuite struct {
+	testing.IsolationSuite
+}
+
+var _ = gc.Suite(&MetaSuite{})
+
+const metadataYAML = `
+name: starsay
+summary: a charm with resources
+resources:
+  store-install:
+    type: file
+    filename: store.tgz
+    description: the store installer
+  licence:
+    description: the product licence
+`
+
+func (s *MetaSuite) TestParseMeta(c *gc.C) {
+	metas, err := resource.ParseMeta([]byte(metadataYAML))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(metas, jc.DeepEquals, []resource.Meta{{
+		Name:        "licence",
+		Type:        resource.TypeFile,
+		Path:        "licence",
+		Description: "the product licence",
+	}, {
+		Name:        "store-install",
+		Type:        resource.TypeFile,
+		Path:        "store.tgz",
+		Description: "the store installer",
+	}})
+}
+
+func (s *MetaSuite) TestParseMetaNoResources(c *gc.C) {
+	metas, err := resource.ParseMeta([]byte("name: starsay\n"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(metas, gc.HasLen, 0)
+}
+
+func (s *MetaSuite) TestParseMetaInvalid(c *gc.C) {
+	_, err := resource.ParseMeta([]byte("resources:\n  foo:\n    type: docker\n"))
+	c.Check(err, gc.ErrorMatches, `resource "foo" type "docker" not valid`)
+
+	_, err = resource.ParseMeta([]byte("resources:\n  foo:\n    filename: ../foo\n"))
+	c.Check(err, gc.ErrorMatches, `resource "foo" path "../foo" not valid`)
+}
+
+func (s *MetaSuite) TestReadCharmArchiveMeta(c *gc.C) {
+	var buf bytes.Buffer
+	zipw := zip.NewWriter(&buf)
+	w, err := zipw.Create("metadata.yaml")
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = w.Write([]byte(metadataYAML))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(zipw.Close(), jc.ErrorIsNil)
+
+	metas, err := resource.ReadCharmArchiveMeta(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(metas, gc.HasLen, 2)
+}
+
+func (s *MetaSuite) TestFingerprint(c *gc.C) {
+	fingerprint, size, err := resource.Fingerprint(bytes.NewBufferString("spam"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(size, gc.Equals, int64(4))
+	c.Check(fingerprint, gc.HasLen, 96)
+
+	other, _, err := resource.Fingerprint(bytes.NewBufferString("eggs"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(other, gc.Not(gc.Equals), fingerprint)
+}
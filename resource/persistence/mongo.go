@@ -0,0 +1,127 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package persistence
+
+import (
+	"fmt"
+	"time"
+
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+
+	"github.com/juju/juju/resource"
+)
+
+const (
+	resourcesC = "resources"
+)
+
+// Collections is the list of names of the mongo collections where state
+// is stored for resources.
+var Collections = []string{
+	resourcesC,
+}
+
+// serviceResourceID returns the ID of the document holding the current
+// revision of a service's resource.
+func serviceResourceID(service, name string) string {
+	return fmt.Sprintf("resource#service#%s#%s", service, name)
+}
+
+// unitResourceID returns the ID of the document recording the revision
+// of a resource last downloaded by a unit.
+func unitResourceID(unit, name string) string {
+	return fmt.Sprintf("resource#unit#%s#%s", unit, name)
+}
+
+// resourceDoc is the top-level document for resources. Service
+// documents hold the current revision of a resource along with where
+// its data is stored; unit documents record the revision a unit has.
+type resourceDoc struct {
+	DocID   string `bson:"_id"`
+	EnvUUID string `bson:"env-uuid"`
+
+	Service string `bson:"service"`
+	Unit    string `bson:"unit,omitempty"`
+
+	Name        string `bson:"name"`
+	Type        string `bson:"type"`
+	Path        string `bson:"path"`
+	Description string `bson:"description"`
+
+	Revision    int       `bson:"revision"`
+	Fingerprint string    `bson:"fingerprint"`
+	Size        int64     `bson:"size"`
+	Username    string    `bson:"username"`
+	Timestamp   time.Time `bson:"timestamp"`
+
+	StoragePath string `bson:"storage-path,omitempty"`
+}
+
+func newResourceDoc(id string, res resource.Resource) *resourceDoc {
+	return &resourceDoc{
+		DocID:       id,
+		Service:     res.Service,
+		Name:        res.Name,
+		Type:        res.Type,
+		Path:        res.Path,
+		Description: res.Description,
+		Revision:    res.Revision,
+		Fingerprint: res.Fingerprint,
+		Size:        res.Size,
+		Username:    res.Username,
+		Timestamp:   res.Timestamp.UTC(),
+	}
+}
+
+func (doc resourceDoc) resource() resource.Resource {
+	return resource.Resource{
+		Meta: resource.Meta{
+			Name:        doc.Name,
+			Type:        doc.Type,
+			Path:        doc.Path,
+			Description: doc.Description,
+		},
+		Service:     doc.Service,
+		Revision:    doc.Revision,
+		Fingerprint: doc.Fingerprint,
+		Size:        doc.Size,
+		Username:    doc.Username,
+		Timestamp:   doc.Timestamp,
+	}
+}
+
+// resourceUpdates returns the fields of the document to be updated when
+// a new revision of the resource is set.
+func (doc resourceDoc) updates() bson.D {
+	return bson.D{
+		{"type", doc.Type},
+		{"path", doc.Path},
+		{"description", doc.Description},
+		{"revision", doc.Revision},
+		{"fingerprint", doc.Fingerprint},
+		{"size", doc.Size},
+		{"username", doc.Username},
+		{"timestamp", doc.Timestamp},
+		{"storage-path", doc.StoragePath},
+	}
+}
+
+func newInsertResourceOps(doc *resourceDoc) []txn.Op {
+	return []txn.Op{{
+		C:      resourcesC,
+		Id:     doc.DocID,
+		Assert: txn.DocMissing,
+		Insert: doc,
+	}}
+}
+
+func newUpdateResourceOps(doc *resourceDoc, assert interface{}) []txn.Op {
+	return []txn.Op{{
+		C:      resourcesC,
+		Id:     doc.DocID,
+		Assert: assert,
+		Update: bson.D{{"$set", doc.updates()}},
+	}}
+}
@@ -0,0 +1,228 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package persistence
+
+import (
+	"sort"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	jujutxn "github.com/juju/txn"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+
+	"github.com/juju/juju/resource"
+)
+
+var logger = loggo.GetLogger("juju.resource.persistence")
+
+// PersistenceBase exposes the core persistence functionality needed
+// for resources.
+type PersistenceBase interface {
+	// One populates doc with the document corresponding to the given
+	// ID. Missing documents result in errors.NotFound.
+	One(collName, id string, doc interface{}) error
+	// All populates docs with the list of the documents corresponding
+	// to the provided query.
+	All(collName string, query, docs interface{}) error
+	// Run runs the transaction generated by the provided factory
+	// function. It may be retried several times.
+	Run(transactions jujutxn.TransactionSource) error
+	// NewCleanupOp returns an operation that schedules a cleanup of
+	// the given kind.
+	NewCleanupOp(kind, prefix string) txn.Op
+}
+
+// CleanupKindResourceBlob is the kind of cleanup that removes the data
+// of a resource revision once nothing refers to it.
+const CleanupKindResourceBlob = "resourceBlob"
+
+// Persistence exposes the high-level persistence functionality
+// related to resources in Juju.
+type Persistence struct {
+	base PersistenceBase
+}
+
+// NewPersistence builds a new Persistence based on the provided info.
+func NewPersistence(base PersistenceBase) *Persistence {
+	return &Persistence{
+		base: base,
+	}
+}
+
+// ListResources returns the resources of the service held in
+// persistence, along with the revisions its units have downloaded.
+// Resources the charm declares that have never been pushed are not
+// included.
+func (p Persistence) ListResources(service string) (resource.ServiceResources, error) {
+	logger.Tracef("listing resources for %q", service)
+
+	var docs []resourceDoc
+	query := bson.D{{"service", service}}
+	if err := p.base.All(resourcesC, query, &docs); err != nil {
+		return resource.ServiceResources{}, errors.Trace(err)
+	}
+
+	var results resource.ServiceResources
+	units := make(map[string][]resource.Resource)
+	for _, doc := range docs {
+		if doc.Unit == "" {
+			results.Resources = append(results.Resources, doc.resource())
+			continue
+		}
+		units[doc.Unit] = append(units[doc.Unit], doc.resource())
+	}
+	var unitNames []string
+	for unit := range units {
+		unitNames = append(unitNames, unit)
+	}
+	sort.Strings(unitNames)
+	for _, unit := range unitNames {
+		results.UnitResources = append(results.UnitResources, resource.UnitResources{
+			Unit:      unit,
+			Resources: units[unit],
+		})
+	}
+	return results, nil
+}
+
+// GetResource returns the current revision of the service's resource,
+// along with the path in storage where its data is held.
+func (p Persistence) GetResource(service, name string) (resource.Resource, string, error) {
+	var doc resourceDoc
+	if err := p.base.One(resourcesC, serviceResourceID(service, name), &doc); err != nil {
+		if errors.IsNotFound(err) {
+			err = errors.NotFoundf("resource %q of service %q", name, service)
+		}
+		return resource.Resource{}, "", err
+	}
+	return doc.resource(), doc.StoragePath, nil
+}
+
+// SetResource records a new revision of the service's resource, with
+// its data held at the given path in storage. The revision must follow
+// on directly from the one currently in persistence, whose data is held
+// at previousPath; if another has been set concurrently, the call
+// fails. The previous data is removed by a cleanup once no unit refers
+// to it.
+func (p Persistence) SetResource(res resource.Resource, storagePath, previousPath string) error {
+	logger.Tracef("setting resource %q of %q to revision %d", res.Name, res.Service, res.Revision)
+
+	doc := newResourceDoc(serviceResourceID(res.Service, res.Name), res)
+	doc.StoragePath = storagePath
+
+	var ops []txn.Op
+	if res.Revision == 1 {
+		ops = newInsertResourceOps(doc)
+	} else {
+		ops = newUpdateResourceOps(doc, bson.D{{"revision", res.Revision - 1}})
+	}
+	if previousPath != "" {
+		ops = append(ops, p.base.NewCleanupOp(CleanupKindResourceBlob, previousPath))
+	}
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if attempt > 0 {
+			return nil, errors.Errorf("resource %q of service %q changed concurrently", res.Name, res.Service)
+		}
+		return ops, nil
+	}
+	if err := p.base.Run(buildTxn); err != nil {
+		return errors.Trace(err)
+	}
+	return nil
+}
+
+// SetUnitResource records that the unit has downloaded the given
+// revision of a resource, which must be the service's current one. The
+// unit's record refers to the revision's data, so that the data is
+// kept while the unit may still be using it.
+func (p Persistence) SetUnitResource(unit string, res resource.Resource) error {
+	logger.Tracef("setting resource %q of unit %q to revision %d", res.Name, unit, res.Revision)
+
+	serviceID := serviceResourceID(res.Service, res.Name)
+	unitID := unitResourceID(unit, res.Name)
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		var current resourceDoc
+		if err := p.base.One(resourcesC, serviceID, &current); err != nil {
+			return nil, errors.Trace(err)
+		}
+		if current.Revision != res.Revision {
+			return nil, errors.Errorf("resource %q of service %q changed concurrently", res.Name, res.Service)
+		}
+		doc := newResourceDoc(unitID, res)
+		doc.Unit = unit
+		doc.StoragePath = current.StoragePath
+		ops := []txn.Op{{
+			C:      resourcesC,
+			Id:     serviceID,
+			Assert: bson.D{{"revision", res.Revision}},
+		}}
+
+		var previous resourceDoc
+		err := p.base.One(resourcesC, unitID, &previous)
+		if errors.IsNotFound(err) {
+			return append(ops, newInsertResourceOps(doc)...), nil
+		} else if err != nil {
+			return nil, errors.Trace(err)
+		}
+		ops = append(ops, newUpdateResourceOps(doc, bson.D{{"revision", previous.Revision}})...)
+		if previous.StoragePath != "" && previous.StoragePath != doc.StoragePath {
+			ops = append(ops, p.base.NewCleanupOp(CleanupKindResourceBlob, previous.StoragePath))
+		}
+		return ops, nil
+	}
+	if err := p.base.Run(buildTxn); err != nil {
+		return errors.Trace(err)
+	}
+	return nil
+}
+
+// NewRemoveResourcesOps returns the operations that remove the
+// resources of the service and its units, along with cleanups that
+// remove their data.
+func (p Persistence) NewRemoveResourcesOps(service string) ([]txn.Op, error) {
+	var docs []resourceDoc
+	if err := p.base.All(resourcesC, bson.D{{"service", service}}, &docs); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return p.newRemoveResourceDocsOps(docs), nil
+}
+
+// NewRemoveUnitResourcesOps returns the operations that remove the
+// records of the resources the unit has, along with cleanups that
+// remove any data no longer referred to.
+func (p Persistence) NewRemoveUnitResourcesOps(unit string) ([]txn.Op, error) {
+	var docs []resourceDoc
+	if err := p.base.All(resourcesC, bson.D{{"unit", unit}}, &docs); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return p.newRemoveResourceDocsOps(docs), nil
+}
+
+func (p Persistence) newRemoveResourceDocsOps(docs []resourceDoc) []txn.Op {
+	var ops []txn.Op
+	paths := make(map[string]bool)
+	for _, doc := range docs {
+		ops = append(ops, txn.Op{
+			C:      resourcesC,
+			Id:     doc.DocID,
+			Remove: true,
+		})
+		if doc.StoragePath != "" && !paths[doc.StoragePath] {
+			paths[doc.StoragePath] = true
+			ops = append(ops, p.base.NewCleanupOp(CleanupKindResourceBlob, doc.StoragePath))
+		}
+	}
+	return ops
+}
+
+// StoragePathInUse reports whether a revision held by a service or unit
+// refers to the data at the given path in storage.
+func (p Persistence) StoragePathInUse(storagePath string) (bool, error) {
+	var docs []resourceDoc
+	if err := p.base.All(resourcesC, bson.D{{"storage-path", storagePath}}, &docs); err != nil {
+		return false, errors.Trace(err)
+	}
+	return len(docs) > 0, nil
+}
@@ -0,0 +1,260 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package persistence
+
+import (
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	jujutxn "github.com/juju/txn"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+
+	"github.com/juju/juju/resource"
+)
+
+type PersistenceSuite struct {
+	testing.IsolationSuite
+
+	stub *testing.Stub
+	base *fakeBase
+}
+
+var _ = gc.Suite(&PersistenceSuite{})
+
+func (s *PersistenceSuite) SetUpTest(c *gc.C) {
+	s.IsolationSuite.SetUpTest(c)
+	s.stub = &testing.Stub{}
+	s.base = &fakeBase{Stub: s.stub}
+}
+
+func newResource(revision int) resource.Resource {
+	return resource.Resource{
+		Meta: resource.Meta{
+			Name: "licence",
+			Type: resource.TypeFile,
+			Path: "licence.txt",
+		},
+		Service:     "starsay",
+		Revision:    revision,
+		Fingerprint: strings.Repeat("a", 96),
+		Size:        10,
+		Username:    "bob",
+		Timestamp:   time.Date(2015, 11, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func (s *PersistenceSuite) TestListResources(c *gc.C) {
+	service := newResourceDoc(serviceResourceID("starsay", "licence"), newResource(2))
+	unit := newResourceDoc(unitResourceID("starsay/0", "licence"), newResource(1))
+	unit.Unit = "starsay/0"
+	s.base.docs = []resourceDoc{*service, *unit}
+
+	resources, err := NewPersistence(s.base).ListResources("starsay")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(resources, jc.DeepEquals, resource.ServiceResources{
+		Resources: []resource.Resource{newResource(2)},
+		UnitResources: []resource.UnitResources{{
+			Unit:      "starsay/0",
+			Resources: []resource.Resource{newResource(1)},
+		}},
+	})
+	s.stub.CheckCall(c, 0, "All", "resources", bson.D{{"service", "starsay"}})
+}
+
+func (s *PersistenceSuite) TestGetResourceNotFound(c *gc.C) {
+	_, _, err := NewPersistence(s.base).GetResource("starsay", "licence")
+	c.Check(err, jc.Satisfies, errors.IsNotFound)
+	c.Check(err, gc.ErrorMatches, `resource "licence" of service "starsay" not found`)
+}
+
+func (s *PersistenceSuite) TestSetResourceFirstRevision(c *gc.C) {
+	err := NewPersistence(s.base).SetResource(newResource(1), "resources/starsay/licence/1", "")
+	c.Assert(err, jc.ErrorIsNil)
+
+	doc := newResourceDoc("resource#service#starsay#licence", newResource(1))
+	doc.StoragePath = "resources/starsay/licence/1"
+	c.Check(s.base.ops, jc.DeepEquals, [][]txn.Op{{{
+		C:      "resources",
+		Id:     "resource#service#starsay#licence",
+		Assert: txn.DocMissing,
+		Insert: doc,
+	}}})
+}
+
+func (s *PersistenceSuite) TestSetResourceNextRevision(c *gc.C) {
+	err := NewPersistence(s.base).SetResource(newResource(3), "path", "old-path")
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Assert(s.base.ops, gc.HasLen, 1)
+	c.Assert(s.base.ops[0], gc.HasLen, 2)
+	c.Check(s.base.ops[0][0].Assert, jc.DeepEquals, bson.D{{"revision", 2}})
+	c.Check(s.base.ops[0][1], jc.DeepEquals, newFakeCleanupOp("resourceBlob", "old-path"))
+}
+
+func (s *PersistenceSuite) TestSetResourceConcurrent(c *gc.C) {
+	s.stub.SetErrors(txn.ErrAborted)
+	err := NewPersistence(s.base).SetResource(newResource(3), "path", "old-path")
+	c.Check(err, gc.ErrorMatches, `resource "licence" of service "starsay" changed concurrently`)
+}
+
+func (s *PersistenceSuite) setServiceResource(revision int, storagePath string) {
+	doc := newResourceDoc(serviceResourceID("starsay", "licence"), newResource(revision))
+	doc.StoragePath = storagePath
+	s.base.docs = append(s.base.docs, *doc)
+}
+
+func (s *PersistenceSuite) setUnitResource(revision int, storagePath string) {
+	doc := newResourceDoc(unitResourceID("starsay/0", "licence"), newResource(revision))
+	doc.Unit = "starsay/0"
+	doc.StoragePath = storagePath
+	s.base.docs = append(s.base.docs, *doc)
+}
+
+func (s *PersistenceSuite) TestSetUnitResourceFirst(c *gc.C) {
+	s.setServiceResource(2, "path-2")
+
+	err := NewPersistence(s.base).SetUnitResource("starsay/0", newResource(2))
+	c.Assert(err, jc.ErrorIsNil)
+
+	doc := newResourceDoc("resource#unit#starsay/0#licence", newResource(2))
+	doc.Unit = "starsay/0"
+	doc.StoragePath = "path-2"
+	c.Check(s.base.ops, jc.DeepEquals, [][]txn.Op{{{
+		C:      "resources",
+		Id:     "resource#service#starsay#licence",
+		Assert: bson.D{{"revision", 2}},
+	}, {
+		C:      "resources",
+		Id:     "resource#unit#starsay/0#licence",
+		Assert: txn.DocMissing,
+		Insert: doc,
+	}}})
+}
+
+func (s *PersistenceSuite) TestSetUnitResourceExisting(c *gc.C) {
+	s.setServiceResource(2, "path-2")
+	s.setUnitResource(1, "path-1")
+
+	err := NewPersistence(s.base).SetUnitResource("starsay/0", newResource(2))
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Assert(s.base.ops, gc.HasLen, 1)
+	ops := s.base.ops[0]
+	c.Assert(ops, gc.HasLen, 3)
+	c.Check(ops[1].Id, gc.Equals, "resource#unit#starsay/0#licence")
+	c.Check(ops[1].Assert, jc.DeepEquals, bson.D{{"revision", 1}})
+	c.Check(ops[2], jc.DeepEquals, newFakeCleanupOp("resourceBlob", "path-1"))
+}
+
+func (s *PersistenceSuite) TestSetUnitResourceNotCurrent(c *gc.C) {
+	s.setServiceResource(3, "path-3")
+
+	err := NewPersistence(s.base).SetUnitResource("starsay/0", newResource(2))
+	c.Check(err, gc.ErrorMatches, `resource "licence" of service "starsay" changed concurrently`)
+	c.Check(s.base.ops, gc.HasLen, 0)
+}
+
+func (s *PersistenceSuite) TestNewRemoveResourcesOps(c *gc.C) {
+	s.setServiceResource(2, "path-2")
+	s.setUnitResource(2, "path-2")
+
+	ops, err := NewPersistence(s.base).NewRemoveResourcesOps("starsay")
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.stub.CheckCall(c, 0, "All", "resources", bson.D{{"service", "starsay"}})
+	c.Check(ops, jc.DeepEquals, []txn.Op{
+		{C: "resources", Id: "resource#service#starsay#licence", Remove: true},
+		newFakeCleanupOp("resourceBlob", "path-2"),
+		{C: "resources", Id: "resource#unit#starsay/0#licence", Remove: true},
+	})
+}
+
+func (s *PersistenceSuite) TestNewRemoveUnitResourcesOps(c *gc.C) {
+	s.setUnitResource(1, "path-1")
+
+	ops, err := NewPersistence(s.base).NewRemoveUnitResourcesOps("starsay/0")
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.stub.CheckCall(c, 0, "All", "resources", bson.D{{"unit", "starsay/0"}})
+	c.Check(ops, jc.DeepEquals, []txn.Op{
+		{C: "resources", Id: "resource#unit#starsay/0#licence", Remove: true},
+		newFakeCleanupOp("resourceBlob", "path-1"),
+	})
+}
+
+func (s *PersistenceSuite) TestStoragePathInUse(c *gc.C) {
+	s.setUnitResource(1, "path-1")
+
+	inUse, err := NewPersistence(s.base).StoragePathInUse("path-1")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(inUse, jc.IsTrue)
+	s.stub.CheckCall(c, 0, "All", "resources", bson.D{{"storage-path", "path-1"}})
+
+	s.base.docs = nil
+	inUse, err = NewPersistence(s.base).StoragePathInUse("path-1")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(inUse, jc.IsFalse)
+}
+
+type fakeBase struct {
+	*testing.Stub
+
+	docs []resourceDoc
+	ops  [][]txn.Op
+}
+
+func (f *fakeBase) One(collName, id string, doc interface{}) error {
+	f.AddCall("One", collName, id)
+	if err := f.NextErr(); err != nil {
+		return err
+	}
+	for _, found := range f.docs {
+		if found.DocID == id {
+			*doc.(*resourceDoc) = found
+			return nil
+		}
+	}
+	return errors.NotFoundf(id)
+}
+
+func (f *fakeBase) All(collName string, query, docs interface{}) error {
+	f.AddCall("All", collName, query)
+	if err := f.NextErr(); err != nil {
+		return err
+	}
+	*docs.(*[]resourceDoc) = f.docs
+	return nil
+}
+
+func (f *fakeBase) Run(transactions jujutxn.TransactionSource) error {
+	for i := 0; i < 3; i++ {
+		ops, err := transactions(i)
+		if err != nil {
+			return err
+		}
+		f.ops = append(f.ops, ops)
+		if err := f.NextErr(); err != txn.ErrAborted {
+			return err
+		}
+	}
+	return jujutxn.ErrExcessiveContention
+}
+
+func (f *fakeBase) NewCleanupOp(kind, prefix string) txn.Op {
+	f.AddCall("NewCleanupOp", kind, prefix)
+	return newFakeCleanupOp(kind, prefix)
+}
+
+func newFakeCleanupOp(kind, prefix string) txn.Op {
+	return txn.Op{
+		C:      "cleanups",
+		Id:     kind + ":" + prefix,
+		Insert: bson.D{{"kind", kind}, {"prefix", prefix}},
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package resource
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+)
+
+// Resource is a revision of a charm resource held for a service.
+type Resource struct {
+	Meta
+
+	// Service is the name of the service that holds the resource.
+	Service string
+
+	// Revision is incremented each time the resource is pushed. A
+	// revision of 0 means that no data has been pushed yet.
+	Revision int
+
+	// Fingerprint is the hex-encoded SHA-384 hash of the data.
+	Fingerprint string
+
+	// Size is the length of the data in bytes.
+	Size int64
+
+	// Username is the name of the user who pushed the revision.
+	Username string
+
+	// Timestamp is when the revision was pushed.
+	Timestamp time.Time
+}
+
+// IsPlaceholder reports whether no data has been pushed for the
+// resource yet.
+func (res Resource) IsPlaceholder() bool {
+	return res.Revision == 0
+}
+
+// Validate checks the resource info to ensure it is correct.
+func (res Resource) Validate() error {
+	if err := res.Meta.Validate(); err != nil {
+		return errors.Trace(err)
+	}
+	if !names.IsValidService(res.Service) {
+		return errors.NotValidf("resource %q service %q", res.Name, res.Service)
+	}
+	if res.Revision < 0 {
+		return errors.NotValidf("resource %q revision %d", res.Name, res.Revision)
+	}
+	if res.IsPlaceholder() {
+		return nil
+	}
+	if len(res.Fingerprint) != 2*48 {
+		return errors.NotValidf("resource %q fingerprint %q", res.Name, res.Fingerprint)
+	}
+	if res.Size < 0 {
+		return errors.NotValidf("resource %q size %d", res.Name, res.Size)
+	}
+	if res.Username == "" {
+		return errors.NotValidf("resource %q missing username", res.Name)
+	}
+	return nil
+}
+
+// UnitResources holds the resource revisions last downloaded by a unit.
+type UnitResources struct {
+	// Unit is the name of the unit.
+	Unit string
+
+	// Resources are the revisions of the resources the unit has.
+	Resources []Resource
+}
+
+// ServiceResources holds the resources of a service, along with the
+// revisions of them that each of its units has.
+type ServiceResources struct {
+	// Resources are the current revisions of the service's resources,
+	// including those declared by the charm but not yet pushed.
+	Resources []Resource
+
+	// UnitResources are the revisions downloaded by each unit.
+	UnitResources []UnitResources
+}
@@ -0,0 +1,75 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package resource_test
+
+import (
+	"strings"
+	"time"
+
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/resource"
+)
+
+type ResourceSuite struct {
+	testing.IsolationSuite
+}
+
+var _ = gc.Suite(&ResourceSuite{})
+
+func newResource() resource.Resource {
+	return resource.Resource{
+		Meta: resource.Meta{
+			Name: "licence",
+			Type: resource.TypeFile,
+			Path: "licence.txt",
+		},
+		Service:     "starsay",
+		Revision:    1,
+		Fingerprint: strings.Repeat("a", 96),
+		Size:        10,
+		Username:    "bob",
+		Timestamp:   time.Now(),
+	}
+}
+
+func (s *ResourceSuite) TestValidate(c *gc.C) {
+	c.Check(newResource().Validate(), jc.ErrorIsNil)
+
+	placeholder := resource.Resource{
+		Meta:    newResource().Meta,
+		Service: "starsay",
+	}
+	c.Check(placeholder.IsPlaceholder(), jc.IsTrue)
+	c.Check(placeholder.Validate(), jc.ErrorIsNil)
+}
+
+func (s *ResourceSuite) TestValidateInvalid(c *gc.C) {
+	for i, test := range []struct {
+		modify func(*resource.Resource)
+		err    string
+	}{{
+		modify: func(res *resource.Resource) { res.Service = "" },
+		err:    `resource "licence" service "" not valid`,
+	}, {
+		modify: func(res *resource.Resource) { res.Revision = -1 },
+		err:    `resource "licence" revision -1 not valid`,
+	}, {
+		modify: func(res *resource.Resource) { res.Fingerprint = "abc" },
+		err:    `resource "licence" fingerprint "abc" not valid`,
+	}, {
+		modify: func(res *resource.Resource) { res.Username = "" },
+		err:    `resource "licence" missing username not valid`,
+	}, {
+		modify: func(res *resource.Resource) { res.Path = "/etc/passwd" },
+		err:    `resource "licence" path "/etc/passwd" not valid`,
+	}} {
+		c.Logf("test %d", i)
+		res := newResource()
+		test.modify(&res)
+		c.Check(res.Validate(), gc.ErrorMatches, test.err)
+	}
+}
@@ -0,0 +1,245 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"github.com/juju/utils"
+	"gopkg.in/mgo.v2/txn"
+
+	"github.com/juju/juju/resource"
+)
+
+var logger = loggo.GetLogger("juju.resource.state")
+
+// Persistence is the state persistence functionality needed for resources.
+type Persistence interface {
+	// ListResources returns the resources of the service held in
+	// persistence, along with the revisions its units have.
+	ListResources(service string) (resource.ServiceResources, error)
+	// GetResource returns the current revision of the service's
+	// resource and the path in storage where its data is held.
+	GetResource(service, name string) (resource.Resource, string, error)
+	// SetResource records a new revision of a service's resource,
+	// replacing the revision whose data is held at previousPath.
+	SetResource(res resource.Resource, storagePath, previousPath string) error
+	// SetUnitResource records the revision of a resource a unit has.
+	SetUnitResource(unit string, res resource.Resource) error
+	// NewRemoveResourcesOps returns the operations that remove the
+	// resources of the service and its units.
+	NewRemoveResourcesOps(service string) ([]txn.Op, error)
+	// NewRemoveUnitResourcesOps returns the operations that remove
+	// the records of the resources a unit has.
+	NewRemoveUnitResourcesOps(unit string) ([]txn.Op, error)
+	// StoragePathInUse reports whether any revision refers to the
+	// data at the path in storage.
+	StoragePathInUse(storagePath string) (bool, error)
+}
+
+// Storage is the blob storage functionality needed for resources.
+type Storage interface {
+	// Get returns the data stored at the path.
+	Get(path string) (io.ReadCloser, int64, error)
+	// Put stores the data read from r at the path.
+	Put(path string, r io.Reader, length int64) error
+	// Remove removes the data stored at the path.
+	Remove(path string) error
+}
+
+// Resources provides the functionality of the resources component in
+// state.
+type Resources struct {
+	// Persist is the persistence layer for resource records.
+	Persist Persistence
+
+	// Storage holds the resource data.
+	Storage Storage
+
+	// CharmMeta returns the resources declared by the charm of the
+	// named service.
+	CharmMeta func(service string) ([]resource.Meta, error)
+
+	// NewStoragePath returns a new path in storage for the resource
+	// revision's data.
+	NewStoragePath func(res resource.Resource) (string, error)
+
+	// CurrentTimestamp returns the time to record for new revisions.
+	CurrentTimestamp func() time.Time
+}
+
+// NewResources returns a Resources with the given persistence,
+// storage and charm metadata lookup.
+func NewResources(persist Persistence, storage Storage, charmMeta func(string) ([]resource.Meta, error)) *Resources {
+	return &Resources{
+		Persist:          persist,
+		Storage:          storage,
+		CharmMeta:        charmMeta,
+		NewStoragePath:   newStoragePath,
+		CurrentTimestamp: time.Now,
+	}
+}
+
+func newStoragePath(res resource.Resource) (string, error) {
+	uuid, err := utils.NewUUID()
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	return fmt.Sprintf("resources/%s/%s/%s", res.Service, res.Name, uuid), nil
+}
+
+// ListResources returns the resources declared by the service's charm,
+// at their current revisions, along with the revisions each of the
+// service's units has downloaded.
+func (r Resources) ListResources(service string) (resource.ServiceResources, error) {
+	metas, err := r.CharmMeta(service)
+	if err != nil {
+		return resource.ServiceResources{}, errors.Trace(err)
+	}
+	stored, err := r.Persist.ListResources(service)
+	if err != nil {
+		return resource.ServiceResources{}, errors.Trace(err)
+	}
+
+	current := make(map[string]resource.Resource)
+	for _, res := range stored.Resources {
+		current[res.Name] = res
+	}
+	results := resource.ServiceResources{
+		UnitResources: stored.UnitResources,
+	}
+	for _, meta := range metas {
+		res, ok := current[meta.Name]
+		if !ok {
+			res = resource.Resource{Service: service}
+		}
+		res.Meta = meta
+		results.Resources = append(results.Resources, res)
+	}
+	return results, nil
+}
+
+// SetResource stores the data read from r as a new revision of the
+// named resource of the service. The data must have the given size and
+// fingerprint, and the resource must be declared by the service's charm.
+func (r Resources) SetResource(service, name, username string, data io.Reader, size int64, fingerprint string) (resource.Resource, error) {
+	metas, err := r.CharmMeta(service)
+	if err != nil {
+		return resource.Resource{}, errors.Trace(err)
+	}
+	res := resource.Resource{Service: service}
+	for _, meta := range metas {
+		if meta.Name == name {
+			res.Meta = meta
+			break
+		}
+	}
+	if res.Name == "" {
+		return resource.Resource{}, errors.NotFoundf("resource %q in charm of service %q", name, service)
+	}
+
+	current, oldPath, err := r.Persist.GetResource(service, name)
+	if errors.IsNotFound(err) {
+		current = resource.Resource{}
+	} else if err != nil {
+		return resource.Resource{}, errors.Trace(err)
+	}
+	res.Revision = current.Revision + 1
+	res.Fingerprint = fingerprint
+	res.Size = size
+	res.Username = username
+	res.Timestamp = r.CurrentTimestamp().UTC()
+	if err := res.Validate(); err != nil {
+		return resource.Resource{}, errors.Trace(err)
+	}
+
+	path, err := r.NewStoragePath(res)
+	if err != nil {
+		return resource.Resource{}, errors.Trace(err)
+	}
+	fr := resource.NewFingerprintReader(data)
+	if err := r.Storage.Put(path, fr, size); err != nil {
+		return resource.Resource{}, errors.Annotate(err, "cannot store resource")
+	}
+	if fr.Fingerprint() != fingerprint || fr.Size() != size {
+		r.removeData(path)
+		return resource.Resource{}, errors.NotValidf("resource %q data (fingerprint or size mismatch)", name)
+	}
+	// The data of the previous revision is left for a cleanup to
+	// remove once no unit is using it.
+	if err := r.Persist.SetResource(res, path, oldPath); err != nil {
+		r.removeData(path)
+		return resource.Resource{}, errors.Trace(err)
+	}
+	return res, nil
+}
+
+func (r Resources) removeData(path string) {
+	if err := r.Storage.Remove(path); err != nil {
+		logger.Errorf("cannot remove resource data at %q: %v", path, err)
+	}
+}
+
+// OpenResource returns the current revision of the named resource of
+// the service, along with a reader for its data. The caller must close
+// the reader.
+func (r Resources) OpenResource(service, name string) (resource.Resource, io.ReadCloser, error) {
+	res, path, err := r.Persist.GetResource(service, name)
+	if err != nil {
+		return resource.Resource{}, nil, errors.Trace(err)
+	}
+	data, size, err := r.Storage.Get(path)
+	if err != nil {
+		return resource.Resource{}, nil, errors.Annotate(err, "cannot read resource")
+	}
+	if size != res.Size {
+		data.Close()
+		return resource.Resource{}, nil, errors.Errorf("resource %q data has size %d, expected %d", name, size, res.Size)
+	}
+	return res, data, nil
+}
+
+// SetUnitResource records that the unit has downloaded the given
+// revision of a resource.
+func (r Resources) SetUnitResource(unit string, res resource.Resource) error {
+	if err := res.Validate(); err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(r.Persist.SetUnitResource(unit, res))
+}
+
+// NewRemoveResourcesOps returns the operations that remove the
+// resources of the service and its units.
+func (r Resources) NewRemoveResourcesOps(service string) ([]txn.Op, error) {
+	ops, err := r.Persist.NewRemoveResourcesOps(service)
+	return ops, errors.Trace(err)
+}
+
+// NewRemoveUnitResourcesOps returns the operations that remove the
+// records of the resources the unit has.
+func (r Resources) NewRemoveUnitResourcesOps(unit string) ([]txn.Op, error) {
+	ops, err := r.Persist.NewRemoveUnitResourcesOps(unit)
+	return ops, errors.Trace(err)
+}
+
+// RemoveUnusedData removes the resource data stored at the path,
+// unless a revision held by a service or unit still refers to it.
+func (r Resources) RemoveUnusedData(storagePath string) error {
+	inUse, err := r.Persist.StoragePathInUse(storagePath)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if inUse {
+		logger.Debugf("keeping resource data at %q, which is still in use", storagePath)
+		return nil
+	}
+	if err := r.Storage.Remove(storagePath); err != nil && !errors.IsNotFound(err) {
+		return errors.Annotatef(err, "cannot remove resource data at %q", storagePath)
+	}
+	return nil
+}
@@ -0,0 +1,222 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/mgo.v2/txn"
+
+	"github.com/juju/juju/resource"
+	"github.com/juju/juju/resource/state"
+)
+
+type ResourcesSuite struct {
+	testing.IsolationSuite
+
+	persist   *fakePersistence
+	storage   *fakeStorage
+	resources *state.Resources
+	now       time.Time
+}
+
+var _ = gc.Suite(&ResourcesSuite{})
+
+var licenceMeta = resource.Meta{
+	Name: "licence",
+	Type: resource.TypeFile,
+	Path: "licence.txt",
+}
+
+func (s *ResourcesSuite) SetUpTest(c *gc.C) {
+	s.IsolationSuite.SetUpTest(c)
+	s.persist = &fakePersistence{resources: make(map[string]resource.Resource)}
+	s.storage = &fakeStorage{data: make(map[string][]byte)}
+	s.now = time.Date(2015, 11, 1, 0, 0, 0, 0, time.UTC)
+	s.resources = state.NewResources(s.persist, s.storage, func(service string) ([]resource.Meta, error) {
+		if service != "starsay" {
+			return nil, errors.NotFoundf("service %q", service)
+		}
+		return []resource.Meta{licenceMeta}, nil
+	})
+	paths := 0
+	s.resources.NewStoragePath = func(res resource.Resource) (string, error) {
+		paths++
+		return fmt.Sprintf("%s%d", res.Name, paths), nil
+	}
+	s.resources.CurrentTimestamp = func() time.Time { return s.now }
+}
+
+func (s *ResourcesSuite) push(c *gc.C, data string) (resource.Resource, error) {
+	fingerprint, size, err := resource.Fingerprint(bytes.NewBufferString(data))
+	c.Assert(err, jc.ErrorIsNil)
+	return s.resources.SetResource("starsay", "licence", "bob", bytes.NewBufferString(data), size, fingerprint)
+}
+
+func (s *ResourcesSuite) TestListResourcesPlaceholder(c *gc.C) {
+	resources, err := s.resources.ListResources("starsay")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(resources, jc.DeepEquals, resource.ServiceResources{
+		Resources: []resource.Resource{{Meta: licenceMeta, Service: "starsay"}},
+	})
+}
+
+func (s *ResourcesSuite) TestSetResource(c *gc.C) {
+	res, err := s.push(c, "GPLv3")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(res.Revision, gc.Equals, 1)
+	c.Check(res.Username, gc.Equals, "bob")
+	c.Check(res.Timestamp, gc.Equals, s.now)
+	c.Check(s.storage.data, jc.DeepEquals, map[string][]byte{"licence1": []byte("GPLv3")})
+
+	res, err = s.push(c, "AGPLv3")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(res.Revision, gc.Equals, 2)
+	// The data of the previous revision is left for a cleanup.
+	c.Check(s.storage.data, jc.DeepEquals, map[string][]byte{
+		"licence1": []byte("GPLv3"),
+		"licence2": []byte("AGPLv3"),
+	})
+	c.Check(s.persist.previousPath, gc.Equals, "licence1")
+
+	resources, err := s.resources.ListResources("starsay")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(resources.Resources, jc.DeepEquals, []resource.Resource{res})
+}
+
+func (s *ResourcesSuite) TestSetResourceFingerprintMismatch(c *gc.C) {
+	fingerprint, _, err := resource.Fingerprint(bytes.NewBufferString("GPLv3"))
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.resources.SetResource("starsay", "licence", "bob", bytes.NewBufferString("BSD"), 5, fingerprint)
+	c.Check(err, gc.ErrorMatches, `resource "licence" data \(fingerprint or size mismatch\) not valid`)
+	c.Check(s.storage.data, gc.HasLen, 0)
+	c.Check(s.persist.resources, gc.HasLen, 0)
+}
+
+func (s *ResourcesSuite) TestSetResourceUndeclared(c *gc.C) {
+	_, err := s.resources.SetResource("starsay", "docs", "bob", &bytes.Buffer{}, 0, "")
+	c.Check(err, jc.Satisfies, errors.IsNotFound)
+	c.Check(err, gc.ErrorMatches, `resource "docs" in charm of service "starsay" not found`)
+}
+
+func (s *ResourcesSuite) TestOpenResource(c *gc.C) {
+	pushed, err := s.push(c, "GPLv3")
+	c.Assert(err, jc.ErrorIsNil)
+
+	res, data, err := s.resources.OpenResource("starsay", "licence")
+	c.Assert(err, jc.ErrorIsNil)
+	defer data.Close()
+	c.Check(res, jc.DeepEquals, pushed)
+	content, err := ioutil.ReadAll(data)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(string(content), gc.Equals, "GPLv3")
+}
+
+func (s *ResourcesSuite) TestOpenResourceNotPushed(c *gc.C) {
+	_, _, err := s.resources.OpenResource("starsay", "licence")
+	c.Check(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *ResourcesSuite) TestRemoveUnusedData(c *gc.C) {
+	s.storage.data["licence1"] = []byte("GPLv3")
+
+	err := s.resources.RemoveUnusedData("licence1")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(s.storage.data, gc.HasLen, 0)
+}
+
+func (s *ResourcesSuite) TestRemoveUnusedDataInUse(c *gc.C) {
+	s.storage.data["licence1"] = []byte("GPLv3")
+	s.persist.inUse = map[string]bool{"licence1": true}
+
+	err := s.resources.RemoveUnusedData("licence1")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(s.storage.data, jc.DeepEquals, map[string][]byte{"licence1": []byte("GPLv3")})
+}
+
+type fakePersistence struct {
+	resources    map[string]resource.Resource
+	paths        map[string]string
+	previousPath string
+	units        []resource.UnitResources
+	inUse        map[string]bool
+}
+
+func (f *fakePersistence) ListResources(service string) (resource.ServiceResources, error) {
+	var results resource.ServiceResources
+	for _, res := range f.resources {
+		results.Resources = append(results.Resources, res)
+	}
+	results.UnitResources = f.units
+	return results, nil
+}
+
+func (f *fakePersistence) GetResource(service, name string) (resource.Resource, string, error) {
+	res, ok := f.resources[name]
+	if !ok {
+		return resource.Resource{}, "", errors.NotFoundf("resource %q", name)
+	}
+	return res, f.paths[name], nil
+}
+
+func (f *fakePersistence) SetResource(res resource.Resource, storagePath, previousPath string) error {
+	if f.paths == nil {
+		f.paths = make(map[string]string)
+	}
+	f.resources[res.Name] = res
+	f.paths[res.Name] = storagePath
+	f.previousPath = previousPath
+	return nil
+}
+
+func (f *fakePersistence) SetUnitResource(unit string, res resource.Resource) error {
+	f.units = append(f.units, resource.UnitResources{Unit: unit, Resources: []resource.Resource{res}})
+	return nil
+}
+
+func (f *fakePersistence) NewRemoveResourcesOps(service string) ([]txn.Op, error) {
+	return nil, nil
+}
+
+func (f *fakePersistence) NewRemoveUnitResourcesOps(unit string) ([]txn.Op, error) {
+	return nil, nil
+}
+
+func (f *fakePersistence) StoragePathInUse(storagePath string) (bool, error) {
+	return f.inUse[storagePath], nil
+}
+
+type fakeStorage struct {
+	data map[string][]byte
+}
+
+func (f *fakeStorage) Get(path string) (io.ReadCloser, int64, error) {
+	data, ok := f.data[path]
+	if !ok {
+		return nil, 0, errors.NotFoundf(path)
+	}
+	return ioutil.NopCloser(bytes.NewReader(data)), int64(len(data)), nil
+}
+
+func (f *fakeStorage) Put(path string, r io.Reader, length int64) error {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	f.data[path] = data
+	return nil
+}
+
+func (f *fakeStorage) Remove(path string) error {
+	delete(f.data, path)
+	return nil
+}
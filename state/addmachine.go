@@ -12,6 +12,7 @@ import (
 	"github.com/juju/names"
 	"github.com/juju/replicaset"
 	jujutxn "github.com/juju/txn"
+	"github.com/juju/utils/set"
 	"gopkg.in/mgo.v2/bson"
 	"gopkg.in/mgo.v2/txn"
 
@@ -41,6 +42,12 @@ type MachineTemplate struct {
 	// It is ignored if Jobs does not contain JobManageEnviron.
 	NoVote bool
 
+	// ReplicaSetRole holds how a machine running a state server
+	// takes part in the mongo replica set. Machines that are not
+	// voters always abstain from peer voting. It is ignored if
+	// Jobs does not contain JobManageEnviron.
+	ReplicaSetRole ReplicaSetRole
+
 	// Addresses holds the addresses to be associated with the
 	// new machine.
 	Addresses []network.Address
@@ -265,6 +272,9 @@ func (st *State) effectiveMachineTemplate(p MachineTemplate, allowStateServer bo
 			return tmpl, errStateServerNotAllowed
 		}
 	}
+	if err := p.ReplicaSetRole.Validate(); err != nil {
+		return tmpl, errors.Trace(err)
+	}
 	return p, nil
 }
 
@@ -469,8 +479,9 @@ func (st *State) machineDocForTemplate(template MachineTemplate, id string) *mac
 		Addresses:               fromNetworkAddresses(template.Addresses, OriginMachine),
 		PreferredPrivateAddress: fromNetworkAddress(privateAddr, OriginMachine),
 		PreferredPublicAddress:  fromNetworkAddress(publicAddr, OriginMachine),
-		NoVote:                  template.NoVote,
+		NoVote:                  template.NoVote || template.ReplicaSetRole != ReplicaSetVoter,
 		Placement:               template.Placement,
+		ReplicaSetRole:          template.ReplicaSetRole,
 	}
 }
 
@@ -786,6 +797,95 @@ func (st *State) EnsureAvailability(
 	return change, nil
 }
 
+// AddStateServerMembers adds state server machines that take part in
+// the mongo replica set with the given role, which must not be
+// ReplicaSetVoter. One state server is added for each of the given
+// placement directives: directives naming existing machines convert
+// them into state servers, and other directives are used to start new
+// machines with the given constraints and series. The state servers
+// added never vote, so they don't affect the number of state servers
+// maintained by EnsureAvailability.
+func (st *State) AddStateServerMembers(
+	role ReplicaSetRole, cons constraints.Value, series string, placement []string,
+) (StateServersChanges, error) {
+	if err := role.Validate(); err != nil {
+		return StateServersChanges{}, errors.Trace(err)
+	}
+	if role == ReplicaSetVoter {
+		return StateServersChanges{}, errors.New("use EnsureAvailability to add voting state servers")
+	}
+	if len(placement) == 0 {
+		return StateServersChanges{}, errors.New("no placement directives specified")
+	}
+	var change StateServersChanges
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		currentInfo, err := st.StateServerInfo()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if len(currentInfo.MachineIds)+len(placement) > replicaset.MaxPeers {
+			return nil, errors.Errorf("state server count is too large (allowed %d)", replicaset.MaxPeers)
+		}
+		change = StateServersChanges{}
+		var ops []txn.Op
+		var mdocs []*machineDoc
+		converted := make(set.Strings)
+		for _, s := range placement {
+			if s == "" {
+				return nil, errors.New("empty placement directive")
+			}
+			p, err := instance.ParsePlacement(s)
+			if err == nil && p.Scope == instance.MachineScope {
+				if names.IsContainerMachine(p.Directive) {
+					return nil, errors.New("container placement directives not supported")
+				}
+				if converted.Contains(p.Directive) {
+					return nil, errors.Errorf("machine for placement directive %q specified more than once", s)
+				}
+				converted.Add(p.Directive)
+				m, err := st.Machine(p.Directive)
+				if err != nil {
+					return nil, errors.Annotatef(err, "can't find machine for placement directive %q", s)
+				}
+				if m.IsManager() {
+					return nil, errors.Errorf("machine for placement directive %q is already a state server", s)
+				}
+				ops = append(ops, convertStateServerMemberOps(m, role)...)
+				change.Converted = append(change.Converted, m.doc.Id)
+				continue
+			}
+			if err != nil && err != instance.ErrPlacementScopeMissing {
+				return nil, errors.Errorf("unsupported placement directive %q", s)
+			}
+			mdoc, addOps, err := st.addMachineOps(MachineTemplate{
+				Series: series,
+				Jobs: []MachineJob{
+					JobHostUnits,
+					JobManageEnviron,
+				},
+				Constraints:    cons,
+				Placement:      s,
+				ReplicaSetRole: role,
+			})
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			mdocs = append(mdocs, mdoc)
+			ops = append(ops, addOps...)
+			change.Added = append(change.Added, mdoc.Id)
+		}
+		ssOps, err := st.maintainStateServersOps(mdocs, currentInfo)
+		if err != nil {
+			return nil, errors.Annotate(err, "cannot prepare machine add operations")
+		}
+		return append(ops, ssOps...), nil
+	}
+	if err := st.run(buildTxn); err != nil {
+		return StateServersChanges{}, errors.Annotatef(err, "failed to add %s state server machines", role)
+	}
+	return change, nil
+}
+
 // Change in state servers after the ensure availability txn has committed.
 type StateServersChanges struct {
 	Added      []string
@@ -941,7 +1041,9 @@ func (st *State) ensureAvailabilityIntentions(info *StateServerInfo, placement [
 		}
 		logger.Infof("machine %q, available %v, wants vote %v, has vote %v", m, available, m.WantsVote(), m.HasVote())
 		if available {
-			if m.WantsVote() {
+			// State servers that are not voters are added
+			// explicitly, and are never promoted.
+			if m.WantsVote() || m.ReplicaSetRole() != ReplicaSetVoter {
 				intent.maintain = append(intent.maintain, m)
 			} else {
 				intent.promote = append(intent.promote, m)
@@ -988,6 +1090,22 @@ func convertStateServerOps(m *Machine) []txn.Op {
 	}}
 }
 
+func convertStateServerMemberOps(m *Machine, role ReplicaSetRole) []txn.Op {
+	return []txn.Op{{
+		C:  machinesC,
+		Id: m.doc.DocID,
+		Update: bson.D{
+			{"$addToSet", bson.D{{"jobs", JobManageEnviron}}},
+			{"$set", bson.D{{"novote", true}, {"replicasetrole", role}}},
+		},
+		Assert: bson.D{{"jobs", bson.D{{"$nin", []MachineJob{JobManageEnviron}}}}},
+	}, {
+		C:      stateServersC,
+		Id:     environGlobalKey,
+		Update: bson.D{{"$addToSet", bson.D{{"machineids", m.doc.Id}}}},
+	}}
+}
+
 func promoteStateServerOps(m *Machine) []txn.Op {
 	return []txn.Op{{
 		C:      machinesC,
@@ -1022,6 +1140,7 @@ func removeStateServerOps(m *Machine) []txn.Op {
 		Update: bson.D{
 			{"$pull", bson.D{{"jobs", JobManageEnviron}}},
 			{"$set", bson.D{{"novote", false}}},
+			{"$unset", bson.D{{"replicasetrole", nil}}},
 		},
 	}, {
 		C:      stateServersC,
@@ -107,12 +107,33 @@ func allCollections() collectionSchema {
 			rawAccess: true,
 		},
 
+		// This collection holds the API tokens that users can log in
+		// with instead of their passwords.
+		apiTokensC: {
+			global: true,
+			indexes: []mgo.Index{{
+				Key: []string{"owner"},
+			}},
+		},
+
+		// This collection holds the last time each API token was used
+		// to log in.
+		apiTokenLastUsedC: {
+			global:    true,
+			rawAccess: true,
+		},
+
 		// This collection is used as a unique key restraint. The _id field is
 		// a concatenation of multiple fields that form a compound index,
 		// allowing us to ensure users cannot have the same name for two
 		// different environments at a time.
 		userenvnameC: {global: true},
 
+		// This collection records the migrations of environments between
+		// controllers, keyed by environment UUID; each controller taking
+		// part in a migration keeps its own record of it.
+		migrationsC: {global: true},
+
 		// This collection holds workload metrics reported by certain charms
 		// for passing onward to other tools.
 		metricsC: {global: true},
@@ -203,6 +224,12 @@ func allCollections() collectionSchema {
 		},
 		relationScopesC: {},
 
+		// These collections hold the endpoints an environment offers to
+		// other environments on the same controller, and the proxies for
+		// services in other environments that take part in its relations.
+		offersC:         {},
+		remoteServicesC: {},
+
 		// -----
 
 		// These collections hold information associated with machines.
@@ -277,6 +304,7 @@ func allCollections() collectionSchema {
 		},
 		openedPortsC:       {},
 		requestedNetworksC: {},
+		endpointBindingsC:  {},
 		subnetsC: {
 			indexes: []mgo.Index{{
 				// TODO(dimitern): make unique per-environment, not globally.
@@ -302,6 +330,10 @@ func allCollections() collectionSchema {
 		// See payload/persistence/mongo.go.
 		"payloads": {},
 
+		// This collection holds the revisions of charm resources held for
+		// services and their units. See resource/persistence/mongo.go.
+		"resources": {},
+
 		// -----
 
 		// The remaining non-global collections share the property of being
@@ -314,6 +346,10 @@ func allCollections() collectionSchema {
 		// shouldn't be written or interpreted by juju.
 		annotationsC: {},
 
+		// This collection holds structured labels for machines, services
+		// and units, which are matched by label selectors.
+		labelsC: {},
+
 		// This collection in particular holds an astounding number of
 		// different sorts of data: service config settings by charm version,
 		// unit relation settings, environment config, etc etc etc.
@@ -327,6 +363,23 @@ func allCollections() collectionSchema {
 				Key: []string{"env-uuid", "globalkey"},
 			}},
 		},
+
+		// This collection holds the revisions of each service's
+		// charm config settings. See state/confighistory.go.
+		configHistoryC: {
+			indexes: []mgo.Index{{
+				Key:    []string{"env-uuid", "service", "revision"},
+				Unique: true,
+			}},
+		},
+
+		// This collection holds a bounded log of the hooks each unit
+		// has run. See state/hookrecords.go.
+		hookRecordsC: {
+			indexes: []mgo.Index{{
+				Key: []string{"env-uuid", "globalkey", "started"},
+			}},
+		},
 		spacesC: {},
 
 		// This collection holds information about cloud image metadata.
@@ -350,33 +403,42 @@ const (
 	actionresultsC         = "actionresults"
 	actionsC               = "actions"
 	annotationsC           = "annotations"
+	apiTokenLastUsedC      = "apiTokenLastUsed"
+	apiTokensC             = "apitokens"
 	assignUnitC            = "assignUnits"
 	blockDevicesC          = "blockdevices"
 	blocksC                = "blocks"
 	charmsC                = "charms"
 	cleanupsC              = "cleanups"
 	cloudimagemetadataC    = "cloudimagemetadata"
+	configHistoryC         = "confighistory"
 	constraintsC           = "constraints"
 	containerRefsC         = "containerRefs"
+	endpointBindingsC      = "endpointbindings"
 	envUsersC              = "envusers"
 	environmentsC          = "environments"
 	filesystemAttachmentsC = "filesystemAttachments"
 	filesystemsC           = "filesystems"
+	hookRecordsC           = "hookrecords"
 	instanceDataC          = "instanceData"
 	ipaddressesC           = "ipaddresses"
+	labelsC                = "labels"
 	leaseC                 = "lease"
 	leasesC                = "leases"
 	machinesC              = "machines"
 	meterStatusC           = "meterStatus"
 	metricsC               = "metrics"
 	metricsManagerC        = "metricsmanager"
+	migrationsC            = "migrations"
 	minUnitsC              = "minunits"
 	networkInterfacesC     = "networkinterfaces"
 	networksC              = "networks"
+	offersC                = "offers"
 	openedPortsC           = "openedPorts"
 	rebootC                = "reboot"
 	relationScopesC        = "relationscopes"
 	relationsC             = "relations"
+	remoteServicesC        = "remoteservices"
 	requestedNetworksC     = "requestednetworks"
 	restoreInfoC           = "restoreInfo"
 	sequenceC              = "sequence"
@@ -403,4 +465,5 @@ const (
 	volumeAttachmentsC     = "volumeattachments"
 	volumesC               = "volumes"
 	// "payloads" (see payload/persistence/mongo.go)
+	// "resources" (see resource/persistence/mongo.go)
 )
@@ -0,0 +1,303 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"github.com/juju/utils"
+	"github.com/juju/utils/set"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+)
+
+var validAPITokenName = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// IsValidAPITokenName returns whether name is a valid API token name.
+func IsValidAPITokenName(name string) bool {
+	return validAPITokenName.MatchString(name)
+}
+
+// APITokenSpec describes an API token to add.
+type APITokenSpec struct {
+	// Owner is the local user that logs in with the token.
+	Owner names.UserTag
+
+	// Name identifies the token among the owner's tokens.
+	Name string
+
+	// Expires holds when the token stops being accepted. The zero
+	// time means the token never expires.
+	Expires time.Time
+
+	// Environments holds the UUIDs of the environments the token may
+	// be used to log in to. If it is empty, the token may be used
+	// with any environment the owner has access to.
+	Environments []string
+
+	// Facades holds the names of the facades the token may be used
+	// to call. If it is empty, the token may be used to call any
+	// facade the owner has access to.
+	Facades []string
+}
+
+type apiTokenDoc struct {
+	DocID        string    `bson:"_id"`
+	Name         string    `bson:"name"`
+	Owner        string    `bson:"owner"`
+	SecretHash   string    `bson:"secrethash"`
+	SecretSalt   string    `bson:"secretsalt"`
+	Created      time.Time `bson:"created"`
+	Expires      time.Time `bson:"expires,omitempty"`
+	Environments []string  `bson:"environments,omitempty"`
+	Facades      []string  `bson:"facades,omitempty"`
+}
+
+type apiTokenLastUsedDoc struct {
+	DocID string `bson:"_id"`
+	// LastUsed is updated by the apiserver whenever the token is
+	// used to log in. Like the last login time of users, it is not
+	// updated using mgo.txn, and should never appear in any
+	// transaction asserts.
+	LastUsed time.Time `bson:"last-used"`
+}
+
+// APIToken represents a named, revocable credential that a local user
+// can log in to the API with instead of their password. Tokens are
+// intended for automation, such as CI systems, that should not need to
+// know the user's password.
+type APIToken struct {
+	st  *State
+	doc apiTokenDoc
+}
+
+func apiTokenDocID(owner names.UserTag, name string) string {
+	return strings.ToLower(owner.Name()) + ":" + name
+}
+
+// AddAPIToken adds an API token with the given specification, and
+// returns it along with its secret. The secret is not stored, so it
+// cannot be retrieved again later.
+func (st *State) AddAPIToken(spec APITokenSpec) (*APIToken, string, error) {
+	if !IsValidAPITokenName(spec.Name) {
+		return nil, "", errors.NotValidf("API token name %q", spec.Name)
+	}
+	if !spec.Owner.IsLocal() {
+		return nil, "", errors.NotValidf("API token owner %q (not a local user)", spec.Owner.Canonical())
+	}
+	for _, uuid := range spec.Environments {
+		if !names.IsValidEnvironment(uuid) {
+			return nil, "", errors.NotValidf("environment UUID %q", uuid)
+		}
+	}
+	owner, err := st.User(spec.Owner)
+	if err != nil {
+		return nil, "", errors.Trace(err)
+	}
+	secret, err := utils.RandomPassword()
+	if err != nil {
+		return nil, "", errors.Trace(err)
+	}
+	salt, err := utils.RandomSalt()
+	if err != nil {
+		return nil, "", errors.Trace(err)
+	}
+	var expires time.Time
+	if !spec.Expires.IsZero() {
+		expires = spec.Expires.Round(time.Second).UTC()
+	}
+	token := &APIToken{
+		st: st,
+		doc: apiTokenDoc{
+			DocID:        apiTokenDocID(spec.Owner, spec.Name),
+			Name:         spec.Name,
+			Owner:        owner.Name(),
+			SecretHash:   utils.UserPasswordHash(secret, salt),
+			SecretSalt:   salt,
+			Created:      nowToTheSecond(),
+			Expires:      expires,
+			Environments: spec.Environments,
+			Facades:      spec.Facades,
+		},
+	}
+	ops := []txn.Op{{
+		C:      usersC,
+		Id:     strings.ToLower(owner.Name()),
+		Assert: txn.DocExists,
+	}, {
+		C:      apiTokensC,
+		Id:     token.doc.DocID,
+		Assert: txn.DocMissing,
+		Insert: &token.doc,
+	}}
+	err = st.runTransaction(ops)
+	if err == txn.ErrAborted {
+		if _, err := st.User(spec.Owner); err != nil {
+			return nil, "", errors.Trace(err)
+		}
+		err = errors.AlreadyExistsf("API token %q for user %q", spec.Name, owner.Name())
+	}
+	if err != nil {
+		return nil, "", errors.Trace(err)
+	}
+	return token, secret, nil
+}
+
+// APIToken returns the API token with the given name owned by the
+// given user.
+func (st *State) APIToken(owner names.UserTag, name string) (*APIToken, error) {
+	tokens, closer := st.getCollection(apiTokensC)
+	defer closer()
+
+	token := &APIToken{st: st}
+	err := tokens.FindId(apiTokenDocID(owner, name)).One(&token.doc)
+	if err == mgo.ErrNotFound {
+		return nil, errors.NotFoundf("API token %q for user %q", name, owner.Name())
+	}
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return token, nil
+}
+
+// APITokens returns all the API tokens owned by the given user, sorted
+// by name.
+func (st *State) APITokens(owner names.UserTag) ([]*APIToken, error) {
+	tokens, closer := st.getCollection(apiTokensC)
+	defer closer()
+
+	var docs []apiTokenDoc
+	query := bson.D{{"owner", owner.Name()}}
+	if err := tokens.Find(query).Sort("name").All(&docs); err != nil {
+		return nil, errors.Trace(err)
+	}
+	result := make([]*APIToken, len(docs))
+	for i, doc := range docs {
+		result[i] = &APIToken{st: st, doc: doc}
+	}
+	return result, nil
+}
+
+// RemoveAPIToken revokes the API token with the given name owned by
+// the given user. The API server stops accepting calls on connections
+// already made with the token shortly afterwards.
+func (st *State) RemoveAPIToken(owner names.UserTag, name string) error {
+	id := apiTokenDocID(owner, name)
+	ops := []txn.Op{{
+		C:      apiTokensC,
+		Id:     id,
+		Assert: txn.DocExists,
+		Remove: true,
+	}}
+	err := st.runTransaction(ops)
+	if err == txn.ErrAborted {
+		return errors.NotFoundf("API token %q for user %q", name, owner.Name())
+	}
+	if err != nil {
+		return errors.Annotatef(err, "cannot remove API token %q", name)
+	}
+
+	lastUsed, closer := st.getRawCollection(apiTokenLastUsedC)
+	defer closer()
+	if err := lastUsed.RemoveId(id); err != nil && err != mgo.ErrNotFound {
+		return errors.Annotatef(err, "cannot remove last use of API token %q", name)
+	}
+	return nil
+}
+
+// Name returns the name of the token.
+func (t *APIToken) Name() string {
+	return t.doc.Name
+}
+
+// Owner returns the tag of the user that owns the token.
+func (t *APIToken) Owner() names.UserTag {
+	return names.NewLocalUserTag(t.doc.Owner)
+}
+
+// Created returns when the token was created, in UTC.
+func (t *APIToken) Created() time.Time {
+	return t.doc.Created.UTC()
+}
+
+// Expires returns when the token expires, in UTC. It returns the zero
+// time if the token never expires.
+func (t *APIToken) Expires() time.Time {
+	if t.doc.Expires.IsZero() {
+		return time.Time{}
+	}
+	return t.doc.Expires.UTC()
+}
+
+// Expired returns whether the token had expired at the given time.
+func (t *APIToken) Expired(now time.Time) bool {
+	return !t.doc.Expires.IsZero() && !now.Before(t.doc.Expires)
+}
+
+// Environments returns the UUIDs of the environments the token is
+// restricted to, or nil if it is not restricted.
+func (t *APIToken) Environments() []string {
+	return t.doc.Environments
+}
+
+// Facades returns the names of the facades the token is restricted
+// to, or nil if it is not restricted.
+func (t *APIToken) Facades() []string {
+	return t.doc.Facades
+}
+
+// AllowsEnvironment returns whether the token may be used to log in to
+// the environment with the given UUID. An empty UUID stands for a
+// login to the controller rather than to an environment, which only
+// tokens that are not restricted to environments allow.
+func (t *APIToken) AllowsEnvironment(uuid string) bool {
+	if len(t.doc.Environments) == 0 {
+		return true
+	}
+	return uuid != "" && set.NewStrings(t.doc.Environments...).Contains(uuid)
+}
+
+// SecretValid returns whether the given secret is the token's secret.
+func (t *APIToken) SecretValid(secret string) bool {
+	return utils.UserPasswordHash(secret, t.doc.SecretSalt) == t.doc.SecretHash
+}
+
+// LastUsed returns when the token was last used to log in, in UTC. It
+// returns the zero time if the token has never been used.
+func (t *APIToken) LastUsed() (time.Time, error) {
+	lastUsed, closer := t.st.getRawCollection(apiTokenLastUsedC)
+	defer closer()
+
+	var doc apiTokenLastUsedDoc
+	err := lastUsed.FindId(t.doc.DocID).One(&doc)
+	if err == mgo.ErrNotFound {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, errors.Trace(err)
+	}
+	return doc.LastUsed.UTC(), nil
+}
+
+// UpdateLastUsed records that the token has just been used to log in.
+func (t *APIToken) UpdateLastUsed() error {
+	lastUsed, closer := t.st.getRawCollection(apiTokenLastUsedC)
+	defer closer()
+
+	// Update the safe mode of the underlying session to not require
+	// write majority, nor sync to disk.
+	lastUsed.Database.Session.SetSafe(&mgo.Safe{})
+
+	doc := apiTokenLastUsedDoc{
+		DocID:    t.doc.DocID,
+		LastUsed: nowToTheSecond(),
+	}
+	_, err := lastUsed.UpsertId(doc.DocID, doc)
+	return errors.Trace(err)
+}
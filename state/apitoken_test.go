@@ -0,0 +1,183 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/testing/factory"
+)
+
+type APITokenSuite struct {
+	ConnSuite
+	owner names.UserTag
+}
+
+var _ = gc.Suite(&APITokenSuite{})
+
+func (s *APITokenSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	s.owner = s.Factory.MakeUser(c, &factory.UserParams{Name: "ci-bot"}).UserTag()
+}
+
+func (s *APITokenSuite) TestAddAPIToken(c *gc.C) {
+	expires := time.Now().Add(24 * time.Hour).Round(time.Second).UTC()
+	token, secret, err := s.State.AddAPIToken(state.APITokenSpec{
+		Owner:        s.owner,
+		Name:         "jenkins",
+		Expires:      expires,
+		Environments: []string{s.State.EnvironUUID()},
+		Facades:      []string{"Client"},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(secret, gc.Not(gc.Equals), "")
+	c.Assert(token.Name(), gc.Equals, "jenkins")
+	c.Assert(token.Owner(), gc.Equals, s.owner)
+	c.Assert(token.Expires(), gc.Equals, expires)
+	c.Assert(token.Environments(), jc.DeepEquals, []string{s.State.EnvironUUID()})
+	c.Assert(token.Facades(), jc.DeepEquals, []string{"Client"})
+	c.Assert(token.SecretValid(secret), jc.IsTrue)
+	c.Assert(token.SecretValid("wrong"), jc.IsFalse)
+
+	token, err = s.State.APIToken(s.owner, "jenkins")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(token.Expires(), gc.Equals, expires)
+	c.Assert(token.SecretValid(secret), jc.IsTrue)
+}
+
+func (s *APITokenSuite) TestAddAPITokenInvalidName(c *gc.C) {
+	for _, name := range []string{"", "-ci", "Jenkins", "ci:bot", "ci bot"} {
+		c.Logf("name %q", name)
+		_, _, err := s.State.AddAPIToken(state.APITokenSpec{Owner: s.owner, Name: name})
+		c.Check(err, jc.Satisfies, errors.IsNotValid)
+	}
+}
+
+func (s *APITokenSuite) TestAddAPITokenInvalidEnvironment(c *gc.C) {
+	_, _, err := s.State.AddAPIToken(state.APITokenSpec{
+		Owner:        s.owner,
+		Name:         "jenkins",
+		Environments: []string{"not-a-uuid"},
+	})
+	c.Assert(err, gc.ErrorMatches, `environment UUID "not-a-uuid" not valid`)
+}
+
+func (s *APITokenSuite) TestAddAPITokenUnknownOwner(c *gc.C) {
+	_, _, err := s.State.AddAPIToken(state.APITokenSpec{
+		Owner: names.NewLocalUserTag("nobody"),
+		Name:  "jenkins",
+	})
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *APITokenSuite) TestAddAPITokenExternalOwner(c *gc.C) {
+	_, _, err := s.State.AddAPIToken(state.APITokenSpec{
+		Owner: names.NewUserTag("bob@remote"),
+		Name:  "jenkins",
+	})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+}
+
+func (s *APITokenSuite) TestAddAPITokenDuplicate(c *gc.C) {
+	_, _, err := s.State.AddAPIToken(state.APITokenSpec{Owner: s.owner, Name: "jenkins"})
+	c.Assert(err, jc.ErrorIsNil)
+	_, _, err = s.State.AddAPIToken(state.APITokenSpec{Owner: s.owner, Name: "jenkins"})
+	c.Assert(err, jc.Satisfies, errors.IsAlreadyExists)
+
+	// Other users may use the same name.
+	_, _, err = s.State.AddAPIToken(state.APITokenSpec{Owner: s.Owner, Name: "jenkins"})
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *APITokenSuite) TestAPITokens(c *gc.C) {
+	for _, name := range []string{"travis", "jenkins"} {
+		_, _, err := s.State.AddAPIToken(state.APITokenSpec{Owner: s.owner, Name: name})
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	_, _, err := s.State.AddAPIToken(state.APITokenSpec{Owner: s.Owner, Name: "other"})
+	c.Assert(err, jc.ErrorIsNil)
+
+	tokens, err := s.State.APITokens(s.owner)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(tokens, gc.HasLen, 2)
+	c.Assert(tokens[0].Name(), gc.Equals, "jenkins")
+	c.Assert(tokens[1].Name(), gc.Equals, "travis")
+}
+
+func (s *APITokenSuite) TestRemoveAPIToken(c *gc.C) {
+	token, _, err := s.State.AddAPIToken(state.APITokenSpec{Owner: s.owner, Name: "jenkins"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(token.UpdateLastUsed(), jc.ErrorIsNil)
+
+	err = s.State.RemoveAPIToken(s.owner, "jenkins")
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.APIToken(s.owner, "jenkins")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+
+	err = s.State.RemoveAPIToken(s.owner, "jenkins")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+
+	// A new token with the same name starts afresh.
+	token, _, err = s.State.AddAPIToken(state.APITokenSpec{Owner: s.owner, Name: "jenkins"})
+	c.Assert(err, jc.ErrorIsNil)
+	lastUsed, err := token.LastUsed()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(lastUsed.IsZero(), jc.IsTrue)
+}
+
+func (s *APITokenSuite) TestExpired(c *gc.C) {
+	now := time.Now()
+	token, _, err := s.State.AddAPIToken(state.APITokenSpec{Owner: s.owner, Name: "forever"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(token.Expires().IsZero(), jc.IsTrue)
+	c.Assert(token.Expired(now.Add(1000*time.Hour)), jc.IsFalse)
+
+	token, _, err = s.State.AddAPIToken(state.APITokenSpec{
+		Owner:   s.owner,
+		Name:    "daily",
+		Expires: now.Add(24 * time.Hour),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(token.Expired(now), jc.IsFalse)
+	c.Assert(token.Expired(now.Add(25*time.Hour)), jc.IsTrue)
+}
+
+func (s *APITokenSuite) TestAllowsEnvironment(c *gc.C) {
+	uuid := s.State.EnvironUUID()
+	token, _, err := s.State.AddAPIToken(state.APITokenSpec{Owner: s.owner, Name: "any"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(token.AllowsEnvironment(uuid), jc.IsTrue)
+	c.Assert(token.AllowsEnvironment(""), jc.IsTrue)
+
+	token, _, err = s.State.AddAPIToken(state.APITokenSpec{
+		Owner:        s.owner,
+		Name:         "scoped",
+		Environments: []string{uuid},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(token.AllowsEnvironment(uuid), jc.IsTrue)
+	c.Assert(token.AllowsEnvironment("deadbeef-0bad-400d-8000-4b1d0d06f00d"), jc.IsFalse)
+	c.Assert(token.AllowsEnvironment(""), jc.IsFalse)
+}
+
+func (s *APITokenSuite) TestLastUsed(c *gc.C) {
+	token, _, err := s.State.AddAPIToken(state.APITokenSpec{Owner: s.owner, Name: "jenkins"})
+	c.Assert(err, jc.ErrorIsNil)
+	lastUsed, err := token.LastUsed()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(lastUsed.IsZero(), jc.IsTrue)
+
+	now := state.NowToTheSecond()
+	err = token.UpdateLastUsed()
+	c.Assert(err, jc.ErrorIsNil)
+	lastUsed, err = token.LastUsed()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(lastUsed.Before(now), jc.IsFalse)
+}
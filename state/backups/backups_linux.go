@@ -6,6 +6,7 @@
 package backups
 
 import (
+	"io"
 	"net"
 	"strconv"
 
@@ -66,12 +67,33 @@ func (b *backups) Restore(backupId string, args RestoreArgs) (names.Tag, error)
 
 	defer backupReader.Close()
 
-	workspace, err := NewArchiveWorkspaceReader(backupReader)
+	var archive io.Reader = backupReader
+	if meta.Encryption != EncryptionNone {
+		if args.Key == nil {
+			return nil, errors.Errorf("backup %q is encrypted (%s); a key is required to restore it", backupId, meta.Encryption)
+		}
+		archive, err = NewDecryptingReader(backupReader, *args.Key)
+		if err != nil {
+			return nil, errors.Annotate(err, "cannot decrypt backup file")
+		}
+	}
+
+	workspace, err := NewArchiveWorkspaceReader(archive)
 	if err != nil {
 		return nil, errors.Annotate(err, "cannot unpack backup file")
 	}
 	defer workspace.Close()
 
+	if meta.Encryption != EncryptionNone && meta.Origin.Machine == UnknownString {
+		// The metadata was built from an encrypted archive uploaded
+		// without its key, so take the origin from the archive itself.
+		archiveMeta, err := workspace.Metadata()
+		if err != nil {
+			return nil, errors.Annotate(err, "cannot read metadata from backup file")
+		}
+		meta.Origin = archiveMeta.Origin
+	}
+
 	// TODO(perrito666) Create a compatibility table of sorts.
 	version := meta.Origin.Version
 	backupMachine := names.NewMachineTag(meta.Origin.Machine)
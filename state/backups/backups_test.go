@@ -56,7 +56,7 @@ func (s *backupsSuite) checkFailure(c *gc.C, expected string) {
 	dbInfo := backups.DBInfo{"a", "b", "c", targets}
 	meta := backupstesting.NewMetadataStarted()
 	meta.Notes = "some notes"
-	err := s.api.Create(meta, &paths, &dbInfo)
+	err := s.api.Create(meta, &paths, &dbInfo, nil)
 
 	c.Check(err, gc.ErrorMatches, expected)
 }
@@ -96,7 +96,7 @@ func (s *backupsSuite) TestCreateOkay(c *gc.C) {
 	meta := backupstesting.NewMetadataStarted()
 	backupstesting.SetOrigin(meta, "<env ID>", "<machine ID>", "<hostname>")
 	meta.Notes = "some notes"
-	err := s.api.Create(meta, &paths, &dbInfo)
+	err := s.api.Create(meta, &paths, &dbInfo, nil)
 
 	// Test the call values.
 	s.Storage.CheckCalled(c, "spam", meta, archiveFile, "Add", "Metadata")
@@ -132,6 +132,37 @@ func (s *backupsSuite) TestCreateOkay(c *gc.C) {
 	c.Check(string(data), gc.Equals, "<compressed tarball>")
 }
 
+func (s *backupsSuite) TestCreateEncrypted(c *gc.C) {
+	received, testCreate := backups.NewTestCreate(nil)
+	s.PatchValue(backups.RunCreate, testCreate)
+	s.PatchValue(backups.TestGetFilesToBackUp, func(root string, paths *backups.Paths, oldmachine string) ([]string, error) {
+		return []string{"<some file>"}, nil
+	})
+	s.PatchValue(backups.GetDBDumper, func(info *backups.DBInfo) (backups.DBDumper, error) {
+		return nil, nil
+	})
+	s.setStored("spam")
+
+	paths := backups.Paths{DataDir: "/var/lib/juju"}
+	dbInfo := backups.DBInfo{"a", "b", "c", set.NewStrings("juju")}
+	meta := backupstesting.NewMetadataStarted()
+	key := &backups.EncryptionKey{Passphrase: "sekrit"}
+	err := s.api.Create(meta, &paths, &dbInfo, key)
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(backups.ExposeCreateKey(received), gc.Equals, key)
+	c.Check(meta.Encryption, gc.Equals, backups.EncryptionPassphrase)
+}
+
+func (s *backupsSuite) TestCreateInvalidKey(c *gc.C) {
+	paths := backups.Paths{DataDir: "/var/lib/juju"}
+	dbInfo := backups.DBInfo{"a", "b", "c", set.NewStrings("juju")}
+	meta := backupstesting.NewMetadataStarted()
+	err := s.api.Create(meta, &paths, &dbInfo, &backups.EncryptionKey{})
+
+	c.Check(err, gc.ErrorMatches, "invalid encryption key: missing passphrase or public key")
+}
+
 func (s *backupsSuite) TestCreateFailToListFiles(c *gc.C) {
 	s.PatchValue(backups.TestGetFilesToBackUp, func(root string, paths *backups.Paths, oldmachine string) ([]string, error) {
 		return nil, errors.New("failed!")
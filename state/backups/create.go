@@ -31,6 +31,8 @@ type createArgs struct {
 	filesToBackUp  []string
 	db             DBDumper
 	metadataReader io.Reader
+	// key, if set, is used to encrypt the archive.
+	key *EncryptionKey
 }
 
 type createResult struct {
@@ -47,6 +49,7 @@ func create(args *createArgs) (_ *createResult, err error) {
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	builder.key = args.key
 	defer func() {
 		if cerr := builder.cleanUp(); cerr != nil {
 			cerr.Log(logger)
@@ -103,6 +106,9 @@ type builder struct {
 	// bundleFile is the inner archive file containing all the juju
 	// state-related files gathered during backup.
 	bundleFile io.WriteCloser
+	// key is used to encrypt the archive file. If it is nil the
+	// archive is not encrypted.
+	key *EncryptionKey
 }
 
 // newBuilder returns a new backup archive builder.  It creates the temp
@@ -312,10 +318,27 @@ func (b *builder) buildArchiveAndChecksum() error {
 	// than to the uncompressed contents of the tarball.  This is so
 	// that users can compare the published checksum against the
 	// checksum of the file without having to decompress it first.
+	// When encrypting, the hash is of the encrypted file for the same
+	// reason.
 	hasher := hash.NewHashingWriter(b.archiveFile, sha1.New())
-	if err := b.buildArchive(hasher); err != nil {
+	var out io.Writer = hasher
+	var encrypter io.WriteCloser
+	if b.key != nil {
+		var err error
+		encrypter, err = NewEncryptingWriter(hasher, *b.key)
+		if err != nil {
+			return errors.Annotate(err, "while preparing archive encryption")
+		}
+		out = encrypter
+	}
+	if err := b.buildArchive(out); err != nil {
 		return errors.Trace(err)
 	}
+	if encrypter != nil {
+		if err := encrypter.Close(); err != nil {
+			return errors.Annotate(err, "while encrypting archive")
+		}
+	}
 
 	// Save the SHA1 checksum.
 	// Gzip writers may buffer what they're writing so we must call
@@ -0,0 +1,423 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package backups
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/pem"
+	"io"
+
+	"github.com/juju/errors"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// These are the encryption schemes which may be recorded in the
+// metadata of a backup archive.
+const (
+	// EncryptionNone indicates that the archive is not encrypted.
+	EncryptionNone = ""
+
+	// EncryptionPassphrase indicates that the archive is encrypted
+	// with a key derived from a passphrase.
+	EncryptionPassphrase = "aes-256-gcm/pbkdf2-sha256"
+
+	// EncryptionPublicKey indicates that the archive is encrypted with
+	// a random key which is itself encrypted with an RSA public key.
+	EncryptionPublicKey = "aes-256-gcm/rsa-oaep-sha256"
+)
+
+// An encrypted archive starts with encryptionMagic followed by a single
+// byte identifying the scheme. The rest of the header depends on the
+// scheme:
+//
+//	passphrase: 16 byte salt, 4 byte big-endian PBKDF2 iteration count
+//	public key: 2 byte big-endian length, RSA-OAEP encrypted data key
+//
+// The header is followed by a sequence of AES-GCM sealed chunks, each
+// preceded by a 4 byte big-endian length. The top bit of the length
+// marks the final chunk so that truncated archives are detected.
+var encryptionMagic = []byte("JUJUBKE1")
+
+const (
+	schemeIDPassphrase byte = 1
+	schemeIDPublicKey  byte = 2
+
+	encryptionKeySize    = 32
+	encryptionSaltSize   = 16
+	encryptionIterations = 100000
+	encryptionChunkSize  = 64 * 1024
+	encryptionFinalChunk = 1 << 31
+)
+
+// oaepLabel is the label used when wrapping data keys with RSA-OAEP.
+var oaepLabel = []byte("juju-backup")
+
+// EncryptionKey holds the key material used to encrypt or decrypt a
+// backup archive. Archives are encrypted using either Passphrase or
+// PublicKey, and decrypted using either Passphrase or PrivateKey.
+type EncryptionKey struct {
+	// Passphrase is used for symmetric encryption.
+	Passphrase string
+	// PublicKey is used to encrypt an archive for the holder of the
+	// corresponding private key.
+	PublicKey *rsa.PublicKey
+	// PrivateKey is used to decrypt an archive that was encrypted
+	// with the corresponding public key.
+	PrivateKey *rsa.PrivateKey
+}
+
+// Scheme returns the encryption scheme that will be used when
+// encrypting with the key.
+func (k EncryptionKey) Scheme() (string, error) {
+	switch {
+	case k.Passphrase != "" && k.PublicKey != nil:
+		return "", errors.New("cannot encrypt with both a passphrase and a public key")
+	case k.Passphrase != "":
+		return EncryptionPassphrase, nil
+	case k.PublicKey != nil:
+		return EncryptionPublicKey, nil
+	}
+	return "", errors.New("missing passphrase or public key")
+}
+
+// ParsePublicKey returns the RSA public key in the given PEM data.
+func ParsePublicKey(data []byte) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+	var key interface{}
+	var err error
+	switch block.Type {
+	case "PUBLIC KEY":
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "CERTIFICATE":
+		var cert *x509.Certificate
+		cert, err = x509.ParseCertificate(block.Bytes)
+		if err == nil {
+			key = cert.PublicKey
+		}
+	default:
+		return nil, errors.Errorf("unexpected PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot parse public key")
+	}
+	rsaKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.Errorf("expected RSA public key, got %T", key)
+	}
+	return rsaKey, nil
+}
+
+// ParsePrivateKey returns the RSA private key in the given PEM data.
+func ParsePrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		return key, errors.Annotate(err, "cannot parse private key")
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, errors.Annotate(err, "cannot parse private key")
+		}
+		rsaKey, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, errors.Errorf("expected RSA private key, got %T", key)
+		}
+		return rsaKey, nil
+	}
+	return nil, errors.Errorf("unexpected PEM block %q", block.Type)
+}
+
+// ArchiveEncryption reads the start of a backup archive and returns
+// the encryption scheme used, or EncryptionNone if the archive is not
+// encrypted. The header bytes are consumed from the reader so callers
+// will usually need to rewind it afterward.
+func ArchiveEncryption(archive io.Reader) (string, error) {
+	header := make([]byte, len(encryptionMagic)+1)
+	if _, err := io.ReadFull(archive, header); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return EncryptionNone, nil
+		}
+		return "", errors.Trace(err)
+	}
+	if !bytes.Equal(header[:len(encryptionMagic)], encryptionMagic) {
+		return EncryptionNone, nil
+	}
+	switch header[len(encryptionMagic)] {
+	case schemeIDPassphrase:
+		return EncryptionPassphrase, nil
+	case schemeIDPublicKey:
+		return EncryptionPublicKey, nil
+	}
+	return "", errors.NotSupportedf("archive encryption scheme %d", header[len(encryptionMagic)])
+}
+
+// NewEncryptingWriter returns a writer that encrypts everything
+// written to it with the given key before passing it on to w. The
+// writer must be closed to flush the final chunk; closing it does not
+// close w.
+func NewEncryptingWriter(w io.Writer, key EncryptionKey) (io.WriteCloser, error) {
+	scheme, err := key.Scheme()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	var header bytes.Buffer
+	header.Write(encryptionMagic)
+	var dataKey []byte
+	switch scheme {
+	case EncryptionPassphrase:
+		salt := make([]byte, encryptionSaltSize)
+		if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+			return nil, errors.Trace(err)
+		}
+		dataKey = passphraseKey(key.Passphrase, salt, encryptionIterations)
+		header.WriteByte(schemeIDPassphrase)
+		header.Write(salt)
+		binary.Write(&header, binary.BigEndian, uint32(encryptionIterations))
+	case EncryptionPublicKey:
+		dataKey = make([]byte, encryptionKeySize)
+		if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+			return nil, errors.Trace(err)
+		}
+		wrapped, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, key.PublicKey, dataKey, oaepLabel)
+		if err != nil {
+			return nil, errors.Annotate(err, "cannot encrypt data key")
+		}
+		header.WriteByte(schemeIDPublicKey)
+		binary.Write(&header, binary.BigEndian, uint16(len(wrapped)))
+		header.Write(wrapped)
+	}
+
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if _, err := w.Write(header.Bytes()); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &encryptingWriter{
+		out:  w,
+		aead: aead,
+		buf:  make([]byte, 0, encryptionChunkSize),
+	}, nil
+}
+
+// NewDecryptingReader returns a reader that yields the decrypted
+// contents of the encrypted archive read from r. An error is returned
+// if the archive is not encrypted or the key does not match the
+// archive's encryption scheme.
+func NewDecryptingReader(r io.Reader, key EncryptionKey) (io.Reader, error) {
+	scheme, err := ArchiveEncryption(r)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	var dataKey []byte
+	switch scheme {
+	case EncryptionNone:
+		return nil, errors.New("archive is not encrypted")
+	case EncryptionPassphrase:
+		if key.Passphrase == "" {
+			return nil, errors.New("archive is encrypted with a passphrase; none given")
+		}
+		salt := make([]byte, encryptionSaltSize)
+		if _, err := io.ReadFull(r, salt); err != nil {
+			return nil, errors.Annotate(err, "cannot read encryption header")
+		}
+		var iterations uint32
+		if err := binary.Read(r, binary.BigEndian, &iterations); err != nil {
+			return nil, errors.Annotate(err, "cannot read encryption header")
+		}
+		dataKey = passphraseKey(key.Passphrase, salt, int(iterations))
+	case EncryptionPublicKey:
+		if key.PrivateKey == nil {
+			return nil, errors.New("archive is encrypted with a public key; no private key given")
+		}
+		var size uint16
+		if err := binary.Read(r, binary.BigEndian, &size); err != nil {
+			return nil, errors.Annotate(err, "cannot read encryption header")
+		}
+		wrapped := make([]byte, size)
+		if _, err := io.ReadFull(r, wrapped); err != nil {
+			return nil, errors.Annotate(err, "cannot read encryption header")
+		}
+		dataKey, err = rsa.DecryptOAEP(sha256.New(), nil, key.PrivateKey, wrapped, oaepLabel)
+		if err != nil {
+			return nil, errors.New("cannot decrypt data key: wrong private key?")
+		}
+	}
+
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &decryptingReader{
+		in:   r,
+		aead: aead,
+	}, nil
+}
+
+func passphraseKey(passphrase string, salt []byte, iterations int) []byte {
+	return pbkdf2.Key([]byte(passphrase), salt, iterations, encryptionKeySize, sha256.New)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	aead, err := cipher.NewGCM(block)
+	return aead, errors.Trace(err)
+}
+
+// chunkNonce returns the nonce for the chunk with the given sequence
+// number. Every archive uses a fresh data key (a random one, or one
+// derived using a random salt), so a counter is a safe nonce.
+func chunkNonce(aead cipher.AEAD, counter uint64) []byte {
+	nonce := make([]byte, aead.NonceSize())
+	binary.BigEndian.PutUint64(nonce[len(nonce)-8:], counter)
+	return nonce
+}
+
+// chunkAdditionalData binds the "final" marker to a chunk so that it
+// cannot be altered without detection.
+func chunkAdditionalData(final bool) []byte {
+	if final {
+		return []byte{1}
+	}
+	return []byte{0}
+}
+
+type encryptingWriter struct {
+	out     io.Writer
+	aead    cipher.AEAD
+	buf     []byte
+	counter uint64
+	closed  bool
+}
+
+// Write implements io.Writer.
+func (w *encryptingWriter) Write(data []byte) (int, error) {
+	if w.closed {
+		return 0, errors.New("write to closed encrypting writer")
+	}
+	written := 0
+	for len(data) > 0 {
+		// Only flush a full chunk once we know more data follows, so
+		// that the final chunk is always written by Close.
+		if len(w.buf) == encryptionChunkSize {
+			if err := w.flush(false); err != nil {
+				return written, errors.Trace(err)
+			}
+		}
+		n := encryptionChunkSize - len(w.buf)
+		if n > len(data) {
+			n = len(data)
+		}
+		w.buf = append(w.buf, data[:n]...)
+		data = data[n:]
+		written += n
+	}
+	return written, nil
+}
+
+// Close implements io.Closer.
+func (w *encryptingWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	return errors.Trace(w.flush(true))
+}
+
+func (w *encryptingWriter) flush(final bool) error {
+	nonce := chunkNonce(w.aead, w.counter)
+	sealed := w.aead.Seal(nil, nonce, w.buf, chunkAdditionalData(final))
+	size := uint32(len(sealed))
+	if final {
+		size |= encryptionFinalChunk
+	}
+	if err := binary.Write(w.out, binary.BigEndian, size); err != nil {
+		return errors.Trace(err)
+	}
+	if _, err := w.out.Write(sealed); err != nil {
+		return errors.Trace(err)
+	}
+	w.counter++
+	w.buf = w.buf[:0]
+	return nil
+}
+
+type decryptingReader struct {
+	in      io.Reader
+	aead    cipher.AEAD
+	buf     []byte
+	counter uint64
+	done    bool
+}
+
+// Read implements io.Reader.
+func (r *decryptingReader) Read(data []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.next(); err != nil {
+			return 0, errors.Trace(err)
+		}
+	}
+	n := copy(data, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+func (r *decryptingReader) next() error {
+	var size uint32
+	if err := binary.Read(r.in, binary.BigEndian, &size); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return errors.New("encrypted archive is truncated")
+		}
+		return errors.Trace(err)
+	}
+	final := size&encryptionFinalChunk != 0
+	size &^= encryptionFinalChunk
+	if size > uint32(encryptionChunkSize+r.aead.Overhead()) {
+		return errors.New("encrypted archive is corrupt")
+	}
+	sealed := make([]byte, size)
+	if _, err := io.ReadFull(r.in, sealed); err != nil {
+		return errors.New("encrypted archive is truncated")
+	}
+	nonce := chunkNonce(r.aead, r.counter)
+	plain, err := r.aead.Open(nil, nonce, sealed, chunkAdditionalData(final))
+	if err != nil {
+		return errors.New("cannot decrypt archive: wrong passphrase or corrupt data")
+	}
+	if final {
+		// Nothing may follow the final chunk; anything that does was
+		// not written by the encrypting writer.
+		var extra [1]byte
+		if n, _ := io.ReadFull(r.in, extra[:]); n > 0 {
+			return errors.New("encrypted archive has data after the final chunk")
+		}
+	}
+	r.counter++
+	r.buf = plain
+	r.done = final
+	return nil
+}
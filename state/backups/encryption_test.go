@@ -0,0 +1,174 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package backups_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"io"
+	"io/ioutil"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state/backups"
+	"github.com/juju/juju/testing"
+)
+
+type encryptionSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&encryptionSuite{})
+
+func encrypt(c *gc.C, data []byte, key backups.EncryptionKey) []byte {
+	var buf bytes.Buffer
+	w, err := backups.NewEncryptingWriter(&buf, key)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = w.Write(data)
+	c.Assert(err, jc.ErrorIsNil)
+	err = w.Close()
+	c.Assert(err, jc.ErrorIsNil)
+	return buf.Bytes()
+}
+
+func decrypt(data []byte, key backups.EncryptionKey) ([]byte, error) {
+	r, err := backups.NewDecryptingReader(bytes.NewReader(data), key)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(r)
+}
+
+func randomData(c *gc.C, size int) []byte {
+	data := make([]byte, size)
+	_, err := io.ReadFull(rand.Reader, data)
+	c.Assert(err, jc.ErrorIsNil)
+	return data
+}
+
+func (s *encryptionSuite) TestPassphraseRoundTrip(c *gc.C) {
+	key := backups.EncryptionKey{Passphrase: "sekrit"}
+	// Exercise empty, partial, exactly full and multiple chunks.
+	for _, size := range []int{0, 10, 64 * 1024, 200 * 1024} {
+		c.Logf("size %d", size)
+		data := randomData(c, size)
+		encrypted := encrypt(c, data, key)
+		if size > 0 {
+			c.Check(bytes.Contains(encrypted, data), jc.IsFalse)
+		}
+
+		decrypted, err := decrypt(encrypted, key)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Check(decrypted, jc.DeepEquals, data)
+	}
+}
+
+func (s *encryptionSuite) TestPublicKeyRoundTrip(c *gc.C) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	c.Assert(err, jc.ErrorIsNil)
+	data := randomData(c, 100*1024)
+
+	encrypted := encrypt(c, data, backups.EncryptionKey{PublicKey: &privateKey.PublicKey})
+	decrypted, err := decrypt(encrypted, backups.EncryptionKey{PrivateKey: privateKey})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(decrypted, jc.DeepEquals, data)
+}
+
+func (s *encryptionSuite) TestWrongPassphrase(c *gc.C) {
+	encrypted := encrypt(c, []byte("<data>"), backups.EncryptionKey{Passphrase: "sekrit"})
+	_, err := decrypt(encrypted, backups.EncryptionKey{Passphrase: "guess"})
+	c.Check(err, gc.ErrorMatches, "cannot decrypt archive: wrong passphrase or corrupt data")
+}
+
+func (s *encryptionSuite) TestWrongPrivateKey(c *gc.C) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	c.Assert(err, jc.ErrorIsNil)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	c.Assert(err, jc.ErrorIsNil)
+
+	encrypted := encrypt(c, []byte("<data>"), backups.EncryptionKey{PublicKey: &privateKey.PublicKey})
+	_, err = decrypt(encrypted, backups.EncryptionKey{PrivateKey: otherKey})
+	c.Check(err, gc.ErrorMatches, "cannot decrypt data key: wrong private key\\?")
+}
+
+func (s *encryptionSuite) TestMissingKey(c *gc.C) {
+	encrypted := encrypt(c, []byte("<data>"), backups.EncryptionKey{Passphrase: "sekrit"})
+	_, err := decrypt(encrypted, backups.EncryptionKey{})
+	c.Check(err, gc.ErrorMatches, "archive is encrypted with a passphrase; none given")
+}
+
+func (s *encryptionSuite) TestTruncated(c *gc.C) {
+	key := backups.EncryptionKey{Passphrase: "sekrit"}
+	encrypted := encrypt(c, randomData(c, 100*1024), key)
+	_, err := decrypt(encrypted[:len(encrypted)-1], key)
+	c.Check(err, gc.ErrorMatches, "encrypted archive is truncated")
+}
+
+func (s *encryptionSuite) TestTrailingData(c *gc.C) {
+	key := backups.EncryptionKey{Passphrase: "sekrit"}
+	encrypted := encrypt(c, randomData(c, 100*1024), key)
+	_, err := decrypt(append(encrypted, "<appended>"...), key)
+	c.Check(err, gc.ErrorMatches, "encrypted archive has data after the final chunk")
+}
+
+func (s *encryptionSuite) TestNotEncrypted(c *gc.C) {
+	_, err := decrypt([]byte("<compressed tarball>"), backups.EncryptionKey{Passphrase: "sekrit"})
+	c.Check(err, gc.ErrorMatches, "archive is not encrypted")
+}
+
+func (s *encryptionSuite) TestArchiveEncryption(c *gc.C) {
+	scheme, err := backups.ArchiveEncryption(bytes.NewBufferString("<compressed tarball>"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(scheme, gc.Equals, backups.EncryptionNone)
+
+	encrypted := encrypt(c, []byte("<data>"), backups.EncryptionKey{Passphrase: "sekrit"})
+	scheme, err = backups.ArchiveEncryption(bytes.NewReader(encrypted))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(scheme, gc.Equals, backups.EncryptionPassphrase)
+}
+
+func (s *encryptionSuite) TestScheme(c *gc.C) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	c.Assert(err, jc.ErrorIsNil)
+
+	scheme, err := backups.EncryptionKey{Passphrase: "sekrit"}.Scheme()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(scheme, gc.Equals, backups.EncryptionPassphrase)
+
+	scheme, err = backups.EncryptionKey{PublicKey: &privateKey.PublicKey}.Scheme()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(scheme, gc.Equals, backups.EncryptionPublicKey)
+
+	_, err = backups.EncryptionKey{Passphrase: "sekrit", PublicKey: &privateKey.PublicKey}.Scheme()
+	c.Check(err, gc.ErrorMatches, "cannot encrypt with both a passphrase and a public key")
+}
+
+func (s *encryptionSuite) TestParseKeys(c *gc.C) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	c.Assert(err, jc.ErrorIsNil)
+	publicDER, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	c.Assert(err, jc.ErrorIsNil)
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+	privatePEM := pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(privateKey),
+	})
+
+	publicKey, err := backups.ParsePublicKey(publicPEM)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(publicKey, jc.DeepEquals, &privateKey.PublicKey)
+
+	parsedKey, err := backups.ParsePrivateKey(privatePEM)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(parsedKey.D, jc.DeepEquals, privateKey.D)
+
+	_, err = backups.ParsePublicKey(privatePEM)
+	c.Check(err, gc.ErrorMatches, `unexpected PEM block "RSA PRIVATE KEY"`)
+	_, err = backups.ParsePrivateKey([]byte("garbage"))
+	c.Check(err, gc.ErrorMatches, "no PEM data found")
+}
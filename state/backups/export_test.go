@@ -102,11 +102,21 @@ func NewTestCreateArgs(filesToBackUp []string, db DBDumper, metar io.Reader) *cr
 	return &args
 }
 
+// SetCreateArgsKey sets the encryption key on a create() args value.
+func SetCreateArgsKey(args *createArgs, key *EncryptionKey) {
+	args.key = key
+}
+
 // ExposeCreateResult extracts the values in a create() args value.
 func ExposeCreateArgs(args *createArgs) ([]string, DBDumper) {
 	return args.filesToBackUp, args.db
 }
 
+// ExposeCreateKey extracts the encryption key in a create() args value.
+func ExposeCreateKey(args *createArgs) *EncryptionKey {
+	return args.key
+}
+
 // NewTestCreateResult builds a new create() result.
 func NewTestCreateResult(file io.ReadCloser, size int64, checksum string) *createResult {
 	result := createResult{
@@ -62,6 +62,9 @@ type Metadata struct {
 	Origin Origin
 	// Notes is an optional user-supplied annotation.
 	Notes string
+	// Encryption identifies the scheme used to encrypt the archive.
+	// It is empty if the archive is not encrypted.
+	Encryption string
 }
 
 // NewMetadata returns a new Metadata for a state backup archive.  Only
@@ -134,6 +137,7 @@ type flatMetadata struct {
 	Machine     string
 	Hostname    string
 	Version     version.Number
+	Encryption  string `json:",omitempty"`
 }
 
 // TODO(ericsnow) Move AsJSONBuffer to filestorage.Metadata.
@@ -153,6 +157,7 @@ func (m *Metadata) AsJSONBuffer() (io.Reader, error) {
 		Machine:     m.Origin.Machine,
 		Hostname:    m.Origin.Hostname,
 		Version:     m.Origin.Version,
+		Encryption:  m.Encryption,
 	}
 
 	stored := m.Stored()
@@ -195,6 +200,7 @@ func NewMetadataJSONReader(in io.Reader) (*Metadata, error) {
 		meta.Finished = &flat.Finished
 	}
 	meta.Notes = flat.Notes
+	meta.Encryption = flat.Encryption
 	meta.Origin = Origin{
 		Environment: flat.Environment,
 		Machine:     flat.Machine,
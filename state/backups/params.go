@@ -16,4 +16,6 @@ type RestoreArgs struct {
 	NewInstId      instance.Id
 	NewInstTag     names.Tag
 	NewInstSeries  string
+	// Key is used to decrypt the backup archive, if it is encrypted.
+	Key *EncryptionKey
 }
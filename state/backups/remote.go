@@ -0,0 +1,236 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package backups
+
+import (
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/juju/errors"
+	jujutxn "github.com/juju/txn"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+)
+
+// RemoteConfig describes a destination outside the controller to
+// which completed backups are copied.
+type RemoteConfig struct {
+	// Type identifies the kind of destination, e.g. "local" or "s3".
+	Type string
+	// Attrs holds the settings specific to the type of destination.
+	Attrs map[string]string
+}
+
+// secretRemoteAttrs holds the remote storage settings that must not
+// be handed back to clients.
+var secretRemoteAttrs = map[string]bool{
+	"secret-key": true,
+}
+
+// IsSecretRemoteAttr returns whether the remote storage setting with
+// the given name is secret, so that it is never sent to clients nor
+// shown by them.
+func IsSecretRemoteAttr(name string) bool {
+	return secretRemoteAttrs[name]
+}
+
+// Redacted returns a copy of the configuration with the values of
+// secret attributes removed. The attributes themselves are kept so
+// that it is still apparent that they are set.
+func (cfg RemoteConfig) Redacted() RemoteConfig {
+	result := RemoteConfig{Type: cfg.Type}
+	if cfg.Attrs != nil {
+		result.Attrs = make(map[string]string, len(cfg.Attrs))
+	}
+	for key, value := range cfg.Attrs {
+		if secretRemoteAttrs[key] {
+			value = ""
+		}
+		result.Attrs[key] = value
+	}
+	return result
+}
+
+// RemoteStorage is a destination outside the controller's database to
+// which backup archives (and their metadata) are copied, so that they
+// survive the loss of the controller.
+type RemoteStorage interface {
+	// Put stores the archive and its metadata. The metadata must
+	// already have its ID set.
+	Put(meta *Metadata, archive io.Reader) error
+
+	// Get returns the metadata and archive associated with the ID.
+	Get(id string) (*Metadata, io.ReadCloser, error)
+
+	// List returns the metadata for all stored backups.
+	List() ([]*Metadata, error)
+
+	// Remove deletes the backup from the destination.
+	Remove(id string) error
+}
+
+// checkRemoteID returns an error if the backup ID cannot safely be
+// used to name the files holding the backup in remote storage.
+func checkRemoteID(id string) error {
+	if id == "" {
+		return errors.New("missing ID")
+	}
+	if strings.ContainsAny(id, `/\`) || strings.Contains(id, "..") {
+		return errors.NotValidf("backup ID %q", id)
+	}
+	return nil
+}
+
+// remoteTypes holds the known kinds of remote storage, keyed by the
+// type name used in RemoteConfig.
+var remoteTypes = map[string]func(attrs map[string]string) (RemoteStorage, error){
+	"local": newLocalRemote,
+	"s3":    newS3Remote,
+}
+
+// RemoteTypes returns the names of the supported remote storage types.
+func RemoteTypes() []string {
+	var types []string
+	for name := range remoteTypes {
+		types = append(types, name)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// NewRemoteStorage returns the remote storage described by cfg.
+func NewRemoteStorage(cfg RemoteConfig) (RemoteStorage, error) {
+	newRemote, ok := remoteTypes[cfg.Type]
+	if !ok {
+		return nil, errors.NotValidf("backup remote type %q", cfg.Type)
+	}
+	remote, err := newRemote(cfg.Attrs)
+	return remote, errors.Annotatef(err, "invalid %s backup remote", cfg.Type)
+}
+
+// ExportToRemote copies the identified backup from local storage to
+// the remote storage.
+func ExportToRemote(backups Backups, remote RemoteStorage, id string) error {
+	meta, archive, err := backups.Get(id)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer archive.Close()
+
+	err = remote.Put(meta, archive)
+	return errors.Annotatef(err, "while copying backup %q to remote storage", id)
+}
+
+// ImportFromRemote copies the identified backup from the remote storage
+// into local storage, unless it is already there.
+func ImportFromRemote(backups Backups, remote RemoteStorage, id string) error {
+	_, archive, err := backups.Get(id)
+	if err == nil {
+		archive.Close()
+		return nil
+	}
+	if !errors.IsNotFound(err) {
+		return errors.Trace(err)
+	}
+
+	meta, archive, err := remote.Get(id)
+	if err != nil {
+		return errors.Annotatef(err, "while fetching backup %q from remote storage", id)
+	}
+	defer archive.Close()
+
+	// Local storage assigns its own ID (derived from the metadata, so
+	// it will match), so we hand over a copy without one.
+	local := NewMetadata()
+	local.Started = meta.Started
+	local.Finished = meta.Finished
+	local.Origin = meta.Origin
+	local.Notes = meta.Notes
+	local.Encryption = meta.Encryption
+	if err := local.SetFileInfo(meta.Size(), meta.Checksum(), meta.ChecksumFormat()); err != nil {
+		return errors.Trace(err)
+	}
+
+	_, err = backups.Add(archive, local)
+	return errors.Trace(err)
+}
+
+//---------------------------
+// remote configuration
+
+const storageRemoteName = "remote"
+
+// remoteConfigDoc holds the remote backup storage configuration for
+// a controller.
+type remoteConfigDoc struct {
+	ID    string            `bson:"_id"`
+	Type  string            `bson:"type"`
+	Attrs map[string]string `bson:"attrs,omitempty"`
+}
+
+// GetRemoteConfig returns the remote backup storage configured for the
+// controller. If none is configured, an error satisfying
+// errors.IsNotFound is returned.
+func GetRemoteConfig(st DB) (*RemoteConfig, error) {
+	dbWrap := newStorageDBWrapper(st.MongoSession().DB(storageDBName), storageRemoteName, st.EnvironTag().Id())
+	defer dbWrap.Close()
+
+	var doc remoteConfigDoc
+	err := dbWrap.metaColl.FindId(dbWrap.envUUID).One(&doc)
+	if err == mgo.ErrNotFound {
+		return nil, errors.NotFoundf("backup remote")
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &RemoteConfig{
+		Type:  doc.Type,
+		Attrs: doc.Attrs,
+	}, nil
+}
+
+// SetRemoteConfig sets the remote backup storage for the controller.
+// The configuration is checked before it is stored. A nil cfg removes
+// any existing configuration.
+func SetRemoteConfig(st DB, cfg *RemoteConfig) error {
+	if cfg != nil {
+		if _, err := NewRemoteStorage(*cfg); err != nil {
+			return errors.Trace(err)
+		}
+	}
+
+	dbWrap := newStorageDBWrapper(st.MongoSession().DB(storageDBName), storageRemoteName, st.EnvironTag().Id())
+	defer dbWrap.Close()
+
+	id := dbWrap.envUUID
+	buildTxn := func(int) ([]txn.Op, error) {
+		count, err := dbWrap.metaColl.FindId(id).Count()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		exists := count > 0
+		switch {
+		case cfg == nil && !exists:
+			return nil, jujutxn.ErrNoOperations
+		case cfg == nil:
+			op := dbWrap.txnOpBase(id)
+			op.Assert = txn.DocExists
+			op.Remove = true
+			return []txn.Op{op}, nil
+		case !exists:
+			return []txn.Op{dbWrap.txnOpInsert(id, &remoteConfigDoc{
+				ID:    id,
+				Type:  cfg.Type,
+				Attrs: cfg.Attrs,
+			})}, nil
+		}
+		return []txn.Op{dbWrap.txnOpUpdate(id,
+			bson.DocElem{"type", cfg.Type},
+			bson.DocElem{"attrs", cfg.Attrs},
+		)}, nil
+	}
+	err := dbWrap.txnRunner.Run(buildTxn)
+	return errors.Annotate(err, "cannot set backup remote")
+}
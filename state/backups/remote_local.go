@@ -0,0 +1,166 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package backups
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/juju/utils"
+)
+
+const (
+	remoteArchiveSuffix  = ".tar.gz"
+	remoteMetadataSuffix = ".json"
+)
+
+// localRemote is a RemoteStorage that keeps backups in a directory on
+// the controller's filesystem, which would usually be a mount point
+// for some network filesystem. Each backup is stored as an archive
+// file and a metadata file, both named after the backup ID.
+type localRemote struct {
+	dir string
+}
+
+// newLocalRemote returns a RemoteStorage for the directory named in
+// the "path" attribute.
+func newLocalRemote(attrs map[string]string) (RemoteStorage, error) {
+	dir := attrs["path"]
+	if dir == "" {
+		return nil, errors.New("missing path")
+	}
+	if !filepath.IsAbs(dir) {
+		return nil, errors.Errorf("path %q is not absolute", dir)
+	}
+	return &localRemote{dir: dir}, nil
+}
+
+func (r *localRemote) path(id, suffix string) string {
+	return filepath.Join(r.dir, id+suffix)
+}
+
+// Put implements RemoteStorage.
+func (r *localRemote) Put(meta *Metadata, archive io.Reader) error {
+	id := meta.ID()
+	if err := checkRemoteID(id); err != nil {
+		return errors.Trace(err)
+	}
+	if err := os.MkdirAll(r.dir, 0700); err != nil {
+		return errors.Trace(err)
+	}
+	metaFile, err := meta.AsJSONBuffer()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	// Write the archive first so that a backup is never listed
+	// without its archive.
+	if err := writeRemoteFile(r.path(id, remoteArchiveSuffix), archive); err != nil {
+		return errors.Annotate(err, "while writing archive")
+	}
+	if err := writeRemoteFile(r.path(id, remoteMetadataSuffix), metaFile); err != nil {
+		return errors.Annotate(err, "while writing metadata")
+	}
+	return nil
+}
+
+// writeRemoteFile writes the data to a temporary file and then renames
+// it into place, so that a partially written file is never seen.
+func writeRemoteFile(filename string, data io.Reader) error {
+	tempFile := filename + ".tmp"
+	file, err := os.OpenFile(tempFile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	_, err = io.Copy(file, data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tempFile)
+		return errors.Trace(err)
+	}
+	return errors.Trace(utils.ReplaceFile(tempFile, filename))
+}
+
+// Get implements RemoteStorage.
+func (r *localRemote) Get(id string) (*Metadata, io.ReadCloser, error) {
+	if err := checkRemoteID(id); err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	meta, err := r.metadata(id)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	archive, err := os.Open(r.path(id, remoteArchiveSuffix))
+	if os.IsNotExist(err) {
+		return nil, nil, errors.NotFoundf("backup archive %q", id)
+	} else if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	return meta, archive, nil
+}
+
+func (r *localRemote) metadata(id string) (*Metadata, error) {
+	file, err := os.Open(r.path(id, remoteMetadataSuffix))
+	if os.IsNotExist(err) {
+		return nil, errors.NotFoundf("backup metadata %q", id)
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer file.Close()
+	meta, err := NewMetadataJSONReader(file)
+	return meta, errors.Annotatef(err, "while reading metadata for %q", id)
+}
+
+// List implements RemoteStorage.
+func (r *localRemote) List() ([]*Metadata, error) {
+	dir, err := os.Open(r.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+	names, err := dir.Readdirnames(-1)
+	dir.Close()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	sort.Strings(names)
+
+	var result []*Metadata
+	for _, name := range names {
+		if !strings.HasSuffix(name, remoteMetadataSuffix) {
+			continue
+		}
+		meta, err := r.metadata(strings.TrimSuffix(name, remoteMetadataSuffix))
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		result = append(result, meta)
+	}
+	return result, nil
+}
+
+// Remove implements RemoteStorage.
+func (r *localRemote) Remove(id string) error {
+	if err := checkRemoteID(id); err != nil {
+		return errors.Trace(err)
+	}
+	// Remove the metadata first so that a backup is never listed
+	// without its archive.
+	if err := os.Remove(r.path(id, remoteMetadataSuffix)); err != nil {
+		if os.IsNotExist(err) {
+			return errors.NotFoundf("backup %q", id)
+		}
+		return errors.Trace(err)
+	}
+	if err := os.Remove(r.path(id, remoteArchiveSuffix)); err != nil && !os.IsNotExist(err) {
+		return errors.Trace(err)
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package backups
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/juju/errors"
+	"gopkg.in/amz.v3/aws"
+	"gopkg.in/amz.v3/s3"
+)
+
+// s3Remote is a RemoteStorage that keeps backups in an S3 bucket, or
+// in a bucket of any service exposing an S3 compatible API. Each
+// backup is stored as an archive object and a metadata object, both
+// named after the backup ID and optionally prefixed.
+type s3Remote struct {
+	bucket *s3.Bucket
+	prefix string
+}
+
+// newS3Remote returns a RemoteStorage for an S3 bucket. The attributes
+// are:
+//
+//	bucket     - the name of the bucket (required)
+//	access-key - the access key for the bucket (required)
+//	secret-key - the secret key for the bucket (required)
+//	region     - the AWS region, "us-east-1" by default
+//	endpoint   - the URL of an S3 compatible service; overrides region
+//	prefix     - a prefix for the names of stored objects
+func newS3Remote(attrs map[string]string) (RemoteStorage, error) {
+	for _, attr := range []string{"bucket", "access-key", "secret-key"} {
+		if attrs[attr] == "" {
+			return nil, errors.Errorf("missing %s", attr)
+		}
+	}
+	var region aws.Region
+	if endpoint := attrs["endpoint"]; endpoint != "" {
+		region = aws.Region{
+			Name:                 "custom",
+			S3Endpoint:           endpoint,
+			S3LocationConstraint: true,
+		}
+	} else {
+		name := attrs["region"]
+		if name == "" {
+			name = "us-east-1"
+		}
+		var ok bool
+		region, ok = aws.Regions[name]
+		if !ok {
+			return nil, errors.NotValidf("region %q", name)
+		}
+	}
+	auth := aws.Auth{
+		AccessKey: attrs["access-key"],
+		SecretKey: attrs["secret-key"],
+	}
+	bucket, err := s3.New(auth, region).Bucket(attrs["bucket"])
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &s3Remote{
+		bucket: bucket,
+		prefix: attrs["prefix"],
+	}, nil
+}
+
+func (r *s3Remote) key(id, suffix string) string {
+	return r.prefix + id + suffix
+}
+
+// Put implements RemoteStorage.
+func (r *s3Remote) Put(meta *Metadata, archive io.Reader) error {
+	id := meta.ID()
+	if err := checkRemoteID(id); err != nil {
+		return errors.Trace(err)
+	}
+	metaFile, err := meta.AsJSONBuffer()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	metaData, err := ioutil.ReadAll(metaFile)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	// PutBucket succeeds if we already own the bucket with the
+	// original endpoint, and fails with a known code elsewhere.
+	if err := r.bucket.PutBucket(s3.Private); err != nil && s3ErrorCode(err) != "BucketAlreadyOwnedByYou" {
+		return errors.Annotate(err, "cannot make bucket")
+	}
+	// Write the archive first so that a backup is never listed
+	// without its archive.
+	err = r.bucket.PutReader(r.key(id, remoteArchiveSuffix), archive, meta.Size(), "application/octet-stream", s3.Private)
+	if err != nil {
+		return errors.Annotate(err, "while writing archive")
+	}
+	err = r.bucket.PutReader(r.key(id, remoteMetadataSuffix), bytes.NewReader(metaData), int64(len(metaData)), "application/json", s3.Private)
+	return errors.Annotate(err, "while writing metadata")
+}
+
+// Get implements RemoteStorage.
+func (r *s3Remote) Get(id string) (*Metadata, io.ReadCloser, error) {
+	if err := checkRemoteID(id); err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	meta, err := r.metadata(id)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	archive, err := r.bucket.GetReader(r.key(id, remoteArchiveSuffix))
+	if err != nil {
+		return nil, nil, s3NotFound(err, "backup archive %q", id)
+	}
+	return meta, archive, nil
+}
+
+func (r *s3Remote) metadata(id string) (*Metadata, error) {
+	data, err := r.bucket.Get(r.key(id, remoteMetadataSuffix))
+	if err != nil {
+		return nil, s3NotFound(err, "backup metadata %q", id)
+	}
+	meta, err := NewMetadataJSONReader(bytes.NewReader(data))
+	return meta, errors.Annotatef(err, "while reading metadata for %q", id)
+}
+
+// List implements RemoteStorage.
+func (r *s3Remote) List() ([]*Metadata, error) {
+	var result []*Metadata
+	marker := ""
+	for {
+		resp, err := r.bucket.List(r.prefix, "", marker, 0)
+		if s3ErrorStatusCode(err) == 404 {
+			// The bucket is only created when the first backup is
+			// stored.
+			return nil, nil
+		} else if err != nil {
+			return nil, errors.Trace(err)
+		}
+		for _, key := range resp.Contents {
+			marker = key.Key
+			if !strings.HasSuffix(key.Key, remoteMetadataSuffix) {
+				continue
+			}
+			id := strings.TrimSuffix(strings.TrimPrefix(key.Key, r.prefix), remoteMetadataSuffix)
+			meta, err := r.metadata(id)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			result = append(result, meta)
+		}
+		if !resp.IsTruncated || len(resp.Contents) == 0 {
+			return result, nil
+		}
+	}
+}
+
+// Remove implements RemoteStorage.
+func (r *s3Remote) Remove(id string) error {
+	if err := checkRemoteID(id); err != nil {
+		return errors.Trace(err)
+	}
+	if _, err := r.metadata(id); err != nil {
+		return errors.Trace(err)
+	}
+	// Remove the metadata first so that a backup is never listed
+	// without its archive.
+	if err := r.bucket.Del(r.key(id, remoteMetadataSuffix)); err != nil {
+		return errors.Trace(err)
+	}
+	err := r.bucket.Del(r.key(id, remoteArchiveSuffix))
+	if s3ErrorStatusCode(err) == 404 {
+		return nil
+	}
+	return errors.Trace(err)
+}
+
+// s3ErrorStatusCode returns the HTTP status of the S3 request error,
+// if it is an error from an S3 operation, or 0 if it was not.
+func s3ErrorStatusCode(err error) int {
+	if err, _ := err.(*s3.Error); err != nil {
+		return err.StatusCode
+	}
+	return 0
+}
+
+// s3ErrorCode returns the text status code of the S3 error code.
+func s3ErrorCode(err error) string {
+	if err, ok := err.(*s3.Error); ok {
+		return err.Code
+	}
+	return ""
+}
+
+// s3NotFound returns an error satisfying errors.IsNotFound if err
+// reports a missing object, and err otherwise.
+func s3NotFound(err error, format string, args ...interface{}) error {
+	if s3ErrorStatusCode(err) == 404 {
+		return errors.NotFoundf(format, args...)
+	}
+	return errors.Trace(err)
+}
@@ -0,0 +1,229 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package backups_test
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	"gopkg.in/amz.v3/s3/s3test"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state/backups"
+	backupstesting "github.com/juju/juju/state/backups/testing"
+	"github.com/juju/juju/testing"
+)
+
+type remoteSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&remoteSuite{})
+
+func (s *remoteSuite) metadata(c *gc.C, id string) *backups.Metadata {
+	meta := backupstesting.NewMetadataStarted()
+	meta.SetID(id)
+	err := meta.SetFileInfo(12, "<checksum>", "SHA-1, base64 encoded")
+	c.Assert(err, jc.ErrorIsNil)
+	return meta
+}
+
+func (s *remoteSuite) checkRoundTrip(c *gc.C, remote backups.RemoteStorage) {
+	list, err := remote.List()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(list, gc.HasLen, 0)
+
+	for _, id := range []string{"spam", "eggs"} {
+		err := remote.Put(s.metadata(c, id), bytes.NewBufferString("<"+id+" data>"))
+		c.Assert(err, jc.ErrorIsNil)
+	}
+
+	meta, archive, err := remote.Get("spam")
+	c.Assert(err, jc.ErrorIsNil)
+	defer archive.Close()
+	c.Check(meta.ID(), gc.Equals, "spam")
+	c.Check(meta.Checksum(), gc.Equals, "<checksum>")
+	data, err := ioutil.ReadAll(archive)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(string(data), gc.Equals, "<spam data>")
+
+	list, err = remote.List()
+	c.Assert(err, jc.ErrorIsNil)
+	var ids []string
+	for _, meta := range list {
+		ids = append(ids, meta.ID())
+	}
+	c.Check(ids, jc.SameContents, []string{"spam", "eggs"})
+
+	err = remote.Remove("spam")
+	c.Assert(err, jc.ErrorIsNil)
+	_, _, err = remote.Get("spam")
+	c.Check(err, jc.Satisfies, errors.IsNotFound)
+	err = remote.Remove("spam")
+	c.Check(err, jc.Satisfies, errors.IsNotFound)
+
+	list, err = remote.List()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(list, gc.HasLen, 1)
+	c.Check(list[0].ID(), gc.Equals, "eggs")
+}
+
+func (s *remoteSuite) TestLocalRoundTrip(c *gc.C) {
+	remote, err := backups.NewRemoteStorage(backups.RemoteConfig{
+		Type:  "local",
+		Attrs: map[string]string{"path": filepath.Join(c.MkDir(), "backups")},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	s.checkRoundTrip(c, remote)
+}
+
+func (s *remoteSuite) TestLocalInvalidID(c *gc.C) {
+	dir := c.MkDir()
+	remote, err := backups.NewRemoteStorage(backups.RemoteConfig{
+		Type:  "local",
+		Attrs: map[string]string{"path": filepath.Join(dir, "backups")},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	for _, id := range []string{"../outside", "a/b", `a\b`, ".."} {
+		_, _, err := remote.Get(id)
+		c.Check(err, gc.ErrorMatches, `backup ID ".*" not valid`)
+		err = remote.Remove(id)
+		c.Check(err, gc.ErrorMatches, `backup ID ".*" not valid`)
+	}
+}
+
+func (s *remoteSuite) TestS3RoundTrip(c *gc.C) {
+	srv, err := s3test.NewServer(&s3test.Config{})
+	c.Assert(err, jc.ErrorIsNil)
+	defer srv.Quit()
+
+	remote, err := backups.NewRemoteStorage(backups.RemoteConfig{
+		Type: "s3",
+		Attrs: map[string]string{
+			"bucket":     "backups",
+			"access-key": "access",
+			"secret-key": "secret",
+			"endpoint":   srv.URL(),
+			"prefix":     "juju/",
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	s.checkRoundTrip(c, remote)
+}
+
+func (s *remoteSuite) TestNewRemoteStorageInvalid(c *gc.C) {
+	for i, test := range []struct {
+		cfg backups.RemoteConfig
+		err string
+	}{{
+		cfg: backups.RemoteConfig{Type: "floppy"},
+		err: `backup remote type "floppy" not valid`,
+	}, {
+		cfg: backups.RemoteConfig{Type: "local"},
+		err: "invalid local backup remote: missing path",
+	}, {
+		cfg: backups.RemoteConfig{Type: "local", Attrs: map[string]string{"path": "backups"}},
+		err: `invalid local backup remote: path "backups" is not absolute`,
+	}, {
+		cfg: backups.RemoteConfig{Type: "s3", Attrs: map[string]string{"bucket": "backups"}},
+		err: "invalid s3 backup remote: missing access-key",
+	}, {
+		cfg: backups.RemoteConfig{Type: "s3", Attrs: map[string]string{
+			"bucket":     "backups",
+			"access-key": "access",
+			"secret-key": "secret",
+			"region":     "moon-1",
+		}},
+		err: `invalid s3 backup remote: region "moon-1" not valid`,
+	}} {
+		c.Logf("test %d: %v", i, test.cfg)
+		_, err := backups.NewRemoteStorage(test.cfg)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
+
+func (s *remoteSuite) TestRedacted(c *gc.C) {
+	cfg := backups.RemoteConfig{
+		Type: "s3",
+		Attrs: map[string]string{
+			"bucket":     "backups",
+			"secret-key": "secret",
+		},
+	}
+	c.Check(cfg.Redacted(), jc.DeepEquals, backups.RemoteConfig{
+		Type: "s3",
+		Attrs: map[string]string{
+			"bucket":     "backups",
+			"secret-key": "",
+		},
+	})
+	c.Check(cfg.Attrs["secret-key"], gc.Equals, "secret")
+}
+
+func (s *remoteSuite) TestRemoteTypes(c *gc.C) {
+	c.Check(backups.RemoteTypes(), jc.DeepEquals, []string{"local", "s3"})
+}
+
+func (s *remoteSuite) TestExportImport(c *gc.C) {
+	remote, err := backups.NewRemoteStorage(backups.RemoteConfig{
+		Type:  "local",
+		Attrs: map[string]string{"path": c.MkDir()},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	meta := s.metadata(c, "spam")
+	meta.Notes = "important"
+	fake := &backupstesting.FakeBackups{
+		Meta:    meta,
+		Archive: ioutil.NopCloser(bytes.NewBufferString("<spam data>")),
+	}
+	err = backups.ExportToRemote(fake, remote, "spam")
+	c.Assert(err, jc.ErrorIsNil)
+
+	local := &importBackups{}
+	err = backups.ImportFromRemote(local, remote, "spam")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(local.Calls, jc.DeepEquals, []string{"Get", "Add"})
+	c.Check(local.MetaArg.ID(), gc.Equals, "")
+	c.Check(local.MetaArg.Notes, gc.Equals, "important")
+	c.Check(local.MetaArg.Checksum(), gc.Equals, "<checksum>")
+	c.Check(local.added, gc.Equals, "<spam data>")
+}
+
+func (s *remoteSuite) TestImportAlreadyLocal(c *gc.C) {
+	fake := &backupstesting.FakeBackups{
+		Meta:    s.metadata(c, "spam"),
+		Archive: ioutil.NopCloser(&bytes.Buffer{}),
+	}
+	err := backups.ImportFromRemote(fake, nil, "spam")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(fake.Calls, jc.DeepEquals, []string{"Get"})
+}
+
+// importBackups is a FakeBackups that reports no local backups, and
+// records the archive added to it.
+type importBackups struct {
+	backupstesting.FakeBackups
+	added string
+}
+
+func (b *importBackups) Get(id string) (*backups.Metadata, io.ReadCloser, error) {
+	b.Calls = append(b.Calls, "Get")
+	return nil, nil, errors.NotFoundf("backup %q", id)
+}
+
+func (b *importBackups) Add(archive io.Reader, meta *backups.Metadata) (string, error) {
+	b.Calls = append(b.Calls, "Add")
+	b.MetaArg = meta
+	data, err := ioutil.ReadAll(archive)
+	if err != nil {
+		return "", err
+	}
+	b.added = string(data)
+	return "spam", nil
+}
@@ -45,6 +45,9 @@ type storageMetaDoc struct {
 	Finished int64  `bson:"finished,minsize"`
 	Notes    string `bson:"notes,omitempty"`
 
+	// Encryption identifies how the archive is encrypted, if at all.
+	Encryption string `bson:"encryption,omitempty"`
+
 	// origin
 
 	Environment string         `bson:"environment"`
@@ -120,6 +123,7 @@ func docAsMetadata(doc *storageMetaDoc) *Metadata {
 	meta := NewMetadata()
 	meta.Started = metadocUnixToTime(doc.Started)
 	meta.Notes = doc.Notes
+	meta.Encryption = doc.Encryption
 
 	meta.Origin.Environment = doc.Environment
 	meta.Origin.Machine = doc.Machine
@@ -170,6 +174,7 @@ func newStorageMetaDoc(meta *Metadata) storageMetaDoc {
 		doc.Finished = metadocTimeToUnix(*meta.Finished)
 	}
 	doc.Notes = meta.Notes
+	doc.Encryption = meta.Encryption
 
 	doc.Environment = meta.Origin.Environment
 	doc.Machine = meta.Origin.Machine
@@ -4,7 +4,10 @@
 package state
 
 import (
+	"io/ioutil"
 	"net/url"
+	"os"
+	"path/filepath"
 	"regexp"
 
 	"github.com/juju/errors"
@@ -14,6 +17,7 @@ import (
 	"gopkg.in/mgo.v2/txn"
 
 	"github.com/juju/juju/mongo"
+	"github.com/juju/juju/resource"
 )
 
 // charmDoc represents the internal state of a charm in MongoDB.
@@ -35,6 +39,12 @@ type charmDoc struct {
 	Actions *charm.Actions `bson:"actions"`
 	Metrics *charm.Metrics `bson:"metrics"`
 
+	// Resources holds the resources declared in the charm's
+	// metadata.yaml, which the charm package does not read. They
+	// are recorded when the charm is added so that the archive need
+	// not be read again.
+	Resources []charmResourceDoc `bson:"resources,omitempty"`
+
 	// DEPRECATED: BundleURL is deprecated, and exists here
 	// only for migration purposes. We should remove this
 	// when migrations are no longer necessary.
@@ -46,12 +56,67 @@ type charmDoc struct {
 	Placeholder   bool   `bson:"placeholder"`
 }
 
+// charmResourceDoc records a resource declared by a charm.
+type charmResourceDoc struct {
+	Name        string `bson:"name"`
+	Type        string `bson:"type"`
+	Path        string `bson:"path"`
+	Description string `bson:"description"`
+}
+
+// charmResourceDocs returns the resources declared by the supplied
+// charm, ready to be stored in its charm document. The charm package
+// does not know about resources, so they are read from the charm's
+// metadata.yaml where there is one.
+func charmResourceDocs(ch charm.Charm) ([]charmResourceDoc, error) {
+	var metas []resource.Meta
+	switch ch := ch.(type) {
+	case *Charm:
+		return ch.doc.Resources, nil
+	case *charm.CharmArchive:
+		f, err := os.Open(ch.Path)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		defer f.Close()
+		info, err := f.Stat()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if metas, err = resource.ReadCharmArchiveMeta(f, info.Size()); err != nil {
+			return nil, errors.Trace(err)
+		}
+	case *charm.CharmDir:
+		data, err := ioutil.ReadFile(filepath.Join(ch.Path, "metadata.yaml"))
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if metas, err = resource.ParseMeta(data); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	var docs []charmResourceDoc
+	for _, meta := range metas {
+		docs = append(docs, charmResourceDoc{
+			Name:        meta.Name,
+			Type:        meta.Type,
+			Path:        meta.Path,
+			Description: meta.Description,
+		})
+	}
+	return docs, nil
+}
+
 // insertCharmOps returns the txn operations necessary to insert the supplied
 // charm data. If curl is nil, an error will be returned.
 func insertCharmOps(st *State, ch charm.Charm, curl *charm.URL, storagePath, bundleSha256 string) ([]txn.Op, error) {
 	if curl == nil {
 		return nil, errors.New("*charm.URL was nil")
 	}
+	resources, err := charmResourceDocs(ch)
+	if err != nil {
+		return nil, errors.Annotatef(err, "cannot read resources of charm %q", curl)
+	}
 	return insertAnyCharmOps(&charmDoc{
 		DocID:        curl.String(),
 		URL:          curl,
@@ -60,6 +125,7 @@ func insertCharmOps(st *State, ch charm.Charm, curl *charm.URL, storagePath, bun
 		Config:       safeConfig(ch),
 		Metrics:      ch.Metrics(),
 		Actions:      ch.Actions(),
+		Resources:    resources,
 		BundleSha256: bundleSha256,
 		StoragePath:  storagePath,
 	})
@@ -113,11 +179,16 @@ func updateCharmOps(
 	st *State, ch charm.Charm, curl *charm.URL, storagePath, bundleSha256 string, assert bson.D,
 ) ([]txn.Op, error) {
 
+	resources, err := charmResourceDocs(ch)
+	if err != nil {
+		return nil, errors.Annotatef(err, "cannot read resources of charm %q", curl)
+	}
 	updateFields := bson.D{{"$set", bson.D{
 		{"meta", ch.Meta()},
 		{"config", safeConfig(ch)},
 		{"actions", ch.Actions()},
 		{"metrics", ch.Metrics()},
+		{"resources", resources},
 		{"storagepath", storagePath},
 		{"bundlesha256", bundleSha256},
 		{"pendingupload", false},
@@ -269,6 +340,20 @@ func (c *Charm) Actions() *charm.Actions {
 	return c.doc.Actions
 }
 
+// ResourceMeta returns the resources declared by the charm.
+func (c *Charm) ResourceMeta() []resource.Meta {
+	var metas []resource.Meta
+	for _, doc := range c.doc.Resources {
+		metas = append(metas, resource.Meta{
+			Name:        doc.Name,
+			Type:        doc.Type,
+			Path:        doc.Path,
+			Description: doc.Description,
+		})
+	}
+	return metas
+}
+
 // StoragePath returns the storage path of the charm bundle.
 func (c *Charm) StoragePath() string {
 	return c.doc.StoragePath
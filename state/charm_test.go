@@ -5,12 +5,15 @@ package state_test
 
 import (
 	"bytes"
+	"os"
+	"path/filepath"
 
 	"github.com/juju/errors"
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
 	"gopkg.in/juju/charm.v6-unstable"
 
+	"github.com/juju/juju/resource"
 	"github.com/juju/juju/state"
 	"github.com/juju/juju/testcharms"
 )
@@ -69,6 +72,38 @@ func (s *CharmSuite) TestCharm(c *gc.C) {
 		})
 }
 
+func (s *CharmSuite) TestCharmResourceMeta(c *gc.C) {
+	dummy, err := s.State.Charm(s.curl)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(dummy.ResourceMeta(), gc.HasLen, 0)
+
+	path := testcharms.Repo.ClonedDirPath(c.MkDir(), "dummy")
+	f, err := os.OpenFile(filepath.Join(path, "metadata.yaml"), os.O_APPEND|os.O_WRONLY, 0)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = f.WriteString("resources:\n  data:\n    filename: data.tgz\n    description: some data\n")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(f.Close(), jc.ErrorIsNil)
+	dir, err := charm.ReadCharmDir(path)
+	c.Assert(err, jc.ErrorIsNil)
+
+	curl := charm.MustParseURL("local:quantal/dummy-2")
+	added, err := s.State.AddCharm(dir, curl, "dummy-path-2", "dummy-2-sha256")
+	c.Assert(err, jc.ErrorIsNil)
+	expected := []resource.Meta{{
+		Name:        "data",
+		Type:        resource.TypeFile,
+		Path:        "data.tgz",
+		Description: "some data",
+	}}
+	c.Check(added.ResourceMeta(), jc.DeepEquals, expected)
+
+	// The resources are recorded with the charm, so the charm need
+	// not be read again.
+	stored, err := s.State.Charm(curl)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(stored.ResourceMeta(), jc.DeepEquals, expected)
+}
+
 func (s *CharmSuite) TestCharmNotFound(c *gc.C) {
 	curl := charm.MustParseURL("local:anotherseries/dummy-1")
 	_, err := s.State.Charm(curl)
@@ -0,0 +1,351 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/juju/errors"
+	"gopkg.in/juju/charm.v6-unstable"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+)
+
+// CharmRolloutParams describes how a service's new charm should be
+// rolled out to its units.
+type CharmRolloutParams struct {
+	// CanaryUnits, if positive, is the number of units upgraded
+	// before the rollout pauses, to be resumed with ResumeCharmRollout.
+	CanaryUnits int
+
+	// BatchSize is the number of units upgraded at a time. If zero,
+	// all remaining units are upgraded at once.
+	BatchSize int
+
+	// WaitHealthy indicates whether each batch of units must report
+	// an active workload status before the next batch is upgraded.
+	WaitHealthy bool
+}
+
+// Validate returns an error if the rollout parameters are invalid.
+func (p CharmRolloutParams) Validate() error {
+	if p.CanaryUnits < 0 {
+		return errors.NotValidf("canary units %d", p.CanaryUnits)
+	}
+	if p.BatchSize < 0 {
+		return errors.NotValidf("batch size %d", p.BatchSize)
+	}
+	return nil
+}
+
+// CharmRollout describes the staged rollout of a service's charm to
+// its units.
+type CharmRollout struct {
+	CharmRolloutParams
+
+	// FromCharmURL is the charm units run until they are released.
+	FromCharmURL *charm.URL
+
+	// Released holds the names of the units that have been released
+	// to upgrade to the service's charm.
+	Released []string
+
+	// Paused indicates that no more units will be released until the
+	// rollout is resumed, for the reason given by PauseReason.
+	Paused      bool
+	PauseReason string
+}
+
+// charmRolloutDoc is the persistent form of a CharmRollout, held in
+// the service document.
+type charmRolloutDoc struct {
+	FromCharmURL *charm.URL `bson:"fromcharmurl"`
+	CanaryUnits  int        `bson:"canaryunits"`
+	BatchSize    int        `bson:"batchsize"`
+	WaitHealthy  bool       `bson:"waithealthy"`
+	Released     []string   `bson:"released"`
+	Paused       bool       `bson:"paused"`
+	PauseReason  string     `bson:"pausereason,omitempty"`
+}
+
+func (doc *charmRolloutDoc) released(unitName string) bool {
+	for _, name := range doc.Released {
+		if name == unitName {
+			return true
+		}
+	}
+	return false
+}
+
+// rolloutUpdate returns the update that records the given rollout in
+// a service document, or removes any rollout if it is nil.
+func rolloutUpdate(rollout *charmRolloutDoc) bson.D {
+	if rollout == nil {
+		return bson.D{{"$unset", bson.D{{"rollout", nil}}}}
+	}
+	return bson.D{{"$set", bson.D{{"rollout", rollout}}}}
+}
+
+// SetCharmStaged changes the charm for the service like SetCharm, but
+// releases the service's existing units to upgrade to it a few at a
+// time, as described by params. Units added during the rollout start
+// with the new charm. The rollout is advanced by AdvanceCharmRollout.
+func (s *Service) SetCharmStaged(ch *Charm, forceSeries, forceUnits bool, params CharmRolloutParams) error {
+	if err := params.Validate(); err != nil {
+		return errors.Trace(err)
+	}
+	units, err := s.rolloutUnits()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	rollout := &charmRolloutDoc{
+		FromCharmURL: s.doc.CharmURL,
+		CanaryUnits:  params.CanaryUnits,
+		BatchSize:    params.BatchSize,
+		WaitHealthy:  params.WaitHealthy,
+		Released:     []string{},
+	}
+	rollout.Released = append(rollout.Released, nextRolloutBatch(rollout, units)...)
+	return s.setCharm(ch, forceSeries, forceUnits, rollout)
+}
+
+// CharmRollout returns the staged rollout of the service's charm, and
+// whether one is in progress.
+func (s *Service) CharmRollout() (CharmRollout, bool) {
+	doc := s.doc.Rollout
+	if doc == nil {
+		return CharmRollout{}, false
+	}
+	return CharmRollout{
+		CharmRolloutParams: CharmRolloutParams{
+			CanaryUnits: doc.CanaryUnits,
+			BatchSize:   doc.BatchSize,
+			WaitHealthy: doc.WaitHealthy,
+		},
+		FromCharmURL: doc.FromCharmURL,
+		Released:     append([]string(nil), doc.Released...),
+		Paused:       doc.Paused,
+		PauseReason:  doc.PauseReason,
+	}, true
+}
+
+// CharmURLForUnit returns the charm URL the named unit of the service
+// should use, and whether it should upgrade even if in an error state.
+// While the service's charm is being rolled out, installed units that
+// have not yet been released keep the charm they were using.
+func (s *Service) CharmURLForUnit(unitName string) (*charm.URL, bool, error) {
+	rollout := s.doc.Rollout
+	if rollout == nil || rollout.released(unitName) {
+		return s.doc.CharmURL, s.doc.ForceCharm, nil
+	}
+	unit, err := s.st.Unit(unitName)
+	if err != nil {
+		return nil, false, errors.Trace(err)
+	}
+	if curl, _ := unit.CharmURL(); curl == nil {
+		// The unit has not installed a charm yet.
+		return s.doc.CharmURL, s.doc.ForceCharm, nil
+	}
+	return rollout.FromCharmURL, false, nil
+}
+
+// ResumeCharmRollout resumes a paused rollout of the service's charm.
+// Once canary units have been upgraded, the rollout continues in
+// batches.
+func (s *Service) ResumeCharmRollout() error {
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if attempt > 0 {
+			if err := s.Refresh(); err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
+		if s.doc.Rollout == nil {
+			return nil, errors.Errorf("no charm rollout in progress")
+		}
+		rollout := *s.doc.Rollout
+		rollout.Paused = false
+		rollout.PauseReason = ""
+		rollout.CanaryUnits = 0
+		return []txn.Op{s.rolloutOp(&rollout)}, nil
+	}
+	if err := s.st.run(buildTxn); err != nil {
+		return errors.Annotatef(err, "cannot resume charm rollout of service %q", s)
+	}
+	return nil
+}
+
+// AdvanceCharmRollout checks the progress of the rollout of the
+// service's charm, if any. Once the released units are running the
+// new charm, and are active if the rollout waits for them to be
+// healthy, the next batch of units is released, or the rollout ends
+// if there are none left. If any released unit is in an error state,
+// the rollout is paused.
+func (s *Service) AdvanceCharmRollout() error {
+	if err := s.advanceCharmRollout(); err != nil {
+		return errors.Annotatef(err, "cannot advance charm rollout of service %q", s)
+	}
+	return nil
+}
+
+func (s *Service) advanceCharmRollout() error {
+	if s.doc.Rollout == nil || s.doc.Rollout.Paused {
+		return nil
+	}
+	rollout := *s.doc.Rollout
+	units, err := s.rolloutUnits()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for _, unit := range units {
+		if !rollout.released(unit.Name()) {
+			continue
+		}
+		done, reason, err := s.unitUpgraded(unit, rollout.WaitHealthy)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if reason != "" {
+			logger.Infof("pausing charm rollout of service %q: %s", s, reason)
+			rollout.Paused = true
+			rollout.PauseReason = reason
+			return s.updateRollout(&rollout)
+		}
+		if !done {
+			return nil
+		}
+	}
+	batch := nextRolloutBatch(&rollout, units)
+	if len(batch) == 0 {
+		logger.Infof("charm rollout of service %q complete", s)
+		return s.updateRollout(nil)
+	}
+	if rollout.CanaryUnits > 0 {
+		rollout.Paused = true
+		rollout.PauseReason = fmt.Sprintf("%d canary units upgraded", len(rollout.Released))
+		return s.updateRollout(&rollout)
+	}
+	logger.Infof("releasing units %v of service %q to upgrade", batch, s)
+	rollout.Released = append(rollout.Released, batch...)
+	return s.updateRollout(&rollout)
+}
+
+// unitUpgraded returns whether the given released unit has finished
+// upgrading to the service's charm, or why the rollout should pause.
+func (s *Service) unitUpgraded(unit *Unit, waitHealthy bool) (bool, string, error) {
+	status, err := unit.Status()
+	if err != nil {
+		return false, "", errors.Trace(err)
+	}
+	if status.Status == StatusError {
+		return false, fmt.Sprintf("unit %q is in error state: %s", unit, status.Message), nil
+	}
+	curl, _ := unit.CharmURL()
+	if curl == nil || *curl != *s.doc.CharmURL {
+		return false, "", nil
+	}
+	if waitHealthy && status.Status != StatusActive {
+		return false, "", nil
+	}
+	return true, "", nil
+}
+
+// updateRollout replaces the service's rollout with the one given,
+// provided the service's rollout and charm have not changed.
+func (s *Service) updateRollout(rollout *charmRolloutDoc) error {
+	ops := []txn.Op{s.rolloutOp(rollout)}
+	if err := s.st.runTransaction(ops); err == txn.ErrAborted {
+		// Try again on the next check.
+		return nil
+	} else if err != nil {
+		return errors.Trace(err)
+	}
+	s.doc.Rollout = rollout
+	return nil
+}
+
+// rolloutOp returns the operation that replaces the service's rollout
+// with the one given, asserting that it is unchanged.
+func (s *Service) rolloutOp(rollout *charmRolloutDoc) txn.Op {
+	return txn.Op{
+		C:  servicesC,
+		Id: s.doc.DocID,
+		Assert: bson.D{
+			{"charmurl", s.doc.CharmURL},
+			{"rollout", s.doc.Rollout},
+		},
+		Update: rolloutUpdate(rollout),
+	}
+}
+
+// rolloutUnits returns the service's alive units, ordered by number.
+func (s *Service) rolloutUnits() ([]*Unit, error) {
+	all, err := s.AllUnits()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var units []*Unit
+	for _, unit := range all {
+		if unit.Life() == Alive {
+			units = append(units, unit)
+		}
+	}
+	sort.Sort(unitsByNumber(units))
+	return units, nil
+}
+
+// nextRolloutBatch returns the names of the units to release next.
+func nextRolloutBatch(rollout *charmRolloutDoc, units []*Unit) []string {
+	size := rollout.BatchSize
+	if rollout.CanaryUnits > 0 {
+		size = rollout.CanaryUnits
+	}
+	var batch []string
+	for _, unit := range units {
+		if size > 0 && len(batch) == size {
+			break
+		}
+		if !rollout.released(unit.Name()) {
+			batch = append(batch, unit.Name())
+		}
+	}
+	return batch
+}
+
+// unitsByNumber sorts a service's units by their unit numbers.
+type unitsByNumber []*Unit
+
+func (u unitsByNumber) Len() int      { return len(u) }
+func (u unitsByNumber) Swap(i, j int) { u[i], u[j] = u[j], u[i] }
+func (u unitsByNumber) Less(i, j int) bool {
+	return unitNumber(u[i].Name()) < unitNumber(u[j].Name())
+}
+
+func unitNumber(unitName string) int {
+	n, _ := strconv.Atoi(unitName[strings.LastIndex(unitName, "/")+1:])
+	return n
+}
+
+// AdvanceCharmRollouts advances the charm rollouts of all services
+// that have one in progress.
+func (st *State) AdvanceCharmRollouts() error {
+	services, closer := st.getCollection(servicesC)
+	defer closer()
+
+	var docs []serviceDoc
+	err := services.Find(bson.D{{"rollout", bson.D{{"$exists", true}}}}).All(&docs)
+	if err != nil {
+		return errors.Annotate(err, "cannot get services with charm rollouts")
+	}
+	for i := range docs {
+		// A failure to advance one service's rollout must not hold
+		// up the others; it will be retried on the next pass.
+		if err := newService(st, &docs[i]).AdvanceCharmRollout(); err != nil {
+			logger.Warningf("cannot advance charm rollout for service %q: %v", docs[i].Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state"
+)
+
+type CharmRolloutSuite struct {
+	ConnSuite
+	oldCharm *state.Charm
+	newCharm *state.Charm
+	service  *state.Service
+	units    []*state.Unit
+}
+
+var _ = gc.Suite(&CharmRolloutSuite{})
+
+func (s *CharmRolloutSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	s.oldCharm = s.AddMetaCharm(c, "mysql", metaBase, 2)
+	s.newCharm = s.AddMetaCharm(c, "mysql", metaBase, 3)
+	s.service = s.AddTestingService(c, "mysql", s.oldCharm)
+	s.units = nil
+	for i := 0; i < 5; i++ {
+		unit, err := s.service.AddUnit()
+		c.Assert(err, jc.ErrorIsNil)
+		err = unit.SetCharmURL(s.oldCharm.URL())
+		c.Assert(err, jc.ErrorIsNil)
+		s.units = append(s.units, unit)
+	}
+}
+
+// upgrade simulates the given units' agents upgrading to the new charm.
+func (s *CharmRolloutSuite) upgrade(c *gc.C, units ...*state.Unit) {
+	for _, unit := range units {
+		err := unit.SetCharmURL(s.newCharm.URL())
+		c.Assert(err, jc.ErrorIsNil)
+		err = unit.SetStatus(state.StatusActive, "", nil)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+}
+
+func (s *CharmRolloutSuite) advance(c *gc.C) state.CharmRollout {
+	err := s.State.AdvanceCharmRollouts()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.service.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	rollout, _ := s.service.CharmRollout()
+	return rollout
+}
+
+func (s *CharmRolloutSuite) assertCharmForUnit(c *gc.C, unit *state.Unit, expect *state.Charm) {
+	curl, _, err := s.service.CharmURLForUnit(unit.Name())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(curl, gc.DeepEquals, expect.URL())
+}
+
+func (s *CharmRolloutSuite) TestSetCharmStagedReleasesFirstBatch(c *gc.C) {
+	err := s.service.SetCharmStaged(s.newCharm, false, false, state.CharmRolloutParams{BatchSize: 2})
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.service.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+
+	curl, _ := s.service.CharmURL()
+	c.Assert(curl, gc.DeepEquals, s.newCharm.URL())
+	rollout, ok := s.service.CharmRollout()
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(rollout.FromCharmURL, gc.DeepEquals, s.oldCharm.URL())
+	c.Assert(rollout.Released, jc.DeepEquals, []string{"mysql/0", "mysql/1"})
+	c.Assert(rollout.Paused, jc.IsFalse)
+
+	s.assertCharmForUnit(c, s.units[0], s.newCharm)
+	s.assertCharmForUnit(c, s.units[1], s.newCharm)
+	s.assertCharmForUnit(c, s.units[2], s.oldCharm)
+
+	// A new unit starts with the new charm.
+	unit, err := s.service.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertCharmForUnit(c, unit, s.newCharm)
+}
+
+func (s *CharmRolloutSuite) TestAdvanceInBatches(c *gc.C) {
+	err := s.service.SetCharmStaged(s.newCharm, false, false, state.CharmRolloutParams{
+		BatchSize:   2,
+		WaitHealthy: true,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	// Nothing happens until the released units are upgraded and active.
+	rollout := s.advance(c)
+	c.Assert(rollout.Released, gc.HasLen, 2)
+	err = s.units[0].SetCharmURL(s.newCharm.URL())
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.units[1].SetCharmURL(s.newCharm.URL())
+	c.Assert(err, jc.ErrorIsNil)
+	rollout = s.advance(c)
+	c.Assert(rollout.Released, gc.HasLen, 2)
+
+	s.upgrade(c, s.units[0], s.units[1])
+	rollout = s.advance(c)
+	c.Assert(rollout.Released, jc.DeepEquals, []string{"mysql/0", "mysql/1", "mysql/2", "mysql/3"})
+
+	s.upgrade(c, s.units[2], s.units[3])
+	rollout = s.advance(c)
+	c.Assert(rollout.Released, gc.HasLen, 5)
+
+	s.upgrade(c, s.units[4])
+	s.advance(c)
+	_, ok := s.service.CharmRollout()
+	c.Assert(ok, jc.IsFalse)
+	s.assertCharmForUnit(c, s.units[4], s.newCharm)
+}
+
+func (s *CharmRolloutSuite) TestCanaryPausesUntilResumed(c *gc.C) {
+	err := s.service.SetCharmStaged(s.newCharm, false, false, state.CharmRolloutParams{
+		CanaryUnits: 1,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	s.upgrade(c, s.units[0])
+	rollout := s.advance(c)
+	c.Assert(rollout.Paused, jc.IsTrue)
+	c.Assert(rollout.PauseReason, gc.Equals, "1 canary units upgraded")
+	c.Assert(rollout.Released, gc.HasLen, 1)
+
+	// Advancing a paused rollout does nothing.
+	rollout = s.advance(c)
+	c.Assert(rollout.Released, gc.HasLen, 1)
+
+	err = s.service.ResumeCharmRollout()
+	c.Assert(err, jc.ErrorIsNil)
+	rollout = s.advance(c)
+	c.Assert(rollout.Paused, jc.IsFalse)
+	c.Assert(rollout.CanaryUnits, gc.Equals, 0)
+	c.Assert(rollout.Released, gc.HasLen, 5)
+}
+
+func (s *CharmRolloutSuite) TestErrorPausesRollout(c *gc.C) {
+	err := s.service.SetCharmStaged(s.newCharm, false, false, state.CharmRolloutParams{BatchSize: 2})
+	c.Assert(err, jc.ErrorIsNil)
+	s.upgrade(c, s.units[0])
+	err = s.units[1].SetAgentStatus(state.StatusError, `hook failed: "upgrade-charm"`, nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	rollout := s.advance(c)
+	c.Assert(rollout.Paused, jc.IsTrue)
+	c.Assert(rollout.PauseReason, gc.Equals, `unit "mysql/1" is in error state: hook failed: "upgrade-charm"`)
+	c.Assert(rollout.Released, gc.HasLen, 2)
+	s.assertCharmForUnit(c, s.units[2], s.oldCharm)
+}
+
+func (s *CharmRolloutSuite) TestSetCharmEndsRollout(c *gc.C) {
+	err := s.service.SetCharmStaged(s.newCharm, false, false, state.CharmRolloutParams{BatchSize: 1})
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.service.RollbackCharm(false)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.service.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	_, ok := s.service.CharmRollout()
+	c.Assert(ok, jc.IsFalse)
+	s.assertCharmForUnit(c, s.units[0], s.oldCharm)
+}
+
+func (s *CharmRolloutSuite) TestSetCharmStagedSameCharm(c *gc.C) {
+	err := s.service.SetCharmStaged(s.oldCharm, false, false, state.CharmRolloutParams{BatchSize: 1})
+	c.Assert(err, gc.ErrorMatches, `service "mysql" already uses charm "local:quantal/quantal-mysql-2"`)
+}
+
+func (s *CharmRolloutSuite) TestResumeWithoutRollout(c *gc.C) {
+	err := s.service.ResumeCharmRollout()
+	c.Assert(err, gc.ErrorMatches, `cannot resume charm rollout of service "mysql": no charm rollout in progress`)
+}
@@ -30,6 +30,9 @@ const (
 	cleanupAttachmentsForDyingFilesystem  cleanupKind = "filesystemAttachments"
 	cleanupEnvironmentsForDyingController cleanupKind = "environments"
 	cleanupMachinesForDyingEnvironment    cleanupKind = "environmentMachines"
+	cleanupServiceConfigHistory           cleanupKind = "serviceConfigHistory"
+	cleanupRemoteRelationScopes           cleanupKind = "remoteRelationScopes"
+	cleanupResourceBlob                   cleanupKind = "resourceBlob"
 )
 
 // cleanupDoc represents a potentially large set of documents that should be
@@ -105,6 +108,12 @@ func (st *State) Cleanup() (err error) {
 			err = st.cleanupEnvironmentsForDyingController()
 		case cleanupMachinesForDyingEnvironment:
 			err = st.cleanupMachinesForDyingEnvironment()
+		case cleanupServiceConfigHistory:
+			err = st.cleanupServiceConfigHistory(doc.Prefix)
+		case cleanupRemoteRelationScopes:
+			err = st.cleanupRemoteRelationScopes(doc.Prefix)
+		case cleanupResourceBlob:
+			err = st.cleanupResourceBlob(doc.Prefix)
 		default:
 			err = fmt.Errorf("unknown cleanup kind %q", doc.Kind)
 		}
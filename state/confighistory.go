@@ -0,0 +1,246 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	"gopkg.in/juju/charm.v6-unstable"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+)
+
+// ConfigRevision records a service's charm config settings as they
+// were after a change.
+type ConfigRevision struct {
+	// Revision numbers the service's config revisions in the order
+	// they were made. The first revision holds the settings the
+	// service had before it was first changed. Revisions are never
+	// reused by a later service of the same name, so only the first
+	// service of a name starts at 1.
+	Revision int
+
+	// CharmURL is the URL of the service's charm at the time.
+	CharmURL string
+
+	// Settings holds the service's settings after the change.
+	Settings charm.Settings
+
+	// User is the name of the user who made the change, if known.
+	User string
+
+	// Time is when the change was made.
+	Time time.Time
+
+	// RolledBackTo is the revision whose settings the change
+	// restored, or 0 if it was not a rollback.
+	RolledBackTo int
+}
+
+// configRevisionDoc is the persistent form of a ConfigRevision.
+type configRevisionDoc struct {
+	DocID        string                 `bson:"_id"`
+	EnvUUID      string                 `bson:"env-uuid"`
+	Service      string                 `bson:"service"`
+	Revision     int                    `bson:"revision"`
+	CharmURL     string                 `bson:"charmurl"`
+	Settings     map[string]interface{} `bson:"settings"`
+	User         string                 `bson:"user,omitempty"`
+	Time         int64                  `bson:"time"`
+	RolledBackTo int                    `bson:"rolledbackto,omitempty"`
+}
+
+func (doc *configRevisionDoc) revision() ConfigRevision {
+	return ConfigRevision{
+		Revision:     doc.Revision,
+		CharmURL:     doc.CharmURL,
+		Settings:     copyMap(doc.Settings, unescapeReplacer.Replace),
+		User:         doc.User,
+		Time:         time.Unix(0, doc.Time).UTC(),
+		RolledBackTo: doc.RolledBackTo,
+	}
+}
+
+// ConfigHistory returns the recorded revisions of the service's config
+// settings, oldest first.
+func (s *Service) ConfigHistory() ([]ConfigRevision, error) {
+	history, closer := s.st.getCollection(configHistoryC)
+	defer closer()
+
+	if s.doc.FirstConfigRevision == 0 {
+		return []ConfigRevision{}, nil
+	}
+	var docs []configRevisionDoc
+	err := history.Find(bson.D{
+		{"service", s.doc.Name},
+		{"revision", bson.D{{"$gte", s.doc.FirstConfigRevision}}},
+	}).Sort("revision").All(&docs)
+	if err != nil {
+		return nil, errors.Annotatef(err, "cannot get config history of service %q", s)
+	}
+	revisions := make([]ConfigRevision, len(docs))
+	for i, doc := range docs {
+		revisions[i] = doc.revision()
+	}
+	return revisions, nil
+}
+
+// ConfigRevision returns the given revision of the service's config
+// settings.
+func (s *Service) ConfigRevision(revision int) (ConfigRevision, error) {
+	history, closer := s.st.getCollection(configHistoryC)
+	defer closer()
+
+	var doc configRevisionDoc
+	err := mgo.ErrNotFound
+	if s.doc.FirstConfigRevision != 0 && revision >= s.doc.FirstConfigRevision {
+		err = history.Find(bson.D{
+			{"service", s.doc.Name},
+			{"revision", revision},
+		}).One(&doc)
+	}
+	if err == mgo.ErrNotFound {
+		return ConfigRevision{}, errors.NotFoundf("config revision %d of service %q", revision, s)
+	} else if err != nil {
+		return ConfigRevision{}, errors.Annotatef(err, "cannot get config revision %d of service %q", revision, s)
+	}
+	return doc.revision(), nil
+}
+
+// RollbackConfigSettings restores the service's config settings to
+// those of the given revision, on behalf of the named user. The
+// rollback is itself recorded as a new revision.
+func (s *Service) RollbackConfigSettings(revision int, user string) error {
+	target, err := s.ConfigRevision(revision)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	current, err := readSettings(s.st, s.settingsKey())
+	if err != nil {
+		return errors.Trace(err)
+	}
+	changes := charm.Settings{}
+	for name := range current.Map() {
+		changes[name] = nil
+	}
+	for name, value := range target.Settings {
+		changes[name] = value
+	}
+	err = s.updateConfigSettings(changes, user, revision)
+	return errors.Annotatef(err, "cannot roll back config of service %q to revision %d", s, revision)
+}
+
+// configRevisionOps returns the operations that record the given
+// settings as the service's latest config revision. If the service has
+// no history yet, the previous settings are recorded first, so the
+// change can be undone. Revision numbers come from a sequence that is
+// never reset, so that a later service of the same name never reuses
+// those of an earlier one whose history has not been cleaned up yet.
+func (s *Service) configRevisionOps(previous, settings map[string]interface{}, user string, rolledBackTo int) ([]txn.Op, error) {
+	var ops []txn.Op
+	first := s.doc.FirstConfigRevision
+	if first == 0 {
+		revision, err := s.nextConfigRevision()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		ops = append(ops, s.insertConfigRevisionOp(&configRevisionDoc{
+			Revision: revision,
+			Settings: previous,
+		}))
+		first = revision
+	}
+	revision, err := s.nextConfigRevision()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	ops = append(ops, s.insertConfigRevisionOp(&configRevisionDoc{
+		Revision:     revision,
+		Settings:     settings,
+		User:         user,
+		RolledBackTo: rolledBackTo,
+	}))
+	return append(ops, txn.Op{
+		C:      servicesC,
+		Id:     s.doc.DocID,
+		Assert: s.configRevisionAssert(),
+		Update: bson.D{{"$set", bson.D{
+			{"firstconfigrevision", first},
+			{"configrevision", revision},
+		}}},
+	}), nil
+}
+
+// nextConfigRevision allocates the next config revision number of the
+// service.
+func (s *Service) nextConfigRevision() (int, error) {
+	seq, err := s.st.sequence("configrevision#" + s.doc.Name)
+	if err != nil {
+		return -1, errors.Trace(err)
+	}
+	return seq + 1, nil
+}
+
+func (s *Service) insertConfigRevisionOp(doc *configRevisionDoc) txn.Op {
+	doc.DocID = fmt.Sprintf("%s#%d", s.doc.Name, doc.Revision)
+	doc.EnvUUID = s.st.EnvironUUID()
+	doc.Service = s.doc.Name
+	doc.CharmURL = s.doc.CharmURL.String()
+	doc.Settings = copyMap(doc.Settings, escapeReplacer.Replace)
+	doc.Time = nowToTheSecond().UnixNano()
+	return txn.Op{
+		C:      configHistoryC,
+		Id:     doc.DocID,
+		Assert: txn.DocMissing,
+		Insert: doc,
+	}
+}
+
+// configRevisionAssert asserts that no config revision has been
+// recorded for the service since it was read.
+func (s *Service) configRevisionAssert() bson.D {
+	if s.doc.ConfigRevision == 0 {
+		return bson.D{{"configrevision", bson.D{{"$exists", false}}}}
+	}
+	return bson.D{{"configrevision", s.doc.ConfigRevision}}
+}
+
+// configHistoryCleanupPrefix returns the prefix of the cleanup that
+// removes the service's config history once the service is removed.
+// It holds the range of the service's revisions as well as its name,
+// so that the history of a later service of the same name is kept.
+func (s *Service) configHistoryCleanupPrefix() string {
+	return fmt.Sprintf("%s#%d#%d", s.doc.Name, s.doc.FirstConfigRevision, s.doc.ConfigRevision)
+}
+
+// cleanupServiceConfigHistory removes the config history of a service
+// after the service has been removed. The prefix is that returned by
+// configHistoryCleanupPrefix.
+func (st *State) cleanupServiceConfigHistory(prefix string) error {
+	parts := strings.Split(prefix, "#")
+	if len(parts) != 3 {
+		return errors.NotValidf("config history cleanup prefix %q", prefix)
+	}
+	serviceName := parts[0]
+	first, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return errors.NotValidf("config history cleanup prefix %q", prefix)
+	}
+	last, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return errors.NotValidf("config history cleanup prefix %q", prefix)
+	}
+	history, closer := st.getCollection(configHistoryC)
+	defer closer()
+	_, err = history.Writeable().RemoveAll(bson.D{
+		{"service", serviceName},
+		{"revision", bson.D{{"$gte", first}, {"$lte", last}}},
+	})
+	return errors.Annotatef(err, "cannot remove config history of service %q", serviceName)
+}
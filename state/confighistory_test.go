@@ -0,0 +1,165 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/charm.v6-unstable"
+
+	"github.com/juju/juju/state"
+)
+
+type ConfigHistorySuite struct {
+	ConnSuite
+	charm   *state.Charm
+	service *state.Service
+}
+
+var _ = gc.Suite(&ConfigHistorySuite{})
+
+func (s *ConfigHistorySuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	s.charm = s.AddTestingCharm(c, "dummy")
+	s.service = s.AddTestingService(c, "dummy", s.charm)
+}
+
+func (s *ConfigHistorySuite) assertSettings(c *gc.C, expect charm.Settings) {
+	settings, err := s.service.ConfigSettings()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(settings, jc.DeepEquals, expect)
+}
+
+func (s *ConfigHistorySuite) TestNoHistory(c *gc.C) {
+	history, err := s.service.ConfigHistory()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(history, gc.HasLen, 0)
+}
+
+func (s *ConfigHistorySuite) TestUpdateRecordsRevisions(c *gc.C) {
+	err := s.service.UpdateConfigSettingsBy(charm.Settings{"title": "one"}, "bob")
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.service.UpdateConfigSettings(charm.Settings{"title": nil, "username": "admin"})
+	c.Assert(err, jc.ErrorIsNil)
+
+	history, err := s.service.ConfigHistory()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(history, gc.HasLen, 3)
+	for i, revision := range history {
+		c.Check(revision.Revision, gc.Equals, i+1)
+		c.Check(revision.CharmURL, gc.Equals, "local:quantal/quantal-dummy-1")
+		c.Check(revision.Time.IsZero(), jc.IsFalse)
+		c.Check(revision.RolledBackTo, gc.Equals, 0)
+	}
+	c.Check(history[0].Settings, gc.HasLen, 0)
+	c.Check(history[0].User, gc.Equals, "")
+	c.Check(history[1].Settings, jc.DeepEquals, charm.Settings{"title": "one"})
+	c.Check(history[1].User, gc.Equals, "bob")
+	c.Check(history[2].Settings, jc.DeepEquals, charm.Settings{"username": "admin"})
+	c.Check(history[2].User, gc.Equals, "")
+
+	revision, err := s.service.ConfigRevision(2)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(revision, jc.DeepEquals, history[1])
+}
+
+func (s *ConfigHistorySuite) TestInvalidUpdateNotRecorded(c *gc.C) {
+	err := s.service.UpdateConfigSettingsBy(charm.Settings{"no-such-option": "x"}, "bob")
+	c.Assert(err, gc.ErrorMatches, `unknown option "no-such-option"`)
+	history, err := s.service.ConfigHistory()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(history, gc.HasLen, 0)
+}
+
+func (s *ConfigHistorySuite) TestRevisionNotFound(c *gc.C) {
+	_, err := s.service.ConfigRevision(42)
+	c.Assert(err, gc.ErrorMatches, `config revision 42 of service "dummy" not found`)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+
+	err = s.service.RollbackConfigSettings(42, "bob")
+	c.Assert(err, gc.ErrorMatches, `config revision 42 of service "dummy" not found`)
+}
+
+func (s *ConfigHistorySuite) TestRollback(c *gc.C) {
+	err := s.service.UpdateConfigSettingsBy(charm.Settings{"title": "one"}, "bob")
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.service.UpdateConfigSettingsBy(charm.Settings{"title": "two", "username": "admin"}, "bob")
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.service.RollbackConfigSettings(2, "alice")
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertSettings(c, charm.Settings{"title": "one"})
+
+	err = s.service.RollbackConfigSettings(1, "alice")
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertSettings(c, charm.Settings{})
+
+	history, err := s.service.ConfigHistory()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(history, gc.HasLen, 5)
+	c.Check(history[3].User, gc.Equals, "alice")
+	c.Check(history[3].RolledBackTo, gc.Equals, 2)
+	c.Check(history[3].Settings, jc.DeepEquals, charm.Settings{"title": "one"})
+	c.Check(history[4].RolledBackTo, gc.Equals, 1)
+	c.Check(history[4].Settings, gc.HasLen, 0)
+}
+
+func (s *ConfigHistorySuite) TestRemoveServiceCleansUpHistory(c *gc.C) {
+	err := s.service.UpdateConfigSettings(charm.Settings{"title": "one"})
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.service.Destroy()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.Cleanup()
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.service = s.AddTestingService(c, "dummy", s.charm)
+	history, err := s.service.ConfigHistory()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(history, gc.HasLen, 0)
+}
+
+func (s *ConfigHistorySuite) TestConcurrentUpdatesRecorded(c *gc.C) {
+	defer state.SetBeforeHooks(c, s.State, func() {
+		service, err := s.State.Service("dummy")
+		c.Assert(err, jc.ErrorIsNil)
+		err = service.UpdateConfigSettingsBy(charm.Settings{"title": "one"}, "alice")
+		c.Assert(err, jc.ErrorIsNil)
+	}).Check()
+	err := s.service.UpdateConfigSettingsBy(charm.Settings{"username": "admin"}, "bob")
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.service.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	history, err := s.service.ConfigHistory()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(history, gc.HasLen, 3)
+	c.Check(history[0].Settings, gc.HasLen, 0)
+	c.Check(history[1].User, gc.Equals, "alice")
+	c.Check(history[2].User, gc.Equals, "bob")
+	c.Check(history[2].Settings, jc.DeepEquals, charm.Settings{"title": "one", "username": "admin"})
+}
+
+func (s *ConfigHistorySuite) TestRecreatedServiceKeepsHistory(c *gc.C) {
+	err := s.service.UpdateConfigSettings(charm.Settings{"title": "one"})
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.service.Destroy()
+	c.Assert(err, jc.ErrorIsNil)
+
+	// The history of the new service is neither mixed up with that of
+	// the old one nor removed with it.
+	s.service = s.AddTestingService(c, "dummy", s.charm)
+	err = s.service.UpdateConfigSettings(charm.Settings{"title": "two"})
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.Cleanup()
+	c.Assert(err, jc.ErrorIsNil)
+
+	history, err := s.service.ConfigHistory()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(history, gc.HasLen, 2)
+	c.Check(history[0].Revision, gc.Equals, 3)
+	c.Check(history[0].Settings, gc.HasLen, 0)
+	c.Check(history[1].Revision, gc.Equals, 4)
+	c.Check(history[1].Settings, jc.DeepEquals, charm.Settings{"title": "two"})
+}
@@ -0,0 +1,268 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	jujutxn "github.com/juju/txn"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+)
+
+const controllerCertificatesKey = "controllerCertificates"
+
+// controllerCertificatesDoc records the CA certificates used by the
+// controller. It lives in the stateServers collection alongside the
+// state serving info. Older controllers do not have this document;
+// ControllerCertificates falls back to the CA certificate used to
+// connect to mongo and the CA private key in the state serving info.
+//
+// The CA private keys are kept here, as the state serving info's CA
+// private key already is, because every state server must sign its
+// own server certificate with the same CA, and the controller's
+// database is the only store the state servers share. The collection
+// belongs to the controller rather than to any environment, and the
+// keys are only ever read by ControllerSigningCA, on behalf of the
+// state servers; ControllerCertificates, which serves everything
+// else, never returns them.
+type controllerCertificatesDoc struct {
+	CACert             string               `bson:"cacert"`
+	CAPrivateKey       string               `bson:"caprivatekey"`
+	NewCACert          string               `bson:"newcacert"`
+	NewCAPrivateKey    string               `bson:"newcaprivatekey"`
+	SwitchTime         time.Time            `bson:"switchtime"`
+	ServerCertsRotated time.Time            `bson:"servercertsrotated"`
+	ServerCertExpiries map[string]time.Time `bson:"servercertexpiries,omitempty"`
+}
+
+// ControllerCertificates describes the CA certificates that agents and
+// clients should trust when connecting to the controller, and the CA
+// that the controller's state servers should use to sign their server
+// certificates.
+//
+// While a CA is being rotated, the new CA is trusted alongside the
+// current one, but server certificates continue to be signed by the
+// current CA until SwitchTime. This gives agents and clients time to
+// learn about the new CA before any server presents a certificate
+// signed by it.
+type ControllerCertificates struct {
+	// CACert holds the certificate of the CA that currently signs
+	// server certificates.
+	CACert string
+
+	// NewCACert holds the certificate of the CA that replaces
+	// CACert at SwitchTime. It is empty if no CA rotation is in
+	// progress.
+	NewCACert  string
+	SwitchTime time.Time
+
+	// ServerCertsRotated records when server certificates were last
+	// asked to be rotated. Server certificates issued before then
+	// should be replaced.
+	ServerCertsRotated time.Time
+
+	// ServerCertExpiries holds the expiry time of the server
+	// certificate in use by each state server machine, keyed by
+	// machine id.
+	ServerCertExpiries map[string]time.Time
+}
+
+// switched reports whether any pending CA should have taken over
+// signing server certificates by the given time.
+func (c ControllerCertificates) switched(now time.Time) bool {
+	return c.NewCACert != "" && !now.Before(c.SwitchTime)
+}
+
+// SigningCACert returns the certificate of the CA that signs server
+// certificates at the given time.
+func (c ControllerCertificates) SigningCACert(now time.Time) string {
+	if c.switched(now) {
+		return c.NewCACert
+	}
+	return c.CACert
+}
+
+// TrustedCACerts returns the PEM-encoded bundle of CA certificates that
+// agents and clients should trust at the given time. The CA that signs
+// server certificates comes first.
+func (c ControllerCertificates) TrustedCACerts(now time.Time) string {
+	switch {
+	case c.NewCACert == "":
+		return c.CACert
+	case c.switched(now):
+		return c.NewCACert
+	}
+	return c.CACert + c.NewCACert
+}
+
+// ControllerCertificates returns the CA certificates used by the
+// controller, without their private keys.
+func (st *State) ControllerCertificates() (ControllerCertificates, error) {
+	doc, err := st.controllerCertificatesDoc()
+	if err != nil {
+		return ControllerCertificates{}, errors.Trace(err)
+	}
+	return ControllerCertificates{
+		CACert:             doc.CACert,
+		NewCACert:          doc.NewCACert,
+		SwitchTime:         doc.SwitchTime,
+		ServerCertsRotated: doc.ServerCertsRotated,
+		ServerCertExpiries: doc.ServerCertExpiries,
+	}, nil
+}
+
+// ControllerSigningCA returns the certificate and private key of the CA
+// that state servers should use to sign their server certificates at
+// the given time. It must only be used on behalf of state servers.
+func (st *State) ControllerSigningCA(now time.Time) (caCert, caPrivateKey string, err error) {
+	doc, err := st.controllerCertificatesDoc()
+	if err != nil {
+		return "", "", errors.Trace(err)
+	}
+	if doc.NewCACert != "" && !now.Before(doc.SwitchTime) {
+		return doc.NewCACert, doc.NewCAPrivateKey, nil
+	}
+	return doc.CACert, doc.CAPrivateKey, nil
+}
+
+// controllerCertificatesDoc returns the stored controller certificates
+// document, or one synthesised from the state serving info if the
+// controller predates CA rotation.
+func (st *State) controllerCertificatesDoc() (*controllerCertificatesDoc, error) {
+	stateServers, closer := st.getCollection(stateServersC)
+	defer closer()
+
+	var doc controllerCertificatesDoc
+	err := stateServers.FindId(controllerCertificatesKey).One(&doc)
+	if err == nil {
+		return &doc, nil
+	}
+	if err != mgo.ErrNotFound {
+		return nil, errors.Annotate(err, "cannot get controller certificates")
+	}
+	info, err := st.StateServingInfo()
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, errors.Trace(err)
+	}
+	return &controllerCertificatesDoc{
+		CACert:       st.mongoInfo.CACert,
+		CAPrivateKey: info.CAPrivateKey,
+	}, nil
+}
+
+// upsertControllerCertificatesOp returns an operation that writes the
+// given controller certificates document, asserting that the existing
+// document, if any, still has the CA certificates in existing.
+func (st *State) upsertControllerCertificatesOp(existing, doc *controllerCertificatesDoc) (txn.Op, error) {
+	stateServers, closer := st.getCollection(stateServersC)
+	defer closer()
+
+	count, err := stateServers.FindId(controllerCertificatesKey).Count()
+	if err != nil {
+		return txn.Op{}, errors.Trace(err)
+	}
+	if count == 0 {
+		return txn.Op{
+			C:      stateServersC,
+			Id:     controllerCertificatesKey,
+			Assert: txn.DocMissing,
+			Insert: doc,
+		}, nil
+	}
+	return txn.Op{
+		C:  stateServersC,
+		Id: controllerCertificatesKey,
+		Assert: bson.D{
+			{"cacert", existing.CACert},
+			{"newcacert", existing.NewCACert},
+			{"servercertsrotated", existing.ServerCertsRotated},
+		},
+		Update: bson.D{{"$set", doc}},
+	}, nil
+}
+
+// RotateControllerCertificates asks all state servers to replace their
+// server certificates. If newCACert is not empty, it and newCAPrivateKey
+// are added to the set of trusted CAs immediately, and replace the
+// current CA for signing server certificates once overlap has passed.
+//
+// It is an error to start a CA rotation while another is in progress.
+func (st *State) RotateControllerCertificates(newCACert, newCAPrivateKey string, overlap time.Duration) error {
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		existing, err := st.controllerCertificatesDoc()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		now := nowToTheSecond()
+		doc := *existing
+		if doc.NewCACert != "" && !now.Before(doc.SwitchTime) {
+			// The previous rotation has completed, so the new CA is
+			// now the only one.
+			doc.CACert, doc.CAPrivateKey = doc.NewCACert, doc.NewCAPrivateKey
+			doc.NewCACert, doc.NewCAPrivateKey = "", ""
+			doc.SwitchTime = time.Time{}
+		}
+		if newCACert != "" {
+			if doc.NewCACert != "" {
+				return nil, errors.Errorf(
+					"CA rotation already in progress until %s",
+					doc.SwitchTime.Format(time.RFC3339),
+				)
+			}
+			doc.NewCACert, doc.NewCAPrivateKey = newCACert, newCAPrivateKey
+			doc.SwitchTime = now.Add(overlap)
+		}
+		doc.ServerCertsRotated = now
+		op, err := st.upsertControllerCertificatesOp(existing, &doc)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return []txn.Op{op}, nil
+	}
+	if newCACert != "" && newCAPrivateKey == "" {
+		return errors.New("cannot rotate controller certificates: missing CA private key")
+	}
+	if err := st.run(buildTxn); err != nil {
+		return errors.Annotate(err, "cannot rotate controller certificates")
+	}
+	return nil
+}
+
+// SetServerCertificateExpiry records the expiry time of the server
+// certificate in use by the state server with the given machine id.
+func (st *State) SetServerCertificateExpiry(machineId string, expiry time.Time) error {
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		existing, err := st.controllerCertificatesDoc()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if existing.ServerCertExpiries[machineId].Equal(expiry) {
+			return nil, jujutxn.ErrNoOperations
+		}
+		doc := *existing
+		doc.ServerCertExpiries = make(map[string]time.Time)
+		for id, t := range existing.ServerCertExpiries {
+			doc.ServerCertExpiries[id] = t
+		}
+		doc.ServerCertExpiries[machineId] = expiry
+		op, err := st.upsertControllerCertificatesOp(existing, &doc)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return []txn.Op{op}, nil
+	}
+	if err := st.run(buildTxn); err != nil {
+		return errors.Annotatef(err, "cannot set server certificate expiry for machine %s", machineId)
+	}
+	return nil
+}
+
+// WatchControllerCertificates returns a NotifyWatcher that notifies
+// when the controller certificates change.
+func (st *State) WatchControllerCertificates() NotifyWatcher {
+	return newEntityWatcher(st, stateServersC, controllerCertificatesKey)
+}
@@ -0,0 +1,130 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	statetesting "github.com/juju/juju/state/testing"
+	"github.com/juju/juju/testing"
+)
+
+type ControllerCertificatesSuite struct {
+	ConnSuite
+}
+
+var _ = gc.Suite(&ControllerCertificatesSuite{})
+
+func (s *ControllerCertificatesSuite) TestControllerCertificatesDefault(c *gc.C) {
+	certs, err := s.State.ControllerCertificates()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(certs.CACert, gc.Equals, testing.CACert)
+	c.Assert(certs.NewCACert, gc.Equals, "")
+	c.Assert(certs.TrustedCACerts(time.Now()), gc.Equals, testing.CACert)
+	c.Assert(s.State.CACert(), gc.Equals, testing.CACert)
+}
+
+func (s *ControllerCertificatesSuite) TestRotateServerCertificates(c *gc.C) {
+	before := time.Now().Add(-time.Second)
+	err := s.State.RotateControllerCertificates("", "", 0)
+	c.Assert(err, jc.ErrorIsNil)
+
+	certs, err := s.State.ControllerCertificates()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(certs.CACert, gc.Equals, testing.CACert)
+	c.Assert(certs.NewCACert, gc.Equals, "")
+	c.Assert(certs.ServerCertsRotated.After(before), jc.IsTrue)
+}
+
+func (s *ControllerCertificatesSuite) TestRotateCA(c *gc.C) {
+	err := s.State.RotateControllerCertificates(testing.OtherCACert, testing.OtherCAKey, time.Hour)
+	c.Assert(err, jc.ErrorIsNil)
+
+	certs, err := s.State.ControllerCertificates()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(certs.NewCACert, gc.Equals, testing.OtherCACert)
+
+	// Both CAs are trusted during the overlap, but the current CA
+	// still signs server certificates.
+	now := time.Now()
+	c.Assert(certs.TrustedCACerts(now), gc.Equals, testing.CACert+testing.OtherCACert)
+	c.Assert(s.State.CACert(), gc.Equals, testing.CACert+testing.OtherCACert)
+	c.Assert(certs.SigningCACert(now), gc.Equals, testing.CACert)
+	caCert, _, err := s.State.ControllerSigningCA(now)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(caCert, gc.Equals, testing.CACert)
+
+	// Once the overlap has passed, only the new CA is used.
+	later := now.Add(2 * time.Hour)
+	c.Assert(certs.TrustedCACerts(later), gc.Equals, testing.OtherCACert)
+	c.Assert(certs.SigningCACert(later), gc.Equals, testing.OtherCACert)
+	caCert, caKey, err := s.State.ControllerSigningCA(later)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(caCert, gc.Equals, testing.OtherCACert)
+	c.Assert(caKey, gc.Equals, testing.OtherCAKey)
+}
+
+func (s *ControllerCertificatesSuite) TestRotateCAInProgress(c *gc.C) {
+	err := s.State.RotateControllerCertificates(testing.OtherCACert, testing.OtherCAKey, time.Hour)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.RotateControllerCertificates(testing.CACert, testing.CAKey, time.Hour)
+	c.Assert(err, gc.ErrorMatches, "cannot rotate controller certificates: CA rotation already in progress until .*")
+
+	// Server certificates can still be rotated.
+	err = s.State.RotateControllerCertificates("", "", 0)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *ControllerCertificatesSuite) TestRotateCACompletesPreviousRotation(c *gc.C) {
+	err := s.State.RotateControllerCertificates(testing.OtherCACert, testing.OtherCAKey, 0)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.RotateControllerCertificates(testing.CACert, testing.CAKey, time.Hour)
+	c.Assert(err, jc.ErrorIsNil)
+
+	certs, err := s.State.ControllerCertificates()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(certs.CACert, gc.Equals, testing.OtherCACert)
+	c.Assert(certs.NewCACert, gc.Equals, testing.CACert)
+	_, caKey, err := s.State.ControllerSigningCA(time.Now())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(caKey, gc.Equals, testing.OtherCAKey)
+}
+
+func (s *ControllerCertificatesSuite) TestRotateCAMissingKey(c *gc.C) {
+	err := s.State.RotateControllerCertificates(testing.OtherCACert, "", time.Hour)
+	c.Assert(err, gc.ErrorMatches, "cannot rotate controller certificates: missing CA private key")
+}
+
+func (s *ControllerCertificatesSuite) TestSetServerCertificateExpiry(c *gc.C) {
+	expiry := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	err := s.State.SetServerCertificateExpiry("0", expiry)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.SetServerCertificateExpiry("1", expiry.Add(time.Hour))
+	c.Assert(err, jc.ErrorIsNil)
+
+	certs, err := s.State.ControllerCertificates()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(certs.CACert, gc.Equals, testing.CACert)
+	c.Assert(certs.ServerCertExpiries, gc.HasLen, 2)
+	c.Assert(certs.ServerCertExpiries["0"].Equal(expiry), jc.IsTrue)
+	c.Assert(certs.ServerCertExpiries["1"].Equal(expiry.Add(time.Hour)), jc.IsTrue)
+}
+
+func (s *ControllerCertificatesSuite) TestWatchControllerCertificates(c *gc.C) {
+	w := s.State.WatchControllerCertificates()
+	defer statetesting.AssertStop(c, w)
+	wc := statetesting.NewNotifyWatcherC(c, s.State, w)
+	wc.AssertOneChange()
+
+	err := s.State.RotateControllerCertificates(testing.OtherCACert, testing.OtherCAKey, time.Hour)
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertOneChange()
+
+	err = s.State.SetServerCertificateExpiry("0", time.Now())
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertOneChange()
+}
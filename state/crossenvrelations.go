@@ -0,0 +1,397 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"gopkg.in/juju/charm.v6-unstable"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+)
+
+// SyncCrossEnvRelations mirrors relations between environments on the
+// controller. For every relation of a consumed remote service, the
+// offering environment gets a relation between the offered service and
+// a proxy for the consuming service; and the units in scope on each side
+// of the relation, with their settings, appear in the other environment
+// as units of the corresponding remote service. Relations with proxies
+// whose consuming relations are gone, or whose consuming user no longer
+// has access to the offering environment, are destroyed, and proxies
+// without relations are removed.
+//
+// A failure to sync one remote service is logged, and does not stop the
+// others from being synced.
+//
+// The State must be connected to the controller environment.
+func (st *State) SyncCrossEnvRelations() error {
+	envs, err := st.AllEnvironments()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	states := map[string]*State{st.EnvironUUID(): st}
+	defer func() {
+		for uuid, envSt := range states {
+			if uuid != st.EnvironUUID() {
+				envSt.Close()
+			}
+		}
+	}()
+	getState := func(uuid string) (*State, error) {
+		if envSt, ok := states[uuid]; ok {
+			return envSt, nil
+		}
+		envSt, err := st.ForEnviron(names.NewEnvironTag(uuid))
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		states[uuid] = envSt
+		return envSt, nil
+	}
+	isEnv := make(map[string]bool)
+	for _, env := range envs {
+		isEnv[env.UUID()] = true
+	}
+	for _, env := range envs {
+		envSt, err := getState(env.UUID())
+		if err != nil {
+			logger.Errorf("cannot sync remote services in environment %q: %v", env.Name(), err)
+			continue
+		}
+		remoteServices, err := envSt.AllRemoteServices()
+		if err != nil {
+			logger.Errorf("cannot sync remote services in environment %q: %v", env.Name(), err)
+			continue
+		}
+		for _, remoteSvc := range remoteServices {
+			sourceUUID := remoteSvc.SourceEnviron().Id()
+			if !isEnv[sourceUUID] {
+				logger.Warningf("environment %q of remote service %q no longer exists", sourceUUID, remoteSvc)
+				continue
+			}
+			sourceSt, err := getState(sourceUUID)
+			if err == nil {
+				if remoteSvc.OfferName() != "" {
+					err = syncConsumedService(envSt, sourceSt, remoteSvc)
+				} else {
+					err = syncConsumerProxy(envSt, sourceSt, remoteSvc)
+				}
+			}
+			if err != nil {
+				logger.Errorf("cannot sync remote service %q in environment %q: %v", remoteSvc, env.Name(), err)
+			}
+		}
+	}
+	return nil
+}
+
+// consumerProxyName returns the name of the proxy in an offering
+// environment for the named service in the consuming environment.
+// Environment names are only unique per owner, so the name includes
+// the consuming environment's UUID instead.
+func consumerProxyName(serviceName string, consumer names.EnvironTag) (string, error) {
+	name := serviceName + "-env" + strings.Replace(consumer.Id(), "-", "", -1)
+	if !names.IsValidService(name) {
+		return "", errors.NotValidf("proxy service name %q", name)
+	}
+	return name, nil
+}
+
+// hasEnvironmentAccess returns whether the user has access to the
+// environment.
+func hasEnvironmentAccess(st *State, user names.UserTag) (bool, error) {
+	if user.Id() == "" {
+		return false, nil
+	}
+	_, err := st.EnvironmentUser(user)
+	if errors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, errors.Trace(err)
+	}
+	return true, nil
+}
+
+// syncConsumedService mirrors the relations of a remote service consumed
+// from the offering environment into that environment.
+func syncConsumedService(consumer, offerer *State, remoteSvc *RemoteService) error {
+	relations, err := remoteSvc.Relations()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if len(relations) == 0 {
+		return nil
+	}
+	hasAccess, err := hasEnvironmentAccess(offerer, remoteSvc.ConsumedBy())
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if !hasAccess {
+		logger.Warningf("user %q no longer has access to the environment of remote service %q", remoteSvc.ConsumedBy().Canonical(), remoteSvc)
+	}
+	offeredSvc, err := offerer.Service(remoteSvc.SourceServiceName())
+	if errors.IsNotFound(err) {
+		logger.Warningf("service %q offered as %q no longer exists", remoteSvc.SourceServiceName(), remoteSvc.OfferName())
+		return nil
+	} else if err != nil {
+		return errors.Trace(err)
+	}
+	for _, rel := range relations {
+		remoteEp, err := rel.Endpoint(remoteSvc.Name())
+		if err != nil {
+			return errors.Trace(err)
+		}
+		localEps, err := rel.RelatedEndpoints(remoteSvc.Name())
+		if err != nil {
+			return errors.Trace(err)
+		}
+		localEp := localEps[0]
+		offeredEp, err := offeredSvc.Endpoint(remoteEp.Name)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		proxyName, err := consumerProxyName(localEp.ServiceName, consumer.EnvironTag())
+		if err != nil {
+			return errors.Trace(err)
+		}
+		proxyEp := Endpoint{ServiceName: proxyName, Relation: localEp.Relation}
+		if rel.Life() != Alive || !hasAccess {
+			if err := destroyRelation(offerer, offeredEp, proxyEp); err != nil {
+				return errors.Trace(err)
+			}
+			if !hasAccess {
+				if err := leaveRelationScopes(rel, remoteSvc.Name()); err != nil {
+					return errors.Trace(err)
+				}
+			}
+			continue
+		}
+		if err := ensureConsumerProxy(offerer, consumer, proxyName, localEp); err != nil {
+			return errors.Trace(err)
+		}
+		offeredRel, err := offerer.EndpointsRelation(offeredEp, proxyEp)
+		if errors.IsNotFound(err) {
+			logger.Infof("relating %s to %s in environment %q", offeredEp, proxyEp, offerer.EnvironUUID())
+			offeredRel, err = offerer.AddRelation(offeredEp, proxyEp)
+		}
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if offeredRel.Life() != Alive {
+			continue
+		}
+		if err := mirrorRelationUnits(rel, localEp.ServiceName, offeredRel, proxyName); err != nil {
+			return errors.Trace(err)
+		}
+		if err := mirrorRelationUnits(offeredRel, offeredEp.ServiceName, rel, remoteSvc.Name()); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// ensureConsumerProxy ensures that the offering environment has a proxy
+// with the given name and endpoint for a service in the consuming
+// environment.
+func ensureConsumerProxy(offerer, consumer *State, proxyName string, localEp Endpoint) error {
+	proxy, err := offerer.RemoteService(proxyName)
+	if errors.IsNotFound(err) {
+		_, err = offerer.AddRemoteService(AddRemoteServiceArgs{
+			Name:              proxyName,
+			SourceEnv:         consumer.EnvironTag(),
+			SourceServiceName: localEp.ServiceName,
+			Endpoints:         []charm.Relation{localEp.Relation},
+		})
+		return errors.Trace(err)
+	} else if err != nil {
+		return errors.Trace(err)
+	}
+	if proxy.SourceEnviron() != consumer.EnvironTag() || proxy.SourceServiceName() != localEp.ServiceName {
+		return errors.Errorf("remote service %q already exists for another service", proxyName)
+	}
+	op, ok := proxy.addEndpointOp(localEp.Relation)
+	if !ok {
+		return nil
+	}
+	return errors.Trace(offerer.runTransaction([]txn.Op{op}))
+}
+
+// syncConsumerProxy destroys the relations of a proxy for a service in
+// the consuming environment that are no longer matched by relations to
+// the consumed service there, by a user with access to the offering
+// environment, and removes the proxy once it has no relations left.
+func syncConsumerProxy(offerer, consumer *State, proxy *RemoteService) error {
+	relations, err := proxy.Relations()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	consumed, err := consumer.AllRemoteServices()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for _, rel := range relations {
+		if rel.Life() != Alive {
+			continue
+		}
+		proxyEp, err := rel.Endpoint(proxy.Name())
+		if err != nil {
+			return errors.Trace(err)
+		}
+		offeredEps, err := rel.RelatedEndpoints(proxy.Name())
+		if err != nil {
+			return errors.Trace(err)
+		}
+		offeredEp := offeredEps[0]
+		alive := false
+		for _, remoteSvc := range consumed {
+			if remoteSvc.SourceEnviron().Id() != offerer.EnvironUUID() || remoteSvc.SourceServiceName() != offeredEp.ServiceName {
+				continue
+			}
+			remoteEp, err := remoteSvc.Endpoint(offeredEp.Name)
+			if err != nil {
+				continue
+			}
+			hasAccess, err := hasEnvironmentAccess(offerer, remoteSvc.ConsumedBy())
+			if err != nil {
+				return errors.Trace(err)
+			} else if !hasAccess {
+				continue
+			}
+			localEp := Endpoint{ServiceName: proxy.SourceServiceName(), Relation: proxyEp.Relation}
+			consumerRel, err := consumer.EndpointsRelation(remoteEp, localEp)
+			if errors.IsNotFound(err) {
+				continue
+			} else if err != nil {
+				return errors.Trace(err)
+			}
+			if consumerRel.Life() == Alive {
+				alive = true
+				break
+			}
+		}
+		if !alive {
+			logger.Infof("destroying relation %q in environment %q", rel, offerer.EnvironUUID())
+			if err := rel.Destroy(); err != nil {
+				return errors.Trace(err)
+			}
+		}
+	}
+	if err := proxy.Refresh(); err != nil {
+		return errors.Trace(err)
+	}
+	if proxy.doc.RelationCount == 0 {
+		logger.Infof("removing remote service %q from environment %q", proxy, offerer.EnvironUUID())
+		return errors.Trace(proxy.Destroy())
+	}
+	return nil
+}
+
+// destroyRelation destroys the relation between the given endpoints, if
+// it exists.
+func destroyRelation(st *State, eps ...Endpoint) error {
+	rel, err := st.EndpointsRelation(eps...)
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return errors.Trace(err)
+	}
+	if rel.Life() != Alive {
+		return nil
+	}
+	return errors.Trace(rel.Destroy())
+}
+
+// leaveRelationScopes makes the units of the named remote service that
+// are in scope of the relation leave it.
+func leaveRelationScopes(rel *Relation, serviceName string) error {
+	units, err := rel.unitsInScope(serviceName)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for unitName := range units {
+		ru, err := rel.RemoteUnit(unitName)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if err := ru.LeaveScope(); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// mirrorRelationUnits makes the units of the source service in scope of
+// the source relation, with their settings, the units of the destination
+// remote service in scope of the destination relation.
+func mirrorRelationUnits(src *Relation, srcService string, dst *Relation, dstService string) error {
+	srcUnits, err := src.unitsInScope(srcService)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	dstUnits, err := dst.unitsInScope(dstService)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	mirrored := make(map[string]bool)
+	for unitName, key := range srcUnits {
+		settings, err := readSettings(src.st, key)
+		if errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return errors.Trace(err)
+		}
+		remoteName := fmt.Sprintf("%s/%d", dstService, unitNumber(unitName))
+		mirrored[remoteName] = true
+		ru, err := dst.RemoteUnit(remoteName)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if err := ru.EnterScope(settings.Map()); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	for unitName := range dstUnits {
+		if mirrored[unitName] {
+			continue
+		}
+		ru, err := dst.RemoteUnit(unitName)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if err := ru.LeaveScope(); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// unitsInScope returns the scope keys of the units of the named service
+// that are in the relation's global scope and not departing it, by unit
+// name.
+func (r *Relation) unitsInScope(serviceName string) (map[string]string, error) {
+	relationScopes, closer := r.st.getCollection(relationScopesC)
+	defer closer()
+
+	ep, err := r.Endpoint(serviceName)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	prefix := fmt.Sprintf("r#%d#%s#%s/", r.Id(), ep.Role, serviceName)
+	sel := bson.D{
+		{"key", bson.D{{"$regex", "^" + prefix}}},
+		{"departing", bson.D{{"$ne", true}}},
+	}
+	var docs []relationScopeDoc
+	if err := relationScopes.Find(sel).All(&docs); err != nil {
+		return nil, errors.Annotatef(err, "cannot get units in scope of relation %q", r)
+	}
+	units := make(map[string]string)
+	for _, doc := range docs {
+		units[doc.unitName()] = doc.Key
+	}
+	return units, nil
+}
@@ -0,0 +1,197 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/charm.v6-unstable"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/testing/factory"
+)
+
+type CrossEnvRelationsSuite struct {
+	ConnSuite
+	consumer      *state.State
+	consumingUser names.UserTag
+	proxyName     string
+	mysqlUnit     *state.Unit
+	wpUnit        *state.Unit
+	consumerRel   *state.Relation
+}
+
+var _ = gc.Suite(&CrossEnvRelationsSuite{})
+
+func (s *CrossEnvRelationsSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	mysql := s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
+	var err error
+	s.mysqlUnit, err = mysql.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	offer, err := s.State.AddOffer("shared-db", "mysql", "server", s.Owner)
+	c.Assert(err, jc.ErrorIsNil)
+	offeredEp, err := offer.Endpoint()
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.consumer = s.Factory.MakeEnvironment(c, &factory.EnvParams{Name: "app"})
+	s.AddCleanup(func(*gc.C) { s.consumer.Close() })
+	s.proxyName = "wordpress-env" + strings.Replace(s.consumer.EnvironUUID(), "-", "", -1)
+	s.consumingUser = s.Factory.MakeUser(c, &factory.UserParams{Name: "bob"}).UserTag()
+	wordpress := state.AddTestingService(c, s.consumer, "wordpress", state.AddTestingCharm(c, s.consumer, "wordpress"), s.Owner)
+	s.wpUnit, err = wordpress.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.consumer.AddRemoteService(state.AddRemoteServiceArgs{
+		Name:              "db",
+		SourceEnv:         s.State.EnvironTag(),
+		SourceServiceName: "mysql",
+		OfferName:         "shared-db",
+		ConsumedBy:        s.consumingUser,
+		Endpoints:         []charm.Relation{offeredEp.Relation},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	eps, err := s.consumer.InferEndpoints("wordpress", "db")
+	c.Assert(err, jc.ErrorIsNil)
+	s.consumerRel, err = s.consumer.AddRelation(eps...)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *CrossEnvRelationsSuite) sync(c *gc.C) {
+	err := s.State.SyncCrossEnvRelations()
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *CrossEnvRelationsSuite) offeredRelation(c *gc.C) *state.Relation {
+	eps, err := s.State.InferEndpoints("mysql", s.proxyName)
+	c.Assert(err, jc.ErrorIsNil)
+	rel, err := s.State.EndpointsRelation(eps...)
+	c.Assert(err, jc.ErrorIsNil)
+	return rel
+}
+
+func (s *CrossEnvRelationsSuite) TestSyncAddsProxyAndRelation(c *gc.C) {
+	s.sync(c)
+	proxy, err := s.State.RemoteService(s.proxyName)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(proxy.SourceEnviron(), gc.Equals, s.consumer.EnvironTag())
+	c.Assert(proxy.SourceServiceName(), gc.Equals, "wordpress")
+	c.Assert(proxy.OfferName(), gc.Equals, "")
+	rel := s.offeredRelation(c)
+	c.Assert(rel.String(), gc.Equals, s.proxyName+":db mysql:server")
+
+	// Syncing again changes nothing.
+	s.sync(c)
+	c.Assert(s.offeredRelation(c).Id(), gc.Equals, rel.Id())
+}
+
+func (s *CrossEnvRelationsSuite) TestSyncMirrorsUnits(c *gc.C) {
+	s.sync(c)
+	offeredRel := s.offeredRelation(c)
+	mysqlRU, err := offeredRel.Unit(s.mysqlUnit)
+	c.Assert(err, jc.ErrorIsNil)
+	err = mysqlRU.EnterScope(map[string]interface{}{"host": "10.0.0.1", "password": "sekrit"})
+	c.Assert(err, jc.ErrorIsNil)
+	wpRU, err := s.consumerRel.Unit(s.wpUnit)
+	c.Assert(err, jc.ErrorIsNil)
+	err = wpRU.EnterScope(map[string]interface{}{"host": "10.1.0.1"})
+	c.Assert(err, jc.ErrorIsNil)
+	s.sync(c)
+
+	settings, err := wpRU.ReadSettings("db/0")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(settings, gc.DeepEquals, map[string]interface{}{"host": "10.0.0.1", "password": "sekrit"})
+	settings, err = mysqlRU.ReadSettings(s.proxyName + "/0")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(settings, gc.DeepEquals, map[string]interface{}{"host": "10.1.0.1"})
+
+	// Units leaving scope on one side leave it on the other.
+	err = wpRU.LeaveScope()
+	c.Assert(err, jc.ErrorIsNil)
+	s.sync(c)
+	remoteUnit, err := offeredRel.RemoteUnit(s.proxyName + "/0")
+	c.Assert(err, jc.ErrorIsNil)
+	inScope, err := remoteUnit.InScope()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(inScope, jc.IsFalse)
+}
+
+func (s *CrossEnvRelationsSuite) TestSyncRemovesRelationAndProxy(c *gc.C) {
+	s.sync(c)
+	offeredRel := s.offeredRelation(c)
+	err := s.consumerRel.Destroy()
+	c.Assert(err, jc.ErrorIsNil)
+	s.sync(c)
+
+	err = offeredRel.Refresh()
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	_, err = s.State.RemoteService(s.proxyName)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *CrossEnvRelationsSuite) TestSyncProxiesDistinctPerEnvironment(c *gc.C) {
+	// An environment with the same name, owned by another user, gets
+	// its own proxy.
+	other := s.Factory.MakeEnvironment(c, &factory.EnvParams{
+		Name:  "app",
+		Owner: s.Factory.MakeUser(c, &factory.UserParams{Name: "mary"}).UserTag(),
+	})
+	defer other.Close()
+	wordpress := state.AddTestingService(c, other, "wordpress", state.AddTestingCharm(c, other, "wordpress"), s.Owner)
+	_, err := wordpress.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	offeredEp, err := s.consumerRel.Endpoint("db")
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = other.AddRemoteService(state.AddRemoteServiceArgs{
+		Name:              "db",
+		SourceEnv:         s.State.EnvironTag(),
+		SourceServiceName: "mysql",
+		OfferName:         "shared-db",
+		ConsumedBy:        s.consumingUser,
+		Endpoints:         []charm.Relation{offeredEp.Relation},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	eps, err := other.InferEndpoints("wordpress", "db")
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = other.AddRelation(eps...)
+	c.Assert(err, jc.ErrorIsNil)
+	s.sync(c)
+
+	s.offeredRelation(c)
+	otherProxyName := "wordpress-env" + strings.Replace(other.EnvironUUID(), "-", "", -1)
+	proxy, err := s.State.RemoteService(otherProxyName)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(proxy.SourceEnviron(), gc.Equals, other.EnvironTag())
+}
+
+func (s *CrossEnvRelationsSuite) TestSyncRevokedAccess(c *gc.C) {
+	s.sync(c)
+	offeredRel := s.offeredRelation(c)
+	mysqlRU, err := offeredRel.Unit(s.mysqlUnit)
+	c.Assert(err, jc.ErrorIsNil)
+	err = mysqlRU.EnterScope(nil)
+	c.Assert(err, jc.ErrorIsNil)
+	s.sync(c)
+	remoteUnit, err := s.consumerRel.RemoteUnit("db/0")
+	c.Assert(err, jc.ErrorIsNil)
+	inScope, err := remoteUnit.InScope()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(inScope, jc.IsTrue)
+
+	err = s.State.RemoveEnvironmentUser(s.consumingUser)
+	c.Assert(err, jc.ErrorIsNil)
+	s.sync(c)
+
+	// The offering side relation is destroyed, and the offered units
+	// leave the consuming side relation.
+	err = offeredRel.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(offeredRel.Life(), gc.Equals, state.Dying)
+	inScope, err = remoteUnit.InScope()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(inScope, jc.IsFalse)
+}
@@ -0,0 +1,416 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// The description package defines a versioned, serialisable
+// description of a juju environment: its machines, services, units,
+// relations, settings, config history, constraints, storage
+// constraints, opened ports, service leaders, storage, status, health,
+// annotations, labels, users and blocks. Descriptions are written by
+// state.State.Export and used by state.State.Import to build a new
+// environment.
+//
+// Descriptions refer to entities by their names and ids rather than
+// by database keys, and serialise to YAML with their contents sorted,
+// so that two snapshots of an environment may be compared with diff.
+package description
+
+// Version is the version of the environment description written by
+// this package. Descriptions of any other version are rejected by
+// Deserialize.
+const Version = 1
+
+// Environment describes a juju environment.
+type Environment struct {
+	// Version is the version of the description; see Version.
+	Version int `yaml:"version"`
+
+	UUID  string `yaml:"uuid"`
+	Name  string `yaml:"name"`
+	Owner string `yaml:"owner"`
+
+	// Config holds the environment's configuration attributes.
+	Config map[string]interface{} `yaml:"config"`
+
+	Constraints string            `yaml:"constraints,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+
+	// Sequences holds the counters from which the environment
+	// allocates ids for new entities, so that ids are not reused
+	// once the environment is imported.
+	Sequences map[string]int `yaml:"sequences,omitempty"`
+
+	Users     []User     `yaml:"users,omitempty"`
+	Machines  []Machine  `yaml:"machines,omitempty"`
+	Charms    []Charm    `yaml:"charms,omitempty"`
+	Services  []Service  `yaml:"services,omitempty"`
+	Relations []Relation `yaml:"relations,omitempty"`
+
+	StoragePools []StoragePool     `yaml:"storage-pools,omitempty"`
+	Storage      []StorageInstance `yaml:"storage,omitempty"`
+	Volumes      []Volume          `yaml:"volumes,omitempty"`
+	Filesystems  []Filesystem      `yaml:"filesystems,omitempty"`
+
+	Blocks []Block `yaml:"blocks,omitempty"`
+}
+
+// User describes a user's access to the environment.
+type User struct {
+	// Name holds the canonical name of the user.
+	Name        string `yaml:"name"`
+	DisplayName string `yaml:"display-name,omitempty"`
+	CreatedBy   string `yaml:"created-by"`
+	Access      string `yaml:"access"`
+}
+
+// Machine describes a machine and the containers it hosts.
+type Machine struct {
+	Id          string   `yaml:"id"`
+	Series      string   `yaml:"series"`
+	Jobs        []string `yaml:"jobs"`
+	Constraints string   `yaml:"constraints,omitempty"`
+	Placement   string   `yaml:"placement,omitempty"`
+
+	// Instance describes the provider instance of the machine, and
+	// is nil if the machine has not been provisioned.
+	Instance *Instance `yaml:"instance,omitempty"`
+
+	ProviderAddresses []Address `yaml:"provider-addresses,omitempty"`
+	MachineAddresses  []Address `yaml:"machine-addresses,omitempty"`
+
+	// PasswordHash holds the hash of the machine agent's password,
+	// so that the agent can still log in once the environment is
+	// imported.
+	PasswordHash string `yaml:"password-hash,omitempty"`
+
+	// OpenedPorts holds the port ranges opened on the machine by its
+	// units, on each network.
+	OpenedPorts []OpenedPorts `yaml:"opened-ports,omitempty"`
+
+	Status      *Status           `yaml:"status,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+	Labels      map[string]string `yaml:"labels,omitempty"`
+
+	Containers []Machine `yaml:"containers,omitempty"`
+}
+
+// OpenedPorts describes the port ranges opened on a network of a
+// machine.
+type OpenedPorts struct {
+	Network string      `yaml:"network"`
+	Ports   []PortRange `yaml:"ports"`
+}
+
+// PortRange describes a range of ports opened by a unit.
+type PortRange struct {
+	Unit     string `yaml:"unit"`
+	FromPort int    `yaml:"from-port"`
+	ToPort   int    `yaml:"to-port"`
+	Protocol string `yaml:"protocol"`
+}
+
+// Instance describes the provider instance of a machine.
+type Instance struct {
+	Id       string `yaml:"id"`
+	Nonce    string `yaml:"nonce"`
+	Hardware string `yaml:"hardware,omitempty"`
+}
+
+// Address describes a network address of a machine.
+type Address struct {
+	Value string `yaml:"value"`
+	Type  string `yaml:"type"`
+	Scope string `yaml:"scope,omitempty"`
+}
+
+// Charm describes a charm used by the environment. The charm's
+// metadata, configuration, actions and metrics are held in the
+// form in which they are stored by juju; the charm's archive is not
+// part of the description.
+type Charm struct {
+	URL          string                 `yaml:"url"`
+	StoragePath  string                 `yaml:"storage-path"`
+	BundleSha256 string                 `yaml:"bundle-sha256"`
+	Meta         map[string]interface{} `yaml:"meta"`
+	Config       map[string]interface{} `yaml:"config,omitempty"`
+	Actions      map[string]interface{} `yaml:"actions,omitempty"`
+	Metrics      map[string]interface{} `yaml:"metrics,omitempty"`
+}
+
+// Service describes a service and its units.
+type Service struct {
+	Name     string `yaml:"name"`
+	Series   string `yaml:"series"`
+	Owner    string `yaml:"owner"`
+	CharmURL string `yaml:"charm-url"`
+	Exposed  bool   `yaml:"exposed,omitempty"`
+	MinUnits int    `yaml:"min-units,omitempty"`
+
+	// PreviousCharmURL holds the URL of the charm the service used
+	// before its charm was last changed, to which it may be rolled
+	// back.
+	PreviousCharmURL string `yaml:"previous-charm-url,omitempty"`
+
+	// Leader holds the name of the unit that is the service's
+	// leader, if it has one.
+	Leader string `yaml:"leader,omitempty"`
+
+	Constraints      string                        `yaml:"constraints,omitempty"`
+	Settings         map[string]interface{}        `yaml:"settings,omitempty"`
+	Storage          map[string]StorageConstraints `yaml:"storage,omitempty"`
+	EndpointBindings map[string]string             `yaml:"endpoint-bindings,omitempty"`
+
+	// ConfigHistory holds the recorded revisions of the service's
+	// settings, oldest first.
+	ConfigHistory []ConfigRevision `yaml:"config-history,omitempty"`
+
+	// Status is nil if the service's status has never been set, in
+	// which case it is derived from the status of its units.
+	Status      *Status           `yaml:"status,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+	Labels      map[string]string `yaml:"labels,omitempty"`
+
+	Units []Unit `yaml:"units,omitempty"`
+}
+
+// ConfigRevision describes a service's settings as they were after a
+// change.
+type ConfigRevision struct {
+	Revision int                    `yaml:"revision"`
+	CharmURL string                 `yaml:"charm-url"`
+	Settings map[string]interface{} `yaml:"settings,omitempty"`
+	User     string                 `yaml:"user,omitempty"`
+
+	// Time holds the time the change was made, in RFC 3339 format.
+	Time string `yaml:"time"`
+
+	// RolledBackTo holds the revision whose settings the change
+	// restored, if it was a rollback.
+	RolledBackTo int `yaml:"rolled-back-to,omitempty"`
+}
+
+// StorageConstraints describes the storage to be provisioned for
+// each unit of a service.
+type StorageConstraints struct {
+	Pool  string `yaml:"pool"`
+	Size  uint64 `yaml:"size"`
+	Count uint64 `yaml:"count"`
+}
+
+// Unit describes a unit of a service.
+type Unit struct {
+	Name string `yaml:"name"`
+
+	// Machine holds the id of the machine the unit is assigned to.
+	Machine string `yaml:"machine,omitempty"`
+
+	// Principal holds the name of the principal unit of a
+	// subordinate unit.
+	Principal string `yaml:"principal,omitempty"`
+
+	// CharmURL holds the URL of the charm the unit agent is running,
+	// if it has reported one.
+	CharmURL string `yaml:"charm-url,omitempty"`
+
+	// PasswordHash holds the hash of the unit agent's password.
+	PasswordHash string `yaml:"password-hash,omitempty"`
+
+	WorkloadStatus *Status `yaml:"workload-status,omitempty"`
+	AgentStatus    *Status `yaml:"agent-status,omitempty"`
+
+	// HealthStatus holds the results of the unit's health checks,
+	// and is nil if they have never been reported.
+	HealthStatus *Status `yaml:"health-status,omitempty"`
+
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+	Labels      map[string]string `yaml:"labels,omitempty"`
+}
+
+// Relation describes a relation between services.
+type Relation struct {
+	Id        int        `yaml:"id"`
+	Key       string     `yaml:"key"`
+	Endpoints []Endpoint `yaml:"endpoints"`
+}
+
+// Endpoint describes one of the endpoints of a relation.
+type Endpoint struct {
+	Service   string `yaml:"service"`
+	Name      string `yaml:"name"`
+	Role      string `yaml:"role"`
+	Interface string `yaml:"interface"`
+	Optional  bool   `yaml:"optional,omitempty"`
+	Limit     int    `yaml:"limit,omitempty"`
+	Scope     string `yaml:"scope"`
+
+	// UnitSettings holds the settings of each of the service's units
+	// in scope of the relation, by unit name.
+	UnitSettings map[string]map[string]interface{} `yaml:"unit-settings,omitempty"`
+}
+
+// StoragePool describes a storage pool of the environment.
+type StoragePool struct {
+	Name     string                 `yaml:"name"`
+	Provider string                 `yaml:"provider"`
+	Attrs    map[string]interface{} `yaml:"attrs,omitempty"`
+}
+
+// StorageInstance describes a storage instance and the units it is
+// attached to.
+type StorageInstance struct {
+	Id          string `yaml:"id"`
+	Kind        string `yaml:"kind"`
+	StorageName string `yaml:"storage-name"`
+
+	// Owner holds the tag of the unit or service that owns the
+	// storage instance.
+	Owner    string `yaml:"owner"`
+	CharmURL string `yaml:"charm-url,omitempty"`
+
+	// Attachments holds the names of the units the storage instance
+	// is attached to.
+	Attachments []string `yaml:"attachments,omitempty"`
+}
+
+// Volume describes a volume and the machines it is attached to.
+type Volume struct {
+	Name string `yaml:"name"`
+
+	// StorageId holds the id of the storage instance the volume is
+	// assigned to, if any.
+	StorageId string `yaml:"storage-id,omitempty"`
+
+	// Binding holds the tag of the entity to which the volume's
+	// lifecycle is bound.
+	Binding string `yaml:"binding,omitempty"`
+
+	// Params holds the parameters the volume is to be provisioned
+	// with, and Info describes the provisioned volume. Params is
+	// nil once the volume is provisioned, and Info until it is.
+	Params *StorageParams `yaml:"params,omitempty"`
+	Info   *VolumeInfo    `yaml:"info,omitempty"`
+
+	Status      *Status            `yaml:"status,omitempty"`
+	Attachments []VolumeAttachment `yaml:"attachments,omitempty"`
+}
+
+// StorageParams describes the parameters with which a volume or a
+// filesystem is to be provisioned.
+type StorageParams struct {
+	Pool string `yaml:"pool"`
+	Size uint64 `yaml:"size"`
+}
+
+// VolumeInfo describes a provisioned volume.
+type VolumeInfo struct {
+	VolumeId   string `yaml:"volume-id"`
+	HardwareId string `yaml:"hardware-id,omitempty"`
+	Pool       string `yaml:"pool"`
+	Size       uint64 `yaml:"size"`
+	Persistent bool   `yaml:"persistent,omitempty"`
+}
+
+// VolumeAttachment describes the attachment of a volume to a machine.
+type VolumeAttachment struct {
+	Machine string `yaml:"machine"`
+
+	// Params holds the parameters the attachment is to be made
+	// with, and Info describes the attachment once it is made.
+	// Params is nil once the attachment is made, and Info until it
+	// is.
+	Params *VolumeAttachmentParams `yaml:"params,omitempty"`
+	Info   *VolumeAttachmentInfo   `yaml:"info,omitempty"`
+}
+
+// VolumeAttachmentParams describes the parameters with which a volume
+// is to be attached to a machine.
+type VolumeAttachmentParams struct {
+	ReadOnly bool `yaml:"read-only,omitempty"`
+}
+
+// VolumeAttachmentInfo describes an attachment of a volume to a
+// machine that has been made.
+type VolumeAttachmentInfo struct {
+	DeviceName string `yaml:"device-name,omitempty"`
+	DeviceLink string `yaml:"device-link,omitempty"`
+	BusAddress string `yaml:"bus-address,omitempty"`
+	ReadOnly   bool   `yaml:"read-only,omitempty"`
+}
+
+// Filesystem describes a filesystem and the machines it is attached
+// to.
+type Filesystem struct {
+	Id string `yaml:"id"`
+
+	// StorageId holds the id of the storage instance the filesystem
+	// is assigned to, if any, and Volume the name of the volume the
+	// filesystem is created on, if any.
+	StorageId string `yaml:"storage-id,omitempty"`
+	Volume    string `yaml:"volume,omitempty"`
+
+	// Binding holds the tag of the entity to which the filesystem's
+	// lifecycle is bound.
+	Binding string `yaml:"binding,omitempty"`
+
+	// Params holds the parameters the filesystem is to be
+	// provisioned with, and Info describes the provisioned
+	// filesystem. Params is nil once the filesystem is provisioned,
+	// and Info until it is.
+	Params *StorageParams  `yaml:"params,omitempty"`
+	Info   *FilesystemInfo `yaml:"info,omitempty"`
+
+	Status      *Status                `yaml:"status,omitempty"`
+	Attachments []FilesystemAttachment `yaml:"attachments,omitempty"`
+}
+
+// FilesystemInfo describes a provisioned filesystem.
+type FilesystemInfo struct {
+	// FilesystemId is empty for filesystems created on volumes.
+	FilesystemId string `yaml:"filesystem-id,omitempty"`
+	Pool         string `yaml:"pool"`
+	Size         uint64 `yaml:"size"`
+}
+
+// FilesystemAttachment describes the attachment of a filesystem to a
+// machine.
+type FilesystemAttachment struct {
+	Machine string `yaml:"machine"`
+
+	// Params holds the parameters the attachment is to be made
+	// with, and Info describes the attachment once it is made.
+	// Params is nil once the attachment is made, and Info until it
+	// is.
+	Params *FilesystemAttachmentParams `yaml:"params,omitempty"`
+	Info   *FilesystemAttachmentInfo   `yaml:"info,omitempty"`
+}
+
+// FilesystemAttachmentParams describes the parameters with which a
+// filesystem is to be attached to a machine.
+type FilesystemAttachmentParams struct {
+	Location string `yaml:"location,omitempty"`
+	ReadOnly bool   `yaml:"read-only,omitempty"`
+}
+
+// FilesystemAttachmentInfo describes an attachment of a filesystem to
+// a machine that has been made.
+type FilesystemAttachmentInfo struct {
+	MountPoint string `yaml:"mount-point,omitempty"`
+	ReadOnly   bool   `yaml:"read-only,omitempty"`
+}
+
+// Block describes a block switched on to prevent changes to the
+// environment.
+type Block struct {
+	Type    string `yaml:"type"`
+	Message string `yaml:"message,omitempty"`
+}
+
+// Status describes the status of an entity.
+type Status struct {
+	Value   string                 `yaml:"value"`
+	Message string                 `yaml:"message,omitempty"`
+	Data    map[string]interface{} `yaml:"data,omitempty"`
+
+	// Updated holds the time the status was last set, in RFC 3339
+	// format.
+	Updated string `yaml:"updated,omitempty"`
+}
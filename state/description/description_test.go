@@ -0,0 +1,327 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package description_test
+
+import (
+	stdtesting "testing"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state/description"
+)
+
+func TestPackage(t *stdtesting.T) {
+	gc.TestingT(t)
+}
+
+type descriptionSuite struct{}
+
+var _ = gc.Suite(&descriptionSuite{})
+
+func minimalEnvironment() *description.Environment {
+	return &description.Environment{
+		Version: description.Version,
+		UUID:    "deadbeef-0bad-400d-8000-4b1d0d06f00d",
+		Name:    "staging",
+		Owner:   "admin@local",
+		Config: map[string]interface{}{
+			"name": "staging",
+			"type": "dummy",
+		},
+		Users: []description.User{{
+			Name:      "admin@local",
+			CreatedBy: "admin@local",
+			Access:    "admin",
+		}},
+		Machines: []description.Machine{{
+			Id:     "0",
+			Series: "trusty",
+			Jobs:   []string{"JobHostUnits"},
+			Instance: &description.Instance{
+				Id:       "i-0",
+				Nonce:    "nonce",
+				Hardware: "arch=amd64 mem=2048M",
+			},
+			OpenedPorts: []description.OpenedPorts{{
+				Network: "juju-public",
+				Ports: []description.PortRange{{
+					Unit:     "wordpress/2",
+					FromPort: 80,
+					ToPort:   80,
+					Protocol: "tcp",
+				}},
+			}},
+			Status: &description.Status{
+				Value:   "started",
+				Updated: "2015-11-02T10:00:00Z",
+			},
+			Labels: map[string]string{"tier": "web"},
+			Containers: []description.Machine{{
+				Id:     "0/lxc/0",
+				Series: "trusty",
+				Jobs:   []string{"JobHostUnits"},
+			}},
+		}},
+		Charms: []description.Charm{{
+			URL:          "cs:trusty/mysql-1",
+			StoragePath:  "charms/mysql",
+			BundleSha256: "abc",
+			Meta: map[string]interface{}{
+				"name": "mysql",
+			},
+		}, {
+			URL:          "cs:trusty/wordpress-3",
+			StoragePath:  "charms/wordpress",
+			BundleSha256: "def",
+			Meta: map[string]interface{}{
+				"name": "wordpress",
+			},
+		}},
+		Services: []description.Service{{
+			Name:     "mysql",
+			Series:   "trusty",
+			Owner:    "user-admin@local",
+			CharmURL: "cs:trusty/mysql-1",
+			Units: []description.Unit{{
+				Name:    "mysql/0",
+				Machine: "0/lxc/0",
+			}},
+		}, {
+			Name:     "wordpress",
+			Series:   "trusty",
+			Owner:    "user-admin@local",
+			CharmURL: "cs:trusty/wordpress-3",
+			Exposed:  true,
+			Leader:   "wordpress/2",
+			Settings: map[string]interface{}{
+				"blog-title": "My Blog",
+			},
+			ConfigHistory: []description.ConfigRevision{{
+				Revision: 1,
+				CharmURL: "cs:trusty/wordpress-3",
+				Time:     "2015-11-02T10:00:00Z",
+			}, {
+				Revision: 2,
+				CharmURL: "cs:trusty/wordpress-3",
+				Settings: map[string]interface{}{
+					"blog-title": "My Blog",
+				},
+				User: "admin@local",
+				Time: "2015-11-02T11:00:00Z",
+			}},
+			Units: []description.Unit{{
+				Name:    "wordpress/2",
+				Machine: "0",
+				HealthStatus: &description.Status{
+					Value:   "healthy",
+					Updated: "2015-11-02T10:00:00Z",
+				},
+			}},
+		}},
+		Relations: []description.Relation{{
+			Id:  3,
+			Key: "wordpress:db mysql:server",
+			Endpoints: []description.Endpoint{{
+				Service:   "mysql",
+				Name:      "server",
+				Role:      "provider",
+				Interface: "mysql",
+				Scope:     "global",
+				UnitSettings: map[string]map[string]interface{}{
+					"mysql/0": {"user": "wp"},
+				},
+			}, {
+				Service:   "wordpress",
+				Name:      "db",
+				Role:      "requirer",
+				Interface: "mysql",
+				Scope:     "global",
+			}},
+		}},
+		StoragePools: []description.StoragePool{{
+			Name:     "fast",
+			Provider: "loop",
+			Attrs:    map[string]interface{}{"speed": "fast"},
+		}},
+		Storage: []description.StorageInstance{{
+			Id:          "data/0",
+			Kind:        "block",
+			StorageName: "data",
+			Owner:       "unit-mysql-0",
+			CharmURL:    "cs:trusty/mysql-1",
+			Attachments: []string{"mysql/0"},
+		}},
+		Volumes: []description.Volume{{
+			Name:      "0/lxc/0/0",
+			StorageId: "data/0",
+			Binding:   "machine-0-lxc-0",
+			Info: &description.VolumeInfo{
+				VolumeId: "loop0",
+				Pool:     "fast",
+				Size:     1024,
+			},
+			Status: &description.Status{
+				Value:   "attached",
+				Updated: "2015-11-02T10:00:00Z",
+			},
+			Attachments: []description.VolumeAttachment{{
+				Machine: "0/lxc/0",
+				Info:    &description.VolumeAttachmentInfo{DeviceName: "loop0"},
+			}},
+		}},
+		Filesystems: []description.Filesystem{{
+			Id:        "0/lxc/0/0",
+			StorageId: "data/0",
+			Volume:    "0/lxc/0/0",
+			Binding:   "storage-data-0",
+			Params:    &description.StorageParams{Pool: "fast", Size: 1024},
+			Attachments: []description.FilesystemAttachment{{
+				Machine: "0/lxc/0",
+				Params:  &description.FilesystemAttachmentParams{},
+			}},
+		}},
+	}
+}
+
+func (*descriptionSuite) TestSerializeRoundTrip(c *gc.C) {
+	env := minimalEnvironment()
+	data, err := description.Serialize(env)
+	c.Assert(err, jc.ErrorIsNil)
+	read, err := description.Deserialize(data)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(read, jc.DeepEquals, env)
+
+	again, err := description.Serialize(read)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(again), gc.Equals, string(data))
+}
+
+func (*descriptionSuite) TestSerializeVersion(c *gc.C) {
+	env := minimalEnvironment()
+	env.Version = 0
+	_, err := description.Serialize(env)
+	c.Assert(err, gc.ErrorMatches, "description version 0 not valid")
+}
+
+func (*descriptionSuite) TestDeserializeVersion(c *gc.C) {
+	_, err := description.Deserialize([]byte("version: 99\nuuid: foo\n"))
+	c.Assert(err, gc.ErrorMatches, "description version 99 not supported")
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+}
+
+func (*descriptionSuite) TestDeserializeInvalidYAML(c *gc.C) {
+	_, err := description.Deserialize([]byte("version: [\n"))
+	c.Assert(err, gc.ErrorMatches, "cannot deserialize environment description: .*")
+}
+
+func (*descriptionSuite) TestDeserializeNestedMaps(c *gc.C) {
+	env := minimalEnvironment()
+	env.Services[1].Settings["theme"] = map[string]interface{}{
+		"colours": []interface{}{
+			map[string]interface{}{"name": "red"},
+		},
+	}
+	data, err := description.Serialize(env)
+	c.Assert(err, jc.ErrorIsNil)
+	read, err := description.Deserialize(data)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(read.Services[1].Settings["theme"], jc.DeepEquals, map[string]interface{}{
+		"colours": []interface{}{
+			map[string]interface{}{"name": "red"},
+		},
+	})
+}
+
+func (*descriptionSuite) TestValidate(c *gc.C) {
+	for i, test := range []struct {
+		about  string
+		change func(*description.Environment)
+		err    string
+	}{{
+		about:  "valid",
+		change: func(*description.Environment) {},
+	}, {
+		about:  "no UUID",
+		change: func(env *description.Environment) { env.UUID = "" },
+		err:    "environment without UUID not valid",
+	}, {
+		about:  "no owner",
+		change: func(env *description.Environment) { env.Owner = "" },
+		err:    "environment without owner not valid",
+	}, {
+		about: "duplicate machine",
+		change: func(env *description.Environment) {
+			env.Machines = append(env.Machines, description.Machine{Id: "0/lxc/0"})
+		},
+		err: `duplicate machine "0/lxc/0" not valid`,
+	}, {
+		about:  "unit on unknown machine",
+		change: func(env *description.Environment) { env.Services[0].Units[0].Machine = "1" },
+		err:    `unit "mysql/0" on undescribed machine "1" not valid`,
+	}, {
+		about:  "service with unknown charm",
+		change: func(env *description.Environment) { env.Charms = env.Charms[1:] },
+		err:    `service "mysql" with undescribed charm "cs:trusty/mysql-1" not valid`,
+	}, {
+		about: "relation with unknown service",
+		change: func(env *description.Environment) {
+			env.Services = env.Services[1:]
+			env.Services[0].Units = nil
+		},
+		err: `relation "wordpress:db mysql:server" with undescribed service "mysql" not valid`,
+	}, {
+		about: "relation with unknown unit",
+		change: func(env *description.Environment) {
+			env.Services[0].Units = nil
+		},
+		err: `relation "wordpress:db mysql:server" with undescribed unit "mysql/0" not valid`,
+	}, {
+		about:  "service with unknown previous charm",
+		change: func(env *description.Environment) { env.Services[0].PreviousCharmURL = "cs:trusty/mysql-0" },
+		err:    `service "mysql" with undescribed previous charm "cs:trusty/mysql-0" not valid`,
+	}, {
+		about:  "service with unknown leader",
+		change: func(env *description.Environment) { env.Services[1].Leader = "mysql/0" },
+		err:    `service "wordpress" with undescribed leader "mysql/0" not valid`,
+	}, {
+		about: "ports opened by unknown unit",
+		change: func(env *description.Environment) {
+			env.Machines[0].OpenedPorts[0].Ports[0].Unit = "wordpress/3"
+		},
+		err: `ports opened on machine "0" by undescribed unit "wordpress/3" not valid`,
+	}, {
+		about:  "storage attached to unknown unit",
+		change: func(env *description.Environment) { env.Storage[0].Attachments[0] = "mysql/1" },
+		err:    `storage "data/0" attached to undescribed unit "mysql/1" not valid`,
+	}, {
+		about:  "volume assigned to unknown storage",
+		change: func(env *description.Environment) { env.Volumes[0].StorageId = "data/1" },
+		err:    `volume "0/lxc/0/0" assigned to undescribed storage "data/1" not valid`,
+	}, {
+		about:  "volume attached to unknown machine",
+		change: func(env *description.Environment) { env.Volumes[0].Attachments[0].Machine = "1" },
+		err:    `volume "0/lxc/0/0" attached to undescribed machine "1" not valid`,
+	}, {
+		about:  "filesystem on unknown volume",
+		change: func(env *description.Environment) { env.Volumes = nil },
+		err:    `filesystem "0/lxc/0/0" on undescribed volume "0/lxc/0/0" not valid`,
+	}, {
+		about:  "filesystem attached to unknown machine",
+		change: func(env *description.Environment) { env.Filesystems[0].Attachments[0].Machine = "1" },
+		err:    `filesystem "0/lxc/0/0" attached to undescribed machine "1" not valid`,
+	}} {
+		c.Logf("test %d: %s", i, test.about)
+		env := minimalEnvironment()
+		test.change(env)
+		err := env.Validate()
+		if test.err == "" {
+			c.Check(err, jc.ErrorIsNil)
+		} else {
+			c.Check(err, gc.ErrorMatches, test.err)
+			c.Check(err, jc.Satisfies, errors.IsNotValid)
+		}
+	}
+}
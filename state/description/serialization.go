@@ -0,0 +1,271 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package description
+
+import (
+	"fmt"
+
+	"github.com/juju/errors"
+	"github.com/juju/utils/set"
+	"gopkg.in/yaml.v2"
+)
+
+// Serialize returns the YAML serialisation of the environment
+// description.
+func Serialize(env *Environment) ([]byte, error) {
+	if env.Version != Version {
+		return nil, errors.NotValidf("description version %d", env.Version)
+	}
+	data, err := yaml.Marshal(env)
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot serialize environment description")
+	}
+	return data, nil
+}
+
+// Deserialize reads an environment description from its YAML
+// serialisation, and checks that it is consistent.
+func Deserialize(data []byte) (*Environment, error) {
+	var env Environment
+	if err := yaml.Unmarshal(data, &env); err != nil {
+		return nil, errors.Annotate(err, "cannot deserialize environment description")
+	}
+	if env.Version != Version {
+		return nil, errors.NotSupportedf("description version %d", env.Version)
+	}
+	normaliseEnvironment(&env)
+	if err := env.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &env, nil
+}
+
+// Validate checks that the description is complete, and that the
+// entities it describes refer only to each other.
+func (env *Environment) Validate() error {
+	if env.UUID == "" {
+		return errors.NotValidf("environment without UUID")
+	}
+	if env.Name == "" {
+		return errors.NotValidf("environment without name")
+	}
+	if env.Owner == "" {
+		return errors.NotValidf("environment without owner")
+	}
+	machines := set.NewStrings()
+	if err := addMachineIds(machines, env.Machines); err != nil {
+		return errors.Trace(err)
+	}
+	charms := set.NewStrings()
+	for _, ch := range env.Charms {
+		charms.Add(ch.URL)
+	}
+	services := set.NewStrings()
+	units := set.NewStrings()
+	for _, svc := range env.Services {
+		if services.Contains(svc.Name) {
+			return errors.NotValidf("duplicate service %q", svc.Name)
+		}
+		services.Add(svc.Name)
+		if !charms.Contains(svc.CharmURL) {
+			return errors.NotValidf("service %q with undescribed charm %q", svc.Name, svc.CharmURL)
+		}
+		if svc.PreviousCharmURL != "" && !charms.Contains(svc.PreviousCharmURL) {
+			return errors.NotValidf("service %q with undescribed previous charm %q", svc.Name, svc.PreviousCharmURL)
+		}
+		leaderFound := svc.Leader == ""
+		for _, unit := range svc.Units {
+			if units.Contains(unit.Name) {
+				return errors.NotValidf("duplicate unit %q", unit.Name)
+			}
+			units.Add(unit.Name)
+			if unit.Machine != "" && !machines.Contains(unit.Machine) {
+				return errors.NotValidf("unit %q on undescribed machine %q", unit.Name, unit.Machine)
+			}
+			leaderFound = leaderFound || unit.Name == svc.Leader
+		}
+		if !leaderFound {
+			return errors.NotValidf("service %q with undescribed leader %q", svc.Name, svc.Leader)
+		}
+	}
+	if err := checkPortUnits(units, env.Machines); err != nil {
+		return errors.Trace(err)
+	}
+	for _, rel := range env.Relations {
+		for _, ep := range rel.Endpoints {
+			if !services.Contains(ep.Service) {
+				return errors.NotValidf("relation %q with undescribed service %q", rel.Key, ep.Service)
+			}
+			for unitName := range ep.UnitSettings {
+				if !units.Contains(unitName) {
+					return errors.NotValidf("relation %q with undescribed unit %q", rel.Key, unitName)
+				}
+			}
+		}
+	}
+	return errors.Trace(env.validateStorage(machines, units))
+}
+
+// validateStorage checks that the described storage is attached only
+// to the described machines and units.
+func (env *Environment) validateStorage(machines, units set.Strings) error {
+	storage := set.NewStrings()
+	for _, s := range env.Storage {
+		if storage.Contains(s.Id) {
+			return errors.NotValidf("duplicate storage %q", s.Id)
+		}
+		storage.Add(s.Id)
+		for _, unitName := range s.Attachments {
+			if !units.Contains(unitName) {
+				return errors.NotValidf("storage %q attached to undescribed unit %q", s.Id, unitName)
+			}
+		}
+	}
+	volumes := set.NewStrings()
+	for _, v := range env.Volumes {
+		if volumes.Contains(v.Name) {
+			return errors.NotValidf("duplicate volume %q", v.Name)
+		}
+		volumes.Add(v.Name)
+		if v.StorageId != "" && !storage.Contains(v.StorageId) {
+			return errors.NotValidf("volume %q assigned to undescribed storage %q", v.Name, v.StorageId)
+		}
+		for _, att := range v.Attachments {
+			if !machines.Contains(att.Machine) {
+				return errors.NotValidf("volume %q attached to undescribed machine %q", v.Name, att.Machine)
+			}
+		}
+	}
+	filesystems := set.NewStrings()
+	for _, f := range env.Filesystems {
+		if filesystems.Contains(f.Id) {
+			return errors.NotValidf("duplicate filesystem %q", f.Id)
+		}
+		filesystems.Add(f.Id)
+		if f.StorageId != "" && !storage.Contains(f.StorageId) {
+			return errors.NotValidf("filesystem %q assigned to undescribed storage %q", f.Id, f.StorageId)
+		}
+		if f.Volume != "" && !volumes.Contains(f.Volume) {
+			return errors.NotValidf("filesystem %q on undescribed volume %q", f.Id, f.Volume)
+		}
+		for _, att := range f.Attachments {
+			if !machines.Contains(att.Machine) {
+				return errors.NotValidf("filesystem %q attached to undescribed machine %q", f.Id, att.Machine)
+			}
+		}
+	}
+	return nil
+}
+
+func addMachineIds(ids set.Strings, machines []Machine) error {
+	for _, m := range machines {
+		if ids.Contains(m.Id) {
+			return errors.NotValidf("duplicate machine %q", m.Id)
+		}
+		ids.Add(m.Id)
+		if err := addMachineIds(ids, m.Containers); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// checkPortUnits returns an error if ports are opened on any of the
+// machines by a unit that is not described.
+func checkPortUnits(units set.Strings, machines []Machine) error {
+	for _, m := range machines {
+		for _, opened := range m.OpenedPorts {
+			for _, ports := range opened.Ports {
+				if !units.Contains(ports.Unit) {
+					return errors.NotValidf("ports opened on machine %q by undescribed unit %q", m.Id, ports.Unit)
+				}
+			}
+		}
+		if err := checkPortUnits(units, m.Containers); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// normaliseEnvironment replaces the maps with non-string keys that
+// YAML decodes nested maps into with maps keyed by string, so that
+// the description's values can be stored by juju.
+func normaliseEnvironment(env *Environment) {
+	normaliseMap(env.Config)
+	for i := range env.Machines {
+		normaliseMachine(&env.Machines[i])
+	}
+	for _, ch := range env.Charms {
+		normaliseMap(ch.Meta)
+		normaliseMap(ch.Config)
+		normaliseMap(ch.Actions)
+		normaliseMap(ch.Metrics)
+	}
+	for _, svc := range env.Services {
+		normaliseMap(svc.Settings)
+		for _, revision := range svc.ConfigHistory {
+			normaliseMap(revision.Settings)
+		}
+		normaliseStatus(svc.Status)
+		for _, unit := range svc.Units {
+			normaliseStatus(unit.WorkloadStatus)
+			normaliseStatus(unit.AgentStatus)
+			normaliseStatus(unit.HealthStatus)
+		}
+	}
+	for _, rel := range env.Relations {
+		for _, ep := range rel.Endpoints {
+			for _, settings := range ep.UnitSettings {
+				normaliseMap(settings)
+			}
+		}
+	}
+	for _, pool := range env.StoragePools {
+		normaliseMap(pool.Attrs)
+	}
+	for _, v := range env.Volumes {
+		normaliseStatus(v.Status)
+	}
+	for _, f := range env.Filesystems {
+		normaliseStatus(f.Status)
+	}
+}
+
+func normaliseMachine(m *Machine) {
+	normaliseStatus(m.Status)
+	for i := range m.Containers {
+		normaliseMachine(&m.Containers[i])
+	}
+}
+
+func normaliseStatus(status *Status) {
+	if status != nil {
+		normaliseMap(status.Data)
+	}
+}
+
+func normaliseMap(m map[string]interface{}) {
+	for key, value := range m {
+		m[key] = normaliseValue(value)
+	}
+}
+
+func normaliseValue(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(value))
+		for key, v := range value {
+			result[fmt.Sprint(key)] = normaliseValue(v)
+		}
+		return result
+	case map[string]interface{}:
+		normaliseMap(value)
+	case []interface{}:
+		for i, v := range value {
+			value[i] = normaliseValue(v)
+		}
+	}
+	return value
+}
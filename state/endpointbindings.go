@@ -0,0 +1,233 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"net"
+	"sort"
+
+	"github.com/juju/errors"
+	"gopkg.in/juju/charm.v6-unstable"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/txn"
+
+	"github.com/juju/juju/network"
+)
+
+// endpointBindingsDoc represents the bindings of a service's charm
+// endpoints to spaces. The document ID field is the globalKey of the
+// service.
+type endpointBindingsDoc struct {
+	DocID   string `bson:"_id"`
+	EnvUUID string `bson:"env-uuid"`
+
+	// Bindings maps the names of the charm's relation endpoints to
+	// the names of spaces.
+	Bindings map[string]string `bson:"bindings"`
+}
+
+func createEndpointBindingsOp(st *State, key string, bindings map[string]string) txn.Op {
+	return txn.Op{
+		C:      endpointBindingsC,
+		Id:     st.docID(key),
+		Assert: txn.DocMissing,
+		Insert: &endpointBindingsDoc{
+			EnvUUID:  st.EnvironUUID(),
+			Bindings: bindings,
+		},
+	}
+}
+
+func removeEndpointBindingsOp(st *State, key string) txn.Op {
+	return txn.Op{
+		C:      endpointBindingsC,
+		Id:     st.docID(key),
+		Remove: true,
+	}
+}
+
+func readEndpointBindings(st *State, key string) (map[string]string, error) {
+	endpointBindings, closer := st.getCollection(endpointBindingsC)
+	defer closer()
+
+	var doc endpointBindingsDoc
+	err := endpointBindings.FindId(key).One(&doc)
+	if err == mgo.ErrNotFound {
+		// Services deployed before endpoint bindings were introduced
+		// have no bindings document; their endpoints are unbound.
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return doc.Bindings, nil
+}
+
+// validateEndpointBindings checks that each binding names a relation
+// endpoint of the charm and an existing space.
+func validateEndpointBindings(st *State, bindings map[string]string, meta *charm.Meta) error {
+	for endpoint, spaceName := range bindings {
+		if !charmHasEndpoint(meta, endpoint) {
+			return errors.NotValidf("binding for unknown endpoint %q", endpoint)
+		}
+		if _, err := st.Space(spaceName); errors.IsNotFound(err) {
+			return errors.NotValidf("binding of endpoint %q to unknown space %q", endpoint, spaceName)
+		} else if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+func charmHasEndpoint(meta *charm.Meta, endpoint string) bool {
+	for _, relations := range []map[string]charm.Relation{meta.Provides, meta.Requires, meta.Peers} {
+		if _, ok := relations[endpoint]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// EndpointBindings returns the bindings of the service's charm
+// endpoints to spaces, keyed by endpoint name. Endpoints that are not
+// bound to a space are not included.
+func (s *Service) EndpointBindings() (map[string]string, error) {
+	bindings, err := readEndpointBindings(s.st, s.globalKey())
+	if err != nil {
+		return nil, errors.Annotatef(err, "cannot get endpoint bindings of service %q", s.doc.Name)
+	}
+	return bindings, nil
+}
+
+// NetworkInfo describes the address a unit uses for an endpoint binding.
+type NetworkInfo struct {
+	// SpaceName is the name of the space the endpoint is bound to, or
+	// empty if it is not bound.
+	SpaceName string
+
+	// Address is the unit's address in the space.
+	Address string
+
+	// InterfaceName is the name of the network interface with the
+	// address, if it is known.
+	InterfaceName string
+
+	// CIDR is the CIDR of the subnet containing the address, if it
+	// is known.
+	CIDR string
+}
+
+// NetworkInfo returns the address, network interface and subnet the
+// unit uses for the named endpoint binding. The address is taken from
+// the subnets of the space the endpoint is bound to; for an unbound
+// endpoint, the unit's private address is used.
+func (u *Unit) NetworkInfo(binding string) (NetworkInfo, error) {
+	service, err := u.Service()
+	if err != nil {
+		return NetworkInfo{}, errors.Trace(err)
+	}
+	ch, _, err := service.Charm()
+	if err != nil {
+		return NetworkInfo{}, errors.Trace(err)
+	}
+	if !charmHasEndpoint(ch.Meta(), binding) {
+		return NetworkInfo{}, errors.NotFoundf("endpoint %q of service %q", binding, service.Name())
+	}
+	bindings, err := service.EndpointBindings()
+	if err != nil {
+		return NetworkInfo{}, errors.Trace(err)
+	}
+	machine, err := u.machine()
+	if err != nil {
+		return NetworkInfo{}, errors.Trace(err)
+	}
+
+	var subnets []*Subnet
+	spaceName := bindings[binding]
+	if spaceName == "" {
+		subnets, err = u.st.AllSubnets()
+	} else {
+		var space *Space
+		if space, err = u.st.Space(spaceName); err == nil {
+			subnets, err = space.Subnets()
+		}
+	}
+	if err != nil {
+		return NetworkInfo{}, errors.Trace(err)
+	}
+
+	info := NetworkInfo{SpaceName: spaceName}
+	var addresses []network.Address
+	if spaceName == "" {
+		private, err := machine.PrivateAddress()
+		if err != nil {
+			return NetworkInfo{}, errors.Trace(err)
+		}
+		addresses = []network.Address{private}
+	} else {
+		addresses = machine.Addresses()
+	}
+	info.Address, info.CIDR = matchSubnetAddress(addresses, subnets)
+	if info.Address == "" {
+		if spaceName != "" {
+			return NetworkInfo{}, errors.NotFoundf("address of unit %q in space %q", u.Name(), spaceName)
+		}
+		info.Address = addresses[0].Value
+	}
+	if info.InterfaceName, err = machine.addressInterfaceName(info.Address); err != nil {
+		return NetworkInfo{}, errors.Trace(err)
+	}
+	return info, nil
+}
+
+// matchSubnetAddress returns the first of the addresses that is in one
+// of the subnets, along with the subnet's CIDR. Subnets are tried in
+// order of CIDR, so the result is stable.
+func matchSubnetAddress(addresses []network.Address, subnets []*Subnet) (string, string) {
+	cidrs := make([]string, len(subnets))
+	for i, subnet := range subnets {
+		cidrs[i] = subnet.CIDR()
+	}
+	sort.Strings(cidrs)
+	for _, addr := range addresses {
+		ip := net.ParseIP(addr.Value)
+		if ip == nil {
+			continue
+		}
+		for _, cidr := range cidrs {
+			_, ipNet, err := net.ParseCIDR(cidr)
+			if err != nil {
+				continue
+			}
+			if ipNet.Contains(ip) {
+				return addr.Value, cidr
+			}
+		}
+	}
+	return "", ""
+}
+
+// addressInterfaceName returns the name of the machine's network
+// interface with the given address allocated to it, or an empty string
+// if the interface is not known.
+func (m *Machine) addressInterfaceName(value string) (string, error) {
+	addr, err := ipAddress(m.st, value)
+	if errors.IsNotFound(err) {
+		return "", nil
+	} else if err != nil {
+		return "", errors.Trace(err)
+	}
+	if addr.MachineId() != m.Id() || addr.MACAddress() == "" {
+		return "", nil
+	}
+	ifaces, err := m.NetworkInterfaces()
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	for _, iface := range ifaces {
+		if iface.MACAddress() == addr.MACAddress() {
+			return iface.InterfaceName(), nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,121 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/network"
+	"github.com/juju/juju/state"
+)
+
+type EndpointBindingsSuite struct {
+	ConnSuite
+}
+
+var _ = gc.Suite(&EndpointBindingsSuite{})
+
+func (s *EndpointBindingsSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	for _, info := range []state.SubnetInfo{
+		{ProviderId: "subnet-0", CIDR: "10.0.0.0/24"},
+		{ProviderId: "subnet-1", CIDR: "192.168.1.0/24"},
+	} {
+		_, err := s.State.AddSubnet(info)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	_, err := s.State.AddSpace("internal", []string{"192.168.1.0/24"}, false)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *EndpointBindingsSuite) addService(c *gc.C, bindings map[string]string) (*state.Service, error) {
+	return s.State.AddService(state.AddServiceArgs{
+		Name:             "wordpress",
+		Owner:            s.Owner.String(),
+		Charm:            s.AddTestingCharm(c, "wordpress"),
+		EndpointBindings: bindings,
+	})
+}
+
+func (s *EndpointBindingsSuite) TestAddServiceWithBindings(c *gc.C) {
+	service, err := s.addService(c, map[string]string{"db": "internal"})
+	c.Assert(err, jc.ErrorIsNil)
+	bindings, err := service.EndpointBindings()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(bindings, jc.DeepEquals, map[string]string{"db": "internal"})
+}
+
+func (s *EndpointBindingsSuite) TestAddServiceInvalidBindings(c *gc.C) {
+	_, err := s.addService(c, map[string]string{"admin": "internal"})
+	c.Check(err, gc.ErrorMatches, `cannot add service "wordpress": binding for unknown endpoint "admin" not valid`)
+
+	_, err = s.addService(c, map[string]string{"db": "dmz"})
+	c.Check(err, gc.ErrorMatches, `cannot add service "wordpress": binding of endpoint "db" to unknown space "dmz" not valid`)
+}
+
+func (s *EndpointBindingsSuite) TestRemoveServiceRemovesBindings(c *gc.C) {
+	service, err := s.addService(c, map[string]string{"db": "internal"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(service.Destroy(), jc.ErrorIsNil)
+
+	// A new service with the same name starts without bindings.
+	service, err = s.addService(c, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	bindings, err := service.EndpointBindings()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(bindings, gc.HasLen, 0)
+}
+
+func (s *EndpointBindingsSuite) addUnit(c *gc.C, service *state.Service) *state.Unit {
+	unit, err := service.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.State.AssignUnit(unit, state.AssignCleanEmpty), jc.ErrorIsNil)
+	machineId, err := unit.AssignedMachineId()
+	c.Assert(err, jc.ErrorIsNil)
+	machine, err := s.State.Machine(machineId)
+	c.Assert(err, jc.ErrorIsNil)
+	err = machine.SetProviderAddresses(
+		network.NewScopedAddress("10.0.0.5", network.ScopeCloudLocal),
+		network.NewScopedAddress("192.168.1.5", network.ScopeCloudLocal),
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	return unit
+}
+
+func (s *EndpointBindingsSuite) TestNetworkInfoBound(c *gc.C) {
+	service, err := s.addService(c, map[string]string{"db": "internal"})
+	c.Assert(err, jc.ErrorIsNil)
+	unit := s.addUnit(c, service)
+
+	info, err := unit.NetworkInfo("db")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(info, jc.DeepEquals, state.NetworkInfo{
+		SpaceName: "internal",
+		Address:   "192.168.1.5",
+		CIDR:      "192.168.1.0/24",
+	})
+}
+
+func (s *EndpointBindingsSuite) TestNetworkInfoUnbound(c *gc.C) {
+	service, err := s.addService(c, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	unit := s.addUnit(c, service)
+
+	info, err := unit.NetworkInfo("url")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(info, jc.DeepEquals, state.NetworkInfo{
+		Address: "10.0.0.5",
+		CIDR:    "10.0.0.0/24",
+	})
+}
+
+func (s *EndpointBindingsSuite) TestNetworkInfoUnknownEndpoint(c *gc.C) {
+	service, err := s.addService(c, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	unit := s.addUnit(c, service)
+
+	_, err = unit.NetworkInfo("admin")
+	c.Check(err, gc.ErrorMatches, `endpoint "admin" of service "wordpress" not found`)
+}
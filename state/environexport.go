@@ -0,0 +1,883 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/juju/juju/network"
+	"github.com/juju/juju/state/description"
+	"github.com/juju/juju/storage/poolmanager"
+)
+
+// Export returns a description of the environment, from which an
+// equivalent environment can be built with Import.
+//
+// Export describes the environment's entities in a form that does not
+// depend on how they are stored, and leaves out the environment's
+// charm archives, logs and status history. Environments with data that
+// cannot be described, such as resources, cross-environment relations
+// and charm rollouts in progress, cannot be exported.
+func (st *State) Export() (*description.Environment, error) {
+	env, err := st.Environment()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if err := st.checkExportable(); err != nil {
+		return nil, errors.Annotate(err, "cannot export environment")
+	}
+	e := &exporter{
+		st: st,
+		desc: &description.Environment{
+			Version: description.Version,
+			UUID:    env.UUID(),
+			Name:    env.Name(),
+			Owner:   env.Owner().Canonical(),
+		},
+	}
+	if err := e.environment(env); err != nil {
+		return nil, errors.Annotate(err, "cannot export environment")
+	}
+	if err := e.users(env); err != nil {
+		return nil, errors.Annotate(err, "cannot export users")
+	}
+	if err := e.machines(); err != nil {
+		return nil, errors.Annotate(err, "cannot export machines")
+	}
+	if err := e.charms(); err != nil {
+		return nil, errors.Annotate(err, "cannot export charms")
+	}
+	if err := e.services(); err != nil {
+		return nil, errors.Annotate(err, "cannot export services")
+	}
+	if err := e.relations(); err != nil {
+		return nil, errors.Annotate(err, "cannot export relations")
+	}
+	if err := e.storage(); err != nil {
+		return nil, errors.Annotate(err, "cannot export storage")
+	}
+	if err := e.blocks(); err != nil {
+		return nil, errors.Annotate(err, "cannot export blocks")
+	}
+	return e.desc, nil
+}
+
+// notAlive matches the documents of entities that are being removed.
+var notAlive = bson.D{{"life", bson.D{{"$ne", Alive}}}}
+
+// undescribedData holds queries for the data that Export does not
+// describe, with the collections they are run on.
+var undescribedData = []struct {
+	collection string
+	query      bson.D
+	what       string
+}{
+	{storageInstancesC, notAlive, "storage being removed"},
+	{storageAttachmentsC, notAlive, "storage being removed"},
+	{volumesC, notAlive, "storage being removed"},
+	{volumeAttachmentsC, notAlive, "storage being removed"},
+	{filesystemsC, notAlive, "storage being removed"},
+	{filesystemAttachmentsC, notAlive, "storage being removed"},
+	{"resources", nil, "resources"},
+	{remoteServicesC, nil, "remote services"},
+	{offersC, nil, "offered services"},
+	{servicesC, bson.D{{"rollout", bson.D{{"$exists", true}}}}, "charm rollouts in progress"},
+}
+
+// checkExportable returns an error satisfying errors.IsNotSupported if
+// the environment has any data that Export does not describe, so that
+// the data is not silently left out.
+func (st *State) checkExportable() error {
+	for _, data := range undescribedData {
+		coll, closer := st.getCollection(data.collection)
+		n, err := coll.Find(data.query).Count()
+		closer()
+		if err != nil {
+			return errors.Trace(err)
+		} else if n > 0 {
+			return errors.NotSupportedf("exporting environment with %s", data.what)
+		}
+	}
+	return nil
+}
+
+// exporter holds the state of an Export.
+type exporter struct {
+	st      *State
+	desc    *description.Environment
+	units   map[string][]*Unit
+	leaders map[string]string
+}
+
+func (e *exporter) environment(env *Environment) error {
+	cfg, err := env.Config()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	e.desc.Config = cfg.AllAttrs()
+	cons, err := e.st.EnvironConstraints()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	e.desc.Constraints = cons.String()
+	if e.desc.Annotations, err = e.annotations(env); err != nil {
+		return errors.Trace(err)
+	}
+	e.desc.Sequences, err = e.st.sequences()
+	return errors.Trace(err)
+}
+
+func (e *exporter) users(env *Environment) error {
+	users, err := env.Users()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for _, user := range users {
+		e.desc.Users = append(e.desc.Users, description.User{
+			Name:        user.UserName(),
+			DisplayName: user.DisplayName(),
+			CreatedBy:   user.CreatedBy(),
+			Access:      string(user.Access()),
+		})
+	}
+	sort.Sort(usersByName(e.desc.Users))
+	return nil
+}
+
+func (e *exporter) machines() error {
+	machines, err := e.st.AllMachines()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	children := make(map[string][]*Machine)
+	for _, m := range machines {
+		parentId, _ := m.ParentId()
+		children[parentId] = append(children[parentId], m)
+	}
+	e.desc.Machines, err = e.machineTree(children, "")
+	return errors.Trace(err)
+}
+
+// machineTree describes the machines hosted by the machine with the
+// given id, or the top level machines if the id is empty.
+func (e *exporter) machineTree(children map[string][]*Machine, parentId string) ([]description.Machine, error) {
+	machines := children[parentId]
+	sort.Sort(machinesById(machines))
+	var result []description.Machine
+	for _, m := range machines {
+		desc, err := e.machine(m)
+		if err != nil {
+			return nil, errors.Annotatef(err, "machine %s", m.Id())
+		}
+		if desc.Containers, err = e.machineTree(children, m.Id()); err != nil {
+			return nil, errors.Trace(err)
+		}
+		result = append(result, desc)
+	}
+	return result, nil
+}
+
+func (e *exporter) machine(m *Machine) (description.Machine, error) {
+	desc := description.Machine{
+		Id:                m.Id(),
+		Series:            m.Series(),
+		Placement:         m.Placement(),
+		PasswordHash:      m.doc.PasswordHash,
+		ProviderAddresses: exportAddresses(m.ProviderAddresses()),
+		MachineAddresses:  exportAddresses(m.MachineAddresses()),
+	}
+	for _, job := range m.Jobs() {
+		desc.Jobs = append(desc.Jobs, job.String())
+	}
+	cons, err := m.Constraints()
+	if err != nil {
+		return desc, errors.Trace(err)
+	}
+	desc.Constraints = cons.String()
+	instData, err := getInstanceData(e.st, m.Id())
+	if err == nil {
+		desc.Instance = &description.Instance{
+			Id:       string(instData.InstanceId),
+			Nonce:    m.doc.Nonce,
+			Hardware: hardwareCharacteristics(instData).String(),
+		}
+	} else if !errors.IsNotFound(err) {
+		return desc, errors.Trace(err)
+	}
+	if desc.OpenedPorts, err = e.openedPorts(m); err != nil {
+		return desc, errors.Trace(err)
+	}
+	if desc.Status, err = e.status(m.globalKey()); err != nil {
+		return desc, errors.Trace(err)
+	}
+	if desc.Annotations, err = e.annotations(m); err != nil {
+		return desc, errors.Trace(err)
+	}
+	desc.Labels, err = e.labels(m)
+	return desc, errors.Trace(err)
+}
+
+func (e *exporter) openedPorts(m *Machine) ([]description.OpenedPorts, error) {
+	allPorts, err := m.AllPorts()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var result []description.OpenedPorts
+	for _, ports := range allPorts {
+		if len(ports.doc.Ports) == 0 {
+			continue
+		}
+		desc := description.OpenedPorts{
+			Network: ports.NetworkName(),
+		}
+		for _, portRange := range ports.doc.Ports {
+			desc.Ports = append(desc.Ports, description.PortRange{
+				Unit:     portRange.UnitName,
+				FromPort: portRange.FromPort,
+				ToPort:   portRange.ToPort,
+				Protocol: portRange.Protocol,
+			})
+		}
+		sort.Sort(portRanges(desc.Ports))
+		result = append(result, desc)
+	}
+	sort.Sort(openedPortsByNetwork(result))
+	return result, nil
+}
+
+func (e *exporter) charms() error {
+	charms, err := e.st.AllCharms()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for _, ch := range charms {
+		if ch.IsPlaceholder() || !ch.IsUploaded() {
+			continue
+		}
+		desc := description.Charm{
+			URL:          ch.URL().String(),
+			StoragePath:  ch.StoragePath(),
+			BundleSha256: ch.BundleSha256(),
+		}
+		if desc.Meta, err = toMap(ch.Meta()); err == nil {
+			if desc.Config, err = toMap(ch.Config()); err == nil {
+				if desc.Actions, err = toMap(ch.Actions()); err == nil {
+					desc.Metrics, err = toMap(ch.Metrics())
+				}
+			}
+		}
+		if err != nil {
+			return errors.Annotatef(err, "charm %q", desc.URL)
+		}
+		e.desc.Charms = append(e.desc.Charms, desc)
+	}
+	sort.Sort(charmsByURL(e.desc.Charms))
+	return nil
+}
+
+func (e *exporter) services() error {
+	services, err := e.st.AllServices()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	sort.Sort(servicesByName(services))
+	if e.leaders, err = e.st.serviceLeaders(); err != nil {
+		return errors.Trace(err)
+	}
+	e.units = make(map[string][]*Unit)
+	for _, svc := range services {
+		desc, err := e.service(svc)
+		if err != nil {
+			return errors.Annotatef(err, "service %q", svc.Name())
+		}
+		e.desc.Services = append(e.desc.Services, desc)
+	}
+	return nil
+}
+
+func (e *exporter) service(svc *Service) (description.Service, error) {
+	curl, _ := svc.CharmURL()
+	desc := description.Service{
+		Name:     svc.Name(),
+		Series:   svc.doc.Series,
+		Owner:    svc.GetOwnerTag(),
+		CharmURL: curl.String(),
+		Exposed:  svc.IsExposed(),
+		MinUnits: svc.MinUnits(),
+	}
+	if previous := svc.PreviousCharmURL(); previous != nil {
+		desc.PreviousCharmURL = previous.String()
+	}
+	cons, err := svc.Constraints()
+	if err != nil {
+		return desc, errors.Trace(err)
+	}
+	desc.Constraints = cons.String()
+	settings, err := svc.ConfigSettings()
+	if err != nil {
+		return desc, errors.Trace(err)
+	}
+	desc.Settings = nilIfEmpty(settings)
+	storage, err := svc.StorageConstraints()
+	if err != nil {
+		return desc, errors.Trace(err)
+	}
+	for name, cons := range storage {
+		if desc.Storage == nil {
+			desc.Storage = make(map[string]description.StorageConstraints)
+		}
+		desc.Storage[name] = description.StorageConstraints{
+			Pool:  cons.Pool,
+			Size:  cons.Size,
+			Count: cons.Count,
+		}
+	}
+	bindings, err := svc.EndpointBindings()
+	if err != nil {
+		return desc, errors.Trace(err)
+	}
+	if len(bindings) > 0 {
+		desc.EndpointBindings = bindings
+	}
+	history, err := svc.ConfigHistory()
+	if err != nil {
+		return desc, errors.Trace(err)
+	}
+	for _, revision := range history {
+		desc.ConfigHistory = append(desc.ConfigHistory, description.ConfigRevision{
+			Revision:     revision.Revision,
+			CharmURL:     revision.CharmURL,
+			Settings:     nilIfEmpty(revision.Settings),
+			User:         revision.User,
+			Time:         revision.Time.Format(time.RFC3339Nano),
+			RolledBackTo: revision.RolledBackTo,
+		})
+	}
+	if desc.Status, err = e.status(svc.globalKey()); err != nil {
+		return desc, errors.Trace(err)
+	}
+	if desc.Annotations, err = e.annotations(svc); err != nil {
+		return desc, errors.Trace(err)
+	}
+	if desc.Labels, err = e.labels(svc); err != nil {
+		return desc, errors.Trace(err)
+	}
+
+	units, err := svc.AllUnits()
+	if err != nil {
+		return desc, errors.Trace(err)
+	}
+	sort.Sort(unitsByNumber(units))
+	e.units[svc.Name()] = units
+	for _, u := range units {
+		unit, err := e.unit(u)
+		if err != nil {
+			return desc, errors.Annotatef(err, "unit %q", u.Name())
+		}
+		desc.Units = append(desc.Units, unit)
+		if u.Name() == e.leaders[svc.Name()] {
+			// The leader is only described with its unit, as a
+			// lease may outlive the unit that holds it.
+			desc.Leader = u.Name()
+		}
+	}
+	return desc, nil
+}
+
+func (e *exporter) unit(u *Unit) (description.Unit, error) {
+	desc := description.Unit{
+		Name:         u.Name(),
+		PasswordHash: u.doc.PasswordHash,
+	}
+	if principal, ok := u.PrincipalName(); ok {
+		desc.Principal = principal
+	} else {
+		machineId, err := u.AssignedMachineId()
+		if err == nil {
+			desc.Machine = machineId
+		} else if !errors.IsNotAssigned(err) {
+			return desc, errors.Trace(err)
+		}
+	}
+	if curl, ok := u.CharmURL(); ok {
+		desc.CharmURL = curl.String()
+	}
+	if !u.IsPrincipal() && u.doc.StorageAttachmentCount > 0 {
+		// Subordinate units get their storage when they are added
+		// on entering scope, rather than as described.
+		return desc, errors.NotSupportedf("subordinate unit with storage")
+	}
+	var err error
+	if desc.WorkloadStatus, err = e.status(u.globalKey()); err != nil {
+		return desc, errors.Trace(err)
+	}
+	if desc.AgentStatus, err = e.status(u.globalAgentKey()); err != nil {
+		return desc, errors.Trace(err)
+	}
+	if desc.HealthStatus, err = e.status(unitHealthGlobalKey(u.Name())); err != nil {
+		return desc, errors.Trace(err)
+	}
+	if desc.Annotations, err = e.annotations(u); err != nil {
+		return desc, errors.Trace(err)
+	}
+	desc.Labels, err = e.labels(u)
+	return desc, errors.Trace(err)
+}
+
+func (e *exporter) relations() error {
+	relations, err := e.st.AllRelations()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	sort.Sort(relationsById(relations))
+	for _, rel := range relations {
+		desc := description.Relation{
+			Id:  rel.Id(),
+			Key: rel.String(),
+		}
+		for _, ep := range rel.Endpoints() {
+			endpoint, err := e.endpoint(rel, ep)
+			if err != nil {
+				return errors.Annotatef(err, "relation %q", rel)
+			}
+			desc.Endpoints = append(desc.Endpoints, endpoint)
+		}
+		e.desc.Relations = append(e.desc.Relations, desc)
+	}
+	return nil
+}
+
+func (e *exporter) endpoint(rel *Relation, ep Endpoint) (description.Endpoint, error) {
+	desc := description.Endpoint{
+		Service:   ep.ServiceName,
+		Name:      ep.Name,
+		Role:      string(ep.Role),
+		Interface: ep.Interface,
+		Optional:  ep.Optional,
+		Limit:     ep.Limit,
+		Scope:     string(ep.Scope),
+	}
+	units, ok := e.units[ep.ServiceName]
+	if !ok {
+		return desc, errors.NotSupportedf("relation with remote service %q", ep.ServiceName)
+	}
+	for _, u := range units {
+		ru, err := rel.Unit(u)
+		if err != nil {
+			return desc, errors.Trace(err)
+		}
+		if inScope, err := ru.InScope(); err != nil {
+			return desc, errors.Trace(err)
+		} else if !inScope {
+			continue
+		}
+		settings, err := ru.Settings()
+		if err != nil {
+			return desc, errors.Trace(err)
+		}
+		if desc.UnitSettings == nil {
+			desc.UnitSettings = make(map[string]map[string]interface{})
+		}
+		desc.UnitSettings[u.Name()] = settings.Map()
+	}
+	return desc, nil
+}
+
+func (e *exporter) storage() error {
+	if err := e.storagePools(); err != nil {
+		return errors.Trace(err)
+	}
+	if err := e.storageInstances(); err != nil {
+		return errors.Trace(err)
+	}
+	if err := e.volumes(); err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(e.filesystems())
+}
+
+func (e *exporter) storagePools() error {
+	pools, err := poolmanager.New(NewStateSettings(e.st)).List()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for _, pool := range pools {
+		e.desc.StoragePools = append(e.desc.StoragePools, description.StoragePool{
+			Name:     pool.Name(),
+			Provider: string(pool.Provider()),
+			Attrs:    nilIfEmpty(pool.Attrs()),
+		})
+	}
+	sort.Sort(storagePoolsByName(e.desc.StoragePools))
+	return nil
+}
+
+func (e *exporter) storageInstances() error {
+	coll, closer := e.st.getCollection(storageAttachmentsC)
+	var attachmentDocs []storageAttachmentDoc
+	err := coll.Find(nil).Sort("unitid").All(&attachmentDocs)
+	closer()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	attachments := make(map[string][]string)
+	for _, doc := range attachmentDocs {
+		attachments[doc.StorageInstance] = append(attachments[doc.StorageInstance], doc.Unit)
+	}
+
+	coll, closer = e.st.getCollection(storageInstancesC)
+	defer closer()
+	var docs []storageInstanceDoc
+	if err := coll.Find(nil).Sort("id").All(&docs); err != nil {
+		return errors.Trace(err)
+	}
+	for _, doc := range docs {
+		desc := description.StorageInstance{
+			Id:          doc.Id,
+			Kind:        storageKindNames[doc.Kind],
+			StorageName: doc.StorageName,
+			Owner:       doc.Owner,
+			Attachments: attachments[doc.Id],
+		}
+		if doc.CharmURL != nil {
+			desc.CharmURL = doc.CharmURL.String()
+		}
+		e.desc.Storage = append(e.desc.Storage, desc)
+	}
+	return nil
+}
+
+func (e *exporter) volumes() error {
+	coll, closer := e.st.getCollection(volumeAttachmentsC)
+	var attachmentDocs []volumeAttachmentDoc
+	err := coll.Find(nil).Sort("machineid").All(&attachmentDocs)
+	closer()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	attachments := make(map[string][]description.VolumeAttachment)
+	for _, doc := range attachmentDocs {
+		desc := description.VolumeAttachment{
+			Machine: doc.Machine,
+		}
+		if doc.Params != nil {
+			desc.Params = &description.VolumeAttachmentParams{
+				ReadOnly: doc.Params.ReadOnly,
+			}
+		}
+		if doc.Info != nil {
+			desc.Info = &description.VolumeAttachmentInfo{
+				DeviceName: doc.Info.DeviceName,
+				DeviceLink: doc.Info.DeviceLink,
+				BusAddress: doc.Info.BusAddress,
+				ReadOnly:   doc.Info.ReadOnly,
+			}
+		}
+		attachments[doc.Volume] = append(attachments[doc.Volume], desc)
+	}
+
+	coll, closer = e.st.getCollection(volumesC)
+	defer closer()
+	var docs []volumeDoc
+	if err := coll.Find(nil).Sort("name").All(&docs); err != nil {
+		return errors.Trace(err)
+	}
+	for _, doc := range docs {
+		desc := description.Volume{
+			Name:        doc.Name,
+			StorageId:   doc.StorageId,
+			Binding:     doc.Binding,
+			Attachments: attachments[doc.Name],
+		}
+		if doc.Params != nil {
+			desc.Params = &description.StorageParams{
+				Pool: doc.Params.Pool,
+				Size: doc.Params.Size,
+			}
+		}
+		if doc.Info != nil {
+			desc.Info = &description.VolumeInfo{
+				VolumeId:   doc.Info.VolumeId,
+				HardwareId: doc.Info.HardwareId,
+				Pool:       doc.Info.Pool,
+				Size:       doc.Info.Size,
+				Persistent: doc.Info.Persistent,
+			}
+		}
+		if desc.Status, err = e.status(volumeGlobalKey(doc.Name)); err != nil {
+			return errors.Annotatef(err, "volume %q", doc.Name)
+		}
+		e.desc.Volumes = append(e.desc.Volumes, desc)
+	}
+	return nil
+}
+
+func (e *exporter) filesystems() error {
+	coll, closer := e.st.getCollection(filesystemAttachmentsC)
+	var attachmentDocs []filesystemAttachmentDoc
+	err := coll.Find(nil).Sort("machineid").All(&attachmentDocs)
+	closer()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	attachments := make(map[string][]description.FilesystemAttachment)
+	for _, doc := range attachmentDocs {
+		desc := description.FilesystemAttachment{
+			Machine: doc.Machine,
+		}
+		if doc.Params != nil {
+			desc.Params = &description.FilesystemAttachmentParams{
+				Location: doc.Params.Location,
+				ReadOnly: doc.Params.ReadOnly,
+			}
+		}
+		if doc.Info != nil {
+			desc.Info = &description.FilesystemAttachmentInfo{
+				MountPoint: doc.Info.MountPoint,
+				ReadOnly:   doc.Info.ReadOnly,
+			}
+		}
+		attachments[doc.Filesystem] = append(attachments[doc.Filesystem], desc)
+	}
+
+	coll, closer = e.st.getCollection(filesystemsC)
+	defer closer()
+	var docs []filesystemDoc
+	if err := coll.Find(nil).Sort("filesystemid").All(&docs); err != nil {
+		return errors.Trace(err)
+	}
+	for _, doc := range docs {
+		desc := description.Filesystem{
+			Id:          doc.FilesystemId,
+			StorageId:   doc.StorageId,
+			Volume:      doc.VolumeId,
+			Binding:     doc.Binding,
+			Attachments: attachments[doc.FilesystemId],
+		}
+		if doc.Params != nil {
+			desc.Params = &description.StorageParams{
+				Pool: doc.Params.Pool,
+				Size: doc.Params.Size,
+			}
+		}
+		if doc.Info != nil {
+			desc.Info = &description.FilesystemInfo{
+				FilesystemId: doc.Info.FilesystemId,
+				Pool:         doc.Info.Pool,
+				Size:         doc.Info.Size,
+			}
+		}
+		if desc.Status, err = e.status(filesystemGlobalKey(doc.FilesystemId)); err != nil {
+			return errors.Annotatef(err, "filesystem %q", doc.FilesystemId)
+		}
+		e.desc.Filesystems = append(e.desc.Filesystems, desc)
+	}
+	return nil
+}
+
+func (e *exporter) blocks() error {
+	blocks, err := e.st.AllBlocks()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for _, block := range blocks {
+		e.desc.Blocks = append(e.desc.Blocks, description.Block{
+			Type:    block.Type().String(),
+			Message: block.Message(),
+		})
+	}
+	sort.Sort(blocksByType(e.desc.Blocks))
+	return nil
+}
+
+// status describes the status with the given global key, or returns
+// nil if the status has never been set.
+func (e *exporter) status(globalKey string) (*description.Status, error) {
+	statuses, closer := e.st.getCollection(statusesC)
+	defer closer()
+	var doc statusDoc
+	err := statuses.FindId(globalKey).One(&doc)
+	if err == mgo.ErrNotFound || doc.NeverSet {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+	status := &description.Status{
+		Value:   string(doc.Status),
+		Message: doc.StatusInfo,
+		Data:    nilIfEmpty(unescapeKeys(doc.StatusData)),
+	}
+	if doc.Updated != 0 {
+		status.Updated = time.Unix(0, doc.Updated).UTC().Format(time.RFC3339Nano)
+	}
+	return status, nil
+}
+
+func (e *exporter) annotations(entity GlobalEntity) (map[string]string, error) {
+	annotations, err := e.st.Annotations(entity)
+	if err != nil || len(annotations) == 0 {
+		return nil, errors.Trace(err)
+	}
+	return annotations, nil
+}
+
+func (e *exporter) labels(entity GlobalEntity) (map[string]string, error) {
+	labels, err := e.st.Labels(entity)
+	if err != nil || len(labels) == 0 {
+		return nil, errors.Trace(err)
+	}
+	return labels, nil
+}
+
+// storageKindNames holds the names with which the kinds of storage
+// instances are described.
+var storageKindNames = map[StorageKind]string{
+	StorageKindBlock:      "block",
+	StorageKindFilesystem: "filesystem",
+}
+
+func exportAddresses(addrs []network.Address) []description.Address {
+	var result []description.Address
+	for _, addr := range addrs {
+		result = append(result, description.Address{
+			Value: addr.Value,
+			Type:  string(addr.Type),
+			Scope: string(addr.Scope),
+		})
+	}
+	return result
+}
+
+// toMap returns the value as it is stored by juju, as a map. It is
+// the inverse of fromMap.
+func toMap(value interface{}) (map[string]interface{}, error) {
+	if v := reflect.ValueOf(value); !v.IsValid() || v.Kind() == reflect.Ptr && v.IsNil() {
+		return nil, nil
+	}
+	data, err := bson.Marshal(value)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var result map[string]interface{}
+	if err := bson.Unmarshal(data, &result); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return plainMap(result), nil
+}
+
+// plainMap replaces the bson.M values in m with plain maps, so that
+// the result is the same as that read from a description.
+func plainMap(m map[string]interface{}) map[string]interface{} {
+	if len(m) == 0 {
+		return nil
+	}
+	for key, value := range m {
+		m[key] = plainValue(value)
+	}
+	return m
+}
+
+func plainValue(value interface{}) interface{} {
+	switch value := value.(type) {
+	case bson.M:
+		return plainMap(map[string]interface{}(value))
+	case []interface{}:
+		for i, v := range value {
+			value[i] = plainValue(v)
+		}
+	}
+	return value
+}
+
+func nilIfEmpty(m map[string]interface{}) map[string]interface{} {
+	if len(m) == 0 {
+		return nil
+	}
+	return m
+}
+
+// machineIdLess reports whether the machine id a sorts before b,
+// comparing the numbers in the ids numerically.
+func machineIdLess(a, b string) bool {
+	aParts, bParts := strings.Split(a, "/"), strings.Split(b, "/")
+	for i := 0; i < len(aParts) && i < len(bParts); i++ {
+		if aParts[i] == bParts[i] {
+			continue
+		}
+		aNum, aErr := strconv.Atoi(aParts[i])
+		bNum, bErr := strconv.Atoi(bParts[i])
+		if aErr != nil || bErr != nil {
+			return aParts[i] < bParts[i]
+		}
+		return aNum < bNum
+	}
+	return len(aParts) < len(bParts)
+}
+
+type usersByName []description.User
+
+func (u usersByName) Len() int           { return len(u) }
+func (u usersByName) Swap(i, j int)      { u[i], u[j] = u[j], u[i] }
+func (u usersByName) Less(i, j int) bool { return u[i].Name < u[j].Name }
+
+type machinesById []*Machine
+
+func (m machinesById) Len() int           { return len(m) }
+func (m machinesById) Swap(i, j int)      { m[i], m[j] = m[j], m[i] }
+func (m machinesById) Less(i, j int) bool { return machineIdLess(m[i].Id(), m[j].Id()) }
+
+type openedPortsByNetwork []description.OpenedPorts
+
+func (p openedPortsByNetwork) Len() int           { return len(p) }
+func (p openedPortsByNetwork) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
+func (p openedPortsByNetwork) Less(i, j int) bool { return p[i].Network < p[j].Network }
+
+type portRanges []description.PortRange
+
+func (p portRanges) Len() int      { return len(p) }
+func (p portRanges) Swap(i, j int) { p[i], p[j] = p[j], p[i] }
+func (p portRanges) Less(i, j int) bool {
+	if p[i].Protocol != p[j].Protocol {
+		return p[i].Protocol < p[j].Protocol
+	}
+	return p[i].FromPort < p[j].FromPort
+}
+
+type charmsByURL []description.Charm
+
+func (c charmsByURL) Len() int           { return len(c) }
+func (c charmsByURL) Swap(i, j int)      { c[i], c[j] = c[j], c[i] }
+func (c charmsByURL) Less(i, j int) bool { return c[i].URL < c[j].URL }
+
+type servicesByName []*Service
+
+func (s servicesByName) Len() int           { return len(s) }
+func (s servicesByName) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s servicesByName) Less(i, j int) bool { return s[i].Name() < s[j].Name() }
+
+type relationsById []*Relation
+
+func (r relationsById) Len() int           { return len(r) }
+func (r relationsById) Swap(i, j int)      { r[i], r[j] = r[j], r[i] }
+func (r relationsById) Less(i, j int) bool { return r[i].Id() < r[j].Id() }
+
+type storagePoolsByName []description.StoragePool
+
+func (p storagePoolsByName) Len() int           { return len(p) }
+func (p storagePoolsByName) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
+func (p storagePoolsByName) Less(i, j int) bool { return p[i].Name < p[j].Name }
+
+type blocksByType []description.Block
+
+func (b blocksByType) Len() int           { return len(b) }
+func (b blocksByType) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+func (b blocksByType) Less(i, j int) bool { return b[i].Type < b[j].Type }
@@ -0,0 +1,448 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/utils"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/charm.v6-unstable"
+
+	"github.com/juju/juju/constraints"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/network"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/description"
+	"github.com/juju/juju/storage/poolmanager"
+	"github.com/juju/juju/storage/provider"
+	"github.com/juju/juju/testing/factory"
+)
+
+type environExportSuite struct {
+	ConnSuite
+	envState *state.State
+	factory  *factory.Factory
+}
+
+var _ = gc.Suite(&environExportSuite{})
+
+func (s *environExportSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	s.envState = s.Factory.MakeEnvironment(c, nil)
+	s.AddCleanup(func(*gc.C) { s.envState.Close() })
+	s.factory = factory.NewFactory(s.envState)
+}
+
+// populate fills the hosted environment with a little of everything
+// an environment description holds, leaving gaps in the machine and
+// unit ids so that their preservation can be checked.
+func (s *environExportSuite) populate(c *gc.C) {
+	st := s.envState
+	err := st.SetEnvironConstraints(constraints.MustParse("mem=4G"))
+	c.Assert(err, jc.ErrorIsNil)
+	env, err := st.Environment()
+	c.Assert(err, jc.ErrorIsNil)
+	err = st.SetAnnotations(env, map[string]string{"purpose": "staging"})
+	c.Assert(err, jc.ErrorIsNil)
+	s.factory.MakeEnvUser(c, &factory.EnvUserParams{DisplayName: "Bob"})
+
+	gone, err := st.AddMachine("trusty", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(gone.EnsureDead(), jc.ErrorIsNil)
+	c.Assert(gone.Remove(), jc.ErrorIsNil)
+
+	machine := s.factory.MakeMachine(c, &factory.MachineParams{
+		Series:     "quantal",
+		InstanceId: "i-exported",
+		Nonce:      "exported-nonce",
+		Addresses:  network.NewAddresses("10.0.0.2"),
+	})
+	err = machine.SetStatus(state.StatusStarted, "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	err = st.SetAnnotations(machine, map[string]string{"rack": "a1"})
+	c.Assert(err, jc.ErrorIsNil)
+	container, err := st.AddMachineInsideMachine(state.MachineTemplate{
+		Series: "quantal",
+		Jobs:   []state.MachineJob{state.JobHostUnits},
+	}, machine.Id(), instance.LXC)
+	c.Assert(err, jc.ErrorIsNil)
+
+	wordpress := s.factory.MakeService(c, &factory.ServiceParams{
+		Name:  "wordpress",
+		Charm: s.factory.MakeCharm(c, &factory.CharmParams{Name: "wordpress"}),
+	})
+	err = wordpress.UpdateConfigSettings(charm.Settings{"blog-title": "Exported"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(wordpress.SetExposed(), jc.ErrorIsNil)
+	c.Assert(wordpress.SetMinUnits(1), jc.ErrorIsNil)
+	err = st.SetAnnotations(wordpress, map[string]string{"owner": "blog team"})
+	c.Assert(err, jc.ErrorIsNil)
+	mysql := s.factory.MakeService(c, &factory.ServiceParams{
+		Name:  "mysql",
+		Charm: s.factory.MakeCharm(c, &factory.CharmParams{Name: "mysql"}),
+		Status: &state.StatusInfo{
+			Status:  state.StatusActive,
+			Message: "serving",
+		},
+	})
+	s.factory.MakeService(c, &factory.ServiceParams{
+		Name:  "logging",
+		Charm: s.factory.MakeCharm(c, &factory.CharmParams{Name: "logging"}),
+	})
+
+	removed, err := wordpress.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(removed.Destroy(), jc.ErrorIsNil)
+	wordpress0 := s.factory.MakeUnit(c, &factory.UnitParams{
+		Service:     wordpress,
+		Machine:     machine,
+		SetCharmURL: true,
+		Status: &state.StatusInfo{
+			Status:  state.StatusMaintenance,
+			Message: "installing",
+			Data:    map[string]interface{}{"step": "apt"},
+		},
+	})
+	err = wordpress0.SetAgentStatus(state.StatusIdle, "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	err = wordpress0.Health().SetStatus(state.StatusHealthy, "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	err = wordpress0.OpenPort("tcp", 80)
+	c.Assert(err, jc.ErrorIsNil)
+	err = st.LeadershipClaimer().ClaimLeadership("wordpress", wordpress0.Name(), time.Minute)
+	c.Assert(err, jc.ErrorIsNil)
+	err = st.SetLabels(machine, map[string]string{"zone": "a"})
+	c.Assert(err, jc.ErrorIsNil)
+	err = st.SetLabels(wordpress, map[string]string{"tier": "web"})
+	c.Assert(err, jc.ErrorIsNil)
+	err = st.SetLabels(wordpress0, map[string]string{"canary": "true"})
+	c.Assert(err, jc.ErrorIsNil)
+	mysql0 := s.factory.MakeUnit(c, &factory.UnitParams{
+		Service: mysql,
+		Machine: container,
+	})
+
+	eps, err := st.InferEndpoints("wordpress", "mysql")
+	c.Assert(err, jc.ErrorIsNil)
+	rel, err := st.AddRelation(eps...)
+	c.Assert(err, jc.ErrorIsNil)
+	s.enterScope(c, rel, wordpress0, map[string]interface{}{"private-address": "10.0.0.2"})
+	s.enterScope(c, rel, mysql0, map[string]interface{}{"user": "wp", "password": "secret"})
+
+	eps, err = st.InferEndpoints("logging", "wordpress")
+	c.Assert(err, jc.ErrorIsNil)
+	rel, err = st.AddRelation(eps...)
+	c.Assert(err, jc.ErrorIsNil)
+	s.enterScope(c, rel, wordpress0, nil)
+	logging0, err := st.Unit("logging/0")
+	c.Assert(err, jc.ErrorIsNil)
+	s.enterScope(c, rel, logging0, map[string]interface{}{"level": "debug"})
+
+	err = st.SwitchBlockOn(state.RemoveBlock, "keep everything")
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *environExportSuite) enterScope(c *gc.C, rel *state.Relation, u *state.Unit, settings map[string]interface{}) {
+	ru, err := rel.Unit(u)
+	c.Assert(err, jc.ErrorIsNil)
+	err = ru.EnterScope(settings)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+// renamed returns the description serialised and read back, with the
+// environment given a new UUID and name.
+func renamed(c *gc.C, desc *description.Environment, name string) *description.Environment {
+	uuid := utils.MustNewUUID().String()
+	desc.UUID = uuid
+	desc.Name = name
+	desc.Config["uuid"] = uuid
+	desc.Config["name"] = name
+	data, err := description.Serialize(desc)
+	c.Assert(err, jc.ErrorIsNil)
+	read, err := description.Deserialize(data)
+	c.Assert(err, jc.ErrorIsNil)
+	return read
+}
+
+func (s *environExportSuite) TestExport(c *gc.C) {
+	s.populate(c)
+	desc, err := s.envState.Export()
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Assert(desc.Version, gc.Equals, description.Version)
+	c.Assert(desc.UUID, gc.Equals, s.envState.EnvironUUID())
+	c.Assert(desc.Constraints, gc.Equals, "mem=4096M")
+	c.Assert(desc.Annotations, jc.DeepEquals, map[string]string{"purpose": "staging"})
+	c.Assert(desc.Users, gc.HasLen, 2)
+
+	c.Assert(desc.Machines, gc.HasLen, 1)
+	machine := desc.Machines[0]
+	c.Assert(machine.Id, gc.Equals, "1")
+	c.Assert(machine.Instance, gc.NotNil)
+	c.Assert(machine.Instance.Id, gc.Equals, "i-exported")
+	c.Assert(machine.Status.Value, gc.Equals, "started")
+	c.Assert(machine.Containers, gc.HasLen, 1)
+	c.Assert(machine.Containers[0].Id, gc.Equals, "1/lxc/0")
+	c.Assert(machine.OpenedPorts, jc.DeepEquals, []description.OpenedPorts{{
+		Network: network.DefaultPublic,
+		Ports: []description.PortRange{{
+			Unit:     "wordpress/1",
+			FromPort: 80,
+			ToPort:   80,
+			Protocol: "tcp",
+		}},
+	}})
+	c.Assert(machine.Labels, jc.DeepEquals, map[string]string{"zone": "a"})
+
+	var svcNames []string
+	for _, svc := range desc.Services {
+		svcNames = append(svcNames, svc.Name)
+	}
+	c.Assert(svcNames, jc.DeepEquals, []string{"logging", "mysql", "wordpress"})
+	wordpress := desc.Services[2]
+	c.Assert(wordpress.Exposed, jc.IsTrue)
+	c.Assert(wordpress.MinUnits, gc.Equals, 1)
+	c.Assert(wordpress.Settings, jc.DeepEquals, map[string]interface{}{"blog-title": "Exported"})
+	c.Assert(wordpress.ConfigHistory, gc.HasLen, 2)
+	c.Assert(wordpress.ConfigHistory[1].Settings, jc.DeepEquals, map[string]interface{}{"blog-title": "Exported"})
+	c.Assert(wordpress.Leader, gc.Equals, "wordpress/1")
+	c.Assert(wordpress.Labels, jc.DeepEquals, map[string]string{"tier": "web"})
+	c.Assert(wordpress.Status, gc.IsNil)
+	c.Assert(wordpress.Units, gc.HasLen, 1)
+	c.Assert(wordpress.Units[0].Name, gc.Equals, "wordpress/1")
+	c.Assert(wordpress.Units[0].Machine, gc.Equals, "1")
+	c.Assert(wordpress.Units[0].WorkloadStatus.Value, gc.Equals, "maintenance")
+	c.Assert(wordpress.Units[0].HealthStatus.Value, gc.Equals, "healthy")
+	c.Assert(wordpress.Units[0].Labels, jc.DeepEquals, map[string]string{"canary": "true"})
+	c.Assert(desc.Services[1].Status.Message, gc.Equals, "serving")
+	c.Assert(desc.Services[0].Units[0].Principal, gc.Equals, "wordpress/1")
+
+	c.Assert(desc.Relations, gc.HasLen, 2)
+	c.Assert(desc.Blocks, jc.DeepEquals, []description.Block{{
+		Type:    state.RemoveBlock.String(),
+		Message: "keep everything",
+	}})
+	c.Assert(desc.Sequences["machine"], gc.Equals, 2)
+	c.Assert(desc.Sequences["service-wordpress"], gc.Equals, 2)
+}
+
+// addStorage adds a unit of the storage-filesystem charm, with its
+// filesystem backed by a provisioned loop volume, to the hosted
+// environment.
+func (s *environExportSuite) addStorage(c *gc.C) *state.Unit {
+	st := s.envState
+	pm := poolmanager.New(state.NewStateSettings(st))
+	_, err := pm.Create("loop-pool", provider.LoopProviderType, map[string]interface{}{})
+	c.Assert(err, jc.ErrorIsNil)
+	env, err := st.Environment()
+	c.Assert(err, jc.ErrorIsNil)
+	ch := state.AddTestingCharm(c, st, "storage-filesystem")
+	svc := state.AddTestingServiceWithStorage(c, st, "storage-filesystem", ch, env.Owner(), map[string]state.StorageConstraints{
+		"data": makeStorageCons("loop-pool", 1024, 1),
+	})
+	machine := s.factory.MakeMachine(c, nil)
+	unit := s.factory.MakeUnit(c, &factory.UnitParams{
+		Service: svc,
+		Machine: machine,
+	})
+
+	storageTag := names.NewStorageTag("data/0")
+	volume, err := st.StorageInstanceVolume(storageTag)
+	c.Assert(err, jc.ErrorIsNil)
+	err = st.SetVolumeInfo(volume.VolumeTag(), state.VolumeInfo{VolumeId: "vol-0", Size: 1024})
+	c.Assert(err, jc.ErrorIsNil)
+	err = st.SetVolumeAttachmentInfo(machine.MachineTag(), volume.VolumeTag(), state.VolumeAttachmentInfo{DeviceName: "loop0"})
+	c.Assert(err, jc.ErrorIsNil)
+	filesystem, err := st.StorageInstanceFilesystem(storageTag)
+	c.Assert(err, jc.ErrorIsNil)
+	err = st.SetFilesystemInfo(filesystem.FilesystemTag(), state.FilesystemInfo{FilesystemId: "fs-0", Size: 1024})
+	c.Assert(err, jc.ErrorIsNil)
+	return unit
+}
+
+func (s *environExportSuite) TestExportWithStorage(c *gc.C) {
+	unit := s.addStorage(c)
+	desc, err := s.envState.Export()
+	c.Assert(err, jc.ErrorIsNil)
+
+	var poolNames []string
+	for _, pool := range desc.StoragePools {
+		poolNames = append(poolNames, pool.Name)
+	}
+	c.Assert(poolNames, jc.DeepEquals, []string{"loop-pool"})
+	c.Assert(desc.Storage, jc.DeepEquals, []description.StorageInstance{{
+		Id:          "data/0",
+		Kind:        "filesystem",
+		StorageName: "data",
+		Owner:       unit.UnitTag().String(),
+		CharmURL:    "local:quantal/quantal-storage-filesystem-1",
+		Attachments: []string{unit.Name()},
+	}})
+
+	c.Assert(desc.Volumes, gc.HasLen, 1)
+	volume := desc.Volumes[0]
+	c.Assert(volume.StorageId, gc.Equals, "data/0")
+	c.Assert(volume.Info, gc.NotNil)
+	c.Assert(volume.Info.VolumeId, gc.Equals, "vol-0")
+	c.Assert(volume.Info.Pool, gc.Equals, "loop-pool")
+	c.Assert(volume.Attachments, gc.HasLen, 1)
+	machineId, err := unit.AssignedMachineId()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(volume.Attachments[0].Machine, gc.Equals, machineId)
+	c.Assert(volume.Attachments[0].Info, gc.NotNil)
+	c.Assert(volume.Attachments[0].Info.DeviceName, gc.Equals, "loop0")
+
+	c.Assert(desc.Filesystems, gc.HasLen, 1)
+	filesystem := desc.Filesystems[0]
+	c.Assert(filesystem.StorageId, gc.Equals, "data/0")
+	c.Assert(filesystem.Volume, gc.Equals, volume.Name)
+	c.Assert(filesystem.Info, gc.NotNil)
+	c.Assert(filesystem.Info.FilesystemId, gc.Equals, "fs-0")
+	c.Assert(filesystem.Attachments, gc.HasLen, 1)
+	c.Assert(filesystem.Attachments[0].Info, gc.IsNil)
+	c.Assert(filesystem.Attachments[0].Params, gc.NotNil)
+}
+
+func (s *environExportSuite) TestExportImportStorage(c *gc.C) {
+	unit := s.addStorage(c)
+	desc, err := s.envState.Export()
+	c.Assert(err, jc.ErrorIsNil)
+	desc = renamed(c, desc, "clone")
+
+	_, st, err := s.State.Import(desc)
+	c.Assert(err, jc.ErrorIsNil)
+	defer st.Close()
+
+	cloned, err := st.Export()
+	c.Assert(err, jc.ErrorIsNil)
+	expected, err := description.Serialize(desc)
+	c.Assert(err, jc.ErrorIsNil)
+	obtained, err := description.Serialize(cloned)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(obtained), gc.Equals, string(expected))
+
+	// The imported storage is attached to the unit and machine as
+	// described.
+	attachments, err := st.UnitStorageAttachments(unit.UnitTag())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(attachments, gc.HasLen, 1)
+	c.Assert(attachments[0].StorageInstance(), gc.Equals, names.NewStorageTag("data/0"))
+	machineId, err := unit.AssignedMachineId()
+	c.Assert(err, jc.ErrorIsNil)
+	machineTag := names.NewMachineTag(machineId)
+	volumeAttachments, err := st.MachineVolumeAttachments(machineTag)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(volumeAttachments, gc.HasLen, 1)
+	filesystemAttachments, err := st.MachineFilesystemAttachments(machineTag)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(filesystemAttachments, gc.HasLen, 1)
+}
+
+func (s *environExportSuite) TestExportWithDyingStorage(c *gc.C) {
+	s.addStorage(c)
+	err := s.envState.DestroyStorageInstance(names.NewStorageTag("data/0"))
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = s.envState.Export()
+	c.Assert(err, gc.ErrorMatches, "cannot export environment: exporting environment with storage being removed not supported")
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+}
+
+func (s *environExportSuite) TestExportWithRemoteService(c *gc.C) {
+	_, err := s.envState.AddRemoteService(state.AddRemoteServiceArgs{
+		Name:              "db",
+		SourceEnv:         s.State.EnvironTag(),
+		SourceServiceName: "mysql",
+		Endpoints:         []charm.Relation{mysqlServerRelation},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = s.envState.Export()
+	c.Assert(err, gc.ErrorMatches, "cannot export environment: exporting environment with remote services not supported")
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+}
+
+func (s *environExportSuite) TestExportWithCharmRollout(c *gc.C) {
+	oldCharm := s.factory.MakeCharm(c, &factory.CharmParams{Name: "mysql", Revision: "1"})
+	newCharm := s.factory.MakeCharm(c, &factory.CharmParams{Name: "mysql", Revision: "2"})
+	mysql := s.factory.MakeService(c, &factory.ServiceParams{Name: "mysql", Charm: oldCharm})
+	for i := 0; i < 2; i++ {
+		s.factory.MakeUnit(c, &factory.UnitParams{Service: mysql, SetCharmURL: true})
+	}
+	err := mysql.SetCharmStaged(newCharm, false, false, state.CharmRolloutParams{CanaryUnits: 1})
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = s.envState.Export()
+	c.Assert(err, gc.ErrorMatches, "cannot export environment: exporting environment with charm rollouts in progress not supported")
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+}
+
+func (s *environExportSuite) TestExportImportRoundTrip(c *gc.C) {
+	s.populate(c)
+	desc, err := s.envState.Export()
+	c.Assert(err, jc.ErrorIsNil)
+	desc = renamed(c, desc, "clone")
+
+	env, st, err := s.State.Import(desc)
+	c.Assert(err, jc.ErrorIsNil)
+	defer st.Close()
+	c.Assert(env.UUID(), gc.Equals, desc.UUID)
+	c.Assert(env.Name(), gc.Equals, "clone")
+
+	// The imported environment is described exactly as the original
+	// was, once renamed.
+	cloned, err := st.Export()
+	c.Assert(err, jc.ErrorIsNil)
+	expected, err := description.Serialize(desc)
+	c.Assert(err, jc.ErrorIsNil)
+	obtained, err := description.Serialize(cloned)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(obtained), gc.Equals, string(expected))
+
+	// New entities in the imported environment do not reuse the ids
+	// of those removed from the original.
+	m, err := st.AddMachine("trusty", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(m.Id(), gc.Equals, "2")
+	svc, err := st.Service("wordpress")
+	c.Assert(err, jc.ErrorIsNil)
+	u, err := svc.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(u.Name(), gc.Equals, "wordpress/2")
+}
+
+func (s *environExportSuite) TestImportFailureRemovesEnvironment(c *gc.C) {
+	s.populate(c)
+	desc, err := s.envState.Export()
+	c.Assert(err, jc.ErrorIsNil)
+	desc = renamed(c, desc, "clone")
+	desc.Services[0].Owner = names.NewUserTag("nobody").String()
+
+	_, _, err = s.State.Import(desc)
+	c.Assert(err, gc.ErrorMatches, `cannot import services: service "logging": .*`)
+	_, err = s.State.GetEnvironment(names.NewEnvironTag(desc.UUID))
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *environExportSuite) TestImportIntoHostedEnvironment(c *gc.C) {
+	desc, err := s.envState.Export()
+	c.Assert(err, jc.ErrorIsNil)
+	desc = renamed(c, desc, "clone")
+	_, _, err = s.envState.Import(desc)
+	c.Assert(err, gc.ErrorMatches, "cannot import environment into a hosted environment")
+}
+
+func (s *environExportSuite) TestImportExistingEnvironment(c *gc.C) {
+	desc, err := s.envState.Export()
+	c.Assert(err, jc.ErrorIsNil)
+	_, _, err = s.State.Import(desc)
+	c.Assert(err, gc.NotNil)
+	env, err := s.envState.Environment()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(env.Life(), gc.Equals, state.Alive)
+}
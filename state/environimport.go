@@ -0,0 +1,1073 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"gopkg.in/juju/charm.v6-unstable"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+
+	"github.com/juju/juju/constraints"
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/network"
+	"github.com/juju/juju/state/description"
+	"github.com/juju/juju/storage"
+	"github.com/juju/juju/storage/poolmanager"
+)
+
+// Import builds a new environment hosted by this controller from the
+// description written by Export, and returns the environment and a
+// State for it, which the caller is responsible for closing. The
+// environment takes its UUID and name from the description, so that
+// an environment can be cloned by changing them.
+//
+// Entities keep the ids and names they are described with. The users
+// and the owners of the environment and its services must already
+// exist, and the archives of the described charms must be copied to
+// the new environment's storage separately. Machines that are not
+// described as provisioned are provisioned once the environment is
+// imported.
+func (st *State) Import(desc *description.Environment) (_ *Environment, _ *State, err error) {
+	if !st.IsStateServer() {
+		return nil, nil, errors.New("cannot import environment into a hosted environment")
+	}
+	if err := desc.Validate(); err != nil {
+		return nil, nil, errors.Annotate(err, "cannot import environment")
+	}
+	attrs := make(map[string]interface{}, len(desc.Config))
+	for key, value := range desc.Config {
+		attrs[key] = value
+	}
+	attrs["uuid"] = desc.UUID
+	attrs["name"] = desc.Name
+	cfg, err := config.New(config.NoDefaults, attrs)
+	if err != nil {
+		return nil, nil, errors.Annotate(err, "cannot import environment")
+	}
+	if !names.IsValidUser(desc.Owner) {
+		return nil, nil, errors.NotValidf("environment owner %q", desc.Owner)
+	}
+	env, newSt, err := st.NewEnvironment(cfg, names.NewUserTag(desc.Owner))
+	if err != nil {
+		return nil, nil, errors.Annotate(err, "cannot import environment")
+	}
+	defer func() {
+		if err == nil {
+			return
+		}
+		if removeErr := newSt.removeImported(env); removeErr != nil {
+			logger.Errorf("cannot remove partially imported environment %s: %v", env.UUID(), removeErr)
+		}
+		newSt.Close()
+	}()
+
+	i := &importer{st: newSt, desc: desc}
+	if err := i.environment(env); err != nil {
+		return nil, nil, errors.Annotate(err, "cannot import environment")
+	}
+	if err := i.users(); err != nil {
+		return nil, nil, errors.Annotate(err, "cannot import users")
+	}
+	if err := i.machines(desc.Machines, ""); err != nil {
+		return nil, nil, errors.Annotate(err, "cannot import machines")
+	}
+	if err := i.charms(); err != nil {
+		return nil, nil, errors.Annotate(err, "cannot import charms")
+	}
+	if err := i.storagePools(); err != nil {
+		return nil, nil, errors.Annotate(err, "cannot import storage pools")
+	}
+	if err := i.services(); err != nil {
+		return nil, nil, errors.Annotate(err, "cannot import services")
+	}
+	if err := i.relations(); err != nil {
+		return nil, nil, errors.Annotate(err, "cannot import relations")
+	}
+	if err := i.storage(); err != nil {
+		return nil, nil, errors.Annotate(err, "cannot import storage")
+	}
+	if err := i.openedPorts(desc.Machines); err != nil {
+		return nil, nil, errors.Annotate(err, "cannot import opened ports")
+	}
+	if err := i.leaders(); err != nil {
+		return nil, nil, errors.Annotate(err, "cannot import service leaders")
+	}
+	if err := i.blocks(); err != nil {
+		return nil, nil, errors.Annotate(err, "cannot import blocks")
+	}
+	for name, next := range desc.Sequences {
+		if err := newSt.setSequence(name, next); err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+	}
+	return env, newSt, nil
+}
+
+// removeImported removes an environment whose import failed.
+func (st *State) removeImported(env *Environment) error {
+	if err := env.Refresh(); err != nil {
+		return errors.Trace(err)
+	}
+	if err := env.setDeadNow(); err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(st.RemoveAllEnvironDocs())
+}
+
+// importer holds the state of an Import.
+type importer struct {
+	st   *State
+	desc *description.Environment
+}
+
+func (i *importer) environment(env *Environment) error {
+	cons, err := constraints.Parse(i.desc.Constraints)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if err := i.st.SetEnvironConstraints(cons); err != nil {
+		return errors.Trace(err)
+	}
+	return i.annotations(env, i.desc.Annotations)
+}
+
+func (i *importer) users() error {
+	for _, user := range i.desc.Users {
+		if user.Name == i.desc.Owner {
+			// The owner is added when the environment is created.
+			continue
+		}
+		if !names.IsValidUser(user.Name) || !names.IsValidUser(user.CreatedBy) {
+			return errors.NotValidf("user %q created by %q", user.Name, user.CreatedBy)
+		}
+		_, err := i.st.AddEnvironmentUser(EnvUserSpec{
+			User:        names.NewUserTag(user.Name),
+			CreatedBy:   names.NewUserTag(user.CreatedBy),
+			DisplayName: user.DisplayName,
+			Access:      EnvUserAccess(user.Access),
+		})
+		if err != nil {
+			return errors.Annotatef(err, "user %q", user.Name)
+		}
+	}
+	return nil
+}
+
+// machines imports the described machines, which are hosted by the
+// machine with the given id, or are top level machines if the id is
+// empty.
+func (i *importer) machines(machines []description.Machine, parentId string) error {
+	for _, desc := range machines {
+		if err := i.machine(desc, parentId); err != nil {
+			return errors.Annotatef(err, "machine %s", desc.Id)
+		}
+		if err := i.machines(desc.Containers, desc.Id); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+func (i *importer) machine(desc description.Machine, parentId string) error {
+	template := MachineTemplate{
+		Series:    desc.Series,
+		Placement: desc.Placement,
+	}
+	for _, name := range desc.Jobs {
+		job, err := machineJobFromString(name)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		template.Jobs = append(template.Jobs, job)
+	}
+	var err error
+	if template.Constraints, err = constraints.Parse(desc.Constraints); err != nil {
+		return errors.Trace(err)
+	}
+
+	// Each machine is given its id by setting the sequence it is
+	// allocated from.
+	parts := strings.Split(desc.Id, "/")
+	num, err := strconv.Atoi(parts[len(parts)-1])
+	if err != nil || !names.IsValidMachine(desc.Id) {
+		return errors.NotValidf("machine id %q", desc.Id)
+	}
+	var m *Machine
+	if parentId == "" {
+		if err := i.st.setSequence("machine", num); err != nil {
+			return errors.Trace(err)
+		}
+		m, err = i.st.AddOneMachine(template)
+	} else {
+		containerType := instance.ContainerType(parts[len(parts)-2])
+		seq := fmt.Sprintf("machine%s%sContainer", parentId, containerType)
+		if err := i.st.setSequence(seq, num); err != nil {
+			return errors.Trace(err)
+		}
+		m, err = i.st.AddMachineInsideMachine(template, parentId, containerType)
+	}
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if m.Id() != desc.Id {
+		return errors.Errorf("machine imported with id %q", m.Id())
+	}
+
+	if desc.Instance != nil {
+		hc, err := instance.ParseHardware(desc.Instance.Hardware)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if err := m.SetProvisioned(instance.Id(desc.Instance.Id), desc.Instance.Nonce, &hc); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if len(desc.ProviderAddresses) > 0 {
+		if err := m.SetProviderAddresses(importAddresses(desc.ProviderAddresses)...); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if len(desc.MachineAddresses) > 0 {
+		if err := m.SetMachineAddresses(importAddresses(desc.MachineAddresses)...); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if desc.PasswordHash != "" {
+		if err := m.setPasswordHash(desc.PasswordHash); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if err := i.status(m.globalKey(), desc.Status); err != nil {
+		return errors.Trace(err)
+	}
+	if err := i.annotations(m, desc.Annotations); err != nil {
+		return errors.Trace(err)
+	}
+	return i.labels(m, desc.Labels)
+}
+
+func (i *importer) charms() error {
+	for _, desc := range i.desc.Charms {
+		curl, err := charm.ParseURL(desc.URL)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if desc.Meta == nil {
+			return errors.NotValidf("charm %q without metadata", desc.URL)
+		}
+		doc := &charmDoc{
+			URL:     curl,
+			Meta:    &charm.Meta{},
+			Config:  charm.NewConfig(),
+			Actions: &charm.Actions{},
+			Metrics: &charm.Metrics{},
+		}
+		for _, field := range []struct {
+			m     map[string]interface{}
+			value interface{}
+		}{
+			{desc.Meta, doc.Meta},
+			{desc.Config, doc.Config},
+			{desc.Actions, doc.Actions},
+			{desc.Metrics, doc.Metrics},
+		} {
+			if err := fromMap(field.m, field.value); err != nil {
+				return errors.Annotatef(err, "charm %q", desc.URL)
+			}
+		}
+		if err := doc.Meta.Check(); err != nil {
+			return errors.Annotatef(err, "charm %q", desc.URL)
+		}
+		ch := newCharm(i.st, doc)
+		if _, err := i.st.AddCharm(ch, curl, desc.StoragePath, desc.BundleSha256); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+func (i *importer) services() error {
+	for _, desc := range i.desc.Services {
+		if err := i.service(desc); err != nil {
+			return errors.Annotatef(err, "service %q", desc.Name)
+		}
+	}
+	return nil
+}
+
+func (i *importer) service(desc description.Service) error {
+	curl, err := charm.ParseURL(desc.CharmURL)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	ch, err := i.st.Charm(curl)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	cons, err := constraints.Parse(desc.Constraints)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	storageCons := make(map[string]StorageConstraints)
+	for name, cons := range desc.Storage {
+		storageCons[name] = StorageConstraints{
+			Pool:  cons.Pool,
+			Size:  cons.Size,
+			Count: cons.Count,
+		}
+	}
+	svc, err := i.st.AddService(AddServiceArgs{
+		Name:             desc.Name,
+		Series:           desc.Series,
+		Owner:            desc.Owner,
+		Charm:            ch,
+		Storage:          storageCons,
+		Settings:         charm.Settings(desc.Settings),
+		Constraints:      cons,
+		EndpointBindings: desc.EndpointBindings,
+	})
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if desc.Exposed {
+		if err := svc.SetExposed(); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	for _, unit := range desc.Units {
+		if unit.Principal != "" {
+			// Subordinate units are added when their principals
+			// enter the scope of their relation.
+			continue
+		}
+		if err := i.principalUnit(svc, unit); err != nil {
+			return errors.Annotatef(err, "unit %q", unit.Name)
+		}
+	}
+	if err := svc.SetMinUnits(desc.MinUnits); err != nil {
+		return errors.Trace(err)
+	}
+	if err := i.serviceHistory(svc, desc); err != nil {
+		return errors.Trace(err)
+	}
+	if err := i.status(svc.globalKey(), desc.Status); err != nil {
+		return errors.Trace(err)
+	}
+	if err := i.annotations(svc, desc.Annotations); err != nil {
+		return errors.Trace(err)
+	}
+	return i.labels(svc, desc.Labels)
+}
+
+// serviceHistory records the service's described config history and
+// previous charm, so that the service can still be rolled back.
+func (i *importer) serviceHistory(svc *Service, desc description.Service) error {
+	var ops []txn.Op
+	var set bson.D
+	if desc.PreviousCharmURL != "" {
+		curl, err := charm.ParseURL(desc.PreviousCharmURL)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		set = append(set, bson.DocElem{"previouscharmurl", curl})
+	}
+	for _, revision := range desc.ConfigHistory {
+		t, err := time.Parse(time.RFC3339Nano, revision.Time)
+		if err != nil {
+			return errors.Annotatef(err, "cannot parse time of config revision %d", revision.Revision)
+		}
+		doc := &configRevisionDoc{
+			DocID:        fmt.Sprintf("%s#%d", desc.Name, revision.Revision),
+			EnvUUID:      i.st.EnvironUUID(),
+			Service:      desc.Name,
+			Revision:     revision.Revision,
+			CharmURL:     revision.CharmURL,
+			Settings:     copyMap(revision.Settings, escapeReplacer.Replace),
+			User:         revision.User,
+			Time:         t.UnixNano(),
+			RolledBackTo: revision.RolledBackTo,
+		}
+		ops = append(ops, txn.Op{
+			C:      configHistoryC,
+			Id:     doc.DocID,
+			Assert: txn.DocMissing,
+			Insert: doc,
+		})
+	}
+	if n := len(desc.ConfigHistory); n > 0 {
+		set = append(set,
+			bson.DocElem{"firstconfigrevision", desc.ConfigHistory[0].Revision},
+			bson.DocElem{"configrevision", desc.ConfigHistory[n-1].Revision},
+		)
+	}
+	if len(set) == 0 {
+		return nil
+	}
+	ops = append(ops, txn.Op{
+		C:      servicesC,
+		Id:     svc.doc.DocID,
+		Assert: txn.DocExists,
+		Update: bson.D{{"$set", set}},
+	})
+	return errors.Annotate(i.st.runTransaction(ops), "cannot set config history")
+}
+
+func (i *importer) principalUnit(svc *Service, desc description.Unit) error {
+	if err := i.setUnitSequence(desc.Name); err != nil {
+		return errors.Trace(err)
+	}
+	u, err := i.addUnit(svc)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if u.Name() != desc.Name {
+		return errors.Errorf("unit imported as %q", u.Name())
+	}
+	if desc.Machine != "" {
+		m, err := i.st.Machine(desc.Machine)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if err := u.AssignToMachine(m); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return i.unit(u, desc)
+}
+
+// addUnit adds a principal unit to the service. Unlike
+// Service.AddUnit, it does not create the unit's storage, which is
+// imported as described.
+func (i *importer) addUnit(svc *Service) (*Unit, error) {
+	scons, err := svc.Constraints()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	cons, err := i.st.resolveConstraints(scons)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	name, ops, err := svc.addUnitOpsWithCons(addUnitOpsArgs{cons: cons})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	ops = append(ops, svc.incUnitCountOp(isAliveDoc))
+	if err := i.st.runTransaction(ops); err != nil {
+		return nil, errors.Annotatef(err, "cannot add unit to service %q", svc)
+	}
+	return i.st.Unit(name)
+}
+
+// setUnitSequence sets the sequence the named unit's number is
+// allocated from, so that the next unit of the service is given
+// that name.
+func (i *importer) setUnitSequence(unitName string) error {
+	if !names.IsValidUnit(unitName) {
+		return errors.NotValidf("unit name %q", unitName)
+	}
+	serviceName, err := names.UnitService(unitName)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return i.st.setSequence(names.NewServiceTag(serviceName).String(), unitNumber(unitName))
+}
+
+func (i *importer) unit(u *Unit, desc description.Unit) error {
+	if desc.CharmURL != "" {
+		curl, err := charm.ParseURL(desc.CharmURL)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if err := u.SetCharmURL(curl); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if desc.PasswordHash != "" {
+		if err := u.setPasswordHash(desc.PasswordHash); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if err := i.status(u.globalKey(), desc.WorkloadStatus); err != nil {
+		return errors.Trace(err)
+	}
+	if err := i.status(u.globalAgentKey(), desc.AgentStatus); err != nil {
+		return errors.Trace(err)
+	}
+	if err := i.healthStatus(u, desc.HealthStatus); err != nil {
+		return errors.Trace(err)
+	}
+	if err := i.annotations(u, desc.Annotations); err != nil {
+		return errors.Trace(err)
+	}
+	return i.labels(u, desc.Labels)
+}
+
+func (i *importer) relations() error {
+	relations := make([]*Relation, len(i.desc.Relations))
+	for n, desc := range i.desc.Relations {
+		rel, err := i.relation(desc)
+		if err != nil {
+			return errors.Annotatef(err, "relation %q", desc.Key)
+		}
+		relations[n] = rel
+	}
+
+	// Principal units enter the scope of container scoped relations
+	// first, which adds their subordinate units; then every other
+	// unit enters scope.
+	entered := make(map[string]bool)
+	for n, desc := range i.desc.Relations {
+		if err := i.enterScopes(relations[n], desc, true, entered); err != nil {
+			return errors.Annotatef(err, "relation %q", desc.Key)
+		}
+	}
+	for _, svc := range i.desc.Services {
+		for _, desc := range svc.Units {
+			if desc.Principal == "" {
+				continue
+			}
+			u, err := i.st.Unit(desc.Name)
+			if errors.IsNotFound(err) {
+				return errors.Errorf("subordinate unit %q not added by its principal %q", desc.Name, desc.Principal)
+			} else if err != nil {
+				return errors.Trace(err)
+			}
+			if err := i.unit(u, desc); err != nil {
+				return errors.Annotatef(err, "unit %q", desc.Name)
+			}
+		}
+	}
+	for n, desc := range i.desc.Relations {
+		if err := i.enterScopes(relations[n], desc, false, entered); err != nil {
+			return errors.Annotatef(err, "relation %q", desc.Key)
+		}
+	}
+	return nil
+}
+
+// relation adds the described relation, or finds it if it is a peer
+// relation added with its service, and gives it its described id.
+func (i *importer) relation(desc description.Relation) (*Relation, error) {
+	rel, err := i.st.KeyRelation(desc.Key)
+	if errors.IsNotFound(err) {
+		var eps []Endpoint
+		for _, ep := range desc.Endpoints {
+			svc, err := i.st.Service(ep.Service)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			endpoint, err := svc.Endpoint(ep.Name)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			eps = append(eps, endpoint)
+		}
+		rel, err = i.st.AddRelation(eps...)
+	}
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if rel.Id() != desc.Id {
+		// Relation ids are allocated in an order that cannot be
+		// recreated, as peer relations are added with their
+		// services; and no unit has entered scope yet, so the ids
+		// are not referred to anywhere.
+		ops := []txn.Op{{
+			C:      relationsC,
+			Id:     rel.doc.DocID,
+			Assert: bson.D{{"id", rel.Id()}},
+			Update: bson.D{{"$set", bson.D{{"id", desc.Id}}}},
+		}}
+		if err := i.st.runTransaction(ops); err != nil {
+			return nil, errors.Annotate(err, "cannot set relation id")
+		}
+		if err := rel.Refresh(); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	return rel, nil
+}
+
+// enterScopes enters the described units of the relation into its
+// scope: if principals is true, the principal units of a container
+// scoped relation, and otherwise all the units not yet entered.
+func (i *importer) enterScopes(rel *Relation, desc description.Relation, principals bool, entered map[string]bool) error {
+	containerScoped := false
+	for _, ep := range desc.Endpoints {
+		if ep.Scope == string(charm.ScopeContainer) {
+			containerScoped = true
+		}
+	}
+	if principals && !containerScoped {
+		return nil
+	}
+	for _, ep := range desc.Endpoints {
+		unitNames := make([]string, 0, len(ep.UnitSettings))
+		for unitName := range ep.UnitSettings {
+			unitNames = append(unitNames, unitName)
+		}
+		sort.Strings(unitNames)
+		for _, unitName := range unitNames {
+			key := fmt.Sprintf("%d %s", desc.Id, unitName)
+			if entered[key] {
+				continue
+			}
+			u, err := i.st.Unit(unitName)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			if principals {
+				if !u.IsPrincipal() {
+					continue
+				}
+				if err := i.setSubordinateSequence(u, desc); err != nil {
+					return errors.Trace(err)
+				}
+			}
+			ru, err := rel.Unit(u)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			if err := ru.EnterScope(ep.UnitSettings[unitName]); err != nil {
+				return errors.Annotatef(err, "unit %q", unitName)
+			}
+			entered[key] = true
+		}
+	}
+	return nil
+}
+
+// setSubordinateSequence sets the sequence of the subordinate unit the
+// principal unit gets when it enters the scope of the described
+// container scoped relation, so that the unit is given the name it is
+// described with.
+func (i *importer) setSubordinateSequence(principal *Unit, desc description.Relation) error {
+	for _, ep := range desc.Endpoints {
+		if ep.Service == principal.ServiceName() {
+			continue
+		}
+		for _, svc := range i.desc.Services {
+			if svc.Name != ep.Service {
+				continue
+			}
+			for _, unit := range svc.Units {
+				if unit.Principal == principal.Name() {
+					return i.setUnitSequence(unit.Name)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// storagePools creates the described storage pools, which must exist
+// before the services whose storage constraints name them are added.
+func (i *importer) storagePools() error {
+	pm := poolmanager.New(NewStateSettings(i.st))
+	for _, pool := range i.desc.StoragePools {
+		if _, err := pm.Create(pool.Name, storage.ProviderType(pool.Provider), pool.Attrs); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// storage adds the described storage instances, volumes and
+// filesystems, and attaches them to the units and machines they are
+// described as attached to.
+func (i *importer) storage() error {
+	for _, desc := range i.desc.Storage {
+		if err := i.storageInstance(desc); err != nil {
+			return errors.Annotatef(err, "storage %q", desc.Id)
+		}
+	}
+	for _, desc := range i.desc.Volumes {
+		if err := i.volume(desc); err != nil {
+			return errors.Annotatef(err, "volume %q", desc.Name)
+		}
+	}
+	for _, desc := range i.desc.Filesystems {
+		if err := i.filesystem(desc); err != nil {
+			return errors.Annotatef(err, "filesystem %q", desc.Id)
+		}
+	}
+	return nil
+}
+
+func (i *importer) storageInstance(desc description.StorageInstance) error {
+	if !names.IsValidStorage(desc.Id) {
+		return errors.NotValidf("storage id %q", desc.Id)
+	}
+	if _, err := names.ParseTag(desc.Owner); err != nil {
+		return errors.Annotate(err, "cannot parse owner")
+	}
+	kind, err := storageKindFromString(desc.Kind)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	doc := &storageInstanceDoc{
+		Id:              desc.Id,
+		Kind:            kind,
+		Owner:           desc.Owner,
+		StorageName:     desc.StorageName,
+		AttachmentCount: len(desc.Attachments),
+	}
+	if desc.CharmURL != "" {
+		if doc.CharmURL, err = charm.ParseURL(desc.CharmURL); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	ops := []txn.Op{{
+		C:      storageInstancesC,
+		Id:     desc.Id,
+		Assert: txn.DocMissing,
+		Insert: doc,
+	}}
+	for _, unitName := range desc.Attachments {
+		ops = append(ops, createStorageAttachmentOp(names.NewStorageTag(desc.Id), names.NewUnitTag(unitName)), txn.Op{
+			C:      unitsC,
+			Id:     i.st.docID(unitName),
+			Assert: txn.DocExists,
+			Update: bson.D{{"$inc", bson.D{{"storageattachmentcount", 1}}}},
+		})
+	}
+	return errors.Trace(i.st.runTransaction(ops))
+}
+
+func (i *importer) volume(desc description.Volume) error {
+	doc := &volumeDoc{
+		Name:            desc.Name,
+		StorageId:       desc.StorageId,
+		Binding:         desc.Binding,
+		AttachmentCount: len(desc.Attachments),
+	}
+	if desc.Params != nil {
+		doc.Params = &VolumeParams{
+			Pool: desc.Params.Pool,
+			Size: desc.Params.Size,
+		}
+	}
+	if desc.Info != nil {
+		doc.Info = &VolumeInfo{
+			VolumeId:   desc.Info.VolumeId,
+			HardwareId: desc.Info.HardwareId,
+			Pool:       desc.Info.Pool,
+			Size:       desc.Info.Size,
+			Persistent: desc.Info.Persistent,
+		}
+	}
+	status, err := importStorageStatusDoc(desc.Status)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	ops := []txn.Op{
+		createStatusOp(i.st, volumeGlobalKey(desc.Name), status),
+		{
+			C:      volumesC,
+			Id:     desc.Name,
+			Assert: txn.DocMissing,
+			Insert: doc,
+		},
+	}
+	for _, att := range desc.Attachments {
+		attDoc := &volumeAttachmentDoc{
+			Volume:  desc.Name,
+			Machine: att.Machine,
+		}
+		if att.Params != nil {
+			attDoc.Params = &VolumeAttachmentParams{
+				ReadOnly: att.Params.ReadOnly,
+			}
+		}
+		if att.Info != nil {
+			attDoc.Info = &VolumeAttachmentInfo{
+				DeviceName: att.Info.DeviceName,
+				DeviceLink: att.Info.DeviceLink,
+				BusAddress: att.Info.BusAddress,
+				ReadOnly:   att.Info.ReadOnly,
+			}
+		}
+		ops = append(ops, txn.Op{
+			C:      volumeAttachmentsC,
+			Id:     volumeAttachmentId(att.Machine, desc.Name),
+			Assert: txn.DocMissing,
+			Insert: attDoc,
+		}, txn.Op{
+			C:      machinesC,
+			Id:     i.st.docID(att.Machine),
+			Assert: txn.DocExists,
+			Update: bson.D{{"$addToSet", bson.D{{"volumes", desc.Name}}}},
+		})
+	}
+	return errors.Trace(i.st.runTransaction(ops))
+}
+
+func (i *importer) filesystem(desc description.Filesystem) error {
+	doc := &filesystemDoc{
+		FilesystemId:    desc.Id,
+		StorageId:       desc.StorageId,
+		VolumeId:        desc.Volume,
+		Binding:         desc.Binding,
+		AttachmentCount: len(desc.Attachments),
+	}
+	if desc.Params != nil {
+		doc.Params = &FilesystemParams{
+			Pool: desc.Params.Pool,
+			Size: desc.Params.Size,
+		}
+	}
+	if desc.Info != nil {
+		doc.Info = &FilesystemInfo{
+			FilesystemId: desc.Info.FilesystemId,
+			Pool:         desc.Info.Pool,
+			Size:         desc.Info.Size,
+		}
+	}
+	status, err := importStorageStatusDoc(desc.Status)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	ops := []txn.Op{
+		createStatusOp(i.st, filesystemGlobalKey(desc.Id), status),
+		{
+			C:      filesystemsC,
+			Id:     desc.Id,
+			Assert: txn.DocMissing,
+			Insert: doc,
+		},
+	}
+	for _, att := range desc.Attachments {
+		attDoc := &filesystemAttachmentDoc{
+			Filesystem: desc.Id,
+			Machine:    att.Machine,
+		}
+		if att.Params != nil {
+			attDoc.Params = &FilesystemAttachmentParams{
+				Location: att.Params.Location,
+				ReadOnly: att.Params.ReadOnly,
+			}
+		}
+		if att.Info != nil {
+			attDoc.Info = &FilesystemAttachmentInfo{
+				MountPoint: att.Info.MountPoint,
+				ReadOnly:   att.Info.ReadOnly,
+			}
+		}
+		ops = append(ops, txn.Op{
+			C:      filesystemAttachmentsC,
+			Id:     filesystemAttachmentId(att.Machine, desc.Id),
+			Assert: txn.DocMissing,
+			Insert: attDoc,
+		}, txn.Op{
+			C:      machinesC,
+			Id:     i.st.docID(att.Machine),
+			Assert: txn.DocExists,
+			Update: bson.D{{"$addToSet", bson.D{{"filesystems", desc.Id}}}},
+		})
+	}
+	return errors.Trace(i.st.runTransaction(ops))
+}
+
+// importStorageStatusDoc returns the status document for the described
+// status of a volume or filesystem, which is pending if its status was
+// never set.
+func importStorageStatusDoc(desc *description.Status) (statusDoc, error) {
+	if desc == nil {
+		return statusDoc{
+			Status:  StatusPending,
+			Updated: time.Now().UnixNano(),
+		}, nil
+	}
+	return importStatusDoc(desc)
+}
+
+// openedPorts opens the ports described on the machines, and on the
+// containers they host, once the units that opened them are added.
+func (i *importer) openedPorts(machines []description.Machine) error {
+	for _, m := range machines {
+		for _, opened := range m.OpenedPorts {
+			ports, err := getOrCreatePorts(i.st, m.Id, opened.Network)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			for _, desc := range opened.Ports {
+				portRange, err := NewPortRange(desc.Unit, desc.FromPort, desc.ToPort, desc.Protocol)
+				if err != nil {
+					return errors.Annotatef(err, "machine %s", m.Id)
+				}
+				if err := ports.OpenPorts(portRange); err != nil {
+					return errors.Annotatef(err, "machine %s", m.Id)
+				}
+			}
+		}
+		if err := i.openedPorts(m.Containers); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// importedLeaseDuration is the duration of the leadership claimed for
+// the described leader of each service; the leader's unit agent extends
+// it once it is running against the imported environment, and another
+// unit is elected if it does not.
+const importedLeaseDuration = time.Minute
+
+// leaders claims the leadership of each service for its described
+// leader.
+func (i *importer) leaders() error {
+	claimer := i.st.LeadershipClaimer()
+	for _, svc := range i.desc.Services {
+		if svc.Leader == "" {
+			continue
+		}
+		if err := claimer.ClaimLeadership(svc.Name, svc.Leader, importedLeaseDuration); err != nil {
+			return errors.Annotatef(err, "service %q", svc.Name)
+		}
+	}
+	return nil
+}
+
+func (i *importer) blocks() error {
+	for _, desc := range i.desc.Blocks {
+		t, err := blockTypeFromString(desc.Type)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if err := i.st.SwitchBlockOn(t, desc.Message); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// status sets the status with the given global key as described, if
+// it was ever set.
+func (i *importer) status(globalKey string, desc *description.Status) error {
+	if desc == nil {
+		return nil
+	}
+	doc, err := importStatusDoc(desc)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Annotatef(i.st.run(updateStatusSource(i.st, globalKey, doc)), "cannot set status")
+}
+
+// healthStatus records the described results of the unit's health
+// checks, if they were ever reported. Unlike the unit's other
+// statuses, its health status is only created once reported.
+func (i *importer) healthStatus(u *Unit, desc *description.Status) error {
+	if desc == nil {
+		return nil
+	}
+	doc, err := importStatusDoc(desc)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	doc.EnvUUID = i.st.EnvironUUID()
+	ops := []txn.Op{{
+		C:      unitsC,
+		Id:     u.doc.DocID,
+		Assert: txn.DocExists,
+	}, createStatusOp(i.st, unitHealthGlobalKey(u.Name()), doc)}
+	return errors.Annotatef(i.st.runTransaction(ops), "cannot set health status")
+}
+
+// importStatusDoc returns the status document for the described
+// status.
+func importStatusDoc(desc *description.Status) (statusDoc, error) {
+	doc := statusDoc{
+		Status:     Status(desc.Value),
+		StatusInfo: desc.Message,
+		StatusData: escapeKeys(desc.Data),
+		Updated:    time.Now().UnixNano(),
+	}
+	if desc.Updated != "" {
+		updated, err := time.Parse(time.RFC3339Nano, desc.Updated)
+		if err != nil {
+			return statusDoc{}, errors.Annotatef(err, "cannot parse status time")
+		}
+		doc.Updated = updated.UnixNano()
+	}
+	return doc, nil
+}
+
+func (i *importer) annotations(entity GlobalEntity, annotations map[string]string) error {
+	if len(annotations) == 0 {
+		return nil
+	}
+	return errors.Trace(i.st.SetAnnotations(entity, annotations))
+}
+
+func (i *importer) labels(entity GlobalEntity, labels map[string]string) error {
+	if len(labels) == 0 {
+		return nil
+	}
+	return errors.Trace(i.st.SetLabels(entity, labels))
+}
+
+func importAddresses(addrs []description.Address) []network.Address {
+	result := make([]network.Address, len(addrs))
+	for n, addr := range addrs {
+		result[n] = network.Address{
+			Value: addr.Value,
+			Type:  network.AddressType(addr.Type),
+			Scope: network.Scope(addr.Scope),
+		}
+	}
+	return result
+}
+
+// machineJobFromString returns the machine job with the given name.
+func machineJobFromString(name string) (MachineJob, error) {
+	for job, jobName := range jobNames {
+		if string(jobName) == name {
+			return job, nil
+		}
+	}
+	return 0, errors.NotValidf("machine job %q", name)
+}
+
+// storageKindFromString returns the storage kind with the given name.
+func storageKindFromString(name string) (StorageKind, error) {
+	for kind, kindName := range storageKindNames {
+		if kindName == name {
+			return kind, nil
+		}
+	}
+	return StorageKindUnknown, errors.NotValidf("storage kind %q", name)
+}
+
+// blockTypeFromString returns the block type with the given name.
+func blockTypeFromString(name string) (BlockType, error) {
+	for _, t := range AllTypes() {
+		if t.String() == name {
+			return t, nil
+		}
+	}
+	return 0, errors.NotValidf("block type %q", name)
+}
+
+// fromMap sets value from the map written by toMap. Nothing is set
+// if the map is nil.
+func fromMap(m map[string]interface{}, value interface{}) error {
+	if m == nil {
+		return nil
+	}
+	data, err := bson.Marshal(m)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(bson.Unmarshal(data, value))
+}
@@ -32,6 +32,7 @@ type envUserDoc struct {
 	CreatedBy   string    `bson:"createdby"`
 	DateCreated time.Time `bson:"datecreated"`
 	ReadOnly    bool      `bson:"readonly"`
+	Access      string    `bson:"access,omitempty"`
 }
 
 // envUserLastConnectionDoc is updated by the apiserver whenever the user
@@ -87,6 +88,46 @@ func (e *EnvironmentUser) ReadOnly() bool {
 	return e.doc.ReadOnly
 }
 
+// Access returns the access the user has to the environment. Users
+// added before access levels were introduced have viewer access if
+// they are read-only, and admin access otherwise.
+func (e *EnvironmentUser) Access() EnvUserAccess {
+	if e.doc.Access != "" {
+		return EnvUserAccess(e.doc.Access)
+	}
+	if e.doc.ReadOnly {
+		return EnvViewerAccess
+	}
+	return EnvAdminAccess
+}
+
+// SetAccess changes the access the user has to the environment.
+func (e *EnvironmentUser) SetAccess(access EnvUserAccess) error {
+	if err := access.Validate(); err != nil {
+		return errors.Trace(err)
+	}
+	readOnly := access == EnvViewerAccess
+	ops := []txn.Op{{
+		C:      envUsersC,
+		Id:     envUserID(e.UserTag()),
+		Assert: txn.DocExists,
+		Update: bson.D{{"$set", bson.D{
+			{"access", string(access)},
+			{"readonly", readOnly},
+		}}},
+	}}
+	err := e.st.runTransaction(ops)
+	if err == txn.ErrAborted {
+		err = errors.NotFoundf("environment user %q", e.UserName())
+	}
+	if err != nil {
+		return errors.Annotatef(err, "cannot set access for %q", e.UserName())
+	}
+	e.doc.Access = string(access)
+	e.doc.ReadOnly = readOnly
+	return nil
+}
+
 // LastConnection returns when this EnvironmentUser last connected through the API
 // in UTC. The resulting time will be nil if the user has never logged in.
 func (e *EnvironmentUser) LastConnection() (time.Time, error) {
@@ -168,6 +209,11 @@ type EnvUserSpec struct {
 	CreatedBy   names.UserTag
 	DisplayName string
 	ReadOnly    bool
+
+	// Access is the access the user is granted. If it is empty, the
+	// user has viewer access if ReadOnly is set, and admin access
+	// otherwise.
+	Access EnvUserAccess
 }
 
 // AddEnvironmentUser adds a new user to the database.
@@ -190,8 +236,19 @@ func (st *State) AddEnvironmentUser(spec EnvUserSpec) (*EnvironmentUser, error)
 		}
 	}
 
+	access := spec.Access
+	if access == "" {
+		access = EnvAdminAccess
+		if spec.ReadOnly {
+			access = EnvViewerAccess
+		}
+	}
+	if err := access.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+
 	envuuid := st.EnvironUUID()
-	op := createEnvUserOp(envuuid, spec.User, spec.CreatedBy, spec.DisplayName, spec.ReadOnly)
+	op := createEnvUserOp(envuuid, spec.User, spec.CreatedBy, spec.DisplayName, access)
 	err := st.runTransaction([]txn.Op{op})
 	if err == txn.ErrAborted {
 		err = errors.AlreadyExistsf("environment user %q", spec.User.Canonical())
@@ -203,20 +260,103 @@ func (st *State) AddEnvironmentUser(spec EnvUserSpec) (*EnvironmentUser, error)
 	return st.EnvironmentUser(spec.User)
 }
 
+// AddDirectoryEnvironmentUser adds a directory user to the environment
+// when they log in to it and their directory groups give them access.
+// A user who has been removed from the environment explicitly is not
+// added back. It returns an error satisfying errors.IsAlreadyExists if
+// the user has been added before, is already a user of the
+// environment, or is not a directory user.
+func (st *State) AddDirectoryEnvironmentUser(spec EnvUserSpec) (*EnvironmentUser, error) {
+	if !spec.User.IsLocal() {
+		return nil, errors.NotValidf("directory user %q", spec.User.Canonical())
+	}
+	if err := spec.Access.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	envuuid := st.EnvironUUID()
+	ops := []txn.Op{
+		{
+			C:  usersC,
+			Id: strings.ToLower(spec.User.Name()),
+			Assert: bson.D{
+				{"directory", true},
+				{"directoryenvironments", bson.D{{"$ne", envuuid}}},
+			},
+			Update: bson.D{{"$addToSet", bson.D{{"directoryenvironments", envuuid}}}},
+		},
+		createEnvUserOp(envuuid, spec.User, spec.CreatedBy, spec.DisplayName, spec.Access),
+	}
+	err := st.runTransaction(ops)
+	if err == txn.ErrAborted {
+		err = errors.AlreadyExistsf("environment user %q", spec.User.Canonical())
+	}
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return st.EnvironmentUser(spec.User)
+}
+
+// UpdateDirectoryEnvironmentUser changes the access of a directory user
+// who was added to the environment automatically to the access their
+// directory groups now give them. If access is empty, the user is
+// removed from the environment, and will be added to it again once
+// their groups give them access. It returns an error satisfying
+// errors.IsNotFound if the user is not a user of the environment that
+// was added automatically.
+func (st *State) UpdateDirectoryEnvironmentUser(user names.UserTag, access EnvUserAccess) error {
+	if access != "" {
+		if err := access.Validate(); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	envuuid := st.EnvironUUID()
+	userOp := txn.Op{
+		C:  usersC,
+		Id: strings.ToLower(user.Name()),
+		Assert: bson.D{
+			{"directory", true},
+			{"directoryenvironments", envuuid},
+		},
+	}
+	envUserOp := txn.Op{
+		C:      envUsersC,
+		Id:     envUserID(user),
+		Assert: txn.DocExists,
+	}
+	if access == "" {
+		userOp.Update = bson.D{{"$pull", bson.D{{"directoryenvironments", envuuid}}}}
+		envUserOp.Remove = true
+	} else {
+		envUserOp.Update = bson.D{{"$set", bson.D{
+			{"access", string(access)},
+			{"readonly", access == EnvViewerAccess},
+		}}}
+	}
+	err := st.runTransaction([]txn.Op{userOp, envUserOp})
+	if err == txn.ErrAborted {
+		err = errors.NotFoundf("directory environment user %q", user.Canonical())
+	}
+	if err != nil {
+		return errors.Annotatef(err, "cannot update access for %q", user.Canonical())
+	}
+	return nil
+}
+
 // envUserID returns the document id of the environment user
 func envUserID(user names.UserTag) string {
 	username := user.Canonical()
 	return strings.ToLower(username)
 }
 
-func createEnvUserOp(envuuid string, user, createdBy names.UserTag, displayName string, readOnly bool) txn.Op {
+func createEnvUserOp(envuuid string, user, createdBy names.UserTag, displayName string, access EnvUserAccess) txn.Op {
 	creatorname := createdBy.Canonical()
 	doc := &envUserDoc{
 		ID:          envUserID(user),
 		EnvUUID:     envuuid,
 		UserName:    user.Canonical(),
 		DisplayName: displayName,
-		ReadOnly:    readOnly,
+		ReadOnly:    access == EnvViewerAccess,
+		Access:      string(access),
 		CreatedBy:   creatorname,
 		DateCreated: nowToTheSecond(),
 	}
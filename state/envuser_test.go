@@ -75,6 +75,91 @@ func (s *EnvUserSuite) TestAddReadOnlyEnvironmentUser(c *gc.C) {
 	c.Assert(envUser.ReadOnly(), jc.IsTrue)
 }
 
+func (s *EnvUserSuite) TestAddEnvironmentUserAccess(c *gc.C) {
+	for i, test := range []struct {
+		readOnly bool
+		access   state.EnvUserAccess
+		expected state.EnvUserAccess
+	}{{
+		expected: state.EnvAdminAccess,
+	}, {
+		readOnly: true,
+		expected: state.EnvViewerAccess,
+	}, {
+		access:   state.EnvOperatorAccess,
+		expected: state.EnvOperatorAccess,
+	}, {
+		access:   state.EnvViewerAccess,
+		expected: state.EnvViewerAccess,
+	}} {
+		c.Logf("test %d", i)
+		envUser := s.Factory.MakeEnvUser(c, &factory.EnvUserParams{
+			ReadOnly: test.readOnly,
+			Access:   test.access,
+		})
+		c.Check(envUser.Access(), gc.Equals, test.expected)
+		c.Check(envUser.ReadOnly(), gc.Equals, test.expected == state.EnvViewerAccess)
+	}
+}
+
+func (s *EnvUserSuite) TestAddEnvironmentUserInvalidAccess(c *gc.C) {
+	user := s.Factory.MakeUser(c, &factory.UserParams{NoEnvUser: true})
+	_, err := s.State.AddEnvironmentUser(state.EnvUserSpec{
+		User:      user.UserTag(),
+		CreatedBy: s.Owner,
+		Access:    "superuser",
+	})
+	c.Assert(err, gc.ErrorMatches, `access level "superuser" not valid`)
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+}
+
+func (s *EnvUserSuite) TestSetAccess(c *gc.C) {
+	envUser := s.Factory.MakeEnvUser(c, nil)
+	err := envUser.SetAccess(state.EnvOperatorAccess)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(envUser.Access(), gc.Equals, state.EnvOperatorAccess)
+	c.Assert(envUser.ReadOnly(), jc.IsFalse)
+
+	envUser, err = s.State.EnvironmentUser(envUser.UserTag())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(envUser.Access(), gc.Equals, state.EnvOperatorAccess)
+
+	err = envUser.SetAccess(state.EnvViewerAccess)
+	c.Assert(err, jc.ErrorIsNil)
+	envUser, err = s.State.EnvironmentUser(envUser.UserTag())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(envUser.Access(), gc.Equals, state.EnvViewerAccess)
+	c.Assert(envUser.ReadOnly(), jc.IsTrue)
+
+	err = envUser.SetAccess("superuser")
+	c.Assert(err, gc.ErrorMatches, `access level "superuser" not valid`)
+}
+
+func (s *EnvUserSuite) TestSetAccessRemovedUser(c *gc.C) {
+	envUser := s.Factory.MakeEnvUser(c, nil)
+	err := s.State.RemoveEnvironmentUser(envUser.UserTag())
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = envUser.SetAccess(state.EnvDeployerAccess)
+	c.Assert(err, gc.ErrorMatches, `cannot set access for "[^"]*": environment user "[^"]*" not found`)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *EnvUserSuite) TestAccessIncludes(c *gc.C) {
+	c.Check(state.EnvAdminAccess.Includes(state.EnvOperatorAccess), jc.IsTrue)
+	c.Check(state.EnvOperatorAccess.Includes(state.EnvOperatorAccess), jc.IsTrue)
+	c.Check(state.EnvOperatorAccess.Includes(state.EnvDeployerAccess), jc.IsFalse)
+	c.Check(state.EnvViewerAccess.Includes(state.EnvAdminAccess), jc.IsFalse)
+	c.Check(state.EnvUserAccess("superuser").Includes(state.EnvViewerAccess), jc.IsFalse)
+	c.Check(state.EnvAdminAccess.Includes("superuser"), jc.IsFalse)
+
+	below, ok := state.EnvDeployerAccess.Below()
+	c.Check(ok, jc.IsTrue)
+	c.Check(below, gc.Equals, state.EnvOperatorAccess)
+	_, ok = state.EnvViewerAccess.Below()
+	c.Check(ok, jc.IsFalse)
+}
+
 func (s *EnvUserSuite) TestCaseUserNameVsId(c *gc.C) {
 	env, err := s.State.Environment()
 	c.Assert(err, jc.ErrorIsNil)
@@ -172,6 +257,75 @@ func (s *EnvUserSuite) TestRemoveEnvironmentUserFails(c *gc.C) {
 	c.Assert(err, jc.Satisfies, errors.IsNotFound)
 }
 
+func (s *EnvUserSuite) TestAddDirectoryEnvironmentUser(c *gc.C) {
+	user, err := s.State.AddDirectoryUser("bob", "Bob Brown", "admin")
+	c.Assert(err, jc.ErrorIsNil)
+	spec := state.EnvUserSpec{
+		User:        user.UserTag(),
+		CreatedBy:   s.Owner,
+		DisplayName: "Bob Brown",
+		Access:      state.EnvOperatorAccess,
+	}
+	envUser, err := s.State.AddDirectoryEnvironmentUser(spec)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(envUser.Access(), gc.Equals, state.EnvOperatorAccess)
+
+	// Once removed, the user is not added automatically again.
+	err = s.State.RemoveEnvironmentUser(user.UserTag())
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.AddDirectoryEnvironmentUser(spec)
+	c.Assert(err, jc.Satisfies, errors.IsAlreadyExists)
+	_, err = s.State.EnvironmentUser(user.UserTag())
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *EnvUserSuite) TestUpdateDirectoryEnvironmentUser(c *gc.C) {
+	user, err := s.State.AddDirectoryUser("bob", "Bob Brown", "admin")
+	c.Assert(err, jc.ErrorIsNil)
+	spec := state.EnvUserSpec{
+		User:      user.UserTag(),
+		CreatedBy: s.Owner,
+		Access:    state.EnvOperatorAccess,
+	}
+	_, err = s.State.AddDirectoryEnvironmentUser(spec)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.State.UpdateDirectoryEnvironmentUser(user.UserTag(), state.EnvViewerAccess)
+	c.Assert(err, jc.ErrorIsNil)
+	envUser, err := s.State.EnvironmentUser(user.UserTag())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(envUser.Access(), gc.Equals, state.EnvViewerAccess)
+
+	// Without access the user is removed, and may be added again.
+	err = s.State.UpdateDirectoryEnvironmentUser(user.UserTag(), "")
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.EnvironmentUser(user.UserTag())
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	_, err = s.State.AddDirectoryEnvironmentUser(spec)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *EnvUserSuite) TestUpdateDirectoryEnvironmentUserAddedExplicitly(c *gc.C) {
+	user, err := s.State.AddDirectoryUser("bob", "Bob Brown", "admin")
+	c.Assert(err, jc.ErrorIsNil)
+	s.Factory.MakeEnvUser(c, &factory.EnvUserParams{
+		User:   user.Name(),
+		Access: state.EnvOperatorAccess,
+	})
+	err = s.State.UpdateDirectoryEnvironmentUser(user.UserTag(), state.EnvAdminAccess)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *EnvUserSuite) TestAddDirectoryEnvironmentUserNotDirectoryUser(c *gc.C) {
+	user := s.Factory.MakeUser(c, &factory.UserParams{NoEnvUser: true})
+	_, err := s.State.AddDirectoryEnvironmentUser(state.EnvUserSpec{
+		User:      user.UserTag(),
+		CreatedBy: s.Owner,
+		Access:    state.EnvOperatorAccess,
+	})
+	c.Assert(err, jc.Satisfies, errors.IsAlreadyExists)
+}
+
 func (s *EnvUserSuite) TestUpdateLastConnection(c *gc.C) {
 	now := state.NowToTheSecond()
 	createdBy := s.Factory.MakeUser(c, &factory.UserParams{Name: "createdby"})
@@ -0,0 +1,80 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"github.com/juju/errors"
+)
+
+// EnvUserAccess is the access an environment user is granted. Each
+// access level includes everything the levels below it allow.
+type EnvUserAccess string
+
+const (
+	// EnvViewerAccess allows a user to look at the environment,
+	// but not to change it.
+	EnvViewerAccess EnvUserAccess = "viewer"
+
+	// EnvOperatorAccess additionally allows a user to keep the
+	// environment's workloads running: to run commands and actions,
+	// and to mark unit errors resolved.
+	EnvOperatorAccess EnvUserAccess = "operator"
+
+	// EnvDeployerAccess additionally allows a user to deploy,
+	// configure, relate, scale and remove services.
+	EnvDeployerAccess EnvUserAccess = "deployer"
+
+	// EnvAdminAccess allows a user to do anything with the
+	// environment, including changing who else has access to it.
+	EnvAdminAccess EnvUserAccess = "admin"
+)
+
+// envUserAccessLevels holds the valid access levels, from least to
+// most privileged.
+var envUserAccessLevels = []EnvUserAccess{
+	EnvViewerAccess,
+	EnvOperatorAccess,
+	EnvDeployerAccess,
+	EnvAdminAccess,
+}
+
+// AllEnvUserAccess returns the valid access levels, from least to most
+// privileged.
+func AllEnvUserAccess() []EnvUserAccess {
+	return append([]EnvUserAccess(nil), envUserAccessLevels...)
+}
+
+func (a EnvUserAccess) level() int {
+	for i, access := range envUserAccessLevels {
+		if a == access {
+			return i
+		}
+	}
+	return -1
+}
+
+// Validate returns an error if the access level is not known.
+func (a EnvUserAccess) Validate() error {
+	if a.level() < 0 {
+		return errors.NotValidf("access level %q", string(a))
+	}
+	return nil
+}
+
+// Includes returns whether the access level allows everything the
+// other access level does. An unknown access level includes nothing.
+func (a EnvUserAccess) Includes(other EnvUserAccess) bool {
+	level, otherLevel := a.level(), other.level()
+	return level >= 0 && otherLevel >= 0 && level >= otherLevel
+}
+
+// Below returns the access level immediately below a, and false if
+// there is none.
+func (a EnvUserAccess) Below() (EnvUserAccess, bool) {
+	level := a.level()
+	if level <= 0 {
+		return "", false
+	}
+	return envUserAccessLevels[level-1], true
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"path/filepath"
+	"sort"
 	"time"
 
 	"github.com/juju/errors"
@@ -49,6 +50,7 @@ var (
 	PortsGlobalKey         = portsGlobalKey
 	CurrentUpgradeId       = currentUpgradeId
 	NowToTheSecond         = nowToTheSecond
+	StatusNow              = &statusNow
 	PickAddress            = &pickAddress
 	AddVolumeOps           = (*State).addVolumeOps
 	CombineMeterStatus     = combineMeterStatus
@@ -454,3 +456,16 @@ func IsManagerMachineError(err error) bool {
 }
 
 var ActionNotificationIdToActionId = actionNotificationIdToActionId
+
+// ResourceStoragePaths returns the storage paths referred to by the
+// resource revisions held in the environment.
+func ResourceStoragePaths(c *gc.C, st *State) []string {
+	resources, closer := st.getCollection("resources")
+	defer closer()
+
+	var paths []string
+	err := resources.Find(nil).Distinct("storage-path", &paths)
+	c.Assert(err, jc.ErrorIsNil)
+	sort.Strings(paths)
+	return paths
+}
@@ -1137,6 +1137,13 @@ func (st *State) FilesystemStatus(tag names.FilesystemTag) (StatusInfo, error) {
 	return getStatus(st, filesystemGlobalKey(tag.Id()), "filesystem")
 }
 
+// FilesystemStatusHistory returns a slice of StatusInfo items matching
+// the filter and representing past statuses of the specified
+// filesystem, newest first.
+func (st *State) FilesystemStatusHistory(tag names.FilesystemTag, filter StatusHistoryFilter) ([]StatusInfo, error) {
+	return statusHistory(st, filesystemGlobalKey(tag.Id()), filter)
+}
+
 // SetFilesystemStatus sets the status of the specified filesystem.
 func (st *State) SetFilesystemStatus(tag names.FilesystemTag, status Status, info string, data map[string]interface{}) error {
 	switch status {
@@ -0,0 +1,142 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// maxHookRecordsPerUnit is the number of hook executions recorded for
+// each unit; older records are discarded.
+const maxHookRecordsPerUnit = 100
+
+// maxHookRecordStderrLines is the number of lines of a hook's
+// standard error kept in its record.
+const maxHookRecordStderrLines = 20
+
+// HookRecord describes one execution of a hook by a unit.
+type HookRecord struct {
+	// Hook is the name of the hook that was run.
+	Hook string
+
+	// RelationId is the id of the relation the hook ran for, or -1
+	// if it is not a relation hook.
+	RelationId int
+
+	// RemoteUnit is the name of the remote unit the hook ran for,
+	// if any.
+	RemoteUnit string
+
+	// Started is the time at which the hook started.
+	Started time.Time
+
+	// Duration is the time the hook took to run.
+	Duration time.Duration
+
+	// ExitStatus is the exit status of the hook process, or -1 if it
+	// could not be determined.
+	ExitStatus int
+
+	// Stderr holds the last lines the hook wrote to its standard
+	// error.
+	Stderr []string
+}
+
+// hookRecordDoc is the persistent form of a HookRecord.
+type hookRecordDoc struct {
+	EnvUUID    string   `bson:"env-uuid"`
+	GlobalKey  string   `bson:"globalkey"`
+	Hook       string   `bson:"hook"`
+	RelationId int      `bson:"relationid"`
+	RemoteUnit string   `bson:"remoteunit,omitempty"`
+	Started    int64    `bson:"started"`
+	Duration   int64    `bson:"duration"`
+	ExitStatus int      `bson:"exitstatus"`
+	Stderr     []string `bson:"stderr,omitempty"`
+}
+
+// AddHookRecord records the execution of a hook by the unit. Only the
+// most recent records are kept for each unit.
+func (u *Unit) AddHookRecord(record HookRecord) error {
+	if record.Hook == "" {
+		return errors.NotValidf("hook record without hook name")
+	}
+	stderr := record.Stderr
+	if len(stderr) > maxHookRecordStderrLines {
+		stderr = stderr[len(stderr)-maxHookRecordStderrLines:]
+	}
+	doc := &hookRecordDoc{
+		GlobalKey:  u.globalKey(),
+		Hook:       record.Hook,
+		RelationId: record.RelationId,
+		RemoteUnit: record.RemoteUnit,
+		Started:    record.Started.UnixNano(),
+		Duration:   int64(record.Duration),
+		ExitStatus: record.ExitStatus,
+		Stderr:     stderr,
+	}
+	records, closer := u.st.getCollection(hookRecordsC)
+	defer closer()
+	recordsW := records.Writeable()
+	if err := recordsW.Insert(doc); err != nil {
+		return errors.Annotatef(err, "cannot record %q hook of unit %q", record.Hook, u.Name())
+	}
+
+	// Discard the records beyond the most recent ones.
+	var oldest hookRecordDoc
+	err := records.Find(bson.D{{"globalkey", u.globalKey()}}).
+		Sort("-started").Skip(maxHookRecordsPerUnit - 1).One(&oldest)
+	if err == mgo.ErrNotFound {
+		return nil
+	} else if err != nil {
+		return errors.Trace(err)
+	}
+	_, err = recordsW.RemoveAll(bson.D{
+		{"globalkey", u.globalKey()},
+		{"started", bson.D{{"$lt", oldest.Started}}},
+	})
+	return errors.Trace(err)
+}
+
+// HookRecords returns the most recent hook executions of the unit,
+// oldest first. If size is positive, at most size records are
+// returned.
+func (u *Unit) HookRecords(size int) ([]HookRecord, error) {
+	records, closer := u.st.getCollection(hookRecordsC)
+	defer closer()
+
+	var docs []hookRecordDoc
+	q := records.Find(bson.D{{"globalkey", u.globalKey()}}).Sort("-started")
+	if size > 0 {
+		q = q.Limit(size)
+	}
+	if err := q.All(&docs); err != nil {
+		return nil, errors.Annotatef(err, "cannot get hook records of unit %q", u.Name())
+	}
+	results := make([]HookRecord, len(docs))
+	for i, doc := range docs {
+		results[len(docs)-i-1] = HookRecord{
+			Hook:       doc.Hook,
+			RelationId: doc.RelationId,
+			RemoteUnit: doc.RemoteUnit,
+			Started:    time.Unix(0, doc.Started).UTC(),
+			Duration:   time.Duration(doc.Duration),
+			ExitStatus: doc.ExitStatus,
+			Stderr:     doc.Stderr,
+		}
+	}
+	return results, nil
+}
+
+// eraseHookRecords removes the hook records of the unit.
+func (u *Unit) eraseHookRecords() error {
+	records, closer := u.st.getCollection(hookRecordsC)
+	defer closer()
+	_, err := records.Writeable().RemoveAll(bson.D{{"globalkey", u.globalKey()}})
+	return errors.Trace(err)
+}
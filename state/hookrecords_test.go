@@ -0,0 +1,103 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"fmt"
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state"
+)
+
+type HookRecordsSuite struct {
+	ConnSuite
+	unit *state.Unit
+}
+
+var _ = gc.Suite(&HookRecordsSuite{})
+
+func (s *HookRecordsSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	s.unit = s.Factory.MakeUnit(c, nil)
+}
+
+func (s *HookRecordsSuite) TestAddHookRecord(c *gc.C) {
+	started := time.Date(2015, 11, 2, 10, 0, 0, 0, time.UTC)
+	records := []state.HookRecord{{
+		Hook:       "install",
+		RelationId: -1,
+		Started:    started,
+		Duration:   90 * time.Second,
+		Stderr:     []string{"apt-get: done"},
+	}, {
+		Hook:       "db-relation-changed",
+		RelationId: 3,
+		RemoteUnit: "mysql/0",
+		Started:    started.Add(2 * time.Minute),
+		Duration:   time.Second,
+		ExitStatus: 1,
+	}}
+	for _, record := range records {
+		c.Assert(s.unit.AddHookRecord(record), jc.ErrorIsNil)
+	}
+
+	result, err := s.unit.HookRecords(0)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, records)
+
+	result, err = s.unit.HookRecords(1)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, records[1:])
+}
+
+func (s *HookRecordsSuite) TestAddHookRecordInvalid(c *gc.C) {
+	err := s.unit.AddHookRecord(state.HookRecord{})
+	c.Assert(err, gc.ErrorMatches, "hook record without hook name not valid")
+}
+
+func (s *HookRecordsSuite) TestAddHookRecordTrimsStderr(c *gc.C) {
+	var stderr []string
+	for i := 0; i < 25; i++ {
+		stderr = append(stderr, fmt.Sprintf("line %d", i))
+	}
+	err := s.unit.AddHookRecord(state.HookRecord{
+		Hook:    "config-changed",
+		Started: time.Now(),
+		Stderr:  stderr,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	result, err := s.unit.HookRecords(0)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, gc.HasLen, 1)
+	c.Assert(result[0].Stderr, jc.DeepEquals, stderr[5:])
+}
+
+func (s *HookRecordsSuite) TestAddHookRecordPrunes(c *gc.C) {
+	started := time.Date(2015, 11, 2, 10, 0, 0, 0, time.UTC)
+	for i := 0; i < 105; i++ {
+		err := s.unit.AddHookRecord(state.HookRecord{
+			Hook:    fmt.Sprintf("hook-%d", i),
+			Started: started.Add(time.Duration(i) * time.Second),
+		})
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	result, err := s.unit.HookRecords(0)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, gc.HasLen, 100)
+	c.Assert(result[0].Hook, gc.Equals, "hook-5")
+	c.Assert(result[99].Hook, gc.Equals, "hook-104")
+}
+
+func (s *HookRecordsSuite) TestDestroyUnitErasesHookRecords(c *gc.C) {
+	err := s.unit.AddHookRecord(state.HookRecord{Hook: "install", Started: time.Now()})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.unit.Destroy(), jc.ErrorIsNil)
+
+	result, err := s.unit.HookRecords(0)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, gc.HasLen, 0)
+}
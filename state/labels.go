@@ -0,0 +1,165 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+
+	"github.com/juju/juju/labels"
+)
+
+// labelsDoc holds the labels set on a machine, service or unit. Unlike
+// annotations, labels are understood by juju: they are used to select
+// entities with label selectors (see the labels package).
+type labelsDoc struct {
+	EnvUUID   string            `bson:"env-uuid"`
+	GlobalKey string            `bson:"globalkey"`
+	Tag       string            `bson:"tag"`
+	Labels    map[string]string `bson:"labels"`
+}
+
+// checkLabelled returns an error if labels cannot be set on the entity.
+func checkLabelled(entity GlobalEntity) error {
+	switch entity.Tag().(type) {
+	case names.MachineTag, names.ServiceTag, names.UnitTag:
+		return nil
+	}
+	return errors.NotSupportedf("labels on %s", names.ReadableString(entity.Tag()))
+}
+
+// SetLabels sets labels on a machine, service or unit. Labels with an
+// empty value are removed; all other labels must be valid according to
+// labels.Validate.
+func (st *State) SetLabels(entity GlobalEntity, newLabels map[string]string) (err error) {
+	defer errors.DeferredAnnotatef(&err, "cannot set labels on %s", names.ReadableString(entity.Tag()))
+	if err := checkLabelled(entity); err != nil {
+		return errors.Trace(err)
+	}
+	if len(newLabels) == 0 {
+		return nil
+	}
+	toSet := make(map[string]string)
+	toUnset := make(bson.M)
+	for key, value := range newLabels {
+		if err := labels.ValidateKey(key); err != nil {
+			return errors.Trace(err)
+		}
+		if value == "" {
+			toUnset["labels."+key] = 1
+			continue
+		}
+		if err := labels.ValidateValue(value); err != nil {
+			return errors.Trace(err)
+		}
+		toSet[key] = value
+	}
+
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		coll, closer := st.getCollection(labelsC)
+		defer closer()
+		count, err := coll.FindId(entity.globalKey()).Count()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if count == 0 {
+			if attempt != 0 {
+				return nil, errors.Errorf("%s no longer exists", names.ReadableString(entity.Tag()))
+			}
+			return insertLabelsOps(st, entity, toSet)
+		}
+		var update bson.D
+		if len(toSet) > 0 {
+			set := make(bson.M)
+			for key, value := range toSet {
+				set["labels."+key] = value
+			}
+			update = append(update, bson.DocElem{"$set", set})
+		}
+		if len(toUnset) > 0 {
+			update = append(update, bson.DocElem{"$unset", toUnset})
+		}
+		return []txn.Op{{
+			C:      labelsC,
+			Id:     st.docID(entity.globalKey()),
+			Assert: txn.DocExists,
+			Update: update,
+		}}, nil
+	}
+	return st.run(buildTxn)
+}
+
+// insertLabelsOps returns the operations required to insert the first
+// labels on an entity, asserting that the entity still exists.
+func insertLabelsOps(st *State, entity GlobalEntity, toSet map[string]string) ([]txn.Op, error) {
+	tag := entity.Tag()
+	coll, id, err := st.tagToCollectionAndId(tag)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return []txn.Op{{
+		C:      labelsC,
+		Id:     st.docID(entity.globalKey()),
+		Assert: txn.DocMissing,
+		Insert: &labelsDoc{
+			GlobalKey: entity.globalKey(),
+			Tag:       tag.String(),
+			Labels:    toSet,
+		},
+	}, {
+		C:      coll,
+		Id:     id,
+		Assert: txn.DocExists,
+	}}, nil
+}
+
+// Labels returns the labels set on an entity.
+func (st *State) Labels(entity GlobalEntity) (map[string]string, error) {
+	coll, closer := st.getCollection(labelsC)
+	defer closer()
+
+	var doc labelsDoc
+	err := coll.FindId(entity.globalKey()).One(&doc)
+	if err == mgo.ErrNotFound {
+		return make(map[string]string), nil
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if doc.Labels == nil {
+		doc.Labels = make(map[string]string)
+	}
+	return doc.Labels, nil
+}
+
+// AllLabels returns the labels of all labelled entities in the
+// environment, keyed by entity tag.
+func (st *State) AllLabels() (map[string]map[string]string, error) {
+	coll, closer := st.getCollection(labelsC)
+	defer closer()
+
+	var docs []labelsDoc
+	if err := coll.Find(nil).All(&docs); err != nil {
+		return nil, errors.Annotate(err, "cannot get labels")
+	}
+	result := make(map[string]map[string]string, len(docs))
+	for _, doc := range docs {
+		if len(doc.Labels) > 0 {
+			result[doc.Tag] = doc.Labels
+		}
+	}
+	return result, nil
+}
+
+// removeLabelsOp returns an operation to remove the labels of the
+// entity with the given global key.
+func removeLabelsOp(st *State, globalKey string) txn.Op {
+	return txn.Op{
+		C:      labelsC,
+		Id:     st.docID(globalKey),
+		Remove: true,
+	}
+}
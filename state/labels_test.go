@@ -0,0 +1,107 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state"
+)
+
+type LabelsSuite struct {
+	ConnSuite
+	machine *state.Machine
+}
+
+var _ = gc.Suite(&LabelsSuite{})
+
+func (s *LabelsSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+
+	var err error
+	s.machine, err = s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *LabelsSuite) TestSetLabels(c *gc.C) {
+	err := s.State.SetLabels(s.machine, map[string]string{"tier": "web", "env": "prod"})
+	c.Assert(err, jc.ErrorIsNil)
+	labels, err := s.State.Labels(s.machine)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(labels, jc.DeepEquals, map[string]string{"tier": "web", "env": "prod"})
+
+	// Existing labels are updated, and empty values remove labels.
+	err = s.State.SetLabels(s.machine, map[string]string{"tier": "db", "env": ""})
+	c.Assert(err, jc.ErrorIsNil)
+	labels, err = s.State.Labels(s.machine)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(labels, jc.DeepEquals, map[string]string{"tier": "db"})
+}
+
+func (s *LabelsSuite) TestLabelsNone(c *gc.C) {
+	labels, err := s.State.Labels(s.machine)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(labels, gc.HasLen, 0)
+}
+
+func (s *LabelsSuite) TestSetLabelsInvalid(c *gc.C) {
+	err := s.State.SetLabels(s.machine, map[string]string{"ti.er": "web"})
+	c.Check(err, gc.ErrorMatches, `cannot set labels on machine 0: label key "ti.er" not valid`)
+	err = s.State.SetLabels(s.machine, map[string]string{"tier": "we b"})
+	c.Check(err, gc.ErrorMatches, `cannot set labels on machine 0: label value "we b" not valid`)
+}
+
+func (s *LabelsSuite) TestSetLabelsNotSupported(c *gc.C) {
+	env, err := s.State.Environment()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.SetLabels(env, map[string]string{"tier": "web"})
+	c.Check(errors.Cause(err), jc.Satisfies, errors.IsNotSupported)
+}
+
+func (s *LabelsSuite) TestAllLabels(c *gc.C) {
+	svc := s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+	unit, err := svc.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.State.SetLabels(s.machine, map[string]string{"zone": "east"})
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.SetLabels(svc, map[string]string{"tier": "web"})
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.SetLabels(unit, map[string]string{"env": "canary"})
+	c.Assert(err, jc.ErrorIsNil)
+
+	all, err := s.State.AllLabels()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(all, jc.DeepEquals, map[string]map[string]string{
+		"machine-0":         {"zone": "east"},
+		"service-wordpress": {"tier": "web"},
+		"unit-wordpress-0":  {"env": "canary"},
+	})
+}
+
+func (s *LabelsSuite) TestLabelsRemovedWithMachine(c *gc.C) {
+	err := s.State.SetLabels(s.machine, map[string]string{"tier": "web"})
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.machine.EnsureDead()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.machine.Remove()
+	c.Assert(err, jc.ErrorIsNil)
+
+	all, err := s.State.AllLabels()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(all, gc.HasLen, 0)
+}
+
+func (s *LabelsSuite) TestSetLabelsRemovedEntity(c *gc.C) {
+	err := s.machine.EnsureDead()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.machine.Remove()
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.State.SetLabels(s.machine, map[string]string{"tier": "web"})
+	c.Check(err, gc.ErrorMatches, "cannot set labels on machine 0: machine 0 no longer exists")
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/juju/errors"
 	jujutxn "github.com/juju/txn"
+	"gopkg.in/mgo.v2/bson"
 	"gopkg.in/mgo.v2/txn"
 
 	"github.com/juju/juju/leadership"
@@ -34,6 +35,32 @@ func (st *State) LeadershipChecker() leadership.Checker {
 	return st.leadershipManager
 }
 
+// serviceLeaders returns the names of the units holding the leases on
+// the leadership of the environment's services, by service name. The
+// leases are read as the lease package stores them, as the leadership
+// manager reports leadership only to the units that hold it.
+func (st *State) serviceLeaders() (map[string]string, error) {
+	leases, closer := st.getCollection(leasesC)
+	defer closer()
+
+	var docs []struct {
+		Name   string `bson:"name"`
+		Holder string `bson:"holder"`
+	}
+	err := leases.Find(bson.D{
+		{"type", "lease"},
+		{"namespace", serviceLeadershipNamespace},
+	}).All(&docs)
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot read service leaders")
+	}
+	leaders := make(map[string]string)
+	for _, doc := range docs {
+		leaders[doc.Name] = doc.Holder
+	}
+	return leaders, nil
+}
+
 // HackLeadership stops the state's internal leadership manager to prevent it
 // from interfering with apiserver shutdown.
 func (st *State) HackLeadership() {
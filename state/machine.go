@@ -142,6 +142,39 @@ type machineDoc struct {
 	// Placement is the placement directive that should be used when provisioning
 	// an instance for the machine.
 	Placement string `bson:",omitempty"`
+
+	// ReplicaSetRole holds how a state server machine takes part
+	// in the mongo replica set.
+	ReplicaSetRole ReplicaSetRole `bson:"replicasetrole,omitempty"`
+}
+
+// ReplicaSetRole describes how a state server machine takes part in
+// the mongo replica set.
+type ReplicaSetRole string
+
+const (
+	// ReplicaSetVoter is the role of state servers that vote in
+	// replica set elections, and so may become primary.
+	ReplicaSetVoter ReplicaSetRole = ""
+
+	// ReplicaSetNonVoter is the role of state servers that
+	// replicate the database but never vote in elections or
+	// become primary, such as those at a disaster recovery site.
+	ReplicaSetNonVoter ReplicaSetRole = "non-voting"
+
+	// ReplicaSetHidden is the role of non-voting state servers
+	// that are also hidden from mongo clients, so that they only
+	// serve as backups.
+	ReplicaSetHidden ReplicaSetRole = "hidden"
+)
+
+// Validate returns an error if the role is not known.
+func (r ReplicaSetRole) Validate() error {
+	switch r {
+	case ReplicaSetVoter, ReplicaSetNonVoter, ReplicaSetHidden:
+		return nil
+	}
+	return errors.NotValidf("replica set role %q", string(r))
 }
 
 func newMachine(st *State, doc *machineDoc) *Machine {
@@ -186,6 +219,12 @@ func (m *Machine) globalKey() string {
 	return machineGlobalKey(m.doc.Id)
 }
 
+// machineInstanceGlobalKey returns the global database key identifying
+// the status history of the identified machine's instance.
+func machineInstanceGlobalKey(id string) string {
+	return machineGlobalKey(id) + "#instance"
+}
+
 // instanceData holds attributes relevant to a provisioned machine.
 type instanceData struct {
 	DocID      string      `bson:"_id"`
@@ -268,6 +307,13 @@ func (m *Machine) WantsVote() bool {
 	return wantsVote(m.doc.Jobs, m.doc.NoVote)
 }
 
+// ReplicaSetRole returns how the machine takes part in the mongo
+// replica set if it is a state server. State servers that are not
+// voters never want a vote.
+func (m *Machine) ReplicaSetRole() ReplicaSetRole {
+	return m.doc.ReplicaSetRole
+}
+
 // HasVote reports whether that machine is currently a voting
 // member of the replica set.
 func (m *Machine) HasVote() bool {
@@ -853,6 +899,7 @@ func (m *Machine) Remove() (err error) {
 		removeConstraintsOp(m.st, m.globalKey()),
 		removeRequestedNetworksOp(m.st, m.globalKey()),
 		annotationRemoveOp(m.st, m.globalKey()),
+		removeLabelsOp(m.st, m.globalKey()),
 		removeRebootDocOp(m.st, m.globalKey()),
 		removeMachineBlockDevicesOp(m.Id()),
 	}
@@ -995,6 +1042,10 @@ func (m *Machine) SetInstanceStatus(status string) (err error) {
 	}
 
 	if err = m.st.runTransaction(ops); err == nil {
+		probablyUpdateStatusHistory(m.st, machineInstanceGlobalKey(m.doc.Id), statusDoc{
+			Status:  Status(status),
+			Updated: statusNow().UnixNano(),
+		})
 		return nil
 	} else if err != txn.ErrAborted {
 		return err
@@ -1698,6 +1749,20 @@ func (m *Machine) SetStatus(status Status, info string, data map[string]interfac
 	})
 }
 
+// StatusHistory returns a slice of StatusInfo items matching the
+// filter and representing past statuses of the machine's agent,
+// newest first.
+func (m *Machine) StatusHistory(filter StatusHistoryFilter) ([]StatusInfo, error) {
+	return statusHistory(m.st, m.globalKey(), filter)
+}
+
+// InstanceStatusHistory returns a slice of StatusInfo items matching
+// the filter and representing past provider specific statuses of the
+// machine's instance, newest first.
+func (m *Machine) InstanceStatusHistory(filter StatusHistoryFilter) ([]StatusInfo, error) {
+	return statusHistory(m.st, machineInstanceGlobalKey(m.doc.Id), filter)
+}
+
 // Clean returns true if the machine does not have any deployed units or containers.
 func (m *Machine) Clean() bool {
 	return m.doc.Clean
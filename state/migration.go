@@ -0,0 +1,577 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	jujutxn "github.com/juju/txn"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+
+	"github.com/juju/juju/network"
+)
+
+// MigrationPhase identifies how far the migration of an environment
+// from one controller to another has progressed.
+type MigrationPhase string
+
+const (
+	// MigrationQuiesce is the first phase of a migration on the
+	// source controller: the environment is frozen by blocks so that
+	// it can be exported.
+	MigrationQuiesce MigrationPhase = "quiesce"
+
+	// MigrationImport is the phase in which the environment is being
+	// imported into the target controller. On the target, the
+	// imported environment is not active yet.
+	MigrationImport MigrationPhase = "import"
+
+	// MigrationRepoint is the phase in which the agents of the
+	// environment are being redirected to the target controller,
+	// where the environment is now active.
+	MigrationRepoint MigrationPhase = "repoint"
+
+	// MigrationDone means that the migration is complete.
+	MigrationDone MigrationPhase = "done"
+
+	// MigrationAborted means that the migration was abandoned.
+	MigrationAborted MigrationPhase = "aborted"
+)
+
+// IsTerminal returns whether the migration is over in the phase.
+func (p MigrationPhase) IsTerminal() bool {
+	return p == MigrationDone || p == MigrationAborted
+}
+
+// validMigrationTransitions holds the phases that a migration may
+// move to from each phase.
+var validMigrationTransitions = map[MigrationPhase][]MigrationPhase{
+	MigrationQuiesce: {MigrationImport, MigrationAborted},
+	MigrationImport:  {MigrationRepoint, MigrationAborted},
+	MigrationRepoint: {MigrationDone, MigrationAborted},
+}
+
+// CanTransitionTo returns whether a migration may move from the
+// phase to the next one.
+func (p MigrationPhase) CanTransitionTo(next MigrationPhase) bool {
+	for _, phase := range validMigrationTransitions[p] {
+		if phase == next {
+			return true
+		}
+	}
+	return false
+}
+
+// MigrationRole identifies the part a controller plays in the
+// migration of an environment.
+type MigrationRole string
+
+const (
+	// MigrationSource identifies the controller the environment is
+	// migrated from.
+	MigrationSource MigrationRole = "source"
+
+	// MigrationTarget identifies the controller the environment is
+	// migrated to.
+	MigrationTarget MigrationRole = "target"
+)
+
+// MigrationSpec describes the other controller taking part in a
+// migration: the target controller when starting a migration, and
+// the source controller when importing an environment.
+type MigrationSpec struct {
+	// Controller identifies the other controller's environment.
+	Controller names.EnvironTag
+
+	// HostPorts holds the API addresses of the other controller.
+	HostPorts [][]network.HostPort
+
+	// CACert holds the CA certificates of the other controller.
+	CACert string
+}
+
+// Validate returns an error if the spec is not valid.
+func (spec MigrationSpec) Validate() error {
+	if spec.Controller.Id() == "" {
+		return errors.NotValidf("empty controller")
+	}
+	if len(spec.HostPorts) == 0 {
+		return errors.NotValidf("empty API addresses")
+	}
+	if spec.CACert == "" {
+		return errors.NotValidf("empty CA certificate")
+	}
+	return nil
+}
+
+// migrationDoc records the progress of the migration of an
+// environment, from the point of view of one of the controllers
+// taking part in it.
+type migrationDoc struct {
+	EnvUUID      string         `bson:"_id"`
+	Role         MigrationRole  `bson:"role"`
+	Phase        MigrationPhase `bson:"phase"`
+	PhaseChanged time.Time      `bson:"phase-changed"`
+
+	// Controller, HostPorts and CACert describe the other controller.
+	Controller string       `bson:"controller"`
+	HostPorts  [][]hostPort `bson:"hostports"`
+	CACert     string       `bson:"ca-cert"`
+
+	// Blocks holds the blocks the migration switched on to freeze
+	// the source environment.
+	Blocks []BlockType `bson:"blocks,omitempty"`
+
+	// Environment holds, on the target controller, the document of
+	// the imported environment until it is activated.
+	Environment *environmentDoc `bson:"environment,omitempty"`
+
+	// Reason holds why the migration was aborted.
+	Reason string `bson:"reason,omitempty"`
+}
+
+// Migration represents the migration of an environment between
+// controllers, as recorded by one of them.
+type Migration struct {
+	st  *State
+	doc migrationDoc
+}
+
+// EnvironTag returns the tag of the migrating environment.
+func (m *Migration) EnvironTag() names.EnvironTag {
+	return names.NewEnvironTag(m.doc.EnvUUID)
+}
+
+// Role returns the part this controller plays in the migration.
+func (m *Migration) Role() MigrationRole {
+	return m.doc.Role
+}
+
+// Phase returns the current phase of the migration.
+func (m *Migration) Phase() MigrationPhase {
+	return m.doc.Phase
+}
+
+// PhaseChanged returns when the migration entered its current phase.
+func (m *Migration) PhaseChanged() time.Time {
+	return m.doc.PhaseChanged
+}
+
+// Controller returns the tag of the other controller taking part in
+// the migration.
+func (m *Migration) Controller() names.EnvironTag {
+	return names.NewEnvironTag(m.doc.Controller)
+}
+
+// HostPorts returns the API addresses of the other controller.
+func (m *Migration) HostPorts() [][]network.HostPort {
+	return networkHostsPorts(m.doc.HostPorts)
+}
+
+// CACert returns the CA certificates of the other controller.
+func (m *Migration) CACert() string {
+	return m.doc.CACert
+}
+
+// Reason returns why the migration was aborted.
+func (m *Migration) Reason() string {
+	return m.doc.Reason
+}
+
+// Refresh reloads the migration from state.
+func (m *Migration) Refresh() error {
+	migrations, closer := m.st.getCollection(migrationsC)
+	defer closer()
+
+	var doc migrationDoc
+	if err := migrations.FindId(m.doc.EnvUUID).One(&doc); err == mgo.ErrNotFound {
+		return errors.NotFoundf("migration of environment %q", m.doc.EnvUUID)
+	} else if err != nil {
+		return errors.Annotate(err, "cannot get migration")
+	}
+	m.doc = doc
+	return nil
+}
+
+// StartMigration freezes the environment and records that it is
+// being migrated to the controller described by target. The
+// environment must be a hosted environment that is alive and not
+// already migrating.
+func (st *State) StartMigration(target MigrationSpec) (*Migration, error) {
+	if err := target.Validate(); err != nil {
+		return nil, errors.Annotate(err, "cannot start migration")
+	}
+	if st.IsStateServer() {
+		return nil, errors.New("cannot migrate the controller environment")
+	}
+	if target.Controller == st.controllerTag {
+		return nil, errors.New("cannot migrate an environment to its own controller")
+	}
+	env, err := st.Environment()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if env.Life() != Alive {
+		return nil, errors.Errorf("cannot migrate environment: environment is no longer alive")
+	}
+
+	// Switch on the blocks that are not on yet; those that are stay
+	// on when the migration is over.
+	var blocks []BlockType
+	for _, t := range AllTypes() {
+		_, exists, err := st.GetBlockForType(t)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if exists {
+			continue
+		}
+		if err := st.SwitchBlockOn(t, "environment is being migrated"); err != nil {
+			return nil, errors.Annotate(err, "cannot freeze environment")
+		}
+		blocks = append(blocks, t)
+	}
+
+	doc := migrationDoc{
+		EnvUUID:      st.EnvironUUID(),
+		Role:         MigrationSource,
+		Phase:        MigrationQuiesce,
+		PhaseChanged: nowToTheSecond(),
+		Controller:   target.Controller.Id(),
+		HostPorts:    fromNetworkHostsPorts(target.HostPorts),
+		CACert:       target.CACert,
+		Blocks:       blocks,
+	}
+	if err := st.insertMigration(doc); err != nil {
+		st.switchMigrationBlocksOff(blocks)
+		return nil, errors.Annotate(err, "cannot start migration")
+	}
+	return &Migration{st: st, doc: doc}, nil
+}
+
+// insertMigration records a new migration, replacing a previous
+// migration of the environment that is over.
+func (st *State) insertMigration(doc migrationDoc) error {
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		existing, err := st.GetMigration(names.NewEnvironTag(doc.EnvUUID))
+		if errors.IsNotFound(err) {
+			return []txn.Op{{
+				C:      migrationsC,
+				Id:     doc.EnvUUID,
+				Assert: txn.DocMissing,
+				Insert: &doc,
+			}}, nil
+		} else if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if !existing.Phase().IsTerminal() {
+			return nil, errors.AlreadyExistsf("migration of environment %q", doc.EnvUUID)
+		}
+		return []txn.Op{{
+			C:      migrationsC,
+			Id:     doc.EnvUUID,
+			Assert: bson.D{{"phase", existing.Phase()}},
+			Remove: true,
+		}, {
+			C:      migrationsC,
+			Id:     doc.EnvUUID,
+			Assert: txn.DocMissing,
+			Insert: &doc,
+		}}, nil
+	}
+	return st.run(buildTxn)
+}
+
+// switchMigrationBlocksOff switches off the given blocks, logging
+// rather than returning errors so that it can be used while
+// unwinding.
+func (st *State) switchMigrationBlocksOff(blocks []BlockType) {
+	for _, t := range blocks {
+		_, exists, err := st.GetBlockForType(t)
+		if err == nil && exists {
+			err = st.SwitchBlockOff(t)
+		}
+		if err != nil {
+			logger.Errorf("cannot switch off %v block: %v", t, err)
+		}
+	}
+}
+
+// Migration returns the most recent migration of the environment.
+func (st *State) Migration() (*Migration, error) {
+	return st.GetMigration(st.environTag)
+}
+
+// GetMigration returns the most recent migration of the environment
+// with the given tag known to this controller.
+func (st *State) GetMigration(tag names.EnvironTag) (*Migration, error) {
+	migrations, closer := st.getCollection(migrationsC)
+	defer closer()
+
+	var doc migrationDoc
+	if err := migrations.FindId(tag.Id()).One(&doc); err == mgo.ErrNotFound {
+		return nil, errors.NotFoundf("migration of environment %q", tag.Id())
+	} else if err != nil {
+		return nil, errors.Annotate(err, "cannot get migration")
+	}
+	return &Migration{st: st, doc: doc}, nil
+}
+
+// WatchMigration returns a watcher that notifies of changes to the
+// migration of the environment.
+func (st *State) WatchMigration() NotifyWatcher {
+	return newEntityWatcher(st, migrationsC, st.EnvironUUID())
+}
+
+// MigrationRedirect describes the controller that the agents of a
+// migrating environment must connect to instead of this one.
+type MigrationRedirect struct {
+	HostPorts [][]network.HostPort
+	CACert    string
+}
+
+// MigrationRedirect returns where the agents of the environment must
+// connect to while it is migrating, or nil if they should connect to
+// this controller: the target controller once the source has started
+// repointing agents, and the source controller again if the target
+// aborted the migration.
+func (st *State) MigrationRedirect() (*MigrationRedirect, error) {
+	m, err := st.Migration()
+	if errors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+	redirect := false
+	switch m.Role() {
+	case MigrationSource:
+		redirect = m.Phase() == MigrationRepoint || m.Phase() == MigrationDone
+	case MigrationTarget:
+		redirect = m.Phase() == MigrationAborted
+	}
+	if !redirect {
+		return nil, nil
+	}
+	return &MigrationRedirect{
+		HostPorts: m.HostPorts(),
+		CACert:    m.CACert(),
+	}, nil
+}
+
+// SetPhase moves the migration to the next phase. Moving the
+// target's record of a migration to MigrationRepoint activates the
+// imported environment. Use Abort to abort a migration.
+func (m *Migration) SetPhase(phase MigrationPhase) error {
+	if phase == MigrationAborted {
+		return errors.NotValidf("setting phase %q; use Abort", phase)
+	}
+	if m.doc.Role == MigrationTarget && phase == MigrationRepoint {
+		if err := m.checkCharmArchives(); err != nil {
+			return errors.Annotate(err, "cannot activate environment")
+		}
+	}
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if attempt > 0 {
+			if err := m.Refresh(); err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
+		if m.doc.Phase == phase {
+			return nil, jujutxn.ErrNoOperations
+		}
+		if !m.doc.Phase.CanTransitionTo(phase) {
+			return nil, errors.Errorf("cannot move migration from phase %q to %q", m.doc.Phase, phase)
+		}
+		ops := []txn.Op{m.setPhaseOp(phase, "")}
+		if m.doc.Role == MigrationTarget && phase == MigrationRepoint {
+			activateOps, err := m.activateOps()
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			ops = append(ops, activateOps...)
+		}
+		return ops, nil
+	}
+	if err := m.st.run(buildTxn); err != nil {
+		return errors.Annotatef(err, "cannot set migration phase")
+	}
+	return m.Refresh()
+}
+
+// setPhaseOp returns the operation that moves the migration from its
+// current phase to the given one.
+func (m *Migration) setPhaseOp(phase MigrationPhase, reason string) txn.Op {
+	set := bson.D{
+		{"phase", phase},
+		{"phase-changed", nowToTheSecond()},
+	}
+	if reason != "" {
+		set = append(set, bson.DocElem{"reason", reason})
+	}
+	return txn.Op{
+		C:      migrationsC,
+		Id:     m.doc.EnvUUID,
+		Assert: bson.D{{"phase", m.doc.Phase}},
+		Update: bson.D{{"$set", set}},
+	}
+}
+
+// activateOps returns the operations that make an environment
+// imported into the target controller a hosted environment of the
+// controller.
+func (m *Migration) activateOps() ([]txn.Op, error) {
+	if m.doc.Environment == nil {
+		return nil, errors.Errorf("migration holds no environment to activate")
+	}
+	doc := *m.doc.Environment
+	doc.ServerUUID = m.st.controllerTag.Id()
+	doc.Life = Alive
+	owner := names.NewUserTag(doc.Owner)
+	return []txn.Op{{
+		C:      environmentsC,
+		Id:     doc.UUID,
+		Assert: txn.DocMissing,
+		Insert: &doc,
+	}, {
+		C:      migrationsC,
+		Id:     m.doc.EnvUUID,
+		Update: bson.D{{"$unset", bson.D{{"environment", nil}}}},
+	},
+		createUniqueOwnerEnvNameOp(owner, doc.Name),
+		incHostedEnvironCountOp(),
+	}, nil
+}
+
+// Abort abandons the migration, recording the reason.
+//
+// On the source controller, the blocks switched on by the migration
+// are switched off, and the agents are no longer redirected to the
+// target controller.
+//
+// On the target controller, an imported environment that was not
+// activated yet is removed straight away. An activated environment is
+// kept, and its agents redirected back to the source controller,
+// until RemoveEnvironment is called.
+func (m *Migration) Abort(reason string) error {
+	if m.doc.Phase.IsTerminal() {
+		return errors.Errorf("cannot abort migration in phase %q", m.doc.Phase)
+	}
+	wasImporting := m.doc.Role == MigrationTarget && m.doc.Phase == MigrationImport
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if attempt > 0 {
+			if err := m.Refresh(); err != nil {
+				return nil, errors.Trace(err)
+			}
+			if m.doc.Phase.IsTerminal() {
+				return nil, errors.Errorf("cannot abort migration in phase %q", m.doc.Phase)
+			}
+		}
+		return []txn.Op{m.setPhaseOp(MigrationAborted, reason)}, nil
+	}
+	if err := m.st.run(buildTxn); err != nil {
+		return errors.Annotate(err, "cannot abort migration")
+	}
+	if err := m.Refresh(); err != nil {
+		return errors.Trace(err)
+	}
+
+	switch {
+	case m.doc.Role == MigrationSource:
+		st, closer, err := m.envState()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		defer closer()
+		st.switchMigrationBlocksOff(m.doc.Blocks)
+	case wasImporting:
+		return errors.Annotate(m.st.removeImportedEnvironment(m.doc.EnvUUID), "cannot remove imported environment")
+	}
+	return nil
+}
+
+// RemoveEnvironment removes the migrated environment from this
+// controller, without touching its machines: from the source
+// controller once the migration is done, or from the target
+// controller once the migration is aborted.
+func (m *Migration) RemoveEnvironment() error {
+	switch {
+	case m.doc.Role == MigrationSource && m.doc.Phase == MigrationDone:
+	case m.doc.Role == MigrationTarget && m.doc.Phase == MigrationAborted:
+	default:
+		return errors.Errorf("cannot remove %s environment in migration phase %q", m.doc.Role, m.doc.Phase)
+	}
+	st, closer, err := m.envState()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer closer()
+
+	env, err := st.Environment()
+	if errors.IsNotFound(err) {
+		// Already removed, or never activated.
+		return nil
+	} else if err != nil {
+		return errors.Trace(err)
+	}
+	// The environment goes straight to Dead, so that its machines
+	// are left alone.
+	if err := env.setDeadNow(); err != nil {
+		return errors.Trace(err)
+	}
+	if err := st.removeEnvironmentBlobs(); err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(st.RemoveAllEnvironDocs())
+}
+
+// setDeadNow sets the environment Dead without destroying it first,
+// so that none of its entities are cleaned up.
+func (e *Environment) setDeadNow() error {
+	if e.Life() == Dead {
+		return nil
+	}
+	ops := []txn.Op{{
+		C:      environmentsC,
+		Id:     e.UUID(),
+		Assert: notDeadDoc,
+		Update: bson.D{{"$set", bson.D{
+			{"life", Dead},
+			{"time-of-death", nowToTheSecond()},
+		}}},
+	}}
+	if e.Life() == Alive {
+		ops = append(ops, decHostedEnvironCountOp())
+	}
+	if err := e.st.runTransaction(ops); err != nil {
+		return errors.Annotate(err, "cannot set environment dead")
+	}
+	return nil
+}
+
+// envState returns a State for the migrating environment, and a func
+// to release it.
+func (m *Migration) envState() (*State, func(), error) {
+	if m.st.EnvironUUID() == m.doc.EnvUUID {
+		return m.st, func() {}, nil
+	}
+	st, err := m.st.ForEnviron(m.EnvironTag())
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	return st, func() {
+		if err := st.Close(); err != nil {
+			logger.Errorf("cannot close state for environment %s: %v", m.doc.EnvUUID, err)
+		}
+	}, nil
+}
+
+// String returns a description of the migration.
+func (m *Migration) String() string {
+	return fmt.Sprintf("%s migration of environment %s (%s)", m.doc.Role, m.doc.EnvUUID, m.doc.Phase)
+}
@@ -0,0 +1,367 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/utils"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/charm.v6-unstable"
+
+	"github.com/juju/juju/network"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/description"
+	"github.com/juju/juju/state/storage"
+	"github.com/juju/juju/testcharms"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/testing/factory"
+)
+
+type migrationSuite struct {
+	ConnSuite
+	envState *state.State
+	target   state.MigrationSpec
+}
+
+var _ = gc.Suite(&migrationSuite{})
+
+func (s *migrationSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	s.envState = s.Factory.MakeEnvironment(c, nil)
+	s.AddCleanup(func(*gc.C) { s.envState.Close() })
+	s.target = state.MigrationSpec{
+		Controller: names.NewEnvironTag(utils.MustNewUUID().String()),
+		HostPorts:  [][]network.HostPort{network.NewHostPorts(17070, "10.0.0.1")},
+		CACert:     "target cert",
+	}
+}
+
+func (s *migrationSuite) assertBlocks(c *gc.C, st *state.State, expected ...state.BlockType) {
+	blocks, err := st.AllBlocks()
+	c.Assert(err, jc.ErrorIsNil)
+	var types []state.BlockType
+	for _, block := range blocks {
+		types = append(types, block.Type())
+	}
+	c.Assert(types, jc.SameContents, expected)
+}
+
+func (s *migrationSuite) TestStartMigrationFreezesEnvironment(c *gc.C) {
+	m, err := s.envState.StartMigration(s.target)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(m.EnvironTag(), gc.Equals, s.envState.EnvironTag())
+	c.Assert(m.Role(), gc.Equals, state.MigrationSource)
+	c.Assert(m.Phase(), gc.Equals, state.MigrationQuiesce)
+	c.Assert(m.Controller(), gc.Equals, s.target.Controller)
+	c.Assert(m.HostPorts(), jc.DeepEquals, s.target.HostPorts)
+	c.Assert(m.CACert(), gc.Equals, "target cert")
+	s.assertBlocks(c, s.envState, state.AllTypes()...)
+
+	m, err = s.envState.Migration()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(m.Phase(), gc.Equals, state.MigrationQuiesce)
+}
+
+func (s *migrationSuite) TestStartMigrationTwice(c *gc.C) {
+	_, err := s.envState.StartMigration(s.target)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.envState.StartMigration(s.target)
+	c.Assert(err, gc.ErrorMatches, `cannot start migration: migration of environment ".*" already exists`)
+}
+
+func (s *migrationSuite) TestStartMigrationControllerEnvironment(c *gc.C) {
+	_, err := s.State.StartMigration(s.target)
+	c.Assert(err, gc.ErrorMatches, "cannot migrate the controller environment")
+}
+
+func (s *migrationSuite) TestStartMigrationInvalidTarget(c *gc.C) {
+	s.target.HostPorts = nil
+	_, err := s.envState.StartMigration(s.target)
+	c.Assert(err, gc.ErrorMatches, "cannot start migration: empty API addresses not valid")
+	s.assertBlocks(c, s.envState)
+}
+
+func (s *migrationSuite) TestAbortKeepsExistingBlocks(c *gc.C) {
+	err := s.envState.SwitchBlockOn(state.ChangeBlock, "hands off")
+	c.Assert(err, jc.ErrorIsNil)
+	m, err := s.envState.StartMigration(s.target)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = m.Abort("changed my mind")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(m.Phase(), gc.Equals, state.MigrationAborted)
+	c.Assert(m.Reason(), gc.Equals, "changed my mind")
+	s.assertBlocks(c, s.envState, state.ChangeBlock)
+
+	// An aborted migration may be started again.
+	_, err = s.envState.StartMigration(s.target)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *migrationSuite) TestSetPhase(c *gc.C) {
+	m, err := s.envState.StartMigration(s.target)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = m.SetPhase(state.MigrationRepoint)
+	c.Assert(err, gc.ErrorMatches, `cannot set migration phase: cannot move migration from phase "quiesce" to "repoint"`)
+	err = m.SetPhase(state.MigrationAborted)
+	c.Assert(err, gc.ErrorMatches, `setting phase "aborted"; use Abort not valid`)
+
+	for _, phase := range []state.MigrationPhase{
+		state.MigrationImport,
+		state.MigrationRepoint,
+		state.MigrationDone,
+	} {
+		err := m.SetPhase(phase)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Assert(m.Phase(), gc.Equals, phase)
+	}
+	err = m.Abort("too late")
+	c.Assert(err, gc.ErrorMatches, `cannot abort migration in phase "done"`)
+}
+
+func (s *migrationSuite) TestMigrationRedirect(c *gc.C) {
+	redirect, err := s.envState.MigrationRedirect()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(redirect, gc.IsNil)
+
+	m, err := s.envState.StartMigration(s.target)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(m.SetPhase(state.MigrationImport), jc.ErrorIsNil)
+	redirect, err = s.envState.MigrationRedirect()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(redirect, gc.IsNil)
+
+	c.Assert(m.SetPhase(state.MigrationRepoint), jc.ErrorIsNil)
+	redirect, err = s.envState.MigrationRedirect()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(redirect, jc.DeepEquals, &state.MigrationRedirect{
+		HostPorts: s.target.HostPorts,
+		CACert:    "target cert",
+	})
+
+	c.Assert(m.Abort("agents stuck"), jc.ErrorIsNil)
+	redirect, err = s.envState.MigrationRedirect()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(redirect, gc.IsNil)
+}
+
+func (s *migrationSuite) TestWatchMigration(c *gc.C) {
+	w := s.envState.WatchMigration()
+	defer w.Stop()
+	s.envState.StartSync()
+	select {
+	case _, ok := <-w.Changes():
+		c.Assert(ok, jc.IsTrue)
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("no initial event")
+	}
+
+	_, err := s.envState.StartMigration(s.target)
+	c.Assert(err, jc.ErrorIsNil)
+	s.envState.StartSync()
+	select {
+	case _, ok := <-w.Changes():
+		c.Assert(ok, jc.IsTrue)
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("no event after starting migration")
+	}
+}
+
+func (s *migrationSuite) TestExportNotMigrating(c *gc.C) {
+	_, err := s.envState.ExportEnvironment()
+	c.Assert(err, gc.ErrorMatches, "cannot export environment that is not being migrated")
+}
+
+func (s *migrationSuite) TestImportExistingEnvironment(c *gc.C) {
+	_, err := s.envState.StartMigration(s.target)
+	c.Assert(err, jc.ErrorIsNil)
+	desc, err := s.envState.ExportEnvironment()
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = s.State.ImportEnvironment(desc, s.sourceSpec())
+	c.Assert(err, gc.ErrorMatches, `cannot import environment: environment ".*" already exists`)
+}
+
+func (s *migrationSuite) TestExportLeavesOutFreezeBlocks(c *gc.C) {
+	err := s.envState.SwitchBlockOn(state.RemoveBlock, "keep everything")
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.envState.StartMigration(s.target)
+	c.Assert(err, jc.ErrorIsNil)
+	desc, err := s.envState.ExportEnvironment()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(desc.Blocks, jc.DeepEquals, []description.Block{{
+		Type:    state.RemoveBlock.String(),
+		Message: "keep everything",
+	}})
+}
+
+func (s *migrationSuite) TestImportIntoHostedEnvironment(c *gc.C) {
+	_, err := s.envState.ImportEnvironment(nil, s.sourceSpec())
+	c.Assert(err, gc.ErrorMatches, "cannot import environment into a hosted environment")
+}
+
+func (s *migrationSuite) sourceSpec() state.MigrationSpec {
+	return state.MigrationSpec{
+		Controller: names.NewEnvironTag(utils.MustNewUUID().String()),
+		HostPorts:  [][]network.HostPort{network.NewHostPorts(17070, "10.0.0.2")},
+		CACert:     "source cert",
+	}
+}
+
+// migrateAway exports the hosted environment and the archives of its
+// charms, as the source controller of a migration would, and removes
+// it.
+func (s *migrationSuite) migrateAway(c *gc.C) (*description.Environment, map[string][]byte) {
+	m, err := s.envState.StartMigration(s.target)
+	c.Assert(err, jc.ErrorIsNil)
+	desc, err := s.envState.ExportEnvironment()
+	c.Assert(err, jc.ErrorIsNil)
+	archives := make(map[string][]byte)
+	for _, ch := range desc.Charms {
+		r, _, err := m.CharmArchive(charm.MustParseURL(ch.URL))
+		c.Assert(err, jc.ErrorIsNil)
+		archives[ch.URL], err = ioutil.ReadAll(r)
+		r.Close()
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	for _, phase := range []state.MigrationPhase{
+		state.MigrationImport,
+		state.MigrationRepoint,
+		state.MigrationDone,
+	} {
+		c.Assert(m.SetPhase(phase), jc.ErrorIsNil)
+	}
+	err = m.RemoveEnvironment()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.envState.EnsureEnvironmentRemoved(), jc.ErrorIsNil)
+	return desc, archives
+}
+
+// addStoredCharm adds a charm to the hosted environment, with an
+// archive holding the given content.
+func (s *migrationSuite) addStoredCharm(c *gc.C, content string) *state.Charm {
+	hash := sha256.Sum256([]byte(content))
+	curl := charm.MustParseURL("cs:quantal/wordpress-3")
+	ch, err := s.envState.AddCharm(testcharms.Repo.CharmDir("wordpress"), curl, "charms/wordpress", hex.EncodeToString(hash[:]))
+	c.Assert(err, jc.ErrorIsNil)
+	stor := storage.NewStorage(s.envState.EnvironUUID(), s.envState.MongoSession())
+	err = stor.Put("charms/wordpress", strings.NewReader(content), int64(len(content)))
+	c.Assert(err, jc.ErrorIsNil)
+	return ch
+}
+
+func (s *migrationSuite) TestExportImportRoundTrip(c *gc.C) {
+	f := factory.NewFactory(s.envState)
+	machine := f.MakeMachine(c, nil)
+	ch := s.addStoredCharm(c, "charm")
+	unit := f.MakeUnit(c, &factory.UnitParams{
+		Service: f.MakeService(c, &factory.ServiceParams{Charm: ch}),
+		Machine: machine,
+	})
+	err := s.envState.SwitchBlockOn(state.RemoveBlock, "keep everything")
+	c.Assert(err, jc.ErrorIsNil)
+	envTag := s.envState.EnvironTag()
+	c.Assert(state.HostedEnvironCount(c, s.State), gc.Equals, 1)
+
+	desc, archives := s.migrateAway(c)
+	c.Assert(archives, jc.DeepEquals, map[string][]byte{ch.URL().String(): []byte("charm")})
+	_, err = s.State.GetEnvironment(envTag)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	c.Assert(state.HostedEnvironCount(c, s.State), gc.Equals, 0)
+
+	m, err := s.State.ImportEnvironment(desc, s.sourceSpec())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(m.Role(), gc.Equals, state.MigrationTarget)
+	c.Assert(m.Phase(), gc.Equals, state.MigrationImport)
+
+	// The imported environment is not active until the agents are
+	// repointed to it, which needs the charm archives.
+	_, err = s.State.GetEnvironment(envTag)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	c.Assert(state.HostedEnvironCount(c, s.State), gc.Equals, 0)
+	err = m.SetPhase(state.MigrationRepoint)
+	c.Assert(err, gc.ErrorMatches, `cannot activate environment: archive of charm "cs:quantal/wordpress-3" not imported`)
+	err = m.AddCharmArchive(ch.URL(), strings.NewReader("other"), 5)
+	c.Assert(err, gc.ErrorMatches, `archive of charm "cs:quantal/wordpress-3" has SHA256 ".*", expected ".*"`)
+	err = m.AddCharmArchive(ch.URL(), bytes.NewReader(archives[ch.URL().String()]), 5)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(m.SetPhase(state.MigrationRepoint), jc.ErrorIsNil)
+	env, err := s.State.GetEnvironment(envTag)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(env.Life(), gc.Equals, state.Alive)
+	c.Assert(env.ServerUUID(), gc.Equals, s.State.EnvironUUID())
+	c.Assert(state.HostedEnvironCount(c, s.State), gc.Equals, 1)
+
+	st, err := s.State.ForEnviron(envTag)
+	c.Assert(err, jc.ErrorIsNil)
+	defer st.Close()
+	imported, err := st.Machine(machine.Id())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(imported.Jobs(), jc.DeepEquals, machine.Jobs())
+	importedUnit, err := st.Unit(unit.Name())
+	c.Assert(err, jc.ErrorIsNil)
+	machineId, err := importedUnit.AssignedMachineId()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(machineId, gc.Equals, machine.Id())
+	// The blocks that froze the environment are left behind.
+	s.assertBlocks(c, st, state.RemoveBlock)
+
+	r, _, err := storage.NewStorage(envTag.Id(), st.MongoSession()).Get("charms/wordpress")
+	c.Assert(err, jc.ErrorIsNil)
+	defer r.Close()
+	content, err := ioutil.ReadAll(r)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(content), gc.Equals, "charm")
+}
+
+func (s *migrationSuite) TestAbortImportRemovesDocuments(c *gc.C) {
+	f := factory.NewFactory(s.envState)
+	f.MakeMachine(c, nil)
+	envTag := s.envState.EnvironTag()
+	desc, _ := s.migrateAway(c)
+
+	m, err := s.State.ImportEnvironment(desc, s.sourceSpec())
+	c.Assert(err, jc.ErrorIsNil)
+	err = m.Abort("target is full")
+	c.Assert(err, jc.ErrorIsNil)
+
+	st, err := s.State.ForEnviron(envTag)
+	c.Assert(err, jc.ErrorIsNil)
+	defer st.Close()
+	c.Assert(st.EnsureEnvironmentRemoved(), jc.ErrorIsNil)
+
+	// An aborted target redirects agents back to the source.
+	redirect, err := st.MigrationRedirect()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(redirect.CACert, gc.Equals, "source cert")
+}
+
+func (s *migrationSuite) TestRemoveEnvironmentAfterAbortedActivation(c *gc.C) {
+	envTag := s.envState.EnvironTag()
+	desc, _ := s.migrateAway(c)
+
+	m, err := s.State.ImportEnvironment(desc, s.sourceSpec())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(m.SetPhase(state.MigrationRepoint), jc.ErrorIsNil)
+	err = m.RemoveEnvironment()
+	c.Assert(err, gc.ErrorMatches, `cannot remove target environment in migration phase "repoint"`)
+
+	c.Assert(m.Abort("agents stuck"), jc.ErrorIsNil)
+	_, err = s.State.GetEnvironment(envTag)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(m.RemoveEnvironment(), jc.ErrorIsNil)
+	_, err = s.State.GetEnvironment(envTag)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	c.Assert(state.HostedEnvironCount(c, s.State), gc.Equals, 0)
+}
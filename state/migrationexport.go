@@ -0,0 +1,334 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"gopkg.in/juju/charm.v6-unstable"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+
+	"github.com/juju/juju/state/description"
+	"github.com/juju/juju/state/storage"
+)
+
+// blobPathFields maps the collections whose documents refer to blob
+// storage to the field holding the storage path.
+var blobPathFields = map[string]string{
+	charmsC:     "storagepath",
+	"resources": "storage-path",
+}
+
+// ExportEnvironment returns the description of the environment, as
+// written by Export, to be imported into another controller with
+// ImportEnvironment. The environment must be frozen by a migration
+// started with StartMigration; the blocks that froze it are left out
+// of the description. The archives of the described charms are not
+// part of the description, and are read with Migration.CharmArchive.
+func (st *State) ExportEnvironment() (*description.Environment, error) {
+	m, err := st.Migration()
+	if errors.IsNotFound(err) {
+		return nil, errors.New("cannot export environment that is not being migrated")
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if !m.isExporting() {
+		return nil, errors.Errorf("cannot export environment in migration phase %q", m.Phase())
+	}
+	desc, err := st.Export()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	frozen := make(map[string]bool)
+	for _, t := range m.doc.Blocks {
+		frozen[t.String()] = true
+	}
+	var blocks []description.Block
+	for _, block := range desc.Blocks {
+		if !frozen[block.Type] {
+			blocks = append(blocks, block)
+		}
+	}
+	desc.Blocks = blocks
+	return desc, nil
+}
+
+// isExporting returns whether the environment may be read from this
+// controller to be imported into the target controller.
+func (m *Migration) isExporting() bool {
+	return m.doc.Role == MigrationSource && (m.doc.Phase == MigrationQuiesce || m.doc.Phase == MigrationImport)
+}
+
+// CharmArchive returns the archive of a charm of an environment that
+// is being migrated from this controller, and its size. The caller is
+// responsible for closing the archive.
+func (m *Migration) CharmArchive(curl *charm.URL) (io.ReadCloser, int64, error) {
+	if !m.isExporting() {
+		return nil, 0, errors.Errorf("cannot export charm archive in migration phase %q", m.doc.Phase)
+	}
+	st, closer, err := m.envState()
+	if err != nil {
+		return nil, 0, errors.Trace(err)
+	}
+	defer closer()
+	ch, err := st.Charm(curl)
+	if err != nil {
+		return nil, 0, errors.Trace(err)
+	}
+	if ch.StoragePath() == "" {
+		return nil, 0, errors.NotFoundf("archive of charm %q", curl)
+	}
+	stor := storage.NewStorage(m.doc.EnvUUID, st.MongoSession())
+	r, size, err := stor.Get(ch.StoragePath())
+	if err != nil {
+		return nil, 0, errors.Annotatef(err, "cannot read archive of charm %q", curl)
+	}
+	return r, size, nil
+}
+
+// AddCharmArchive stores the archive of a charm of an environment that
+// is being imported into this controller, where the charm is stored
+// at the path it was described with. The archive must match the
+// described SHA256 hash.
+func (m *Migration) AddCharmArchive(curl *charm.URL, r io.Reader, size int64) error {
+	if m.doc.Role != MigrationTarget || m.doc.Phase != MigrationImport {
+		return errors.Errorf("cannot import charm archive in migration phase %q", m.doc.Phase)
+	}
+	st, closer, err := m.envState()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer closer()
+	ch, err := st.Charm(curl)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if ch.StoragePath() == "" {
+		return errors.Errorf("charm %q has no archive", curl)
+	}
+	stor := storage.NewStorage(m.doc.EnvUUID, st.MongoSession())
+	hash := sha256.New()
+	if err := stor.Put(ch.StoragePath(), io.TeeReader(r, hash), size); err != nil {
+		return errors.Annotatef(err, "cannot store archive of charm %q", curl)
+	}
+	if got := hex.EncodeToString(hash.Sum(nil)); got != ch.BundleSha256() {
+		if err := stor.Remove(ch.StoragePath()); err != nil {
+			logger.Warningf("cannot remove %q: %v", ch.StoragePath(), err)
+		}
+		return errors.Errorf("archive of charm %q has SHA256 %q, expected %q", curl, got, ch.BundleSha256())
+	}
+	return nil
+}
+
+// checkCharmArchives returns an error if the archive of any charm of
+// an environment imported into this controller has not been stored.
+func (m *Migration) checkCharmArchives() error {
+	st, closer, err := m.envState()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer closer()
+	charms, err := st.AllCharms()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	stor := storage.NewStorage(m.doc.EnvUUID, st.MongoSession())
+	for _, ch := range charms {
+		if ch.StoragePath() == "" {
+			continue
+		}
+		r, _, err := stor.Get(ch.StoragePath())
+		if errors.IsNotFound(err) {
+			return errors.Errorf("archive of charm %q not imported", ch.URL())
+		} else if err != nil {
+			return errors.Trace(err)
+		}
+		r.Close()
+	}
+	return nil
+}
+
+// ImportEnvironment builds the environment described by desc, as
+// written by ExportEnvironment, in this controller, and records that
+// the environment is being migrated from the source controller. The
+// archives of the environment's charms must be added with
+// Migration.AddCharmArchive. The imported environment is not active
+// until its migration is moved to MigrationRepoint.
+func (st *State) ImportEnvironment(desc *description.Environment, source MigrationSpec) (_ *Migration, err error) {
+	if err := source.Validate(); err != nil {
+		return nil, errors.Annotate(err, "cannot import environment")
+	}
+	if !st.IsStateServer() {
+		return nil, errors.New("cannot import environment into a hosted environment")
+	}
+	if err := desc.Validate(); err != nil {
+		return nil, errors.Annotate(err, "cannot import environment")
+	}
+	if err := st.checkImportedEnvironment(desc); err != nil {
+		return nil, errors.Annotate(err, "cannot import environment")
+	}
+
+	doc := migrationDoc{
+		EnvUUID:      desc.UUID,
+		Role:         MigrationTarget,
+		Phase:        MigrationImport,
+		PhaseChanged: nowToTheSecond(),
+		Controller:   source.Controller.Id(),
+		HostPorts:    fromNetworkHostsPorts(source.HostPorts),
+		CACert:       source.CACert,
+	}
+	if err := st.insertMigration(doc); err != nil {
+		return nil, errors.Annotate(err, "cannot import environment")
+	}
+	m := &Migration{st: st, doc: doc}
+	defer func() {
+		if err == nil {
+			return
+		}
+		// Aborting the import removes what was imported so far.
+		if abortErr := m.Abort(err.Error()); abortErr != nil {
+			logger.Errorf("cannot abort import of environment %s: %v", desc.UUID, abortErr)
+		}
+	}()
+
+	env, envSt, err := st.Import(desc)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer envSt.Close()
+	// The environment is built alive, so that its entities can be
+	// added; it is then held by the migration until it is activated,
+	// so that this controller does not keep running its workers
+	// while the source controller still does.
+	if err := m.deactivate(env); err != nil {
+		if removeErr := envSt.removeImported(env); removeErr != nil {
+			logger.Errorf("cannot remove imported environment %s: %v", desc.UUID, removeErr)
+		}
+		return nil, errors.Annotate(err, "cannot import environment")
+	}
+	return m, nil
+}
+
+// deactivate moves the document of an imported environment from the
+// environments collection to the migration, until activateOps puts it
+// back.
+func (m *Migration) deactivate(env *Environment) error {
+	envDoc := env.doc
+	ops := []txn.Op{{
+		C:      environmentsC,
+		Id:     envDoc.UUID,
+		Assert: isEnvAliveDoc,
+		Remove: true,
+	}, {
+		C:      userenvnameC,
+		Id:     userEnvNameIndex(env.Owner().Canonical(), envDoc.Name),
+		Assert: txn.DocExists,
+		Remove: true,
+	}, {
+		C:      migrationsC,
+		Id:     m.doc.EnvUUID,
+		Assert: bson.D{{"phase", MigrationImport}},
+		Update: bson.D{{"$set", bson.D{{"environment", &envDoc}}}},
+	},
+		decHostedEnvironCountOp(),
+	}
+	if err := m.st.runTransaction(ops); err != nil {
+		return errors.Annotate(err, "cannot deactivate environment")
+	}
+	m.doc.Environment = &envDoc
+	return nil
+}
+
+// checkImportedEnvironment returns an error if the environment
+// described by desc cannot be imported into this controller.
+func (st *State) checkImportedEnvironment(desc *description.Environment) error {
+	environments, closer := st.getCollection(environmentsC)
+	defer closer()
+	if n, err := environments.FindId(desc.UUID).Count(); err != nil {
+		return errors.Trace(err)
+	} else if n > 0 {
+		return errors.AlreadyExistsf("environment %q", desc.UUID)
+	}
+
+	// The environment's local users must be known to this controller.
+	users := []string{desc.Owner}
+	for _, user := range desc.Users {
+		users = append(users, user.Name)
+	}
+	for _, name := range users {
+		if !names.IsValidUser(name) {
+			return errors.NotValidf("user %q", name)
+		}
+		user := names.NewUserTag(name)
+		if !user.IsLocal() {
+			continue
+		}
+		if _, err := st.User(user); errors.IsNotFound(err) {
+			return errors.NotFoundf("user %q on this controller", user.Canonical())
+		} else if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// removeImportedEnvironment removes the documents and stored blobs of
+// an environment whose import into this controller did not complete.
+func (st *State) removeImportedEnvironment(uuid string) error {
+	stor := storage.NewStorage(uuid, st.MongoSession())
+	for name, info := range st.database.Schema() {
+		if info.global {
+			continue
+		}
+		coll, closer := st.getRawCollection(name)
+		if field, ok := blobPathFields[name]; ok {
+			var docs []bson.M
+			if err := coll.Find(bson.D{{"env-uuid", uuid}}).Select(bson.D{{field, 1}}).All(&docs); err != nil {
+				closer()
+				return errors.Trace(err)
+			}
+			for _, doc := range docs {
+				if path, _ := doc[field].(string); path != "" {
+					if err := stor.Remove(path); err != nil && !errors.IsNotFound(err) {
+						logger.Warningf("cannot remove %q: %v", path, err)
+					}
+				}
+			}
+		}
+		_, err := coll.RemoveAll(bson.D{{"env-uuid", uuid}})
+		closer()
+		if err != nil {
+			return errors.Annotatef(err, "cannot remove %s", name)
+		}
+	}
+	return nil
+}
+
+// removeEnvironmentBlobs removes the charms and resources stored for
+// the environment.
+func (st *State) removeEnvironmentBlobs() error {
+	stor := storage.NewStorage(st.EnvironUUID(), st.MongoSession())
+	for name, field := range blobPathFields {
+		coll, closer := st.getCollection(name)
+		var docs []bson.M
+		err := coll.Find(nil).Select(bson.D{{field, 1}}).All(&docs)
+		closer()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		for _, doc := range docs {
+			if path, _ := doc[field].(string); path != "" {
+				if err := stor.Remove(path); err != nil && !errors.IsNotFound(err) {
+					logger.Warningf("cannot remove %q: %v", path, err)
+				}
+			}
+		}
+	}
+	return nil
+}
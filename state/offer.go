@@ -0,0 +1,186 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"gopkg.in/juju/charm.v6-unstable"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+)
+
+// Offer represents a service endpoint that the environment makes
+// available to other environments on the same controller. Users with
+// access to the offering environment may consume the offer from
+// their own environments, and relate their services to it.
+type Offer struct {
+	st  *State
+	doc offerDoc
+}
+
+// offerDoc represents the internal state of an offer in MongoDB.
+type offerDoc struct {
+	DocID       string `bson:"_id"`
+	Name        string `bson:"name"`
+	EnvUUID     string `bson:"env-uuid"`
+	ServiceName string `bson:"servicename"`
+	Endpoint    string `bson:"endpoint"`
+	Owner       string `bson:"owner"`
+}
+
+// Name returns the name under which the endpoint is offered.
+func (o *Offer) Name() string {
+	return o.doc.Name
+}
+
+// ServiceName returns the name of the offered service.
+func (o *Offer) ServiceName() string {
+	return o.doc.ServiceName
+}
+
+// EndpointName returns the name of the offered endpoint of the service.
+func (o *Offer) EndpointName() string {
+	return o.doc.Endpoint
+}
+
+// Owner returns the tag of the user that made the offer.
+func (o *Offer) Owner() names.UserTag {
+	return names.NewUserTag(o.doc.Owner)
+}
+
+// Endpoint returns the offered endpoint.
+func (o *Offer) Endpoint() (Endpoint, error) {
+	svc, err := o.st.Service(o.doc.ServiceName)
+	if err != nil {
+		return Endpoint{}, errors.Trace(err)
+	}
+	ep, err := svc.Endpoint(o.doc.Endpoint)
+	if err != nil {
+		return Endpoint{}, errors.Trace(err)
+	}
+	return ep, nil
+}
+
+// Remove withdraws the offer. Existing relations to the offered
+// service from other environments are not affected.
+func (o *Offer) Remove() error {
+	ops := []txn.Op{{
+		C:      offersC,
+		Id:     o.doc.DocID,
+		Remove: true,
+	}}
+	if err := o.st.runTransaction(ops); err != nil {
+		return errors.Annotatef(err, "cannot remove offer %q", o.doc.Name)
+	}
+	return nil
+}
+
+// AddOffer offers the named endpoint of a service to other environments
+// under the given name.
+func (st *State) AddOffer(name, serviceName, endpointName string, owner names.UserTag) (_ *Offer, err error) {
+	defer errors.DeferredAnnotatef(&err, "cannot offer %s:%s", serviceName, endpointName)
+	if !names.IsValidService(name) {
+		return nil, errors.NotValidf("offer name %q", name)
+	}
+	svc, err := st.Service(serviceName)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if svc.Life() != Alive {
+		return nil, errors.Errorf("service is not alive")
+	}
+	ep, err := svc.Endpoint(endpointName)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if ep.Role == charm.RolePeer {
+		return nil, errors.Errorf("cannot offer peer relation %q", endpointName)
+	}
+	if ep.Scope == charm.ScopeContainer {
+		return nil, errors.Errorf("cannot offer container-scoped relation %q", endpointName)
+	}
+	doc := offerDoc{
+		DocID:       st.docID(name),
+		Name:        name,
+		EnvUUID:     st.EnvironUUID(),
+		ServiceName: serviceName,
+		Endpoint:    endpointName,
+		Owner:       owner.Canonical(),
+	}
+	ops := []txn.Op{{
+		C:      servicesC,
+		Id:     svc.doc.DocID,
+		Assert: isAliveDoc,
+	}, {
+		C:      offersC,
+		Id:     doc.DocID,
+		Assert: txn.DocMissing,
+		Insert: &doc,
+	}}
+	if err := st.runTransaction(ops); err == txn.ErrAborted {
+		if _, err := st.Offer(name); err == nil {
+			return nil, errors.AlreadyExistsf("offer %q", name)
+		}
+		return nil, errors.Errorf("service is not alive")
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &Offer{st: st, doc: doc}, nil
+}
+
+// Offer returns the offer with the given name.
+func (st *State) Offer(name string) (*Offer, error) {
+	offers, closer := st.getCollection(offersC)
+	defer closer()
+
+	var doc offerDoc
+	err := offers.FindId(name).One(&doc)
+	if err == mgo.ErrNotFound {
+		return nil, errors.NotFoundf("offer %q", name)
+	} else if err != nil {
+		return nil, errors.Annotatef(err, "cannot get offer %q", name)
+	}
+	return &Offer{st: st, doc: doc}, nil
+}
+
+// AllOffers returns all the offers made by the environment.
+func (st *State) AllOffers() ([]*Offer, error) {
+	offers, closer := st.getCollection(offersC)
+	defer closer()
+
+	var docs []offerDoc
+	if err := offers.Find(nil).Sort("name").All(&docs); err != nil {
+		return nil, errors.Annotate(err, "cannot get offers")
+	}
+	result := make([]*Offer, len(docs))
+	for i, doc := range docs {
+		result[i] = &Offer{st: st, doc: doc}
+	}
+	return result, nil
+}
+
+// removeOffersOps returns the operations that withdraw the offers of
+// the service, which is being removed.
+func (s *Service) removeOffersOps() []txn.Op {
+	offers, closer := s.st.getCollection(offersC)
+	defer closer()
+
+	var docs []offerDoc
+	err := offers.Find(bson.D{{"servicename", s.doc.Name}}).Select(bson.D{{"_id", 1}}).All(&docs)
+	if err != nil {
+		logger.Warningf("cannot get offers of service %q: %v", s.doc.Name, err)
+		return nil
+	}
+	ops := make([]txn.Op, len(docs))
+	for i, doc := range docs {
+		ops[i] = txn.Op{
+			C:      offersC,
+			Id:     doc.DocID,
+			Remove: true,
+		}
+	}
+	return ops
+}
@@ -0,0 +1,88 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state"
+)
+
+type OfferSuite struct {
+	ConnSuite
+	mysql *state.Service
+}
+
+var _ = gc.Suite(&OfferSuite{})
+
+func (s *OfferSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	s.mysql = s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
+}
+
+func (s *OfferSuite) TestAddOffer(c *gc.C) {
+	offer, err := s.State.AddOffer("shared-db", "mysql", "server", s.Owner)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(offer.Name(), gc.Equals, "shared-db")
+	c.Assert(offer.ServiceName(), gc.Equals, "mysql")
+	c.Assert(offer.EndpointName(), gc.Equals, "server")
+	c.Assert(offer.Owner(), gc.Equals, s.Owner)
+
+	offer, err = s.State.Offer("shared-db")
+	c.Assert(err, jc.ErrorIsNil)
+	ep, err := offer.Endpoint()
+	c.Assert(err, jc.ErrorIsNil)
+	expect, err := s.mysql.Endpoint("server")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ep, gc.DeepEquals, expect)
+}
+
+func (s *OfferSuite) TestAddOfferDuplicate(c *gc.C) {
+	_, err := s.State.AddOffer("shared-db", "mysql", "server", s.Owner)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.AddOffer("shared-db", "mysql", "server", s.Owner)
+	c.Assert(err, gc.ErrorMatches, `cannot offer mysql:server: offer "shared-db" already exists`)
+}
+
+func (s *OfferSuite) TestAddOfferInvalid(c *gc.C) {
+	_, err := s.State.AddOffer("shared-db", "mysql", "cache", s.Owner)
+	c.Assert(err, gc.ErrorMatches, `cannot offer mysql:cache: service "mysql" has no "cache" relation`)
+	_, err = s.State.AddOffer("shared-db", "nosuch", "server", s.Owner)
+	c.Assert(err, gc.ErrorMatches, `cannot offer nosuch:server: service "nosuch" not found`)
+	_, err = s.State.AddOffer("Shared_DB", "mysql", "server", s.Owner)
+	c.Assert(err, gc.ErrorMatches, `cannot offer mysql:server: offer name "Shared_DB" not valid`)
+
+	riak := s.AddTestingService(c, "riak", s.AddTestingCharm(c, "riak"))
+	_, err = s.State.AddOffer("ring", riak.Name(), "ring", s.Owner)
+	c.Assert(err, gc.ErrorMatches, `cannot offer riak:ring: cannot offer peer relation "ring"`)
+}
+
+func (s *OfferSuite) TestAllOffersAndRemove(c *gc.C) {
+	_, err := s.State.AddOffer("db2", "mysql", "server", s.Owner)
+	c.Assert(err, jc.ErrorIsNil)
+	offer, err := s.State.AddOffer("db1", "mysql", "server", s.Owner)
+	c.Assert(err, jc.ErrorIsNil)
+
+	offers, err := s.State.AllOffers()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(offers, gc.HasLen, 2)
+	c.Assert(offers[0].Name(), gc.Equals, "db1")
+	c.Assert(offers[1].Name(), gc.Equals, "db2")
+
+	err = offer.Remove()
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.Offer("db1")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *OfferSuite) TestRemovingServiceRemovesOffers(c *gc.C) {
+	_, err := s.State.AddOffer("shared-db", "mysql", "server", s.Owner)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.mysql.Destroy()
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.Offer("shared-db")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
@@ -6,6 +6,7 @@ package state
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/juju/errors"
 	"github.com/juju/names"
@@ -186,7 +187,7 @@ func (st *State) envSetupOps(cfg *config.Config, envUUID, serverUUID string, own
 	if serverUUID == "" {
 		serverUUID = envUUID
 	}
-	envUserOp := createEnvUserOp(envUUID, owner, owner, owner.Name(), false)
+	envUserOp := createEnvUserOp(envUUID, owner, owner, owner.Name(), EnvAdminAccess)
 	ops := []txn.Op{
 		createConstraintsOp(st, environGlobalKey, constraints.Value{}),
 		createSettingsOp(environGlobalKey, cfg.AllAttrs()),
@@ -258,9 +259,16 @@ func (st *State) MongoConnectionInfo() *mongo.MongoInfo {
 	return st.mongoInfo
 }
 
-// CACert returns the certificate used to validate the state connection.
+// CACert returns the certificates used to validate the state
+// connection. While the controller's CA is being rotated, it holds
+// both the current and the new CA certificates.
 func (st *State) CACert() string {
-	return st.mongoInfo.CACert
+	certs, err := st.ControllerCertificates()
+	if err != nil {
+		logger.Warningf("cannot get controller certificates: %v", err)
+		return st.mongoInfo.CACert
+	}
+	return certs.TrustedCACerts(time.Now())
 }
 
 func (st *State) Close() (err error) {
@@ -0,0 +1,110 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"time"
+	"unicode"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/environs/config"
+)
+
+// PasswordPolicy holds the rules that the passwords of local users
+// must follow, and how failed logins are treated. It is configured in
+// the state server environment and applies to all environments.
+type PasswordPolicy struct {
+	// MinLength holds the minimum length of passwords.
+	MinLength int
+
+	// MinCharacterClasses holds the minimum number of character
+	// classes (lower case letters, upper case letters, digits and
+	// other characters) that passwords must contain.
+	MinCharacterClasses int
+
+	// MaxAge holds how long passwords last before they must be
+	// changed. Zero means passwords never expire.
+	MaxAge time.Duration
+
+	// LockoutAttempts holds the number of failed logins within
+	// LockoutWindow after which a user is locked out. Zero means
+	// users are never locked out.
+	LockoutAttempts int
+
+	// LockoutWindow holds the window within which failed logins
+	// count towards locking a user out.
+	LockoutWindow time.Duration
+}
+
+// NewPasswordPolicy returns the password policy held in the given
+// environment configuration.
+func NewPasswordPolicy(cfg *config.Config) PasswordPolicy {
+	return PasswordPolicy{
+		MinLength:           cfg.PasswordMinLength(),
+		MinCharacterClasses: cfg.PasswordMinCharacterClasses(),
+		MaxAge:              cfg.PasswordMaxAge(),
+		LockoutAttempts:     cfg.LoginLockoutAttempts(),
+		LockoutWindow:       cfg.LoginLockoutWindow(),
+	}
+}
+
+// Check returns an error satisfying errors.IsNotValid if the given
+// password does not follow the policy.
+func (p PasswordPolicy) Check(password string) error {
+	if n := len([]rune(password)); n < p.MinLength {
+		return errors.NotValidf("password shorter than %d characters", p.MinLength)
+	}
+	if n := characterClasses(password); n < p.MinCharacterClasses {
+		return errors.NotValidf(
+			"password with fewer than %d of lower case letters, upper case letters, digits and other characters",
+			p.MinCharacterClasses,
+		)
+	}
+	return nil
+}
+
+// characterClasses returns how many of lower case letters, upper case
+// letters, digits and other characters the given password contains.
+func characterClasses(password string) int {
+	var lower, upper, digit, other bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			other = true
+		}
+	}
+	n := 0
+	for _, found := range []bool{lower, upper, digit, other} {
+		if found {
+			n++
+		}
+	}
+	return n
+}
+
+// PasswordPolicy returns the password policy configured in the state
+// server environment.
+func (st *State) PasswordPolicy() (PasswordPolicy, error) {
+	ssState := st
+	if !st.IsStateServer() {
+		var err error
+		ssState, err = st.ForEnviron(st.controllerTag)
+		if err != nil {
+			return PasswordPolicy{}, errors.Trace(err)
+		}
+		defer ssState.Close()
+	}
+	cfg, err := ssState.EnvironConfig()
+	if err != nil {
+		return PasswordPolicy{}, errors.Annotate(err, "cannot get password policy")
+	}
+	return NewPasswordPolicy(cfg), nil
+}
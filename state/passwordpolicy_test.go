@@ -0,0 +1,85 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state"
+)
+
+type PasswordPolicySuite struct {
+	ConnSuite
+}
+
+var _ = gc.Suite(&PasswordPolicySuite{})
+
+func (s *PasswordPolicySuite) TestCheck(c *gc.C) {
+	policy := state.PasswordPolicy{
+		MinLength:           8,
+		MinCharacterClasses: 3,
+	}
+	for i, test := range []struct {
+		password string
+		err      string
+	}{{
+		password: "Passw0rd",
+	}, {
+		password: "pass word 1",
+	}, {
+		password: "Pa55",
+		err:      "password shorter than 8 characters not valid",
+	}, {
+		password: "passwords",
+		err:      "password with fewer than 3 of .* not valid",
+	}, {
+		password: "Passwords",
+		err:      "password with fewer than 3 of .* not valid",
+	}} {
+		c.Logf("test %d: %q", i, test.password)
+		err := policy.Check(test.password)
+		if test.err == "" {
+			c.Check(err, jc.ErrorIsNil)
+		} else {
+			c.Check(err, gc.ErrorMatches, test.err)
+			c.Check(err, jc.Satisfies, errors.IsNotValid)
+		}
+	}
+}
+
+func (s *PasswordPolicySuite) TestEmptyPolicyAllowsAnything(c *gc.C) {
+	var policy state.PasswordPolicy
+	c.Assert(policy.Check(""), jc.ErrorIsNil)
+}
+
+func (s *PasswordPolicySuite) TestPasswordPolicy(c *gc.C) {
+	err := s.State.UpdateEnvironConfig(map[string]interface{}{
+		"password-min-length":    10,
+		"password-max-age":       "720h",
+		"login-lockout-attempts": 5,
+	}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	expected := state.PasswordPolicy{
+		MinLength:       10,
+		MaxAge:          720 * time.Hour,
+		LockoutAttempts: 5,
+		LockoutWindow:   15 * time.Minute,
+	}
+	policy, err := s.State.PasswordPolicy()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(policy, jc.DeepEquals, expected)
+
+	// Hosted environments use the policy of the state server
+	// environment.
+	st := s.Factory.MakeEnvironment(c, nil)
+	defer st.Close()
+	policy, err = st.PasswordPolicy()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(policy, jc.DeepEquals, expected)
+}
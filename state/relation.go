@@ -178,10 +178,18 @@ func (r *Relation) removeOps(ignoreService string, departingUnit *Unit) ([]txn.O
 		relOp.Assert = bson.D{{"life", Alive}, {"unitcount", 0}}
 	}
 	ops := []txn.Op{relOp}
+	hasRemote := false
 	for _, ep := range r.doc.Endpoints {
 		if ep.ServiceName == ignoreService {
 			continue
 		}
+		if remote, err := isRemoteService(r.st, ep.ServiceName); err != nil {
+			return nil, err
+		} else if remote {
+			ops = append(ops, remoteRelationRemovedOp(r.st, ep.ServiceName))
+			hasRemote = true
+			continue
+		}
 		var asserts bson.D
 		hasRelation := bson.D{{"relationcount", bson.D{{"$gt", 0}}}}
 		if departingUnit == nil {
@@ -203,7 +211,11 @@ func (r *Relation) removeOps(ignoreService string, departingUnit *Unit) ([]txn.O
 			hasLastRef := bson.D{{"life", Dying}, {"unitcount", 0}, {"relationcount", 1}}
 			removable := append(bson.D{{"_id", ep.ServiceName}}, hasLastRef...)
 			if err := services.Find(removable).One(&svc.doc); err == nil {
-				ops = append(ops, svc.removeOps(hasLastRef)...)
+				removeOps, err := svc.removeOps(hasLastRef)
+				if err != nil {
+					return nil, errors.Trace(err)
+				}
+				ops = append(ops, removeOps...)
 				continue
 			} else if err != mgo.ErrNotFound {
 				return nil, err
@@ -223,7 +235,12 @@ func (r *Relation) removeOps(ignoreService string, departingUnit *Unit) ([]txn.O
 			Update: bson.D{{"$inc", bson.D{{"relationcount", -1}}}},
 		})
 	}
-	cleanupOp := r.st.newCleanupOp(cleanupRelationSettings, fmt.Sprintf("r#%d#", r.Id()))
+	prefix := fmt.Sprintf("r#%d#", r.Id())
+	if hasRemote {
+		// Remote units do not leave scope before the relation is removed.
+		ops = append(ops, r.st.newCleanupOp(cleanupRemoteRelationScopes, prefix))
+	}
+	cleanupOp := r.st.newCleanupOp(cleanupRelationSettings, prefix)
 	return append(ops, cleanupOp), nil
 }
 
@@ -0,0 +1,482 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	jujutxn "github.com/juju/txn"
+	"gopkg.in/juju/charm.v6-unstable"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+)
+
+// RemoteService represents a service in another environment on the same
+// controller that takes part in relations in this environment. It has no
+// charm or units of its own: its endpoints are those of the service it
+// stands for, and the units of that service appear in its relations as
+// units of the remote service.
+//
+// A remote service is either added when a user consumes an offer from
+// another environment, or is a proxy added in the offering environment
+// for a service that is related to the offer.
+type RemoteService struct {
+	st  *State
+	doc remoteServiceDoc
+}
+
+// remoteServiceDoc represents the internal state of a remote service in
+// MongoDB.
+type remoteServiceDoc struct {
+	DocID             string           `bson:"_id"`
+	Name              string           `bson:"name"`
+	EnvUUID           string           `bson:"env-uuid"`
+	SourceEnvUUID     string           `bson:"sourceenvuuid"`
+	SourceServiceName string           `bson:"sourceservicename"`
+	OfferName         string           `bson:"offername,omitempty"`
+	ConsumedBy        string           `bson:"consumedby,omitempty"`
+	Endpoints         []charm.Relation `bson:"endpoints"`
+	Life              Life             `bson:"life"`
+	RelationCount     int              `bson:"relationcount"`
+}
+
+func newRemoteService(st *State, doc *remoteServiceDoc) *RemoteService {
+	return &RemoteService{st: st, doc: *doc}
+}
+
+// Name returns the remote service's name.
+func (s *RemoteService) Name() string {
+	return s.doc.Name
+}
+
+// String returns the remote service's name.
+func (s *RemoteService) String() string {
+	return s.doc.Name
+}
+
+// SourceEnviron returns the tag of the environment holding the service
+// the remote service stands for.
+func (s *RemoteService) SourceEnviron() names.EnvironTag {
+	return names.NewEnvironTag(s.doc.SourceEnvUUID)
+}
+
+// SourceServiceName returns the name of the service the remote service
+// stands for, in its own environment.
+func (s *RemoteService) SourceServiceName() string {
+	return s.doc.SourceServiceName
+}
+
+// OfferName returns the name of the offer the remote service was
+// consumed from, or an empty string if it is a proxy for a service
+// related to one of this environment's offers.
+func (s *RemoteService) OfferName() string {
+	return s.doc.OfferName
+}
+
+// ConsumedBy returns the tag of the user who consumed the offer the
+// remote service was consumed from. The relations of the remote service
+// are only kept in sync while that user has access to the offering
+// environment.
+func (s *RemoteService) ConsumedBy() names.UserTag {
+	if s.doc.ConsumedBy == "" {
+		return names.UserTag{}
+	}
+	return names.NewUserTag(s.doc.ConsumedBy)
+}
+
+// Life returns whether the remote service is Alive, Dying or Dead.
+func (s *RemoteService) Life() Life {
+	return s.doc.Life
+}
+
+// Endpoints returns the remote service's endpoints.
+func (s *RemoteService) Endpoints() []Endpoint {
+	eps := make([]Endpoint, len(s.doc.Endpoints))
+	for i, rel := range s.doc.Endpoints {
+		eps[i] = Endpoint{ServiceName: s.doc.Name, Relation: rel}
+	}
+	return eps
+}
+
+// Endpoint returns the remote service's endpoint with the given name.
+func (s *RemoteService) Endpoint(relationName string) (Endpoint, error) {
+	for _, ep := range s.Endpoints() {
+		if ep.Name == relationName {
+			return ep, nil
+		}
+	}
+	return Endpoint{}, errors.Errorf("remote service %q has no %q relation", s, relationName)
+}
+
+// Relations returns the relations the remote service takes part in.
+func (s *RemoteService) Relations() ([]*Relation, error) {
+	return serviceRelations(s.st, s.doc.Name)
+}
+
+// Refresh refreshes the contents of the remote service from the
+// underlying state.
+func (s *RemoteService) Refresh() error {
+	remoteServices, closer := s.st.getCollection(remoteServicesC)
+	defer closer()
+
+	err := remoteServices.FindId(s.doc.DocID).One(&s.doc)
+	if err == mgo.ErrNotFound {
+		return errors.NotFoundf("remote service %q", s)
+	} else if err != nil {
+		return errors.Annotatef(err, "cannot refresh remote service %q", s)
+	}
+	return nil
+}
+
+// Destroy removes the remote service. It is an error to destroy a
+// remote service that still takes part in relations.
+func (s *RemoteService) Destroy() (err error) {
+	defer errors.DeferredAnnotatef(&err, "cannot destroy remote service %q", s)
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if attempt > 0 {
+			if err := s.Refresh(); errors.IsNotFound(err) {
+				return nil, jujutxn.ErrNoOperations
+			} else if err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
+		if s.doc.RelationCount > 0 {
+			return nil, errors.Errorf("remote service has %d relations", s.doc.RelationCount)
+		}
+		return []txn.Op{{
+			C:      remoteServicesC,
+			Id:     s.doc.DocID,
+			Assert: bson.D{{"relationcount", 0}},
+			Remove: true,
+		}}, nil
+	}
+	return s.st.run(buildTxn)
+}
+
+// addEndpointOp returns the operation that adds the given endpoint to
+// the remote service, if it does not already have it.
+func (s *RemoteService) addEndpointOp(rel charm.Relation) (txn.Op, bool) {
+	for _, existing := range s.doc.Endpoints {
+		if existing.Name == rel.Name {
+			return txn.Op{}, false
+		}
+	}
+	return txn.Op{
+		C:      remoteServicesC,
+		Id:     s.doc.DocID,
+		Assert: bson.D{{"endpoints.name", bson.D{{"$ne", rel.Name}}}},
+		Update: bson.D{{"$push", bson.D{{"endpoints", rel}}}},
+	}, true
+}
+
+// AddRemoteServiceArgs holds the arguments for adding a remote service.
+type AddRemoteServiceArgs struct {
+	// Name is the name of the remote service in this environment.
+	Name string
+
+	// SourceEnv is the environment holding the service that the
+	// remote service stands for, and SourceServiceName that
+	// service's name.
+	SourceEnv         names.EnvironTag
+	SourceServiceName string
+
+	// OfferName is the name of the offer the service is consumed
+	// from, if any, and ConsumedBy the user who consumed it.
+	OfferName  string
+	ConsumedBy names.UserTag
+
+	// Endpoints are the relation endpoints of the remote service.
+	Endpoints []charm.Relation
+}
+
+// AddRemoteService adds a service in another environment to this
+// environment, so that local services can be related to it.
+func (st *State) AddRemoteService(args AddRemoteServiceArgs) (_ *RemoteService, err error) {
+	defer errors.DeferredAnnotatef(&err, "cannot add remote service %q", args.Name)
+	if !names.IsValidService(args.Name) {
+		return nil, errors.NotValidf("service name %q", args.Name)
+	}
+	if args.SourceEnv.Id() == st.EnvironUUID() {
+		return nil, errors.Errorf("source environment is this environment")
+	}
+	if args.OfferName != "" && args.ConsumedBy.Id() == "" {
+		return nil, errors.Errorf("no consuming user specified")
+	}
+	if len(args.Endpoints) == 0 {
+		return nil, errors.Errorf("no endpoints specified")
+	}
+	for _, rel := range args.Endpoints {
+		if rel.Role == charm.RolePeer || rel.Scope == charm.ScopeContainer {
+			return nil, errors.Errorf("endpoint %q cannot be related across environments", rel.Name)
+		}
+	}
+	env, err := st.Environment()
+	if err != nil {
+		return nil, errors.Trace(err)
+	} else if env.Life() != Alive {
+		return nil, errors.Errorf("environment is no longer alive")
+	}
+	doc := &remoteServiceDoc{
+		DocID:             st.docID(args.Name),
+		Name:              args.Name,
+		EnvUUID:           st.EnvironUUID(),
+		SourceEnvUUID:     args.SourceEnv.Id(),
+		SourceServiceName: args.SourceServiceName,
+		OfferName:         args.OfferName,
+		Endpoints:         args.Endpoints,
+		Life:              Alive,
+	}
+	if args.OfferName != "" {
+		doc.ConsumedBy = args.ConsumedBy.Canonical()
+	}
+	ops := []txn.Op{
+		env.assertAliveOp(),
+		{
+			C:      servicesC,
+			Id:     doc.DocID,
+			Assert: txn.DocMissing,
+		}, {
+			C:      remoteServicesC,
+			Id:     doc.DocID,
+			Assert: txn.DocMissing,
+			Insert: doc,
+		},
+	}
+	if err := st.runTransaction(ops); err == txn.ErrAborted {
+		if err := checkEnvLife(st); err != nil {
+			return nil, errors.Trace(err)
+		}
+		return nil, errors.Errorf("service already exists")
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return newRemoteService(st, doc), nil
+}
+
+// RemoteService returns the remote service with the given name.
+func (st *State) RemoteService(name string) (*RemoteService, error) {
+	remoteServices, closer := st.getCollection(remoteServicesC)
+	defer closer()
+
+	doc := &remoteServiceDoc{}
+	err := remoteServices.FindId(name).One(doc)
+	if err == mgo.ErrNotFound {
+		return nil, errors.NotFoundf("remote service %q", name)
+	} else if err != nil {
+		return nil, errors.Annotatef(err, "cannot get remote service %q", name)
+	}
+	return newRemoteService(st, doc), nil
+}
+
+// AllRemoteServices returns all the remote services in the environment.
+func (st *State) AllRemoteServices() ([]*RemoteService, error) {
+	remoteServices, closer := st.getCollection(remoteServicesC)
+	defer closer()
+
+	var docs []remoteServiceDoc
+	if err := remoteServices.Find(nil).Sort("name").All(&docs); err != nil {
+		return nil, errors.Annotate(err, "cannot get remote services")
+	}
+	result := make([]*RemoteService, len(docs))
+	for i := range docs {
+		result[i] = newRemoteService(st, &docs[i])
+	}
+	return result, nil
+}
+
+// isRemoteService returns whether the named service is a remote service.
+func isRemoteService(st *State, name string) (bool, error) {
+	remoteServices, closer := st.getCollection(remoteServicesC)
+	defer closer()
+
+	count, err := remoteServices.FindId(name).Count()
+	if err != nil {
+		return false, errors.Annotatef(err, "cannot get remote service %q", name)
+	}
+	return count > 0, nil
+}
+
+// relationAddedOp returns the operation that records a new relation of
+// the remote service for the given endpoint, checking that the endpoint
+// belongs to the service.
+func (s *RemoteService) relationAddedOp(ep Endpoint) (txn.Op, error) {
+	if s.doc.Life != Alive {
+		return txn.Op{}, errors.Errorf("service %q is not alive", s)
+	}
+	own, err := s.Endpoint(ep.Name)
+	if err != nil || own.Relation != ep.Relation {
+		return txn.Op{}, errors.Errorf("%q does not implement %q", s, ep)
+	}
+	return txn.Op{
+		C:      remoteServicesC,
+		Id:     s.doc.DocID,
+		Assert: bson.D{{"life", Alive}, {"endpoints.name", ep.Name}},
+		Update: bson.D{{"$inc", bson.D{{"relationcount", 1}}}},
+	}, nil
+}
+
+// remoteRelationRemovedOp returns the operation that records the
+// removal of one of the named remote service's relations.
+func remoteRelationRemovedOp(st *State, serviceName string) txn.Op {
+	return txn.Op{
+		C:      remoteServicesC,
+		Id:     st.docID(serviceName),
+		Assert: bson.D{{"relationcount", bson.D{{"$gt", 0}}}},
+		Update: bson.D{{"$inc", bson.D{{"relationcount", -1}}}},
+	}
+}
+
+// RemoteRelationUnit represents a unit of a remote service in one of its
+// relations. The units of the service a remote service stands for enter
+// and leave the remote service's relations, with their settings, as
+// they do the service's own relations.
+type RemoteRelationUnit struct {
+	st       *State
+	relation *Relation
+	endpoint Endpoint
+	unitName string
+}
+
+// RemoteUnit returns a RemoteRelationUnit for the named unit of a remote
+// service in the relation.
+func (r *Relation) RemoteUnit(unitName string) (*RemoteRelationUnit, error) {
+	serviceName, err := names.UnitService(unitName)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	ep, err := r.Endpoint(serviceName)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if remote, err := isRemoteService(r.st, serviceName); err != nil {
+		return nil, errors.Trace(err)
+	} else if !remote {
+		return nil, errors.Errorf("service %q is not a remote service", serviceName)
+	}
+	return &RemoteRelationUnit{
+		st:       r.st,
+		relation: r,
+		endpoint: ep,
+		unitName: unitName,
+	}, nil
+}
+
+// key returns the key of the remote unit in the relation's settings and
+// scopes. Remote services only take part in relations of global scope.
+func (ru *RemoteRelationUnit) key() string {
+	return fmt.Sprintf("r#%d#%s#%s", ru.relation.Id(), ru.endpoint.Role, ru.unitName)
+}
+
+// InScope returns whether the remote unit has entered the relation's
+// scope and not left it.
+func (ru *RemoteRelationUnit) InScope() (bool, error) {
+	relationScopes, closer := ru.st.getCollection(relationScopesC)
+	defer closer()
+
+	count, err := relationScopes.FindId(ru.key()).Count()
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	return count > 0, nil
+}
+
+// Settings returns the remote unit's settings in the relation.
+func (ru *RemoteRelationUnit) Settings() (map[string]interface{}, error) {
+	settings, err := readSettings(ru.st, ru.key())
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return settings.Map(), nil
+}
+
+// EnterScope ensures that the remote unit is in the relation's scope,
+// with the supplied settings. Unlike units of local services, remote
+// units do not keep a relation alive: once all local units have left a
+// dying relation, it is removed regardless of its remote units.
+func (ru *RemoteRelationUnit) EnterScope(settings map[string]interface{}) (err error) {
+	defer errors.DeferredAnnotatef(&err, "cannot enter scope for remote unit %q in relation %q", ru.unitName, ru.relation)
+	key := ru.key()
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		ops := []txn.Op{{
+			C:      relationsC,
+			Id:     ru.relation.doc.DocID,
+			Assert: isAliveDoc,
+		}}
+		existing, err := readSettings(ru.st, key)
+		if errors.IsNotFound(err) {
+			ops = append(ops, createSettingsOp(key, settings))
+		} else if err != nil {
+			return nil, errors.Trace(err)
+		} else if !reflect.DeepEqual(existing.Map(), settings) {
+			op, _, err := replaceSettingsOp(ru.st, key, settings)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			ops = append(ops, op)
+		}
+		inScope, err := ru.InScope()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if !inScope {
+			ops = append(ops, txn.Op{
+				C:      relationScopesC,
+				Id:     ru.st.docID(key),
+				Assert: txn.DocMissing,
+				Insert: relationScopeDoc{
+					DocID:   ru.st.docID(key),
+					Key:     key,
+					EnvUUID: ru.st.EnvironUUID(),
+				},
+			})
+		} else if len(ops) == 1 {
+			return nil, jujutxn.ErrNoOperations
+		}
+		return ops, nil
+	}
+	return ru.st.run(buildTxn)
+}
+
+// LeaveScope removes the remote unit from the relation's scope. Its
+// settings remain until the relation is removed.
+func (ru *RemoteRelationUnit) LeaveScope() error {
+	ops := []txn.Op{{
+		C:      relationScopesC,
+		Id:     ru.st.docID(ru.key()),
+		Remove: true,
+	}}
+	if err := ru.st.runTransaction(ops); err != nil {
+		return errors.Annotatef(err, "cannot leave scope for remote unit %q in relation %q", ru.unitName, ru.relation)
+	}
+	return nil
+}
+
+// cleanupRemoteRelationScopes removes the scope documents of the remote
+// units of a relation that has been removed.
+func (st *State) cleanupRemoteRelationScopes(prefix string) error {
+	relationScopes, closer := st.getCollection(relationScopesC)
+	defer closer()
+
+	sel := bson.D{{"key", bson.D{{"$regex", "^" + prefix}}}}
+	var docs []relationScopeDoc
+	if err := relationScopes.Find(sel).Select(bson.D{{"_id", 1}}).All(&docs); err != nil {
+		return errors.Annotatef(err, "cannot get relation scopes with prefix %q", prefix)
+	}
+	if len(docs) == 0 {
+		return nil
+	}
+	ops := make([]txn.Op, len(docs))
+	for i, doc := range docs {
+		ops[i] = txn.Op{
+			C:      relationScopesC,
+			Id:     doc.DocID,
+			Remove: true,
+		}
+	}
+	return errors.Annotatef(st.runTransaction(ops), "cannot remove relation scopes with prefix %q", prefix)
+}
@@ -0,0 +1,203 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/charm.v6-unstable"
+
+	"github.com/juju/juju/state"
+)
+
+type RemoteServiceSuite struct {
+	ConnSuite
+	sourceEnv names.EnvironTag
+	wordpress *state.Service
+	remote    *state.RemoteService
+}
+
+var _ = gc.Suite(&RemoteServiceSuite{})
+
+var mysqlServerRelation = charm.Relation{
+	Name:      "server",
+	Role:      charm.RoleProvider,
+	Interface: "mysql",
+	Scope:     charm.ScopeGlobal,
+}
+
+func (s *RemoteServiceSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	s.sourceEnv = names.NewEnvironTag("deadbeef-0bad-400d-8000-4b1d0d06f00d")
+	s.wordpress = s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+	var err error
+	s.remote, err = s.State.AddRemoteService(state.AddRemoteServiceArgs{
+		Name:              "db",
+		SourceEnv:         s.sourceEnv,
+		SourceServiceName: "mysql",
+		OfferName:         "shared-db",
+		ConsumedBy:        s.Owner,
+		Endpoints:         []charm.Relation{mysqlServerRelation},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *RemoteServiceSuite) TestAddRemoteService(c *gc.C) {
+	remote, err := s.State.RemoteService("db")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(remote.Name(), gc.Equals, "db")
+	c.Assert(remote.SourceEnviron(), gc.Equals, s.sourceEnv)
+	c.Assert(remote.SourceServiceName(), gc.Equals, "mysql")
+	c.Assert(remote.OfferName(), gc.Equals, "shared-db")
+	c.Assert(remote.ConsumedBy(), gc.Equals, s.Owner)
+	c.Assert(remote.Life(), gc.Equals, state.Alive)
+	c.Assert(remote.Endpoints(), jc.DeepEquals, []state.Endpoint{{
+		ServiceName: "db",
+		Relation:    mysqlServerRelation,
+	}})
+
+	all, err := s.State.AllRemoteServices()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(all, gc.HasLen, 1)
+	c.Assert(all[0].Name(), gc.Equals, "db")
+}
+
+func (s *RemoteServiceSuite) TestNamesAreShared(c *gc.C) {
+	_, err := s.State.AddRemoteService(state.AddRemoteServiceArgs{
+		Name:      "wordpress",
+		SourceEnv: s.sourceEnv,
+		Endpoints: []charm.Relation{mysqlServerRelation},
+	})
+	c.Assert(err, gc.ErrorMatches, `cannot add remote service "wordpress": service already exists`)
+
+	ch := s.AddTestingCharm(c, "mysql")
+	_, err = s.State.AddService(state.AddServiceArgs{Name: "db", Owner: s.Owner.String(), Charm: ch})
+	c.Assert(err, gc.ErrorMatches, `cannot add service "db": service already exists`)
+}
+
+func (s *RemoteServiceSuite) TestAddRemoteServiceInvalid(c *gc.C) {
+	_, err := s.State.AddRemoteService(state.AddRemoteServiceArgs{
+		Name:      "db2",
+		SourceEnv: s.State.EnvironTag(),
+		Endpoints: []charm.Relation{mysqlServerRelation},
+	})
+	c.Assert(err, gc.ErrorMatches, `cannot add remote service "db2": source environment is this environment`)
+
+	_, err = s.State.AddRemoteService(state.AddRemoteServiceArgs{
+		Name:      "ring",
+		SourceEnv: s.sourceEnv,
+		Endpoints: []charm.Relation{{Name: "ring", Role: charm.RolePeer, Interface: "riak"}},
+	})
+	c.Assert(err, gc.ErrorMatches, `cannot add remote service "ring": endpoint "ring" cannot be related across environments`)
+
+	_, err = s.State.AddRemoteService(state.AddRemoteServiceArgs{
+		Name:              "db2",
+		SourceEnv:         s.sourceEnv,
+		SourceServiceName: "mysql",
+		OfferName:         "shared-db",
+		Endpoints:         []charm.Relation{mysqlServerRelation},
+	})
+	c.Assert(err, gc.ErrorMatches, `cannot add remote service "db2": no consuming user specified`)
+}
+
+func (s *RemoteServiceSuite) TestRelate(c *gc.C) {
+	eps, err := s.State.InferEndpoints("wordpress", "db")
+	c.Assert(err, jc.ErrorIsNil)
+	rel, err := s.State.AddRelation(eps...)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(rel.String(), gc.Equals, "wordpress:db db:server")
+
+	err = s.remote.Destroy()
+	c.Assert(err, gc.ErrorMatches, `cannot destroy remote service "db": remote service has 1 relations`)
+
+	err = rel.Destroy()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.remote.Destroy()
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.RemoteService("db")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *RemoteServiceSuite) TestRemoteServicesCannotBeRelated(c *gc.C) {
+	_, err := s.State.AddRemoteService(state.AddRemoteServiceArgs{
+		Name:      "wp",
+		SourceEnv: s.sourceEnv,
+		Endpoints: []charm.Relation{{
+			Name:      "db",
+			Role:      charm.RoleRequirer,
+			Interface: "mysql",
+			Scope:     charm.ScopeGlobal,
+		}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	eps, err := s.State.InferEndpoints("wp", "db")
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.AddRelation(eps...)
+	c.Assert(err, gc.ErrorMatches, `cannot add relation "wp:db db:server": cannot relate remote services to each other`)
+}
+
+func (s *RemoteServiceSuite) TestRemoteUnits(c *gc.C) {
+	eps, err := s.State.InferEndpoints("wordpress", "db")
+	c.Assert(err, jc.ErrorIsNil)
+	rel, err := s.State.AddRelation(eps...)
+	c.Assert(err, jc.ErrorIsNil)
+	unit, err := s.wordpress.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	ru, err := rel.Unit(unit)
+	c.Assert(err, jc.ErrorIsNil)
+	err = ru.EnterScope(nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = rel.RemoteUnit("wordpress/0")
+	c.Assert(err, gc.ErrorMatches, `service "wordpress" is not a remote service`)
+	remoteUnit, err := rel.RemoteUnit("db/0")
+	c.Assert(err, jc.ErrorIsNil)
+	err = remoteUnit.EnterScope(map[string]interface{}{"host": "10.0.0.1"})
+	c.Assert(err, jc.ErrorIsNil)
+	inScope, err := remoteUnit.InScope()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(inScope, jc.IsTrue)
+
+	// Local units see the remote unit's settings.
+	settings, err := ru.ReadSettings("db/0")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(settings, gc.DeepEquals, map[string]interface{}{"host": "10.0.0.1"})
+
+	err = remoteUnit.EnterScope(map[string]interface{}{"host": "10.0.0.2"})
+	c.Assert(err, jc.ErrorIsNil)
+	settings, err = ru.ReadSettings("db/0")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(settings, gc.DeepEquals, map[string]interface{}{"host": "10.0.0.2"})
+
+	err = remoteUnit.LeaveScope()
+	c.Assert(err, jc.ErrorIsNil)
+	inScope, err = remoteUnit.InScope()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(inScope, jc.IsFalse)
+}
+
+func (s *RemoteServiceSuite) TestRemovingRelationRemovesRemoteUnits(c *gc.C) {
+	eps, err := s.State.InferEndpoints("wordpress", "db")
+	c.Assert(err, jc.ErrorIsNil)
+	rel, err := s.State.AddRelation(eps...)
+	c.Assert(err, jc.ErrorIsNil)
+	remoteUnit, err := rel.RemoteUnit("db/0")
+	c.Assert(err, jc.ErrorIsNil)
+	err = remoteUnit.EnterScope(nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	// Remote units do not keep the relation alive.
+	err = rel.Destroy()
+	c.Assert(err, jc.ErrorIsNil)
+	err = rel.Refresh()
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+
+	err = s.State.Cleanup()
+	c.Assert(err, jc.ErrorIsNil)
+	inScope, err := remoteUnit.InScope()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(inScope, jc.IsFalse)
+}
@@ -0,0 +1,156 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"io"
+
+	"github.com/juju/errors"
+	"gopkg.in/mgo.v2/txn"
+
+	"github.com/juju/juju/resource"
+	"github.com/juju/juju/state/storage"
+)
+
+// Resources exposes high-level interaction with the charm resources
+// of services in an environment.
+type Resources interface {
+	// ListResources returns the resources declared by the service's
+	// charm, at their current revisions, along with the revisions
+	// each of the service's units has.
+	ListResources(service string) (resource.ServiceResources, error)
+	// SetResource stores the data read from r as a new revision of
+	// the named resource of the service.
+	SetResource(service, name, username string, r io.Reader, size int64, fingerprint string) (resource.Resource, error)
+	// OpenResource returns the current revision of the service's
+	// resource and a reader for its data.
+	OpenResource(service, name string) (resource.Resource, io.ReadCloser, error)
+	// SetUnitResource records that the unit has the given revision of
+	// a resource.
+	SetUnitResource(unit string, res resource.Resource) error
+	// NewRemoveResourcesOps returns the operations that remove the
+	// resources of the service and its units. The data of the
+	// resources is removed later, by a cleanup.
+	NewRemoveResourcesOps(service string) ([]txn.Op, error)
+	// NewRemoveUnitResourcesOps returns the operations that remove
+	// the records of the resources the unit has.
+	NewRemoveUnitResourcesOps(unit string) ([]txn.Op, error)
+	// RemoveUnusedData removes the resource data stored at the path,
+	// unless a resource revision held by a service or unit refers
+	// to it.
+	RemoveUnusedData(storagePath string) error
+}
+
+// ResourcesPersistence provides all the information needed to produce
+// a new Resources value.
+type ResourcesPersistence interface {
+	Persistence
+
+	// Storage returns the blob storage in which resource data is held.
+	Storage() storage.Storage
+
+	// CharmResourceMeta returns the resources declared by the charm of
+	// the named service.
+	CharmResourceMeta(service string) ([]resource.Meta, error)
+
+	// NewCleanupOp returns an operation that schedules a cleanup of
+	// the given kind.
+	NewCleanupOp(kind, prefix string) txn.Op
+}
+
+type newResourcesFunc func(ResourcesPersistence) (Resources, error)
+
+var newResources newResourcesFunc
+
+// SetResourcesComponent registers the function that provides the
+// state functionality related to resources.
+func SetResourcesComponent(resourcesFunc newResourcesFunc) {
+	newResources = resourcesFunc
+}
+
+// Resources exposes interaction with resources in state.
+func (st *State) Resources() (Resources, error) {
+	if newResources == nil {
+		return nil, errors.Errorf("resources not supported")
+	}
+
+	persist := &resourcesPersistence{
+		Persistence: st.newPersistence(),
+		st:          st,
+	}
+	resources, err := newResources(persist)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return resources, nil
+}
+
+type resourcesPersistence struct {
+	Persistence
+	st *State
+}
+
+// removeResourcesOps returns the operations required to remove the
+// resources of the named service, if resources are supported.
+func removeResourcesOps(st *State, service string) ([]txn.Op, error) {
+	if newResources == nil {
+		return nil, nil
+	}
+	resources, err := st.Resources()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	ops, err := resources.NewRemoveResourcesOps(service)
+	return ops, errors.Trace(err)
+}
+
+// removeUnitResourcesOps returns the operations required to remove the
+// records of the resources the named unit has, if resources are
+// supported.
+func removeUnitResourcesOps(st *State, unit string) ([]txn.Op, error) {
+	if newResources == nil {
+		return nil, nil
+	}
+	resources, err := st.Resources()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	ops, err := resources.NewRemoveUnitResourcesOps(unit)
+	return ops, errors.Trace(err)
+}
+
+// cleanupResourceBlob removes the resource data stored at the given
+// path, unless it is still in use.
+func (st *State) cleanupResourceBlob(storagePath string) error {
+	resources, err := st.Resources()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(resources.RemoveUnusedData(storagePath))
+}
+
+// Storage implements ResourcesPersistence.
+func (p *resourcesPersistence) Storage() storage.Storage {
+	return storage.NewStorage(p.st.EnvironUUID(), p.st.MongoSession())
+}
+
+// NewCleanupOp implements ResourcesPersistence.
+func (p *resourcesPersistence) NewCleanupOp(kind, prefix string) txn.Op {
+	return p.st.newCleanupOp(cleanupKind(kind), prefix)
+}
+
+// CharmResourceMeta implements ResourcesPersistence. The resources
+// are those recorded with the charm when it was added.
+func (p *resourcesPersistence) CharmResourceMeta(service string) ([]resource.Meta, error) {
+	svc, err := p.st.Service(service)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	curl, _ := svc.CharmURL()
+	ch, err := p.st.Charm(curl)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return ch.ResourceMeta(), nil
+}
@@ -0,0 +1,141 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"bytes"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/resource"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/storage"
+)
+
+var _ = gc.Suite(&ResourcesSuite{})
+
+type ResourcesSuite struct {
+	ConnSuite
+
+	service   *state.Service
+	unit      *state.Unit
+	resources state.Resources
+}
+
+const resourcesMetaYAML = `
+name: starsay
+summary: "A charm with resources."
+description: "A charm with resources."
+resources:
+  licence:
+    filename: licence.txt
+    description: The licence.
+`
+
+func (s *ResourcesSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	ch := s.AddMetaCharm(c, "dummy", resourcesMetaYAML, 2)
+	s.service = s.AddTestingService(c, "starsay", ch)
+	var err error
+	s.unit, err = s.service.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	s.resources, err = s.State.Resources()
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *ResourcesSuite) push(c *gc.C, data string) resource.Resource {
+	fingerprint, size, err := resource.Fingerprint(bytes.NewBufferString(data))
+	c.Assert(err, jc.ErrorIsNil)
+	res, err := s.resources.SetResource("starsay", "licence", "bob", bytes.NewBufferString(data), size, fingerprint)
+	c.Assert(err, jc.ErrorIsNil)
+	return res
+}
+
+func (s *ResourcesSuite) assertDataRemoved(c *gc.C, path string) {
+	_, _, err := storage.NewStorage(s.State.EnvironUUID(), s.State.MongoSession()).Get(path)
+	c.Check(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *ResourcesSuite) assertDataKept(c *gc.C, path string) {
+	r, _, err := storage.NewStorage(s.State.EnvironUUID(), s.State.MongoSession()).Get(path)
+	c.Assert(err, jc.ErrorIsNil)
+	r.Close()
+}
+
+func (s *ResourcesSuite) TestCharmResourceMeta(c *gc.C) {
+	resources, err := s.resources.ListResources("starsay")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(resources.Resources, gc.HasLen, 1)
+	c.Check(resources.Resources[0].Meta, jc.DeepEquals, resource.Meta{
+		Name:        "licence",
+		Type:        resource.TypeFile,
+		Path:        "licence.txt",
+		Description: "The licence.",
+	})
+}
+
+func (s *ResourcesSuite) TestRevisionDataKeptWhileUnitUsesIt(c *gc.C) {
+	res := s.push(c, "GPLv3")
+	err := s.resources.SetUnitResource(s.unit.Name(), res)
+	c.Assert(err, jc.ErrorIsNil)
+	paths := state.ResourceStoragePaths(c, s.State)
+	c.Assert(paths, gc.HasLen, 1)
+	oldPath := paths[0]
+
+	res = s.push(c, "AGPLv3")
+	c.Assert(s.State.Cleanup(), jc.ErrorIsNil)
+	s.assertDataKept(c, oldPath)
+
+	err = s.resources.SetUnitResource(s.unit.Name(), res)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.State.Cleanup(), jc.ErrorIsNil)
+	s.assertDataRemoved(c, oldPath)
+	c.Check(state.ResourceStoragePaths(c, s.State), gc.HasLen, 1)
+}
+
+func (s *ResourcesSuite) TestUnitRemovalRemovesUnitResources(c *gc.C) {
+	res := s.push(c, "GPLv3")
+	err := s.resources.SetUnitResource(s.unit.Name(), res)
+	c.Assert(err, jc.ErrorIsNil)
+	path := state.ResourceStoragePaths(c, s.State)[0]
+
+	err = s.unit.EnsureDead()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.unit.Remove()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.State.Cleanup(), jc.ErrorIsNil)
+
+	resources, err := s.resources.ListResources("starsay")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(resources.UnitResources, gc.HasLen, 0)
+	// The service still holds the revision.
+	s.assertDataKept(c, path)
+}
+
+func (s *ResourcesSuite) TestServiceRemovalRemovesResources(c *gc.C) {
+	res := s.push(c, "GPLv3")
+	err := s.resources.SetUnitResource(s.unit.Name(), res)
+	c.Assert(err, jc.ErrorIsNil)
+	path := state.ResourceStoragePaths(c, s.State)[0]
+
+	err = s.service.Destroy()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.unit.EnsureDead()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.unit.Remove()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.State.Cleanup(), jc.ErrorIsNil)
+
+	c.Check(state.ResourceStoragePaths(c, s.State), gc.HasLen, 0)
+	s.assertDataRemoved(c, path)
+
+	// A service deployed again under the same name does not inherit
+	// the resources.
+	s.AddTestingService(c, "starsay", s.AddMetaCharm(c, "dummy", resourcesMetaYAML, 3))
+	resources, err := s.resources.ListResources("starsay")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(resources.Resources[0].Revision, gc.Equals, 0)
+}
@@ -38,3 +38,39 @@ func (s *State) sequence(name string) (int, error) {
 	}
 	return result.Counter, nil
 }
+
+// setSequence sets the next value of the named sequence number.
+func (st *State) setSequence(name string, next int) error {
+	sequences, closer := st.getCollection(sequenceC)
+	defer closer()
+	set := mgo.Change{
+		Update: bson.M{
+			"$set": bson.M{
+				"name":     name,
+				"env-uuid": st.EnvironUUID(),
+				"counter":  next,
+			},
+		},
+		Upsert: true,
+	}
+	if _, err := sequences.FindId(name).Apply(set, &sequenceDoc{}); err != nil {
+		return fmt.Errorf("cannot set %q sequence number: %v", name, err)
+	}
+	return nil
+}
+
+// sequences returns the next value of each of the environment's
+// sequence numbers, by name.
+func (st *State) sequences() (map[string]int, error) {
+	sequences, closer := st.getCollection(sequenceC)
+	defer closer()
+	var docs []sequenceDoc
+	if err := sequences.Find(nil).All(&docs); err != nil {
+		return nil, fmt.Errorf("cannot read sequence numbers: %v", err)
+	}
+	result := make(map[string]int, len(docs))
+	for _, doc := range docs {
+		result[doc.Name] = doc.Counter
+	}
+	return result, nil
+}
@@ -48,6 +48,24 @@ type serviceDoc struct {
 	OwnerTag          string     `bson:"ownertag"`
 	TxnRevno          int64      `bson:"txn-revno"`
 	MetricCredentials []byte     `bson:"metric-credentials"`
+
+	// HookTimeout overrides the environment's hook-timeout for the
+	// service's units, when positive.
+	HookTimeout time.Duration `bson:"hooktimeout,omitempty"`
+
+	// PreviousCharmURL is the charm the service used before its
+	// charm was last changed, if it has been.
+	PreviousCharmURL *charm.URL `bson:"previouscharmurl,omitempty"`
+
+	// Rollout describes the staged rollout of the service's charm to
+	// its units, while one is in progress.
+	Rollout *charmRolloutDoc `bson:"rollout,omitempty"`
+
+	// FirstConfigRevision and ConfigRevision hold the first and the
+	// latest revisions of the service's config history, if any have
+	// been recorded. See state/confighistory.go.
+	FirstConfigRevision int `bson:"firstconfigrevision,omitempty"`
+	ConfigRevision      int `bson:"configrevision,omitempty"`
 }
 
 func newService(st *State, doc *serviceDoc) *Service {
@@ -171,7 +189,11 @@ func (s *Service) destroyOps() ([]txn.Op, error) {
 	// removed, the service can also be removed.
 	if s.doc.UnitCount == 0 && s.doc.RelationCount == removeCount {
 		hasLastRefs := bson.D{{"life", Alive}, {"unitcount", 0}, {"relationcount", removeCount}}
-		return append(ops, s.removeOps(hasLastRefs)...), nil
+		removeOps, err := s.removeOps(hasLastRefs)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return append(ops, removeOps...), nil
 	}
 	// In all other cases, service removal will be handled as a consequence
 	// of the removal of the last unit or relation referencing it. If any
@@ -209,13 +231,13 @@ func (s *Service) destroyOps() ([]txn.Op, error) {
 
 // removeOps returns the operations required to remove the service. Supplied
 // asserts will be included in the operation on the service document.
-func (s *Service) removeOps(asserts bson.D) []txn.Op {
+func (s *Service) removeOps(asserts bson.D) ([]txn.Op, error) {
 	settingsDocID := s.st.docID(s.settingsKey())
 	ops := []txn.Op{
 		{
 			C:      servicesC,
 			Id:     s.doc.DocID,
-			Assert: asserts,
+			Assert: append(asserts, s.configRevisionAssert()...),
 			Remove: true,
 		}, {
 			C:      settingsrefsC,
@@ -227,13 +249,23 @@ func (s *Service) removeOps(asserts bson.D) []txn.Op {
 			Remove: true,
 		},
 		removeRequestedNetworksOp(s.st, s.globalKey()),
+		removeEndpointBindingsOp(s.st, s.globalKey()),
 		removeStorageConstraintsOp(s.globalKey()),
 		removeConstraintsOp(s.st, s.globalKey()),
 		annotationRemoveOp(s.st, s.globalKey()),
+		removeLabelsOp(s.st, s.globalKey()),
 		removeLeadershipSettingsOp(s.Tag().Id()),
 		removeStatusOp(s.st, s.globalKey()),
 	}
-	return ops
+	if s.doc.FirstConfigRevision != 0 {
+		ops = append(ops, s.st.newCleanupOp(cleanupServiceConfigHistory, s.configHistoryCleanupPrefix()))
+	}
+	resourcesOps, err := removeResourcesOps(s.st, s.doc.Name)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	ops = append(ops, resourcesOps...)
+	return append(ops, s.removeOffersOps()...), nil
 }
 
 // IsExposed returns whether this service is exposed. The explicitly open
@@ -269,6 +301,31 @@ func (s *Service) setExposed(exposed bool) (err error) {
 	return nil
 }
 
+// HookTimeout returns how long the service's hooks may run before they
+// are killed. Zero means the environment's hook-timeout applies.
+func (s *Service) HookTimeout() time.Duration {
+	return s.doc.HookTimeout
+}
+
+// SetHookTimeout sets how long the service's hooks may run before they
+// are killed. Zero restores the environment's hook-timeout.
+func (s *Service) SetHookTimeout(timeout time.Duration) error {
+	if timeout < 0 {
+		return errors.NotValidf("negative hook timeout %v", timeout)
+	}
+	ops := []txn.Op{{
+		C:      servicesC,
+		Id:     s.doc.DocID,
+		Assert: isAliveDoc,
+		Update: bson.D{{"$set", bson.D{{"hooktimeout", timeout}}}},
+	}}
+	if err := s.st.runTransaction(ops); err != nil {
+		return errors.Annotatef(onAbort(err, errNotAlive), "cannot set hook timeout for service %q", s)
+	}
+	s.doc.HookTimeout = timeout
+	return nil
+}
+
 // Charm returns the service's charm and whether units should upgrade to that
 // charm even if they are in an error state.
 func (s *Service) Charm() (ch *Charm, force bool, err error) {
@@ -458,8 +515,9 @@ func (s *Service) checkStorageUpgrade(newMeta *charm.Meta) (err error) {
 }
 
 // changeCharmOps returns the operations necessary to set a service's
-// charm URL to a new value.
-func (s *Service) changeCharmOps(ch *Charm, forceUnits bool) ([]txn.Op, error) {
+// charm URL to a new value, rolling it out to the service's units as
+// described by rollout, or to all of them at once if rollout is nil.
+func (s *Service) changeCharmOps(ch *Charm, forceUnits bool, rollout *charmRolloutDoc) ([]txn.Op, error) {
 	// Build the new service config from what can be used of the old one.
 	var newSettings charm.Settings
 	oldSettings, err := readSettings(s.st, s.settingsKey())
@@ -520,7 +578,11 @@ func (s *Service) changeCharmOps(ch *Charm, forceUnits bool) ([]txn.Op, error) {
 			C:      servicesC,
 			Id:     s.doc.DocID,
 			Assert: append(notDeadDoc, differentCharm...),
-			Update: bson.D{{"$set", bson.D{{"charmurl", ch.URL()}, {"forcecharm", forceUnits}}}},
+			Update: append(bson.D{{"$set", bson.D{
+				{"charmurl", ch.URL()},
+				{"forcecharm", forceUnits},
+				{"previouscharmurl", s.doc.CharmURL},
+			}}}, rolloutUpdate(rollout)...),
 		},
 	}...)
 	// Add any extra peer relations that need creation.
@@ -569,6 +631,13 @@ func (s *Service) changeCharmOps(ch *Charm, forceUnits bool) ([]txn.Op, error) {
 // If forceSeries is true, the charm will be used even if it's the service's series
 // is not supported by the charm.
 func (s *Service) SetCharm(ch *Charm, forceSeries, forceUnits bool) error {
+	return s.setCharm(ch, forceSeries, forceUnits, nil)
+}
+
+// setCharm changes the charm for the service, rolling it out to the
+// service's units as described by rollout, or to all of them at once
+// if rollout is nil. Any rollout already in progress is replaced.
+func (s *Service) setCharm(ch *Charm, forceSeries, forceUnits bool, rollout *charmRolloutDoc) error {
 	if ch.Meta().Subordinate != s.doc.Subordinate {
 		return errors.Errorf("cannot change a service's subordinacy")
 	}
@@ -622,6 +691,7 @@ func (s *Service) SetCharm(ch *Charm, forceSeries, forceUnits bool) error {
 	services, closer := s.st.getCollection(servicesC)
 	defer closer()
 
+	var changed bool
 	buildTxn := func(attempt int) ([]txn.Op, error) {
 		if attempt > 0 {
 			// NOTE: We're explicitly allowing SetCharm to succeed
@@ -638,9 +708,13 @@ func (s *Service) SetCharm(ch *Charm, forceSeries, forceUnits bool) error {
 		// Make sure the service doesn't have this charm already.
 		sel := bson.D{{"_id", s.doc.DocID}, {"charmurl", ch.URL()}}
 		var ops []txn.Op
+		changed = false
 		if count, err := services.Find(sel).Count(); err != nil {
 			return nil, errors.Trace(err)
 		} else if count == 1 {
+			if rollout != nil {
+				return nil, errors.Errorf("service %q already uses charm %q", s, ch)
+			}
 			// Charm URL already set; just update the force flag.
 			sameCharm := bson.D{{"charmurl", ch.URL()}}
 			ops = []txn.Op{{
@@ -651,21 +725,55 @@ func (s *Service) SetCharm(ch *Charm, forceSeries, forceUnits bool) error {
 			}}
 		} else {
 			// Change the charm URL.
-			ops, err = s.changeCharmOps(ch, forceUnits)
+			ops, err = s.changeCharmOps(ch, forceUnits, rollout)
 			if err != nil {
 				return nil, errors.Trace(err)
 			}
+			changed = true
 		}
 		return ops, nil
 	}
 	err := s.st.run(buildTxn)
 	if err == nil {
+		if changed {
+			s.doc.PreviousCharmURL = s.doc.CharmURL
+			s.doc.Rollout = rollout
+		}
 		s.doc.CharmURL = ch.URL()
 		s.doc.ForceCharm = forceUnits
 	}
 	return err
 }
 
+// PreviousCharmURL returns the URL of the charm the service used before
+// its charm was last changed, or nil if it has never been changed.
+func (s *Service) PreviousCharmURL() *charm.URL {
+	return s.doc.PreviousCharmURL
+}
+
+// RollbackCharm changes the service's charm back to the one it used
+// before its charm was last changed, and returns that charm. Units are
+// upgraded to it as they would be by SetCharm, running their
+// upgrade-charm hooks with the restored charm. The rollback is refused
+// if the restored charm would lose any of the service's storage or the
+// endpoints of any of its relations.
+func (s *Service) RollbackCharm(forceUnits bool) (_ *Charm, err error) {
+	if s.doc.PreviousCharmURL == nil {
+		return nil, errors.Errorf("cannot roll back charm of service %q: no previous charm", s)
+	}
+	defer errors.DeferredAnnotatef(&err, "cannot roll back service %q to charm %q", s, s.doc.PreviousCharmURL)
+	ch, err := s.st.Charm(s.doc.PreviousCharmURL)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	// The service ran the previous charm before, so its series
+	// has already been accepted.
+	if err := s.SetCharm(ch, true, forceUnits); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return ch, nil
+}
+
 // String returns the service name.
 func (s *Service) String() string {
 	return s.doc.Name
@@ -938,8 +1046,10 @@ func (s *Service) removeUnitOps(u *Unit, asserts bson.D) ([]txn.Op, error) {
 		removeMeterStatusOp(s.st, u.globalMeterStatusKey()),
 		removeStatusOp(s.st, u.globalAgentKey()),
 		removeStatusOp(s.st, u.globalKey()),
+		removeStatusOp(s.st, unitHealthGlobalKey(u.doc.Name)),
 		removeConstraintsOp(s.st, u.globalAgentKey()),
 		annotationRemoveOp(s.st, u.globalKey()),
+		removeLabelsOp(s.st, u.globalKey()),
 		s.st.newCleanupOp(cleanupRemovedUnit, u.doc.Name),
 	)
 	ops = append(ops, portsOps...)
@@ -954,9 +1064,19 @@ func (s *Service) removeUnitOps(u *Unit, asserts bson.D) ([]txn.Op, error) {
 		ops = append(ops, decOps...)
 	}
 	if s.doc.Life == Dying && s.doc.RelationCount == 0 && s.doc.UnitCount == 1 {
+		// The service's removal takes the unit's resources with it.
 		hasLastRef := bson.D{{"life", Dying}, {"relationcount", 0}, {"unitcount", 1}}
-		return append(ops, s.removeOps(hasLastRef)...), nil
+		removeOps, err := s.removeOps(hasLastRef)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return append(ops, removeOps...), nil
 	}
+	resourcesOps, err := removeUnitResourcesOps(s.st, u.doc.Name)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	ops = append(ops, resourcesOps...)
 	svcOp := txn.Op{
 		C:      servicesC,
 		Id:     s.doc.DocID,
@@ -1032,6 +1152,16 @@ func (s *Service) ConfigSettings() (charm.Settings, error) {
 // UpdateConfigSettings changes a service's charm config settings. Values set
 // to nil will be deleted; unknown and invalid values will return an error.
 func (s *Service) UpdateConfigSettings(changes charm.Settings) error {
+	return s.updateConfigSettings(changes, "", 0)
+}
+
+// UpdateConfigSettingsBy is like UpdateConfigSettings, but records the
+// named user as having made the change in the service's config history.
+func (s *Service) UpdateConfigSettingsBy(changes charm.Settings, user string) error {
+	return s.updateConfigSettings(changes, user, 0)
+}
+
+func (s *Service) updateConfigSettings(changes charm.Settings, user string, rolledBackTo int) error {
 	charm, _, err := s.Charm()
 	if err != nil {
 		return err
@@ -1040,23 +1170,39 @@ func (s *Service) UpdateConfigSettings(changes charm.Settings) error {
 	if err != nil {
 		return err
 	}
-	// TODO(fwereade) state.Settings is itself really problematic in just
-	// about every use case. This needs to be resolved some time; but at
-	// least the settings docs are keyed by charm url as well as service
-	// name, so the actual impact of a race is non-threatening.
-	node, err := readSettings(s.st, s.settingsKey())
-	if err != nil {
-		return err
-	}
-	for name, value := range changes {
-		if value == nil {
-			node.Delete(name)
-		} else {
-			node.Set(name, value)
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if attempt > 0 {
+			if err := s.Refresh(); err != nil {
+				return nil, errors.Trace(err)
+			}
 		}
+		// TODO(fwereade) state.Settings is itself really problematic in just
+		// about every use case. This needs to be resolved some time; but at
+		// least the settings docs are keyed by charm url as well as service
+		// name, so the actual impact of a race is non-threatening.
+		node, err := readSettings(s.st, s.settingsKey())
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		previous := node.Map()
+		for name, value := range changes {
+			if value == nil {
+				node.Delete(name)
+			} else {
+				node.Set(name, value)
+			}
+		}
+		_, ops := node.writeOps()
+		historyOps, err := s.configRevisionOps(previous, node.Map(), user, rolledBackTo)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return append(ops, historyOps...), nil
 	}
-	_, err = node.Write()
-	return err
+	if err := s.st.run(buildTxn); err != nil {
+		return errors.Trace(err)
+	}
+	return s.Refresh()
 }
 
 // LeaderSettings returns a service's leader settings. If nothing has been set
@@ -1367,6 +1513,14 @@ func (s *Service) SetStatus(status Status, info string, data map[string]interfac
 	})
 }
 
+// StatusHistory returns a slice of StatusInfo items matching the
+// filter and representing past statuses for this service, newest
+// first. Only statuses set on the service itself are recorded; those
+// derived from its units are not.
+func (s *Service) StatusHistory(filter StatusHistoryFilter) ([]StatusInfo, error) {
+	return statusHistory(s.st, s.globalKey(), filter)
+}
+
 // ServiceAndUnitsStatus returns the status for this service and all its units.
 func (s *Service) ServiceAndUnitsStatus() (StatusInfo, map[string]StatusInfo, error) {
 	serviceStatus, err := s.Status()
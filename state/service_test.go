@@ -6,6 +6,7 @@ package state_test
 import (
 	"fmt"
 	"sort"
+	"time"
 
 	"github.com/juju/errors"
 	"github.com/juju/loggo"
@@ -248,6 +249,43 @@ func (s *ServiceSuite) TestSetCharmChecksEndpointsWithRelations(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, `cannot upgrade service "myrequirer" to charm "local:quantal/quantal-mysql-4": would break relation "myrequirer:kludge myprovider:kludge"`)
 }
 
+func (s *ServiceSuite) TestRollbackCharm(c *gc.C) {
+	c.Assert(s.mysql.PreviousCharmURL(), gc.IsNil)
+	_, err := s.mysql.RollbackCharm(false)
+	c.Assert(err, gc.ErrorMatches, `cannot roll back charm of service "mysql": no previous charm`)
+
+	sch := s.AddMetaCharm(c, "mysql", metaBase, 2)
+	err = s.mysql.SetCharm(sch, false, false)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.mysql.PreviousCharmURL(), gc.DeepEquals, s.charm.URL())
+
+	ch, err := s.mysql.RollbackCharm(true)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ch.URL(), gc.DeepEquals, s.charm.URL())
+
+	err = s.mysql.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	url, force := s.mysql.CharmURL()
+	c.Assert(url, gc.DeepEquals, s.charm.URL())
+	c.Assert(force, jc.IsTrue)
+	c.Assert(s.mysql.PreviousCharmURL(), gc.DeepEquals, sch.URL())
+}
+
+func (s *ServiceSuite) TestRollbackCharmWouldBreakRelation(c *gc.C) {
+	baseCharm := s.AddMetaCharm(c, "mysql", metaBase, 2)
+	svc := s.AddTestingService(c, "fakemysql", baseCharm)
+	extraCharm := s.AddMetaCharm(c, "mysql", metaExtraEndpoints, 3)
+	err := svc.SetCharm(extraCharm, false, false)
+	c.Assert(err, jc.ErrorIsNil)
+
+	// The upgrade added the "just" peer relation, which the
+	// previous charm does not implement.
+	_, err = svc.RollbackCharm(false)
+	c.Assert(err, gc.ErrorMatches, `cannot roll back service "fakemysql" to charm "local:quantal/quantal-mysql-2": .*would break relation "fakemysql:just"`)
+	url, _ := svc.CharmURL()
+	c.Assert(url, gc.DeepEquals, extraCharm.URL())
+}
+
 var stringConfig = `
 options:
   key: {default: My Key, description: Desc, type: string}
@@ -1082,6 +1120,32 @@ func (s *ServiceSuite) TestServiceExposed(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, notAliveErr)
 }
 
+func (s *ServiceSuite) TestServiceHookTimeout(c *gc.C) {
+	c.Assert(s.mysql.HookTimeout(), gc.Equals, time.Duration(0))
+
+	err := s.mysql.SetHookTimeout(30 * time.Minute)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.mysql.HookTimeout(), gc.Equals, 30*time.Minute)
+	err = s.mysql.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.mysql.HookTimeout(), gc.Equals, 30*time.Minute)
+
+	err = s.mysql.SetHookTimeout(-time.Second)
+	c.Assert(err, gc.ErrorMatches, "negative hook timeout -1s not valid")
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+
+	err = s.mysql.SetHookTimeout(0)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.mysql.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.mysql.HookTimeout(), gc.Equals, time.Duration(0))
+
+	err = s.mysql.Destroy()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.mysql.SetHookTimeout(time.Minute)
+	c.Assert(err, gc.ErrorMatches, notAliveErr)
+}
+
 func (s *ServiceSuite) TestAddUnit(c *gc.C) {
 	// Check that principal units can be added on their own.
 	unitZero, err := s.mysql.AddUnit()
@@ -1960,6 +2024,18 @@ func (s *ServiceSuite) TestSetCharmStorageRemoved(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, `cannot upgrade service "test" to charm "mysql": storage "data1" removed`)
 }
 
+func (s *ServiceSuite) TestRollbackCharmStorageRemoved(c *gc.C) {
+	err := s.setCharmFromMeta(c,
+		mysqlBaseMeta+oneOptionalStorageMeta,
+		mysqlBaseMeta+twoOptionalStorageMeta,
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	svc, err := s.State.Service("test")
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = svc.RollbackCharm(false)
+	c.Assert(err, gc.ErrorMatches, `cannot roll back service "test" to charm "local:quantal/quantal-mysql-2": .*storage "data1" removed`)
+}
+
 func (s *ServiceSuite) TestSetCharmRequiredStorageAdded(c *gc.C) {
 	err := s.setCharmFromMeta(c,
 		mysqlBaseMeta+oneRequiredStorageMeta,
@@ -166,6 +166,25 @@ func cacheKeys(caches ...map[string]interface{}) map[string]bool {
 // as a delta applied on top of the latest version of the node, to prevent
 // overwriting unrelated changes made to the node since it was last read.
 func (c *Settings) Write() ([]ItemChange, error) {
+	changes, ops := c.writeOps()
+	if len(ops) == 0 {
+		return changes, nil
+	}
+	err := c.st.runTransaction(ops)
+	if err == txn.ErrAborted {
+		return nil, errors.NotFoundf("settings")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot write settings: %v", err)
+	}
+	c.disk = copyMap(c.core, nil)
+	return changes, nil
+}
+
+// writeOps returns the changes made to c, and the operations that
+// write them back onto its node. There are no operations if nothing
+// has changed.
+func (c *Settings) writeOps() ([]ItemChange, []txn.Op) {
 	changes := []ItemChange{}
 	updates := bson.M{}
 	deletions := bson.M{}
@@ -193,24 +212,15 @@ func (c *Settings) Write() ([]ItemChange, error) {
 		changes = append(changes, change)
 	}
 	if len(changes) == 0 {
-		return []ItemChange{}, nil
+		return changes, nil
 	}
 	sort.Sort(itemChangeSlice(changes))
-	ops := []txn.Op{{
+	return changes, []txn.Op{{
 		C:      settingsC,
 		Id:     c.key,
 		Assert: txn.DocExists,
 		Update: setUnsetUpdateSettings(updates, deletions),
 	}}
-	err := c.st.runTransaction(ops)
-	if err == txn.ErrAborted {
-		return nil, errors.NotFoundf("settings")
-	}
-	if err != nil {
-		return nil, fmt.Errorf("cannot write settings: %v", err)
-	}
-	c.disk = copyMap(c.core, nil)
-	return changes, nil
 }
 
 func newSettings(st *State, key string) *Settings {
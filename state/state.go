@@ -1106,6 +1106,10 @@ type AddServiceArgs struct {
 	NumUnits    int
 	Placement   []*instance.Placement
 	Constraints constraints.Value
+
+	// EndpointBindings maps the names of charm relation endpoints
+	// to the names of the spaces they are bound to.
+	EndpointBindings map[string]string
 }
 
 // AddService creates a new service, running the supplied charm, with the
@@ -1148,6 +1152,9 @@ func (st *State) AddService(args AddServiceArgs) (service *Service, err error) {
 	if err := validateStorageConstraints(st, args.Storage, args.Charm.Meta()); err != nil {
 		return nil, errors.Trace(err)
 	}
+	if err := validateEndpointBindings(st, args.EndpointBindings, args.Charm.Meta()); err != nil {
+		return nil, errors.Trace(err)
+	}
 	storagePools := make(set.Strings)
 	for _, storageParams := range args.Storage {
 		storagePools.Add(storageParams.Pool)
@@ -1261,6 +1268,7 @@ func (st *State) AddService(args AddServiceArgs) (service *Service, err error) {
 		// provisioning, we should check the given networks are valid
 		// and known before setting them.
 		createRequestedNetworksOp(st, svc.globalKey(), args.Networks),
+		createEndpointBindingsOp(st, svc.globalKey(), args.EndpointBindings),
 		createStorageConstraintsOp(svc.globalKey(), args.Storage),
 		createSettingsOp(svc.settingsKey(), map[string]interface{}(args.Settings)),
 		addLeadershipSettingsOp(svc.Tag().Id()),
@@ -1277,6 +1285,10 @@ func (st *State) AddService(args AddServiceArgs) (service *Service, err error) {
 			Id:     serviceID,
 			Assert: txn.DocMissing,
 			Insert: svcDoc,
+		}, {
+			C:      remoteServicesC,
+			Id:     serviceID,
+			Assert: txn.DocMissing,
 		},
 	}
 
@@ -1884,7 +1896,9 @@ func (st *State) endpoints(name string, filter func(ep Endpoint) bool) ([]Endpoi
 		return nil, errors.Errorf("invalid endpoint %q", name)
 	}
 	svc, err := st.Service(svcName)
-	if err != nil {
+	if errors.IsNotFound(err) {
+		return st.remoteEndpoints(svcName, relName, filter, err)
+	} else if err != nil {
 		return nil, errors.Trace(err)
 	}
 	eps := []Endpoint{}
@@ -1909,6 +1923,34 @@ func (st *State) endpoints(name string, filter func(ep Endpoint) bool) ([]Endpoi
 	return final, nil
 }
 
+// remoteEndpoints returns the endpoints of the named remote service
+// that could be intended by the supplied relation name, and which
+// cause the filter param to return true. If there is no such remote
+// service, notFound is returned.
+func (st *State) remoteEndpoints(svcName, relName string, filter func(ep Endpoint) bool, notFound error) ([]Endpoint, error) {
+	svc, err := st.RemoteService(svcName)
+	if errors.IsNotFound(err) {
+		return nil, errors.Trace(notFound)
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+	eps := svc.Endpoints()
+	if relName != "" {
+		ep, err := svc.Endpoint(relName)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		eps = []Endpoint{ep}
+	}
+	final := []Endpoint{}
+	for _, ep := range eps {
+		if filter(ep) {
+			final = append(final, ep)
+		}
+	}
+	return final, nil
+}
+
 // AddRelation creates a new relation with the given endpoints.
 func (st *State) AddRelation(eps ...Endpoint) (r *Relation, err error) {
 	key := relationKey(eps)
@@ -1948,12 +1990,24 @@ func (st *State) AddRelation(eps ...Endpoint) (r *Relation, err error) {
 		}
 		// Collect per-service operations, checking sanity as we go.
 		var ops []txn.Op
-		var subordinateCount int
+		var subordinateCount, remoteCount int
 		series := map[string]bool{}
 		for _, ep := range eps {
 			svc, err := st.Service(ep.ServiceName)
 			if errors.IsNotFound(err) {
-				return nil, errors.Errorf("service %q does not exist", ep.ServiceName)
+				remoteSvc, err := st.RemoteService(ep.ServiceName)
+				if errors.IsNotFound(err) {
+					return nil, errors.Errorf("service %q does not exist", ep.ServiceName)
+				} else if err != nil {
+					return nil, errors.Trace(err)
+				}
+				op, err := remoteSvc.relationAddedOp(ep)
+				if err != nil {
+					return nil, errors.Trace(err)
+				}
+				ops = append(ops, op)
+				remoteCount++
+				continue
 			} else if err != nil {
 				return nil, errors.Trace(err)
 			} else if svc.doc.Life != Alive {
@@ -1977,6 +2031,9 @@ func (st *State) AddRelation(eps ...Endpoint) (r *Relation, err error) {
 				Update: bson.D{{"$inc", bson.D{{"relationcount", 1}}}},
 			})
 		}
+		if remoteCount == len(eps) {
+			return nil, errors.Errorf("cannot relate remote services to each other")
+		}
 		if matchSeries && len(series) != 1 {
 			return nil, errors.Errorf("principal and subordinate services' series must match")
 		}
@@ -3989,6 +3989,111 @@ func (s *StateSuite) TestEnsureAvailabilityTo(c *gc.C) {
 	s.assertStateServerInfo(c, ids, ids, nil)
 }
 
+func (s *StateSuite) TestAddStateServerMembers(c *gc.C) {
+	s.PatchValue(state.StateServerAvailable, func(m *state.Machine) (bool, error) {
+		return true, nil
+	})
+	changes, err := s.State.EnsureAvailability(3, constraints.Value{}, "quantal", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(changes.Added, gc.HasLen, 3)
+	_, err = s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+
+	changes, err = s.State.AddStateServerMembers(
+		state.ReplicaSetHidden, constraints.Value{}, "quantal", []string{"3", "dr-site"},
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(changes.Converted, gc.DeepEquals, []string{"3"})
+	c.Assert(changes.Added, gc.DeepEquals, []string{"4"})
+	s.assertStateServerInfo(c,
+		[]string{"0", "1", "2", "3", "4"},
+		[]string{"0", "1", "2"},
+		[]string{"", "", "", "", "dr-site"})
+	for _, id := range []string{"3", "4"} {
+		m, err := s.State.Machine(id)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Check(m.IsManager(), jc.IsTrue)
+		c.Check(m.WantsVote(), jc.IsFalse)
+		c.Check(m.ReplicaSetRole(), gc.Equals, state.ReplicaSetHidden)
+	}
+
+	// The new members are never promoted to replace a voting
+	// state server that has become unavailable.
+	s.PatchValue(state.StateServerAvailable, func(m *state.Machine) (bool, error) {
+		return m.Id() != "0", nil
+	})
+	changes, err = s.State.EnsureAvailability(3, constraints.Value{}, "quantal", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(changes.Added, gc.DeepEquals, []string{"5"})
+	c.Assert(changes.Promoted, gc.HasLen, 0)
+	s.assertStateServerInfo(c,
+		[]string{"0", "1", "2", "3", "4", "5"},
+		[]string{"1", "2", "5"},
+		[]string{"", "", "", "", "dr-site"})
+}
+
+func (s *StateSuite) TestAddStateServerMembersErrors(c *gc.C) {
+	_, err := s.State.AddMachine("quantal", state.JobHostUnits, state.JobManageEnviron)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+
+	for i, test := range []struct {
+		role      state.ReplicaSetRole
+		placement []string
+		err       string
+	}{{
+		role:      state.ReplicaSetVoter,
+		placement: []string{"1"},
+		err:       "use EnsureAvailability to add voting state servers",
+	}, {
+		role:      "bogus",
+		placement: []string{"1"},
+		err:       `replica set role "bogus" not valid`,
+	}, {
+		role: state.ReplicaSetNonVoter,
+		err:  "no placement directives specified",
+	}, {
+		role:      state.ReplicaSetNonVoter,
+		placement: []string{"0"},
+		err:       `failed to add non-voting state server machines: machine for placement directive "0" is already a state server`,
+	}, {
+		role:      state.ReplicaSetNonVoter,
+		placement: []string{"1", "1"},
+		err:       `failed to add non-voting state server machines: machine for placement directive "1" specified more than once`,
+	}, {
+		role:      state.ReplicaSetNonVoter,
+		placement: []string{"1/lxc/0"},
+		err:       "failed to add non-voting state server machines: container placement directives not supported",
+	}} {
+		c.Logf("test %d: %q %v", i, test.role, test.placement)
+		_, err := s.State.AddStateServerMembers(test.role, constraints.Value{}, "quantal", test.placement)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+	s.assertStateServerInfo(c, []string{"0"}, []string{"0"}, nil)
+}
+
+func (s *StateSuite) TestAddMachineReplicaSetRole(c *gc.C) {
+	m, err := s.State.AddOneMachine(state.MachineTemplate{
+		Series:         "quantal",
+		Jobs:           []state.MachineJob{state.JobManageEnviron},
+		ReplicaSetRole: state.ReplicaSetNonVoter,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(m.WantsVote(), jc.IsFalse)
+	c.Assert(m.ReplicaSetRole(), gc.Equals, state.ReplicaSetNonVoter)
+	err = m.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(m.ReplicaSetRole(), gc.Equals, state.ReplicaSetNonVoter)
+
+	_, err = s.State.AddOneMachine(state.MachineTemplate{
+		Series:         "quantal",
+		Jobs:           []state.MachineJob{state.JobManageEnviron},
+		ReplicaSetRole: "bogus",
+	})
+	c.Assert(err, gc.ErrorMatches, `cannot add a new machine: replica set role "bogus" not valid`)
+}
+
 func newUint64(i uint64) *uint64 {
 	return &i
 }
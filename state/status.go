@@ -118,6 +118,10 @@ type setStatusParams struct {
 	token leadership.Token
 }
 
+// statusNow returns the time recorded with a status change, and the
+// time against which status history is pruned.
+var statusNow = time.Now
+
 // setStatus inteprets the supplied params as documented on the type.
 func setStatus(st *State, params setStatusParams) (err error) {
 	defer errors.DeferredAnnotatef(&err, "cannot set status")
@@ -126,7 +130,7 @@ func setStatus(st *State, params setStatusParams) (err error) {
 	// status was *set*, not the time it happened to arrive in state.
 	// We should almost certainly be accepting StatusInfo in the exposed
 	// SetStatus methods, for symetry with the Status methods.
-	now := time.Now().UnixNano()
+	now := statusNow().UnixNano()
 	doc := statusDoc{
 		Status:     params.status,
 		StatusInfo: params.message,
@@ -217,13 +221,59 @@ func probablyUpdateStatusHistory(st *State, globalKey string, doc statusDoc) {
 	}
 }
 
-func statusHistory(st *State, globalKey string, size int) ([]StatusInfo, error) {
+// StatusHistoryFilter restricts the entries returned by a status
+// history query. At least one of Size and FromDate must be set.
+type StatusHistoryFilter struct {
+	// Size, if non-zero, limits the result to the Size most recent
+	// matching entries.
+	Size int
+
+	// FromDate, if set, excludes entries recorded before it.
+	FromDate *time.Time
+
+	// ToDate, if set, excludes entries recorded after it.
+	ToDate *time.Time
+}
+
+// Validate returns an error if the filter is not valid.
+func (f StatusHistoryFilter) Validate() error {
+	if f.Size < 0 {
+		return errors.NotValidf("history size %d", f.Size)
+	}
+	if f.Size == 0 && f.FromDate == nil {
+		return errors.NotValidf("status history filter without size or start date")
+	}
+	if f.FromDate != nil && f.ToDate != nil && f.ToDate.Before(*f.FromDate) {
+		return errors.NotValidf("status history date range ending before it starts")
+	}
+	return nil
+}
+
+func statusHistory(st *State, globalKey string, filter StatusHistoryFilter) ([]StatusInfo, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
 	statusHistory, closer := st.getCollection(statusesHistoryC)
 	defer closer()
 
+	query := bson.D{{"globalkey", globalKey}}
+	updated := bson.M{}
+	if filter.FromDate != nil {
+		updated["$gte"] = filter.FromDate.UnixNano()
+	}
+	if filter.ToDate != nil {
+		updated["$lte"] = filter.ToDate.UnixNano()
+	}
+	if len(updated) > 0 {
+		query = append(query, bson.D{{"updated", updated}}...)
+	}
+
 	var docs []historicalStatusDoc
-	query := statusHistory.Find(bson.D{{"globalkey", globalKey}})
-	err := query.Sort("-updated").Limit(size).All(&docs)
+	q := statusHistory.Find(query).Sort("-updated")
+	if filter.Size > 0 {
+		q = q.Limit(filter.Size)
+	}
+	err := q.All(&docs)
 	if err == mgo.ErrNotFound {
 		return []StatusInfo{}, errors.NotFoundf("status history")
 	} else if err != nil {
@@ -242,14 +292,27 @@ func statusHistory(st *State, globalKey string, size int) ([]StatusInfo, error)
 	return results, nil
 }
 
-// PruneStatusHistory removes status history entries until
-// only the maxLogsPerEntity newest records per unit remain.
-func PruneStatusHistory(st *State, maxLogsPerEntity int) error {
+// PruneStatusHistory removes status history entries until only the
+// maxLogsPerEntity newest records per entity remain, and removes all
+// entries older than maxHistoryTime. A zero value for either limit
+// disables it.
+func PruneStatusHistory(st *State, maxLogsPerEntity int, maxHistoryTime time.Duration) error {
 	history, closer := st.getCollection(statusesHistoryC)
 	defer closer()
 
 	historyW := history.Writeable()
 
+	if maxHistoryTime > 0 {
+		keepFrom := statusNow().Add(-maxHistoryTime).UnixNano()
+		_, err := historyW.RemoveAll(bson.D{{"updated", bson.M{"$lt": keepFrom}}})
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if maxLogsPerEntity <= 0 {
+		return nil
+	}
+
 	// TODO(fwereade): This is a very strange implementation.
 	//
 	// It goes to a lot of effort to keep a *different* span of history for
@@ -155,3 +155,15 @@ func (s *FilesystemStatusSuite) TestSetStatusPendingProvisioned(c *gc.C) {
 	err = s.filesystem.SetStatus(state.StatusPending, "", nil)
 	c.Check(err, gc.ErrorMatches, `cannot set status "pending"`)
 }
+
+func (s *FilesystemStatusSuite) TestStatusHistory(c *gc.C) {
+	err := s.filesystem.SetStatus(state.StatusAttaching, "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.filesystem.SetStatus(state.StatusAttached, "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	history, err := s.State.FilesystemStatusHistory(s.filesystem.FilesystemTag(), state.StatusHistoryFilter{Size: 1})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(history, gc.HasLen, 1)
+	c.Check(history[0].Status, gc.Equals, state.StatusAttached)
+}
@@ -4,6 +4,9 @@
 package state_test
 
 import (
+	"time"
+
+	"github.com/juju/errors"
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
 
@@ -45,10 +48,10 @@ func (s *StatusHistorySuite) TestPruneStatusHistory(c *gc.C) {
 	primeUnitAgentStatusHistory(c, agents[1], 50)
 	primeUnitAgentStatusHistory(c, agents[2], 10)
 
-	err := state.PruneStatusHistory(s.State, 30)
+	err := state.PruneStatusHistory(s.State, 30, 0)
 	c.Assert(err, jc.ErrorIsNil)
 
-	history, err := units[0].StatusHistory(50)
+	history, err := units[0].StatusHistory(state.StatusHistoryFilter{Size: 50})
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(history, gc.HasLen, 11)
 	checkInitialWorkloadStatus(c, history[10])
@@ -56,35 +59,35 @@ func (s *StatusHistorySuite) TestPruneStatusHistory(c *gc.C) {
 		checkPrimedUnitStatus(c, statusInfo, 9-i)
 	}
 
-	history, err = units[1].StatusHistory(50)
+	history, err = units[1].StatusHistory(state.StatusHistoryFilter{Size: 50})
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(history, gc.HasLen, 30)
 	for i, statusInfo := range history {
 		checkPrimedUnitStatus(c, statusInfo, 49-i)
 	}
 
-	history, err = units[2].StatusHistory(50)
+	history, err = units[2].StatusHistory(state.StatusHistoryFilter{Size: 50})
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(history, gc.HasLen, 30)
 	for i, statusInfo := range history {
 		checkPrimedUnitStatus(c, statusInfo, 99-i)
 	}
 
-	history, err = agents[0].StatusHistory(50)
+	history, err = agents[0].StatusHistory(state.StatusHistoryFilter{Size: 50})
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(history, gc.HasLen, 30)
 	for i, statusInfo := range history {
 		checkPrimedUnitAgentStatus(c, statusInfo, 99-i)
 	}
 
-	history, err = agents[1].StatusHistory(50)
+	history, err = agents[1].StatusHistory(state.StatusHistoryFilter{Size: 50})
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(history, gc.HasLen, 30)
 	for i, statusInfo := range history {
 		checkPrimedUnitAgentStatus(c, statusInfo, 49-i)
 	}
 
-	history, err = agents[2].StatusHistory(50)
+	history, err = agents[2].StatusHistory(state.StatusHistoryFilter{Size: 50})
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(history, gc.HasLen, 11)
 	checkInitialUnitAgentStatus(c, history[10])
@@ -92,3 +95,98 @@ func (s *StatusHistorySuite) TestPruneStatusHistory(c *gc.C) {
 		checkPrimedUnitAgentStatus(c, statusInfo, 9-i)
 	}
 }
+
+// patchStatusClock makes each status change, and each prune, happen
+// one second after the last. It returns a function reporting the time
+// of the most recent one.
+func (s *StatusHistorySuite) patchStatusClock() func() time.Time {
+	now := time.Now()
+	s.PatchValue(state.StatusNow, func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	})
+	return func() time.Time { return now }
+}
+
+func (s *StatusHistorySuite) TestPruneStatusHistoryByAge(c *gc.C) {
+	unit := s.Factory.MakeUnit(c, nil)
+	s.patchStatusClock()
+	primeUnitStatusHistory(c, unit, 5)
+
+	// Nothing is removed while all entries are younger than the limit.
+	err := state.PruneStatusHistory(s.State, 0, time.Hour)
+	c.Assert(err, jc.ErrorIsNil)
+	history, err := unit.StatusHistory(state.StatusHistoryFilter{Size: 50})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(history, gc.HasLen, 6)
+
+	// Only the entries made in the last 3 seconds are kept.
+	err = state.PruneStatusHistory(s.State, 0, 3*time.Second)
+	c.Assert(err, jc.ErrorIsNil)
+	history, err = unit.StatusHistory(state.StatusHistoryFilter{Size: 50})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(history, gc.HasLen, 2)
+	for i, statusInfo := range history {
+		checkPrimedUnitStatus(c, statusInfo, 4-i)
+	}
+
+	err = state.PruneStatusHistory(s.State, 0, time.Nanosecond)
+	c.Assert(err, jc.ErrorIsNil)
+	history, err = unit.StatusHistory(state.StatusHistoryFilter{Size: 50})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(history, gc.HasLen, 0)
+}
+
+func (s *StatusHistorySuite) TestStatusHistoryDateRange(c *gc.C) {
+	unit := s.Factory.MakeUnit(c, nil)
+	last := s.patchStatusClock()
+	primeUnitStatusHistory(c, unit, 3)
+	from := last().Add(time.Second)
+	primeUnitStatusHistory(c, unit, 2)
+	to := last()
+	err := unit.SetStatus(state.StatusBlocked, "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	history, err := unit.StatusHistory(state.StatusHistoryFilter{FromDate: &from, ToDate: &to})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(history, gc.HasLen, 2)
+	for i, statusInfo := range history {
+		checkPrimedUnitStatus(c, statusInfo, 1-i)
+	}
+
+	history, err = unit.StatusHistory(state.StatusHistoryFilter{FromDate: &from, Size: 1})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(history, gc.HasLen, 1)
+	c.Check(history[0].Status, gc.Equals, state.StatusBlocked)
+}
+
+func (s *StatusHistorySuite) TestStatusHistoryFilterValidate(c *gc.C) {
+	now := time.Now()
+	earlier := now.Add(-time.Hour)
+	for i, test := range []struct {
+		filter state.StatusHistoryFilter
+		err    string
+	}{{
+		filter: state.StatusHistoryFilter{Size: 1},
+	}, {
+		filter: state.StatusHistoryFilter{FromDate: &earlier, ToDate: &now},
+	}, {
+		filter: state.StatusHistoryFilter{},
+		err:    "status history filter without size or start date not valid",
+	}, {
+		filter: state.StatusHistoryFilter{Size: -1},
+		err:    "history size -1 not valid",
+	}, {
+		filter: state.StatusHistoryFilter{FromDate: &now, ToDate: &earlier},
+		err:    "status history date range ending before it starts not valid",
+	}} {
+		c.Logf("test %d", i)
+		err := test.filter.Validate()
+		if test.err == "" {
+			c.Check(err, jc.ErrorIsNil)
+		} else {
+			c.Check(err, gc.ErrorMatches, test.err)
+			c.Check(err, jc.Satisfies, errors.IsNotValid)
+		}
+	}
+}
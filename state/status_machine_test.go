@@ -4,6 +4,8 @@
 package state_test
 
 import (
+	"time"
+
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
 
@@ -134,3 +136,40 @@ func (s *MachineStatusSuite) TestSetStatusPendingUnprovisioned(c *gc.C) {
 	err = machine.SetStatus(state.StatusPending, "", nil)
 	c.Check(err, jc.ErrorIsNil)
 }
+
+func (s *MachineStatusSuite) TestStatusHistory(c *gc.C) {
+	err := s.machine.SetStatus(state.StatusError, "provisioning failed", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.machine.SetStatus(state.StatusStarted, "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	history, err := s.machine.StatusHistory(state.StatusHistoryFilter{Size: 10})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(history, gc.HasLen, 3)
+	c.Check(history[0].Status, gc.Equals, state.StatusStarted)
+	c.Check(history[1].Status, gc.Equals, state.StatusError)
+	c.Check(history[1].Message, gc.Equals, "provisioning failed")
+	c.Check(history[2].Status, gc.Equals, state.StatusPending)
+}
+
+func (s *MachineStatusSuite) TestInstanceStatusHistory(c *gc.C) {
+	now := time.Date(2015, 10, 1, 12, 0, 0, 0, time.UTC)
+	s.PatchValue(state.StatusNow, func() time.Time { return now })
+	err := s.machine.SetInstanceStatus("pending")
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.machine.SetInstanceStatus("running")
+	c.Assert(err, jc.ErrorIsNil)
+
+	history, err := s.machine.InstanceStatusHistory(state.StatusHistoryFilter{Size: 10})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(history, gc.HasLen, 2)
+	c.Check(history[0].Status, gc.Equals, state.Status("running"))
+	c.Check(history[1].Status, gc.Equals, state.Status("pending"))
+	c.Check(history[0].Since.Equal(now), jc.IsTrue)
+
+	// The agent status history is kept separately.
+	history, err = s.machine.StatusHistory(state.StatusHistoryFilter{Size: 10})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(history, gc.HasLen, 1)
+	c.Check(history[0].Status, gc.Equals, state.StatusPending)
+}
@@ -12,7 +12,7 @@ import (
 // very clear reason.
 //
 // Status values currently apply to machine (agents), unit (agents), unit
-// (workloads), service (workloads), and volumes.
+// (workloads), service (workloads), volumes and filesystems.
 type Status string
 
 // StatusInfo holds a Status and associated information.
@@ -35,7 +35,7 @@ type StatusGetter interface {
 
 // StatusHistoryGetter instances can fetch their status history.
 type StatusHistoryGetter interface {
-	StatusHistory(size int) ([]StatusInfo, error)
+	StatusHistory(filter StatusHistoryFilter) ([]StatusInfo, error)
 }
 
 // Status values common to machine and unit agents.
@@ -136,6 +136,17 @@ const (
 	StatusActive Status = "active"
 )
 
+// Status values specific to unit health, reflecting the results of
+// the health checks declared by a unit's charm.
+const (
+
+	// The unit's health checks last passed.
+	StatusHealthy Status = "healthy"
+
+	// At least one of the unit's health checks last failed.
+	StatusUnhealthy Status = "unhealthy"
+)
+
 // Status values specific to storage.
 const (
 	// StatusAttaching indicates that the storage is being attached to a
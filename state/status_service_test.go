@@ -179,3 +179,17 @@ func (s *ServiceStatusSuite) TestServiceStatusOverridesDerivedStatus(c *gc.C) {
 	c.Check(err, jc.ErrorIsNil)
 	c.Check(info.Status, gc.Equals, state.StatusMaintenance)
 }
+
+func (s *ServiceStatusSuite) TestStatusHistory(c *gc.C) {
+	err := s.service.SetStatus(state.StatusMaintenance, "installing", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.service.SetStatus(state.StatusActive, "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	history, err := s.service.StatusHistory(state.StatusHistoryFilter{Size: 2})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(history, gc.HasLen, 2)
+	c.Check(history[0].Status, gc.Equals, state.StatusActive)
+	c.Check(history[1].Status, gc.Equals, state.StatusMaintenance)
+	c.Check(history[1].Message, gc.Equals, "installing")
+}
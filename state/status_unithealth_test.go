@@ -0,0 +1,127 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state"
+)
+
+type StatusUnitHealthSuite struct {
+	ConnSuite
+	unit   *state.Unit
+	health *state.UnitHealth
+}
+
+var _ = gc.Suite(&StatusUnitHealthSuite{})
+
+func (s *StatusUnitHealthSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	s.unit = s.Factory.MakeUnit(c, nil)
+	s.health = s.unit.Health()
+}
+
+func (s *StatusUnitHealthSuite) checkHealth(c *gc.C, status state.Status, message string) {
+	statusInfo, err := s.health.Status()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(statusInfo.Status, gc.Equals, status)
+	c.Check(statusInfo.Message, gc.Equals, message)
+}
+
+func (s *StatusUnitHealthSuite) checkWorkload(c *gc.C, status state.Status, message string) {
+	statusInfo, err := s.unit.Status()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(statusInfo.Status, gc.Equals, status)
+	c.Check(statusInfo.Message, gc.Equals, message)
+}
+
+func (s *StatusUnitHealthSuite) TestInitialStatus(c *gc.C) {
+	s.checkHealth(c, state.StatusUnknown, "")
+}
+
+func (s *StatusUnitHealthSuite) TestSetInvalidStatus(c *gc.C) {
+	err := s.health.SetStatus(state.StatusActive, "", nil)
+	c.Check(err, gc.ErrorMatches, `cannot set invalid status "active"`)
+	err = s.health.SetStatus(state.StatusUnhealthy, "", nil)
+	c.Check(err, gc.ErrorMatches, `cannot set status "unhealthy" without info`)
+	s.checkHealth(c, state.StatusUnknown, "")
+}
+
+func (s *StatusUnitHealthSuite) TestSetStatus(c *gc.C) {
+	err := s.health.SetStatus(state.StatusHealthy, "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	s.checkHealth(c, state.StatusHealthy, "")
+
+	err = s.health.SetStatus(state.StatusUnhealthy, `check "web" failed`, map[string]interface{}{
+		"failed": []interface{}{"web"},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	s.checkHealth(c, state.StatusUnhealthy, `check "web" failed`)
+
+	history, err := s.health.StatusHistory(state.StatusHistoryFilter{Size: 10})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(history, gc.HasLen, 2)
+	c.Check(history[0].Status, gc.Equals, state.StatusUnhealthy)
+	c.Check(history[1].Status, gc.Equals, state.StatusHealthy)
+}
+
+func (s *StatusUnitHealthSuite) TestUnhealthyBlocksActiveWorkload(c *gc.C) {
+	err := s.unit.SetStatus(state.StatusActive, "serving", nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.health.SetStatus(state.StatusUnhealthy, `check "web" failed`, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	s.checkWorkload(c, state.StatusBlocked, `health check failed: check "web" failed`)
+
+	err = s.health.SetStatus(state.StatusUnhealthy, `check "db" failed`, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	s.checkWorkload(c, state.StatusBlocked, `health check failed: check "db" failed`)
+
+	err = s.health.SetStatus(state.StatusHealthy, "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	s.checkWorkload(c, state.StatusActive, "serving")
+}
+
+func (s *StatusUnitHealthSuite) TestUnhealthyLeavesOtherWorkloadStatus(c *gc.C) {
+	err := s.unit.SetStatus(state.StatusMaintenance, "upgrading", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.health.SetStatus(state.StatusUnhealthy, `check "web" failed`, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	s.checkWorkload(c, state.StatusMaintenance, "upgrading")
+
+	err = s.unit.SetStatus(state.StatusBlocked, "needs a database", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.health.SetStatus(state.StatusHealthy, "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	s.checkWorkload(c, state.StatusBlocked, "needs a database")
+}
+
+func (s *StatusUnitHealthSuite) TestActiveWorkloadStaysBlockedWhileUnhealthy(c *gc.C) {
+	err := s.unit.SetStatus(state.StatusActive, "serving", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.health.SetStatus(state.StatusUnhealthy, `check "web" failed`, nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	// The charm setting its workload active does not clear the
+	// health check failure.
+	err = s.unit.SetStatus(state.StatusActive, "serving again", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	s.checkWorkload(c, state.StatusBlocked, `health check failed: check "web" failed`)
+
+	err = s.health.SetStatus(state.StatusHealthy, "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	s.checkWorkload(c, state.StatusActive, "serving again")
+}
+
+func (s *StatusUnitHealthSuite) TestSetStatusRemovedUnit(c *gc.C) {
+	err := s.unit.EnsureDead()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.unit.Remove()
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.health.SetStatus(state.StatusHealthy, "", nil)
+	c.Assert(err, gc.ErrorMatches, `cannot set status: unit "[^"]*" not found`)
+}
@@ -10,7 +10,7 @@ import (
 	"github.com/juju/juju/state"
 )
 
-type statusHistoryFunc func(int) ([]state.StatusInfo, error)
+type statusHistoryFunc func(state.StatusHistoryFilter) ([]state.StatusInfo, error)
 
 func checkInitialWorkloadStatus(c *gc.C, statusInfo state.StatusInfo) {
 	c.Check(statusInfo.Status, gc.Equals, state.StatusUnknown)
@@ -161,3 +161,17 @@ func (s *VolumeStatusSuite) TestSetStatusPendingProvisioned(c *gc.C) {
 	err = s.volume.SetStatus(state.StatusPending, "", nil)
 	c.Check(err, gc.ErrorMatches, `cannot set status "pending"`)
 }
+
+func (s *VolumeStatusSuite) TestStatusHistory(c *gc.C) {
+	err := s.volume.SetStatus(state.StatusAttaching, "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.volume.SetStatus(state.StatusError, "failed to attach", nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	history, err := s.State.VolumeStatusHistory(s.volume.VolumeTag(), state.StatusHistoryFilter{Size: 10})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(history, gc.HasLen, 2)
+	c.Check(history[0].Status, gc.Equals, state.StatusError)
+	c.Check(history[0].Message, gc.Equals, "failed to attach")
+	c.Check(history[1].Status, gc.Equals, state.StatusAttaching)
+}
@@ -339,7 +339,7 @@ func (u *Unit) eraseHistory() error {
 	if _, err := historyW.RemoveAll(bson.D{{"statusid", u.globalAgentKey()}}); err != nil {
 		return err
 	}
-	return nil
+	return u.eraseHookRecords()
 }
 
 // destroyOps returns the operations required to destroy the unit. If it
@@ -806,10 +806,10 @@ func (u *Unit) AgentStatus() (StatusInfo, error) {
 	return agent.Status()
 }
 
-// StatusHistory returns a slice of at most <size> StatusInfo items
-// representing past statuses for this unit.
-func (u *Unit) StatusHistory(size int) ([]StatusInfo, error) {
-	return statusHistory(u.st, u.globalKey(), size)
+// StatusHistory returns a slice of StatusInfo items matching the
+// filter and representing past statuses for this unit, newest first.
+func (u *Unit) StatusHistory(filter StatusHistoryFilter) ([]StatusInfo, error) {
+	return statusHistory(u.st, u.globalKey(), filter)
 }
 
 // Status returns the status of the unit.
@@ -844,6 +844,21 @@ func (u *Unit) SetStatus(status Status, info string, data map[string]interface{}
 	if !ValidWorkloadStatus(status) {
 		return errors.Errorf("cannot set invalid status %q", status)
 	}
+	if status == StatusActive {
+		// A workload cannot be made active while the unit's health
+		// checks fail; it stays blocked, and is made active with the
+		// given message once they pass.
+		health, err := u.Health().Status()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if health.Status == StatusUnhealthy {
+			status, info, data = StatusBlocked, healthCheckFailedMessage(health.Message), map[string]interface{}{
+				healthCheckDataKey:     true,
+				healthActiveMessageKey: info,
+			}
+		}
+	}
 	return setStatus(u.st, setStatusParams{
 		badge:     "unit",
 		globalKey: u.globalKey(),
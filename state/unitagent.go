@@ -75,10 +75,10 @@ func (u *UnitAgent) SetStatus(status Status, info string, data map[string]interf
 	})
 }
 
-// StatusHistory returns a slice of at most <size> StatusInfo items
-// representing past statuses for this agent.
-func (u *UnitAgent) StatusHistory(size int) ([]StatusInfo, error) {
-	return statusHistory(u.st, u.globalKey(), size)
+// StatusHistory returns a slice of StatusInfo items matching the
+// filter and representing past statuses for this agent, newest first.
+func (u *UnitAgent) StatusHistory(filter StatusHistoryFilter) ([]StatusInfo, error) {
+	return statusHistory(u.st, u.globalKey(), filter)
 }
 
 // unitAgentGlobalKey returns the global database key for the named unit.
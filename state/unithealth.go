@@ -0,0 +1,191 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	jujutxn "github.com/juju/txn"
+	"gopkg.in/mgo.v2/txn"
+)
+
+const (
+	// healthCheckDataKey marks a workload status set because the
+	// unit's health checks failed.
+	healthCheckDataKey = "health-check"
+
+	// healthActiveMessageKey holds the workload status message the
+	// charm last set while active, so it can be restored once the
+	// unit's health checks pass again.
+	healthActiveMessageKey = "active-message"
+)
+
+// UnitHealth represents the results of the health checks declared by
+// a unit's charm, as last reported by the unit agent.
+type UnitHealth struct {
+	st   *State
+	tag  names.Tag
+	name string
+}
+
+func newUnitHealth(st *State, tag names.Tag, name string) *UnitHealth {
+	return &UnitHealth{
+		st:   st,
+		tag:  tag,
+		name: name,
+	}
+}
+
+// Health returns the health of the unit.
+func (u *Unit) Health() *UnitHealth {
+	return newUnitHealth(u.st, u.Tag(), u.Name())
+}
+
+// String returns the unit health as string.
+func (u *UnitHealth) String() string {
+	return u.name
+}
+
+// Tag returns a names.Tag identifying this health's unit.
+func (u *UnitHealth) Tag() names.Tag {
+	return u.tag
+}
+
+// unitHealthGlobalKey returns the global database key for the health
+// of the named unit.
+func unitHealthGlobalKey(name string) string {
+	return "u#" + name + "#health"
+}
+
+// globalKey returns the global database key for the unit's health.
+func (u *UnitHealth) globalKey() string {
+	return unitHealthGlobalKey(u.name)
+}
+
+// Status returns the health of the unit. Units whose charm declares no
+// health checks, or whose checks have not yet run, have unknown health.
+func (u *UnitHealth) Status() (StatusInfo, error) {
+	info, err := getStatus(u.st, u.globalKey(), "health")
+	if errors.IsNotFound(err) {
+		return StatusInfo{
+			Status: StatusUnknown,
+			Data:   map[string]interface{}{},
+		}, nil
+	} else if err != nil {
+		return StatusInfo{}, errors.Trace(err)
+	}
+	return info, nil
+}
+
+// SetStatus records the results of the unit's health checks. While
+// the checks fail, an active workload is reported as blocked, so that
+// the failure shows up wherever workload status does, and the charm
+// cannot make it active again; the workload is made active again, with
+// the message the charm last set, once the checks pass.
+func (u *UnitHealth) SetStatus(status Status, info string, data map[string]interface{}) (err error) {
+	switch status {
+	case StatusHealthy, StatusUnknown:
+	case StatusUnhealthy:
+		if info == "" {
+			return errors.Errorf("cannot set status %q without info", status)
+		}
+	default:
+		return errors.Errorf("cannot set invalid status %q", status)
+	}
+	err = setStatus(u.st, setStatusParams{
+		badge:     "health",
+		globalKey: u.globalKey(),
+		status:    status,
+		message:   info,
+		rawData:   data,
+	})
+	if errors.IsNotFound(err) {
+		// The unit's health has never been set.
+		err = u.createStatus(status, info, data)
+	}
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return u.updateWorkloadStatus(status, info)
+}
+
+// createStatus creates the unit's health status document.
+func (u *UnitHealth) createStatus(status Status, info string, data map[string]interface{}) error {
+	doc := statusDoc{
+		EnvUUID:    u.st.EnvironUUID(),
+		Status:     status,
+		StatusInfo: info,
+		StatusData: escapeKeys(data),
+		Updated:    time.Now().UnixNano(),
+	}
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if attempt > 0 {
+			if _, err := u.st.Unit(u.name); err != nil {
+				return nil, errors.Trace(err)
+			}
+			if _, err := getStatus(u.st, u.globalKey(), "health"); err == nil {
+				// Created concurrently; the status reported
+				// most recently is as good as ours.
+				return nil, jujutxn.ErrNoOperations
+			}
+		}
+		return []txn.Op{{
+			C:      unitsC,
+			Id:     u.st.docID(u.name),
+			Assert: notDeadDoc,
+		}, createStatusOp(u.st, u.globalKey(), doc)}, nil
+	}
+	if err := u.st.run(buildTxn); err != nil {
+		return errors.Annotate(err, "cannot set status")
+	}
+	probablyUpdateStatusHistory(u.st, u.globalKey(), doc)
+	return nil
+}
+
+// updateWorkloadStatus reflects a change in the unit's health in its
+// workload status.
+func (u *UnitHealth) updateWorkloadStatus(status Status, info string) error {
+	unit, err := u.st.Unit(u.name)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	workload, err := getStatus(u.st, unit.globalKey(), "unit")
+	if err != nil {
+		return errors.Trace(err)
+	}
+	setByHealth, _ := workload.Data[healthCheckDataKey].(bool)
+	switch {
+	case status == StatusUnhealthy && workload.Status == StatusActive:
+		return unit.SetStatus(StatusBlocked, healthCheckFailedMessage(info), map[string]interface{}{
+			healthCheckDataKey:     true,
+			healthActiveMessageKey: workload.Message,
+		})
+	case status == StatusUnhealthy && workload.Status == StatusBlocked && setByHealth:
+		// Keep the message in step with the failing checks.
+		message := healthCheckFailedMessage(info)
+		if workload.Message == message {
+			return nil
+		}
+		return unit.SetStatus(StatusBlocked, message, workload.Data)
+	case status != StatusUnhealthy && workload.Status == StatusBlocked && setByHealth:
+		message, _ := workload.Data[healthActiveMessageKey].(string)
+		return unit.SetStatus(StatusActive, message, nil)
+	}
+	return nil
+}
+
+// healthCheckFailedMessage returns the workload status message for a
+// unit whose health checks failed as described by info.
+func healthCheckFailedMessage(info string) string {
+	return "health check failed: " + info
+}
+
+// StatusHistory returns a slice of StatusInfo items matching the
+// filter and representing past results of the unit's health checks,
+// newest first.
+func (u *UnitHealth) StatusHistory(filter StatusHistoryFilter) ([]StatusInfo, error) {
+	return statusHistory(u.st, u.globalKey(), filter)
+}
@@ -16,6 +16,7 @@ import (
 
 	"github.com/juju/errors"
 	"github.com/juju/names"
+	jujutxn "github.com/juju/txn"
 	"github.com/juju/utils"
 	"gopkg.in/mgo.v2"
 	"gopkg.in/mgo.v2/bson"
@@ -47,26 +48,44 @@ func (st *State) AddUser(name, displayName, password, creator string) (*User, er
 	if err != nil {
 		return nil, err
 	}
-	nameToLower := strings.ToLower(name)
+	return st.addUser(userDoc{
+		Name:         name,
+		DisplayName:  displayName,
+		PasswordHash: utils.UserPasswordHash(password, salt),
+		PasswordSalt: salt,
+		CreatedBy:    creator,
+	})
+}
+
+// AddDirectoryUser adds a user that authenticates against an external
+// directory to the database. Directory users have no password of
+// their own.
+func (st *State) AddDirectoryUser(name, displayName, creator string) (*User, error) {
+	if !names.IsValidUserName(name) {
+		return nil, errors.Errorf("invalid user name %q", name)
+	}
+	return st.addUser(userDoc{
+		Name:        name,
+		DisplayName: displayName,
+		Directory:   true,
+		CreatedBy:   creator,
+	})
+}
+
+func (st *State) addUser(doc userDoc) (*User, error) {
+	doc.DocID = strings.ToLower(doc.Name)
+	doc.DateCreated = nowToTheSecond()
 	user := &User{
-		st: st,
-		doc: userDoc{
-			DocID:        nameToLower,
-			Name:         name,
-			DisplayName:  displayName,
-			PasswordHash: utils.UserPasswordHash(password, salt),
-			PasswordSalt: salt,
-			CreatedBy:    creator,
-			DateCreated:  nowToTheSecond(),
-		},
+		st:  st,
+		doc: doc,
 	}
 	ops := []txn.Op{{
 		C:      usersC,
-		Id:     nameToLower,
+		Id:     doc.DocID,
 		Assert: txn.DocMissing,
 		Insert: &user.doc,
 	}}
-	err = st.runTransaction(ops)
+	err := st.runTransaction(ops)
 	if err == txn.ErrAborted {
 		err = errors.AlreadyExistsf("user")
 	}
@@ -167,6 +186,24 @@ type userDoc struct {
 	PasswordSalt string    `bson:"passwordsalt"`
 	CreatedBy    string    `bson:"createdby"`
 	DateCreated  time.Time `bson:"datecreated"`
+	// Directory users authenticate against an external directory
+	// rather than with a password.
+	Directory bool `bson:"directory,omitempty"`
+	// DirectoryEnvironments holds the UUIDs of the environments a
+	// directory user has been added to automatically. They are not
+	// added to them again, so that removing them sticks.
+	DirectoryEnvironments []string `bson:"directoryenvironments,omitempty"`
+	// PasswordChanged holds when the password was last set. It is
+	// not set for users whose password has not changed since they
+	// were created.
+	PasswordChanged time.Time `bson:"passwordchanged,omitempty"`
+	// FailedLogins holds the times of recent failed logins, which
+	// count towards locking the user out.
+	FailedLogins []time.Time `bson:"failedlogins,omitempty"`
+	// LockedOutUntil is set when the user has been locked out after
+	// too many failed logins. Locked out users cannot log in until the
+	// lockout window has passed or they are enabled again.
+	LockedOutUntil time.Time `bson:"lockedoutuntil,omitempty"`
 }
 
 type userLastLoginDoc struct {
@@ -196,6 +233,12 @@ func (u *User) DisplayName() string {
 	return u.doc.DisplayName
 }
 
+// IsDirectoryUser returns whether the User authenticates against an
+// external directory rather than with a password.
+func (u *User) IsDirectoryUser() bool {
+	return u.doc.Directory
+}
+
 // CreatedBy returns the name of the User that created this User.
 func (u *User) CreatedBy() string {
 	return u.doc.CreatedBy
@@ -300,20 +343,46 @@ func (u *User) SetPassword(password string) error {
 
 // SetPasswordHash stores the hash and the salt of the password.
 func (u *User) SetPasswordHash(pwHash string, pwSalt string) error {
+	now := nowToTheSecond()
 	ops := []txn.Op{{
 		C:      usersC,
 		Id:     u.Name(),
 		Assert: txn.DocExists,
-		Update: bson.D{{"$set", bson.D{{"passwordhash", pwHash}, {"passwordsalt", pwSalt}}}},
+		Update: bson.D{{"$set", bson.D{
+			{"passwordhash", pwHash},
+			{"passwordsalt", pwSalt},
+			{"passwordchanged", now},
+		}}},
 	}}
 	if err := u.st.runTransaction(ops); err != nil {
 		return errors.Annotatef(err, "cannot set password of user %q", u.Name())
 	}
 	u.doc.PasswordHash = pwHash
 	u.doc.PasswordSalt = pwSalt
+	u.doc.PasswordChanged = now
 	return nil
 }
 
+// PasswordChanged returns when the password of the User was last set,
+// in UTC. For users whose password has not been set since they were
+// created, this is when they were created.
+func (u *User) PasswordChanged() time.Time {
+	if u.doc.PasswordChanged.IsZero() {
+		return u.DateCreated()
+	}
+	return u.doc.PasswordChanged.UTC()
+}
+
+// PasswordExpired returns whether the password of the User was set more
+// than maxAge before the given time. Passwords never expire if maxAge
+// is zero. Directory users have no password, so theirs never expire.
+func (u *User) PasswordExpired(maxAge time.Duration, now time.Time) bool {
+	if maxAge <= 0 || u.doc.Directory {
+		return false
+	}
+	return now.Sub(u.PasswordChanged()) > maxAge
+}
+
 // PasswordValid returns whether the given password is valid for the User.
 func (u *User) PasswordValid(password string) bool {
 	// If the User is deactivated, no point in carrying on. Since any
@@ -324,6 +393,16 @@ func (u *User) PasswordValid(password string) bool {
 	if u.IsDisabled() {
 		return false
 	}
+	// Nor is there any point if the user has been locked out; they
+	// must wait for the lockout to expire or be enabled again first.
+	if u.IsLockedOut() {
+		return false
+	}
+	// Directory users have no password; the directory checks their
+	// credentials.
+	if u.doc.Directory {
+		return false
+	}
 	if u.doc.PasswordSalt != "" {
 		return utils.UserPasswordHash(password, u.doc.PasswordSalt) == u.doc.PasswordHash
 	}
@@ -366,17 +445,25 @@ func (u *User) Disable() error {
 	return errors.Annotatef(u.setDeactivated(true), "cannot disable user %q", u.Name())
 }
 
-// Enable reactivates the user, setting disabled to false.
+// Enable reactivates the user, setting disabled to false. It also
+// clears any lockout after failed logins.
 func (u *User) Enable() error {
 	return errors.Annotatef(u.setDeactivated(false), "cannot enable user %q", u.Name())
 }
 
 func (u *User) setDeactivated(value bool) error {
+	update := bson.D{{"$set", bson.D{{"deactivated", value}}}}
+	if !value {
+		update = append(update, bson.DocElem{"$unset", bson.D{
+			{"lockedoutuntil", nil},
+			{"failedlogins", nil},
+		}})
+	}
 	ops := []txn.Op{{
 		C:      usersC,
 		Id:     u.Name(),
 		Assert: txn.DocExists,
-		Update: bson.D{{"$set", bson.D{{"deactivated", value}}}},
+		Update: update,
 	}}
 	if err := u.st.runTransaction(ops); err != nil {
 		if err == txn.ErrAborted {
@@ -385,6 +472,10 @@ func (u *User) setDeactivated(value bool) error {
 		return err
 	}
 	u.doc.Deactivated = value
+	if !value {
+		u.doc.LockedOutUntil = time.Time{}
+		u.doc.FailedLogins = nil
+	}
 	return nil
 }
 
@@ -395,6 +486,101 @@ func (u *User) IsDisabled() bool {
 	return u.doc.Deactivated
 }
 
+// IsLockedOut returns whether the user has been locked out after too
+// many failed logins, and the lockout has not yet expired.
+func (u *User) IsLockedOut() bool {
+	return u.doc.LockedOutUntil.After(time.Now())
+}
+
+// RecordFailedLogin records a failed login by the user. If this makes
+// attempts failed logins within the given window, the user is locked
+// out for the length of the window, or until they are enabled again.
+// It returns whether the user is locked out after the failed login.
+// Failed logins are not recorded if attempts is zero, as users are
+// then never locked out.
+func (u *User) RecordFailedLogin(attempts int, window time.Duration) (bool, error) {
+	if attempts <= 0 {
+		return u.IsLockedOut(), nil
+	}
+	var lockedOut bool
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if attempt > 0 {
+			if err := u.Refresh(); err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
+		if u.IsLockedOut() {
+			lockedOut = true
+			return nil, jujutxn.ErrNoOperations
+		}
+		now := nowToTheSecond()
+		var failures []time.Time
+		for _, t := range u.doc.FailedLogins {
+			if now.Sub(t) < window {
+				failures = append(failures, t.UTC())
+			}
+		}
+		failures = append(failures, now)
+		if len(failures) > attempts {
+			failures = failures[len(failures)-attempts:]
+		}
+		// An expired lockout is replaced, or cleared along with the
+		// failures that caused it, which are all outside the window.
+		var lockedOutUntil interface{} = bson.D{{"$exists", false}}
+		if !u.doc.LockedOutUntil.IsZero() {
+			lockedOutUntil = u.doc.LockedOutUntil
+		}
+		lockedOut = len(failures) >= attempts
+		var update bson.D
+		if lockedOut {
+			update = bson.D{{"$set", bson.D{
+				{"failedlogins", failures},
+				{"lockedoutuntil", now.Add(window)},
+			}}}
+		} else {
+			update = bson.D{
+				{"$set", bson.D{{"failedlogins", failures}}},
+				{"$unset", bson.D{{"lockedoutuntil", nil}}},
+			}
+		}
+		return []txn.Op{{
+			C:  usersC,
+			Id: u.doc.DocID,
+			Assert: bson.D{
+				{"lockedoutuntil", lockedOutUntil},
+				{"failedlogins", u.doc.FailedLogins},
+			},
+			Update: update,
+		}}, nil
+	}
+	if err := u.st.run(buildTxn); err != nil {
+		return false, errors.Annotatef(err, "cannot record failed login of user %q", u.Name())
+	}
+	if err := u.Refresh(); err != nil {
+		return false, errors.Trace(err)
+	}
+	return lockedOut, nil
+}
+
+// ResetFailedLogins forgets the failed logins recorded for the user,
+// as is done when they log in successfully.
+func (u *User) ResetFailedLogins() error {
+	if len(u.doc.FailedLogins) == 0 {
+		return nil
+	}
+	ops := []txn.Op{{
+		C:      usersC,
+		Id:     u.doc.DocID,
+		Assert: txn.DocExists,
+		Update: bson.D{{"$unset", bson.D{{"failedlogins", nil}}}},
+	}}
+	if err := u.st.runTransaction(ops); err != nil {
+		return errors.Annotatef(err, "cannot reset failed logins of user %q", u.Name())
+	}
+	u.doc.FailedLogins = nil
+	return nil
+}
+
 // userList type is used to provide the methods for sorting.
 type userList []*User
 
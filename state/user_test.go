@@ -75,6 +75,37 @@ func (s *UserSuite) TestAddUser(c *gc.C) {
 	c.Assert(lastLogin, gc.DeepEquals, time.Time{})
 }
 
+func (s *UserSuite) TestAddDirectoryUser(c *gc.C) {
+	user, err := s.State.AddDirectoryUser("bob", "Bob Brown", "admin")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(user.Name(), gc.Equals, "bob")
+	c.Assert(user.DisplayName(), gc.Equals, "Bob Brown")
+	c.Assert(user.CreatedBy(), gc.Equals, "admin")
+	c.Assert(user.IsDirectoryUser(), jc.IsTrue)
+	c.Assert(user.PasswordValid(""), jc.IsFalse)
+
+	user, err = s.State.User(user.UserTag())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(user.IsDirectoryUser(), jc.IsTrue)
+
+	// Directory users cannot log in with a password, even if one
+	// is set.
+	err = user.SetPassword("password")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(user.PasswordValid("password"), jc.IsFalse)
+}
+
+func (s *UserSuite) TestAddDirectoryUserExists(c *gc.C) {
+	s.Factory.MakeUser(c, &factory.UserParams{Name: "bob"})
+	_, err := s.State.AddDirectoryUser("bob", "", "admin")
+	c.Assert(err, jc.Satisfies, errors.IsAlreadyExists)
+}
+
+func (s *UserSuite) TestAddUserNotDirectoryUser(c *gc.C) {
+	user := s.Factory.MakeUser(c, nil)
+	c.Assert(user.IsDirectoryUser(), jc.IsFalse)
+}
+
 func (s *UserSuite) TestCheckUserExists(c *gc.C) {
 	user := s.Factory.MakeUser(c, nil)
 	exists, err := state.CheckUserExists(s.State, user.Name())
@@ -144,6 +175,97 @@ func (s *UserSuite) TestDisable(c *gc.C) {
 	c.Assert(user.PasswordValid("a-password"), jc.IsTrue)
 }
 
+func (s *UserSuite) TestPasswordExpired(c *gc.C) {
+	user := s.Factory.MakeUser(c, &factory.UserParams{Password: "a-password"})
+	now := time.Now()
+	c.Assert(user.PasswordChanged(), gc.Equals, user.DateCreated())
+	c.Assert(user.PasswordExpired(0, now.Add(time.Hour)), jc.IsFalse)
+	c.Assert(user.PasswordExpired(time.Hour, now), jc.IsFalse)
+	c.Assert(user.PasswordExpired(time.Hour, now.Add(2*time.Hour)), jc.IsTrue)
+
+	err := user.SetPassword("another-password")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(user.PasswordChanged().Before(user.DateCreated()), jc.IsFalse)
+	err = user.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(user.PasswordExpired(time.Hour, user.PasswordChanged().Add(30*time.Minute)), jc.IsFalse)
+	c.Assert(user.PasswordExpired(time.Hour, user.PasswordChanged().Add(2*time.Hour)), jc.IsTrue)
+}
+
+func (s *UserSuite) TestRecordFailedLoginLocksOut(c *gc.C) {
+	user := s.Factory.MakeUser(c, &factory.UserParams{Password: "a-password"})
+	for i := 0; i < 2; i++ {
+		lockedOut, err := user.RecordFailedLogin(3, time.Hour)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Assert(lockedOut, jc.IsFalse)
+	}
+	c.Assert(user.PasswordValid("a-password"), jc.IsTrue)
+
+	lockedOut, err := user.RecordFailedLogin(3, time.Hour)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(lockedOut, jc.IsTrue)
+	c.Assert(user.IsLockedOut(), jc.IsTrue)
+	c.Assert(user.PasswordValid("a-password"), jc.IsFalse)
+
+	// The lockout is recorded in the database.
+	user, err = s.State.User(user.UserTag())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(user.IsLockedOut(), jc.IsTrue)
+
+	// Enabling the user clears the lockout.
+	err = user.Enable()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(user.IsLockedOut(), jc.IsFalse)
+	c.Assert(user.PasswordValid("a-password"), jc.IsTrue)
+	err = user.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(user.IsLockedOut(), jc.IsFalse)
+	lockedOut, err = user.RecordFailedLogin(3, time.Hour)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(lockedOut, jc.IsFalse)
+}
+
+func (s *UserSuite) TestRecordFailedLoginLockoutExpires(c *gc.C) {
+	user := s.Factory.MakeUser(c, &factory.UserParams{Password: "a-password"})
+	// With such a short window, the lockout has expired by the time
+	// it is read back.
+	lockedOut, err := user.RecordFailedLogin(1, time.Nanosecond)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(lockedOut, jc.IsTrue)
+	c.Assert(user.IsLockedOut(), jc.IsFalse)
+	c.Assert(user.PasswordValid("a-password"), jc.IsTrue)
+
+	// Failing again after the lockout has expired locks the user out
+	// afresh.
+	lockedOut, err = user.RecordFailedLogin(1, time.Hour)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(lockedOut, jc.IsTrue)
+	c.Assert(user.IsLockedOut(), jc.IsTrue)
+	c.Assert(user.PasswordValid("a-password"), jc.IsFalse)
+}
+
+func (s *UserSuite) TestRecordFailedLoginNoLockout(c *gc.C) {
+	user := s.Factory.MakeUser(c, nil)
+	for i := 0; i < 5; i++ {
+		lockedOut, err := user.RecordFailedLogin(0, time.Hour)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Assert(lockedOut, jc.IsFalse)
+	}
+}
+
+func (s *UserSuite) TestResetFailedLogins(c *gc.C) {
+	user := s.Factory.MakeUser(c, nil)
+	for i := 0; i < 2; i++ {
+		_, err := user.RecordFailedLogin(3, time.Hour)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	err := user.ResetFailedLogins()
+	c.Assert(err, jc.ErrorIsNil)
+	lockedOut, err := user.RecordFailedLogin(3, time.Hour)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(lockedOut, jc.IsFalse)
+}
+
 func (s *UserSuite) TestSetPasswordHash(c *gc.C) {
 	user := s.Factory.MakeUser(c, nil)
 
@@ -1030,6 +1030,13 @@ func (st *State) VolumeStatus(tag names.VolumeTag) (StatusInfo, error) {
 	return getStatus(st, volumeGlobalKey(tag.Id()), "volume")
 }
 
+// VolumeStatusHistory returns a slice of StatusInfo items matching the
+// filter and representing past statuses of the specified volume,
+// newest first.
+func (st *State) VolumeStatusHistory(tag names.VolumeTag, filter StatusHistoryFilter) ([]StatusInfo, error) {
+	return statusHistory(st, volumeGlobalKey(tag.Id()), filter)
+}
+
 // SetVolumeStatus sets the status of the specified volume.
 func (st *State) SetVolumeStatus(tag names.VolumeTag, status Status, info string, data map[string]interface{}) error {
 	switch status {
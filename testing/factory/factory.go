@@ -55,6 +55,7 @@ type EnvUserParams struct {
 	DisplayName string
 	CreatedBy   names.Tag
 	ReadOnly    bool
+	Access      state.EnvUserAccess
 }
 
 // CharmParams defines the parameters for creating a charm.
@@ -204,6 +205,7 @@ func (factory *Factory) MakeEnvUser(c *gc.C, params *EnvUserParams) *state.Envir
 		CreatedBy:   createdByUserTag,
 		DisplayName: params.DisplayName,
 		ReadOnly:    params.ReadOnly,
+		Access:      params.Access,
 	})
 	c.Assert(err, jc.ErrorIsNil)
 	return envUser
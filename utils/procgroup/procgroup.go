@@ -0,0 +1,45 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// procgroup runs commands in process groups of their own, so that a
+// command that runs for too long can be killed along with any children
+// it has started.
+package procgroup
+
+import (
+	"os/exec"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+)
+
+var logger = loggo.GetLogger("juju.utils.procgroup")
+
+// ErrTimeout is returned by Wait when the command was killed for running
+// longer than its timeout.
+var ErrTimeout = errors.New("timed out")
+
+// Wait waits for the started command to finish. If a positive timeout
+// is given and the command runs for longer than that, the command and
+// the rest of its process group are killed and ErrTimeout is returned.
+// The command should have been prepared with Set before it was started.
+func Wait(ps *exec.Cmd, timeout time.Duration) error {
+	if timeout <= 0 {
+		return ps.Wait()
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- ps.Wait()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(timeout):
+	}
+	if err := Kill(ps.Process); err != nil {
+		logger.Warningf("cannot kill process %d: %v", ps.Process.Pid, err)
+	}
+	<-done
+	return ErrTimeout
+}
@@ -0,0 +1,65 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// +build !windows
+
+package procgroup_test
+
+import (
+	"os/exec"
+	"time"
+
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/utils/procgroup"
+)
+
+type procgroupSuite struct {
+	testing.IsolationSuite
+}
+
+var _ = gc.Suite(&procgroupSuite{})
+
+func (s *procgroupSuite) start(c *gc.C, command string) *exec.Cmd {
+	ps := exec.Command("/bin/sh", "-c", command)
+	procgroup.Set(ps)
+	c.Assert(ps.Start(), jc.ErrorIsNil)
+	return ps
+}
+
+func (s *procgroupSuite) TestWaitFinished(c *gc.C) {
+	ps := s.start(c, "exit 0")
+	c.Assert(procgroup.Wait(ps, time.Minute), jc.ErrorIsNil)
+}
+
+func (s *procgroupSuite) TestWaitFailed(c *gc.C) {
+	ps := s.start(c, "exit 3")
+	c.Assert(procgroup.Wait(ps, time.Minute), gc.ErrorMatches, "exit status 3")
+}
+
+func (s *procgroupSuite) TestWaitNoTimeout(c *gc.C) {
+	ps := s.start(c, "sleep 0.1")
+	c.Assert(procgroup.Wait(ps, 0), jc.ErrorIsNil)
+}
+
+func (s *procgroupSuite) TestWaitTimeoutKillsGroup(c *gc.C) {
+	// The child keeps the output pipe open, so Wait only returns once
+	// the whole group has been killed.
+	ps := exec.Command("/bin/sh", "-c", "sleep 60 & sleep 60")
+	ps.Stdout = &nullWriter{}
+	procgroup.Set(ps)
+	c.Assert(ps.Start(), jc.ErrorIsNil)
+
+	start := time.Now()
+	err := procgroup.Wait(ps, 100*time.Millisecond)
+	c.Assert(err, gc.Equals, procgroup.ErrTimeout)
+	c.Assert(time.Since(start) < 30*time.Second, jc.IsTrue)
+}
+
+type nullWriter struct{}
+
+func (*nullWriter) Write(p []byte) (int, error) {
+	return len(p), nil
+}
@@ -0,0 +1,24 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// +build !windows
+
+package procgroup
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// Set makes the command run in a new process group, led by the
+// command's process.
+func Set(ps *exec.Cmd) {
+	ps.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// Kill kills the process and every other process in the process group
+// it leads.
+func Kill(p *os.Process) error {
+	return syscall.Kill(-p.Pid, syscall.SIGKILL)
+}
@@ -0,0 +1,18 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package procgroup
+
+import (
+	"os"
+	"os/exec"
+)
+
+// Set does nothing on Windows, where processes are killed
+// individually.
+func Set(ps *exec.Cmd) {}
+
+// Kill kills the process. Its children are left running.
+func Kill(p *os.Process) error {
+	return p.Kill()
+}
@@ -0,0 +1,76 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package caupdater
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+
+	"github.com/juju/juju/api/watcher"
+	"github.com/juju/juju/worker"
+)
+
+var logger = loggo.GetLogger("juju.worker.caupdater")
+
+// CACertUpdater is responsible for propagating the controller's CA
+// certificates.
+//
+// In practice, CACertUpdater is used by machine and unit agents to
+// watch the controller's CA certificates and write them to the agent's
+// config file, so that the agent keeps trusting the API and state
+// servers while their CA is rotated.
+type CACertUpdater struct {
+	getter CACertGetter
+	setter CACertSetter
+}
+
+// CACertGetter is an interface that is provided to NewCACertUpdater
+// which can be used to watch for CA certificate changes.
+type CACertGetter interface {
+	CACert() (string, error)
+	WatchCACert() (watcher.NotifyWatcher, error)
+}
+
+// CACertSetter is an interface that is provided to NewCACertUpdater
+// whose SetCACert method will be invoked whenever the CA certificates
+// change.
+type CACertSetter interface {
+	SetCACert(caCert string) error
+}
+
+// NewCACertUpdater returns a worker.Worker that watches for changes to
+// the controller's CA certificates and then sets them on the
+// CACertSetter.
+func NewCACertUpdater(getter CACertGetter, setter CACertSetter) worker.Worker {
+	return worker.NewNotifyWorker(&CACertUpdater{
+		getter: getter,
+		setter: setter,
+	})
+}
+
+// SetUp is defined on the NotifyWatchHandler interface.
+func (c *CACertUpdater) SetUp() (watcher.NotifyWatcher, error) {
+	return c.getter.WatchCACert()
+}
+
+// Handle is defined on the NotifyWatchHandler interface.
+func (c *CACertUpdater) Handle(_ <-chan struct{}) error {
+	caCert, err := c.getter.CACert()
+	if err != nil {
+		return errors.Annotate(err, "cannot get CA certificate")
+	}
+	if caCert == "" {
+		logger.Warningf("controller returned no CA certificate, ignoring")
+		return nil
+	}
+	if err := c.setter.SetCACert(caCert); err != nil {
+		return errors.Annotate(err, "cannot set CA certificate")
+	}
+	return nil
+}
+
+// TearDown is defined on the NotifyWatchHandler interface.
+func (c *CACertUpdater) TearDown() error {
+	return nil
+}
@@ -0,0 +1,81 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package caupdater_test
+
+import (
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker/caupdater"
+)
+
+type CACertUpdaterSuite struct {
+	jujutesting.JujuConnSuite
+}
+
+var _ = gc.Suite(&CACertUpdaterSuite{})
+
+type caCertSetter struct {
+	caCerts chan string
+	err     error
+}
+
+func (s *caCertSetter) SetCACert(caCert string) error {
+	s.caCerts <- caCert
+	return s.err
+}
+
+func (s *CACertUpdaterSuite) TestStartStop(c *gc.C) {
+	st, _ := s.OpenAPIAsNewMachine(c, state.JobHostUnits)
+	worker := caupdater.NewCACertUpdater(st.Machiner(), &caCertSetter{})
+	worker.Kill()
+	c.Assert(worker.Wait(), gc.IsNil)
+}
+
+func (s *CACertUpdaterSuite) TestCACertInitialUpdate(c *gc.C) {
+	setter := &caCertSetter{caCerts: make(chan string, 1)}
+	st, _ := s.OpenAPIAsNewMachine(c, state.JobHostUnits)
+	worker := caupdater.NewCACertUpdater(st.Machiner(), setter)
+	defer func() { c.Assert(worker.Wait(), gc.IsNil) }()
+	defer worker.Kill()
+
+	select {
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for SetCACert to be called")
+	case caCert := <-setter.caCerts:
+		c.Assert(caCert, gc.Equals, s.State.CACert())
+	}
+}
+
+func (s *CACertUpdaterSuite) TestCACertRotated(c *gc.C) {
+	setter := &caCertSetter{caCerts: make(chan string, 1)}
+	st, _ := s.OpenAPIAsNewMachine(c, state.JobHostUnits)
+	worker := caupdater.NewCACertUpdater(st.Machiner(), setter)
+	defer func() { c.Assert(worker.Wait(), gc.IsNil) }()
+	defer worker.Kill()
+
+	// Wait for the initial update.
+	select {
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for SetCACert to be called initially")
+	case <-setter.caCerts:
+	}
+
+	originalCACert := s.State.CACert()
+	err := s.State.RotateControllerCertificates(coretesting.OtherCACert, coretesting.OtherCAKey, time.Hour)
+	c.Assert(err, jc.ErrorIsNil)
+
+	// Both CAs should now be trusted.
+	select {
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for SetCACert to be called after rotation")
+	case caCert := <-setter.caCerts:
+		c.Assert(caCert, gc.Equals, originalCACert+coretesting.OtherCACert)
+	}
+}
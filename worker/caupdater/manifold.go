@@ -0,0 +1,37 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package caupdater
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/agent"
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/api/uniter"
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/dependency"
+	"github.com/juju/juju/worker/util"
+)
+
+// ManifoldConfig defines the names of the manifolds on which a Manifold will depend.
+type ManifoldConfig util.AgentApiManifoldConfig
+
+// Manifold returns a dependency manifold that runs a CA certificate updater
+// worker, using the resource names defined in the supplied config.
+func Manifold(config ManifoldConfig) dependency.Manifold {
+	return util.AgentApiManifold(util.AgentApiManifoldConfig(config), newWorker)
+}
+
+// newWorker trivially wraps NewCACertUpdater for use in a util.AgentApiManifold.
+// Like the API address updater, it uses the uniter facade, because that is
+// what unit agents have access to.
+var newWorker = func(a agent.Agent, apiCaller base.APICaller) (worker.Worker, error) {
+	tag := a.CurrentConfig().Tag()
+	unitTag, ok := tag.(names.UnitTag)
+	if !ok {
+		return nil, errors.Errorf("expected a unit tag; got %q", tag)
+	}
+	return NewCACertUpdater(uniter.NewState(apiCaller, unitTag), agent.CACertSetter{a}), nil
+}
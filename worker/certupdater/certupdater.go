@@ -5,17 +5,18 @@ package certupdater
 
 import (
 	"reflect"
+	"time"
 
 	"github.com/juju/errors"
 	"github.com/juju/loggo"
 	"github.com/juju/utils/set"
+	"launchpad.net/tomb"
 
-	"github.com/juju/juju/api/watcher"
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/cert"
-	"github.com/juju/juju/environs/config"
 	"github.com/juju/juju/network"
 	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/watcher"
 	"github.com/juju/juju/worker"
 )
 
@@ -25,27 +26,40 @@ var logger = loggo.GetLogger("juju.worker.certupdater")
 //
 // In practice, CertificateUpdater is used by a state server's machine agent to watch
 // that server's machines addresses in state, and write a new certificate to the
-// agent's config file.
+// agent's config file. It also replaces the certificate when rotation is requested,
+// when the controller's CA changes, and when the certificate nears its expiry.
 type CertificateUpdater struct {
+	tomb            tomb.Tomb
 	addressWatcher  AddressWatcher
 	getter          StateServingInfoGetter
 	setter          StateServingInfoSetter
-	configGetter    EnvironConfigGetter
+	certsAccessor   CertificatesAccessor
 	hostPortsGetter APIHostPortsGetter
 	addresses       []network.Address
+
+	// switchTime is when a new CA, trusted but not yet signing
+	// server certificates, takes over signing them. It is zero if
+	// no CA rotation is in progress.
+	switchTime time.Time
 }
 
 // AddressWatcher is an interface that is provided to NewCertificateUpdater
 // which can be used to watch for machine address changes.
 type AddressWatcher interface {
+	Id() string
 	WatchAddresses() state.NotifyWatcher
 	Addresses() (addresses []network.Address)
 }
 
-// EnvironConfigGetter is an interface that is provided to NewCertificateUpdater
-// which can be used to get environment config.
-type EnvironConfigGetter interface {
-	EnvironConfig() (*config.Config, error)
+// CertificatesAccessor is an interface that is provided to NewCertificateUpdater
+// which can be used to get and watch the controller's CA certificates, to get
+// the CA that signs server certificates, and to record the expiry of the
+// certificate in use.
+type CertificatesAccessor interface {
+	ControllerCertificates() (state.ControllerCertificates, error)
+	ControllerSigningCA(now time.Time) (caCert, caPrivateKey string, err error)
+	WatchControllerCertificates() state.NotifyWatcher
+	SetServerCertificateExpiry(machineId string, expiry time.Time) error
 }
 
 // StateServingInfoGetter is an interface that is provided to NewCertificateUpdater
@@ -64,27 +78,52 @@ type APIHostPortsGetter interface {
 	APIHostPorts() ([][]network.HostPort, error)
 }
 
+var (
+	// CheckInterval is how often the certificate is checked for
+	// expiry, and for a new CA taking over signing, when nothing
+	// else has changed.
+	CheckInterval = time.Hour
+
+	// RenewBefore is how long before its expiry a certificate is
+	// replaced.
+	RenewBefore = 30 * 24 * time.Hour
+)
+
 // NewCertificateUpdater returns a worker.Worker that watches for changes to
-// machine addresses and then generates a new state server certificate with those
-// addresses in the certificate's SAN value.
+// machine addresses and the controller's certificates, and then generates a new
+// state server certificate with those addresses in the certificate's SAN value.
 func NewCertificateUpdater(addressWatcher AddressWatcher, getter StateServingInfoGetter,
-	configGetter EnvironConfigGetter, hostPortsGetter APIHostPortsGetter, setter StateServingInfoSetter,
+	certsAccessor CertificatesAccessor, hostPortsGetter APIHostPortsGetter, setter StateServingInfoSetter,
 ) worker.Worker {
-	return worker.NewNotifyWorker(&CertificateUpdater{
+	c := &CertificateUpdater{
 		addressWatcher:  addressWatcher,
-		configGetter:    configGetter,
+		certsAccessor:   certsAccessor,
 		hostPortsGetter: hostPortsGetter,
 		getter:          getter,
 		setter:          setter,
-	})
+	}
+	go func() {
+		defer c.tomb.Done()
+		c.tomb.Kill(c.loop())
+	}()
+	return c
+}
+
+// Kill is defined on the worker.Worker interface.
+func (c *CertificateUpdater) Kill() {
+	c.tomb.Kill(nil)
 }
 
-// SetUp is defined on the NotifyWatchHandler interface.
-func (c *CertificateUpdater) SetUp() (watcher.NotifyWatcher, error) {
+// Wait is defined on the worker.Worker interface.
+func (c *CertificateUpdater) Wait() error {
+	return c.tomb.Wait()
+}
+
+func (c *CertificateUpdater) loop() error {
 	// Populate certificate SAN with any addresses we know about now.
 	apiHostPorts, err := c.hostPortsGetter.APIHostPorts()
 	if err != nil {
-		return nil, errors.Annotate(err, "retrieving initial server addesses")
+		return errors.Annotate(err, "retrieving initial server addesses")
 	}
 	var initialSANAddresses []network.Address
 	for _, server := range apiHostPorts {
@@ -95,23 +134,55 @@ func (c *CertificateUpdater) SetUp() (watcher.NotifyWatcher, error) {
 			initialSANAddresses = append(initialSANAddresses, nhp.Address)
 		}
 	}
-	if err := c.updateCertificate(initialSANAddresses, make(chan struct{})); err != nil {
-		return nil, errors.Annotate(err, "setting initial cerificate SAN list")
+	if err := c.updateCertificate(initialSANAddresses, c.tomb.Dying()); err != nil {
+		return errors.Annotate(err, "setting initial cerificate SAN list")
 	}
-	// Return
-	return c.addressWatcher.WatchAddresses(), nil
-}
 
-// Handle is defined on the NotifyWatchHandler interface.
-func (c *CertificateUpdater) Handle(done <-chan struct{}) error {
-	addresses := c.addressWatcher.Addresses()
-	if reflect.DeepEqual(addresses, c.addresses) {
-		// Sometimes the watcher will tell us things have changed, when they
-		// haven't as far as we can tell.
-		logger.Debugf("addresses haven't really changed since last updated cert")
-		return nil
+	addressWatcher := c.addressWatcher.WatchAddresses()
+	defer watcher.Stop(addressWatcher, &c.tomb)
+	certsWatcher := c.certsAccessor.WatchControllerCertificates()
+	defer watcher.Stop(certsWatcher, &c.tomb)
+	for {
+		// Wake up when a new CA takes over signing, as nothing in
+		// state changes then.
+		var switchTimer <-chan time.Time
+		if !c.switchTime.IsZero() {
+			switchTimer = time.After(c.switchTime.Sub(time.Now()))
+		}
+		select {
+		case <-c.tomb.Dying():
+			return tomb.ErrDying
+		case _, ok := <-addressWatcher.Changes():
+			if !ok {
+				return watcher.EnsureErr(addressWatcher)
+			}
+			addresses := c.addressWatcher.Addresses()
+			if reflect.DeepEqual(addresses, c.addresses) {
+				// Sometimes the watcher will tell us things have changed, when they
+				// haven't as far as we can tell.
+				logger.Debugf("addresses haven't really changed since last updated cert")
+				continue
+			}
+			if err := c.updateCertificate(addresses, c.tomb.Dying()); err != nil {
+				return errors.Trace(err)
+			}
+		case _, ok := <-certsWatcher.Changes():
+			if !ok {
+				return watcher.EnsureErr(certsWatcher)
+			}
+			if err := c.updateCertificate(c.addresses, c.tomb.Dying()); err != nil {
+				return errors.Trace(err)
+			}
+		case <-switchTimer:
+			if err := c.updateCertificate(c.addresses, c.tomb.Dying()); err != nil {
+				return errors.Trace(err)
+			}
+		case <-time.After(CheckInterval):
+			if err := c.updateCertificate(c.addresses, c.tomb.Dying()); err != nil {
+				return errors.Trace(err)
+			}
+		}
 	}
-	return c.updateCertificate(addresses, done)
 }
 
 func (c *CertificateUpdater) updateCertificate(addresses []network.Address, done <-chan struct{}) error {
@@ -125,19 +196,27 @@ func (c *CertificateUpdater) updateCertificate(addresses []network.Address, done
 		logger.Warningf("no state serving info, cannot regenerate server certificate")
 		return nil
 	}
-	caPrivateKey := stateInfo.CAPrivateKey
-	if caPrivateKey == "" {
-		logger.Warningf("no CA cert private key, cannot regenerate server certificate")
-		return nil
-	}
-	// Grab the env config and update a copy with ca cert private key.
-	envConfig, err := c.configGetter.EnvironConfig()
+	certs, err := c.certsAccessor.ControllerCertificates()
 	if err != nil {
-		return errors.Annotate(err, "cannot read environment config")
+		return errors.Annotate(err, "cannot read controller certificates")
+	}
+	now := time.Now()
+	c.switchTime = time.Time{}
+	if certs.NewCACert != "" && now.Before(certs.SwitchTime) {
+		c.switchTime = certs.SwitchTime
 	}
-	envConfig, err = envConfig.Apply(map[string]interface{}{"ca-private-key": caPrivateKey})
+	caCert, caPrivateKey, err := c.certsAccessor.ControllerSigningCA(now)
 	if err != nil {
-		return errors.Annotate(err, "cannot add CA private key to environment config")
+		return errors.Annotate(err, "cannot read controller signing CA")
+	}
+	if caPrivateKey == "" {
+		// Controllers that have never rotated their CA only
+		// have the key in the agent config.
+		caPrivateKey = stateInfo.CAPrivateKey
+	}
+	if caPrivateKey == "" {
+		logger.Warningf("no CA cert private key, cannot regenerate server certificate")
+		return nil
 	}
 
 	// For backwards compatibility, we must include "anything", "juju-apiserver"
@@ -156,24 +235,70 @@ func (c *CertificateUpdater) updateCertificate(addresses []network.Address, done
 	if err != nil {
 		return errors.Annotate(err, "cannot determine if cert update needed")
 	}
+	if !update {
+		update, err = renewalRequired(stateInfo.Cert, caCert, certs.ServerCertsRotated, now)
+		if err != nil {
+			return errors.Annotate(err, "cannot determine if cert renewal needed")
+		}
+	}
 	if !update {
 		logger.Debugf("no certificate update required")
-		return nil
+		return c.recordExpiry(stateInfo.Cert)
 	}
 
 	// Generate a new state server certificate with the machine addresses in the SAN value.
-	newCert, newKey, err := envConfig.GenerateStateServerCertAndKey(newServerAddrs)
+	newCert, newKey, err := cert.NewDefaultServer(caCert, caPrivateKey, newServerAddrs)
 	if err != nil {
 		return errors.Annotate(err, "cannot generate state server certificate")
 	}
-	stateInfo.Cert = string(newCert)
-	stateInfo.PrivateKey = string(newKey)
+	stateInfo.Cert = newCert
+	stateInfo.PrivateKey = newKey
+	stateInfo.CAPrivateKey = caPrivateKey
 	err = c.setter(stateInfo, done)
 	if err != nil {
 		return errors.Annotate(err, "cannot write agent config")
 	}
 	logger.Infof("State Server cerificate addresses updated to %q", newServerAddrs)
-	return nil
+	return c.recordExpiry(newCert)
+}
+
+// recordExpiry records the expiry time of the given server certificate
+// against this state server, so that it can be reported to users.
+func (c *CertificateUpdater) recordExpiry(serverCert string) error {
+	x509Cert, err := cert.ParseCert(serverCert)
+	if err != nil {
+		return errors.Annotate(err, "cannot parse TLS certificate")
+	}
+	err = c.certsAccessor.SetServerCertificateExpiry(c.addressWatcher.Id(), x509Cert.NotAfter)
+	return errors.Trace(err)
+}
+
+// renewalRequired returns true if the server certificate was not signed
+// by the given CA, was issued before rotated, or expires soon.
+func renewalRequired(serverCert, caCert string, rotated, now time.Time) (bool, error) {
+	x509Cert, err := cert.ParseCert(serverCert)
+	if err != nil {
+		return false, errors.Annotate(err, "cannot parse existing TLS certificate")
+	}
+	x509CACert, err := cert.ParseCert(caCert)
+	if err != nil {
+		return false, errors.Annotate(err, "cannot parse CA certificate")
+	}
+	if err := x509Cert.CheckSignatureFrom(x509CACert); err != nil {
+		logger.Infof("server certificate not signed by current CA, renewing")
+		return true, nil
+	}
+	// Certificates are valid from a week before they are issued.
+	issued := x509Cert.NotBefore.AddDate(0, 0, 7)
+	if issued.Before(rotated) {
+		logger.Infof("server certificate rotation requested, renewing")
+		return true, nil
+	}
+	if x509Cert.NotAfter.Before(now.Add(RenewBefore)) {
+		logger.Infof("server certificate expires at %s, renewing", x509Cert.NotAfter)
+		return true, nil
+	}
+	return false, nil
 }
 
 // updateRequired returns true and a list of merged addresses if any of the
@@ -195,8 +320,3 @@ func updateRequired(serverCert string, newAddrs []string) ([]string, bool, error
 	newAddrSet = newAddrSet.Union(existingAddr)
 	return newAddrSet.SortedValues(), update, nil
 }
-
-// TearDown is defined on the NotifyWatchHandler interface.
-func (c *CertificateUpdater) TearDown() error {
-	return nil
-}
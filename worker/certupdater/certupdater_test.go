@@ -5,6 +5,7 @@ package certupdater_test
 
 import (
 	"crypto/x509"
+	"sync"
 	stdtesting "testing"
 	"time"
 
@@ -14,7 +15,6 @@ import (
 
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/cert"
-	"github.com/juju/juju/environs/config"
 	"github.com/juju/juju/network"
 	"github.com/juju/juju/state"
 	coretesting "github.com/juju/juju/testing"
@@ -74,6 +74,10 @@ type mockMachine struct {
 	changes chan struct{}
 }
 
+func (m *mockMachine) Id() string {
+	return "0"
+}
+
 func (m *mockMachine) WatchAddresses() state.NotifyWatcher {
 	return newMockNotifyWatcher(m.changes)
 }
@@ -88,11 +92,56 @@ func (s *CertUpdaterSuite) StateServingInfo() (params.StateServingInfo, bool) {
 	return s.stateServingInfo, true
 }
 
-type mockConfigGetter struct{}
+type mockCertificates struct {
+	mu       sync.Mutex
+	certs    state.ControllerCertificates
+	newCAKey string
+	changes  chan struct{}
+	expiries chan time.Time
+}
 
-func (g *mockConfigGetter) EnvironConfig() (*config.Config, error) {
-	return config.New(config.NoDefaults, coretesting.FakeConfig())
+func (m *mockCertificates) ControllerCertificates() (state.ControllerCertificates, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.certs, nil
+}
 
+func (m *mockCertificates) ControllerSigningCA(now time.Time) (string, string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.certs.NewCACert != "" && !now.Before(m.certs.SwitchTime) {
+		return m.certs.NewCACert, m.newCAKey, nil
+	}
+	// The current CA's key is in the state serving info.
+	return m.certs.CACert, "", nil
+}
+
+func (m *mockCertificates) setNewCA(caCert, caKey string, switchTime time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.certs.NewCACert = caCert
+	m.newCAKey = caKey
+	m.certs.SwitchTime = switchTime
+}
+
+func (m *mockCertificates) WatchControllerCertificates() state.NotifyWatcher {
+	return newMockNotifyWatcher(m.changes)
+}
+
+func (m *mockCertificates) SetServerCertificateExpiry(machineId string, expiry time.Time) error {
+	if m.expiries != nil {
+		m.expiries <- expiry
+	}
+	return nil
+}
+
+func newMockCertificates() *mockCertificates {
+	return &mockCertificates{
+		certs: state.ControllerCertificates{
+			CACert: coretesting.CACert,
+		},
+		changes: make(chan struct{}),
+	}
 }
 
 type mockAPIHostGetter struct{}
@@ -123,7 +172,7 @@ func (s *CertUpdaterSuite) TestStartStop(c *gc.C) {
 	}
 	changes := make(chan struct{})
 	worker := certupdater.NewCertificateUpdater(
-		&mockMachine{changes}, s, &mockConfigGetter{}, &mockAPIHostGetter{}, setter,
+		&mockMachine{changes}, s, newMockCertificates(), &mockAPIHostGetter{}, setter,
 	)
 	worker.Kill()
 	c.Assert(worker.Wait(), gc.IsNil)
@@ -151,7 +200,7 @@ func (s *CertUpdaterSuite) TestAddressChange(c *gc.C) {
 	}
 	changes := make(chan struct{})
 	worker := certupdater.NewCertificateUpdater(
-		&mockMachine{changes}, s, &mockConfigGetter{}, &mockAPIHostGetter{}, setter,
+		&mockMachine{changes}, s, newMockCertificates(), &mockAPIHostGetter{}, setter,
 	)
 	defer func() { c.Assert(worker.Wait(), gc.IsNil) }()
 	defer worker.Kill()
@@ -192,7 +241,7 @@ func (s *CertUpdaterSuite) TestAddressChangeNoCAKey(c *gc.C) {
 	}
 	changes := make(chan struct{})
 	worker := certupdater.NewCertificateUpdater(
-		&mockMachine{changes}, &mockStateServingGetterNoCAKey{}, &mockConfigGetter{}, &mockAPIHostGetter{}, setter,
+		&mockMachine{changes}, &mockStateServingGetterNoCAKey{}, newMockCertificates(), &mockAPIHostGetter{}, setter,
 	)
 	defer func() { c.Assert(worker.Wait(), gc.IsNil) }()
 	defer worker.Kill()
@@ -205,3 +254,126 @@ func (s *CertUpdaterSuite) TestAddressChangeNoCAKey(c *gc.C) {
 		c.Fatalf("set state serving info unexpectedly called")
 	}
 }
+
+func (s *CertUpdaterSuite) TestRotationRequested(c *gc.C) {
+	certs := newMockCertificates()
+	certs.certs.ServerCertsRotated = time.Now().Add(time.Minute)
+	updated := make(chan string, 1)
+	setter := func(info params.StateServingInfo, dying <-chan struct{}) error {
+		updated <- info.Cert
+		return nil
+	}
+	worker := certupdater.NewCertificateUpdater(
+		&mockMachine{make(chan struct{})}, s, certs, &mockAPIHostGetter{}, setter,
+	)
+	defer func() { c.Assert(worker.Wait(), gc.IsNil) }()
+	defer worker.Kill()
+
+	select {
+	case newCert := <-updated:
+		c.Assert(newCert, gc.Not(gc.Equals), coretesting.ServerCert)
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for certificate to be rotated")
+	}
+}
+
+func (s *CertUpdaterSuite) TestCAChange(c *gc.C) {
+	certs := newMockCertificates()
+	// Ensure the initial certificate doesn't need updating.
+	s.stateServingInfo.Cert, s.stateServingInfo.PrivateKey = mustNewServer(c, "192.168.1.1")
+	updated := make(chan params.StateServingInfo, 1)
+	setter := func(info params.StateServingInfo, dying <-chan struct{}) error {
+		updated <- info
+		return nil
+	}
+	worker := certupdater.NewCertificateUpdater(
+		&mockMachine{make(chan struct{})}, s, certs, &mockAPIHostGetter{}, setter,
+	)
+	defer func() { c.Assert(worker.Wait(), gc.IsNil) }()
+	defer worker.Kill()
+
+	select {
+	case <-updated:
+		c.Fatalf("certificate unexpectedly updated")
+	case <-time.After(coretesting.ShortWait):
+	}
+
+	// Switch to a new CA, and let the worker know.
+	certs.setNewCA(coretesting.OtherCACert, coretesting.OtherCAKey, time.Now().Add(-time.Minute))
+	certs.changes <- struct{}{}
+
+	select {
+	case info := <-updated:
+		c.Assert(cert.Verify(info.Cert, coretesting.OtherCACert, time.Now()), jc.ErrorIsNil)
+		c.Assert(info.CAPrivateKey, gc.Equals, coretesting.OtherCAKey)
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for certificate to be updated")
+	}
+}
+
+func (s *CertUpdaterSuite) TestCASwitchTime(c *gc.C) {
+	certs := newMockCertificates()
+	certs.setNewCA(coretesting.OtherCACert, coretesting.OtherCAKey, time.Now().Add(coretesting.ShortWait))
+	// Ensure the initial certificate doesn't need updating.
+	s.stateServingInfo.Cert, s.stateServingInfo.PrivateKey = mustNewServer(c, "192.168.1.1")
+	updated := make(chan params.StateServingInfo, 1)
+	setter := func(info params.StateServingInfo, dying <-chan struct{}) error {
+		updated <- info
+		return nil
+	}
+	worker := certupdater.NewCertificateUpdater(
+		&mockMachine{make(chan struct{})}, s, certs, &mockAPIHostGetter{}, setter,
+	)
+	defer func() { c.Assert(worker.Wait(), gc.IsNil) }()
+	defer worker.Kill()
+
+	// The certificate is replaced once the new CA takes over,
+	// without anything else changing.
+	select {
+	case info := <-updated:
+		c.Assert(cert.Verify(info.Cert, coretesting.OtherCACert, time.Now()), jc.ErrorIsNil)
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for certificate to be updated")
+	}
+}
+
+func (s *CertUpdaterSuite) TestExpiringCertificateRenewed(c *gc.C) {
+	// Ensure the initial certificate doesn't need updating for
+	// any other reason.
+	s.stateServingInfo.Cert, s.stateServingInfo.PrivateKey = mustNewServer(c, "192.168.1.1")
+	s.PatchValue(&certupdater.RenewBefore, 20*365*24*time.Hour)
+	certs := newMockCertificates()
+	certs.expiries = make(chan time.Time, 1)
+	updated := make(chan string, 1)
+	setter := func(info params.StateServingInfo, dying <-chan struct{}) error {
+		updated <- info.Cert
+		return nil
+	}
+	worker := certupdater.NewCertificateUpdater(
+		&mockMachine{make(chan struct{})}, s, certs, &mockAPIHostGetter{}, setter,
+	)
+	defer func() { c.Assert(worker.Wait(), gc.IsNil) }()
+	defer worker.Kill()
+
+	select {
+	case newCert := <-updated:
+		c.Assert(newCert, gc.Not(gc.Equals), s.stateServingInfo.Cert)
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for certificate to be renewed")
+	}
+
+	// The new certificate's expiry is recorded.
+	select {
+	case expiry := <-certs.expiries:
+		c.Assert(expiry.After(time.Now().AddDate(9, 0, 0)), jc.IsTrue)
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for certificate expiry to be recorded")
+	}
+}
+
+func mustNewServer(c *gc.C, addrs ...string) (string, string) {
+	hostnames := append([]string{"localhost", "juju-apiserver", "juju-mongodb", "anything"}, addrs...)
+	srvCert, srvKey, err := cert.NewDefaultServer(coretesting.CACert, coretesting.CAKey, hostnames)
+	c.Assert(err, jc.ErrorIsNil)
+	return srvCert, srvKey
+}
@@ -0,0 +1,65 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package charmrollout provides a worker that advances the staged
+// rollout of services' charms to their units.
+package charmrollout
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+
+	"github.com/juju/juju/worker"
+)
+
+var logger = loggo.GetLogger("juju.worker.charmrollout")
+
+// DefaultCheckInterval is how often charm rollouts are checked.
+const DefaultCheckInterval = 15 * time.Second
+
+// Facade represents an API that advances charm rollouts.
+type Facade interface {
+	AdvanceCharmRollouts() error
+}
+
+// Config holds all necessary attributes to start a charm rollout worker.
+type Config struct {
+	Facade        Facade
+	CheckInterval time.Duration
+	NewTimer      worker.NewTimerFunc
+}
+
+// Validate will err unless basic requirements for a valid
+// config are met.
+func (c *Config) Validate() error {
+	if c.Facade == nil {
+		return errors.New("missing Facade")
+	}
+	if c.NewTimer == nil {
+		return errors.New("missing Timer")
+	}
+	if c.CheckInterval <= 0 {
+		return errors.New("non-positive CheckInterval")
+	}
+	return nil
+}
+
+// New returns a worker.Worker that periodically advances the charm
+// rollouts in progress, releasing further units to upgrade or pausing
+// rollouts whose units are in error.
+func New(conf Config) (worker.Worker, error) {
+	if err := conf.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	advance := func(stop <-chan struct{}) error {
+		if err := conf.Facade.AdvanceCharmRollouts(); err != nil {
+			// A failure for one service should not stop the
+			// others' rollouts for long; try again later.
+			logger.Errorf("cannot advance charm rollouts: %v", err)
+		}
+		return nil
+	}
+	return worker.NewPeriodicWorker(advance, conf.CheckInterval, conf.NewTimer), nil
+}
@@ -0,0 +1,84 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package charmrollout_test
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/charmrollout"
+)
+
+type charmRolloutSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&charmRolloutSuite{})
+
+type fakeFacade struct {
+	calls chan struct{}
+	err   error
+}
+
+func (f *fakeFacade) AdvanceCharmRollouts() error {
+	// Never block, so the worker can always be stopped.
+	select {
+	case f.calls <- struct{}{}:
+	default:
+	}
+	return f.err
+}
+
+func (s *charmRolloutSuite) assertCalled(c *gc.C, facade *fakeFacade) {
+	select {
+	case <-facade.calls:
+	case <-time.After(coretesting.LongWait):
+		c.Fatal("timed out waiting for charm rollouts to be advanced")
+	}
+}
+
+func (s *charmRolloutSuite) TestWorkerAdvancesRollouts(c *gc.C) {
+	facade := &fakeFacade{
+		calls: make(chan struct{}),
+		err:   errors.New("boom"),
+	}
+	w, err := charmrollout.New(charmrollout.Config{
+		Facade:        facade,
+		CheckInterval: coretesting.ShortWait,
+		NewTimer:      worker.NewTimer,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	s.AddCleanup(func(*gc.C) {
+		c.Assert(worker.Stop(w), jc.ErrorIsNil)
+	})
+
+	// Errors do not stop the worker.
+	s.assertCalled(c, facade)
+	s.assertCalled(c, facade)
+}
+
+func (s *charmRolloutSuite) TestConfigValidation(c *gc.C) {
+	for i, test := range []struct {
+		conf charmrollout.Config
+		err  string
+	}{{
+		conf: charmrollout.Config{CheckInterval: time.Second, NewTimer: worker.NewTimer},
+		err:  "missing Facade",
+	}, {
+		conf: charmrollout.Config{Facade: &fakeFacade{}, CheckInterval: time.Second},
+		err:  "missing Timer",
+	}, {
+		conf: charmrollout.Config{Facade: &fakeFacade{}, NewTimer: worker.NewTimer},
+		err:  "non-positive CheckInterval",
+	}} {
+		c.Logf("test %d", i)
+		_, err := charmrollout.New(test.conf)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package crossenvrelations provides a worker that mirrors relations
+// between environments on the same controller.
+package crossenvrelations
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+
+	"github.com/juju/juju/worker"
+)
+
+var logger = loggo.GetLogger("juju.worker.crossenvrelations")
+
+// DefaultCheckInterval is how often cross-environment relations are synced.
+const DefaultCheckInterval = 5 * time.Second
+
+// Facade represents an API that syncs cross-environment relations.
+type Facade interface {
+	SyncCrossEnvRelations() error
+}
+
+// Config holds all necessary attributes to start the worker.
+type Config struct {
+	Facade        Facade
+	CheckInterval time.Duration
+	NewTimer      worker.NewTimerFunc
+}
+
+// Validate will err unless basic requirements for a valid
+// config are met.
+func (c *Config) Validate() error {
+	if c.Facade == nil {
+		return errors.New("missing Facade")
+	}
+	if c.NewTimer == nil {
+		return errors.New("missing Timer")
+	}
+	if c.CheckInterval <= 0 {
+		return errors.New("non-positive CheckInterval")
+	}
+	return nil
+}
+
+// New returns a worker.Worker that periodically mirrors the relations
+// of consumed remote services, and the settings of their units, into
+// the environments offering them.
+func New(conf Config) (worker.Worker, error) {
+	if err := conf.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sync := func(stop <-chan struct{}) error {
+		if err := conf.Facade.SyncCrossEnvRelations(); err != nil {
+			// Relations left unsynced are picked up next time.
+			logger.Errorf("cannot sync cross-environment relations: %v", err)
+		}
+		return nil
+	}
+	return worker.NewPeriodicWorker(sync, conf.CheckInterval, conf.NewTimer), nil
+}
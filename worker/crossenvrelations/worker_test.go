@@ -0,0 +1,84 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package crossenvrelations_test
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/crossenvrelations"
+)
+
+type syncSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&syncSuite{})
+
+type fakeFacade struct {
+	calls chan struct{}
+	err   error
+}
+
+func (f *fakeFacade) SyncCrossEnvRelations() error {
+	// Never block, so the worker can always be stopped.
+	select {
+	case f.calls <- struct{}{}:
+	default:
+	}
+	return f.err
+}
+
+func (s *syncSuite) assertCalled(c *gc.C, facade *fakeFacade) {
+	select {
+	case <-facade.calls:
+	case <-time.After(coretesting.LongWait):
+		c.Fatal("timed out waiting for relations to be synced")
+	}
+}
+
+func (s *syncSuite) TestWorkerSyncsRelations(c *gc.C) {
+	facade := &fakeFacade{
+		calls: make(chan struct{}),
+		err:   errors.New("boom"),
+	}
+	w, err := crossenvrelations.New(crossenvrelations.Config{
+		Facade:        facade,
+		CheckInterval: coretesting.ShortWait,
+		NewTimer:      worker.NewTimer,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	s.AddCleanup(func(*gc.C) {
+		c.Assert(worker.Stop(w), jc.ErrorIsNil)
+	})
+
+	// Errors do not stop the worker.
+	s.assertCalled(c, facade)
+	s.assertCalled(c, facade)
+}
+
+func (s *syncSuite) TestConfigValidation(c *gc.C) {
+	for i, test := range []struct {
+		conf crossenvrelations.Config
+		err  string
+	}{{
+		conf: crossenvrelations.Config{CheckInterval: time.Second, NewTimer: worker.NewTimer},
+		err:  "missing Facade",
+	}, {
+		conf: crossenvrelations.Config{Facade: &fakeFacade{}, CheckInterval: time.Second},
+		err:  "missing Timer",
+	}, {
+		conf: crossenvrelations.Config{Facade: &fakeFacade{}, NewTimer: worker.NewTimer},
+		err:  "non-positive CheckInterval",
+	}} {
+		c.Logf("test %d", i)
+		_, err := crossenvrelations.New(test.conf)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
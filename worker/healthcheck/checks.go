@@ -0,0 +1,253 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package healthcheck
+
+import (
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	goyaml "gopkg.in/yaml.v2"
+
+	"github.com/juju/juju/utils/procgroup"
+)
+
+// HealthFile is the name of the file, in the root of the charm
+// directory, in which a charm declares its health checks.
+const HealthFile = "health.yaml"
+
+const (
+	// DefaultInterval is how often a check runs if its declaration
+	// does not say.
+	DefaultInterval = time.Minute
+
+	// DefaultTimeout is how long a check may take if its
+	// declaration does not say.
+	DefaultTimeout = 10 * time.Second
+)
+
+// Check is a health check declared by a charm. Exactly one of Command,
+// HTTP and TCP is set.
+type Check struct {
+	// Name identifies the check.
+	Name string
+
+	// Command is a shell command, run in the charm directory, that
+	// passes if it exits with status zero.
+	Command string
+
+	// HTTP is a URL that passes if a GET request for it returns a
+	// status below 400.
+	HTTP string
+
+	// TCP is a host:port address that passes if a connection can be
+	// made to it.
+	TCP string
+
+	// Interval is how often the check runs.
+	Interval time.Duration
+
+	// Timeout is how long the check may take before it fails.
+	Timeout time.Duration
+}
+
+type checkDoc struct {
+	Command  string `yaml:"command"`
+	HTTP     string `yaml:"http"`
+	TCP      string `yaml:"tcp"`
+	Interval string `yaml:"interval"`
+	Timeout  string `yaml:"timeout"`
+}
+
+type healthDoc struct {
+	Checks map[string]checkDoc `yaml:"checks"`
+}
+
+// ReadChecks returns the health checks declared in the charm in the
+// given directory, sorted by name. A charm without a health file
+// declares no checks.
+func ReadChecks(charmDir string) ([]Check, error) {
+	data, err := ioutil.ReadFile(filepath.Join(charmDir, HealthFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return ParseChecks(data)
+}
+
+// ParseChecks parses the health checks declared in the contents of a
+// charm's health file, and returns them sorted by name.
+func ParseChecks(data []byte) ([]Check, error) {
+	var doc healthDoc
+	if err := goyaml.Unmarshal(data, &doc); err != nil {
+		return nil, errors.Annotate(err, "cannot parse health checks")
+	}
+	checks := make([]Check, 0, len(doc.Checks))
+	for name, checkDoc := range doc.Checks {
+		check, err := parseCheck(name, checkDoc)
+		if err != nil {
+			return nil, errors.Annotatef(err, "invalid health check %q", name)
+		}
+		checks = append(checks, check)
+	}
+	sort.Sort(byName(checks))
+	return checks, nil
+}
+
+func parseCheck(name string, doc checkDoc) (Check, error) {
+	check := Check{
+		Name:     name,
+		Command:  doc.Command,
+		HTTP:     doc.HTTP,
+		TCP:      doc.TCP,
+		Interval: DefaultInterval,
+		Timeout:  DefaultTimeout,
+	}
+	var kinds []string
+	if check.Command != "" {
+		kinds = append(kinds, "command")
+	}
+	if check.HTTP != "" {
+		u, err := url.Parse(check.HTTP)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return Check{}, errors.Errorf("invalid URL %q", check.HTTP)
+		}
+		kinds = append(kinds, "http")
+	}
+	if check.TCP != "" {
+		if _, _, err := net.SplitHostPort(check.TCP); err != nil {
+			return Check{}, errors.Errorf("invalid address %q", check.TCP)
+		}
+		kinds = append(kinds, "tcp")
+	}
+	switch len(kinds) {
+	case 0:
+		return Check{}, errors.New("expected one of command, http or tcp")
+	case 1:
+	default:
+		return Check{}, errors.Errorf("expected one of command, http or tcp, got %s", strings.Join(kinds, ", "))
+	}
+	var err error
+	if doc.Interval != "" {
+		if check.Interval, err = parsePositiveDuration(doc.Interval); err != nil {
+			return Check{}, errors.Annotate(err, "invalid interval")
+		}
+	}
+	if doc.Timeout != "" {
+		if check.Timeout, err = parsePositiveDuration(doc.Timeout); err != nil {
+			return Check{}, errors.Annotate(err, "invalid timeout")
+		}
+	}
+	return check, nil
+}
+
+func parsePositiveDuration(value string) (time.Duration, error) {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	if d <= 0 {
+		return 0, errors.Errorf("%q is not positive", value)
+	}
+	return d, nil
+}
+
+// Run runs the check, and returns why it failed, if it did. Commands
+// are run in the given charm directory.
+func (c Check) Run(charmDir string) error {
+	switch {
+	case c.Command != "":
+		return runCommand(c.Command, charmDir, c.Timeout)
+	case c.HTTP != "":
+		client := &http.Client{Timeout: c.Timeout}
+		resp, err := client.Get(c.HTTP)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= http.StatusBadRequest {
+			return errors.Errorf("GET %s: %s", c.HTTP, resp.Status)
+		}
+		return nil
+	case c.TCP != "":
+		conn, err := net.DialTimeout("tcp", c.TCP, c.Timeout)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		conn.Close()
+		return nil
+	}
+	return errors.New("nothing to check")
+}
+
+// runCommand runs the shell command in the given directory, and kills
+// it if it runs for longer than the timeout.
+func runCommand(command, dir string, timeout time.Duration) error {
+	var ps *exec.Cmd
+	if runtime.GOOS == "windows" {
+		ps = exec.Command("cmd", "/C", command)
+	} else {
+		ps = exec.Command("/bin/sh", "-c", command)
+	}
+	ps.Dir = dir
+	// Run the command in its own process group, so that it can be
+	// killed along with its children if it times out.
+	procgroup.Set(ps)
+	output := &limitedBuffer{limit: 1024}
+	ps.Stdout = output
+	ps.Stderr = output
+	if err := ps.Start(); err != nil {
+		return errors.Trace(err)
+	}
+	err := procgroup.Wait(ps, timeout)
+	switch {
+	case err == nil:
+		return nil
+	case err == procgroup.ErrTimeout:
+		return errors.Errorf("timed out after %v", timeout)
+	}
+	if out := strings.TrimSpace(output.String()); out != "" {
+		return errors.Errorf("%v: %s", err, out)
+	}
+	return errors.Trace(err)
+}
+
+// limitedBuffer keeps at most the first limit bytes written to it.
+type limitedBuffer struct {
+	limit int
+	data  []byte
+}
+
+// Write is part of the io.Writer interface.
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - len(b.data); room > 0 {
+		if len(p) > room {
+			b.data = append(b.data, p[:room]...)
+		} else {
+			b.data = append(b.data, p...)
+		}
+	}
+	return len(p), nil
+}
+
+// String returns the bytes kept.
+func (b *limitedBuffer) String() string {
+	return string(b.data)
+}
+
+type byName []Check
+
+func (s byName) Len() int           { return len(s) }
+func (s byName) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s byName) Less(i, j int) bool { return s[i].Name < s[j].Name }
@@ -0,0 +1,144 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package healthcheck_test
+
+import (
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker/healthcheck"
+)
+
+type ChecksSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&ChecksSuite{})
+
+func (s *ChecksSuite) TestParseChecks(c *gc.C) {
+	checks, err := healthcheck.ParseChecks([]byte(`
+checks:
+  web:
+    http: http://localhost:8080/health
+    interval: 30s
+    timeout: 5s
+  db:
+    tcp: localhost:5432
+  daemon:
+    command: pgrep -x mydaemon
+`))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(checks, jc.DeepEquals, []healthcheck.Check{{
+		Name:     "daemon",
+		Command:  "pgrep -x mydaemon",
+		Interval: healthcheck.DefaultInterval,
+		Timeout:  healthcheck.DefaultTimeout,
+	}, {
+		Name:     "db",
+		TCP:      "localhost:5432",
+		Interval: healthcheck.DefaultInterval,
+		Timeout:  healthcheck.DefaultTimeout,
+	}, {
+		Name:     "web",
+		HTTP:     "http://localhost:8080/health",
+		Interval: 30 * time.Second,
+		Timeout:  5 * time.Second,
+	}})
+}
+
+func (s *ChecksSuite) TestParseChecksInvalid(c *gc.C) {
+	for i, test := range []struct {
+		yaml string
+		err  string
+	}{{
+		yaml: "checks: [web]",
+		err:  "cannot parse health checks: .*",
+	}, {
+		yaml: "checks: {web: {}}",
+		err:  `invalid health check "web": expected one of command, http or tcp`,
+	}, {
+		yaml: "checks: {web: {http: 'http://localhost/', tcp: 'localhost:80'}}",
+		err:  `invalid health check "web": expected one of command, http or tcp, got http, tcp`,
+	}, {
+		yaml: "checks: {web: {http: 'localhost/health'}}",
+		err:  `invalid health check "web": invalid URL "localhost/health"`,
+	}, {
+		yaml: "checks: {db: {tcp: localhost}}",
+		err:  `invalid health check "db": invalid address "localhost"`,
+	}, {
+		yaml: "checks: {db: {tcp: 'localhost:5432', interval: soon}}",
+		err:  `invalid health check "db": invalid interval: time: invalid duration .*soon.*`,
+	}, {
+		yaml: "checks: {db: {tcp: 'localhost:5432', timeout: -1s}}",
+		err:  `invalid health check "db": invalid timeout: "-1s" is not positive`,
+	}} {
+		c.Logf("test %d: %s", i, test.yaml)
+		_, err := healthcheck.ParseChecks([]byte(test.yaml))
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
+
+func (s *ChecksSuite) TestReadChecks(c *gc.C) {
+	dir := c.MkDir()
+	checks, err := healthcheck.ReadChecks(dir)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(checks, gc.HasLen, 0)
+
+	err = ioutil.WriteFile(filepath.Join(dir, healthcheck.HealthFile), []byte("checks: {daemon: {command: 'true'}}"), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+	checks, err = healthcheck.ReadChecks(dir)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(checks, gc.HasLen, 1)
+	c.Assert(checks[0].Name, gc.Equals, "daemon")
+}
+
+func (s *ChecksSuite) TestRunCommand(c *gc.C) {
+	check := healthcheck.Check{Command: "exit 0", Timeout: coretesting.LongWait}
+	c.Assert(check.Run(c.MkDir()), jc.ErrorIsNil)
+
+	check.Command = "echo not running && exit 3"
+	err := check.Run(c.MkDir())
+	c.Assert(err, gc.ErrorMatches, "exit status 3: not running")
+}
+
+func (s *ChecksSuite) TestRunCommandTimeout(c *gc.C) {
+	check := healthcheck.Check{Command: "sleep 10", Timeout: coretesting.ShortWait}
+	err := check.Run(c.MkDir())
+	c.Assert(err, gc.ErrorMatches, "timed out after .*")
+}
+
+func (s *ChecksSuite) TestRunHTTP(c *gc.C) {
+	healthy := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	check := healthcheck.Check{HTTP: server.URL, Timeout: coretesting.LongWait}
+	c.Assert(check.Run(""), jc.ErrorIsNil)
+
+	healthy = false
+	err := check.Run("")
+	c.Assert(err, gc.ErrorMatches, "GET .*: 503 Service Unavailable")
+}
+
+func (s *ChecksSuite) TestRunTCP(c *gc.C) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	c.Assert(err, jc.ErrorIsNil)
+	check := healthcheck.Check{TCP: listener.Addr().String(), Timeout: coretesting.LongWait}
+	c.Assert(check.Run(""), jc.ErrorIsNil)
+
+	listener.Close()
+	c.Assert(check.Run(""), gc.NotNil)
+}
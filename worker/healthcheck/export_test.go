@@ -0,0 +1,14 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package healthcheck
+
+var (
+	// NewHealthCheck allows patching the function that creates the
+	// health check entity.
+	NewHealthCheck = &newHealthCheck
+
+	// NewChecker returns a health check entity. It is exported here
+	// for calling from tests, but not patching.
+	NewChecker = newChecker
+)
@@ -0,0 +1,231 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package healthcheck provides a worker that periodically runs the
+// health checks declared by a unit's charm, and reports their results
+// to the state server, which records them as the unit's health and
+// reflects failures in its workload status. Checks only run while the
+// charm directory is available: after the start hook has run, before
+// the stop hook runs, and not during upgrades.
+package healthcheck
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"github.com/juju/names"
+	"github.com/juju/utils/clock"
+
+	"github.com/juju/juju/agent"
+	"github.com/juju/juju/api/base"
+	uniterapi "github.com/juju/juju/api/uniter"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/dependency"
+	"github.com/juju/juju/worker/fortress"
+	"github.com/juju/juju/worker/uniter"
+)
+
+// defaultPeriod is how often the worker looks for checks that are due
+// to run; checks cannot usefully have shorter intervals.
+const defaultPeriod = 10 * time.Second
+
+var logger = loggo.GetLogger("juju.worker.healthcheck")
+
+// ManifoldConfig identifies the resource names upon which the health
+// check manifold depends.
+type ManifoldConfig struct {
+	Period *time.Duration
+
+	AgentName     string
+	APICallerName string
+	CharmDirName  string
+}
+
+// Manifold returns a health check manifold.
+func Manifold(config ManifoldConfig) dependency.Manifold {
+	return dependency.Manifold{
+		Inputs: []string{
+			config.AgentName,
+			config.APICallerName,
+			config.CharmDirName,
+		},
+		Start: func(getResource dependency.GetResourceFunc) (worker.Worker, error) {
+			checker, err := newHealthCheck(config, getResource)
+			if err != nil {
+				return nil, err
+			}
+			return worker.NewPeriodicWorker(checker.Do, checker.period, worker.NewTimer), nil
+		},
+	}
+}
+
+// HealthReporter reports the results of a unit's health checks.
+type HealthReporter interface {
+	SetUnitHealth(status params.Status, info string, data map[string]interface{}) error
+}
+
+var newHealthCheck = func(config ManifoldConfig, getResource dependency.GetResourceFunc) (*healthCheck, error) {
+	period := defaultPeriod
+	if config.Period != nil {
+		period = *config.Period
+	}
+
+	var agent agent.Agent
+	if err := getResource(config.AgentName, &agent); err != nil {
+		return nil, err
+	}
+	agentConfig := agent.CurrentConfig()
+	unitTag, ok := agentConfig.Tag().(names.UnitTag)
+	if !ok {
+		return nil, errors.Errorf("expected a unit tag, got %v", agentConfig.Tag())
+	}
+
+	var apiCaller base.APICaller
+	if err := getResource(config.APICallerName, &apiCaller); err != nil {
+		return nil, err
+	}
+
+	var charmdir fortress.Guest
+	if err := getResource(config.CharmDirName, &charmdir); err != nil {
+		return nil, err
+	}
+
+	paths := uniter.NewWorkerPaths(agentConfig.DataDir(), unitTag, "health-check")
+	return newChecker(&unitHealthReporter{
+		st:      uniterapi.NewState(apiCaller, unitTag),
+		unitTag: unitTag,
+	}, charmdir, paths.GetCharmDir(), clock.WallClock, period), nil
+}
+
+// unitHealthReporter reports health to the uniter facade, looking up
+// the unit the first time it is needed.
+type unitHealthReporter struct {
+	st      *uniterapi.State
+	unitTag names.UnitTag
+	unit    *uniterapi.Unit
+}
+
+// SetUnitHealth is part of the HealthReporter interface.
+func (r *unitHealthReporter) SetUnitHealth(status params.Status, info string, data map[string]interface{}) error {
+	if r.unit == nil {
+		unit, err := r.st.Unit(r.unitTag)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		r.unit = unit
+	}
+	return r.unit.SetUnitHealth(status, info, data)
+}
+
+// newChecker returns a health check that runs the checks declared by
+// the charm in charmDir when they are due, and reports their results
+// whenever they change. Its Do method is intended to be called every
+// period by a periodic worker.
+func newChecker(reporter HealthReporter, charmdir fortress.Guest, charmDir string, clock clock.Clock, period time.Duration) *healthCheck {
+	return &healthCheck{
+		reporter: reporter,
+		charmdir: charmdir,
+		charmDir: charmDir,
+		clock:    clock,
+		period:   period,
+		lastRun:  make(map[string]time.Time),
+		results:  make(map[string]error),
+	}
+}
+
+type healthCheck struct {
+	reporter HealthReporter
+	charmdir fortress.Guest
+	charmDir string
+	clock    clock.Clock
+	period   time.Duration
+
+	// lastRun and results hold when each check last ran, and why it
+	// failed if it did.
+	lastRun map[string]time.Time
+	results map[string]error
+
+	// reported holds the health last reported, so that unchanged
+	// results are not reported again.
+	reported *params.StatusResult
+}
+
+// Do satisfies the worker.PeriodWorkerCall function type.
+func (h *healthCheck) Do(stop <-chan struct{}) error {
+	err := h.charmdir.Visit(h.do, stop)
+	if err == fortress.ErrAborted {
+		logger.Tracef("cannot run health checks: %v", err)
+		return nil
+	}
+	return err
+}
+
+func (h *healthCheck) do() error {
+	checks, err := ReadChecks(h.charmDir)
+	if err != nil {
+		// Report a broken health file as a failure, rather than
+		// restarting the worker over it until the charm is fixed.
+		return h.report(params.StatusUnhealthy, fmt.Sprintf("cannot read health checks: %v", err), nil)
+	}
+	if len(checks) == 0 {
+		return nil
+	}
+
+	now := h.clock.Now()
+	declared := make(map[string]bool)
+	for _, check := range checks {
+		declared[check.Name] = true
+		if last, ok := h.lastRun[check.Name]; ok && now.Sub(last) < check.Interval {
+			continue
+		}
+		logger.Tracef("running health check %q", check.Name)
+		h.lastRun[check.Name] = now
+		h.results[check.Name] = check.Run(h.charmDir)
+	}
+	// Forget checks the charm no longer declares.
+	for name := range h.results {
+		if !declared[name] {
+			delete(h.results, name)
+			delete(h.lastRun, name)
+		}
+	}
+
+	var failed []string
+	var failure error
+	data := make(map[string]interface{})
+	for _, check := range checks {
+		if err := h.results[check.Name]; err != nil {
+			failed = append(failed, fmt.Sprintf("%q", check.Name))
+			failure = err
+			data[check.Name] = err.Error()
+		}
+	}
+	switch len(failed) {
+	case 0:
+		return h.report(params.StatusHealthy, "", nil)
+	case 1:
+		return h.report(params.StatusUnhealthy, fmt.Sprintf("check %s failed: %v", failed[0], failure), data)
+	}
+	return h.report(params.StatusUnhealthy, fmt.Sprintf("checks %s failed", strings.Join(failed, ", ")), data)
+}
+
+// report reports the unit's health, unless it is unchanged since it
+// was last reported.
+func (h *healthCheck) report(status params.Status, info string, data map[string]interface{}) error {
+	if h.reported != nil && h.reported.Status == status && h.reported.Info == info {
+		return nil
+	}
+	err := h.reporter.SetUnitHealth(status, info, data)
+	if errors.IsNotImplemented(err) {
+		logger.Debugf("cannot report unit health: %v", err)
+		return nil
+	} else if err != nil {
+		return errors.Annotate(err, "cannot report unit health")
+	}
+	h.reported = &params.StatusResult{Status: status, Info: info}
+	return nil
+}
@@ -0,0 +1,251 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package healthcheck_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/agent"
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/apiserver/params"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker/dependency"
+	dt "github.com/juju/juju/worker/dependency/testing"
+	"github.com/juju/juju/worker/fortress"
+	"github.com/juju/juju/worker/healthcheck"
+)
+
+type ManifoldSuite struct {
+	coretesting.BaseSuite
+
+	manifold       dependency.Manifold
+	dummyResources dt.StubResources
+}
+
+var _ = gc.Suite(&ManifoldSuite{})
+
+func (s *ManifoldSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.manifold = healthcheck.Manifold(healthcheck.ManifoldConfig{
+		AgentName:     "agent-name",
+		APICallerName: "apicaller-name",
+		CharmDirName:  "charmdir-name",
+	})
+	s.dummyResources = dt.StubResources{
+		"agent-name":     dt.StubResource{Output: &dummyAgent{dataDir: c.MkDir()}},
+		"apicaller-name": dt.StubResource{Output: &dummyAPICaller{}},
+		"charmdir-name":  dt.StubResource{Output: &dummyCharmdir{}},
+	}
+}
+
+func (s *ManifoldSuite) TestInputs(c *gc.C) {
+	c.Check(s.manifold.Inputs, jc.DeepEquals, []string{
+		"agent-name", "apicaller-name", "charmdir-name",
+	})
+}
+
+func (s *ManifoldSuite) TestStartMissingDeps(c *gc.C) {
+	for _, missingDep := range []string{
+		"agent-name", "apicaller-name", "charmdir-name",
+	} {
+		testResources := dt.StubResources{}
+		for k, v := range s.dummyResources {
+			if k == missingDep {
+				testResources[k] = dt.StubResource{Error: dependency.ErrMissing}
+			} else {
+				testResources[k] = v
+			}
+		}
+		worker, err := s.manifold.Start(dt.StubGetResource(testResources))
+		c.Check(worker, gc.IsNil)
+		c.Check(err, gc.Equals, dependency.ErrMissing)
+	}
+}
+
+func (s *ManifoldSuite) TestWorkerStarts(c *gc.C) {
+	worker, err := s.manifold.Start(dt.StubGetResource(s.dummyResources))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(worker, gc.NotNil)
+	worker.Kill()
+	err = worker.Wait()
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+type CheckerSuite struct {
+	coretesting.BaseSuite
+
+	charmDir string
+	reporter *fakeReporter
+	charmdir *dummyCharmdir
+	clock    *coretesting.Clock
+}
+
+var _ = gc.Suite(&CheckerSuite{})
+
+func (s *CheckerSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.charmDir = c.MkDir()
+	s.reporter = &fakeReporter{}
+	s.charmdir = &dummyCharmdir{}
+	s.clock = coretesting.NewClock(time.Now())
+}
+
+func (s *CheckerSuite) writeChecks(c *gc.C, content string) {
+	err := ioutil.WriteFile(filepath.Join(s.charmDir, healthcheck.HealthFile), []byte(content), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *CheckerSuite) do(c *gc.C, checker interface {
+	Do(<-chan struct{}) error
+}) {
+	err := checker.Do(nil)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *CheckerSuite) newChecker() interface {
+	Do(<-chan struct{}) error
+} {
+	return healthcheck.NewChecker(s.reporter, s.charmdir, s.charmDir, s.clock, time.Second)
+}
+
+func (s *CheckerSuite) TestNoChecks(c *gc.C) {
+	s.do(c, s.newChecker())
+	c.Assert(s.reporter.reports, gc.HasLen, 0)
+}
+
+func (s *CheckerSuite) TestCharmDirUnavailable(c *gc.C) {
+	s.writeChecks(c, "checks: {daemon: {command: 'exit 0'}}")
+	s.charmdir.aborted = true
+	s.do(c, s.newChecker())
+	c.Assert(s.reporter.reports, gc.HasLen, 0)
+}
+
+func (s *CheckerSuite) TestReportsChanges(c *gc.C) {
+	s.writeChecks(c, "checks: {daemon: {command: 'exit 0', interval: 1m}}")
+	checker := s.newChecker()
+	s.do(c, checker)
+	s.do(c, checker)
+	c.Assert(s.reporter.reports, jc.DeepEquals, []report{{
+		status: params.StatusHealthy,
+	}})
+
+	// The check does not run again until its interval has passed.
+	s.writeChecks(c, "checks: {daemon: {command: 'echo stopped && exit 1', interval: 1m}}")
+	s.do(c, checker)
+	c.Assert(s.reporter.reports, gc.HasLen, 1)
+
+	s.clock.Advance(time.Minute)
+	s.do(c, checker)
+	c.Assert(s.reporter.reports, jc.DeepEquals, []report{{
+		status: params.StatusHealthy,
+	}, {
+		status: params.StatusUnhealthy,
+		info:   `check "daemon" failed: exit status 1: stopped`,
+		data:   map[string]interface{}{"daemon": "exit status 1: stopped"},
+	}})
+}
+
+func (s *CheckerSuite) TestSeveralFailures(c *gc.C) {
+	s.writeChecks(c, `
+checks:
+  daemon: {command: 'exit 1'}
+  web: {command: 'exit 2'}
+  db: {command: 'exit 0'}
+`)
+	s.do(c, s.newChecker())
+	c.Assert(s.reporter.reports, jc.DeepEquals, []report{{
+		status: params.StatusUnhealthy,
+		info:   `checks "daemon", "web" failed`,
+		data: map[string]interface{}{
+			"daemon": "exit status 1",
+			"web":    "exit status 2",
+		},
+	}})
+}
+
+func (s *CheckerSuite) TestInvalidChecks(c *gc.C) {
+	s.writeChecks(c, "checks: {daemon: {}}")
+	s.do(c, s.newChecker())
+	c.Assert(s.reporter.reports, jc.DeepEquals, []report{{
+		status: params.StatusUnhealthy,
+		info:   `cannot read health checks: invalid health check "daemon": expected one of command, http or tcp`,
+	}})
+}
+
+func (s *CheckerSuite) TestReportError(c *gc.C) {
+	s.writeChecks(c, "checks: {daemon: {command: 'exit 0'}}")
+	s.reporter.err = errors.New("boom")
+	err := s.newChecker().Do(nil)
+	c.Assert(err, gc.ErrorMatches, "cannot report unit health: boom")
+
+	s.reporter.err = errors.NotImplementedf("SetUnitHealth")
+	s.do(c, s.newChecker())
+}
+
+type report struct {
+	status params.Status
+	info   string
+	data   map[string]interface{}
+}
+
+type fakeReporter struct {
+	reports []report
+	err     error
+}
+
+func (r *fakeReporter) SetUnitHealth(status params.Status, info string, data map[string]interface{}) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.reports = append(r.reports, report{status, info, data})
+	return nil
+}
+
+type dummyAgent struct {
+	agent.Agent
+	dataDir string
+}
+
+func (a dummyAgent) CurrentConfig() agent.Config {
+	return &dummyAgentConfig{dataDir: a.dataDir}
+}
+
+type dummyAgentConfig struct {
+	agent.Config
+	dataDir string
+}
+
+// Tag implements agent.AgentConfig.
+func (ac dummyAgentConfig) Tag() names.Tag {
+	return names.NewUnitTag("u/0")
+}
+
+// DataDir implements agent.AgentConfig.
+func (ac dummyAgentConfig) DataDir() string {
+	return ac.dataDir
+}
+
+type dummyAPICaller struct {
+	base.APICaller
+}
+
+type dummyCharmdir struct {
+	fortress.Guest
+
+	aborted bool
+}
+
+func (a *dummyCharmdir) Visit(visit fortress.Visit, _ fortress.Abort) error {
+	if a.aborted {
+		return fortress.ErrAborted
+	}
+	return visit()
+}
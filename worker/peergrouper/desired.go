@@ -82,6 +82,9 @@ func desiredPeerGroup(info *peerGroupInfo) ([]replicaset.Member, map[*machine]bo
 	if updateAddresses(members, info.machines) {
 		changed = true
 	}
+	if updateHidden(members, machineVoting) {
+		changed = true
+	}
 	if !changed {
 		return nil, machineVoting, nil
 	}
@@ -161,6 +164,21 @@ func updateAddresses(members map[*machine]*replicaset.Member, machines map[strin
 	return changed
 }
 
+// updateHidden hides the members of machines that are to be hidden
+// once they have lost their vote, and unhides any others. It reports
+// whether any changes have been made.
+func updateHidden(members map[*machine]*replicaset.Member, machineVoting map[*machine]bool) bool {
+	changed := false
+	for m, member := range members {
+		hidden := m.hidden && !machineVoting[m]
+		if hidden != isHiddenMember(member) {
+			setMemberHidden(member, hidden)
+			changed = true
+		}
+	}
+	return changed
+}
+
 // adjustVotes adjusts the votes of the given machines, taking
 // care not to let the total number of votes become even at
 // any time. It calls setVoting to change the voting status
@@ -243,6 +261,18 @@ func setMemberVoting(member *replicaset.Member, voting bool) {
 	}
 }
 
+func isHiddenMember(member *replicaset.Member) bool {
+	return member.Hidden != nil && *member.Hidden
+}
+
+func setMemberHidden(member *replicaset.Member, hidden bool) {
+	if hidden {
+		member.Hidden = &hidden
+	} else {
+		member.Hidden = nil
+	}
+}
+
 type byId []*machine
 
 func (l byId) Len() int           { return len(l) }
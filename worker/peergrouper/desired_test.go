@@ -183,6 +183,34 @@ func desiredPeerGroupTests(ipVersion TestIPVersion) []desiredPeerGroupTest {
 			members:       mkMembers("1v 2v 3v", ipVersion),
 			expectVoting:  []bool{true, true, true},
 			expectMembers: nil,
+		}, {
+			about:         "new hidden machine is added as a hidden non-voter",
+			machines:      mkMachines("10v 11v 12v 13h", ipVersion),
+			statuses:      mkStatuses("0p 1s 2s", ipVersion),
+			members:       mkMembers("0v 1v 2v", ipVersion),
+			expectVoting:  []bool{true, true, true, false},
+			expectMembers: mkMembers("0v 1v 2v 3h", ipVersion),
+		}, {
+			about:         "voting machine that becomes hidden is hidden when its vote is removed",
+			machines:      mkMachines("10v 11v 12h 13v", ipVersion),
+			statuses:      mkStatuses("0p 1s 2s 3s", ipVersion),
+			members:       mkMembers("0v 1v 2v 3", ipVersion),
+			expectVoting:  []bool{true, true, false, true},
+			expectMembers: mkMembers("0v 1v 2h 3v", ipVersion),
+		}, {
+			about:         "hidden machine that must keep its vote is not hidden",
+			machines:      mkMachines("10v 11v 12h", ipVersion),
+			statuses:      mkStatuses("0p 1s 2s", ipVersion),
+			members:       mkMembers("0v 1v 2v", ipVersion),
+			expectVoting:  []bool{true, true, true},
+			expectMembers: nil,
+		}, {
+			about:         "machine that is no longer hidden is unhidden",
+			machines:      mkMachines("10v 11v 12v 13", ipVersion),
+			statuses:      mkStatuses("0p 1s 2s 3s", ipVersion),
+			members:       mkMembers("0v 1v 2v 3h", ipVersion),
+			expectVoting:  []bool{true, true, true, false},
+			expectMembers: mkMembers("0v 1v 2v 3", ipVersion),
 		}}
 }
 
@@ -256,11 +284,16 @@ func newFloat64(f float64) *float64 {
 	return &f
 }
 
+func newBool(b bool) *bool {
+	return &b
+}
+
 // mkMachines returns a slice of *machine based on
 // the given description.
 // Each machine in the description is white-space separated
 // and holds the decimal machine id followed by an optional
-// "v" if the machine wants a vote.
+// "v" if the machine wants a vote and an optional "h" if
+// the machine should be hidden.
 func mkMachines(description string, ipVersion TestIPVersion) []*machine {
 	descrs := parseDescr(description)
 	ms := make([]*machine, len(descrs))
@@ -276,6 +309,7 @@ func mkMachines(description string, ipVersion TestIPVersion) []*machine {
 				Port: mongoPort,
 			}},
 			wantsVote: strings.Contains(d.flags, "v"),
+			hidden:    strings.Contains(d.flags, "h"),
 		}
 	}
 	return ms
@@ -291,6 +325,7 @@ func memberTag(id string) map[string]string {
 // and holds the decimal replica-set id optionally followed by the characters:
 //	- 'v' if the member is voting.
 // 	- 'T' if the member has no associated machine tags.
+// 	- 'h' if the member is hidden.
 // Unless the T flag is specified, the machine tag
 // will be the replica-set id + 10.
 func mkMembers(description string, ipVersion TestIPVersion) []replicaset.Member {
@@ -310,6 +345,9 @@ func mkMembers(description string, ipVersion TestIPVersion) []replicaset.Member
 		if strings.Contains(d.flags, "T") {
 			m.Tags = nil
 		}
+		if strings.Contains(d.flags, "h") {
+			m.Hidden = newBool(true)
+		}
 		ms[i] = m
 	}
 	return ms
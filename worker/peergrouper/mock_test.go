@@ -237,6 +237,7 @@ type machineDoc struct {
 	id             string
 	wantsVote      bool
 	hasVote        bool
+	replicaSetRole state.ReplicaSetRole
 	instanceId     instance.Id
 	mongoHostPorts []network.HostPort
 	apiHostPorts   []network.HostPort
@@ -285,6 +286,12 @@ func (m *fakeMachine) WantsVote() bool {
 	return m.doc.wantsVote
 }
 
+func (m *fakeMachine) ReplicaSetRole() state.ReplicaSetRole {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.doc.replicaSetRole
+}
+
 func (m *fakeMachine) HasVote() bool {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -370,6 +377,12 @@ func (m *fakeMachine) setWantsVote(wantsVote bool) {
 	})
 }
 
+func (m *fakeMachine) setReplicaSetRole(role state.ReplicaSetRole) {
+	m.mutate(func(doc *machineDoc) {
+		doc.replicaSetRole = role
+	})
+}
+
 type fakeMongoSession struct {
 	// If InstantlyReady is true, replica status of
 	// all members will be instantly reported as ready.
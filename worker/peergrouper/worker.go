@@ -34,6 +34,7 @@ type stateMachine interface {
 	WantsVote() bool
 	HasVote() bool
 	SetHasVote(hasVote bool) error
+	ReplicaSetRole() state.ReplicaSetRole
 	APIHostPorts() []network.HostPort
 	MongoHostPorts() []network.HostPort
 }
@@ -442,6 +443,7 @@ func (infow *serverInfoWatcher) updateMachines() (bool, error) {
 type machine struct {
 	id             string
 	wantsVote      bool
+	hidden         bool
 	apiHostPorts   []network.HostPort
 	mongoHostPorts []network.HostPort
 
@@ -459,7 +461,7 @@ func (m *machine) String() string {
 }
 
 func (m *machine) GoString() string {
-	return fmt.Sprintf("&peergrouper.machine{id: %q, wantsVote: %v, hostPort: %q}", m.id, m.wantsVote, m.mongoHostPort())
+	return fmt.Sprintf("&peergrouper.machine{id: %q, wantsVote: %v, hidden: %v, hostPort: %q}", m.id, m.wantsVote, m.hidden, m.mongoHostPort())
 }
 
 func (w *pgWorker) newMachine(stm stateMachine) *machine {
@@ -470,6 +472,7 @@ func (w *pgWorker) newMachine(stm stateMachine) *machine {
 		apiHostPorts:   stm.APIHostPorts(),
 		mongoHostPorts: stm.MongoHostPorts(),
 		wantsVote:      stm.WantsVote(),
+		hidden:         stm.ReplicaSetRole() == state.ReplicaSetHidden,
 		machineWatcher: stm.Watch(),
 	}
 	w.start(m.loop)
@@ -512,6 +515,10 @@ func (m *machine) refresh() (bool, error) {
 		m.wantsVote = wantsVote
 		changed = true
 	}
+	if hidden := m.stm.ReplicaSetRole() == state.ReplicaSetHidden; hidden != m.hidden {
+		m.hidden = hidden
+		changed = true
+	}
 	if hps := m.stm.MongoHostPorts(); !hostPortsEqual(hps, m.mongoHostPorts) {
 		m.mongoHostPorts = hps
 		changed = true
@@ -15,20 +15,24 @@ import (
 type HistoryPrunerParams struct {
 	// TODO(perrito666) We might want to have some sort of limitation of the collection size too.
 	MaxLogsPerEntity int
+	MaxHistoryTime   time.Duration
 	PruneInterval    time.Duration
 }
 
 // Facade represents an API that implements status history pruning.
 type Facade interface {
-	Prune(int) error
+	Prune(maxLogsPerEntity int, maxHistoryTime time.Duration) error
 }
 
 // Config holds all necessary attributes to start a pruner worker.
 type Config struct {
 	Facade           Facade
 	MaxLogsPerEntity uint
-	PruneInterval    time.Duration
-	NewTimer         worker.NewTimerFunc
+	// MaxHistoryTime, if non-zero, is the age beyond which
+	// status history entries are removed.
+	MaxHistoryTime time.Duration
+	PruneInterval  time.Duration
+	NewTimer       worker.NewTimerFunc
 }
 
 // Validate will err unless basic requirements for a valid
@@ -40,6 +44,9 @@ func (c *Config) Validate() error {
 	if c.NewTimer == nil {
 		return errors.New("missing Timer")
 	}
+	if c.MaxHistoryTime < 0 {
+		return errors.New("negative MaxHistoryTime")
+	}
 	return nil
 }
 
@@ -49,7 +56,7 @@ func New(conf Config) (worker.Worker, error) {
 		return nil, errors.Trace(err)
 	}
 	doPruning := func(stop <-chan struct{}) error {
-		err := conf.Facade.Prune(int(conf.MaxLogsPerEntity))
+		err := conf.Facade.Prune(int(conf.MaxLogsPerEntity), conf.MaxHistoryTime)
 		if err != nil {
 			return errors.Trace(err)
 		}
@@ -34,6 +34,7 @@ func (s *statusHistoryPrunerSuite) TestWorkerCallsPrune(c *gc.C) {
 	conf := statushistorypruner.Config{
 		Facade:           facade,
 		MaxLogsPerEntity: 3,
+		MaxHistoryTime:   time.Hour,
 		PruneInterval:    coretesting.ShortWait,
 		NewTimer:         fakeTimerFunc,
 	}
@@ -54,6 +55,7 @@ func (s *statusHistoryPrunerSuite) TestWorkerCallsPrune(c *gc.C) {
 		c.Fatal("timed out waiting for passed logs to pruner")
 	}
 	c.Assert(passedLogs, gc.Equals, 3)
+	c.Assert(<-facade.passedMaxHistoryTime, gc.Equals, time.Hour)
 
 	// Reset will have been called with the actual PruneInterval
 	var period time.Duration
@@ -95,6 +97,16 @@ func (s *statusHistoryPrunerSuite) TestWorkerWontCallPruneBeforeFiringTimer(c *g
 	}
 }
 
+func (s *statusHistoryPrunerSuite) TestValidateNegativeMaxHistoryTime(c *gc.C) {
+	conf := statushistorypruner.Config{
+		Facade:         newFakeFacade(),
+		MaxHistoryTime: -time.Hour,
+		NewTimer:       worker.NewTimer,
+	}
+	_, err := statushistorypruner.New(conf)
+	c.Assert(err, gc.ErrorMatches, "negative MaxHistoryTime")
+}
+
 type mockTimer struct {
 	period chan time.Duration
 	c      chan time.Time
@@ -129,21 +141,24 @@ func newMockTimer(d time.Duration) *mockTimer {
 }
 
 type fakeFacade struct {
-	passedMaxLogs chan int
+	passedMaxLogs        chan int
+	passedMaxHistoryTime chan time.Duration
 }
 
 func newFakeFacade() *fakeFacade {
 	return &fakeFacade{
-		passedMaxLogs: make(chan int, 1),
+		passedMaxLogs:        make(chan int, 1),
+		passedMaxHistoryTime: make(chan time.Duration, 1),
 	}
 }
 
 // Prune implements Facade
-func (f *fakeFacade) Prune(maxLogs int) error {
+func (f *fakeFacade) Prune(maxLogs int, maxHistoryTime time.Duration) error {
 	select {
 	case f.passedMaxLogs <- maxLogs:
 	case <-time.After(coretesting.LongWait):
 		return errors.New("timed out waiting for facade call Prune to run")
 	}
+	f.passedMaxHistoryTime <- maxHistoryTime
 	return nil
 }
@@ -90,6 +90,16 @@ func (opc *operationCallbacks) NotifyHookFailed(hook string, ctx runner.Context)
 	}
 }
 
+// RecordHook is part of the operation.Callbacks interface.
+func (opc *operationCallbacks) RecordHook(record params.HookRecord) error {
+	err := opc.u.unit.AddHookRecord(record)
+	if errors.IsNotImplemented(err) {
+		// Older state servers do not keep hook records.
+		return nil
+	}
+	return errors.Trace(err)
+}
+
 // FailAction is part of the operation.Callbacks interface.
 func (opc *operationCallbacks) FailAction(actionId, message string) error {
 	if !names.IsValidAction(actionId) {
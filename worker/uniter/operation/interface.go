@@ -9,6 +9,7 @@ import (
 	utilexec "github.com/juju/utils/exec"
 	corecharm "gopkg.in/juju/charm.v6-unstable"
 
+	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/worker/uniter/charm"
 	"github.com/juju/juju/worker/uniter/hook"
 	"github.com/juju/juju/worker/uniter/runner"
@@ -142,6 +143,10 @@ type Callbacks interface {
 	NotifyHookCompleted(string, runner.Context)
 	NotifyHookFailed(string, runner.Context)
 
+	// RecordHook records the execution of a hook, for the unit's hook
+	// log. It's only used by RunHook operations.
+	RecordHook(params.HookRecord) error
+
 	// The following methods exist primarily to allow us to test operation code
 	// without using a live api connection.
 
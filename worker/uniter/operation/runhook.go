@@ -5,6 +5,8 @@ package operation
 
 import (
 	"fmt"
+	"os/exec"
+	"time"
 
 	"github.com/juju/errors"
 	"gopkg.in/juju/charm.v6-unstable/hooks"
@@ -91,8 +93,13 @@ func (rh *runHook) Execute(state State) (*State, error) {
 	ranHook := true
 	step := Done
 
+	started := time.Now()
 	err := rh.runner.RunHook(rh.name)
+	duration := time.Since(started)
 	cause := errors.Cause(err)
+	if !context.IsMissingHookError(cause) {
+		rh.recordHook(started, duration, cause)
+	}
 	switch {
 	case context.IsMissingHookError(cause):
 		ranHook = false
@@ -103,6 +110,17 @@ func (rh *runHook) Execute(state State) (*State, error) {
 	case cause == context.ErrReboot:
 		err = ErrNeedsReboot
 	case err == nil:
+	case context.IsHookTimeoutError(cause):
+		logger.Errorf("hook %q failed: %v", rh.name, err)
+		rh.callbacks.NotifyHookFailed(rh.name, rh.runner.Context())
+		// Record the timeout, so the unit's error status can say why
+		// the hook failed.
+		return stateChange{
+			Kind:         RunHook,
+			Step:         Pending,
+			Hook:         &rh.info,
+			HookTimedOut: true,
+		}.apply(state), ErrHookFailed
 	default:
 		logger.Errorf("hook %q failed: %v", rh.name, err)
 		rh.callbacks.NotifyHookFailed(rh.name, rh.runner.Context())
@@ -129,6 +147,43 @@ func (rh *runHook) Execute(state State) (*State, error) {
 	}.apply(state), err
 }
 
+// recordHook adds the hook's execution to the unit's hook log. Failure
+// to do so is logged but does not affect the hook's outcome.
+func (rh *runHook) recordHook(started time.Time, duration time.Duration, cause error) {
+	record := params.HookRecord{
+		Hook:       rh.name,
+		RelationId: -1,
+		RemoteUnit: rh.info.RemoteUnit,
+		Started:    started.UTC(),
+		Duration:   duration,
+		ExitStatus: hookExitStatus(cause),
+		Stderr:     rh.runner.HookStderr(),
+	}
+	if rh.info.Kind.IsRelation() {
+		record.RelationId = rh.info.RelationId
+	}
+	if err := rh.callbacks.RecordHook(record); err != nil {
+		logger.Warningf("cannot record %q hook: %v", rh.name, err)
+	}
+}
+
+// hookExitStatus returns the exit status of a hook process that
+// finished with the given error, or -1 if it cannot be determined.
+func hookExitStatus(cause error) int {
+	switch cause {
+	case nil, context.ErrReboot, context.ErrRequeueAndReboot:
+		return 0
+	}
+	if exitErr, ok := cause.(*exec.ExitError); ok {
+		if status, ok := exitErr.Sys().(interface {
+			ExitStatus() int
+		}); ok {
+			return status.ExitStatus()
+		}
+	}
+	return -1
+}
+
 func (rh *runHook) beforeHook() error {
 	var err error
 	switch rh.info.Kind {
@@ -85,6 +85,10 @@ type State struct {
 	// upgrade is complete (instead of running an upgrade-charm hook).
 	Hook *hook.Info `yaml:"hook,omitempty"`
 
+	// HookTimedOut indicates that the hook run by a RunHook operation
+	// was killed because it ran for longer than the hook timeout.
+	HookTimedOut bool `yaml:"hook-timed-out,omitempty"`
+
 	// ActionId holds action information relevant to the current operation. If
 	// Kind is Continue, it holds the last action that was executed; if Kind is
 	// RunAction, it holds the running action.
@@ -165,6 +169,7 @@ type stateChange struct {
 	ActionId        *string
 	CharmURL        *charm.URL
 	HasRunStatusSet bool
+	HookTimedOut    bool
 }
 
 func (change stateChange) apply(state State) *State {
@@ -173,6 +178,7 @@ func (change stateChange) apply(state State) *State {
 	state.Hook = change.Hook
 	state.ActionId = change.ActionId
 	state.CharmURL = change.CharmURL
+	state.HookTimedOut = change.HookTimedOut
 	state.StatusSet = state.StatusSet || change.HasRunStatusSet
 	return &state
 }
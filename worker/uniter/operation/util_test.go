@@ -10,6 +10,7 @@ import (
 	corecharm "gopkg.in/juju/charm.v6-unstable"
 	"gopkg.in/juju/charm.v6-unstable/hooks"
 
+	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/worker/uniter/charm"
 	"github.com/juju/juju/worker/uniter/hook"
 	"github.com/juju/juju/worker/uniter/operation"
@@ -185,6 +186,7 @@ type ExecuteHookCallbacks struct {
 	*PrepareHookCallbacks
 	MockNotifyHookCompleted *MockNotify
 	MockNotifyHookFailed    *MockNotify
+	recordedHooks           []params.HookRecord
 }
 
 func (cb *ExecuteHookCallbacks) NotifyHookCompleted(hookName string, ctx runner.Context) {
@@ -195,6 +197,11 @@ func (cb *ExecuteHookCallbacks) NotifyHookFailed(hookName string, ctx runner.Con
 	cb.MockNotifyHookFailed.Call(hookName, ctx)
 }
 
+func (cb *ExecuteHookCallbacks) RecordHook(record params.HookRecord) error {
+	cb.recordedHooks = append(cb.recordedHooks, record)
+	return nil
+}
+
 type MockCommitHook struct {
 	gotHook *hook.Info
 	err     error
@@ -363,6 +370,7 @@ type MockRunner struct {
 	*MockRunCommands
 	*MockRunHook
 	context runner.Context
+	stderr  []string
 }
 
 func (r *MockRunner) Context() runner.Context {
@@ -377,6 +385,10 @@ func (r *MockRunner) RunCommands(commands string) (*utilexec.ExecResponse, error
 	return r.MockRunCommands.Call(commands)
 }
 
+func (r *MockRunner) HookStderr() []string {
+	return r.stderr
+}
+
 func (r *MockRunner) RunHook(hookName string) error {
 	r.Context().(*MockContext).setStatusCalled = r.MockRunHook.setStatusCalled
 	return r.MockRunHook.Call(hookName)
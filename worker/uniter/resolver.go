@@ -17,7 +17,7 @@ import (
 // ResolverConfig defines configuration for the uniter resolver.
 type ResolverConfig struct {
 	ClearResolved       func() error
-	ReportHookError     func(hook.Info) error
+	ReportHookError     func(info hook.Info, timedOut bool) error
 	FixDeployer         func() error
 	StartRetryHookTimer func()
 	StopRetryHookTimer  func()
@@ -161,7 +161,7 @@ func (s *uniterResolver) nextOpHookError(
 ) (operation.Operation, error) {
 
 	// Report the hook error.
-	if err := s.config.ReportHookError(*localState.Hook); err != nil {
+	if err := s.config.ReportHookError(*localState.Hook, localState.HookTimedOut); err != nil {
 		return nil, errors.Trace(err)
 	}
 
@@ -32,7 +32,7 @@ type resolverSuite struct {
 	resolver    resolver.Resolver
 
 	clearResolved   func() error
-	reportHookError func(hook.Info) error
+	reportHookError func(hook.Info, bool) error
 }
 
 var _ = gc.Suite(&resolverSuite{})
@@ -52,13 +52,13 @@ func (s *resolverSuite) SetUpTest(c *gc.C) {
 		return errors.New("unexpected resolved")
 	}
 
-	s.reportHookError = func(hook.Info) error {
+	s.reportHookError = func(hook.Info, bool) error {
 		return errors.New("unexpected report hook error")
 	}
 
 	s.resolver = uniter.NewUniterResolver(uniter.ResolverConfig{
 		ClearResolved:       func() error { return s.clearResolved() },
-		ReportHookError:     func(info hook.Info) error { return s.reportHookError(info) },
+		ReportHookError:     func(info hook.Info, timedOut bool) error { return s.reportHookError(info, timedOut) },
 		FixDeployer:         func() error { return nil },
 		StartRetryHookTimer: func() { s.stub.AddCall("StartRetryHookTimer") },
 		StopRetryHookTimer:  func() { s.stub.AddCall("StopRetryHookTimer") },
@@ -103,7 +103,7 @@ func (s *resolverSuite) TestNotStartedNotInstalled(c *gc.C) {
 }
 
 func (s *resolverSuite) TestHookErrorStartRetryTimer(c *gc.C) {
-	s.reportHookError = func(hook.Info) error { return nil }
+	s.reportHookError = func(hook.Info, bool) error { return nil }
 	localState := resolver.LocalState{
 		CharmURL: s.charmURL,
 		State: operation.State{
@@ -128,7 +128,7 @@ func (s *resolverSuite) TestHookErrorStartRetryTimer(c *gc.C) {
 }
 
 func (s *resolverSuite) TestHookErrorStartRetryTimerAgain(c *gc.C) {
-	s.reportHookError = func(hook.Info) error { return nil }
+	s.reportHookError = func(hook.Info, bool) error { return nil }
 	localState := resolver.LocalState{
 		CharmURL: s.charmURL,
 		State: operation.State{
@@ -171,7 +171,7 @@ func (s *resolverSuite) TestResolvedNoHooksStopRetryTimer(c *gc.C) {
 func (s *resolverSuite) testResolveHookErrorStopRetryTimer(c *gc.C, mode params.ResolvedMode) {
 	s.stub.ResetCalls()
 	s.clearResolved = func() error { return nil }
-	s.reportHookError = func(hook.Info) error { return nil }
+	s.reportHookError = func(hook.Info, bool) error { return nil }
 	localState := resolver.LocalState{
 		CharmURL: s.charmURL,
 		State: operation.State{
@@ -196,7 +196,7 @@ func (s *resolverSuite) testResolveHookErrorStopRetryTimer(c *gc.C, mode params.
 }
 
 func (s *resolverSuite) TestRunHookStopRetryTimer(c *gc.C) {
-	s.reportHookError = func(hook.Info) error { return nil }
+	s.reportHookError = func(hook.Info, bool) error { return nil }
 	localState := resolver.LocalState{
 		CharmURL: s.charmURL,
 		State: operation.State{
@@ -178,6 +178,10 @@ type HookContext struct {
 	// like a juju-run command or a hook
 	process HookProcess
 
+	// hookTimeout is how long a hook may run before it is killed;
+	// zero means hooks may run indefinitely.
+	hookTimeout time.Duration
+
 	// rebootPriority tells us when the hook wants to reboot. If rebootPriority is jujuc.RebootNow
 	// the hook will be killed and requeued
 	rebootPriority jujuc.RebootPriority
@@ -273,6 +277,12 @@ func (ctx *HookContext) SetProcess(process HookProcess) {
 	ctx.process = process
 }
 
+// HookTimeout returns how long a hook run in the context may run
+// before it is killed. Zero means it may run indefinitely.
+func (ctx *HookContext) HookTimeout() time.Duration {
+	return ctx.hookTimeout
+}
+
 func (ctx *HookContext) Id() string {
 	return ctx.id
 }
@@ -459,6 +469,10 @@ func (ctx *HookContext) OpenedPorts() []network.PortRange {
 	return unitRanges
 }
 
+func (ctx *HookContext) NetworkInfo(bindingNames []string) (map[string]params.NetworkInfoResult, error) {
+	return ctx.unit.NetworkInfo(bindingNames)
+}
+
 func (ctx *HookContext) ConfigSettings() (charm.Settings, error) {
 	if ctx.configSettings == nil {
 		var err error
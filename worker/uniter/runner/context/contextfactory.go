@@ -283,6 +283,16 @@ func (f *contextFactory) updateContext(ctx *HookContext) (err error) {
 	}
 	ctx.proxySettings = environConfig.ProxySettings()
 
+	ctx.hookTimeout, err = f.unit.HookTimeout()
+	if errors.IsNotImplemented(err) {
+		// Older state servers know nothing of service hook
+		// timeouts, so fall back to the environment's.
+		ctx.hookTimeout, err = environConfig.HookTimeout(), nil
+	}
+	if err != nil {
+		return errors.Trace(err)
+	}
+
 	// Calling these last, because there's a potential race: they're not guaranteed
 	// to be set in time to be needed for a hook. If they're not, we just leave them
 	// unset as we always have; this isn't great but it's about behaviour preservation.
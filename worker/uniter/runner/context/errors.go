@@ -4,6 +4,9 @@
 package context
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/juju/errors"
 )
 
@@ -27,3 +30,25 @@ func IsMissingHookError(err error) bool {
 func NewMissingHookError(hookName string) error {
 	return &missingHookError{hookName}
 }
+
+type hookTimeoutError struct {
+	hookName string
+	timeout  time.Duration
+}
+
+func (e *hookTimeoutError) Error() string {
+	return fmt.Sprintf("%s timed out after %v", e.hookName, e.timeout)
+}
+
+// IsHookTimeoutError returns whether err was returned because a hook
+// ran for longer than the hook timeout and was killed.
+func IsHookTimeoutError(err error) bool {
+	_, ok := err.(*hookTimeoutError)
+	return ok
+}
+
+// NewHookTimeoutError returns an error reporting that the named hook
+// was killed after running for the given timeout.
+func NewHookTimeoutError(hookName string, timeout time.Duration) error {
+	return &hookTimeoutError{hookName, timeout}
+}
@@ -157,6 +157,11 @@ type ContextNetworking interface {
 	// unit on its assigned machine. The result is sorted first by
 	// protocol, then by number.
 	OpenedPorts() []network.PortRange
+
+	// NetworkInfo returns the network information the executing unit
+	// should use for each of the given relation endpoints, taking
+	// into account the spaces the endpoints are bound to.
+	NetworkInfo(bindingNames []string) (map[string]params.NetworkInfoResult, error)
 }
 
 // ContextLeadership is the part of a hook context related to the
@@ -0,0 +1,96 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc
+
+import (
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"launchpad.net/gnuflag"
+)
+
+// NetworkGetCommand implements the network-get command.
+type NetworkGetCommand struct {
+	cmd.CommandBase
+	ctx Context
+
+	bindingName    string
+	primaryAddress bool
+
+	out cmd.Output
+}
+
+func NewNetworkGetCommand(ctx Context) (cmd.Command, error) {
+	return &NetworkGetCommand{ctx: ctx}, nil
+}
+
+// Info is part of the cmd.Command interface.
+func (c *NetworkGetCommand) Info() *cmd.Info {
+	doc := `
+network-get returns the network information the unit should use for
+the given relation endpoint (binding). If the endpoint was bound to a
+space when the service was deployed, the address is taken from that
+space; otherwise the unit's private address is used.
+
+With --primary-address, only the address is printed. Otherwise the
+address, the name of the network interface it is assigned to, the CIDR
+of its subnet and the space it belongs to are printed, where known.
+`
+	return &cmd.Info{
+		Name:    "network-get",
+		Args:    "<binding-name> [--primary-address]",
+		Purpose: "get network config",
+		Doc:     doc,
+	}
+}
+
+// SetFlags is part of the cmd.Command interface.
+func (c *NetworkGetCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.out.AddFlags(f, "smart", cmd.DefaultFormatters)
+	f.BoolVar(&c.primaryAddress, "primary-address", false, "get the primary address for the binding")
+}
+
+// Init is part of the cmd.Command interface.
+func (c *NetworkGetCommand) Init(args []string) error {
+	if len(args) < 1 {
+		return errors.New("no arguments specified")
+	}
+	c.bindingName = args[0]
+	if c.bindingName == "" {
+		return errors.Errorf("no binding name specified")
+	}
+	return cmd.CheckEmpty(args[1:])
+}
+
+// networkInfo is the output format of network-get without
+// --primary-address.
+type networkInfo struct {
+	Address       string `json:"address" yaml:"address"`
+	InterfaceName string `json:"interface-name,omitempty" yaml:"interface-name,omitempty"`
+	CIDR          string `json:"cidr,omitempty" yaml:"cidr,omitempty"`
+	Space         string `json:"space,omitempty" yaml:"space,omitempty"`
+}
+
+// Run is part of the cmd.Command interface.
+func (c *NetworkGetCommand) Run(ctx *cmd.Context) error {
+	results, err := c.ctx.NetworkInfo([]string{c.bindingName})
+	if err != nil {
+		return errors.Trace(err)
+	}
+	result, ok := results[c.bindingName]
+	if !ok {
+		return errors.NotFoundf("network info for binding %q", c.bindingName)
+	}
+	if result.Error != nil {
+		return errors.Trace(result.Error)
+	}
+	if c.primaryAddress {
+		return c.out.Write(ctx, result.Info.Address)
+	}
+	return c.out.Write(ctx, networkInfo{
+		Address:       result.Info.Address,
+		InterfaceName: result.Info.InterfaceName,
+		CIDR:          result.Info.CIDR,
+		Space:         result.Info.SpaceName,
+	})
+}
@@ -0,0 +1,99 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc_test
+
+import (
+	"github.com/juju/cmd"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/testing"
+	"github.com/juju/juju/worker/uniter/runner/jujuc"
+)
+
+type NetworkGetSuite struct {
+	ContextSuite
+}
+
+var _ = gc.Suite(&NetworkGetSuite{})
+
+func (s *NetworkGetSuite) createCommand(c *gc.C) cmd.Command {
+	hctx := s.GetHookContext(c, -1, "")
+	hctx.info.NetworkInterface.NetworkInfo = map[string]params.NetworkInfoResult{
+		"db": {Info: params.NetworkInfo{
+			SpaceName:     "internal",
+			Address:       "10.10.0.5",
+			InterfaceName: "eth1",
+			CIDR:          "10.10.0.0/24",
+		}},
+		"website": {Info: params.NetworkInfo{
+			Address: "192.168.0.99",
+		}},
+		"cache": {Error: &params.Error{
+			Message: `endpoint "cache" of service "u" not found`,
+			Code:    params.CodeNotFound,
+		}},
+	}
+	com, err := jujuc.NewCommand(hctx, cmdString("network-get"))
+	c.Assert(err, jc.ErrorIsNil)
+	return com
+}
+
+func (s *NetworkGetSuite) TestNetworkGet(c *gc.C) {
+	for i, t := range []struct {
+		args []string
+		out  string
+	}{{
+		args: []string{"db", "--primary-address"},
+		out:  "10.10.0.5\n",
+	}, {
+		args: []string{"website", "--primary-address"},
+		out:  "192.168.0.99\n",
+	}, {
+		args: []string{"db", "--format", "yaml"},
+		out:  "address: 10.10.0.5\ninterface-name: eth1\ncidr: 10.10.0.0/24\nspace: internal\n",
+	}, {
+		args: []string{"website", "--format", "json"},
+		out:  `{"address":"192.168.0.99"}` + "\n",
+	}} {
+		c.Logf("test %d: %v", i, t.args)
+		com := s.createCommand(c)
+		ctx := testing.Context(c)
+		code := cmd.Main(com, ctx, t.args)
+		c.Check(code, gc.Equals, 0)
+		c.Check(bufferString(ctx.Stderr), gc.Equals, "")
+		c.Check(bufferString(ctx.Stdout), gc.Equals, t.out)
+	}
+}
+
+func (s *NetworkGetSuite) TestNetworkGetErrors(c *gc.C) {
+	for i, t := range []struct {
+		args []string
+		code int
+		err  string
+	}{{
+		code: 2,
+		err:  "error: no arguments specified\n",
+	}, {
+		args: []string{"db", "website"},
+		code: 2,
+		err:  "error: unrecognized args: [\"website\"]\n",
+	}, {
+		args: []string{"cache", "--primary-address"},
+		code: 1,
+		err:  "error: endpoint \"cache\" of service \"u\" not found\n",
+	}, {
+		args: []string{"admin", "--primary-address"},
+		code: 1,
+		err:  "error: network info for binding \"admin\" not found\n",
+	}} {
+		c.Logf("test %d: %v", i, t.args)
+		com := s.createCommand(c)
+		ctx := testing.Context(c)
+		code := cmd.Main(com, ctx, t.args)
+		c.Check(code, gc.Equals, t.code)
+		c.Check(bufferString(ctx.Stderr), gc.Equals, t.err)
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc
+
+import (
+	"fmt"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/resource"
+)
+
+// ResourceGetCmdName is the name of the resource-get command.
+const ResourceGetCmdName = "resource-get"
+
+// ContextResources is the part of a hook context related to the
+// charm resources of the unit's service.
+type ContextResources interface {
+	// Download makes the current revision of the named resource
+	// available to the unit, fetching it only if the unit does not
+	// already have it, and returns the path to the file.
+	Download(name string) (string, error)
+}
+
+// ResourceGetCommand implements the resource-get command.
+type ResourceGetCommand struct {
+	cmd.CommandBase
+	ctx  Context
+	name string
+}
+
+// NewResourceGetCommand returns a new resource-get command.
+func NewResourceGetCommand(ctx Context) (cmd.Command, error) {
+	return &ResourceGetCommand{ctx: ctx}, nil
+}
+
+func (c *ResourceGetCommand) Info() *cmd.Info {
+	doc := `
+resource-get fetches the current revision of a resource declared by the
+charm, if the unit does not already have it, and prints the path of the
+file holding it. It fails if no revision of the resource has been pushed
+with "juju push-resource".
+`
+	return &cmd.Info{
+		Name:    ResourceGetCmdName,
+		Args:    "<resource name>",
+		Purpose: "get the path to the locally stored resource file",
+		Doc:     doc,
+	}
+}
+
+func (c *ResourceGetCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return errors.New("no resource name specified")
+	}
+	c.name = args[0]
+	return cmd.CheckEmpty(args[1:])
+}
+
+func (c *ResourceGetCommand) Run(ctx *cmd.Context) error {
+	component, err := c.ctx.Component(resource.ComponentName)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	resources, ok := component.(ContextResources)
+	if !ok {
+		return errors.Errorf("wrong component context type registered: %T", component)
+	}
+	path, err := resources.Download(c.name)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	fmt.Fprintln(ctx.Stdout, path)
+	return nil
+}
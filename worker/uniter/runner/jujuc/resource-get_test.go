@@ -0,0 +1,69 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc_test
+
+import (
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/resource"
+	"github.com/juju/juju/testing"
+	"github.com/juju/juju/worker/uniter/runner/jujuc"
+)
+
+type ResourceGetSuite struct {
+	ContextSuite
+}
+
+var _ = gc.Suite(&ResourceGetSuite{})
+
+type fakeContextResources struct {
+	paths map[string]string
+}
+
+func (f fakeContextResources) Download(name string) (string, error) {
+	path, ok := f.paths[name]
+	if !ok {
+		return "", errors.NotFoundf("resource %q", name)
+	}
+	return path, nil
+}
+
+func (f fakeContextResources) Flush() error {
+	return nil
+}
+
+func (s *ResourceGetSuite) newCommand(c *gc.C) cmd.Command {
+	hctx := s.newHookContext(c)
+	hctx.info.SetComponent(resource.ComponentName, fakeContextResources{
+		paths: map[string]string{"licence": "/var/lib/juju/resources/licence/licence.txt"},
+	})
+	com, err := jujuc.NewResourceGetCommand(hctx)
+	c.Assert(err, jc.ErrorIsNil)
+	return com
+}
+
+func (s *ResourceGetSuite) TestRun(c *gc.C) {
+	ctx := testing.Context(c)
+	code := cmd.Main(s.newCommand(c), ctx, []string{"licence"})
+	c.Check(code, gc.Equals, 0)
+	c.Check(bufferString(ctx.Stderr), gc.Equals, "")
+	c.Check(bufferString(ctx.Stdout), gc.Equals, "/var/lib/juju/resources/licence/licence.txt\n")
+}
+
+func (s *ResourceGetSuite) TestRunNotFound(c *gc.C) {
+	ctx := testing.Context(c)
+	code := cmd.Main(s.newCommand(c), ctx, []string{"store"})
+	c.Check(code, gc.Equals, 1)
+	c.Check(bufferString(ctx.Stderr), gc.Equals, "error: resource \"store\" not found\n")
+}
+
+func (s *ResourceGetSuite) TestInit(c *gc.C) {
+	ctx := testing.Context(c)
+	code := cmd.Main(s.newCommand(c), ctx, nil)
+	c.Check(code, gc.Equals, 2)
+	c.Check(bufferString(ctx.Stderr), gc.Equals, "error: no resource name specified\n")
+}
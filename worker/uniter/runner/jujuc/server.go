@@ -56,6 +56,7 @@ var baseCommands = map[string]creator{
 	"relation-list" + cmdSuffix: NewRelationListCommand,
 	"relation-set" + cmdSuffix:  NewRelationSetCommand,
 	"unit-get" + cmdSuffix:      NewUnitGetCommand,
+	"network-get" + cmdSuffix:   NewNetworkGetCommand,
 	"add-metric" + cmdSuffix:    NewAddMetricCommand,
 	"juju-reboot" + cmdSuffix:   NewJujuRebootCommand,
 	"status-get" + cmdSuffix:    NewStatusGetCommand,
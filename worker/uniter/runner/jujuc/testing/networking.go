@@ -8,6 +8,7 @@ import (
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
 
+	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/network"
 )
 
@@ -16,6 +17,7 @@ type NetworkInterface struct {
 	PublicAddress  string
 	PrivateAddress string
 	Ports          []network.PortRange
+	NetworkInfo    map[string]params.NetworkInfoResult
 }
 
 // CheckPorts checks the current ports.
@@ -100,3 +102,19 @@ func (c *ContextNetworking) OpenedPorts() []network.PortRange {
 
 	return c.info.Ports
 }
+
+// NetworkInfo implements jujuc.ContextNetworking.
+func (c *ContextNetworking) NetworkInfo(bindingNames []string) (map[string]params.NetworkInfoResult, error) {
+	c.stub.AddCall("NetworkInfo", bindingNames)
+	if err := c.stub.NextErr(); err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	results := make(map[string]params.NetworkInfoResult)
+	for _, name := range bindingNames {
+		if result, ok := c.info.NetworkInfo[name]; ok {
+			results[name] = result
+		}
+	}
+	return results, nil
+}
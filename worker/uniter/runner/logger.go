@@ -18,6 +18,11 @@ type hookLogger struct {
 	mu      sync.Mutex
 	stopped bool
 	logger  loggo.Logger
+
+	// tailSize is the number of the most recent lines of output
+	// kept in tail.
+	tailSize int
+	tail     []string
 }
 
 func (l *hookLogger) run() {
@@ -38,6 +43,12 @@ func (l *hookLogger) run() {
 			return
 		}
 		l.logger.Infof("%s", line)
+		if l.tailSize > 0 {
+			l.tail = append(l.tail, string(line))
+			if len(l.tail) > l.tailSize {
+				l.tail = l.tail[len(l.tail)-l.tailSize:]
+			}
+		}
 		l.mu.Unlock()
 	}
 }
@@ -58,3 +69,10 @@ func (l *hookLogger) stop() {
 	l.stopped = true
 	l.mu.Unlock()
 }
+
+// lines returns the most recent lines of output read by the logger.
+func (l *hookLogger) lines() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.tail...)
+}
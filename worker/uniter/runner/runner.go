@@ -8,12 +8,14 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"time"
 
 	"github.com/juju/cmd"
 	"github.com/juju/errors"
 	"github.com/juju/loggo"
 	utilexec "github.com/juju/utils/exec"
 
+	"github.com/juju/juju/utils/procgroup"
 	"github.com/juju/juju/worker/uniter/runner/context"
 	"github.com/juju/juju/worker/uniter/runner/debug"
 	"github.com/juju/juju/worker/uniter/runner/jujuc"
@@ -31,6 +33,10 @@ type Runner interface {
 	// RunHook executes the hook with the supplied name.
 	RunHook(name string) error
 
+	// HookStderr returns the last lines written to standard error by
+	// the hook most recently executed with RunHook.
+	HookStderr() []string
+
 	// RunAction executes the action with the supplied name.
 	RunAction(name string) error
 
@@ -45,6 +51,7 @@ type Context interface {
 	HookVars(paths context.Paths) ([]string, error)
 	ActionData() (*context.ActionData, error)
 	SetProcess(process context.HookProcess)
+	HookTimeout() time.Duration
 	HasExecutionSetUnitStatus() bool
 	ResetExecutionSetUnitStatus()
 
@@ -52,15 +59,22 @@ type Context interface {
 	Flush(badge string, failure error) error
 }
 
+// maxHookStderrLines is the number of lines of a hook's standard error
+// kept by the runner.
+const maxHookStderrLines = 20
+
 // NewRunner returns a Runner backed by the supplied context and paths.
 func NewRunner(context Context, paths context.Paths) Runner {
-	return &runner{context, paths}
+	return &runner{context: context, paths: paths}
 }
 
 // runner implements Runner.
 type runner struct {
 	context Context
 	paths   context.Paths
+
+	// stderr holds the tail of the last hook's standard error.
+	stderr []string
 }
 
 func (runner *runner) Context() Context {
@@ -101,15 +115,22 @@ func (runner *runner) RunAction(actionName string) error {
 	if _, err := runner.context.ActionData(); err != nil {
 		return errors.Trace(err)
 	}
-	return runner.runCharmHookWithLocation(actionName, "actions")
+	// Actions are often expected to run for a long time (backups,
+	// for example), so they are not subject to the hook timeout.
+	return runner.runCharmHookWithLocation(actionName, "actions", 0)
 }
 
 // RunHook exists to satisfy the Runner interface.
 func (runner *runner) RunHook(hookName string) error {
-	return runner.runCharmHookWithLocation(hookName, "hooks")
+	return runner.runCharmHookWithLocation(hookName, "hooks", runner.context.HookTimeout())
 }
 
-func (runner *runner) runCharmHookWithLocation(hookName, charmLocation string) error {
+// HookStderr exists to satisfy the Runner interface.
+func (runner *runner) HookStderr() []string {
+	return runner.stderr
+}
+
+func (runner *runner) runCharmHookWithLocation(hookName, charmLocation string, timeout time.Duration) error {
 	srv, err := runner.startJujucServer()
 	if err != nil {
 		return err
@@ -127,17 +148,18 @@ func (runner *runner) runCharmHookWithLocation(hookName, charmLocation string) e
 		env = mergeWindowsEnvironment(env, os.Environ())
 	}
 
+	runner.stderr = nil
 	debugctx := debug.NewHooksContext(runner.context.UnitName())
 	if session, _ := debugctx.FindSession(); session != nil && session.MatchHook(hookName) {
 		logger.Infof("executing %s via debug-hooks", hookName)
 		err = session.RunHook(hookName, runner.paths.GetCharmDir(), env)
 	} else {
-		err = runner.runCharmHook(hookName, env, charmLocation)
+		err = runner.runCharmHook(hookName, env, charmLocation, timeout)
 	}
 	return runner.context.Flush(hookName, err)
 }
 
-func (runner *runner) runCharmHook(hookName string, env []string, charmLocation string) error {
+func (runner *runner) runCharmHook(hookName string, env []string, charmLocation string, timeout time.Duration) error {
 	charmDir := runner.paths.GetCharmDir()
 	hook, err := searchHook(charmDir, filepath.Join(charmLocation, hookName))
 	if err != nil {
@@ -147,30 +169,65 @@ func (runner *runner) runCharmHook(hookName string, env []string, charmLocation
 	ps := exec.Command(hookCmd[0], hookCmd[1:]...)
 	ps.Env = env
 	ps.Dir = charmDir
+	if timeout > 0 {
+		// Run the hook in its own process group, so that it can be
+		// killed along with its children if it times out.
+		procgroup.Set(ps)
+	}
 	outReader, outWriter, err := os.Pipe()
 	if err != nil {
 		return errors.Errorf("cannot make logging pipe: %v", err)
 	}
+	errReader, errWriter, err := os.Pipe()
+	if err != nil {
+		outReader.Close()
+		outWriter.Close()
+		return errors.Errorf("cannot make logging pipe: %v", err)
+	}
 	ps.Stdout = outWriter
-	ps.Stderr = outWriter
+	ps.Stderr = errWriter
 	hookLogger := &hookLogger{
 		r:      outReader,
 		done:   make(chan struct{}),
 		logger: runner.getLogger(hookName),
 	}
 	go hookLogger.run()
+	// Standard error is logged in the same way, but its tail is
+	// also kept for the hook's execution record.
+	errLogger := &hookLogger{
+		r:        errReader,
+		done:     make(chan struct{}),
+		logger:   runner.getLogger(hookName),
+		tailSize: maxHookStderrLines,
+	}
+	go errLogger.run()
 	err = ps.Start()
 	outWriter.Close()
+	errWriter.Close()
 	if err == nil {
 		// Record the *os.Process of the hook
 		runner.context.SetProcess(hookProcess{ps.Process})
 		// Block until execution finishes
-		err = ps.Wait()
+		err = waitHook(ps, hookName, timeout)
 	}
 	hookLogger.stop()
+	errLogger.stop()
+	runner.stderr = errLogger.lines()
 	return errors.Trace(err)
 }
 
+// waitHook waits for the hook process to finish. If a positive timeout
+// is given and the process runs for longer than that, the process and
+// its children are killed and a hook timeout error is returned.
+func waitHook(ps *exec.Cmd, hookName string, timeout time.Duration) error {
+	err := procgroup.Wait(ps, timeout)
+	if err == procgroup.ErrTimeout {
+		logger.Errorf("%s hook timed out after %v; killed it", hookName, timeout)
+		return context.NewHookTimeoutError(hookName, timeout)
+	}
+	return err
+}
+
 func (runner *runner) startJujucServer() (*jujuc.Server, error) {
 	// Prepare server.
 	getCmd := func(ctxId, cmdName string) (cmd.Command, error) {
@@ -151,6 +151,7 @@ type MockContext struct {
 	flushBadge   string
 	flushFailure error
 	flushResult  error
+	hookTimeout  time.Duration
 }
 
 func (ctx *MockContext) UnitName() string {
@@ -172,6 +173,10 @@ func (ctx *MockContext) SetProcess(process context.HookProcess) {
 	ctx.expectPid = process.Pid()
 }
 
+func (ctx *MockContext) HookTimeout() time.Duration {
+	return ctx.hookTimeout
+}
+
 func (ctx *MockContext) Prepare() error {
 	return nil
 }
@@ -237,6 +242,27 @@ func (s *RunMockContextSuite) TestRunHookFlushFailure(c *gc.C) {
 	s.assertRecordedPid(c, ctx.expectPid)
 }
 
+func (s *RunMockContextSuite) TestRunHookTimeout(c *gc.C) {
+	ctx := &MockContext{
+		hookTimeout: 100 * time.Millisecond,
+	}
+	makeCharm(c, hookSpec{
+		dir:   "hooks",
+		name:  hookName,
+		perm:  0700,
+		sleep: 10,
+	}, s.paths.GetCharmDir())
+	t0 := time.Now()
+	err := runner.NewRunner(ctx, s.paths).RunHook("something-happened")
+	c.Assert(err, jc.ErrorIsNil)
+	if time.Now().Sub(t0) > 5*time.Second {
+		c.Errorf("hook was not killed on timeout")
+	}
+	c.Assert(ctx.flushBadge, gc.Equals, "something-happened")
+	c.Assert(ctx.flushFailure, gc.ErrorMatches, "something-happened timed out after 100ms")
+	c.Assert(errors.Cause(ctx.flushFailure), jc.Satisfies, context.IsHookTimeoutError)
+}
+
 func (s *RunMockContextSuite) TestRunActionFlushSuccess(c *gc.C) {
 	expectErr := errors.New("pew pew pew")
 	ctx := &MockContext{
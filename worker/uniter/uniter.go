@@ -579,7 +579,7 @@ func (u *Uniter) acquireExecutionLock(message string) (func() error, error) {
 	}, nil
 }
 
-func (u *Uniter) reportHookError(hookInfo hook.Info) error {
+func (u *Uniter) reportHookError(hookInfo hook.Info, timedOut bool) error {
 	// Set the agent status to "error". We must do this here in case the
 	// hook is interrupted (e.g. unit agent crashes), rather than immediately
 	// after attempting a runHookOp.
@@ -598,5 +598,9 @@ func (u *Uniter) reportHookError(hookInfo hook.Info) error {
 	}
 	statusData["hook"] = hookName
 	statusMessage := fmt.Sprintf("hook failed: %q", hookName)
+	if timedOut {
+		statusData["timed-out"] = true
+		statusMessage = fmt.Sprintf("hook timed out: %q", hookName)
+	}
 	return setAgentStatus(u, params.StatusError, statusMessage, statusData)
 }